@@ -2,16 +2,20 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/rs/zerolog"
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
 	"github.com/ryanrussell/claude-cache-service/internal/api"
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
 	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+	"github.com/ryanrussell/claude-cache-service/internal/telemetry"
 	"github.com/ryanrussell/claude-cache-service/internal/worker"
 )
 
@@ -25,10 +29,13 @@ func main() {
 		Logger()
 
 	// Load configuration
-	cfg, err := config.Load()
+	cfg, err := config.Load(logger)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to load configuration")
 	}
+	if err := cfg.Validate(); err != nil {
+		logger.Fatal().Err(err).Msg("Invalid configuration")
+	}
 
 	// Set log level from config
 	if cfg.Debug {
@@ -40,8 +47,48 @@ func main() {
 		Str("port", cfg.Port).
 		Msg("Starting Claude Cache Service")
 
+	// Export traces of requests, cache operations and Claude API calls
+	tracing, err := telemetry.Setup(context.Background(), telemetry.Config{
+		Enabled:  cfg.TracingEnabled,
+		Endpoint: cfg.OTLPEndpoint,
+		Version:  cfg.Version,
+	}, logger)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer shutdownCancel()
+		if err := tracing.Shutdown(shutdownCtx); err != nil {
+			logger.Error().Err(err).Msg("Failed to flush traces")
+		}
+	}()
+
+	cacheOpts := []cache.Option{
+		cache.WithFeatureFlags(cfg),
+		cache.WithMaxSize(cfg.MaxCacheSize),
+		cache.WithCompressThreshold(cfg.CompressThreshold),
+		cache.WithL1CacheSize(cfg.L1CacheSize),
+		cache.WithBackend(cfg.CacheBackend, cfg.RedisURL),
+	}
+
+	// Share evictions with other instances when Redis is configured. A
+	// Redis backend is already shared, so needs no notices.
+	if cfg.RedisURL != "" && cfg.CacheBackend != cache.BackendRedis {
+		notifier, err := cache.NewRedisEvictionNotifier(cfg.RedisURL, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to initialize cache eviction notifier")
+		}
+		defer func() {
+			if err := notifier.Close(); err != nil {
+				logger.Error().Err(err).Msg("Failed to close cache eviction notifier")
+			}
+		}()
+		cacheOpts = append(cacheOpts, cache.WithEvictionNotifier(notifier))
+	}
+
 	// Initialize cache manager
-	cacheManager, err := cache.NewManager(cfg.CacheDir, logger)
+	cacheManager, err := cache.NewManager(cfg.CacheDir, logger, cacheOpts...)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Failed to initialize cache manager")
 	}
@@ -51,20 +98,68 @@ func main() {
 		}
 	}()
 
+	// Load SDKs registered at runtime on top of the embedded ones
+	registryPath := filepath.Join(cfg.CacheDir, "sdk_registry.json")
+	registry, err := sdk.OpenRegistry(registryPath)
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to load SDK registry")
+	}
+	sdk.SetDefaultRegistry(registry)
+
+	// Analyze SDKs with the configured prompt template, failing now if it
+	// is invalid rather than on the first analysis
+	if cfg.DefaultPromptTemplate != "" {
+		prompt, err := claude.LoadPromptTemplate(claude.PromptSDKAnalysis, cfg.DefaultPromptTemplate)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load prompt template")
+		}
+		if err := claude.SetPromptTemplate(prompt); err != nil {
+			logger.Fatal().Err(err).Msg("Failed to load prompt template")
+		}
+	}
+
 	// Initialize update worker
 	updateWorker := worker.NewUpdateWorker(cacheManager, logger, cfg)
 
+	// Initialize analytics store
+	if cfg.EnableAnalytics {
+		analyticsStore, err := analytics.Open(cfg.AnalyticsDBPath, logger)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("Failed to initialize analytics store")
+		}
+		defer func() {
+			if err := analyticsStore.Close(); err != nil {
+				logger.Error().Err(err).Msg("Failed to close analytics store")
+			}
+		}()
+		updateWorker.SetAnalyticsStore(analyticsStore)
+		cacheManager.SetUsageRecorder(analyticsStore.Buckets())
+	}
+
 	// Start scheduled updates
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go updateWorker.Start(ctx)
 
+	// Apply schedule and cache size changes made to .env while running
+	watcher, err := config.NewWatcher(cfg, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to watch configuration, changes need a restart")
+	} else {
+		go updateWorker.WatchConfig(ctx, watcher.Subscribe())
+		go cacheManager.WatchConfig(ctx, watcher.Subscribe())
+		go watcher.Run(ctx)
+	}
+
 	// Initialize API server
-	server := api.NewServer(cfg, cacheManager, logger)
+	server := api.NewServer(cfg, cacheManager, updateWorker, logger)
 
 	// Handle graceful shutdown
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
+
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		<-sigChan
@@ -72,7 +167,8 @@ func main() {
 		logger.Info().Msg("Shutting down gracefully...")
 		cancel()
 
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		// Allow the worker's drain timeout on top of the HTTP shutdown budget
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.DrainTimeout+30*time.Second)
 		defer shutdownCancel()
 
 		if err := server.Shutdown(shutdownCtx); err != nil {
@@ -81,10 +177,12 @@ func main() {
 	}()
 
 	// Start the server
-	addr := fmt.Sprintf(":%s", cfg.Port)
-	logger.Info().Str("address", addr).Msg("Starting API server")
-
-	if err := server.Run(addr); err != nil {
+	if err := server.Run(); err != nil {
 		logger.Fatal().Err(err).Msg("Failed to start server")
 	}
+
+	// Run returns as soon as shutdown begins; wait for requests and the
+	// worker to drain
+	<-shutdownDone
+	logger.Info().Msg("Server stopped")
 }
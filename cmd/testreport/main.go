@@ -0,0 +1,420 @@
+// Command testreport converts `go test -json` output read from stdin into a
+// JUnit XML report for CI systems and prints a per-package summary.
+//
+// Usage:
+//
+//	go test -json -cover ./... | go run ./cmd/testreport [flags]
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Exit codes
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+// maxLineBytes bounds a single line of `go test -json` output.
+const maxLineBytes = 4 << 20
+
+// coveragePattern matches the coverage line printed by `go test -cover`.
+var coveragePattern = regexp.MustCompile(`coverage: ([0-9.]+)% of statements`)
+
+// TestEvent is a single event of `go test -json` output.
+type TestEvent struct {
+	Time    time.Time `json:"Time"`
+	Action  string    `json:"Action"`
+	Package string    `json:"Package"`
+	Test    string    `json:"Test"`
+	Elapsed float64   `json:"Elapsed"`
+	Output  string    `json:"Output"`
+}
+
+// testResult is the outcome of a single test.
+type testResult struct {
+	name    string
+	status  string // "pass", "fail" or "skip"
+	elapsed float64
+	output  strings.Builder
+}
+
+// packageResult aggregates the tests of a single package.
+type packageResult struct {
+	name     string
+	started  time.Time
+	status   string
+	elapsed  float64
+	coverage float64 // Negative when not reported
+	output   strings.Builder
+	tests    map[string]*testResult
+	order    []string
+}
+
+func (p *packageResult) counts() (passed, failed, skipped int) {
+	for _, test := range p.tests {
+		switch test.status {
+		case "pass":
+			passed++
+		case "fail":
+			failed++
+		case "skip":
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// packageError reports whether the package failed without a failing test,
+// e.g. because it did not build or a test binary panicked outside a test.
+func (p *packageResult) packageError() bool {
+	_, failed, _ := p.counts()
+	return p.status == "fail" && failed == 0
+}
+
+// report collects the results of a test run.
+type report struct {
+	packages map[string]*packageResult
+	order    []string
+}
+
+func newReport() *report {
+	return &report{packages: make(map[string]*packageResult)}
+}
+
+func (r *report) pkg(name string) *packageResult {
+	p, ok := r.packages[name]
+	if !ok {
+		p = &packageResult{name: name, coverage: -1, tests: make(map[string]*testResult)}
+		r.packages[name] = p
+		r.order = append(r.order, name)
+	}
+	return p
+}
+
+// add records an event and reports whether it was a test failure.
+func (r *report) add(event TestEvent) bool {
+	if event.Package == "" {
+		return false
+	}
+	p := r.pkg(event.Package)
+	if p.started.IsZero() {
+		p.started = event.Time
+	}
+
+	if event.Test == "" {
+		switch event.Action {
+		case "output":
+			p.output.WriteString(event.Output)
+			if match := coveragePattern.FindStringSubmatch(event.Output); match != nil {
+				if coverage, err := strconv.ParseFloat(match[1], 64); err == nil {
+					p.coverage = coverage
+				}
+			}
+		case "pass", "fail", "skip":
+			p.status = event.Action
+			p.elapsed = event.Elapsed
+		}
+		return false
+	}
+
+	test, ok := p.tests[event.Test]
+	if !ok {
+		test = &testResult{name: event.Test}
+		p.tests[event.Test] = test
+		p.order = append(p.order, event.Test)
+	}
+
+	switch event.Action {
+	case "output":
+		test.output.WriteString(event.Output)
+	case "pass", "fail", "skip":
+		test.status = event.Action
+		test.elapsed = event.Elapsed
+		return event.Action == "fail"
+	}
+	return false
+}
+
+// totalCoverage returns the mean coverage of the packages that reported
+// one. Statement counts are not part of `go test -json` output, so packages
+// are weighted equally.
+func (r *report) totalCoverage() (float64, bool) {
+	var sum float64
+	var n int
+	for _, p := range r.packages {
+		if p.coverage >= 0 {
+			sum += p.coverage
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return sum / float64(n), true
+}
+
+func (r *report) failed() bool {
+	for _, p := range r.packages {
+		if p.status == "fail" {
+			return true
+		}
+		if _, failed, _ := p.counts(); failed > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// JUnit XML report structure
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+}
+
+type junitMessage struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
+
+// junit converts the report to JUnit XML test suites, one per package.
+func (r *report) junit() junitTestSuites {
+	var suites junitTestSuites
+	var total float64
+
+	for _, name := range r.order {
+		p := r.packages[name]
+		passed, failed, skipped := p.counts()
+
+		suite := junitTestSuite{
+			Name:     p.name,
+			Tests:    passed + failed + skipped,
+			Failures: failed,
+			Skipped:  skipped,
+			Time:     formatSeconds(p.elapsed),
+		}
+		if !p.started.IsZero() {
+			suite.Timestamp = p.started.UTC().Format(time.RFC3339)
+		}
+		if p.coverage >= 0 {
+			suite.Properties = append(suite.Properties, junitProperty{
+				Name:  "coverage",
+				Value: strconv.FormatFloat(p.coverage, 'f', 1, 64),
+			})
+		}
+
+		for _, testName := range p.order {
+			test := p.tests[testName]
+			testCase := junitTestCase{
+				ClassName: p.name,
+				Name:      test.name,
+				Time:      formatSeconds(test.elapsed),
+			}
+			switch test.status {
+			case "fail":
+				testCase.Failure = &junitMessage{Message: "Failed", Contents: test.output.String()}
+			case "skip":
+				testCase.Skipped = &junitMessage{Message: "Skipped", Contents: test.output.String()}
+			case "":
+				// Still running when the input ended, e.g. after --fail-fast
+				continue
+			}
+			suite.TestCases = append(suite.TestCases, testCase)
+		}
+
+		if p.packageError() {
+			suite.Tests++
+			suite.Errors++
+			suite.TestCases = append(suite.TestCases, junitTestCase{
+				ClassName: p.name,
+				Name:      "[package]",
+				Time:      formatSeconds(p.elapsed),
+				Error:     &junitMessage{Message: "Package failed", Contents: p.output.String()},
+			})
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Skipped += suite.Skipped
+		total += p.elapsed
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	suites.Time = formatSeconds(total)
+	return suites
+}
+
+// writeJUnit writes the report as JUnit XML to path.
+func (r *report) writeJUnit(path string) error {
+	data, err := xml.MarshalIndent(r.junit(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+// writeSummary prints a table of per-package results.
+func (r *report) writeSummary(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PACKAGE\tPASS\tFAIL\tSKIP\tCOVERAGE\tTIME")
+
+	names := append([]string(nil), r.order...)
+	sort.Strings(names)
+
+	var totalPassed, totalFailed, totalSkipped int
+	for _, name := range names {
+		p := r.packages[name]
+		passed, failed, skipped := p.counts()
+		if p.packageError() {
+			failed++
+		}
+		totalPassed += passed
+		totalFailed += failed
+		totalSkipped += skipped
+
+		coverage := "-"
+		if p.coverage >= 0 {
+			coverage = fmt.Sprintf("%.1f%%", p.coverage)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%ss\n", name, passed, failed, skipped, coverage, formatSeconds(p.elapsed))
+	}
+
+	coverage := "-"
+	if total, ok := r.totalCoverage(); ok {
+		coverage = fmt.Sprintf("%.1f%%", total)
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%s\t\n", totalPassed, totalFailed, totalSkipped, coverage)
+	return tw.Flush()
+}
+
+// readEvents adds the events read from in to r. With failFast it stops at
+// the first test failure and reports true.
+func readEvents(in io.Reader, r *report, failFast bool) (bool, error) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxLineBytes)
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 || line[0] != '{' {
+			// Build errors and other non-JSON output
+			continue
+		}
+
+		var event TestEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return false, fmt.Errorf("failed to parse test event: %w", err)
+		}
+		if r.add(event) && failFast {
+			return true, nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed to read test output: %w", err)
+	}
+	return false, nil
+}
+
+// run executes the command and returns its exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("testreport", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("output", "testreport.xml", "path of the JUnit XML report")
+	failFast := flags.Bool("fail-fast", false, "stop at the first failing test")
+	coverageThreshold := flags.Float64("coverage-threshold", 0, "minimum total coverage percentage")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	r := newReport()
+	stopped, err := readEvents(stdin, r, *failFast)
+	if err != nil {
+		fmt.Fprintln(stderr, "testreport:", err)
+		return exitError
+	}
+
+	if err := r.writeJUnit(*output); err != nil {
+		fmt.Fprintln(stderr, "testreport:", err)
+		return exitError
+	}
+	if err := r.writeSummary(stdout); err != nil {
+		fmt.Fprintln(stderr, "testreport:", err)
+		return exitError
+	}
+
+	code := exitOK
+	if stopped {
+		fmt.Fprintln(stderr, "testreport: stopped at first failure")
+	}
+	if r.failed() {
+		code = exitFailed
+	}
+	if *coverageThreshold > 0 {
+		total, ok := r.totalCoverage()
+		if !ok || total < *coverageThreshold {
+			fmt.Fprintf(stderr, "testreport: total coverage %.1f%% is below threshold %.1f%%\n", total, *coverageThreshold)
+			code = exitFailed
+		}
+	}
+	return code
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sampleOutput is `go test -json -cover` output for two packages: one
+// passing with a skipped test and one with a failing subtest.
+const sampleOutput = `{"Time":"2026-01-05T10:00:00Z","Action":"start","Package":"example.com/app/cache"}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/cache","Test":"TestGet"}
+{"Time":"2026-01-05T10:00:00Z","Action":"output","Package":"example.com/app/cache","Test":"TestGet","Output":"=== RUN   TestGet\n"}
+{"Time":"2026-01-05T10:00:00Z","Action":"output","Package":"example.com/app/cache","Test":"TestGet","Output":"--- PASS: TestGet (0.01s)\n"}
+{"Time":"2026-01-05T10:00:00Z","Action":"pass","Package":"example.com/app/cache","Test":"TestGet","Elapsed":0.01}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/cache","Test":"TestRedis"}
+{"Time":"2026-01-05T10:00:00Z","Action":"output","Package":"example.com/app/cache","Test":"TestRedis","Output":"    cache_test.go:40: REDIS_URL not set\n"}
+{"Time":"2026-01-05T10:00:00Z","Action":"skip","Package":"example.com/app/cache","Test":"TestRedis","Elapsed":0}
+{"Time":"2026-01-05T10:00:01Z","Action":"output","Package":"example.com/app/cache","Output":"coverage: 80.0% of statements\n"}
+{"Time":"2026-01-05T10:00:01Z","Action":"output","Package":"example.com/app/cache","Output":"ok  \texample.com/app/cache\t0.5s\tcoverage: 80.0% of statements\n"}
+{"Time":"2026-01-05T10:00:01Z","Action":"pass","Package":"example.com/app/cache","Elapsed":0.5}
+{"Time":"2026-01-05T10:00:00Z","Action":"start","Package":"example.com/app/api"}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/api","Test":"TestHealth"}
+{"Time":"2026-01-05T10:00:00Z","Action":"pass","Package":"example.com/app/api","Test":"TestHealth","Elapsed":0.02}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/api","Test":"TestRefresh"}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/api","Test":"TestRefresh/unauthorized"}
+{"Time":"2026-01-05T10:00:00Z","Action":"output","Package":"example.com/app/api","Test":"TestRefresh/unauthorized","Output":"    api_test.go:12: expected 401, got 200\n"}
+{"Time":"2026-01-05T10:00:00Z","Action":"fail","Package":"example.com/app/api","Test":"TestRefresh/unauthorized","Elapsed":0.03}
+{"Time":"2026-01-05T10:00:00Z","Action":"fail","Package":"example.com/app/api","Test":"TestRefresh","Elapsed":0.03}
+{"Time":"2026-01-05T10:00:00Z","Action":"run","Package":"example.com/app/api","Test":"TestVersion"}
+{"Time":"2026-01-05T10:00:00Z","Action":"pass","Package":"example.com/app/api","Test":"TestVersion","Elapsed":0.01}
+{"Time":"2026-01-05T10:00:01Z","Action":"output","Package":"example.com/app/api","Output":"coverage: 60.0% of statements\n"}
+{"Time":"2026-01-05T10:00:01Z","Action":"fail","Package":"example.com/app/api","Elapsed":0.7}
+`
+
+// buildFailureOutput is the output for a package that does not compile.
+const buildFailureOutput = `# example.com/app/worker
+worker.go:10:2: undefined: missing
+{"Time":"2026-01-05T10:00:00Z","Action":"start","Package":"example.com/app/worker"}
+{"Time":"2026-01-05T10:00:00Z","Action":"output","Package":"example.com/app/worker","Output":"FAIL\texample.com/app/worker [build failed]\n"}
+{"Time":"2026-01-05T10:00:00Z","Action":"fail","Package":"example.com/app/worker","Elapsed":0}
+`
+
+// packageOutput returns the lines of sampleOutput for a single package.
+func packageOutput(pkg string) string {
+	var lines []string
+	for _, line := range strings.Split(sampleOutput, "\n") {
+		if strings.Contains(line, `"Package":"`+pkg+`"`) {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func runReport(t *testing.T, input string, args ...string) (int, junitTestSuites, string, string) {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "testreport.xml")
+	var stdout, stderr bytes.Buffer
+	code := run(append([]string{"--output", output}, args...), strings.NewReader(input), &stdout, &stderr)
+
+	var suites junitTestSuites
+	if code != exitError {
+		data, err := os.ReadFile(output)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(data), xml.Header))
+		require.NoError(t, xml.Unmarshal(data, &suites), "report should be valid XML")
+	}
+	return code, suites, stdout.String(), stderr.String()
+}
+
+func TestReport(t *testing.T) {
+	code, suites, stdout, _ := runReport(t, sampleOutput)
+
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, 6, suites.Tests)
+	assert.Equal(t, 2, suites.Failures)
+	assert.Equal(t, 1, suites.Skipped)
+	assert.Equal(t, 0, suites.Errors)
+	assert.Equal(t, "1.200", suites.Time)
+
+	require.Len(t, suites.Suites, 2)
+	cacheSuite := suites.Suites[0]
+	assert.Equal(t, "example.com/app/cache", cacheSuite.Name)
+	assert.Equal(t, 2, cacheSuite.Tests)
+	assert.Equal(t, 1, cacheSuite.Skipped)
+	assert.Equal(t, "2026-01-05T10:00:00Z", cacheSuite.Timestamp)
+	assert.Equal(t, []junitProperty{{Name: "coverage", Value: "80.0"}}, cacheSuite.Properties)
+	require.Len(t, cacheSuite.TestCases, 2)
+	require.NotNil(t, cacheSuite.TestCases[1].Skipped)
+	assert.Contains(t, cacheSuite.TestCases[1].Skipped.Contents, "REDIS_URL not set")
+
+	apiSuite := suites.Suites[1]
+	assert.Equal(t, "example.com/app/api", apiSuite.Name)
+	assert.Equal(t, 4, apiSuite.Tests)
+	assert.Equal(t, 2, apiSuite.Failures)
+	require.Len(t, apiSuite.TestCases, 4)
+	subtest := apiSuite.TestCases[2]
+	assert.Equal(t, "TestRefresh/unauthorized", subtest.Name)
+	assert.Equal(t, "example.com/app/api", subtest.ClassName)
+	assert.Equal(t, "0.030", subtest.Time)
+	require.NotNil(t, subtest.Failure)
+	assert.Contains(t, subtest.Failure.Contents, "expected 401, got 200")
+	assert.Nil(t, apiSuite.TestCases[0].Failure)
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	assert.Regexp(t, `^PACKAGE\s+PASS\s+FAIL\s+SKIP\s+COVERAGE\s+TIME$`, lines[0])
+	assert.Regexp(t, `^example.com/app/api\s+2\s+2\s+0\s+60.0%\s+0.700s$`, lines[1])
+	assert.Regexp(t, `^example.com/app/cache\s+1\s+0\s+1\s+80.0%\s+0.500s$`, lines[2])
+	assert.Regexp(t, `^TOTAL\s+3\s+2\s+1\s+70.0%`, lines[3])
+}
+
+func TestReportPassing(t *testing.T) {
+	code, suites, _, _ := runReport(t, packageOutput("example.com/app/cache"))
+
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, 2, suites.Tests)
+	assert.Zero(t, suites.Failures)
+}
+
+func TestReportBuildFailure(t *testing.T) {
+	code, suites, stdout, _ := runReport(t, buildFailureOutput)
+
+	assert.Equal(t, exitFailed, code)
+	assert.Equal(t, 1, suites.Errors)
+	require.Len(t, suites.Suites, 1)
+	require.Len(t, suites.Suites[0].TestCases, 1)
+	testCase := suites.Suites[0].TestCases[0]
+	require.NotNil(t, testCase.Error)
+	assert.Contains(t, testCase.Error.Contents, "build failed")
+	assert.Regexp(t, `example.com/app/worker\s+0\s+1\s+0`, stdout)
+}
+
+func TestReportFailFast(t *testing.T) {
+	code, suites, _, stderr := runReport(t, sampleOutput, "--fail-fast")
+
+	assert.Equal(t, exitFailed, code)
+	assert.Contains(t, stderr, "stopped at first failure")
+
+	require.Len(t, suites.Suites, 2)
+	apiSuite := suites.Suites[1]
+	// The parent test had not finished when the subtest failed
+	require.Len(t, apiSuite.TestCases, 2)
+	assert.Equal(t, "TestHealth", apiSuite.TestCases[0].Name)
+	assert.Equal(t, "TestRefresh/unauthorized", apiSuite.TestCases[1].Name)
+}
+
+func TestReportCoverageThreshold(t *testing.T) {
+	input := packageOutput("example.com/app/cache")
+
+	tests := []struct {
+		name      string
+		input     string
+		threshold string
+		expected  int
+	}{
+		{"above threshold", input, "75", exitOK},
+		{"below threshold", input, "85", exitFailed},
+		{"no coverage reported", `{"Action":"pass","Package":"example.com/app/none"}`, "10", exitFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, _, stderr := runReport(t, tt.input, "--coverage-threshold", tt.threshold)
+			assert.Equal(t, tt.expected, code)
+			if tt.expected == exitFailed {
+				assert.Contains(t, stderr, "below threshold")
+			}
+		})
+	}
+}
+
+func TestReportInvalidInput(t *testing.T) {
+	code, _, _, stderr := runReport(t, "{not json}\n")
+
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "failed to parse test event")
+}
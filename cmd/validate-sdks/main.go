@@ -0,0 +1,64 @@
+// Command validate-sdks checks the SDK configurations of sdks.yaml, and
+// optionally the SDKs registered at runtime, and prints every problem
+// found. It exits non-zero if there are any, so CI can run it.
+//
+// Usage:
+//
+//	go run ./cmd/validate-sdks [-registry CACHE_DIR/sdk_registry.json]
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// Exit codes
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+// run executes the command and returns its exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("validate-sdks", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	registryPath := flags.String("registry", "", "SDK registry file whose registrations and removals to apply")
+	if err := flags.Parse(args); err != nil {
+		return exitError
+	}
+
+	if *registryPath != "" {
+		registry, err := sdk.OpenRegistry(*registryPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "validate-sdks:", err)
+			return exitError
+		}
+		sdk.SetDefaultRegistry(registry)
+	}
+
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		fmt.Fprintln(stderr, "validate-sdks:", err)
+		return exitError
+	}
+
+	errs := configs.Validate()
+	for _, err := range errs {
+		fmt.Fprintln(stderr, err)
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(stderr, "validate-sdks: %d problems in %d SDK configs\n", len(errs), len(configs.SDKs))
+		return exitInvalid
+	}
+	fmt.Fprintf(stdout, "%d SDK configs are valid\n", len(configs.SDKs))
+	return exitOK
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func TestRun(t *testing.T) {
+	t.Cleanup(func() { sdk.SetDefaultRegistry(sdk.NewRegistry()) })
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitOK, run(nil, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "SDK configs are valid")
+	assert.Empty(t, stderr.String())
+
+	// A registry file edited by hand can hold SDKs Register would reject
+	path := filepath.Join(t.TempDir(), "sdk_registry.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"registered": [{"name": "sentry-crystal", "url": "sentry-crystal"}]}`), 0o600))
+	stdout.Reset()
+	stderr.Reset()
+	assert.Equal(t, exitInvalid, run([]string{"-registry", path}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "SDK sentry-crystal: language is required")
+	assert.Contains(t, stderr.String(), "3 problems")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+	assert.Equal(t, exitError, run([]string{"-registry", path}, &stdout, &stderr))
+}
@@ -0,0 +1,190 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/tidwall/buntdb"
+)
+
+// kindDeadLetter prefixes failed analyses, keyed by SDK name.
+const kindDeadLetter = "deadletter"
+
+// FailedAnalysis records the failed analyses of an SDK. Attempts counts
+// the failures since FirstFailedAt; FailedAt and Error describe the latest.
+type FailedAnalysis struct {
+	SDK           string    `json:"sdk"`
+	FailedAt      time.Time `json:"failed_at"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	Error         string    `json:"error"`
+	Attempts      int       `json:"attempts"`
+}
+
+// DeadLetterQueue keeps the SDKs whose analyses failed until they are
+// analyzed successfully or retried. Records are kept in a BuntDB database,
+// if it has one, and in memory otherwise.
+type DeadLetterQueue struct {
+	db *buntdb.DB
+
+	// Failures counted more than window after the first start over
+	window time.Duration
+
+	mu       sync.Mutex
+	failures map[string]FailedAnalysis
+}
+
+// NewDeadLetterQueue creates an empty, in-memory dead-letter queue that
+// counts failures within window of each other.
+func NewDeadLetterQueue(window time.Duration) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		window:   window,
+		failures: make(map[string]FailedAnalysis),
+	}
+}
+
+// DeadLetters returns a dead-letter queue kept in the store that counts
+// failures within window of each other.
+func (s *Store) DeadLetters(window time.Duration) *DeadLetterQueue {
+	q := NewDeadLetterQueue(window)
+	q.db = s.db
+	return q
+}
+
+// Record adds a failed analysis of sdkName at now and returns the SDK's
+// record. The attempts start over once window has passed since the first
+// failure counted.
+func (q *DeadLetterQueue) Record(sdkName, errMsg string, now time.Time) (FailedAnalysis, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	failure, ok, err := q.get(sdkName)
+	if err != nil {
+		return FailedAnalysis{}, err
+	}
+	if !ok || now.Sub(failure.FirstFailedAt) > q.window {
+		failure = FailedAnalysis{SDK: sdkName, FirstFailedAt: now}
+	}
+	failure.FailedAt = now
+	failure.Error = errMsg
+	failure.Attempts++
+
+	if q.db == nil {
+		q.failures[sdkName] = failure
+		return failure, nil
+	}
+
+	data, err := json.Marshal(failure)
+	if err != nil {
+		return FailedAnalysis{}, fmt.Errorf("failed to marshal failed analysis: %w", err)
+	}
+	err = q.db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(kindDeadLetter+":"+sdkName, string(data), nil)
+		return err
+	})
+	if err != nil {
+		return FailedAnalysis{}, fmt.Errorf("failed to record failed analysis: %w", err)
+	}
+	return failure, nil
+}
+
+// Get returns the record of sdkName, and whether it has one.
+func (q *DeadLetterQueue) Get(sdkName string) (FailedAnalysis, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.get(sdkName)
+}
+
+// Remove deletes the record of sdkName and reports whether it had one.
+func (q *DeadLetterQueue) Remove(sdkName string) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.db == nil {
+		_, ok := q.failures[sdkName]
+		delete(q.failures, sdkName)
+		return ok, nil
+	}
+
+	removed := false
+	err := q.db.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(kindDeadLetter + ":" + sdkName)
+		if err == buntdb.ErrNotFound {
+			return nil
+		}
+		removed = err == nil
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to remove failed analysis: %w", err)
+	}
+	return removed, nil
+}
+
+// List returns every record, most recent failure first.
+func (q *DeadLetterQueue) List() ([]FailedAnalysis, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	failures := []FailedAnalysis{}
+	if q.db == nil {
+		for _, failure := range q.failures {
+			failures = append(failures, failure)
+		}
+	} else {
+		var decodeErr error
+		err := q.db.View(func(tx *buntdb.Tx) error {
+			return tx.AscendKeys(kindDeadLetter+":*", func(key, value string) bool {
+				var failure FailedAnalysis
+				if err := json.Unmarshal([]byte(value), &failure); err != nil {
+					decodeErr = fmt.Errorf("invalid failed analysis %s: %w", key, err)
+					return false
+				}
+				failures = append(failures, failure)
+				return true
+			})
+		})
+		if err == nil {
+			err = decodeErr
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read failed analyses: %w", err)
+		}
+	}
+
+	sort.Slice(failures, func(i, j int) bool {
+		if !failures[i].FailedAt.Equal(failures[j].FailedAt) {
+			return failures[i].FailedAt.After(failures[j].FailedAt)
+		}
+		return failures[i].SDK < failures[j].SDK
+	})
+	return failures, nil
+}
+
+func (q *DeadLetterQueue) get(sdkName string) (FailedAnalysis, bool, error) {
+	if q.db == nil {
+		failure, ok := q.failures[sdkName]
+		return failure, ok, nil
+	}
+
+	var value string
+	err := q.db.View(func(tx *buntdb.Tx) error {
+		var err error
+		value, err = tx.Get(kindDeadLetter + ":" + sdkName)
+		return err
+	})
+	if err == buntdb.ErrNotFound {
+		return FailedAnalysis{}, false, nil
+	}
+	if err != nil {
+		return FailedAnalysis{}, false, fmt.Errorf("failed to read failed analysis: %w", err)
+	}
+
+	var failure FailedAnalysis
+	if err := json.Unmarshal([]byte(value), &failure); err != nil {
+		return FailedAnalysis{}, false, fmt.Errorf("invalid failed analysis %s: %w", sdkName, err)
+	}
+	return failure, true, nil
+}
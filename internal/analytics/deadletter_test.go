@@ -0,0 +1,66 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetterQueue(t *testing.T) {
+	queues := map[string]func(t *testing.T) *DeadLetterQueue{
+		"memory": func(t *testing.T) *DeadLetterQueue { return NewDeadLetterQueue(24 * time.Hour) },
+		"store":  func(t *testing.T) *DeadLetterQueue { return newTestStore(t).DeadLetters(24 * time.Hour) },
+	}
+
+	for name, newQueue := range queues {
+		t.Run(name, func(t *testing.T) {
+			q := newQueue(t)
+			start := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+
+			failures, err := q.List()
+			require.NoError(t, err)
+			assert.Empty(t, failures)
+
+			// Failures within the window are counted together
+			for i := 1; i <= 3; i++ {
+				failure, err := q.Record("sentry-go", "clone failed", start.Add(time.Duration(i-1)*time.Hour))
+				require.NoError(t, err)
+				assert.Equal(t, i, failure.Attempts)
+				assert.True(t, failure.FirstFailedAt.Equal(start))
+			}
+			_, err = q.Record("sentry-python", "rate limited", start.Add(time.Hour))
+			require.NoError(t, err)
+
+			failures, err = q.List()
+			require.NoError(t, err)
+			require.Len(t, failures, 2)
+			assert.Equal(t, "sentry-go", failures[0].SDK, "most recent failure first")
+			assert.Equal(t, 3, failures[0].Attempts)
+			assert.Equal(t, "clone failed", failures[0].Error)
+			assert.True(t, failures[0].FailedAt.Equal(start.Add(2*time.Hour)))
+
+			// A failure after the window starts over
+			failure, err := q.Record("sentry-go", "timeout", start.Add(25*time.Hour))
+			require.NoError(t, err)
+			assert.Equal(t, 1, failure.Attempts)
+			assert.Equal(t, "timeout", failure.Error)
+
+			removed, err := q.Remove("sentry-go")
+			require.NoError(t, err)
+			assert.True(t, removed)
+			removed, err = q.Remove("sentry-go")
+			require.NoError(t, err)
+			assert.False(t, removed)
+
+			_, ok, err := q.Get("sentry-go")
+			require.NoError(t, err)
+			assert.False(t, ok)
+			failure, ok, err = q.Get("sentry-python")
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, 1, failure.Attempts)
+		})
+	}
+}
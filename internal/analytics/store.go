@@ -0,0 +1,314 @@
+// Package analytics records usage events and enforces their retention.
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/buntdb"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// Event kinds double as key prefixes in the store.
+const (
+	kindToken = "token"
+	kindCache = "cache"
+	kindAudit = "audit"
+)
+
+// TokenEvent records Claude tokens spent on an analysis.
+type TokenEvent struct {
+	SDK       string    `json:"sdk"`
+	Tokens    int       `json:"tokens"`
+	Timestamp time.Time `json:"-"`
+}
+
+// CacheEvent records a cache hit, miss or write.
+type CacheEvent struct {
+	Key       string    `json:"key"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"-"`
+}
+
+// AuditEvent records a mutating operation.
+type AuditEvent struct {
+	Action    string    `json:"action"`
+	Actor     string    `json:"actor"`
+	Resource  string    `json:"resource"`
+	RequestID string    `json:"request_id,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	Success   bool      `json:"success"`
+	Timestamp time.Time `json:"-"`
+}
+
+// storedEvent wraps an event with the timestamp used for retention.
+type storedEvent struct {
+	TS    int64           `json:"ts"`
+	Event json.RawMessage `json:"event"`
+}
+
+// PruneResult reports how many events a prune removed.
+type PruneResult struct {
+	TokenEventsDeleted int       `json:"token_events_deleted"`
+	CacheEventsDeleted int       `json:"cache_events_deleted"`
+	AuditEventsDeleted int       `json:"audit_events_deleted"`
+	BucketsDeleted     int       `json:"buckets_deleted"`
+	PrunedAt           time.Time `json:"pruned_at"`
+}
+
+// Store persists analytics events in BuntDB.
+type Store struct {
+	db      *buntdb.DB
+	logger  zerolog.Logger
+	seq     atomic.Uint64
+	buckets *BucketStore
+
+	mu        sync.RWMutex
+	lastPrune *PruneResult
+}
+
+// Open opens or creates the analytics database at path. Use ":memory:" for
+// a non-persistent store.
+func Open(path string, logger zerolog.Logger) (*Store, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open analytics database: %w", err)
+	}
+
+	for _, kind := range []string{kindToken, kindCache, kindAudit} {
+		if err := db.CreateIndex(kind, kind+":*", buntdb.IndexJSON("ts")); err != nil && err != buntdb.ErrIndexExists {
+			if closeErr := db.Close(); closeErr != nil {
+				logger.Error().Err(closeErr).Msg("Failed to close analytics database")
+			}
+			return nil, fmt.Errorf("failed to create %s index: %w", kind, err)
+		}
+	}
+
+	buckets, err := openBucketStore(db, logger)
+	if err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			logger.Error().Err(closeErr).Msg("Failed to close analytics database")
+		}
+		return nil, err
+	}
+
+	logger.Info().Str("path", path).Msg("Analytics store initialized")
+	return &Store{db: db, logger: logger, buckets: buckets}, nil
+}
+
+// Buckets returns the hourly cache usage counters kept in the store.
+func (s *Store) Buckets() *BucketStore {
+	return s.buckets
+}
+
+// Close writes out the usage buckets and closes the analytics database.
+func (s *Store) Close() error {
+	if err := s.buckets.Close(); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to persist analytics buckets")
+	}
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("failed to close analytics database: %w", err)
+	}
+	return nil
+}
+
+// RecordTokenUsage stores a token usage event.
+func (s *Store) RecordTokenUsage(event TokenEvent) error {
+	return s.record(kindToken, event.Timestamp, event)
+}
+
+// RecordCacheEvent stores a cache event.
+func (s *Store) RecordCacheEvent(event CacheEvent) error {
+	return s.record(kindCache, event.Timestamp, event)
+}
+
+// RecordAuditEvent stores an audit event.
+func (s *Store) RecordAuditEvent(event AuditEvent) error {
+	return s.record(kindAudit, event.Timestamp, event)
+}
+
+// AuditEvents returns up to limit audit events, newest first, or all of
+// them if limit is zero. A non-empty action keeps only events with that
+// action.
+func (s *Store) AuditEvents(limit int, action string) ([]AuditEvent, error) {
+	events := []AuditEvent{}
+	var decodeErr error
+	err := s.db.View(func(tx *buntdb.Tx) error {
+		return tx.Descend(kindAudit, func(key, value string) bool {
+			var stored storedEvent
+			var event AuditEvent
+			if err := json.Unmarshal([]byte(value), &stored); err != nil {
+				decodeErr = fmt.Errorf("invalid audit event %s: %w", key, err)
+				return false
+			}
+			if err := json.Unmarshal(stored.Event, &event); err != nil {
+				decodeErr = fmt.Errorf("invalid audit event %s: %w", key, err)
+				return false
+			}
+			if action != "" && event.Action != action {
+				return true
+			}
+
+			event.Timestamp = time.Unix(0, stored.TS)
+			events = append(events, event)
+			return limit <= 0 || len(events) < limit
+		})
+	})
+	if err == nil {
+		err = decodeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit events: %w", err)
+	}
+	return events, nil
+}
+
+// Count returns the number of stored events of each kind.
+func (s *Store) Count() (tokens, cacheEvents, audits int, err error) {
+	err = s.db.View(func(tx *buntdb.Tx) error {
+		counts := make([]int, 3)
+		for i, kind := range []string{kindToken, kindCache, kindAudit} {
+			if err := tx.Ascend(kind, func(key, value string) bool {
+				counts[i]++
+				return true
+			}); err != nil {
+				return err
+			}
+		}
+		tokens, cacheEvents, audits = counts[0], counts[1], counts[2]
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count analytics events: %w", err)
+	}
+	return tokens, cacheEvents, audits, nil
+}
+
+// Prune deletes events older than the policy allows. Usage buckets count
+// cache events and follow their retention. A retention of zero days keeps
+// that kind of event forever.
+func (s *Store) Prune(ctx context.Context, policy config.RetentionPolicy) (PruneResult, error) {
+	now := time.Now()
+	result := PruneResult{PrunedAt: now}
+
+	kinds := []struct {
+		kind    string
+		days    int
+		deleted *int
+	}{
+		{kindToken, policy.TokenEventDays, &result.TokenEventsDeleted},
+		{kindCache, policy.CacheEventDays, &result.CacheEventsDeleted},
+		{kindAudit, policy.AuditLogDays, &result.AuditEventsDeleted},
+	}
+
+	for _, k := range kinds {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if k.days <= 0 {
+			continue
+		}
+
+		cutoff := now.AddDate(0, 0, -k.days)
+		deleted, err := s.deleteBefore(k.kind, cutoff)
+		if err != nil {
+			return result, err
+		}
+		*k.deleted = deleted
+	}
+
+	if policy.CacheEventDays > 0 {
+		deleted, err := s.buckets.PruneBefore(now.AddDate(0, 0, -policy.CacheEventDays))
+		if err != nil {
+			return result, err
+		}
+		result.BucketsDeleted = deleted
+	}
+
+	s.mu.Lock()
+	s.lastPrune = &result
+	s.mu.Unlock()
+
+	s.logger.Info().
+		Int("token_events_deleted", result.TokenEventsDeleted).
+		Int("cache_events_deleted", result.CacheEventsDeleted).
+		Int("audit_events_deleted", result.AuditEventsDeleted).
+		Int("buckets_deleted", result.BucketsDeleted).
+		Msg("Pruned analytics events")
+
+	return result, nil
+}
+
+// LastPrune returns the result of the most recent prune, or nil if the
+// store has not been pruned yet.
+func (s *Store) LastPrune() *PruneResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.lastPrune == nil {
+		return nil
+	}
+	result := *s.lastPrune
+	return &result
+}
+
+func (s *Store) record(kind string, timestamp time.Time, event interface{}) error {
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", kind, err)
+	}
+
+	data, err := json.Marshal(storedEvent{TS: timestamp.UnixNano(), Event: payload})
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", kind, err)
+	}
+
+	// The sequence keeps keys unique for events recorded in the same nanosecond
+	key := fmt.Sprintf("%s:%d:%d", kind, timestamp.UnixNano(), s.seq.Add(1))
+
+	err = s.db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(key, string(data), nil)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", kind, err)
+	}
+	return nil
+}
+
+func (s *Store) deleteBefore(kind string, cutoff time.Time) (int, error) {
+	pivot := fmt.Sprintf(`{"ts":%d}`, cutoff.UnixNano())
+
+	deleted := 0
+	err := s.db.Update(func(tx *buntdb.Tx) error {
+		var keys []string
+		if err := tx.AscendLessThan(kind, pivot, func(key, value string) bool {
+			keys = append(keys, key)
+			return true
+		}); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if _, err := tx.Delete(key); err != nil && err != buntdb.ErrNotFound {
+				return err
+			}
+			deleted++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune %s events: %w", kind, err)
+	}
+	return deleted, nil
+}
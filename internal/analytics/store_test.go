@@ -0,0 +1,148 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	store, err := Open(filepath.Join(t.TempDir(), "analytics.db"), logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	return store
+}
+
+// seedDays records one event of each kind per day for the past days days.
+func seedDays(t *testing.T, store *Store, days int) {
+	t.Helper()
+
+	now := time.Now()
+	for d := 0; d < days; d++ {
+		ts := now.AddDate(0, 0, -d).Add(-time.Hour)
+		require.NoError(t, store.RecordTokenUsage(TokenEvent{SDK: "sentry-go", Tokens: 100, Timestamp: ts}))
+		require.NoError(t, store.RecordCacheEvent(CacheEvent{Key: "sdk:sentry-go", Type: "hit", Timestamp: ts}))
+		require.NoError(t, store.RecordAuditEvent(AuditEvent{Action: "delete", Actor: "admin", Resource: "sdk:sentry-go", Timestamp: ts}))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	store := newTestStore(t)
+	seedDays(t, store, 90)
+
+	tokens, cacheEvents, audits, err := store.Count()
+	require.NoError(t, err)
+	require.Equal(t, 90, tokens)
+	require.Equal(t, 90, cacheEvents)
+	require.Equal(t, 90, audits)
+
+	result, err := store.Prune(context.Background(), config.RetentionPolicy{
+		TokenEventDays: 30,
+		CacheEventDays: 30,
+		AuditLogDays:   30,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 60, result.TokenEventsDeleted)
+	assert.Equal(t, 60, result.CacheEventsDeleted)
+	assert.Equal(t, 60, result.AuditEventsDeleted)
+
+	tokens, cacheEvents, audits, err = store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 30, tokens)
+	assert.Equal(t, 30, cacheEvents)
+	assert.Equal(t, 30, audits)
+}
+
+func TestPruneMixedPolicy(t *testing.T) {
+	store := newTestStore(t)
+	seedDays(t, store, 90)
+
+	result, err := store.Prune(context.Background(), config.RetentionPolicy{
+		TokenEventDays: 60,
+		CacheEventDays: 7,
+		AuditLogDays:   0, // Keep forever
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 30, result.TokenEventsDeleted)
+	assert.Equal(t, 83, result.CacheEventsDeleted)
+	assert.Equal(t, 0, result.AuditEventsDeleted)
+
+	tokens, cacheEvents, audits, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 60, tokens)
+	assert.Equal(t, 7, cacheEvents)
+	assert.Equal(t, 90, audits)
+}
+
+func TestLastPrune(t *testing.T) {
+	store := newTestStore(t)
+	assert.Nil(t, store.LastPrune())
+
+	seedDays(t, store, 10)
+	_, err := store.Prune(context.Background(), config.RetentionPolicy{TokenEventDays: 5})
+	require.NoError(t, err)
+
+	last := store.LastPrune()
+	require.NotNil(t, last)
+	assert.Equal(t, 5, last.TokenEventsDeleted)
+	assert.WithinDuration(t, time.Now(), last.PrunedAt, 5*time.Second)
+}
+
+func TestPruneCancelled(t *testing.T) {
+	store := newTestStore(t)
+	seedDays(t, store, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := store.Prune(ctx, config.RetentionPolicy{TokenEventDays: 1})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, store.LastPrune())
+}
+
+func TestAuditEvents(t *testing.T) {
+	store := newTestStore(t)
+
+	now := time.Now()
+	for i, action := range []string{"set", "delete", "set", "delete", "set"} {
+		require.NoError(t, store.RecordAuditEvent(AuditEvent{
+			Action:    action,
+			Actor:     "system",
+			Resource:  fmt.Sprintf("key-%d", i),
+			Success:   true,
+			Timestamp: now.Add(time.Duration(i) * time.Second),
+		}))
+	}
+
+	events, err := store.AuditEvents(0, "")
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+	assert.Equal(t, "key-4", events[0].Resource, "newest first")
+	assert.True(t, events[0].Success)
+	assert.Equal(t, now.Add(4*time.Second).UnixNano(), events[0].Timestamp.UnixNano())
+
+	events, err = store.AuditEvents(1, "delete")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "key-3", events[0].Resource)
+
+	events, err = store.AuditEvents(10, "import")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
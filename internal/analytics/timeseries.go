@@ -0,0 +1,263 @@
+package analytics
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/tidwall/buntdb"
+)
+
+// EventType selects the counter an event is added to.
+type EventType int
+
+// Event types counted by a BucketStore.
+const (
+	HitEvent EventType = iota
+	MissEvent
+	TokensSavedEvent
+)
+
+// bucketSpan is the width of a time series bucket.
+const bucketSpan = time.Hour
+
+// bucketFlushInterval is how often changed buckets are written through to
+// the database.
+const bucketFlushInterval = time.Minute
+
+// kindBucket prefixes persisted buckets, keyed by bucket number.
+const kindBucket = "bucket"
+
+// BucketStats counts the events recorded in one hour.
+type BucketStats struct {
+	Start       time.Time `json:"start"`
+	Hits        int64     `json:"hits"`
+	Misses      int64     `json:"misses"`
+	TokensSaved int64     `json:"tokens_saved"`
+}
+
+// BucketStore counts cache hits, misses and token savings in hourly
+// buckets, keyed by Unix time divided by 3600. Buckets are written through
+// to a BuntDB database, if it has one, by a background flush once a minute
+// and on Flush, so recording an event never waits for the database.
+type BucketStore struct {
+	db     *buntdb.DB
+	logger zerolog.Logger
+
+	mu      sync.RWMutex
+	buckets map[int64]BucketStats
+	dirty   map[int64]bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewBucketStore creates an empty, in-memory bucket store.
+func NewBucketStore() *BucketStore {
+	return &BucketStore{
+		logger:  zerolog.Nop(),
+		buckets: make(map[int64]BucketStats),
+		dirty:   make(map[int64]bool),
+	}
+}
+
+// openBucketStore loads the buckets persisted in db.
+func openBucketStore(db *buntdb.DB, logger zerolog.Logger) (*BucketStore, error) {
+	b := NewBucketStore()
+	b.db = db
+	b.logger = logger
+
+	err := db.View(func(tx *buntdb.Tx) error {
+		var decodeErr error
+		err := tx.AscendKeys(kindBucket+":*", func(key, value string) bool {
+			bucket, err := strconv.ParseInt(strings.TrimPrefix(key, kindBucket+":"), 10, 64)
+			if err != nil {
+				decodeErr = fmt.Errorf("invalid bucket key %s: %w", key, err)
+				return false
+			}
+			var stats BucketStats
+			if err := json.Unmarshal([]byte(value), &stats); err != nil {
+				decodeErr = fmt.Errorf("invalid bucket %s: %w", key, err)
+				return false
+			}
+			b.buckets[bucket] = stats
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load analytics buckets: %w", err)
+	}
+
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.flushLoop(bucketFlushInterval)
+	return b, nil
+}
+
+// flushLoop writes changed buckets through to the database every interval
+// until Close.
+func (b *BucketStore) flushLoop(interval time.Duration) {
+	defer close(b.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Flush(); err != nil {
+				b.logger.Error().Err(err).Msg("Failed to persist analytics buckets")
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// Close stops the background flush and writes out the changed buckets.
+func (b *BucketStore) Close() error {
+	if b.stop != nil {
+		close(b.stop)
+		<-b.done
+		b.stop = nil
+	}
+	return b.Flush()
+}
+
+// Record adds count to the event's counter in the current hour's bucket.
+func (b *BucketStore) Record(event EventType, count int64) {
+	bucket := time.Now().Unix() / int64(bucketSpan/time.Second)
+
+	b.mu.Lock()
+	stats, ok := b.buckets[bucket]
+	if !ok {
+		stats.Start = time.Unix(bucket*int64(bucketSpan/time.Second), 0).UTC()
+	}
+	switch event {
+	case HitEvent:
+		stats.Hits += count
+	case MissEvent:
+		stats.Misses += count
+	case TokensSavedEvent:
+		stats.TokensSaved += count
+	}
+	b.buckets[bucket] = stats
+	b.dirty[bucket] = true
+	b.mu.Unlock()
+}
+
+// RecordHit counts a cache hit that saved tokensSaved Claude tokens.
+func (b *BucketStore) RecordHit(tokensSaved int64) {
+	b.Record(HitEvent, 1)
+	if tokensSaved > 0 {
+		b.Record(TokensSavedEvent, tokensSaved)
+	}
+}
+
+// RecordMiss counts a cache miss.
+func (b *BucketStore) RecordMiss() {
+	b.Record(MissEvent, 1)
+}
+
+// GetRange returns the buckets that have events and overlap from through
+// to, oldest first. A zero bound is open.
+func (b *BucketStore) GetRange(from, to time.Time) []BucketStats {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	result := make([]BucketStats, 0, len(b.buckets))
+	for _, stats := range b.buckets {
+		if !from.IsZero() && !stats.Start.Add(bucketSpan).After(from) {
+			continue
+		}
+		if !to.IsZero() && stats.Start.After(to) {
+			continue
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Start.Before(result[j].Start) })
+	return result
+}
+
+// Flush writes the buckets changed since the last flush to the database.
+// It does nothing for an in-memory store.
+func (b *BucketStore) Flush() error {
+	if b.db == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	changed := make(map[int64]BucketStats, len(b.dirty))
+	for bucket := range b.dirty {
+		changed[bucket] = b.buckets[bucket]
+	}
+	b.dirty = make(map[int64]bool)
+	b.mu.Unlock()
+
+	if len(changed) == 0 {
+		return nil
+	}
+
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		for bucket, stats := range changed {
+			data, err := json.Marshal(stats)
+			if err != nil {
+				return err
+			}
+			if _, _, err := tx.Set(fmt.Sprintf("%s:%d", kindBucket, bucket), string(data), nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		// Try again on the next flush
+		b.mu.Lock()
+		for bucket := range changed {
+			b.dirty[bucket] = true
+		}
+		b.mu.Unlock()
+		return fmt.Errorf("failed to persist analytics buckets: %w", err)
+	}
+	return nil
+}
+
+// PruneBefore deletes the buckets that end before cutoff and returns how
+// many it deleted.
+func (b *BucketStore) PruneBefore(cutoff time.Time) (int, error) {
+	b.mu.Lock()
+	var pruned []int64
+	for bucket, stats := range b.buckets {
+		if !stats.Start.Add(bucketSpan).After(cutoff) {
+			pruned = append(pruned, bucket)
+			delete(b.buckets, bucket)
+			delete(b.dirty, bucket)
+		}
+	}
+	b.mu.Unlock()
+
+	if b.db == nil || len(pruned) == 0 {
+		return len(pruned), nil
+	}
+
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		for _, bucket := range pruned {
+			if _, err := tx.Delete(fmt.Sprintf("%s:%d", kindBucket, bucket)); err != nil && err != buntdb.ErrNotFound {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune analytics buckets: %w", err)
+	}
+	return len(pruned), nil
+}
@@ -0,0 +1,156 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/buntdb"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func TestBucketStoreRecord(t *testing.T) {
+	b := NewBucketStore()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.RecordHit(100)
+			b.RecordMiss()
+		}()
+	}
+	wg.Wait()
+	b.RecordHit(0)
+
+	buckets := b.GetRange(time.Now().Add(-time.Hour), time.Now())
+	require.Len(t, buckets, 1)
+	assert.Equal(t, int64(11), buckets[0].Hits)
+	assert.Equal(t, int64(10), buckets[0].Misses)
+	assert.Equal(t, int64(1000), buckets[0].TokensSaved)
+	assert.Equal(t, time.Now().UTC().Truncate(time.Hour), buckets[0].Start)
+}
+
+func TestBucketStoreGetRange(t *testing.T) {
+	b := NewBucketStore()
+	hour := time.Now().Unix() / 3600
+	for _, offset := range []int64{0, -1, -5, -48} {
+		b.buckets[hour+offset] = BucketStats{Start: time.Unix((hour+offset)*3600, 0).UTC(), Hits: -offset}
+	}
+
+	now := time.Now()
+	tests := []struct {
+		name     string
+		from, to time.Time
+		hits     []int64
+	}{
+		{name: "open", hits: []int64{48, 5, 1, 0}},
+		{name: "last day", from: now.Add(-24 * time.Hour), to: now, hits: []int64{5, 1, 0}},
+		{name: "partial bucket", from: now.Add(-90 * time.Minute), to: now.Add(-time.Hour), hits: []int64{1}},
+		{name: "empty", from: now.Add(-30 * time.Hour), to: now.Add(-25 * time.Hour), hits: []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := []int64{}
+			for _, bucket := range b.GetRange(tt.from, tt.to) {
+				hits = append(hits, bucket.Hits)
+			}
+			assert.Equal(t, tt.hits, hits)
+		})
+	}
+}
+
+func TestBucketStorePersists(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	path := filepath.Join(t.TempDir(), "analytics.db")
+
+	store, err := Open(path, logger)
+	require.NoError(t, err)
+	store.Buckets().RecordHit(250)
+	store.Buckets().RecordMiss()
+	require.NoError(t, store.Close())
+
+	store, err = Open(path, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+
+	buckets := store.Buckets().GetRange(time.Time{}, time.Time{})
+	require.Len(t, buckets, 1)
+	assert.Equal(t, int64(1), buckets[0].Hits)
+	assert.Equal(t, int64(1), buckets[0].Misses)
+	assert.Equal(t, int64(250), buckets[0].TokensSaved)
+
+	// Buckets are not analytics events
+	tokens, cacheEvents, audits, err := store.Count()
+	require.NoError(t, err)
+	assert.Zero(t, tokens+cacheEvents+audits)
+}
+
+func TestBucketStoreBackgroundFlush(t *testing.T) {
+	db, err := buntdb.Open(":memory:")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	b := NewBucketStore()
+	b.db = db
+	b.stop = make(chan struct{})
+	b.done = make(chan struct{})
+	go b.flushLoop(10 * time.Millisecond)
+	defer func() {
+		require.NoError(t, b.Close())
+	}()
+
+	b.RecordHit(100)
+
+	key := fmt.Sprintf("%s:%d", kindBucket, time.Now().Unix()/3600)
+	assert.Eventually(t, func() bool {
+		return db.View(func(tx *buntdb.Tx) error {
+			_, err := tx.Get(key)
+			return err
+		}) == nil
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestPruneBuckets(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	path := filepath.Join(t.TempDir(), "analytics.db")
+
+	store, err := Open(path, logger)
+	require.NoError(t, err)
+
+	hour := time.Now().Unix() / 3600
+	b := store.Buckets()
+	for _, offset := range []int64{0, -24, -40 * 24, -90 * 24} {
+		b.buckets[hour+offset] = BucketStats{Start: time.Unix((hour+offset)*3600, 0).UTC(), Hits: 1}
+		b.dirty[hour+offset] = true
+	}
+	require.NoError(t, b.Flush())
+
+	result, err := store.Prune(context.Background(), config.RetentionPolicy{CacheEventDays: 30})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.BucketsDeleted)
+	assert.Len(t, b.GetRange(time.Time{}, time.Time{}), 2)
+	require.NoError(t, store.Close())
+
+	// Pruned buckets are gone from the database too
+	store, err = Open(path, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	assert.Len(t, store.Buckets().GetRange(time.Time{}, time.Time{}), 2)
+}
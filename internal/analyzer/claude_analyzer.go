@@ -4,17 +4,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
 	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
 )
 
+// MaxOutputTokens caps the length of Claude's response to an analysis
+// request, so it bounds the output tokens an analysis can be billed for.
+const MaxOutputTokens = 4096
+
 // ClaudeAnalyzer implements the Analyzer interface using Claude API
 type ClaudeAnalyzer struct {
-	client  *claude.Client
-	logger  zerolog.Logger
-	version string
+	client   *claude.Client
+	logger   zerolog.Logger
+	version  string
+	features config.FeatureChecker
+
+	// cache holds analyses by commit; nil disables reuse
+	cache         *cache.Manager
+	apiCallsSaved atomic.Int64
 }
 
 // NewClaudeAnalyzer creates a new Claude-based analyzer
@@ -26,38 +40,186 @@ func NewClaudeAnalyzer(apiKey, model string, logger zerolog.Logger) *ClaudeAnaly
 	}
 }
 
+// NewClaudeAnalyzerWithClient creates a Claude-based analyzer using an existing client
+func NewClaudeAnalyzerWithClient(client *claude.Client, logger zerolog.Logger) *ClaudeAnalyzer {
+	return &ClaudeAnalyzer{
+		client:  client,
+		logger:  logger,
+		version: "1.0.0",
+	}
+}
+
+// CircuitBreaker returns the circuit breaker of the analyzer's Claude client
+func (a *ClaudeAnalyzer) CircuitBreaker() *claude.CircuitBreaker {
+	return a.client.CircuitBreaker()
+}
+
+// SetFeatureFlags makes the analyzer honour runtime feature flags
+func (a *ClaudeAnalyzer) SetFeatureFlags(features config.FeatureChecker) {
+	a.features = features
+}
+
 // AnalyzeCode analyzes a single SDK's code
 func (a *ClaudeAnalyzer) AnalyzeCode(ctx context.Context, request AnalysisRequest) (*SDKAnalysis, error) {
 	startTime := time.Now()
+	cacheKey := commitCacheKey(request)
+	if analysis := a.cachedAnalysis(cacheKey); analysis != nil {
+		a.logger.Info().
+			Str("sdk", request.SDKName).
+			Str("commit", request.CommitHash).
+			Msg("Reusing cached analysis of commit")
+		return analysis, nil
+	}
 
-	// Generate analysis prompt
-	prompt := claude.SDKAnalysisPrompt(request.SDKName, request.Version, request.Code)
+	messages, err := a.analysisMessages(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 
-	messages := []claude.Message{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	// Send request to Claude
+	ctx = claude.WithSDKName(ctx, request.SDKName)
+	response, err := a.client.SendMessage(ctx, messages, "", MaxOutputTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze SDK: %w", err)
 	}
 
-	// Count tokens before sending
-	tokenCount, err := a.client.CountTokens(ctx, messages)
+	analysis, err := a.parseAnalysis(request, response, startTime)
 	if err != nil {
-		a.logger.Warn().Err(err).Msg("Failed to count tokens")
+		return nil, err
+	}
+	a.cacheAnalysis(cacheKey, analysis)
+	return analysis, nil
+}
+
+// AnalysisStreamEvent is a step of a streamed analysis. Partial holds the
+// analysis JSON received so far; the last event carries either the parsed
+// Analysis or Err.
+type AnalysisStreamEvent struct {
+	Partial  string
+	Analysis *SDKAnalysis
+	Err      error
+}
+
+// AnalyzeCodeStream analyzes a single SDK's code like AnalyzeCode, but
+// streams the analysis JSON back as Claude writes it. The channel is
+// closed after the final event or when ctx is cancelled.
+func (a *ClaudeAnalyzer) AnalyzeCodeStream(ctx context.Context, request AnalysisRequest) (<-chan AnalysisStreamEvent, error) {
+	startTime := time.Now()
+	cacheKey := commitCacheKey(request)
+	if analysis := a.cachedAnalysis(cacheKey); analysis != nil {
+		events := make(chan AnalysisStreamEvent, 1)
+		events <- AnalysisStreamEvent{Analysis: analysis}
+		close(events)
+		return events, nil
 	}
 
-	a.logger.Info().
-		Str("sdk", request.SDKName).
-		Str("version", request.Version).
-		Int("estimated_tokens", tokenCount).
-		Msg("Analyzing SDK with Claude")
+	messages, err := a.analysisMessages(ctx, request)
+	if err != nil {
+		return nil, err
+	}
 
-	// Send request to Claude
-	response, err := a.client.SendMessage(ctx, messages, "", 4096)
+	stream, err := a.client.SendMessageStream(ctx, messages, "", MaxOutputTokens)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze SDK: %w", err)
 	}
 
+	events := make(chan AnalysisStreamEvent)
+	go func() {
+		defer close(events)
+
+		emit := func(event AnalysisStreamEvent) bool {
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		var partial strings.Builder
+		for event := range stream {
+			switch {
+			case event.Err != nil:
+				emit(AnalysisStreamEvent{Partial: partial.String(), Err: fmt.Errorf("failed to analyze SDK: %w", event.Err)})
+				return
+			case event.Delta != "":
+				partial.WriteString(event.Delta)
+				if !emit(AnalysisStreamEvent{Partial: partial.String()}) {
+					return
+				}
+			case event.Response != nil:
+				analysis, err := a.parseAnalysis(request, event.Response, startTime)
+				if err == nil {
+					a.cacheAnalysis(cacheKey, analysis)
+				}
+				emit(AnalysisStreamEvent{Partial: partial.String(), Analysis: analysis, Err: err})
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// analysisMessages builds the prompt for request, asking for an update of
+// request.Previous when it is set, and logs the analysis.
+func (a *ClaudeAnalyzer) analysisMessages(ctx context.Context, request AnalysisRequest) ([]claude.Message, error) {
+	messages, err := promptMessages(request, a.logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count tokens before sending
+	event := a.logger.Info().
+		Str("sdk", request.SDKName).
+		Str("version", request.Version).
+		Bool("incremental", request.Previous != nil).
+		Bool("prompt_override", request.PromptOverride != "")
+	if config.FeatureEnabled(a.features, config.FlagTokenEstimation) {
+		tokenCount, err := a.client.CountTokens(ctx, messages)
+		if err != nil {
+			a.logger.Warn().Err(err).Msg("Failed to count tokens")
+		}
+		event = event.Int("estimated_tokens", tokenCount)
+	}
+	event.Msg("Analyzing SDK with Claude")
+
+	return messages, nil
+}
+
+// promptMessages renders the prompt for request: its PromptOverride if set,
+// else the incremental or full analysis prompt.
+func promptMessages(request AnalysisRequest, logger zerolog.Logger) ([]claude.Message, error) {
+	var previousJSON []byte
+	if request.Previous != nil {
+		var err error
+		previousJSON, err = json.Marshal(request.Previous)
+		if err != nil {
+			logger.Warn().Err(err).Str("sdk", request.SDKName).Msg("Failed to encode previous analysis, analyzing from scratch")
+			previousJSON = nil
+		}
+	}
+
+	if request.PromptOverride != "" {
+		t, err := claude.ParsePromptTemplate("prompt_override", "request", request.PromptOverride)
+		if err != nil {
+			return nil, err
+		}
+		return claude.AnalysisPrompt(t, claude.PromptData{
+			SDKName:          request.SDKName,
+			Version:          request.Version,
+			Code:             claude.FormatCodeFiles(request.Code),
+			PreviousAnalysis: string(previousJSON),
+		})
+	}
+
+	if previousJSON != nil {
+		return claude.IncrementalAnalysisPrompt(request.SDKName, request.Version, request.Code, string(previousJSON))
+	}
+	return claude.SDKAnalysisPrompt(request.SDKName, request.Version, request.Code)
+}
+
+// parseAnalysis decodes and validates the analysis in Claude's response.
+func (a *ClaudeAnalyzer) parseAnalysis(request AnalysisRequest, response *claude.Response, startTime time.Time) (*SDKAnalysis, error) {
 	// Extract JSON from response
 	if len(response.Content) == 0 {
 		return nil, fmt.Errorf("empty response from Claude")
@@ -71,12 +233,31 @@ func (a *ClaudeAnalyzer) AnalyzeCode(ctx context.Context, request AnalysisReques
 		// Try to extract JSON from markdown code block
 		analysisJSON = extractJSONFromMarkdown(analysisJSON)
 		if err := json.Unmarshal([]byte(analysisJSON), &analysis); err != nil {
+			// Report fields with the wrong type rather than the decoder error
+			if problems := validateAnalysisJSON([]byte(analysisJSON)); HasValidationErrors(problems) {
+				return nil, fmt.Errorf("invalid analysis: %w", validationFailure(problems))
+			}
 			return nil, fmt.Errorf("failed to parse analysis: %w", err)
 		}
 	}
 
+	// Reject analyses that do not match the schema before they are cached
+	problems := ValidateSDKAnalysis(&analysis)
+	if HasValidationErrors(problems) {
+		return nil, fmt.Errorf("invalid analysis: %w", validationFailure(problems))
+	}
+	for _, p := range problems {
+		a.logger.Warn().
+			Str("sdk", request.SDKName).
+			Str("field", p.Field).
+			Msg(p.Message)
+		analysis.ValidationErrors = append(analysis.ValidationErrors, p.Error())
+	}
+
 	// Add metadata
-	analysis.TokensUsed = response.Usage.InputTokens + response.Usage.OutputTokens
+	analysis.TokensUsed = response.Usage.TotalTokens()
+	analysis.TokensSavedByCache = response.Usage.CacheReadInputTokens
+	analysis.PassCount = 1
 	analysis.AnalyzedAt = time.Now()
 	analysis.AnalysisVersion = a.version
 
@@ -85,64 +266,18 @@ func (a *ClaudeAnalyzer) AnalyzeCode(ctx context.Context, request AnalysisReques
 		Str("sdk", request.SDKName).
 		Dur("duration", duration).
 		Int("tokens_used", analysis.TokensUsed).
+		Int("tokens_saved_by_cache", analysis.TokensSavedByCache).
 		Msg("SDK analysis completed")
 
 	return &analysis, nil
 }
 
-// BatchAnalyze analyzes multiple SDKs in batch for cost optimization
-func (a *ClaudeAnalyzer) BatchAnalyze(ctx context.Context, requests []AnalysisRequest) (*BatchAnalysisResult, error) {
-	// For now, implement sequential analysis
-	// TODO: Implement actual batch API when available
-
-	result := &BatchAnalysisResult{
-		JobID:   generateJobID(),
-		Status:  "processing",
-		Results: make(map[string]*SDKAnalysis),
-		Errors:  make(map[string]string),
-	}
-
-	totalTokens := 0
-
-	for _, req := range requests {
-		analysis, err := a.AnalyzeCode(ctx, req)
-		if err != nil {
-			result.Errors[req.SDKName] = err.Error()
-			a.logger.Error().
-				Err(err).
-				Str("sdk", req.SDKName).
-				Msg("Failed to analyze SDK in batch")
-			continue
-		}
-
-		result.Results[req.SDKName] = analysis
-		totalTokens += analysis.TokensUsed
-	}
-
-	now := time.Now()
-	result.Status = "completed"
-	result.TotalTokens = totalTokens
-	result.CompletedAt = &now
-
-	return result, nil
-}
-
-// GetBatchStatus checks the status of a batch job
-func (a *ClaudeAnalyzer) GetBatchStatus(ctx context.Context, jobID string) (*BatchAnalysisResult, error) {
-	// TODO: Implement when batch API is available
-	return nil, fmt.Errorf("batch status checking not yet implemented")
-}
-
 // CountTokens estimates token usage before sending request
 func (a *ClaudeAnalyzer) CountTokens(ctx context.Context, request AnalysisRequest) (int, error) {
-	prompt := claude.SDKAnalysisPrompt(request.SDKName, request.Version, request.Code)
-	messages := []claude.Message{
-		{
-			Role:    "user",
-			Content: prompt,
-		},
+	messages, err := promptMessages(request, a.logger)
+	if err != nil {
+		return 0, err
 	}
-
 	return a.client.CountTokens(ctx, messages)
 }
 
@@ -200,10 +335,6 @@ func findString(s, substr string) int {
 	return -1
 }
 
-func generateJobID() string {
-	return fmt.Sprintf("job_%d", time.Now().UnixNano())
-}
-
 func trimString(s string) string {
 	// Trim leading whitespace
 	start := 0
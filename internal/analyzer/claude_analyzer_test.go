@@ -3,15 +3,18 @@ package analyzer
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/ryanrussell/claude-cache-service/internal/claude"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
 )
 
 func TestAnalyzeCode(t *testing.T) {
@@ -45,29 +48,23 @@ func TestAnalyzeCode(t *testing.T) {
 		},
 	}
 
-	// Create test server
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Return mock Claude response
-		analysisJSON, _ := json.Marshal(mockAnalysis)
-		response := claude.Response{
-			ID:   "msg_123",
-			Type: "message",
-			Role: "assistant",
-			Content: []claude.ContentBlock{
-				{Type: "text", Text: string(analysisJSON)},
-			},
-			Usage: claude.Usage{
-				InputTokens:  100,
-				OutputTokens: 200,
-			},
-		}
+	analysisJSON, err := json.Marshal(mockAnalysis)
+	require.NoError(t, err)
 
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			t.Fatalf("Failed to encode response: %v", err)
-		}
-	}))
-	defer server.Close()
+	// Create test server
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.Response{
+		ID:   "msg_123",
+		Type: "message",
+		Role: "assistant",
+		Content: []mockserver.ContentBlock{
+			{Type: "text", Text: string(analysisJSON)},
+		},
+		Usage: mockserver.Usage{
+			InputTokens:  100,
+			OutputTokens: 200,
+		},
+	})
 
 	// Create analyzer with mock client
 	logger := zerolog.Nop()
@@ -126,29 +123,11 @@ func TestAnalyzeCodeWithMarkdown(t *testing.T) {
 	}`
 
 	// Create test server that returns JSON in markdown
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		response := claude.Response{
-			ID:   "msg_123",
-			Type: "message",
-			Role: "assistant",
-			Content: []claude.ContentBlock{
-				{
-					Type: "text",
-					Text: "Here's the analysis:\n\n```json\n" + mockAnalysisJSON + "\n```\n\nThe SDK uses modern patterns.",
-				},
-			},
-			Usage: claude.Usage{
-				InputTokens:  50,
-				OutputTokens: 100,
-			},
-		}
-
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			t.Fatalf("Failed to encode response: %v", err)
-		}
-	}))
-	defer server.Close()
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse(
+		"Here's the analysis:\n\n```json\n"+mockAnalysisJSON+"\n```\n\nThe SDK uses modern patterns.",
+		50, 100,
+	))
 
 	// Create analyzer
 	logger := zerolog.Nop()
@@ -179,46 +158,111 @@ func TestAnalyzeCodeWithMarkdown(t *testing.T) {
 	assert.Equal(t, "8", analysis.ProtocolVersion)
 }
 
-func TestBatchAnalyze(t *testing.T) {
-	callCount := 0
+func TestAnalyzeCodePromptCaching(t *testing.T) {
+	analysisJSON, err := json.Marshal(SDKAnalysis{Language: "go", ProtocolVersion: "7"})
+	require.NoError(t, err)
 
-	// Create test server
+	server := mockserver.NewMockServer(t)
+	server.EnablePromptCaching()
+	server.SetResponse(mockserver.TextResponse(string(analysisJSON), 100, 200))
+
+	logger := zerolog.Nop()
+	client := claude.NewClient("test-key", "claude-3-opus", logger)
+	client.BaseURL = server.URL
+	analyzer := NewClaudeAnalyzerWithClient(client, logger)
+
+	request := AnalysisRequest{
+		SDKName: "sentry-go",
+		Version: "1.0.0",
+		Code:    map[string]string{"main.go": "package main"},
+	}
+
+	// The first request writes the instructions to the cache
+	first, err := analyzer.AnalyzeCode(context.Background(), request)
+	require.NoError(t, err)
+	assert.Zero(t, first.TokensSavedByCache)
+	assert.Greater(t, first.TokensUsed, 300)
+
+	// The second reads them back
+	second, err := analyzer.AnalyzeCode(context.Background(), request)
+	require.NoError(t, err)
+	assert.Positive(t, second.TokensSavedByCache)
+	assert.Equal(t, 300+second.TokensSavedByCache, second.TokensUsed)
+}
+
+func TestAnalyzeCodePromptOverride(t *testing.T) {
+	analysisJSON, err := json.Marshal(SDKAnalysis{Language: "go", ProtocolVersion: "7"})
+	require.NoError(t, err)
+
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse(string(analysisJSON), 100, 200))
+
+	logger := zerolog.Nop()
+	client := claude.NewClient("test-key", "claude-3-opus", logger)
+	client.BaseURL = server.URL
+	analyzer := NewClaudeAnalyzerWithClient(client, logger)
+
+	request := AnalysisRequest{
+		SDKName:        "sentry-go",
+		Version:        "0.29.1",
+		Code:           map[string]string{"main.go": "package main"},
+		PromptOverride: "List the transports of {{.SDKName}} {{.Version}}:\n\n{{.Code}}",
+	}
+	_, err = analyzer.AnalyzeCode(context.Background(), request)
+	require.NoError(t, err)
+
+	messages := server.LastRequest().Messages
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0].Content, "expert SDK analyzer")
+	assert.Equal(t, "List the transports of sentry-go 0.29.1:\n\nFile: main.go\n```\npackage main\n```", messages[1].Content)
+
+	// An invalid override fails before calling Claude
+	request.PromptOverride = "{{.SDKName"
+	_, err = analyzer.AnalyzeCode(context.Background(), request)
+	assert.Error(t, err)
+	assert.Equal(t, 1, server.CallCount())
+}
+
+func TestAnalyzeCodeStream(t *testing.T) {
+	analysisJSON, err := json.Marshal(SDKAnalysis{
+		Language:        "ruby",
+		EnvelopeFormat:  "JSON envelope",
+		Transport:       TransportDetails{Type: "http"},
+		ProtocolVersion: "7",
+	})
+	require.NoError(t, err)
+
+	// Stream the analysis in three chunks
+	text := string(analysisJSON)
+	chunks := []string{text[:10], text[10:40], text[40:]}
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-
-		// Return different analysis based on call count
-		var analysis SDKAnalysis
-		if callCount == 1 {
-			analysis = SDKAnalysis{
-				Language:        "python",
-				ProtocolVersion: "7",
-			}
-		} else {
-			analysis = SDKAnalysis{
-				Language:        "javascript",
-				ProtocolVersion: "7",
-			}
+		w.Header().Set("Content-Type", "text/event-stream")
+		frames := []interface{}{
+			map[string]interface{}{"type": "message_start", "message": map[string]interface{}{
+				"id": "msg_stream", "content": []interface{}{}, "usage": map[string]int{"input_tokens": 100},
+			}},
 		}
-
-		analysisJSON, _ := json.Marshal(analysis)
-		response := claude.Response{
-			Content: []claude.ContentBlock{
-				{Type: "text", Text: string(analysisJSON)},
-			},
-			Usage: claude.Usage{
-				InputTokens:  100,
-				OutputTokens: 100,
-			},
+		for _, chunk := range chunks {
+			frames = append(frames, map[string]interface{}{"type": "content_block_delta", "index": 0,
+				"delta": map[string]string{"type": "text_delta", "text": chunk}})
 		}
-
-		w.WriteHeader(http.StatusOK)
-		if err := json.NewEncoder(w).Encode(response); err != nil {
-			t.Fatalf("Failed to encode response: %v", err)
+		frames = append(frames,
+			map[string]interface{}{"type": "message_delta", "delta": map[string]string{"stop_reason": "end_turn"},
+				"usage": map[string]int{"output_tokens": 50}},
+			map[string]interface{}{"type": "message_stop"},
+		)
+		for _, frame := range frames {
+			data, err := json.Marshal(frame)
+			if err != nil {
+				t.Errorf("failed to encode frame: %v", err)
+				return
+			}
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+			w.(http.Flusher).Flush()
 		}
 	}))
 	defer server.Close()
 
-	// Create analyzer
 	logger := zerolog.Nop()
 	client := claude.NewClient("test-key", "claude-3-opus", logger)
 	client.BaseURL = server.URL
@@ -229,47 +273,39 @@ func TestBatchAnalyze(t *testing.T) {
 		version: "1.0.0",
 	}
 
-	// Test batch analyze
-	ctx := context.Background()
-	requests := []AnalysisRequest{
-		{
-			SDKName: "sentry-python",
-			Version: "1.0.0",
-			Code:    map[string]string{"main.py": "import sentry_sdk"},
-		},
-		{
-			SDKName: "sentry-javascript",
-			Version: "2.0.0",
-			Code:    map[string]string{"index.js": "const Sentry = require('@sentry/node')"},
-		},
+	events, err := analyzer.AnalyzeCodeStream(context.Background(), AnalysisRequest{
+		SDKName: "sentry-ruby",
+		Version: "5.0.0",
+		Code:    map[string]string{"client.rb": "class Client; end"},
+	})
+	require.NoError(t, err)
+
+	var collected []AnalysisStreamEvent
+	for event := range events {
+		collected = append(collected, event)
 	}
 
-	result, err := analyzer.BatchAnalyze(ctx, requests)
+	require.Len(t, collected, 4)
+	assert.Equal(t, chunks[0], collected[0].Partial)
+	assert.Equal(t, chunks[0]+chunks[1], collected[1].Partial)
+	assert.Equal(t, text, collected[2].Partial)
+	for _, event := range collected[:3] {
+		assert.Nil(t, event.Analysis)
+		assert.NoError(t, event.Err)
+	}
 
-	require.NoError(t, err)
-	assert.Equal(t, "completed", result.Status)
-	assert.Len(t, result.Results, 2)
-	assert.Len(t, result.Errors, 0)
-	assert.Equal(t, 400, result.TotalTokens) // 200 + 200
-	assert.NotNil(t, result.CompletedAt)
-
-	// Check individual results
-	pythonAnalysis := result.Results["sentry-python"]
-	assert.NotNil(t, pythonAnalysis)
-	assert.Equal(t, "python", pythonAnalysis.Language)
-
-	jsAnalysis := result.Results["sentry-javascript"]
-	assert.NotNil(t, jsAnalysis)
-	assert.Equal(t, "javascript", jsAnalysis.Language)
+	final := collected[3]
+	require.NoError(t, final.Err)
+	require.NotNil(t, final.Analysis)
+	assert.Equal(t, "ruby", final.Analysis.Language)
+	assert.Equal(t, "http", final.Analysis.Transport.Type)
+	assert.Equal(t, 150, final.Analysis.TokensUsed)
+	assert.Equal(t, "1.0.0", final.Analysis.AnalysisVersion)
 }
 
 func TestCountTokens(t *testing.T) {
 	// Create test server
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Should not be called for token counting
-		t.Fatal("Server should not be called for token counting")
-	}))
-	defer server.Close()
+	server := mockserver.NewMockServer(t)
 
 	// Create analyzer
 	logger := zerolog.Nop()
@@ -297,6 +333,7 @@ func TestCountTokens(t *testing.T) {
 
 	require.NoError(t, err)
 	assert.Greater(t, count, 100) // Should include prompt template
+	assert.Equal(t, 0, server.CallCount(), "Server should not be called for token counting")
 }
 
 func TestExtractJSONFromMarkdown(t *testing.T) {
@@ -0,0 +1,179 @@
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+)
+
+// Polling intervals for batch jobs. The interval doubles after each poll
+// up to the maximum.
+var (
+	batchPollInterval    = 10 * time.Second
+	maxBatchPollInterval = 5 * time.Minute
+)
+
+// batchCancelTimeout bounds the request that cancels an abandoned batch
+const batchCancelTimeout = 30 * time.Second
+
+// BatchAnalyze analyzes multiple SDKs in one Claude batch job, which costs
+// less than individual requests. It polls the job until it ends. If ctx is
+// cancelled first, the job is cancelled too.
+func (a *ClaudeAnalyzer) BatchAnalyze(ctx context.Context, requests []AnalysisRequest) (*BatchAnalysisResult, error) {
+	startTime := time.Now()
+
+	batchRequests := make([]claude.BatchRequest, len(requests))
+	byID := make(map[string]AnalysisRequest, len(requests))
+	for i, req := range requests {
+		messages, err := a.analysisMessages(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build prompt for %s: %w", req.SDKName, err)
+		}
+		batchRequests[i] = a.client.NewBatchRequest(req.SDKName, messages, "", MaxOutputTokens)
+		byID[req.SDKName] = req
+	}
+
+	batch, err := a.client.CreateBatch(ctx, batchRequests)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+
+	batchID := batch.ID
+	delay := batchPollInterval
+	for batch.ProcessingStatus != "ended" {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			a.cancelBatch(batchID)
+			return nil, ctx.Err()
+		}
+		delay = min(delay*2, maxBatchPollInterval)
+
+		batch, err = a.client.GetBatchStatus(ctx, batchID)
+		if err != nil {
+			if ctx.Err() != nil {
+				a.cancelBatch(batchID)
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to poll batch %s: %w", batchID, err)
+		}
+	}
+
+	result, err := a.batchResult(ctx, batch, byID, startTime)
+	if err != nil {
+		return nil, err
+	}
+
+	a.logger.Info().
+		Str("batch_id", batch.ID).
+		Int("succeeded", len(result.Results)).
+		Int("failed", len(result.Errors)).
+		Int("total_tokens", result.TotalTokens).
+		Dur("duration", time.Since(startTime)).
+		Msg("Batch analysis completed")
+
+	return result, nil
+}
+
+// GetBatchStatus returns the current state of a batch job, with its
+// results once it has ended.
+func (a *ClaudeAnalyzer) GetBatchStatus(ctx context.Context, jobID string) (*BatchAnalysisResult, error) {
+	batch, err := a.client.GetBatchStatus(ctx, jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch status: %w", err)
+	}
+
+	if batch.ProcessingStatus != "ended" {
+		return &BatchAnalysisResult{
+			JobID:   batch.ID,
+			Status:  batchStatus(batch.ProcessingStatus),
+			Results: make(map[string]*SDKAnalysis),
+			Errors:  make(map[string]string),
+		}, nil
+	}
+	return a.batchResult(ctx, batch, nil, time.Now())
+}
+
+// batchResult fetches and parses the results of an ended batch. Results
+// are matched to requests by custom ID, which is the SDK name; any request
+// without a result is reported as an error.
+func (a *ClaudeAnalyzer) batchResult(ctx context.Context, batch *claude.BatchResponse, requests map[string]AnalysisRequest, startTime time.Time) (*BatchAnalysisResult, error) {
+	results, err := a.client.GetBatchResults(ctx, batch.ResultsURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch batch results: %w", err)
+	}
+
+	result := &BatchAnalysisResult{
+		JobID:       batch.ID,
+		Status:      "completed",
+		Results:     make(map[string]*SDKAnalysis),
+		Errors:      make(map[string]string),
+		CompletedAt: batch.CompletedAt,
+	}
+	if result.CompletedAt == nil {
+		now := time.Now()
+		result.CompletedAt = &now
+	}
+
+	for _, r := range results {
+		request, ok := requests[r.CustomID]
+		if !ok {
+			request = AnalysisRequest{SDKName: r.CustomID}
+		}
+
+		var analysis *SDKAnalysis
+		switch {
+		case r.Error != nil:
+			err = fmt.Errorf("%s: %s", r.Error.Type, r.Error.Message)
+		case r.Response == nil:
+			err = fmt.Errorf("empty response from Claude")
+		default:
+			analysis, err = a.parseAnalysis(request, r.Response, startTime)
+		}
+		if err != nil {
+			result.Errors[r.CustomID] = err.Error()
+			a.logger.Error().
+				Err(err).
+				Str("sdk", r.CustomID).
+				Msg("Failed to analyze SDK in batch")
+			continue
+		}
+
+		result.Results[r.CustomID] = analysis
+		result.TotalTokens += analysis.TokensUsed
+	}
+
+	for sdkName := range requests {
+		_, succeeded := result.Results[sdkName]
+		_, failed := result.Errors[sdkName]
+		if !succeeded && !failed {
+			result.Errors[sdkName] = "no result returned for SDK"
+		}
+	}
+
+	return result, nil
+}
+
+// cancelBatch cancels a batch whose caller has gone away. It uses its own
+// context because the caller's has already been cancelled.
+func (a *ClaudeAnalyzer) cancelBatch(batchID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), batchCancelTimeout)
+	defer cancel()
+
+	if err := a.client.CancelBatch(ctx, batchID); err != nil {
+		a.logger.Error().Err(err).Str("batch_id", batchID).Msg("Failed to cancel batch")
+	}
+}
+
+// batchStatus maps the processing status of a running batch to a
+// BatchAnalysisResult status.
+func batchStatus(processingStatus string) string {
+	switch processingStatus {
+	case "canceling":
+		return "cancelling"
+	default:
+		return "processing"
+	}
+}
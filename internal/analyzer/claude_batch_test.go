@@ -0,0 +1,207 @@
+package analyzer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+)
+
+// fakeBatchAPI simulates the batch lifecycle: a created batch stays in
+// progress for pollsUntilEnded status requests, then ends with one result
+// per request. SDKs listed in failing end with an error result.
+type fakeBatchAPI struct {
+	*httptest.Server
+
+	t               *testing.T
+	pollsUntilEnded int
+	failing         map[string]bool
+
+	mu        sync.Mutex
+	requests  []claude.BatchRequest
+	polls     int
+	cancelled bool
+}
+
+func newFakeBatchAPI(t *testing.T, pollsUntilEnded int) *fakeBatchAPI {
+	t.Helper()
+
+	f := &fakeBatchAPI{t: t, pollsUntilEnded: pollsUntilEnded, failing: make(map[string]bool)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Requests []claude.BatchRequest `json:"requests"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode batch: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.requests = body.Requests
+		f.mu.Unlock()
+		f.writeBatch(w, "in_progress")
+	})
+	mux.HandleFunc("GET /v1/batches/batch_123", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.polls++
+		ended := f.polls >= f.pollsUntilEnded
+		f.mu.Unlock()
+		if ended {
+			f.writeBatch(w, "ended")
+			return
+		}
+		f.writeBatch(w, "in_progress")
+	})
+	mux.HandleFunc("GET /v1/batches/batch_123/results", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		results := make([]claude.BatchResult, 0, len(f.requests))
+		for _, req := range f.requests {
+			result := claude.BatchResult{CustomID: req.CustomID}
+			if f.failing[req.CustomID] {
+				result.Error = &struct {
+					Type    string `json:"type"`
+					Message string `json:"message"`
+				}{Type: "invalid_request_error", Message: "prompt is too long"}
+			} else {
+				analysisJSON, err := json.Marshal(SDKAnalysis{Language: req.CustomID, ProtocolVersion: "7"})
+				if err != nil {
+					t.Errorf("failed to encode analysis: %v", err)
+				}
+				result.Response = &claude.Response{
+					ID:      "msg_" + req.CustomID,
+					Content: []claude.ContentBlock{{Type: "text", Text: string(analysisJSON)}},
+					Usage:   claude.Usage{InputTokens: 100, OutputTokens: 50},
+				}
+			}
+			results = append(results, result)
+		}
+		f.writeJSON(w, results)
+	})
+	mux.HandleFunc("POST /v1/batches/batch_123/cancel", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.cancelled = true
+		f.mu.Unlock()
+		f.writeBatch(w, "canceling")
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeBatchAPI) writeBatch(w http.ResponseWriter, status string) {
+	batch := claude.BatchResponse{ID: "batch_123", Type: "message_batch", ProcessingStatus: status}
+	if status == "ended" {
+		batch.ResultsURL = "/v1/batches/batch_123/results"
+	}
+	f.writeJSON(w, batch)
+}
+
+func (f *fakeBatchAPI) writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		f.t.Errorf("failed to encode response: %v", err)
+	}
+}
+
+func newBatchTestAnalyzer(t *testing.T, server *fakeBatchAPI) *ClaudeAnalyzer {
+	t.Helper()
+
+	original := batchPollInterval
+	batchPollInterval = time.Millisecond
+	t.Cleanup(func() { batchPollInterval = original })
+
+	logger := zerolog.Nop()
+	client := claude.NewClient("test-key", "claude-3-opus", logger)
+	client.BaseURL = server.URL
+	return NewClaudeAnalyzerWithClient(client, logger)
+}
+
+func TestBatchAnalyze(t *testing.T) {
+	server := newFakeBatchAPI(t, 3)
+	server.failing["sentry-ruby"] = true
+	analyzer := newBatchTestAnalyzer(t, server)
+
+	requests := []AnalysisRequest{
+		{SDKName: "sentry-python", Version: "1.0.0", Code: map[string]string{"main.py": "import sentry_sdk"}},
+		{SDKName: "sentry-javascript", Version: "2.0.0", Code: map[string]string{"index.js": "require('@sentry/node')"}},
+		{SDKName: "sentry-ruby", Version: "5.0.0", Code: map[string]string{"client.rb": "class Client; end"}},
+	}
+
+	result, err := analyzer.BatchAnalyze(context.Background(), requests)
+	require.NoError(t, err)
+
+	assert.Equal(t, "batch_123", result.JobID)
+	assert.Equal(t, "completed", result.Status)
+	assert.NotNil(t, result.CompletedAt)
+	require.Len(t, result.Results, 2)
+	assert.Equal(t, "sentry-python", result.Results["sentry-python"].Language)
+	assert.Equal(t, "sentry-javascript", result.Results["sentry-javascript"].Language)
+	assert.Contains(t, result.Errors["sentry-ruby"], "prompt is too long")
+	assert.Equal(t, 300, result.TotalTokens)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 3, server.polls)
+	assert.False(t, server.cancelled)
+	require.Len(t, server.requests, 3)
+	assert.Equal(t, "sentry-python", server.requests[0].CustomID)
+	assert.Equal(t, "/v1/messages", server.requests[0].URL)
+	assert.Equal(t, "claude-3-opus", server.requests[0].Body.Model)
+	require.Len(t, server.requests[0].Body.Messages, 2)
+	assert.Contains(t, server.requests[0].Body.Messages[1].Content, "sentry-python")
+}
+
+func TestBatchAnalyzeCancelsOnContextDone(t *testing.T) {
+	// The batch never ends
+	server := newFakeBatchAPI(t, 1<<30)
+	analyzer := newBatchTestAnalyzer(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := analyzer.BatchAnalyze(ctx, []AnalysisRequest{{SDKName: "sentry-go", Version: "1.0.0"}})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, result)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.True(t, server.cancelled)
+	assert.Positive(t, server.polls)
+}
+
+func TestClaudeGetBatchStatus(t *testing.T) {
+	server := newFakeBatchAPI(t, 2)
+	analyzer := newBatchTestAnalyzer(t, server)
+
+	// Submit the batch without waiting for it
+	_, err := analyzer.client.CreateBatch(context.Background(), []claude.BatchRequest{
+		analyzer.client.NewBatchRequest("sentry-go", []claude.Message{{Role: "user", Content: "analyze"}}, "", 4096),
+	})
+	require.NoError(t, err)
+
+	status, err := analyzer.GetBatchStatus(context.Background(), "batch_123")
+	require.NoError(t, err)
+	assert.Equal(t, "batch_123", status.JobID)
+	assert.Equal(t, "processing", status.Status)
+	assert.Empty(t, status.Results)
+
+	status, err = analyzer.GetBatchStatus(context.Background(), "batch_123")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", status.Status)
+	require.Contains(t, status.Results, "sentry-go")
+	assert.Equal(t, "sentry-go", status.Results["sentry-go"].Language)
+	assert.Equal(t, 150, status.TotalTokens)
+}
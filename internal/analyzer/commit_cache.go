@@ -0,0 +1,105 @@
+package analyzer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// commitCacheTTL is how long an analysis of a commit is reused. A commit's
+// files never change, so this only bounds how long stale prompts linger.
+const commitCacheTTL = 30 * 24 * time.Hour
+
+// ClaudeAnalyzerStats reports the work a ClaudeAnalyzer avoided
+type ClaudeAnalyzerStats struct {
+	// APICallsSaved counts analyses answered from the commit cache
+	APICallsSaved int64 `json:"api_calls_saved"`
+}
+
+// SetCache makes the analyzer reuse analyses of commits it has already
+// analyzed, stored in cacheManager, instead of calling Claude again.
+func (a *ClaudeAnalyzer) SetCache(cacheManager *cache.Manager) {
+	a.cache = cacheManager
+}
+
+// Stats returns the analyzer's counters
+func (a *ClaudeAnalyzer) Stats() ClaudeAnalyzerStats {
+	return ClaudeAnalyzerStats{APICallsSaved: a.apiCallsSaved.Load()}
+}
+
+// commitCacheKey returns the key request's analysis is cached under, or ""
+// when it cannot be cached. Multi-pass and incremental analyses send
+// different files for the same commit, so the key includes a digest of the
+// files sent as well as the commit hash. Analyses with a PromptOverride
+// are not cached.
+func commitCacheKey(request AnalysisRequest) string {
+	if request.CommitHash == "" || request.PromptOverride != "" {
+		return ""
+	}
+
+	filenames := make([]string, 0, len(request.Code))
+	for filename := range request.Code {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	h := sha256.New()
+	for _, filename := range filenames {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(filename), filename, len(request.Code[filename]), request.Code[filename])
+	}
+	if request.Previous != nil {
+		h.Write([]byte("incremental"))
+	}
+	return fmt.Sprintf("sdk:%s:commit:%s:%s", request.SDKName, request.CommitHash, hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// cachedAnalysis returns the cached analysis stored under key, or nil when
+// there is none. The copy returned used no tokens.
+func (a *ClaudeAnalyzer) cachedAnalysis(key string) *SDKAnalysis {
+	if a.cache == nil || key == "" {
+		return nil
+	}
+
+	value, err := a.cache.Get(key)
+	if err != nil {
+		if !errors.Is(err, cache.ErrNotFound) {
+			a.logger.Warn().Err(err).Str("key", key).Msg("Failed to read cached commit analysis")
+		}
+		return nil
+	}
+
+	var analysis SDKAnalysis
+	if err := json.Unmarshal([]byte(value), &analysis); err != nil {
+		a.logger.Warn().Err(err).Str("key", key).Msg("Ignoring unreadable cached commit analysis")
+		return nil
+	}
+
+	analysis.TokensUsed = 0
+	analysis.TokensSavedByCache = 0
+	analysis.AnalyzedAt = time.Now()
+	a.apiCallsSaved.Add(1)
+	return &analysis
+}
+
+// cacheAnalysis stores analysis under key for later calls for the same
+// commit. Failures are logged; the analysis itself has succeeded.
+func (a *ClaudeAnalyzer) cacheAnalysis(key string, analysis *SDKAnalysis) {
+	if a.cache == nil || key == "" {
+		return
+	}
+
+	data, err := json.Marshal(analysis)
+	if err != nil {
+		a.logger.Warn().Err(err).Str("key", key).Msg("Failed to encode commit analysis")
+		return
+	}
+	if err := a.cache.Set(key, string(data), commitCacheTTL, cache.WithTokensCached(analysis.TokensUsed)); err != nil {
+		a.logger.Warn().Err(err).Str("key", key).Msg("Failed to cache commit analysis")
+	}
+}
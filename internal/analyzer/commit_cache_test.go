@@ -0,0 +1,107 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
+)
+
+const commitCacheAnalysisJSON = `{
+	"language": "go",
+	"envelope_format": "binary format",
+	"transport": {
+		"type": "http",
+		"protocols": ["https"],
+		"retry_mechanism": "simple retry",
+		"queue_implementation": "channel-based"
+	},
+	"event_types": ["error"],
+	"error_patterns": [],
+	"integrations": [],
+	"features": ["concurrent"],
+	"protocol_version": "7",
+	"caching_patterns": []
+}`
+
+func newCachingAnalyzer(t *testing.T) (*ClaudeAnalyzer, *mockserver.MockServer) {
+	t.Helper()
+
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse(commitCacheAnalysisJSON, 100, 200))
+
+	logger := zerolog.Nop()
+	cacheManager, err := cache.NewManager(t.TempDir(), logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	client := claude.NewClient("test-key", "claude-3-opus", logger)
+	client.BaseURL = server.URL
+
+	a := NewClaudeAnalyzerWithClient(client, logger)
+	a.SetCache(cacheManager)
+	return a, server
+}
+
+func TestAnalyzeCodeReusesCommitAnalysis(t *testing.T) {
+	a, server := newCachingAnalyzer(t)
+	ctx := context.Background()
+
+	request := AnalysisRequest{
+		SDKName:    "sentry-go",
+		Version:    "abc1234",
+		CommitHash: "abc1234def",
+		Code:       map[string]string{"client.go": "package sentry"},
+	}
+
+	first, err := a.AnalyzeCode(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, 300, first.TokensUsed)
+	require.Equal(t, 1, server.CallCount())
+
+	second, err := a.AnalyzeCode(ctx, request)
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.CallCount(), "second analysis of the same commit should not call Claude")
+	assert.Equal(t, first.Language, second.Language)
+	assert.Equal(t, first.Transport, second.Transport)
+	assert.Zero(t, second.TokensUsed)
+	assert.Equal(t, int64(1), a.Stats().APICallsSaved)
+
+	// A new commit, or other files of the same one, is analyzed again
+	newCommit := request
+	newCommit.CommitHash = "fed4321cba"
+	_, err = a.AnalyzeCode(ctx, newCommit)
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.CallCount())
+
+	otherFiles := request
+	otherFiles.Code = map[string]string{"transport.go": "package sentry"}
+	_, err = a.AnalyzeCode(ctx, otherFiles)
+	require.NoError(t, err)
+	assert.Equal(t, 3, server.CallCount())
+	assert.Equal(t, int64(1), a.Stats().APICallsSaved)
+}
+
+func TestAnalyzeCodeWithoutCommitHashIsNotCached(t *testing.T) {
+	a, server := newCachingAnalyzer(t)
+	ctx := context.Background()
+
+	request := AnalysisRequest{
+		SDKName: "sentry-go",
+		Code:    map[string]string{"client.go": "package sentry"},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := a.AnalyzeCode(ctx, request)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, server.CallCount())
+	assert.Zero(t, a.Stats().APICallsSaved)
+}
@@ -0,0 +1,109 @@
+package analyzer
+
+import "strings"
+
+// Compliance violation severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ComplianceViolation is a Sentry protocol rule an SDK analysis fails
+type ComplianceViolation struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+}
+
+// ComplianceRule is a single protocol requirement
+type ComplianceRule struct {
+	Name        string
+	Severity    string
+	Description string
+
+	// Passes reports whether the analysis satisfies the rule
+	Passes func(a *SDKAnalysis) bool
+}
+
+// DefaultComplianceRules are the protocol behaviors every Sentry SDK must implement
+var DefaultComplianceRules = []ComplianceRule{
+	{
+		Name:        "protocol_version",
+		Severity:    SeverityError,
+		Description: "Protocol version must be 7",
+		Passes: func(a *SDKAnalysis) bool {
+			return a.ProtocolVersion == "7"
+		},
+	},
+	{
+		Name:        "envelope_format",
+		Severity:    SeverityError,
+		Description: "Envelope format must be JSON",
+		Passes: func(a *SDKAnalysis) bool {
+			return strings.Contains(strings.ToUpper(a.EnvelopeFormat), "JSON")
+		},
+	},
+	{
+		Name:        "https_transport",
+		Severity:    SeverityError,
+		Description: "Transport must support HTTPS",
+		Passes: func(a *SDKAnalysis) bool {
+			return containsFold(a.Transport.Protocols, "https")
+		},
+	},
+	{
+		Name:        "retry_mechanism",
+		Severity:    SeverityWarning,
+		Description: "Transport must retry failed requests",
+		Passes: func(a *SDKAnalysis) bool {
+			return strings.TrimSpace(a.Transport.RetryMechanism) != ""
+		},
+	},
+	{
+		Name:        "error_events",
+		Severity:    SeverityError,
+		Description: "SDK must send error events",
+		Passes: func(a *SDKAnalysis) bool {
+			return containsFold(a.EventTypes, "error")
+		},
+	},
+}
+
+// ProtocolChecker checks SDK analyses against Sentry protocol rules
+type ProtocolChecker struct {
+	rules []ComplianceRule
+}
+
+// NewProtocolChecker creates a checker for the given rules, or
+// DefaultComplianceRules when none are given
+func NewProtocolChecker(rules ...ComplianceRule) *ProtocolChecker {
+	if len(rules) == 0 {
+		rules = DefaultComplianceRules
+	}
+	return &ProtocolChecker{rules: rules}
+}
+
+// Check returns the rules the analysis violates, in rule order
+func (c *ProtocolChecker) Check(a *SDKAnalysis) []ComplianceViolation {
+	violations := []ComplianceViolation{}
+	for _, rule := range c.rules {
+		if rule.Passes(a) {
+			continue
+		}
+		violations = append(violations, ComplianceViolation{
+			Rule:        rule.Name,
+			Severity:    rule.Severity,
+			Description: rule.Description,
+		})
+	}
+	return violations
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), target) {
+			return true
+		}
+	}
+	return false
+}
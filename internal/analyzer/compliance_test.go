@@ -0,0 +1,109 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func compliantAnalysis() *SDKAnalysis {
+	return &SDKAnalysis{
+		EnvelopeFormat: "JSON envelope with headers and items",
+		Transport: TransportDetails{
+			Type:           "http",
+			Protocols:      []string{"https"},
+			RetryMechanism: "exponential backoff",
+		},
+		EventTypes:      []string{"error", "transaction"},
+		ProtocolVersion: "7",
+	}
+}
+
+func TestProtocolCheckerCompliant(t *testing.T) {
+	violations := NewProtocolChecker().Check(compliantAnalysis())
+	assert.Empty(t, violations)
+	assert.NotNil(t, violations)
+}
+
+func TestProtocolCheckerRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		severity string
+		modify   func(a *SDKAnalysis)
+	}{
+		{
+			name:     "old protocol version",
+			rule:     "protocol_version",
+			severity: SeverityError,
+			modify:   func(a *SDKAnalysis) { a.ProtocolVersion = "6" },
+		},
+		{
+			name:     "missing protocol version",
+			rule:     "protocol_version",
+			severity: SeverityError,
+			modify:   func(a *SDKAnalysis) { a.ProtocolVersion = "" },
+		},
+		{
+			name:     "non-JSON envelope",
+			rule:     "envelope_format",
+			severity: SeverityError,
+			modify:   func(a *SDKAnalysis) { a.EnvelopeFormat = "msgpack frames" },
+		},
+		{
+			name:     "plain http only",
+			rule:     "https_transport",
+			severity: SeverityError,
+			modify:   func(a *SDKAnalysis) { a.Transport.Protocols = []string{"http"} },
+		},
+		{
+			name:     "no retry mechanism",
+			rule:     "retry_mechanism",
+			severity: SeverityWarning,
+			modify:   func(a *SDKAnalysis) { a.Transport.RetryMechanism = " " },
+		},
+		{
+			name:     "no error events",
+			rule:     "error_events",
+			severity: SeverityError,
+			modify:   func(a *SDKAnalysis) { a.EventTypes = []string{"transaction"} },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := compliantAnalysis()
+			tt.modify(a)
+
+			violations := NewProtocolChecker().Check(a)
+			if assert.Len(t, violations, 1) {
+				assert.Equal(t, tt.rule, violations[0].Rule)
+				assert.Equal(t, tt.severity, violations[0].Severity)
+				assert.NotEmpty(t, violations[0].Description)
+			}
+		})
+	}
+}
+
+func TestProtocolCheckerCaseInsensitive(t *testing.T) {
+	a := compliantAnalysis()
+	a.EnvelopeFormat = "json items"
+	a.Transport.Protocols = []string{"HTTPS"}
+	a.EventTypes = []string{"Error"}
+
+	assert.Empty(t, NewProtocolChecker().Check(a))
+}
+
+func TestProtocolCheckerCustomRules(t *testing.T) {
+	checker := NewProtocolChecker(ComplianceRule{
+		Name:        "go_only",
+		Severity:    SeverityWarning,
+		Description: "SDK must be written in Go",
+		Passes:      func(a *SDKAnalysis) bool { return a.Language == "go" },
+	})
+
+	violations := checker.Check(&SDKAnalysis{Language: "python"})
+	assert.Equal(t, []ComplianceViolation{
+		{Rule: "go_only", Severity: SeverityWarning, Description: "SDK must be written in Go"},
+	}, violations)
+}
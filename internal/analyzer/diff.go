@@ -0,0 +1,117 @@
+package analyzer
+
+// FieldChange is a scalar analysis field whose value differs
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ListDiff holds the items added to and removed from a collection
+type ListDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// AnalysisDiff describes how one analysis of an SDK differs from another
+type AnalysisDiff struct {
+	// Changed lists scalar fields with different values
+	Changed []FieldChange `json:"changed"`
+
+	// Lists holds the collections that differ, keyed by JSON field name.
+	// Error and caching patterns are compared by name and type.
+	Lists map[string]ListDiff `json:"lists"`
+
+	// SimilarityScore is 1 minus the normalized Hamming distance between
+	// the feature sets: 1 when they are identical, 0 when disjoint
+	SimilarityScore float64 `json:"similarity_score"`
+}
+
+// DiffAnalyses reports what changed between the from and to analyses.
+// Metadata such as token usage and analysis time is not compared.
+func DiffAnalyses(from, to *SDKAnalysis) AnalysisDiff {
+	diff := AnalysisDiff{
+		Changed:         []FieldChange{},
+		Lists:           make(map[string]ListDiff),
+		SimilarityScore: FeatureSimilarity(from.Features, to.Features),
+	}
+
+	for _, field := range []FieldChange{
+		{Field: "language", Old: from.Language, New: to.Language},
+		{Field: "envelope_format", Old: from.EnvelopeFormat, New: to.EnvelopeFormat},
+		{Field: "protocol_version", Old: from.ProtocolVersion, New: to.ProtocolVersion},
+		{Field: "transport.type", Old: from.Transport.Type, New: to.Transport.Type},
+		{Field: "transport.retry_mechanism", Old: from.Transport.RetryMechanism, New: to.Transport.RetryMechanism},
+		{Field: "transport.queue_implementation", Old: from.Transport.QueueImplementation, New: to.Transport.QueueImplementation},
+	} {
+		if field.Old != field.New {
+			diff.Changed = append(diff.Changed, field)
+		}
+	}
+
+	lists := []struct {
+		field    string
+		from, to []string
+	}{
+		{"features", from.Features, to.Features},
+		{"event_types", from.EventTypes, to.EventTypes},
+		{"integrations", from.Integrations, to.Integrations},
+		{"transport.protocols", from.Transport.Protocols, to.Transport.Protocols},
+		{"error_patterns", errorPatternNames(from.ErrorPatterns), errorPatternNames(to.ErrorPatterns)},
+		{"caching_patterns", cachingPatternTypes(from.CachingPatterns), cachingPatternTypes(to.CachingPatterns)},
+	}
+	for _, list := range lists {
+		added := difference(list.to, list.from)
+		removed := difference(list.from, list.to)
+		if len(added) > 0 || len(removed) > 0 {
+			diff.Lists[list.field] = ListDiff{Added: added, Removed: removed}
+		}
+	}
+
+	return diff
+}
+
+// FeatureSimilarity returns 1 minus the Hamming distance between the two
+// feature sets, as bit vectors over their union, normalized by the size of
+// the union. Two empty sets are identical.
+func FeatureSimilarity(a, b []string) float64 {
+	union := len(difference(a, nil)) + len(difference(b, a))
+	if union == 0 {
+		return 1
+	}
+	distance := len(difference(a, b)) + len(difference(b, a))
+	return 1 - float64(distance)/float64(union)
+}
+
+// difference returns the distinct items of a that are not in b, in order
+func difference(a, b []string) []string {
+	exclude := make(map[string]bool, len(b))
+	for _, item := range b {
+		exclude[item] = true
+	}
+
+	result := []string{}
+	for _, item := range a {
+		if !exclude[item] {
+			result = append(result, item)
+			exclude[item] = true
+		}
+	}
+	return result
+}
+
+func errorPatternNames(patterns []ErrorPattern) []string {
+	names := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func cachingPatternTypes(patterns []CachingPattern) []string {
+	types := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		types = append(types, p.Type)
+	}
+	return types
+}
@@ -0,0 +1,144 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffAnalyses(t *testing.T) {
+	from := &SDKAnalysis{
+		Language:       "go",
+		EnvelopeFormat: "JSON",
+		Transport: TransportDetails{
+			Type:           "http",
+			Protocols:      []string{"https"},
+			RetryMechanism: "none",
+		},
+		Features:        []string{"breadcrumbs", "sessions", "tracing"},
+		EventTypes:      []string{"error", "transaction"},
+		ErrorPatterns:   []ErrorPattern{{Name: "recover"}},
+		ProtocolVersion: "7",
+		TokensUsed:      100,
+	}
+	to := &SDKAnalysis{
+		Language:       "go",
+		EnvelopeFormat: "JSON",
+		Transport: TransportDetails{
+			Type:           "http",
+			Protocols:      []string{"https", "http2"},
+			RetryMechanism: "exponential backoff",
+		},
+		Features:        []string{"breadcrumbs", "tracing", "profiling"},
+		EventTypes:      []string{"error", "transaction"},
+		ErrorPatterns:   []ErrorPattern{{Name: "recover"}},
+		ProtocolVersion: "7",
+		TokensUsed:      250,
+	}
+
+	diff := DiffAnalyses(from, to)
+
+	assert.Equal(t, []FieldChange{
+		{Field: "transport.retry_mechanism", Old: "none", New: "exponential backoff"},
+	}, diff.Changed)
+	assert.Equal(t, map[string]ListDiff{
+		"features":            {Added: []string{"profiling"}, Removed: []string{"sessions"}},
+		"transport.protocols": {Added: []string{"http2"}, Removed: []string{}},
+	}, diff.Lists)
+	// Union of 4 features, 2 differ
+	assert.InDelta(t, 0.5, diff.SimilarityScore, 1e-9)
+}
+
+func TestDiffAnalysesIdentical(t *testing.T) {
+	a := &SDKAnalysis{Language: "python", Features: []string{"breadcrumbs"}}
+
+	diff := DiffAnalyses(a, a)
+
+	assert.Empty(t, diff.Changed)
+	assert.Empty(t, diff.Lists)
+	assert.Equal(t, 1.0, diff.SimilarityScore)
+}
+
+func TestDiffAnalysesChanges(t *testing.T) {
+	base := SDKAnalysis{
+		Language:     "ruby",
+		Transport:    TransportDetails{Type: "http"},
+		Integrations: []string{"rails"},
+		CachingPatterns: []CachingPattern{
+			{Type: "envelope", Location: "transport"},
+			{Type: "rate_limit", Location: "client"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(a *SDKAnalysis)
+		changed []FieldChange
+		lists   map[string]ListDiff
+	}{
+		{
+			name:    "identical",
+			modify:  func(a *SDKAnalysis) {},
+			changed: []FieldChange{},
+			lists:   map[string]ListDiff{},
+		},
+		{
+			name:    "added integrations",
+			modify:  func(a *SDKAnalysis) { a.Integrations = []string{"rails", "sidekiq", "resque"} },
+			changed: []FieldChange{},
+			lists: map[string]ListDiff{
+				"integrations": {Added: []string{"sidekiq", "resque"}, Removed: []string{}},
+			},
+		},
+		{
+			name:   "changed transport type",
+			modify: func(a *SDKAnalysis) { a.Transport.Type = "grpc" },
+			changed: []FieldChange{
+				{Field: "transport.type", Old: "http", New: "grpc"},
+			},
+			lists: map[string]ListDiff{},
+		},
+		{
+			name:    "removed caching patterns",
+			modify:  func(a *SDKAnalysis) { a.CachingPatterns = a.CachingPatterns[:1] },
+			changed: []FieldChange{},
+			lists: map[string]ListDiff{
+				"caching_patterns": {Added: []string{}, Removed: []string{"rate_limit"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			to := base
+			to.Integrations = append([]string(nil), base.Integrations...)
+			to.CachingPatterns = append([]CachingPattern(nil), base.CachingPatterns...)
+			tt.modify(&to)
+
+			diff := DiffAnalyses(&base, &to)
+			assert.Equal(t, tt.changed, diff.Changed)
+			assert.Equal(t, tt.lists, diff.Lists)
+		})
+	}
+}
+
+func TestFeatureSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     []string
+		expected float64
+	}{
+		{"both empty", nil, nil, 1},
+		{"identical", []string{"a", "b"}, []string{"b", "a"}, 1},
+		{"disjoint", []string{"a"}, []string{"b"}, 0},
+		{"one empty", []string{"a", "b"}, nil, 0},
+		{"overlap", []string{"a", "b", "c"}, []string{"b", "c", "d"}, 0.5},
+		{"duplicates ignored", []string{"a", "a", "b"}, []string{"a", "b"}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, FeatureSimilarity(tt.a, tt.b), 1e-9)
+		})
+	}
+}
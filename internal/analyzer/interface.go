@@ -17,8 +17,30 @@ type SDKAnalysis struct {
 	ProtocolVersion string           `json:"protocol_version"`
 	CachingPatterns []CachingPattern `json:"caching_patterns"`
 	TokensUsed      int              `json:"tokens_used"`
+	PassCount       int              `json:"pass_count"`
 	AnalyzedAt      time.Time        `json:"analyzed_at"`
 	AnalysisVersion string           `json:"analysis_version"`
+
+	// SDKVersion is the version of the SDK that was analyzed: its latest
+	// release tag, or the short hash of the commit analyzed if it has none
+	SDKVersion string `json:"sdk_version,omitempty"`
+
+	// TokensSavedByCache counts the input tokens, included in TokensUsed,
+	// that were read from Claude's prompt cache
+	TokensSavedByCache int `json:"tokens_saved_by_cache"`
+
+	// ComplianceReport lists the Sentry protocol rules the SDK violates
+	ComplianceReport []ComplianceViolation `json:"compliance_report"`
+
+	// LFSFilesSkipped counts the Git LFS pointer files matching the SDK's
+	// patterns that were left out of the analysis
+	LFSFilesSkipped int `json:"lfs_files_skipped"`
+
+	// ValidationErrors lists schema warnings raised when the analysis was accepted
+	ValidationErrors []string `json:"validation_errors,omitempty"`
+
+	// QualityScore rates how complete the analysis is, from 0 to 100; see Score
+	QualityScore float64 `json:"quality_score"`
 }
 
 // TransportDetails contains transport implementation details
@@ -49,6 +71,14 @@ type AnalysisRequest struct {
 	Version    string            `json:"version"`
 	Code       map[string]string `json:"code"` // filename -> content
 	CommitHash string            `json:"commit_hash"`
+
+	// Previous is the SDK's cached analysis when Code holds only the files
+	// changed since; the analyzer updates it rather than starting over
+	Previous *SDKAnalysis `json:"previous,omitempty"`
+
+	// PromptOverride, if set, is a prompt template used instead of the
+	// default one; see claude.PromptData for what it is rendered with
+	PromptOverride string `json:"prompt_override,omitempty"`
 }
 
 // BatchAnalysisResult represents results from batch analysis
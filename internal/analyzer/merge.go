@@ -0,0 +1,68 @@
+package analyzer
+
+// MergeAnalyses combines partial analyses of the same SDK, such as the
+// passes of a multi-pass analysis. Collections are unioned in order of
+// first appearance, scalar fields take the first non-empty value, token
+// usage and pass counts are summed and AnalyzedAt is the latest time.
+// Nil analyses are ignored; MergeAnalyses returns nil if there are none.
+func MergeAnalyses(analyses ...*SDKAnalysis) *SDKAnalysis {
+	var merged *SDKAnalysis
+
+	for _, a := range analyses {
+		if a == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &SDKAnalysis{}
+		}
+
+		merged.Language = firstNonEmpty(merged.Language, a.Language)
+		merged.EnvelopeFormat = firstNonEmpty(merged.EnvelopeFormat, a.EnvelopeFormat)
+		merged.ProtocolVersion = firstNonEmpty(merged.ProtocolVersion, a.ProtocolVersion)
+		merged.AnalysisVersion = firstNonEmpty(merged.AnalysisVersion, a.AnalysisVersion)
+		merged.SDKVersion = firstNonEmpty(merged.SDKVersion, a.SDKVersion)
+
+		merged.Transport.Type = firstNonEmpty(merged.Transport.Type, a.Transport.Type)
+		merged.Transport.RetryMechanism = firstNonEmpty(merged.Transport.RetryMechanism, a.Transport.RetryMechanism)
+		merged.Transport.QueueImplementation = firstNonEmpty(merged.Transport.QueueImplementation, a.Transport.QueueImplementation)
+		merged.Transport.Protocols = union(merged.Transport.Protocols, a.Transport.Protocols)
+
+		merged.EventTypes = union(merged.EventTypes, a.EventTypes)
+		merged.Integrations = union(merged.Integrations, a.Integrations)
+		merged.Features = union(merged.Features, a.Features)
+		merged.ErrorPatterns = union(merged.ErrorPatterns, a.ErrorPatterns)
+		merged.CachingPatterns = union(merged.CachingPatterns, a.CachingPatterns)
+
+		merged.TokensUsed += a.TokensUsed
+		merged.TokensSavedByCache += a.TokensSavedByCache
+		merged.PassCount += max(a.PassCount, 1)
+		if a.AnalyzedAt.After(merged.AnalyzedAt) {
+			merged.AnalyzedAt = a.AnalyzedAt
+		}
+	}
+
+	return merged
+}
+
+func firstNonEmpty(current, next string) string {
+	if current != "" {
+		return current
+	}
+	return next
+}
+
+// union appends the items of next that are not already in current
+func union[T comparable](current, next []T) []T {
+	seen := make(map[T]bool, len(current))
+	for _, item := range current {
+		seen[item] = true
+	}
+
+	for _, item := range next {
+		if !seen[item] {
+			seen[item] = true
+			current = append(current, item)
+		}
+	}
+	return current
+}
@@ -0,0 +1,75 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeAnalyses(t *testing.T) {
+	earlier := time.Now().Add(-time.Minute)
+	later := time.Now()
+
+	first := &SDKAnalysis{
+		Language: "javascript",
+		Transport: TransportDetails{
+			Type:      "http",
+			Protocols: []string{"https"},
+		},
+		EventTypes: []string{"error", "transaction"},
+		ErrorPatterns: []ErrorPattern{
+			{Name: "try_catch", Pattern: "try {}", Description: "Wraps callbacks"},
+		},
+		Features:   []string{"breadcrumbs"},
+		TokensUsed: 100,
+		PassCount:  1,
+		AnalyzedAt: earlier,
+	}
+	second := &SDKAnalysis{
+		Language:       "typescript",
+		EnvelopeFormat: "JSON envelope",
+		Transport: TransportDetails{
+			Type:           "fetch",
+			Protocols:      []string{"https", "http2"},
+			RetryMechanism: "exponential backoff",
+		},
+		EventTypes: []string{"transaction", "profile"},
+		ErrorPatterns: []ErrorPattern{
+			{Name: "try_catch", Pattern: "try {}", Description: "Wraps callbacks"},
+			{Name: "on_error", Pattern: "window.onerror", Description: "Global handler"},
+		},
+		CachingPatterns: []CachingPattern{
+			{Type: "envelope_buffer", Location: "transport", Description: "Offline queue"},
+		},
+		Integrations: []string{"react"},
+		TokensUsed:   50,
+		AnalyzedAt:   later,
+	}
+
+	merged := MergeAnalyses(first, nil, second)
+	require.NotNil(t, merged)
+
+	assert.Equal(t, "javascript", merged.Language)
+	assert.Equal(t, "JSON envelope", merged.EnvelopeFormat)
+	assert.Equal(t, "http", merged.Transport.Type)
+	assert.Equal(t, "exponential backoff", merged.Transport.RetryMechanism)
+	assert.Equal(t, []string{"https", "http2"}, merged.Transport.Protocols)
+	assert.Equal(t, []string{"error", "transaction", "profile"}, merged.EventTypes)
+	assert.Len(t, merged.ErrorPatterns, 2)
+	assert.Len(t, merged.CachingPatterns, 1)
+	assert.Equal(t, []string{"breadcrumbs"}, merged.Features)
+	assert.Equal(t, []string{"react"}, merged.Integrations)
+	assert.Equal(t, 150, merged.TokensUsed)
+	assert.Equal(t, 2, merged.PassCount)
+	assert.Equal(t, later, merged.AnalyzedAt)
+
+	// Inputs are left untouched
+	assert.Equal(t, []string{"error", "transaction"}, first.EventTypes)
+}
+
+func TestMergeAnalysesEmpty(t *testing.T) {
+	assert.Nil(t, MergeAnalyses())
+	assert.Nil(t, MergeAnalyses(nil, nil))
+}
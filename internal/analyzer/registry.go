@@ -0,0 +1,82 @@
+package analyzer
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// Factory creates an Analyzer from the service configuration
+type Factory func(cfg *config.Config, logger zerolog.Logger) Analyzer
+
+// ProviderRegistry maps provider names to analyzer factories. It is safe
+// for concurrent use.
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]Factory
+}
+
+// Registry is the default provider registry. Packages that implement a
+// provider register it here, typically from an init function.
+var Registry = NewProviderRegistry()
+
+func init() {
+	Registry.Register("claude", func(cfg *config.Config, logger zerolog.Logger) Analyzer {
+		a := NewClaudeAnalyzer(cfg.ClaudeAPIKey, cfg.ClaudeModel, logger)
+		a.SetFeatureFlags(cfg)
+		return a
+	})
+	Registry.Register("mock", func(cfg *config.Config, logger zerolog.Logger) Analyzer {
+		return NewMockAnalyzer(logger)
+	})
+}
+
+// NewProviderRegistry creates an empty provider registry
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{factories: make(map[string]Factory)}
+}
+
+// Register makes a provider available by name. It panics if factory is nil
+// or name is already registered.
+func (r *ProviderRegistry) Register(name string, factory Factory) {
+	if factory == nil {
+		panic("analyzer: Register factory is nil for provider " + name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		panic("analyzer: Register called twice for provider " + name)
+	}
+	r.factories[name] = factory
+}
+
+// Create builds an analyzer using the named provider
+func (r *ProviderRegistry) Create(name string, cfg *config.Config, logger zerolog.Logger) (Analyzer, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown analyzer provider %q (available: %v)", name, r.Providers())
+	}
+	return factory(cfg, logger), nil
+}
+
+// Providers returns the registered provider names in sorted order
+func (r *ProviderRegistry) Providers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,66 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// stubAnalyzer is a custom provider that remembers the model it was built with.
+type stubAnalyzer struct {
+	MockAnalyzer
+	model string
+}
+
+func TestRegistryCustomProvider(t *testing.T) {
+	registry := NewProviderRegistry()
+	registry.Register("stub", func(cfg *config.Config, logger zerolog.Logger) Analyzer {
+		return &stubAnalyzer{MockAnalyzer: MockAnalyzer{logger: logger}, model: cfg.ClaudeModel}
+	})
+
+	a, err := registry.Create("stub", &config.Config{ClaudeModel: "stub-model"}, zerolog.Nop())
+	require.NoError(t, err)
+
+	stub, ok := a.(*stubAnalyzer)
+	require.True(t, ok)
+	assert.Equal(t, "stub-model", stub.model)
+	assert.Equal(t, []string{"stub"}, registry.Providers())
+
+	assert.Panics(t, func() {
+		registry.Register("stub", func(cfg *config.Config, logger zerolog.Logger) Analyzer { return nil })
+	})
+	assert.Panics(t, func() {
+		registry.Register("nil", nil)
+	})
+}
+
+func TestRegistryBuiltinProviders(t *testing.T) {
+	cfg := &config.Config{ClaudeAPIKey: "test-key", ClaudeModel: "test-model"}
+
+	tests := []struct {
+		name     string
+		expected Analyzer
+	}{
+		{name: "claude", expected: &ClaudeAnalyzer{}},
+		{name: "mock", expected: &MockAnalyzer{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := Registry.Create(tt.name, cfg, zerolog.Nop())
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, a)
+		})
+	}
+}
+
+func TestRegistryUnknownProvider(t *testing.T) {
+	_, err := Registry.Create("no-such-provider", &config.Config{}, zerolog.Nop())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown analyzer provider "no-such-provider"`)
+	assert.Contains(t, err.Error(), "claude")
+}
@@ -0,0 +1,108 @@
+package analyzer
+
+import "strings"
+
+// MaxQualityScore is the quality score of a complete analysis
+const MaxQualityScore = 100.0
+
+// LowQualityScore is the score below which an analysis is likely too
+// incomplete to rely on
+const LowQualityScore = 60.0
+
+// qualityDeduction is a gap in an analysis and the points it costs
+type qualityDeduction struct {
+	Reason string
+	Points float64
+
+	// Applies reports whether the analysis has the gap
+	Applies func(a *SDKAnalysis) bool
+}
+
+// qualityDeductions are the gaps Score looks for. Together they cost fewer
+// than MaxQualityScore points.
+var qualityDeductions = []qualityDeduction{
+	{
+		Reason: "language is empty",
+		Points: 20,
+		Applies: func(a *SDKAnalysis) bool {
+			return strings.TrimSpace(a.Language) == ""
+		},
+	},
+	{
+		Reason: "transport type is empty",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return strings.TrimSpace(a.Transport.Type) == ""
+		},
+	},
+	{
+		Reason: "no error patterns",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.ErrorPatterns) == 0
+		},
+	},
+	{
+		Reason: "protocol version is empty",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return strings.TrimSpace(a.ProtocolVersion) == ""
+		},
+	},
+	{
+		Reason: "no caching patterns",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.CachingPatterns) == 0
+		},
+	},
+	{
+		Reason: "envelope format is empty",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return strings.TrimSpace(a.EnvelopeFormat) == ""
+		},
+	},
+	{
+		Reason: "no event types",
+		Points: 10,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.EventTypes) == 0
+		},
+	},
+	{
+		Reason: "no transport protocols",
+		Points: 5,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.Transport.Protocols) == 0
+		},
+	},
+	{
+		Reason: "no features",
+		Points: 5,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.Features) == 0
+		},
+	},
+	{
+		Reason: "no integrations",
+		Points: 5,
+		Applies: func(a *SDKAnalysis) bool {
+			return len(a.Integrations) == 0
+		},
+	},
+}
+
+// Score rates how complete an analysis is, from 0 to MaxQualityScore, and
+// lists the reasons for each deduction
+func Score(a *SDKAnalysis) (float64, []string) {
+	score := MaxQualityScore
+	var reasons []string
+	for _, d := range qualityDeductions {
+		if d.Applies(a) {
+			score -= d.Points
+			reasons = append(reasons, d.Reason)
+		}
+	}
+	return max(score, 0), reasons
+}
@@ -0,0 +1,69 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// completeAnalysis returns an analysis with nothing to deduct.
+func completeAnalysis() *SDKAnalysis {
+	return &SDKAnalysis{
+		Language:       "go",
+		EnvelopeFormat: "JSON",
+		Transport: TransportDetails{
+			Type:      "http",
+			Protocols: []string{"https"},
+		},
+		EventTypes:      []string{"error"},
+		ErrorPatterns:   []ErrorPattern{{Name: "wrapped_errors"}},
+		Integrations:    []string{"net/http"},
+		Features:        []string{"breadcrumbs"},
+		ProtocolVersion: "7",
+		CachingPatterns: []CachingPattern{{Type: "envelope_buffer"}},
+	}
+}
+
+func TestScoreComplete(t *testing.T) {
+	score, reasons := Score(completeAnalysis())
+	assert.Equal(t, MaxQualityScore, score)
+	assert.Empty(t, reasons)
+}
+
+func TestScoreDeductions(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*SDKAnalysis)
+		score  float64
+		reason string
+	}{
+		{"empty language", func(a *SDKAnalysis) { a.Language = " " }, 80, "language is empty"},
+		{"empty transport type", func(a *SDKAnalysis) { a.Transport.Type = "" }, 90, "transport type is empty"},
+		{"no error patterns", func(a *SDKAnalysis) { a.ErrorPatterns = nil }, 90, "no error patterns"},
+		{"empty protocol version", func(a *SDKAnalysis) { a.ProtocolVersion = "" }, 90, "protocol version is empty"},
+		{"no caching patterns", func(a *SDKAnalysis) { a.CachingPatterns = []CachingPattern{} }, 90, "no caching patterns"},
+		{"empty envelope format", func(a *SDKAnalysis) { a.EnvelopeFormat = "" }, 90, "envelope format is empty"},
+		{"no event types", func(a *SDKAnalysis) { a.EventTypes = nil }, 90, "no event types"},
+		{"no transport protocols", func(a *SDKAnalysis) { a.Transport.Protocols = nil }, 95, "no transport protocols"},
+		{"no features", func(a *SDKAnalysis) { a.Features = nil }, 95, "no features"},
+		{"no integrations", func(a *SDKAnalysis) { a.Integrations = nil }, 95, "no integrations"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := completeAnalysis()
+			tt.modify(a)
+
+			score, reasons := Score(a)
+			assert.Equal(t, tt.score, score)
+			assert.Equal(t, []string{tt.reason}, reasons)
+		})
+	}
+}
+
+func TestScoreEmptyAnalysis(t *testing.T) {
+	score, reasons := Score(&SDKAnalysis{})
+	assert.Equal(t, 5.0, score)
+	assert.Len(t, reasons, len(qualityDeductions))
+	assert.Less(t, score, LowQualityScore)
+}
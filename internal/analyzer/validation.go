@@ -0,0 +1,143 @@
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// TransportTypes are the transport.type values an analysis may report
+var TransportTypes = []string{"http", "grpc", "other"}
+
+// KnownProtocolVersions are the Sentry protocol versions the service
+// recognizes. Other values are accepted with a warning.
+var KnownProtocolVersions = []string{"7"}
+
+// ValidationError describes a problem with an SDK analysis
+type ValidationError struct {
+	Field    string `json:"field"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// SDKAnalysisSchema returns the JSON Schema an analysis returned by Claude
+// must satisfy. Metadata set by the service is not covered.
+func SDKAnalysisSchema() *openapi3.Schema {
+	stringList := openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()).WithNullable()
+
+	return openapi3.NewObjectSchema().
+		WithProperty("language", openapi3.NewStringSchema().WithMinLength(1)).
+		WithProperty("envelope_format", openapi3.NewStringSchema()).
+		WithProperty("transport", openapi3.NewObjectSchema().
+			// Empty when the analysis could not determine the transport
+			WithProperty("type", openapi3.NewStringSchema().WithEnum(enumValues(append([]string{""}, TransportTypes...))...)).
+			WithProperty("protocols", stringList).
+			WithProperty("retry_mechanism", openapi3.NewStringSchema()).
+			WithProperty("queue_implementation", openapi3.NewStringSchema())).
+		WithProperty("event_types", stringList).
+		WithProperty("error_patterns", openapi3.NewArraySchema().WithNullable().WithItems(openapi3.NewObjectSchema().
+			WithProperty("name", openapi3.NewStringSchema()).
+			WithProperty("pattern", openapi3.NewStringSchema()).
+			WithProperty("description", openapi3.NewStringSchema()))).
+		WithProperty("integrations", stringList).
+		WithProperty("features", stringList).
+		WithProperty("protocol_version", openapi3.NewStringSchema()).
+		WithProperty("caching_patterns", openapi3.NewArraySchema().WithNullable().WithItems(openapi3.NewObjectSchema().
+			WithProperty("type", openapi3.NewStringSchema()).
+			WithProperty("location", openapi3.NewStringSchema()).
+			WithProperty("description", openapi3.NewStringSchema()))).
+		WithRequired([]string{"language"})
+}
+
+var sdkAnalysisSchema = SDKAnalysisSchema()
+
+// ValidateSDKAnalysis checks an analysis against SDKAnalysisSchema. Schema
+// violations are errors; unrecognized but plausible values are warnings.
+func ValidateSDKAnalysis(a *SDKAnalysis) []ValidationError {
+	data, err := json.Marshal(a)
+	if err != nil {
+		return []ValidationError{{Field: "analysis", Severity: SeverityError, Message: err.Error()}}
+	}
+	return validateAnalysisJSON(data)
+}
+
+// validateAnalysisJSON validates a raw JSON analysis
+func validateAnalysisJSON(data []byte) []ValidationError {
+	var value map[string]any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []ValidationError{{Field: "analysis", Severity: SeverityError, Message: "analysis is not a JSON object"}}
+	}
+
+	problems := []ValidationError{}
+	if err := sdkAnalysisSchema.VisitJSON(value, openapi3.MultiErrors()); err != nil {
+		problems = append(problems, schemaErrors(err)...)
+	}
+
+	if version, ok := value["protocol_version"].(string); ok && version != "" && !containsFold(KnownProtocolVersions, version) {
+		problems = append(problems, ValidationError{
+			Field:    "protocol_version",
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("unrecognized protocol version %q", version),
+		})
+	}
+	return problems
+}
+
+// HasValidationErrors reports whether any problem has error severity
+func HasValidationErrors(problems []ValidationError) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// validationFailure joins the error severity problems into one error
+func validationFailure(problems []ValidationError) error {
+	var errs []error
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			errs = append(errs, p)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// schemaErrors flattens a schema validation error into ValidationErrors
+func schemaErrors(err error) []ValidationError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var problems []ValidationError
+		for _, e := range multi {
+			problems = append(problems, schemaErrors(e)...)
+		}
+		return problems
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return []ValidationError{{Field: "analysis", Severity: SeverityError, Message: err.Error()}}
+	}
+
+	field := strings.Join(schemaErr.JSONPointer(), ".")
+	if field == "" {
+		field = "analysis"
+	}
+	return []ValidationError{{Field: field, Severity: SeverityError, Message: schemaErr.Reason}}
+}
+
+func enumValues(values []string) []any {
+	enum := make([]any, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
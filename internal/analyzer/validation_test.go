@@ -0,0 +1,221 @@
+package analyzer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
+)
+
+func validAnalysis() *SDKAnalysis {
+	return &SDKAnalysis{
+		Language:       "go",
+		EnvelopeFormat: "JSON",
+		Transport: TransportDetails{
+			Type:      "http",
+			Protocols: []string{"https"},
+		},
+		ProtocolVersion: "7",
+	}
+}
+
+func TestValidateSDKAnalysis(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(a *SDKAnalysis)
+		expected []ValidationError
+	}{
+		{
+			name:     "valid",
+			modify:   func(a *SDKAnalysis) {},
+			expected: []ValidationError{},
+		},
+		{
+			name:     "transport not determined",
+			modify:   func(a *SDKAnalysis) { a.Transport = TransportDetails{} },
+			expected: []ValidationError{},
+		},
+		{
+			name:   "missing language",
+			modify: func(a *SDKAnalysis) { a.Language = "" },
+			expected: []ValidationError{
+				{Field: "language", Severity: SeverityError},
+			},
+		},
+		{
+			name:   "unexpected transport type",
+			modify: func(a *SDKAnalysis) { a.Transport.Type = "carrier-pigeon" },
+			expected: []ValidationError{
+				{Field: "transport.type", Severity: SeverityError},
+			},
+		},
+		{
+			name:   "unknown protocol version",
+			modify: func(a *SDKAnalysis) { a.ProtocolVersion = "8" },
+			expected: []ValidationError{
+				{Field: "protocol_version", Severity: SeverityWarning},
+			},
+		},
+		{
+			name: "several problems",
+			modify: func(a *SDKAnalysis) {
+				a.Language = ""
+				a.Transport.Type = "smtp"
+				a.ProtocolVersion = "6"
+			},
+			expected: []ValidationError{
+				{Field: "language", Severity: SeverityError},
+				{Field: "transport.type", Severity: SeverityError},
+				{Field: "protocol_version", Severity: SeverityWarning},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := validAnalysis()
+			tt.modify(a)
+
+			problems := ValidateSDKAnalysis(a)
+			require.Len(t, problems, len(tt.expected))
+			for _, expected := range tt.expected {
+				assert.True(t, containsProblem(problems, expected), "expected %s %s in %v", expected.Severity, expected.Field, problems)
+			}
+		})
+	}
+}
+
+func TestValidateAnalysisJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		expected []ValidationError
+	}{
+		{
+			name:     "minimal",
+			json:     `{"language": "go"}`,
+			expected: []ValidationError{},
+		},
+		{
+			name: "missing language",
+			json: `{"envelope_format": "JSON", "transport": {"type": "http"}}`,
+			expected: []ValidationError{
+				{Field: "language", Severity: SeverityError},
+			},
+		},
+		{
+			name: "wrong types",
+			json: `{"language": 42, "transport": {"type": "http", "protocols": "https"}, "features": [1, 2]}`,
+			expected: []ValidationError{
+				{Field: "language", Severity: SeverityError},
+				{Field: "transport.protocols", Severity: SeverityError},
+				{Field: "features.0", Severity: SeverityError},
+				{Field: "features.1", Severity: SeverityError},
+			},
+		},
+		{
+			name: "transport is not an object",
+			json: `{"language": "go", "transport": "http"}`,
+			expected: []ValidationError{
+				{Field: "transport", Severity: SeverityError},
+			},
+		},
+		{
+			name:     "null lists are accepted",
+			json:     `{"language": "go", "event_types": null, "error_patterns": null}`,
+			expected: []ValidationError{},
+		},
+		{
+			name: "not an object",
+			json: `["go"]`,
+			expected: []ValidationError{
+				{Field: "analysis", Severity: SeverityError},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := validateAnalysisJSON([]byte(tt.json))
+			require.Len(t, problems, len(tt.expected), "problems: %v", problems)
+			for _, expected := range tt.expected {
+				assert.True(t, containsProblem(problems, expected), "expected %s %s in %v", expected.Severity, expected.Field, problems)
+			}
+		})
+	}
+}
+
+func TestAnalyzeCodeValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		response      string
+		expectedError string
+		warnings      int
+	}{
+		{
+			name:     "valid",
+			response: `{"language": "go", "transport": {"type": "http"}, "protocol_version": "7"}`,
+		},
+		{
+			name:          "missing language",
+			response:      `{"transport": {"type": "http"}, "protocol_version": "7"}`,
+			expectedError: "invalid analysis: language",
+		},
+		{
+			name:          "unexpected transport type",
+			response:      `{"language": "go", "transport": {"type": "websocket"}}`,
+			expectedError: "invalid analysis: transport.type",
+		},
+		{
+			name:          "wrong type",
+			response:      `{"language": "go", "features": "breadcrumbs"}`,
+			expectedError: "invalid analysis: features",
+		},
+		{
+			name:     "unknown protocol version",
+			response: `{"language": "go", "transport": {"type": "http"}, "protocol_version": "8"}`,
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := mockserver.NewMockServer(t)
+			server.SetResponse(mockserver.TextResponse(tt.response, 10, 20))
+
+			logger := zerolog.Nop()
+			client := claude.NewClient("test-key", "claude-3-opus", logger)
+			client.BaseURL = server.URL
+			a := NewClaudeAnalyzerWithClient(client, logger)
+
+			analysis, err := a.AnalyzeCode(context.Background(), AnalysisRequest{
+				SDKName: "sentry-go",
+				Version: "1.0.0",
+				Code:    map[string]string{"client.go": "package sentry"},
+			})
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Nil(t, analysis)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, analysis.ValidationErrors, tt.warnings)
+		})
+	}
+}
+
+func containsProblem(problems []ValidationError, expected ValidationError) bool {
+	for _, p := range problems {
+		if p.Field == expected.Field && p.Severity == expected.Severity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// handleAnalyzeSDK analyzes one SDK on demand. By default it waits for the
+// analysis and returns it; with ?async=true it starts a cache warm job and
+// returns its ID instead. ?force=true re-analyzes an SDK that has not
+// changed since its last analysis.
+func (s *Server) handleAnalyzeSDK(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "force must be true or false",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	async, err := strconv.ParseBool(c.DefaultQuery("async", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "async must be true or false",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	if async {
+		job, err := s.worker.WarmSDKs([]string{sdkName}, force)
+		if err != nil {
+			s.respondAnalyzeError(c, sdkName, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, SuccessResponse{
+			Data:      gin.H{"job_id": job.ID()},
+			Message:   "SDK analysis started",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	analysis, analyzed, err := s.worker.AnalyzeSDKNow(c.Request.Context(), sdkName, force)
+	if err != nil {
+		s.respondAnalyzeError(c, sdkName, err)
+		return
+	}
+
+	message := "SDK analyzed successfully"
+	if !analyzed {
+		message = "SDK analysis is up to date"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      analysis,
+		Message:   message,
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// respondAnalyzeError reports why an SDK could not be analyzed. Failures
+// of the analysis itself are reported as 502, since they come from the
+// SDK's repository or the Claude API rather than from this service.
+func (s *Server) respondAnalyzeError(c *gin.Context, sdkName string, err error) {
+	var notFound *apperrors.SDKNotFoundError
+	var rateLimited *apperrors.RateLimitError
+	var failed *apperrors.AnalysisFailedError
+	switch {
+	case errors.As(err, &notFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + notFound.Name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+	case errors.Is(err, worker.ErrDraining):
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     "unavailable",
+			Message:   "Worker is shutting down",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+	case errors.As(err, &rateLimited):
+		s.logger.Warn().Err(err).Str("sdk", sdkName).Msg("SDK analysis was rate limited")
+		respondRateLimitError(c, rateLimited)
+	case errors.As(err, &failed):
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to analyze SDK")
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Error:     "analysis_failed",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+	default:
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to analyze SDK")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to analyze SDK",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+	}
+}
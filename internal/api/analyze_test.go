@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeSDKEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name           string
+		path           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "missing token",
+			path:           "/api/v1/cache/sdk/sentry-go/analyze",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+		{
+			name:           "non-admin token",
+			path:           "/api/v1/cache/sdk/sentry-go/analyze",
+			authHeader:     "Bearer " + testAPIKeys[0],
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid_token",
+		},
+		{
+			name:           "invalid force",
+			path:           "/api/v1/cache/sdk/sentry-go/analyze?force=maybe",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "invalid async",
+			path:           "/api/v1/cache/sdk/sentry-go/analyze?async=later",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "unknown sdk",
+			path:           "/api/v1/cache/sdk/no-such-sdk/analyze",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+		{
+			name:           "unknown sdk async",
+			path:           "/api/v1/cache/sdk/no-such-sdk/analyze?async=true",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse.Error)
+		})
+	}
+}
+
+func TestAnalyzeSDKEndpointAsync(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	// A fresh SDK is skipped, so the job completes without cloning
+	server.config.StaleThreshold = time.Hour
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", time.Now().Format(time.RFC3339), 0))
+
+	req, _ := http.NewRequest("POST", "/api/v1/cache/sdk/sentry-go/analyze?async=true", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started struct {
+		Data struct {
+			JobID string `json:"job_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	require.NotEmpty(t, started.Data.JobID)
+
+	job, ok := server.worker.Jobs().Get(started.Data.JobID)
+	require.True(t, ok)
+	assert.Equal(t, 1, job.Progress().Total)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/audit"
+)
+
+// Page sizes for GET /api/v1/audit.
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// actorAnonymous is the audit actor of requests without a valid API key.
+const actorAnonymous = "anonymous"
+
+// auditMiddleware records the request in the audit log as action once it
+// has been handled, as a success if it did not fail with a 4xx or 5xx
+// status. It goes before the auth middleware so rejected attempts are
+// recorded too. key, if not nil, extracts the key, prefix or SDK the
+// request applies to.
+func (s *Server) auditMiddleware(action string, key func(c *gin.Context) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		event := audit.AuditEvent{
+			Timestamp: time.Now(),
+			RequestID: c.GetString("request_id"),
+			ClientIP:  c.ClientIP(),
+			Action:    action,
+			Actor:     c.GetString("actor"),
+			Success:   c.Writer.Status() < http.StatusBadRequest,
+		}
+		if event.Actor == "" {
+			event.Actor = actorAnonymous
+		}
+		if key != nil {
+			event.Key = key(c)
+		}
+		s.audit.Log(event)
+	}
+}
+
+// auditParam extracts the audited key from the named path parameter.
+func auditParam(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string { return c.Param(name) }
+}
+
+// auditQuery extracts the audited key from the named query parameter.
+func auditQuery(name string) func(c *gin.Context) string {
+	return func(c *gin.Context) string { return c.Query(name) }
+}
+
+// handleListAuditEvents returns the most recent audit events, newest first,
+// optionally only those with the action query parameter.
+func (s *Server) handleListAuditEvents(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+	if err != nil || limit < 1 || limit > maxAuditLimit {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	if s.audit == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     "analytics_disabled",
+			Message:   "The audit log is kept in the analytics store, which is disabled",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	events, err := s.audit.Recent(limit, c.Query("action"))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to query audit log")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to query audit log",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"events": events,
+			"count":  len(events),
+		},
+		Message:   "Audit events retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,233 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/audit"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// setupAuditTestServer returns a test server recording its audit log in a
+// fresh analytics store.
+func setupAuditTestServer(t *testing.T) (*Server, *cache.Manager) {
+	t.Helper()
+
+	server, cacheManager := setupTestServer(t)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	store, err := analytics.Open(filepath.Join(t.TempDir(), "analytics.db"), zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	server.worker.SetAnalyticsStore(store)
+	server = NewServer(server.config, cacheManager, server.worker, server.logger)
+	return server, cacheManager
+}
+
+// listAuditEvents fetches GET /api/v1/audit with query.
+func listAuditEvents(t *testing.T, server *Server, query string) []audit.AuditEvent {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/api/v1/audit"+query, nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data struct {
+			Events []audit.AuditEvent `json:"events"`
+			Count  int                `json:"count"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, len(response.Data.Events), response.Data.Count)
+	return response.Data.Events
+}
+
+func TestAuditLogRecordsMutations(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		token      string
+		action     string
+		key        string
+		actorKind  string
+		success    bool
+		setupCache map[string]string
+	}{
+		{
+			name:       "delete key",
+			method:     "DELETE",
+			path:       "/api/v1/cache/key/sdk:sentry-go",
+			token:      testAPIKeys[0],
+			action:     audit.ActionDeleteKey,
+			key:        "sdk:sentry-go",
+			actorKind:  "api:",
+			success:    true,
+			setupCache: map[string]string{"sdk:sentry-go": "{}"},
+		},
+		{
+			name:       "delete keys by prefix",
+			method:     "DELETE",
+			path:       "/api/v1/cache/keys?prefix=project:",
+			token:      testAdminKey,
+			action:     audit.ActionDeleteKeys,
+			key:        "project:",
+			actorKind:  "admin:",
+			success:    true,
+			setupCache: map[string]string{"project:gremlin": "arrow flight"},
+		},
+		{
+			name:      "import",
+			method:    "POST",
+			path:      "/api/v1/cache/import",
+			body:      `{"key":"sdk:sentry-python","value":"{}"}` + "\n",
+			token:     testAdminKey,
+			action:    audit.ActionImport,
+			actorKind: "admin:",
+			success:   true,
+		},
+		{
+			name:      "set feature",
+			method:    "PATCH",
+			path:      "/api/v1/admin/features/streaming",
+			body:      `{"enabled": true}`,
+			token:     testAdminKey,
+			action:    audit.ActionSetFeature,
+			key:       "streaming",
+			actorKind: "admin:",
+			success:   true,
+		},
+		{
+			name:      "reset backoff",
+			method:    "POST",
+			path:      "/api/v1/worker/reset-backoff",
+			token:     testAdminKey,
+			action:    audit.ActionResetBackoff,
+			actorKind: "admin:",
+			success:   true,
+		},
+		{
+			name:      "unauthorized attempt",
+			method:    "POST",
+			path:      "/api/v1/worker/reset-backoff",
+			token:     testAPIKeys[0],
+			action:    audit.ActionResetBackoff,
+			actorKind: actorAnonymous,
+			success:   false,
+		},
+		{
+			name:      "failed request",
+			method:    "DELETE",
+			path:      "/api/v1/cache/keys",
+			token:     testAPIKeys[0],
+			action:    audit.ActionDeleteKeys,
+			actorKind: "api:",
+			success:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cacheManager := setupAuditTestServer(t)
+			for key, value := range tt.setupCache {
+				require.NoError(t, cacheManager.Set(key, value, 0))
+			}
+
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("X-Request-ID", "audit-"+tt.action)
+			req.RemoteAddr = "203.0.113.7:12345"
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			events := listAuditEvents(t, server, "?action="+tt.action)
+			require.Len(t, events, 1)
+			event := events[0]
+			assert.Equal(t, tt.action, event.Action)
+			assert.Equal(t, tt.key, event.Key)
+			assert.Equal(t, "audit-"+tt.action, event.RequestID)
+			assert.Equal(t, "203.0.113.7", event.ClientIP)
+			assert.True(t, strings.HasPrefix(event.Actor, tt.actorKind), event.Actor)
+			assert.NotContains(t, event.Actor, tt.token)
+			assert.Equal(t, tt.success, event.Success)
+			assert.False(t, event.Timestamp.IsZero())
+		})
+	}
+}
+
+func TestAuditLogRecordsCacheWrites(t *testing.T) {
+	server, cacheManager := setupAuditTestServer(t)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", 0))
+	require.NoError(t, cacheManager.Delete("sdk:sentry-go"))
+
+	events := listAuditEvents(t, server, "")
+	require.Len(t, events, 2)
+
+	assert.Equal(t, string(cache.EventDelete), events[0].Action)
+	assert.Equal(t, string(cache.EventSet), events[1].Action)
+	for _, event := range events {
+		assert.Equal(t, "sdk:sentry-go", event.Key)
+		assert.Equal(t, audit.ActorSystem, event.Actor)
+		assert.True(t, event.Success)
+		assert.Empty(t, event.RequestID)
+	}
+}
+
+func TestListAuditEvents(t *testing.T) {
+	server, cacheManager := setupAuditTestServer(t)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, cacheManager.Set(key, "value", 0))
+	}
+	require.NoError(t, cacheManager.Delete("a"))
+
+	events := listAuditEvents(t, server, "?limit=2&action=set")
+	require.Len(t, events, 2)
+	assert.Equal(t, "c", events[0].Key)
+	assert.Equal(t, "b", events[1].Key)
+
+	request := func(query, token string) int {
+		req, _ := http.NewRequest("GET", "/api/v1/audit"+query, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("", ""))
+	assert.Equal(t, http.StatusUnauthorized, request("", testAPIKeys[0]))
+	assert.Equal(t, http.StatusBadRequest, request("?limit=0", testAdminKey))
+	assert.Equal(t, http.StatusBadRequest, request("?limit=1001", testAdminKey))
+
+	// Without analytics there is no audit log
+	disabled, disabledCache := setupTestServer(t)
+	defer func() {
+		require.NoError(t, disabledCache.Close())
+	}()
+	req, _ := http.NewRequest("GET", "/api/v1/audit", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	disabled.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
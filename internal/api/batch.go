@@ -0,0 +1,132 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// batchAnalyzeRequest is the body of POST /api/v1/batch/analyze.
+type batchAnalyzeRequest struct {
+	Analyses []adhocAnalysis `json:"analyses" binding:"required,min=1,dive"`
+}
+
+type adhocAnalysis struct {
+	SDKName string            `json:"sdk_name" binding:"required"`
+	Version string            `json:"version"`
+	Code    map[string]string `json:"code" binding:"required,min=1"`
+}
+
+func (s *Server) handleBatchAnalyze(c *gin.Context) {
+	var req batchAnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"analyses\": [{\"sdk_name\": ..., \"version\": ..., \"code\": {...}}]}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	requests := make([]analyzer.AnalysisRequest, len(req.Analyses))
+	for i, a := range req.Analyses {
+		requests[i] = analyzer.AnalysisRequest{
+			SDKName: a.SDKName,
+			Version: a.Version,
+			Code:    a.Code,
+		}
+	}
+
+	tokens, err := s.worker.EstimateAdhocTokens(c.Request.Context(), requests)
+	if err != nil {
+		if errors.Is(err, worker.ErrTokenBudgetExceeded) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error:     "token_budget_exceeded",
+				Message:   err.Error(),
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		s.logger.Error().Err(err).Msg("Failed to estimate ad-hoc analysis tokens")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to estimate token usage",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	if tokens > s.config.AdhocSyncTokens {
+		s.startBatchAnalyze(c, requests)
+		return
+	}
+
+	result, err := s.worker.AnalyzeAdhoc(c.Request.Context(), requests)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Ad-hoc analysis failed")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to analyze code",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      result,
+		Message:   "Analysis completed",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// startBatchAnalyze runs requests that are too large to answer inline in
+// the background. Progress is available from the jobs endpoint and results
+// are served from the cache once the same request is repeated.
+func (s *Server) startBatchAnalyze(c *gin.Context, requests []analyzer.AnalysisRequest) {
+	job, err := s.worker.StartAdhocAnalysis(requests)
+	if err != nil {
+		if errors.Is(err, worker.ErrDraining) {
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Worker is shutting down",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		s.logger.Error().Err(err).Msg("Failed to start ad-hoc analysis")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to start analysis",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Data: &analyzer.BatchAnalysisResult{
+			JobID:   job.ID(),
+			Status:  "processing",
+			Results: map[string]*analyzer.SDKAnalysis{},
+			Errors:  map[string]string{},
+		},
+		Message:   "Analysis started",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
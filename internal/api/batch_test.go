@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// setupBatchTestServer returns a server whose ad-hoc limits are small
+// enough to exercise every response.
+func setupBatchTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	server, cacheManager := setupTestServer(t)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	server.config.MaxAdhocTokens = 10000
+	server.config.AdhocSyncTokens = 1000
+	server.config.MaxRequestBodyBytes = 64 << 10
+	return NewServer(server.config, cacheManager, server.worker, server.logger)
+}
+
+// adhocBody builds a request body analyzing roughly tokens tokens of code.
+func adhocBody(sdkName string, tokens int) string {
+	body, _ := json.Marshal(map[string]interface{}{
+		"analyses": []map[string]interface{}{{
+			"sdk_name": sdkName,
+			"version":  "1.0.0",
+			"code":     map[string]string{"main.go": strings.Repeat("x", tokens*4)},
+		}},
+	})
+	return string(body)
+}
+
+func postBatchAnalyze(server *Server, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("POST", "/api/v1/batch/analyze", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBatchAnalyzeValidation(t *testing.T) {
+	server := setupBatchTestServer(t)
+
+	tests := []struct {
+		name           string
+		body           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "missing token",
+			body:           adhocBody("my-sdk", 10),
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+		{
+			name:           "empty analyses",
+			body:           `{"analyses": []}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "missing code",
+			body:           `{"analyses": [{"sdk_name": "my-sdk", "version": "1.0.0"}]}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "body over limit",
+			body:           adhocBody("my-sdk", 20000),
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "request_too_large",
+		},
+		{
+			name:           "token budget exceeded",
+			body:           adhocBody("my-sdk", 12000),
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedError:  "token_budget_exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/batch/analyze", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedError, response.Error)
+		})
+	}
+}
+
+func TestBatchAnalyzeBodyWithoutContentLength(t *testing.T) {
+	server := setupBatchTestServer(t)
+
+	req, _ := http.NewRequest("POST", "/api/v1/batch/analyze", strings.NewReader(adhocBody("my-sdk", 20000)))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestBatchAnalyzeSync(t *testing.T) {
+	server := setupBatchTestServer(t)
+
+	w := postBatchAnalyze(server, adhocBody("my-sdk", 100))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data analyzer.BatchAnalysisResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "completed", response.Data.Status)
+	require.Contains(t, response.Data.Results, "my-sdk")
+	assert.Empty(t, response.Data.Errors)
+
+	cached, err := server.cache.Get(worker.AdhocCacheKey("my-sdk", "1.0.0"))
+	require.NoError(t, err)
+	assert.NotEmpty(t, cached)
+}
+
+func TestBatchAnalyzeAsync(t *testing.T) {
+	server := setupBatchTestServer(t)
+	body := adhocBody("large-sdk", 5000)
+
+	w := postBatchAnalyze(server, body)
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started struct {
+		Data analyzer.BatchAnalysisResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	assert.Equal(t, "processing", started.Data.Status)
+	require.NotEmpty(t, started.Data.JobID)
+
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", fmt.Sprintf("/api/v1/jobs/%s/progress", started.Data.JobID), nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		var progress struct {
+			Data worker.JobProgress `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+		return progress.Data.Status == worker.JobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The result is cached once the job finishes
+	_, err := server.cache.Get(worker.AdhocCacheKey("large-sdk", "1.0.0"))
+	require.NoError(t, err)
+}
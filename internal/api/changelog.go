@@ -0,0 +1,119 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+)
+
+// maxChangelogCommits caps the commits GET /api/v1/cache/sdk/:name/changelog
+// returns.
+const maxChangelogCommits = 100
+
+// shortHashLength is the length of the abbreviated commit hashes in
+// changelogs.
+const shortHashLength = 7
+
+// changelogCommit is a commit as listed by the changelog endpoint.
+type changelogCommit struct {
+	Hash         string    `json:"hash"`
+	Author       string    `json:"author"`
+	Message      string    `json:"message"`
+	FilesChanged int       `json:"files_changed"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// handleSDKChangelog lists the commits made to an SDK's cloned repository
+// since it was last analyzed, or since the RFC 3339 since parameter, newest
+// first and at most maxChangelogCommits of them.
+func (s *Server) handleSDKChangelog(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "invalid_request",
+				Message:   "since must be an RFC 3339 timestamp",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		since = t
+	}
+
+	value, err := s.cache.GetContext(c.Request.Context(), "sdk:"+sdkName+":last_analyzed")
+	if err != nil {
+		s.respondCacheError(c, err, "SDK has not been analyzed yet")
+		return
+	}
+	if since.IsZero() {
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Invalid last analyzed timestamp")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "invalid_cache_entry",
+				Message:   "Cached last analyzed timestamp is invalid",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+	}
+
+	commits, err := s.worker.SDKCommits(c.Request.Context(), sdkName, since, maxChangelogCommits)
+	var notFound *apperrors.SDKNotFoundError
+	switch {
+	case errors.As(err, &notFound):
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + notFound.Name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case errors.Is(err, git.ErrRepoNotCloned):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     "conflict",
+			Message:   "Repository of SDK " + sdkName + " is not cloned",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case err != nil:
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to read SDK commits")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to read SDK commits",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	changelog := make([]changelogCommit, len(commits))
+	for i, commit := range commits {
+		changelog[i] = changelogCommit{
+			Hash:         commit.Hash[:min(len(commit.Hash), shortHashLength)],
+			Author:       commit.Author,
+			Message:      strings.TrimSpace(commit.Message),
+			FilesChanged: len(commit.Files),
+			Timestamp:    commit.Timestamp,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      changelog,
+		Message:   "SDK changelog retrieved",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
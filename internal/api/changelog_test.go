@@ -0,0 +1,120 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+type changelogResponse struct {
+	Data []struct {
+		Hash         string    `json:"hash"`
+		Author       string    `json:"author"`
+		Message      string    `json:"message"`
+		FilesChanged int       `json:"files_changed"`
+		Timestamp    time.Time `json:"timestamp"`
+	} `json:"data"`
+}
+
+// cloneTestRepo creates the clone of an SDK's repository where the update
+// worker looks for it, with one commit an hour from start, each adding a
+// file, and returns the commit hashes in order.
+func cloneTestRepo(t *testing.T, server *Server, sdkName string, start time.Time, commits int) []string {
+	t.Helper()
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	cfg, ok := configs.FindSDK(sdkName)
+	require.True(t, ok)
+
+	repoPath := filepath.Join(server.config.CacheDir, "repos", filepath.Base(cfg.URL))
+	repo, err := gogit.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	hashes := make([]string, commits)
+	for i := range commits {
+		name := fmt.Sprintf("file%d.go", i)
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte("package sdk\n"), 0644))
+		_, err = w.Add(name)
+		require.NoError(t, err)
+		signature := &object.Signature{
+			Name:  "Test",
+			Email: "test@example.com",
+			When:  start.Add(time.Duration(i+1) * time.Hour),
+		}
+		hash, err := w.Commit(fmt.Sprintf("Add %s\n", name), &gogit.CommitOptions{Author: signature, Committer: signature})
+		require.NoError(t, err)
+		hashes[i] = hash.String()
+	}
+	return hashes
+}
+
+func TestSDKChangelog(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	get := func(path string) (int, changelogResponse) {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response changelogResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	// Not analyzed yet
+	code, _ := get("/api/v1/cache/sdk/sentry-go/changelog")
+	assert.Equal(t, http.StatusNotFound, code)
+
+	analyzedAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	hashes := cloneTestRepo(t, server, "sentry-go", analyzedAt, 3)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", analyzedAt.Format(time.RFC3339), time.Hour))
+
+	code, response := get("/api/v1/cache/sdk/sentry-go/changelog")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Data, 3)
+	for i, commit := range response.Data {
+		// Newest first
+		n := len(hashes) - 1 - i
+		assert.Equal(t, hashes[n][:7], commit.Hash)
+		assert.Equal(t, "test@example.com", commit.Author)
+		assert.Equal(t, fmt.Sprintf("Add file%d.go", n), commit.Message)
+		assert.Equal(t, 1, commit.FilesChanged)
+		assert.True(t, analyzedAt.Add(time.Duration(n+1)*time.Hour).Equal(commit.Timestamp))
+	}
+
+	// since overrides the last analysis
+	since := analyzedAt.Add(150 * time.Minute).Format(time.RFC3339)
+	code, response = get("/api/v1/cache/sdk/sentry-go/changelog?since=" + since)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Data, 1)
+	assert.Equal(t, hashes[2][:7], response.Data[0].Hash)
+
+	code, _ = get("/api/v1/cache/sdk/sentry-go/changelog?since=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Analyzed but no longer cloned
+	require.NoError(t, cacheManager.Set("sdk:sentry-python:last_analyzed", analyzedAt.Format(time.RFC3339), time.Hour))
+	code, _ = get("/api/v1/cache/sdk/sentry-python/changelog")
+	assert.Equal(t, http.StatusConflict, code)
+}
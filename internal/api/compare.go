@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+// handleSDKCompare diffs two cached analyses of an SDK. version1 selects the
+// baseline; version2 defaults to the latest analysis.
+func (s *Server) handleSDKCompare(c *gin.Context) {
+	sdkName := c.Param("name")
+	version1 := c.Query("version1")
+	version2 := c.Query("version2")
+
+	if version1 == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "version1 is required",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	diff, ok := s.diffSDKVersions(c, sdkName, version1, version2)
+	if !ok {
+		return
+	}
+
+	if version2 == "" {
+		version2 = "latest"
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk":              sdkName,
+			"version1":         version1,
+			"version2":         version2,
+			"changed":          diff.Changed,
+			"lists":            diff.Lists,
+			"similarity_score": diff.SimilarityScore,
+		},
+		Message:   "SDK analyses compared",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleSDKDiff diffs two cached analyses of an SDK like handleSDKCompare,
+// selected by the from and to query parameters.
+func (s *Server) handleSDKDiff(c *gin.Context) {
+	sdkName := c.Param("name")
+	from := c.Query("from")
+	to := c.Query("to")
+
+	if from == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "from is required",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	diff, ok := s.diffSDKVersions(c, sdkName, from, to)
+	if !ok {
+		return
+	}
+
+	if to == "" {
+		to = "latest"
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk":              sdkName,
+			"from":             from,
+			"to":               to,
+			"changed":          diff.Changed,
+			"lists":            diff.Lists,
+			"similarity_score": diff.SimilarityScore,
+		},
+		Message:   "SDK analyses compared",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// respondSDKComparison responds with the latest analysis of an SDK, the
+// analysis of version and the diff between them under a comparison key.
+func (s *Server) respondSDKComparison(c *gin.Context, sdkName, version string) {
+	compared, ok := s.loadComparedAnalysis(c, sdkName, "sdk:"+sdkName+":"+version, "version "+version)
+	if !ok {
+		return
+	}
+	current, ok := s.loadComparedAnalysis(c, sdkName, "sdk:"+sdkName, "latest analysis")
+	if !ok {
+		return
+	}
+
+	diff := analyzer.DiffAnalyses(compared, current)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk": sdkName,
+			"comparison": gin.H{
+				"compare_to": version,
+				"current":    current,
+				"compared":   compared,
+				"diff":       diff,
+			},
+		},
+		Message:   "SDK analyses compared",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// diffSDKVersions diffs the cached analysis of version from against that of
+// version to, or the latest analysis when to is empty.
+func (s *Server) diffSDKVersions(c *gin.Context, sdkName, from, to string) (analyzer.AnalysisDiff, bool) {
+	fromAnalysis, ok := s.loadComparedAnalysis(c, sdkName, "sdk:"+sdkName+":"+from, "version "+from)
+	if !ok {
+		return analyzer.AnalysisDiff{}, false
+	}
+
+	toKey, toLabel := "sdk:"+sdkName, "latest analysis"
+	if to != "" {
+		toKey, toLabel = "sdk:"+sdkName+":"+to, "version "+to
+	}
+	toAnalysis, ok := s.loadComparedAnalysis(c, sdkName, toKey, toLabel)
+	if !ok {
+		return analyzer.AnalysisDiff{}, false
+	}
+
+	return analyzer.DiffAnalyses(fromAnalysis, toAnalysis), true
+}
+
+// loadComparedAnalysis reads a cached analysis for comparison, writing an
+// error response naming label when it is missing or unreadable.
+func (s *Server) loadComparedAnalysis(c *gin.Context, sdkName, key, label string) (*analyzer.SDKAnalysis, bool) {
+	value, err := s.cache.GetContext(c.Request.Context(), key)
+	if err != nil {
+		s.respondCacheError(c, err, fmt.Sprintf("SDK analysis not found for %s", label))
+		return nil, false
+	}
+
+	var analysis analyzer.SDKAnalysis
+	if err := json.Unmarshal([]byte(value), &analysis); err != nil {
+		s.logger.Error().Err(err).Str("sdk", sdkName).Str("key", key).Msg("Failed to parse cached SDK analysis")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Cached SDK analysis is invalid",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return nil, false
+	}
+	return &analysis, true
+}
@@ -0,0 +1,273 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+func TestSDKCompareEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	v1, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:        "go",
+		Transport:       analyzer.TransportDetails{Type: "http", RetryMechanism: "none"},
+		Features:        []string{"breadcrumbs", "sessions"},
+		ProtocolVersion: "7",
+		AnalysisVersion: "1.0.0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:1.0.0", string(v1), 0))
+
+	v2, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:        "go",
+		Transport:       analyzer.TransportDetails{Type: "http", RetryMechanism: "backoff"},
+		Features:        []string{"breadcrumbs", "sessions", "tracing"},
+		ProtocolVersion: "7",
+		AnalysisVersion: "2.0.0",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:2.0.0", string(v2), 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", string(v2), 0))
+
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedVersion2 string
+		expectedMessage  string
+	}{
+		{name: "explicit versions", query: "?version1=1.0.0&version2=2.0.0", expectedStatus: http.StatusOK, expectedVersion2: "2.0.0"},
+		{name: "latest", query: "?version1=1.0.0", expectedStatus: http.StatusOK, expectedVersion2: "latest"},
+		{name: "missing version1", query: "", expectedStatus: http.StatusBadRequest},
+		{name: "version1 not cached", query: "?version1=0.9.0&version2=2.0.0", expectedStatus: http.StatusNotFound, expectedMessage: "SDK analysis not found for version 0.9.0"},
+		{name: "version2 not cached", query: "?version1=1.0.0&version2=3.0.0", expectedStatus: http.StatusNotFound, expectedMessage: "SDK analysis not found for version 3.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/sdks/sentry-go/compare"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				if tt.expectedMessage != "" {
+					var response ErrorResponse
+					require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+					assert.Equal(t, tt.expectedMessage, response.Message)
+				}
+				return
+			}
+
+			var response struct {
+				Data struct {
+					SDK             string                       `json:"sdk"`
+					Version1        string                       `json:"version1"`
+					Version2        string                       `json:"version2"`
+					Changed         []analyzer.FieldChange       `json:"changed"`
+					Lists           map[string]analyzer.ListDiff `json:"lists"`
+					SimilarityScore float64                      `json:"similarity_score"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "sentry-go", response.Data.SDK)
+			assert.Equal(t, "1.0.0", response.Data.Version1)
+			assert.Equal(t, tt.expectedVersion2, response.Data.Version2)
+			assert.Equal(t, []analyzer.FieldChange{
+				{Field: "transport.retry_mechanism", Old: "none", New: "backoff"},
+			}, response.Data.Changed)
+			assert.Equal(t, map[string]analyzer.ListDiff{
+				"features": {Added: []string{"tracing"}, Removed: []string{}},
+			}, response.Data.Lists)
+			assert.InDelta(t, 2.0/3.0, response.Data.SimilarityScore, 1e-9)
+		})
+	}
+}
+
+func TestSDKCompareEndpointLatestMissing(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:1.0.0", `{"language":"go"}`, 0))
+
+	req, _ := http.NewRequest("GET", "/api/v1/sdks/sentry-go/compare?version1=1.0.0", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var response ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "SDK analysis not found for latest analysis", response.Message)
+}
+
+func TestSDKDiffEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	v1, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:        "python",
+		Transport:       analyzer.TransportDetails{Type: "http"},
+		Integrations:    []string{"django"},
+		CachingPatterns: []analyzer.CachingPattern{{Type: "envelope"}, {Type: "rate_limit"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-python:1.0.0", string(v1), 0))
+
+	v2, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:        "python",
+		Transport:       analyzer.TransportDetails{Type: "http2"},
+		Integrations:    []string{"django", "flask"},
+		CachingPatterns: []analyzer.CachingPattern{{Type: "envelope"}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-python:2.0.0", string(v2), 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", string(v2), 0))
+
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedTo     string
+		expectEmpty    bool
+	}{
+		{name: "explicit versions", query: "?from=1.0.0&to=2.0.0", expectedStatus: http.StatusOK, expectedTo: "2.0.0"},
+		{name: "latest", query: "?from=1.0.0", expectedStatus: http.StatusOK, expectedTo: "latest"},
+		{name: "identical", query: "?from=2.0.0&to=2.0.0", expectedStatus: http.StatusOK, expectedTo: "2.0.0", expectEmpty: true},
+		{name: "missing from", query: "?to=2.0.0", expectedStatus: http.StatusBadRequest},
+		{name: "not cached", query: "?from=0.9.0", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-python/diff"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					From    string                       `json:"from"`
+					To      string                       `json:"to"`
+					Changed []analyzer.FieldChange       `json:"changed"`
+					Lists   map[string]analyzer.ListDiff `json:"lists"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedTo, response.Data.To)
+
+			if tt.expectEmpty {
+				assert.Empty(t, response.Data.Changed)
+				assert.Empty(t, response.Data.Lists)
+				return
+			}
+			assert.Equal(t, "1.0.0", response.Data.From)
+			assert.Equal(t, []analyzer.FieldChange{
+				{Field: "transport.type", Old: "http", New: "http2"},
+			}, response.Data.Changed)
+			assert.Equal(t, map[string]analyzer.ListDiff{
+				"integrations":     {Added: []string{"flask"}, Removed: []string{}},
+				"caching_patterns": {Added: []string{}, Removed: []string{"rate_limit"}},
+			}, response.Data.Lists)
+		})
+	}
+}
+
+func TestGetSDKCacheCompareTo(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	v1, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:     "ruby",
+		Transport:    analyzer.TransportDetails{Type: "http"},
+		Integrations: []string{"rails"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-ruby:1.0.0", string(v1), 0))
+
+	current, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:     "ruby",
+		Transport:    analyzer.TransportDetails{Type: "http2"},
+		Integrations: []string{"rails", "sidekiq"},
+	})
+	require.NoError(t, err)
+
+	get := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-ruby"+query, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// The latest analysis is not cached yet
+	w := get("?compare_to=1.0.0")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var errResponse ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	assert.Equal(t, "SDK analysis not found for latest analysis", errResponse.Message)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-ruby", string(current), 0))
+
+	w = get("?compare_to=0.9.0")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	assert.Equal(t, "SDK analysis not found for version 0.9.0", errResponse.Message)
+
+	w = get("?compare_to=1.0.0")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			SDK        string `json:"sdk"`
+			Comparison struct {
+				CompareTo string                `json:"compare_to"`
+				Current   analyzer.SDKAnalysis  `json:"current"`
+				Compared  analyzer.SDKAnalysis  `json:"compared"`
+				Diff      analyzer.AnalysisDiff `json:"diff"`
+			} `json:"comparison"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	comparison := response.Data.Comparison
+	assert.Equal(t, "sentry-ruby", response.Data.SDK)
+	assert.Equal(t, "1.0.0", comparison.CompareTo)
+	assert.Equal(t, "http2", comparison.Current.Transport.Type)
+	assert.Equal(t, "http", comparison.Compared.Transport.Type)
+	assert.Equal(t, []analyzer.FieldChange{
+		{Field: "transport.type", Old: "http", New: "http2"},
+	}, comparison.Diff.Changed)
+	assert.Equal(t, map[string]analyzer.ListDiff{
+		"integrations": {Added: []string{"sidekiq"}, Removed: []string{}},
+	}, comparison.Diff.Lists)
+
+	// Without compare_to the cached JSON is returned as before
+	w = get("")
+	require.Equal(t, http.StatusOK, w.Code)
+	var plain struct {
+		Data string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &plain))
+	assert.JSONEq(t, string(current), plain.Data)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+var protocolChecker = analyzer.NewProtocolChecker()
+
+// handleSDKCompliance checks the cached analysis of an SDK against the
+// Sentry protocol rules. The check runs on every request so analyses cached
+// before a rule change are reported against the current rules.
+func (s *Server) handleSDKCompliance(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	value, err := s.cache.GetContext(c.Request.Context(), "sdk:"+sdkName)
+	if err != nil {
+		s.respondCacheError(c, err, "SDK analysis not found")
+		return
+	}
+
+	var analysis analyzer.SDKAnalysis
+	if err := json.Unmarshal([]byte(value), &analysis); err != nil {
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to parse cached SDK analysis")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Cached SDK analysis is invalid",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	violations := protocolChecker.Check(&analysis)
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk":        sdkName,
+			"compliant":  len(violations) == 0,
+			"violations": violations,
+		},
+		Message:   "SDK compliance checked",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
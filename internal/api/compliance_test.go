@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+func TestSDKComplianceEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	compliant, err := json.Marshal(analyzer.SDKAnalysis{
+		EnvelopeFormat:  "JSON envelope",
+		Transport:       analyzer.TransportDetails{Protocols: []string{"https"}, RetryMechanism: "backoff"},
+		EventTypes:      []string{"error"},
+		ProtocolVersion: "7",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", string(compliant), 0))
+
+	legacy, err := json.Marshal(analyzer.SDKAnalysis{
+		EnvelopeFormat:  "JSON envelope",
+		Transport:       analyzer.TransportDetails{Protocols: []string{"https"}, RetryMechanism: "backoff"},
+		EventTypes:      []string{"error"},
+		ProtocolVersion: "6",
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-legacy", string(legacy), 0))
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-broken", "not json", 0))
+
+	tests := []struct {
+		name           string
+		sdk            string
+		expectedStatus int
+		compliant      bool
+		rules          []string
+	}{
+		{name: "compliant", sdk: "sentry-go", expectedStatus: http.StatusOK, compliant: true, rules: []string{}},
+		{name: "violations", sdk: "sentry-legacy", expectedStatus: http.StatusOK, rules: []string{"protocol_version"}},
+		{name: "not cached", sdk: "sentry-missing", expectedStatus: http.StatusNotFound},
+		{name: "invalid analysis", sdk: "sentry-broken", expectedStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/sdks/"+tt.sdk+"/compliance", nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					SDK        string                         `json:"sdk"`
+					Compliant  bool                           `json:"compliant"`
+					Violations []analyzer.ComplianceViolation `json:"violations"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.sdk, response.Data.SDK)
+			assert.Equal(t, tt.compliant, response.Data.Compliant)
+
+			rules := []string{}
+			for _, v := range response.Data.Violations {
+				rules = append(rules, v.Rule)
+			}
+			assert.Equal(t, tt.rules, rules)
+		})
+	}
+}
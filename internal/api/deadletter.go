@@ -0,0 +1,87 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// handleListDeadLetters lists the SDKs whose analyses failed, most recent
+// failure first.
+func (s *Server) handleListDeadLetters(c *gin.Context) {
+	failures, err := s.worker.DeadLetters().List()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list failed analyses")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to list failed analyses",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      failures,
+		Message:   "Failed analyses retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleRetryDeadLetter removes an SDK from the dead-letter queue and queues
+// a forced refresh of it.
+func (s *Server) handleRetryDeadLetter(c *gin.Context) {
+	name := c.Param("sdk")
+
+	job, position, err := s.worker.RetryDeadLetter(name)
+	if err != nil {
+		switch {
+		case errors.Is(err, worker.ErrNotDeadLettered), errors.Is(err, worker.ErrUnknownSDK):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "not_found",
+				Message:   "No failed analyses of SDK " + name,
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		case errors.Is(err, worker.ErrDraining):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Worker is shutting down",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		case errors.Is(err, worker.ErrQueueFull):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Refresh queue is full, try again later",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		default:
+			s.logger.Error().Err(err).Str("sdk", name).Msg("Failed to retry SDK analysis")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "internal_error",
+				Message:   "Failed to retry SDK analysis",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Data: gin.H{
+			"job_id":   job.ID,
+			"sdk":      name,
+			"position": position,
+		},
+		Message:   "SDK analysis retry queued",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+)
+
+func TestDeadLetterEndpoints(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	serve := func(method, path, token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	list := func() []analytics.FailedAnalysis {
+		w := serve("GET", "/api/v1/worker/dead-letter", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data []analytics.FailedAnalysis `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	assert.Empty(t, list())
+
+	failedAt := time.Now().Truncate(time.Second)
+	_, err := server.worker.DeadLetters().Record("sentry-go", "clone failed", failedAt)
+	require.NoError(t, err)
+
+	failures := list()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "sentry-go", failures[0].SDK)
+	assert.Equal(t, "clone failed", failures[0].Error)
+	assert.Equal(t, 1, failures[0].Attempts)
+	assert.True(t, failures[0].FailedAt.Equal(failedAt))
+
+	// Retrying needs the admin key
+	w := serve("POST", "/api/v1/worker/dead-letter/sentry-go/retry", testAPIKeys[0])
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = serve("POST", "/api/v1/worker/dead-letter/sentry-python/retry", testAdminKey)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = serve("POST", "/api/v1/worker/dead-letter/sentry-go/retry", testAdminKey)
+	require.Equal(t, http.StatusAccepted, w.Code)
+	var response struct {
+		Data struct {
+			JobID string `json:"job_id"`
+			SDK   string `json:"sdk"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.Data.JobID)
+	assert.Equal(t, "sentry-go", response.Data.SDK)
+
+	assert.Empty(t, list())
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+// respondCacheError reports a failed cache read. A missing key is a 404
+// with message; any other error means the cache itself failed, and is
+// logged and reported as a 500.
+func (s *Server) respondCacheError(c *gin.Context, err error, message string) {
+	var miss *apperrors.CacheMissError
+	if errors.As(err, &miss) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   message,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.logger.Error().Err(err).Str("path", c.FullPath()).Msg("Failed to read cache")
+	c.JSON(http.StatusInternalServerError, ErrorResponse{
+		Error:     "internal_error",
+		Message:   "Failed to read cache",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// respondRateLimitError reports that the Claude API is rate limiting the
+// service, passing on how long it asked callers to wait.
+func respondRateLimitError(c *gin.Context, err *apperrors.RateLimitError) {
+	if err.RetryAfter > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(err.RetryAfter.Seconds()))))
+	}
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{
+		Error:     "rate_limited",
+		Message:   "Claude API rate limit reached, try again later",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+func TestRespondAnalyzeError(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	rateLimited := &apperrors.RateLimitError{RetryAfter: 1500 * time.Millisecond}
+
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedError  string
+		retryAfter     string
+	}{
+		{
+			name:           "unknown sdk",
+			err:            &apperrors.SDKNotFoundError{Name: "sentry-cobol"},
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+		{
+			name:           "draining",
+			err:            worker.ErrDraining,
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedError:  "unavailable",
+		},
+		{
+			name:           "rate limited",
+			err:            &apperrors.AnalysisFailedError{SDK: "sentry-go", Cause: fmt.Errorf("max retries exceeded: %w", rateLimited)},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedError:  "rate_limited",
+			retryAfter:     "2",
+		},
+		{
+			name:           "analysis failed",
+			err:            &apperrors.AnalysisFailedError{SDK: "sentry-go", Cause: errors.New("clone failed")},
+			expectedStatus: http.StatusBadGateway,
+			expectedError:  "analysis_failed",
+		},
+		{
+			name:           "other error",
+			err:            errors.New("failed to cache analysis"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedError:  "internal_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			server.respondAnalyzeError(c, "sentry-go", tt.err)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.retryAfter, w.Header().Get("Retry-After"))
+
+			var errorResponse ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse.Error)
+		})
+	}
+}
+
+func TestRespondCacheError(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	// A real miss from the cache manager is a 404
+	_, err := cacheManager.Get("sdk:sentry-cobol")
+	var miss *apperrors.CacheMissError
+	require.True(t, errors.As(err, &miss))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	server.respondCacheError(c, err, "SDK cache not found")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var errorResponse ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, "not_found", errorResponse.Error)
+	assert.Equal(t, "SDK cache not found", errorResponse.Message)
+
+	// Any other failure is the cache's fault, not a missing entry
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	server.respondCacheError(c, errors.New("database is closed"), "SDK cache not found")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+	assert.Equal(t, "internal_error", errorResponse.Error)
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Cache export formats, chosen with the Accept header
+const (
+	exportFormatNDJSON = "application/x-ndjson"
+	exportFormatCSV    = "text/csv"
+	exportFormatZip    = "application/zip"
+)
+
+// exportFormats are the formats handleExportCache offers, the first being
+// the default for clients that accept anything.
+var exportFormats = []string{exportFormatNDJSON, exportFormatCSV, exportFormatZip}
+
+// handleExportCache streams every unexpired cache entry as newline-delimited
+// JSON, a CSV summary without values, or a ZIP archive of the values,
+// depending on the Accept header. Errors after the first entry has been
+// written can only be logged; clients see a truncated body.
+func (s *Server) handleExportCache(c *gin.Context) {
+	c.Header("Vary", "Accept")
+
+	format := c.NegotiateFormat(exportFormats...)
+	var (
+		extension string
+		export    func(context.Context, io.Writer) error
+	)
+	switch format {
+	case exportFormatNDJSON:
+		extension, export = "ndjson", s.cache.ExportCache
+	case exportFormatCSV:
+		extension, export = "csv", s.cache.ExportCacheCSV
+	case exportFormatZip:
+		extension, export = "zip", s.cache.ExportCacheZip
+	default:
+		c.JSON(http.StatusNotAcceptable, ErrorResponse{
+			Error:     "not_acceptable",
+			Message:   "Accept must allow " + strings.Join(exportFormats, ", ") + " or */*",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("cache-%s.%s", time.Now().UTC().Format("20060102-150405"), extension)
+	c.Header("Content-Type", format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	if err := export(c.Request.Context(), c.Writer); err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("format", format).
+			Str("request_id", c.GetString("request_id")).
+			Msg("Failed to export cache")
+		return
+	}
+	s.logger.Info().
+		Str("format", format).
+		Str("request_id", c.GetString("request_id")).
+		Msg("Cache exported")
+}
+
+// handleImportCache loads an export made by handleExportCache, uploaded
+// either as the "file" field of a multipart form or as the raw request
+// body. Existing keys are kept unless overwrite=true.
+func (s *Server) handleImportCache(c *gin.Context) {
+	overwrite, err := strconv.ParseBool(c.DefaultQuery("overwrite", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "overwrite must be true or false",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	var body io.Reader = c.Request.Body
+	if mediaType, _, err := mime.ParseMediaType(c.ContentType()); err == nil && mediaType == "multipart/form-data" {
+		header, err := c.FormFile("file")
+		if err != nil {
+			if isBodyTooLarge(err) {
+				s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+				return
+			}
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "invalid_request",
+				Message:   "Multipart uploads must include the export in a \"file\" field",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		file, err := header.Open()
+		if err != nil {
+			s.logger.Error().Err(err).Msg("Failed to open uploaded cache export")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "internal_error",
+				Message:   "Failed to read uploaded file",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				s.logger.Error().Err(err).Msg("Failed to close uploaded cache export")
+			}
+		}()
+		body = file
+	}
+
+	imported, err := s.cache.ImportCache(c.Request.Context(), body, overwrite)
+	if err != nil {
+		s.logger.Warn().
+			Err(err).
+			Int("imported", imported).
+			Str("request_id", c.GetString("request_id")).
+			Msg("Cache import stopped early")
+		if isBodyTooLarge(err) {
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error:     "request_too_large",
+				Message:   fmt.Sprintf("Request body exceeds %d bytes; import stopped after %d entries", s.config.MaxRequestBodyBytes, imported),
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   fmt.Sprintf("Import stopped after %d entries: %v", imported, err),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.logger.Info().
+		Int("imported", imported).
+		Bool("overwrite", overwrite).
+		Str("request_id", c.GetString("request_id")).
+		Msg("Cache imported")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"imported": imported,
+		},
+		Message:   "Cache imported successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,277 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func exportCache(t *testing.T, server *Server) []byte {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/api/v1/cache/export", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+	return w.Body.Bytes()
+}
+
+func importCache(t *testing.T, server *Server, body io.Reader, contentType, query string) (int, int) {
+	t.Helper()
+
+	req, _ := http.NewRequest("POST", "/api/v1/cache/import"+query, body)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	var response struct {
+		Data struct {
+			Imported int `json:"imported"`
+		} `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response.Data.Imported
+}
+
+func TestExportImportEndpoints(t *testing.T) {
+	dataset := map[string]string{
+		"sdk:sentry-go":     `{"language":"go"}`,
+		"sdk:sentry-python": `{"language":"python"}`,
+		"project:gremlin":   "arrow flight",
+	}
+
+	tests := []struct {
+		name   string
+		upload func(t *testing.T, export []byte) (io.Reader, string)
+	}{
+		{
+			name: "raw body",
+			upload: func(t *testing.T, export []byte) (io.Reader, string) {
+				return bytes.NewReader(export), "application/x-ndjson"
+			},
+		},
+		{
+			name: "multipart upload",
+			upload: func(t *testing.T, export []byte) (io.Reader, string) {
+				var body bytes.Buffer
+				form := multipart.NewWriter(&body)
+				part, err := form.CreateFormFile("file", "cache.ndjson")
+				require.NoError(t, err)
+				_, err = part.Write(export)
+				require.NoError(t, err)
+				require.NoError(t, form.Close())
+				return &body, form.FormDataContentType()
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cacheManager := setupTestServer(t)
+			defer func() {
+				err := cacheManager.Close()
+				require.NoError(t, err)
+			}()
+
+			for key, value := range dataset {
+				require.NoError(t, cacheManager.Set(key, value, time.Hour))
+			}
+
+			export := exportCache(t, server)
+			assert.Len(t, strings.Split(strings.TrimSpace(string(export)), "\n"), len(dataset))
+
+			_, err := cacheManager.DeleteByPrefix("")
+			require.NoError(t, err)
+
+			body, contentType := tt.upload(t, export)
+			code, imported := importCache(t, server, body, contentType, "")
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, len(dataset), imported)
+
+			for key, value := range dataset {
+				got, err := cacheManager.Get(key)
+				require.NoError(t, err, key)
+				assert.Equal(t, value, got, key)
+			}
+		})
+	}
+}
+
+func TestImportEndpointOverwrite(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "local", 0))
+	export := `{"key":"sdk:sentry-go","value":"imported"}` + "\n"
+
+	code, imported := importCache(t, server, strings.NewReader(export), "application/x-ndjson", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0, imported)
+
+	code, imported = importCache(t, server, strings.NewReader(export), "application/x-ndjson", "?overwrite=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 1, imported)
+
+	got, err := cacheManager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "imported", got)
+}
+
+func TestImportEndpointErrors(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		query       string
+	}{
+		{name: "invalid overwrite", body: "", contentType: "application/x-ndjson", query: "?overwrite=maybe"},
+		{name: "malformed record", body: "{not json\n", contentType: "application/x-ndjson"},
+		{name: "multipart without file", body: "--x--\r\n", contentType: "multipart/form-data; boundary=x"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := importCache(t, server, strings.NewReader(tt.body), tt.contentType, tt.query)
+			assert.Equal(t, http.StatusBadRequest, code)
+		})
+	}
+
+	// Both endpoints are admin-only
+	for _, req := range []*http.Request{
+		httptest.NewRequest("GET", "/api/v1/cache/export", nil),
+		httptest.NewRequest("POST", "/api/v1/cache/import", strings.NewReader("")),
+	} {
+		req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, req.URL.Path)
+	}
+}
+
+func TestExportFormats(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	dataset := map[string]string{
+		"sdk:sentry-go":     `{"language":"go"}`,
+		"sdk:sentry-python": `{"language":"python"}`,
+		"project:a/b":       "arrow flight",
+	}
+	for key, value := range dataset {
+		require.NoError(t, cacheManager.Set(key, value, time.Hour))
+	}
+	require.NoError(t, cacheManager.Set("sdks:total", "29", 0))
+	dataset["sdks:total"] = "29"
+
+	export := func(accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/export", nil)
+		req.Header.Set("Authorization", "Bearer "+testAdminKey)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ndjson", func(t *testing.T) {
+		w := export("application/x-ndjson")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		keys := make(map[string]string)
+		for _, line := range strings.Split(strings.TrimSpace(w.Body.String()), "\n") {
+			var entry cache.CacheEntry
+			require.NoError(t, json.Unmarshal([]byte(line), &entry))
+			keys[entry.Key] = entry.Value
+		}
+		assert.Equal(t, dataset, keys)
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		w := export("text/csv")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, len(dataset)+1)
+		assert.Equal(t, []string{"key", "value_length", "hit_count", "created_at", "ttl_seconds"}, records[0])
+
+		for _, record := range records[1:] {
+			value, ok := dataset[record[0]]
+			require.True(t, ok, record[0])
+			assert.Equal(t, strconv.Itoa(len(value)), record[1], record[0])
+			_, err := time.Parse(time.RFC3339, record[3])
+			assert.NoError(t, err, record[0])
+			if record[0] == "sdks:total" {
+				assert.Empty(t, record[4])
+				continue
+			}
+			ttl, err := strconv.Atoi(record[4])
+			require.NoError(t, err, record[0])
+			assert.InDelta(t, time.Hour.Seconds(), ttl, 5, record[0])
+		}
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		w := export("application/zip")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		require.NoError(t, err)
+		require.Len(t, archive.File, len(dataset))
+
+		for _, file := range archive.File {
+			key, err := url.PathUnescape(file.Name)
+			require.NoError(t, err)
+			assert.NotContains(t, file.Name, "/")
+
+			r, err := file.Open()
+			require.NoError(t, err)
+			value, err := io.ReadAll(r)
+			require.NoError(t, err)
+			require.NoError(t, r.Close())
+			assert.Equal(t, dataset[key], string(value), key)
+		}
+	})
+
+	t.Run("not acceptable", func(t *testing.T) {
+		w := export("application/pdf")
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// setFeatureRequest is the body of PATCH /api/v1/admin/features/:name.
+type setFeatureRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+func (s *Server) handleListFeatures(c *gin.Context) {
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      gin.H{"features": s.config.Features()},
+		Message:   "Feature flags retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handleSetFeature(c *gin.Context) {
+	name := c.Param("name")
+
+	if !config.IsKnownFeature(name) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown feature flag: " + name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	var req setFeatureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"enabled\": true|false}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	previous := s.config.SetFeature(name, *req.Enabled)
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Str("flag", name).
+		Bool("previous", previous).
+		Bool("enabled", *req.Enabled).
+		Msg("Feature flag changed")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"name":     name,
+			"enabled":  *req.Enabled,
+			"previous": previous,
+		},
+		Message:   "Feature flag updated successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
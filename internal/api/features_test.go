@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func TestListFeaturesEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/features", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Features map[string]bool `json:"features"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Features[config.FlagCacheHitTracking])
+	assert.Contains(t, response.Data.Features, config.FlagPromptCaching)
+	assert.False(t, response.Data.Features[config.FlagPromptCaching])
+}
+
+func TestSetFeatureEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name           string
+		flag           string
+		body           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "missing token",
+			flag:           config.FlagPromptCaching,
+			body:           `{"enabled": true}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+		{
+			name:           "unknown flag",
+			flag:           "no_such_flag",
+			body:           `{"enabled": true}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+		{
+			name:           "missing enabled field",
+			flag:           config.FlagPromptCaching,
+			body:           `{}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "enable flag",
+			flag:           config.FlagPromptCaching,
+			body:           `{"enabled": true}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("PATCH", "/api/v1/admin/features/"+tt.flag, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse ErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedError, errorResponse.Error)
+				assert.False(t, server.config.IsEnabled(config.FlagPromptCaching))
+				return
+			}
+
+			var response struct {
+				Data struct {
+					Name     string `json:"name"`
+					Enabled  bool   `json:"enabled"`
+					Previous bool   `json:"previous"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.flag, response.Data.Name)
+			assert.True(t, response.Data.Enabled)
+			assert.False(t, response.Data.Previous)
+			assert.True(t, server.config.IsEnabled(tt.flag))
+		})
+	}
+}
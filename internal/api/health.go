@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+	"github.com/ryanrussell/claude-cache-service/internal/telemetry"
+)
+
+// circuitDisabled is reported by /api/v1/health/claude when analyses do
+// not use the Claude API.
+const circuitDisabled = "disabled"
+
+// Statuses of a deep health check, from best to worst.
+const (
+	healthOK       = "ok"
+	healthDegraded = "degraded"
+	healthError    = "error"
+)
+
+// healthCheck is the result of one deep health check.
+type healthCheck struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// handleDeepHealth checks the cache database, the git work directory, the
+// Claude API key and the free disk space. The overall status is the worst
+// of the checks; it is served with 503 only when a check errored, since a
+// degraded service still answers from the cache.
+func (s *Server) handleDeepHealth(c *gin.Context) {
+	checks := map[string]healthCheck{
+		"cache":          s.checkCacheHealth(),
+		"git_work_dir":   s.checkGitWorkDir(),
+		"claude_api_key": s.checkClaudeAPIKey(),
+		"disk_space":     s.checkDiskSpace(),
+	}
+
+	status := healthOK
+	for name, check := range checks {
+		switch {
+		case check.Status == healthError:
+			status = healthError
+		case check.Status == healthDegraded && status == healthOK:
+			status = healthDegraded
+		}
+		if check.Status != healthOK {
+			s.logger.Warn().Str("check", name).Str("status", check.Status).Msg(check.Message)
+		}
+	}
+
+	code := http.StatusOK
+	if status == healthError {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"status":    status,
+		"version":   s.config.Version,
+		"checks":    checks,
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// checkCacheHealth writes, reads back and deletes a key in the cache
+// database.
+func (s *Server) checkCacheHealth() healthCheck {
+	if err := s.cache.CheckHealth(); err != nil {
+		return healthCheck{Status: healthError, Message: err.Error()}
+	}
+	return healthCheck{Status: healthOK, Message: "Cache database is readable and writable"}
+}
+
+// checkGitWorkDir checks that repositories can be cloned. Before the first
+// clone creates the directory, the check is only degraded.
+func (s *Server) checkGitWorkDir() healthCheck {
+	err := s.worker.CheckGitWorkDir()
+	switch {
+	case errors.Is(err, git.ErrWorkDirMissing):
+		return healthCheck{Status: healthDegraded, Message: err.Error()}
+	case err != nil:
+		return healthCheck{Status: healthError, Message: err.Error()}
+	}
+	return healthCheck{Status: healthOK, Message: "Git work directory is writable"}
+}
+
+// checkClaudeAPIKey checks that a Claude API key is configured. Without one
+// the cache is still served, but SDKs are not analyzed.
+func (s *Server) checkClaudeAPIKey() healthCheck {
+	if s.config.ClaudeAPIKey == "" {
+		return healthCheck{Status: healthDegraded, Message: "CLAUDE_API_KEY is not set"}
+	}
+	return healthCheck{Status: healthOK, Message: "Claude API key is set"}
+}
+
+// checkDiskSpace checks that CacheDir has HealthMinFreeDiskBytes free.
+func (s *Server) checkDiskSpace() healthCheck {
+	err := git.CheckDiskSpace(s.config.CacheDir, s.config.HealthMinFreeDiskBytes)
+	switch {
+	case errors.Is(err, git.ErrInsufficientDiskSpace):
+		return healthCheck{Status: healthDegraded, Message: err.Error()}
+	case err != nil:
+		return healthCheck{Status: healthError, Message: err.Error()}
+	}
+	return healthCheck{
+		Status:  healthOK,
+		Message: fmt.Sprintf("At least %d bytes free in %s", s.config.HealthMinFreeDiskBytes, s.config.CacheDir),
+	}
+}
+
+// handleTelemetryHealth reports whether traces are exported and whether
+// the last export reached the collector. It is served with 503 while
+// exports fail.
+func (s *Server) handleTelemetryHealth(c *gin.Context) {
+	status := telemetry.CurrentStatus()
+	code := http.StatusOK
+	if status.Enabled && status.LastError != "" {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"enabled":        status.Enabled,
+		"endpoint":       status.Endpoint,
+		"connected":      status.Connected,
+		"last_export_at": optionalTime(status.LastExportAt),
+		"last_error":     status.LastError,
+		"timestamp":      time.Now().Unix(),
+	})
+}
+
+// handleClaudeHealth reports the state of the Claude API circuit breaker.
+// It is served with 503 while the circuit is open, when analyses fail
+// without reaching the API.
+func (s *Server) handleClaudeHealth(c *gin.Context) {
+	breaker := s.worker.ClaudeCircuitBreaker()
+	if breaker == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"state":     circuitDisabled,
+			"message":   "Analyses do not use the Claude API",
+			"timestamp": time.Now().Unix(),
+		})
+		return
+	}
+
+	status := breaker.Status()
+	code := http.StatusOK
+	if status.State == claude.CircuitOpen {
+		code = http.StatusServiceUnavailable
+	}
+	c.JSON(code, gin.H{
+		"state":                status.State,
+		"consecutive_failures": status.ConsecutiveFailures,
+		"failure_threshold":    status.FailureThreshold,
+		"opened_at":            status.OpenedAt,
+		"retry_at":             status.RetryAt,
+		"timestamp":            time.Now().Unix(),
+	})
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+func TestDeepHealthEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		modify       func(t *testing.T, server *Server)
+		closeCache   bool
+		expectedCode int
+		status       string
+		failedCheck  string
+	}{
+		{
+			name:         "all checks pass",
+			expectedCode: http.StatusOK,
+			status:       healthOK,
+		},
+		{
+			name:         "cache database unavailable",
+			closeCache:   true,
+			expectedCode: http.StatusServiceUnavailable,
+			status:       healthError,
+			failedCheck:  "cache",
+		},
+		{
+			name: "git work directory not created yet",
+			modify: func(t *testing.T, server *Server) {
+				require.NoError(t, os.Remove(filepath.Join(server.config.CacheDir, "repos")))
+			},
+			expectedCode: http.StatusOK,
+			status:       healthDegraded,
+			failedCheck:  "git_work_dir",
+		},
+		{
+			name: "git work directory unusable",
+			modify: func(t *testing.T, server *Server) {
+				workDir := filepath.Join(server.config.CacheDir, "repos")
+				require.NoError(t, os.Remove(workDir))
+				require.NoError(t, os.WriteFile(workDir, nil, 0644))
+			},
+			expectedCode: http.StatusServiceUnavailable,
+			status:       healthError,
+			failedCheck:  "git_work_dir",
+		},
+		{
+			name: "Claude API key missing",
+			modify: func(t *testing.T, server *Server) {
+				server.config.ClaudeAPIKey = ""
+			},
+			expectedCode: http.StatusOK,
+			status:       healthDegraded,
+			failedCheck:  "claude_api_key",
+		},
+		{
+			name: "low disk space",
+			modify: func(t *testing.T, server *Server) {
+				server.config.HealthMinFreeDiskBytes = 1 << 62
+			},
+			expectedCode: http.StatusOK,
+			status:       healthDegraded,
+			failedCheck:  "disk_space",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cacheManager := setupTestServer(t)
+			server.config.ClaudeAPIKey = "test-key"
+			server.config.HealthMinFreeDiskBytes = 1 << 20
+			require.NoError(t, os.MkdirAll(filepath.Join(server.config.CacheDir, "repos"), 0755))
+
+			if tt.modify != nil {
+				tt.modify(t, server)
+			}
+			if tt.closeCache {
+				require.NoError(t, cacheManager.Close())
+			} else {
+				defer func() {
+					require.NoError(t, cacheManager.Close())
+				}()
+			}
+
+			req, _ := http.NewRequest("GET", "/api/v1/health/deep", nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+			assert.Equal(t, tt.expectedCode, w.Code, w.Body.String())
+
+			var response struct {
+				Status string                 `json:"status"`
+				Checks map[string]healthCheck `json:"checks"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.status, response.Status)
+
+			require.Len(t, response.Checks, 4)
+			for name, check := range response.Checks {
+				assert.NotEmpty(t, check.Message, name)
+				if name == tt.failedCheck {
+					assert.Equal(t, tt.status, check.Status, name)
+				} else {
+					assert.Equal(t, healthOK, check.Status, "%s: %s", name, check.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestClaudeHealthEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	request := func() (int, map[string]any) {
+		req, _ := http.NewRequest("GET", "/api/v1/health/claude", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+
+	// Without an API key analyses use the mock analyzer
+	code, response := request()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, circuitDisabled, response["state"])
+
+	server.config.ClaudeAPIKey = "test-key"
+	updateWorker := worker.NewUpdateWorker(cacheManager, server.logger, server.config)
+	server = NewServer(server.config, cacheManager, updateWorker, server.logger)
+	breaker := updateWorker.ClaudeCircuitBreaker()
+	require.NotNil(t, breaker)
+
+	code, response = request()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, string(claude.CircuitClosed), response["state"])
+	assert.Equal(t, float64(claude.DefaultFailureThreshold), response["failure_threshold"])
+	assert.Nil(t, response["opened_at"])
+
+	for i := 0; i < breaker.FailureThreshold; i++ {
+		require.NoError(t, breaker.Allow())
+		breaker.Failure()
+	}
+	code, response = request()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, string(claude.CircuitOpen), response["state"])
+	assert.Equal(t, float64(claude.DefaultFailureThreshold), response["consecutive_failures"])
+	assert.NotNil(t, response["opened_at"])
+	assert.NotNil(t, response["retry_at"])
+}
+
+func TestTelemetryHealthEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/health/telemetry", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Enabled      bool    `json:"enabled"`
+		Connected    bool    `json:"connected"`
+		LastExportAt *string `json:"last_export_at"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Enabled)
+	assert.False(t, response.Connected)
+	assert.Nil(t, response.LastExportAt)
+}
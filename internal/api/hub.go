@@ -0,0 +1,277 @@
+package api
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+const (
+	// wsPingInterval keeps idle connections open through proxies
+	wsPingInterval = 30 * time.Second
+
+	// wsWriteWait bounds how long a single write to a client may take
+	wsWriteWait = 10 * time.Second
+
+	// hubClientBuffer is how many events a client may fall behind by before
+	// further events are dropped
+	hubClientBuffer = 64
+)
+
+// EventRepoCleaned is sent when the worker removes the cloned repository of
+// a disabled SDK. Its key is "repo:<name>".
+const EventRepoCleaned cache.EventType = "repo_cleaned"
+
+// hubClient is a WebSocket connection subscribed to the hub.
+type hubClient struct {
+	conn       *websocket.Conn
+	topic      string
+	send       chan cache.CacheEvent
+	writerDone chan struct{}
+	closeOnce  sync.Once
+}
+
+// Hub fans cache events out to connected WebSocket clients, grouped by
+// topic. The subscriber sets are owned by the Run goroutine, so connects
+// and disconnects are serialized through channels.
+type Hub struct {
+	logger       zerolog.Logger
+	pingInterval time.Duration
+
+	register   chan *hubClient
+	unregister chan *websocket.Conn
+	published  chan cache.CacheEvent
+	done       chan struct{}
+}
+
+// NewHub creates a hub. Call Run to start delivering events.
+func NewHub(logger zerolog.Logger) *Hub {
+	return &Hub{
+		logger:       logger,
+		pingInterval: wsPingInterval,
+		register:     make(chan *hubClient),
+		unregister:   make(chan *websocket.Conn),
+		published:    make(chan cache.CacheEvent, hubClientBuffer),
+		done:         make(chan struct{}),
+	}
+}
+
+// matchesTopic reports whether key belongs to topic. The empty topic
+// matches every key; otherwise the key must equal the topic or continue it
+// after a colon, so "project:foo" does not match "project:foobar".
+func matchesTopic(topic, key string) bool {
+	if topic == "" || key == topic {
+		return true
+	}
+	return strings.HasPrefix(key, topic+":")
+}
+
+// Run delivers events to subscribers until events is closed, then
+// disconnects every client.
+func (h *Hub) Run(events <-chan cache.CacheEvent) {
+	clients := make(map[*websocket.Conn]*hubClient)
+	topics := make(map[string]map[*hubClient]struct{})
+	defer func() {
+		close(h.done)
+		for _, client := range clients {
+			close(client.send)
+		}
+	}()
+
+	for {
+		select {
+		case client := <-h.register:
+			clients[client.conn] = client
+			if topics[client.topic] == nil {
+				topics[client.topic] = make(map[*hubClient]struct{})
+			}
+			topics[client.topic][client] = struct{}{}
+		case conn := <-h.unregister:
+			client, ok := clients[conn]
+			if !ok {
+				continue
+			}
+			delete(clients, conn)
+			delete(topics[client.topic], client)
+			if len(topics[client.topic]) == 0 {
+				delete(topics, client.topic)
+			}
+			close(client.send)
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			h.deliver(topics, event)
+		case event := <-h.published:
+			h.deliver(topics, event)
+		}
+	}
+}
+
+// deliver sends event to the subscribers of every topic matching its key.
+func (h *Hub) deliver(topics map[string]map[*hubClient]struct{}, event cache.CacheEvent) {
+	for topic, subscribers := range topics {
+		if !matchesTopic(topic, event.Key) {
+			continue
+		}
+		for client := range subscribers {
+			select {
+			case client.send <- event:
+			default:
+				h.logger.Warn().
+					Str("remote", client.conn.RemoteAddr().String()).
+					Str("key", event.Key).
+					Msg("Dropped cache event for slow WebSocket client")
+			}
+		}
+	}
+}
+
+// Publish delivers an event that did not come from the cache, such as
+// EventRepoCleaned, alongside cache events. It never blocks; the event is
+// dropped if the hub has fallen behind or stopped.
+func (h *Hub) Publish(event cache.CacheEvent) {
+	select {
+	case <-h.done:
+		return
+	default:
+	}
+
+	select {
+	case h.published <- event:
+	default:
+		h.logger.Warn().Str("key", event.Key).Msg("Dropped event for WebSocket clients")
+	}
+}
+
+// Subscribe starts delivering events for keys in topic to conn, or every
+// event when topic is empty. It reports false if the hub has stopped. Call
+// it once per connection, and Unsubscribe when the client goes away.
+func (h *Hub) Subscribe(conn *websocket.Conn, topic string) bool {
+	_, ok := h.subscribe(conn, topic)
+	return ok
+}
+
+func (h *Hub) subscribe(conn *websocket.Conn, topic string) (*hubClient, bool) {
+	client := &hubClient{
+		conn:       conn,
+		topic:      topic,
+		send:       make(chan cache.CacheEvent, hubClientBuffer),
+		writerDone: make(chan struct{}),
+	}
+
+	select {
+	case h.register <- client:
+	case <-h.done:
+		return nil, false
+	}
+
+	// Closing the connection when the writer stops also ends the read pump
+	go func() {
+		defer close(client.writerDone)
+		defer h.closeConn(client)
+		h.writePump(client)
+	}()
+	return client, true
+}
+
+// Unsubscribe stops delivering events to conn and closes it after sending a
+// close frame.
+func (h *Hub) Unsubscribe(conn *websocket.Conn) {
+	select {
+	case h.unregister <- conn:
+	case <-h.done:
+	}
+}
+
+// Serve subscribes conn to topic and blocks until the client disconnects or
+// the hub stops. It closes conn before returning.
+func (h *Hub) Serve(conn *websocket.Conn, topic string) {
+	client, ok := h.subscribe(conn, topic)
+	if !ok {
+		if err := conn.Close(); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to close WebSocket connection")
+		}
+		return
+	}
+
+	h.readPump(client)
+	h.Unsubscribe(conn)
+	<-client.writerDone
+}
+
+// readPump discards client messages, extending the read deadline on every
+// pong, until the connection fails or is closed.
+func (h *Hub) readPump(client *hubClient) {
+	pongWait := 2 * h.pingInterval
+	extend := func() {
+		if err := client.conn.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+			h.logger.Debug().Err(err).Msg("Failed to set WebSocket read deadline")
+		}
+	}
+
+	extend()
+	client.conn.SetPongHandler(func(string) error {
+		extend()
+		return nil
+	})
+
+	for {
+		if _, _, err := client.conn.ReadMessage(); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				h.logger.Debug().Err(err).Msg("WebSocket connection closed unexpectedly")
+			}
+			return
+		}
+	}
+}
+
+// writePump sends events and heartbeat pings to the client. It is the only
+// writer on the connection, and returns after sending a close frame once the
+// hub closes the send channel.
+func (h *Hub) writePump(client *hubClient) {
+	ticker := time.NewTicker(h.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-client.send:
+			if !ok {
+				h.writeClose(client)
+				return
+			}
+			if err := client.conn.SetWriteDeadline(time.Now().Add(wsWriteWait)); err != nil {
+				h.logger.Debug().Err(err).Msg("Failed to set WebSocket write deadline")
+			}
+			if err := client.conn.WriteJSON(event); err != nil {
+				h.logger.Debug().Err(err).Msg("Failed to write cache event to WebSocket")
+				return
+			}
+		case <-ticker.C:
+			if err := client.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				h.logger.Debug().Err(err).Msg("Failed to ping WebSocket client")
+				return
+			}
+		}
+	}
+}
+
+func (h *Hub) writeClose(client *hubClient) {
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "")
+	if err := client.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(wsWriteWait)); err != nil {
+		h.logger.Debug().Err(err).Msg("Failed to send WebSocket close frame")
+	}
+}
+
+func (h *Hub) closeConn(client *hubClient) {
+	client.closeOnce.Do(func() {
+		if err := client.conn.Close(); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to close WebSocket connection")
+		}
+	})
+}
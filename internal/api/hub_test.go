@@ -0,0 +1,456 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func dialUpdates(t *testing.T, ts *httptest.Server) *websocket.Conn {
+	t.Helper()
+	return dialWebSocket(t, ts, "/ws/updates")
+}
+
+func dialWebSocket(t *testing.T, ts *httptest.Server, path string) *websocket.Conn {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + path
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	return conn
+}
+
+func readEvent(t *testing.T, conn *websocket.Conn) cache.CacheEvent {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var event cache.CacheEvent
+	require.NoError(t, conn.ReadJSON(&event))
+	return event
+}
+
+// waitForClients publishes events for probeKey until every conn has
+// received one, which shows the hub has registered it. Later reads may
+// still see probes.
+func waitForClients(t *testing.T, cacheManager *cache.Manager, probeKey string, conns ...*websocket.Conn) {
+	t.Helper()
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				assert.NoError(t, cacheManager.Set(probeKey, "x", 0))
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	for _, conn := range conns {
+		event := readEvent(t, conn)
+		require.Equal(t, probeKey, event.Key)
+	}
+}
+
+func TestWebSocketUpdatesBroadcast(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	first := dialUpdates(t, ts)
+	defer first.Close()
+	second := dialUpdates(t, ts)
+	defer second.Close()
+	waitForClients(t, cacheManager, "probe", first, second)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", 0))
+	require.NoError(t, cacheManager.Delete("sdk:sentry-go"))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", "{}", time.Second))
+
+	for _, conn := range []*websocket.Conn{first, second} {
+		// Drain probes still in flight from waitForClients
+		event := readEvent(t, conn)
+		for event.Key == "probe" {
+			event = readEvent(t, conn)
+		}
+
+		assert.Equal(t, cache.EventSet, event.Type)
+		assert.Equal(t, "sdk:sentry-go", event.Key)
+		assert.NotZero(t, event.Timestamp)
+
+		event = readEvent(t, conn)
+		assert.Equal(t, cache.EventDelete, event.Type)
+		assert.Equal(t, "sdk:sentry-go", event.Key)
+
+		event = readEvent(t, conn)
+		assert.Equal(t, cache.EventSet, event.Type)
+		assert.Equal(t, "sdk:sentry-python", event.Key)
+	}
+
+	// buntdb expires keys in a background sweep about once a second
+	event := readEvent(t, first)
+	assert.Equal(t, cache.EventExpire, event.Type)
+	assert.Equal(t, "sdk:sentry-python", event.Key)
+}
+
+func TestWebSocketUpdatesPayload(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	conn := dialUpdates(t, ts)
+	defer conn.Close()
+	waitForClients(t, cacheManager, "probe", conn)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", 0))
+
+	for {
+		var payload map[string]any
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		require.NoError(t, conn.ReadJSON(&payload))
+		if payload["key"] == "probe" {
+			continue
+		}
+
+		assert.Equal(t, "set", payload["event"])
+		assert.Equal(t, "sdk:sentry-go", payload["key"])
+		assert.IsType(t, float64(0), payload["timestamp"])
+		assert.Len(t, payload, 3)
+		return
+	}
+}
+
+func TestWebSocketUpdatesHeartbeat(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	server.hub.pingInterval = 20 * time.Millisecond
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	conn := dialUpdates(t, ts)
+	defer conn.Close()
+
+	pings := make(chan struct{}, 10)
+	conn.SetPingHandler(func(string) error {
+		select {
+		case pings <- struct{}{}:
+		default:
+		}
+		return nil
+	})
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-pings:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no heartbeat ping received")
+	}
+}
+
+func TestWebSocketUpdatesConcurrentClients(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	stop := make(chan struct{})
+	publisherDone := make(chan struct{})
+	go func() {
+		defer close(publisherDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				assert.NoError(t, cacheManager.Set("busy", "x", 0))
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/updates"
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+			var event cache.CacheEvent
+			assert.NoError(t, conn.ReadJSON(&event))
+			assert.NoError(t, conn.Close())
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	<-publisherDone
+
+	// The hub keeps serving after the churn
+	conn := dialUpdates(t, ts)
+	defer conn.Close()
+	waitForClients(t, cacheManager, "probe", conn)
+}
+
+func TestHubStopsWhenEventsClose(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	conn := dialUpdates(t, ts)
+	defer conn.Close()
+	waitForClients(t, cacheManager, "probe", conn)
+
+	// Closing the manager ends the event subscription, which disconnects
+	// every client
+	require.NoError(t, cacheManager.Close())
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err := conn.ReadMessage()
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+			return
+		}
+	}
+}
+
+func TestMatchesTopic(t *testing.T) {
+	tests := []struct {
+		topic    string
+		key      string
+		expected bool
+	}{
+		{topic: "", key: "sdk:sentry-go", expected: true},
+		{topic: "project:foo", key: "project:foo", expected: true},
+		{topic: "project:foo", key: "project:foo:sdk:sentry-go", expected: true},
+		{topic: "project:foo", key: "project:foobar", expected: false},
+		{topic: "project:foo", key: "project:bar", expected: false},
+		{topic: "project:foo", key: "sdk:sentry-go", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.topic+"/"+tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, matchesTopic(tt.topic, tt.key))
+		})
+	}
+}
+
+func TestWebSocketProjectSubscriptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		project   string
+		key       string
+		delivered bool
+	}{
+		{name: "own project", project: "bar", key: "project:bar:summary", delivered: true},
+		{name: "exact key", project: "bar", key: "project:bar", delivered: true},
+		{name: "other project", project: "bar", key: "project:baz:summary", delivered: false},
+		{name: "other project exact key", project: "bar", key: "project:baz", delivered: false},
+		{name: "shared prefix", project: "bar", key: "project:barn", delivered: false},
+		{name: "sdk key", project: "bar", key: "sdk:sentry-go", delivered: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cacheManager := setupTestServer(t)
+			defer func() {
+				err := cacheManager.Close()
+				require.NoError(t, err)
+			}()
+			ts := httptest.NewServer(server.router)
+			defer ts.Close()
+
+			conn := dialWebSocket(t, ts, "/ws/project/"+tt.project)
+			defer conn.Close()
+			probe := "project:" + tt.project + ":probe"
+			waitForClients(t, cacheManager, probe, conn)
+
+			// The sentinel follows the published key, so receiving it first
+			// proves the published key was filtered out
+			sentinel := "project:" + tt.project + ":sentinel"
+			require.NoError(t, cacheManager.Set(tt.key, "{}", 0))
+			require.NoError(t, cacheManager.Set(sentinel, "{}", 0))
+
+			event := readEvent(t, conn)
+			for event.Key == probe {
+				event = readEvent(t, conn)
+			}
+			if tt.delivered {
+				assert.Equal(t, tt.key, event.Key)
+				event = readEvent(t, conn)
+			}
+			assert.Equal(t, sentinel, event.Key)
+		})
+	}
+}
+
+func TestWebSocketProjectIsolation(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	bar := dialWebSocket(t, ts, "/ws/project/bar")
+	defer bar.Close()
+	baz := dialWebSocket(t, ts, "/ws/project/baz")
+	defer baz.Close()
+	all := dialUpdates(t, ts)
+	defer all.Close()
+	waitForClients(t, cacheManager, "project:bar:probe", bar, all)
+	waitForClients(t, cacheManager, "project:baz:probe", baz)
+
+	require.NoError(t, cacheManager.Set("project:baz:summary", "{}", 0))
+	require.NoError(t, cacheManager.Set("project:bar:summary", "{}", 0))
+
+	nextEvent := func(conn *websocket.Conn) cache.CacheEvent {
+		event := readEvent(t, conn)
+		for strings.HasSuffix(event.Key, ":probe") {
+			event = readEvent(t, conn)
+		}
+		return event
+	}
+
+	assert.Equal(t, "project:bar:summary", nextEvent(bar).Key)
+	assert.Equal(t, "project:baz:summary", nextEvent(baz).Key)
+	assert.Equal(t, "project:baz:summary", nextEvent(all).Key)
+	assert.Equal(t, "project:bar:summary", nextEvent(all).Key)
+
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	hub := NewHub(zerolog.Nop())
+	events := make(chan cache.CacheEvent)
+	defer close(events)
+	go hub.Run(events)
+
+	upgrader := websocket.Upgrader{}
+	serverConns := make(chan *websocket.Conn, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if !assert.NoError(t, err) {
+			return
+		}
+		serverConns <- conn
+		hub.Serve(conn, "project:bar")
+	}))
+	defer ts.Close()
+
+	client := dialWebSocket(t, ts, "/")
+	defer client.Close()
+	serverConn := <-serverConns
+
+	// Serve registers asynchronously, so publish until the client sees an event
+	received := make(chan cache.CacheEvent, 1)
+	go func() {
+		var event cache.CacheEvent
+		if err := client.SetReadDeadline(time.Now().Add(5 * time.Second)); err == nil && client.ReadJSON(&event) == nil {
+			received <- event
+		}
+		close(received)
+	}()
+	event := cache.CacheEvent{Type: cache.EventSet, Key: "project:bar:summary"}
+	for delivered := false; !delivered; {
+		select {
+		case got, ok := <-received:
+			require.True(t, ok, "no event received before unsubscribing")
+			assert.Equal(t, event.Key, got.Key)
+			delivered = true
+		case events <- event:
+		}
+	}
+
+	hub.Unsubscribe(serverConn)
+
+	// The client gets a close frame rather than further events
+	events <- event
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		_, _, err := client.ReadMessage()
+		if err != nil {
+			assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "unexpected error: %v", err)
+			return
+		}
+	}
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+	server.config.AllowedOrigins = []string{"https://app.example.com"}
+	server = NewServer(server.config, cacheManager, server.worker, server.logger)
+
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/ws/updates"
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "allowed origin", origin: "https://app.example.com", allowed: true},
+		{name: "no origin header", allowed: true},
+		{name: "disallowed origin", origin: "https://evil.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.origin != "" {
+				header.Set("Origin", tt.origin)
+			}
+			conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+			if !tt.allowed {
+				require.ErrorIs(t, err, websocket.ErrBadHandshake)
+				assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+				return
+			}
+			require.NoError(t, err)
+			assert.NoError(t, conn.Close())
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes for GET /api/v1/cache/keys.
+const (
+	defaultKeysLimit = 50
+	maxKeysLimit     = 200
+)
+
+// handleListCacheKeys lists the keys starting with the prefix query
+// parameter with their sizes, remaining TTLs and hit counts. Results are
+// paged in key order: pass next_cursor back as cursor to fetch the
+// following page. total counts every matching key.
+func (s *Server) handleListCacheKeys(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultKeysLimit)))
+	if err != nil || limit < 1 || limit > maxKeysLimit {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   fmt.Sprintf("limit must be between 1 and %d", maxKeysLimit),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	prefix := c.Query("prefix")
+	entries, next, err := s.cache.ListKeys(prefix, limit, c.Query("cursor"))
+	var total int
+	if err == nil {
+		total, err = s.cache.CountKeys(prefix)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to list cache keys")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to list cache keys",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	now := time.Now()
+	keys := make([]gin.H, len(entries))
+	for i, entry := range entries {
+		// Keys without a TTL never expire
+		var ttlRemaining *int64
+		if entry.TTL > 0 {
+			seconds := int64(entry.TTLRemaining(now).Seconds())
+			ttlRemaining = &seconds
+		}
+		keys[i] = gin.H{
+			"key":           entry.Key,
+			"size":          entry.Size,
+			"ttl_remaining": ttlRemaining,
+			"hit_count":     entry.HitCount,
+			"created_at":    entry.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"keys":        keys,
+			"next_cursor": next,
+			"total":       total,
+		},
+		Message:   "Cache keys retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleDeleteCacheKeys deletes every key starting with the prefix query
+// parameter. The prefix is required so a bare request cannot clear the
+// whole cache.
+func (s *Server) handleDeleteCacheKeys(c *gin.Context) {
+	prefix := c.Query("prefix")
+	if prefix == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "prefix is required",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	count, err := s.cache.DeleteByPrefix(prefix)
+	if err != nil {
+		s.logger.Error().Err(err).Str("prefix", prefix).Msg("Failed to delete cache keys")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to delete cache keys",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"prefix":  prefix,
+			"deleted": count,
+		},
+		Message:   "Cache keys deleted successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// touchRequest is the optional body of POST /api/v1/cache/key/:key/touch.
+type touchRequest struct {
+	TTL string `json:"ttl"`
+}
+
+// handleTouchCacheKey restarts the TTL of a cache key without changing its
+// value. The body may set a new TTL as a Go duration; without one the
+// key keeps its current TTL.
+func (s *Server) handleTouchCacheKey(c *gin.Context) {
+	key := c.Param("key")
+
+	var req touchRequest
+	if c.Request.Body == nil {
+		c.Request.Body = http.NoBody
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"ttl\": \"<duration>\"}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		var err error
+		ttl, err = time.ParseDuration(req.TTL)
+		if err != nil || ttl <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "invalid_request",
+				Message:   "ttl must be a positive duration, such as 24h",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+	}
+
+	if err := s.cache.Touch(key, ttl); err != nil {
+		s.respondCacheError(c, err, "Cache key not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      gin.H{"touched": key},
+		Message:   "Cache key touched successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// keysPage is the data of a GET /api/v1/cache/keys response.
+type keysPage struct {
+	Keys []struct {
+		Key          string    `json:"key"`
+		Size         int64     `json:"size"`
+		TTLRemaining *int64    `json:"ttl_remaining"`
+		HitCount     int64     `json:"hit_count"`
+		CreatedAt    time.Time `json:"created_at"`
+	} `json:"keys"`
+	NextCursor string `json:"next_cursor"`
+	Total      int    `json:"total"`
+}
+
+func listCacheKeys(t *testing.T, server *Server, query string) (int, keysPage) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/api/v1/cache/keys"+query, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	var response struct {
+		Data keysPage `json:"data"`
+	}
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response.Data
+}
+
+func TestListCacheKeysEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "analysis", time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", "analysis", 0))
+	require.NoError(t, cacheManager.Set("project:sentry", "analysis", 0))
+	_, err := cacheManager.Get("sdk:sentry-python")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return cacheManager.HitsByPrefix("sdk:sentry-python")["sdk:sentry-python"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	code, page := listCacheKeys(t, server, "?prefix=sdk:&limit=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, page.Keys, 1)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "sdk:sentry-go", page.Keys[0].Key)
+	assert.Equal(t, int64(8), page.Keys[0].Size)
+	require.NotNil(t, page.Keys[0].TTLRemaining)
+	assert.InDelta(t, 3600, *page.Keys[0].TTLRemaining, 60)
+	assert.WithinDuration(t, time.Now(), page.Keys[0].CreatedAt, time.Minute)
+	assert.Equal(t, "sdk:sentry-go", page.NextCursor)
+
+	code, page = listCacheKeys(t, server, "?prefix=sdk:&limit=1&cursor="+page.NextCursor)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, page.Keys, 1)
+	assert.Equal(t, "sdk:sentry-python", page.Keys[0].Key)
+	assert.Nil(t, page.Keys[0].TTLRemaining)
+	assert.Equal(t, int64(1), page.Keys[0].HitCount)
+	assert.Empty(t, page.NextCursor)
+
+	code, page = listCacheKeys(t, server, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, page.Keys, 3)
+	assert.Equal(t, 3, page.Total)
+
+	for _, query := range []string{"?limit=0", "?limit=abc", "?limit=201"} {
+		code, _ = listCacheKeys(t, server, query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestListCacheKeysPagination(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	const count = 500
+	entries := make([]cache.CacheEntry, count)
+	for i := range entries {
+		entries[i] = cache.CacheEntry{Key: fmt.Sprintf("sdk:bench-%03d", i), Value: "v"}
+	}
+	require.NoError(t, cacheManager.SetMulti(entries))
+	require.NoError(t, cacheManager.Set("project:sentry", "v", 0))
+
+	var seen []string
+	cursor, pages := "", 0
+	for {
+		code, page := listCacheKeys(t, server, "?prefix=sdk:&limit=200&cursor="+cursor)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, count, page.Total)
+		pages++
+
+		for _, key := range page.Keys {
+			seen = append(seen, key.Key)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, 3, pages)
+	require.Len(t, seen, count)
+	for i, key := range seen {
+		assert.Equal(t, fmt.Sprintf("sdk:bench-%03d", i), key)
+	}
+}
+
+func TestDeleteCacheKeysEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "analysis", 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", "analysis", 0))
+	require.NoError(t, cacheManager.Set("project:sentry", "analysis", 0))
+
+	del := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("DELETE", "/api/v1/cache/keys"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, del("").Code)
+
+	w := del("?prefix=sdk:")
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Deleted int `json:"deleted"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Data.Deleted)
+
+	_, err := cacheManager.Get("sdk:sentry-go")
+	assert.Error(t, err)
+	_, err = cacheManager.Get("project:sentry")
+	assert.NoError(t, err)
+}
+
+func TestTouchCacheKeyEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "analysis", time.Hour))
+
+	touch := func(key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/cache/key/"+key+"/touch", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+	ttl := func() time.Duration {
+		entries, _, err := cacheManager.ListKeys("sdk:sentry-go", 1, "")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		return entries[0].TTL
+	}
+
+	// Without a body the TTL is kept
+	w := touch("sdk:sentry-go", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, time.Hour, ttl())
+
+	w = touch("sdk:sentry-go", `{"ttl": "24h"}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 24*time.Hour, ttl())
+
+	value, err := cacheManager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", value)
+	assert.Equal(t, int64(2), cacheManager.GetStats().Touches)
+
+	assert.Equal(t, http.StatusBadRequest, touch("sdk:sentry-go", `{"ttl": "tomorrow"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, touch("sdk:sentry-go", `{"ttl": "-1h"}`).Code)
+	assert.Equal(t, http.StatusNotFound, touch("sdk:missing", "").Code)
+
+	req, _ := http.NewRequest("POST", "/api/v1/cache/key/sdk:sentry-go/touch", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
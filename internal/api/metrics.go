@@ -0,0 +1,144 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// cacheMetrics exports cache operations to Prometheus. It is attached to
+// the cache manager as its MetricsCollector.
+type cacheMetrics struct {
+	hits      prometheus.Counter
+	misses    prometheus.Counter
+	sets      prometheus.Counter
+	deletes   prometheus.Counter
+	latency   *prometheus.HistogramVec
+	items     prometheus.Gauge
+	totalSize prometheus.Gauge
+}
+
+func newCacheMetrics() *cacheMetrics {
+	return &cacheMetrics{
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "claude_cache_hits_total",
+			Help: "Number of cache lookups that found a value.",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "claude_cache_misses_total",
+			Help: "Number of cache lookups that found no value.",
+		}),
+		sets: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "claude_cache_sets_total",
+			Help: "Number of values written to the cache.",
+		}),
+		deletes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "claude_cache_deletes_total",
+			Help: "Number of cache deletes.",
+		}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "claude_cache_operation_duration_seconds",
+			Help:    "Duration of cache gets and sets.",
+			Buckets: prometheus.ExponentialBuckets(0.00001, 4, 10), // 10µs to ~2.6s
+		}, []string{"operation"}),
+		items: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "claude_cache_items",
+			Help: "Number of entries in the cache.",
+		}),
+		totalSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "claude_cache_size_bytes",
+			Help: "Total size of the cached values in bytes.",
+		}),
+	}
+}
+
+// register adds every metric to reg.
+func (m *cacheMetrics) register(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{m.hits, m.misses, m.sets, m.deletes, m.latency, m.items, m.totalSize} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *cacheMetrics) ObserveHit()    { m.hits.Inc() }
+func (m *cacheMetrics) ObserveMiss()   { m.misses.Inc() }
+func (m *cacheMetrics) ObserveSet()    { m.sets.Inc() }
+func (m *cacheMetrics) ObserveDelete() { m.deletes.Inc() }
+
+func (m *cacheMetrics) ObserveGetLatency(d time.Duration) {
+	m.latency.WithLabelValues("get").Observe(d.Seconds())
+}
+
+func (m *cacheMetrics) ObserveSetLatency(d time.Duration) {
+	m.latency.WithLabelValues("set").Observe(d.Seconds())
+}
+
+func (m *cacheMetrics) ObserveSize(itemCount, totalSize int64) {
+	m.items.Set(float64(itemCount))
+	m.totalSize.Set(float64(totalSize))
+}
+
+func (s *Server) handleWorkerMetrics(c *gin.Context) {
+	metrics := s.worker.Metrics()
+
+	recent := metrics.RecentRuns()
+	runs := make([]gin.H, 0, len(recent))
+	for _, run := range recent {
+		runs = append(runs, gin.H{
+			"started_at":          run.StartedAt,
+			"duration_seconds":    run.Duration.Seconds(),
+			"succeeded":           run.Succeeded,
+			"failed":              run.Failed,
+			"skipped":             run.Skipped,
+			"tokens_used":         run.TokensUsed,
+			"incremental_updates": run.IncrementalUpdates,
+			"error":               run.Error,
+		})
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"success_rate_ema":         metrics.SuccessRateEMA,
+			"avg_duration_ema_seconds": metrics.AvgDurationEMA,
+			"tokens_per_run_ema":       metrics.TokensPerRunEMA,
+			"runs":                     metrics.Runs,
+			"incremental_updates":      metrics.IncrementalUpdates,
+			"repos_cleaned":            metrics.RepoCleaned,
+			"last_runs":                runs,
+		},
+		Message:   "Worker metrics retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleWorkerStatus reports whether a cache update is running, how the
+// last one went and when the next is scheduled. Times the worker does not
+// know yet are null.
+func (s *Server) handleWorkerStatus(c *gin.Context) {
+	status := s.worker.Status()
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"running":                   status.Running,
+			"last_run_at":               optionalTime(status.LastRunAt),
+			"last_run_duration_seconds": status.LastRunDuration.Seconds(),
+			"last_run_success_count":    status.LastRunSuccessCount,
+			"last_run_error_count":      status.LastRunErrorCount,
+			"next_run_at":               optionalTime(status.NextRunAt),
+			"schedule":                  s.worker.UpdateSchedule(),
+		},
+		Message:   "Worker status retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handlePrometheusMetrics(c *gin.Context) {
+	promhttp.HandlerFor(s.metrics, promhttp.HandlerOpts{}).ServeHTTP(c.Writer, c.Request)
+}
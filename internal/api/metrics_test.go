@@ -0,0 +1,132 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerMetricsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/worker/metrics", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response.Data["runs"])
+	assert.Equal(t, []interface{}{}, response.Data["last_runs"])
+	assert.Contains(t, response.Data, "success_rate_ema")
+	assert.Contains(t, response.Data, "avg_duration_ema_seconds")
+	assert.Contains(t, response.Data, "tokens_per_run_ema")
+}
+
+func TestWorkerStatusEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/worker/status", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, false, response.Data["running"])
+	assert.Nil(t, response.Data["last_run_at"], "the worker has not run yet")
+	assert.Equal(t, float64(0), response.Data["last_run_success_count"])
+	assert.Equal(t, server.config.UpdateSchedule, response.Data["schedule"])
+	assert.NotNil(t, response.Data["next_run_at"])
+}
+
+func TestPrometheusMetricsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "claude_cache_worker_success_rate_ema")
+	assert.Contains(t, w.Body.String(), "claude_cache_worker_runs_total")
+}
+
+func TestPrometheusCacheMetrics(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "12345", 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", "123", 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-ruby", "1", 0))
+	for i := 0; i < 4; i++ {
+		_, err := cacheManager.Get("sdk:sentry-go")
+		require.NoError(t, err)
+	}
+	_, err := cacheManager.Get("sdk:missing")
+	require.Error(t, err)
+	require.NoError(t, cacheManager.Delete("sdk:sentry-ruby"))
+
+	req, _ := http.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(w.Body)
+	require.NoError(t, err)
+
+	value := func(name string) float64 {
+		family, ok := families[name]
+		require.True(t, ok, "missing metric %s", name)
+		metric := family.GetMetric()[0]
+		switch {
+		case metric.GetCounter() != nil:
+			return metric.GetCounter().GetValue()
+		case metric.GetGauge() != nil:
+			return metric.GetGauge().GetValue()
+		}
+		t.Fatalf("unexpected type for %s", name)
+		return 0
+	}
+
+	assert.Equal(t, float64(4), value("claude_cache_hits_total"))
+	assert.Equal(t, float64(1), value("claude_cache_misses_total"))
+	assert.Equal(t, float64(3), value("claude_cache_sets_total"))
+	assert.Equal(t, float64(1), value("claude_cache_deletes_total"))
+	assert.Equal(t, float64(2), value("claude_cache_items"))
+	assert.Equal(t, float64(8), value("claude_cache_size_bytes"))
+
+	counts := make(map[string]uint64)
+	for _, metric := range families["claude_cache_operation_duration_seconds"].GetMetric() {
+		counts[metric.GetLabel()[0].GetValue()] = metric.GetHistogram().GetSampleCount()
+	}
+	assert.Equal(t, map[string]uint64{"get": 5, "set": 3}, counts)
+}
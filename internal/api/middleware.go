@@ -1,10 +1,25 @@
 package api
 
 import (
+	"compress/gzip"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // requestIDMiddleware adds a unique request ID to each request.
@@ -22,6 +37,42 @@ func (s *Server) requestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
+// tracerName names the tracer of request spans.
+const tracerName = "github.com/ryanrussell/claude-cache-service/internal/api"
+
+// tracingMiddleware records each request as a server span, continuing the
+// caller's trace from its traceparent header. Handlers pass
+// c.Request.Context() on so that cache operations become child spans.
+func (s *Server) tracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		ctx, span := otel.Tracer(tracerName).Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+				attribute.String("url.path", c.Request.URL.Path),
+				attribute.String("request_id", c.GetString("request_id")),
+			),
+		)
+		defer span.End()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
 // loggingMiddleware logs all requests.
 func (s *Server) loggingMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -88,11 +139,60 @@ func (s *Server) recoveryMiddleware() gin.HandlerFunc {
 	}
 }
 
-// corsMiddleware handles CORS headers.
+// originPolicy is the cross-origin allowlist configured in AllowedOrigins.
+type originPolicy struct {
+	allowed     map[string]bool
+	anyOrigin   bool
+	credentials bool
+}
+
+func newOriginPolicy(origins []string, credentials bool) originPolicy {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+	return originPolicy{allowed: allowed, anyOrigin: allowed["*"], credentials: credentials}
+}
+
+// wildcard reports whether every origin is allowed, which a "*" entry does
+// only without credentials.
+func (p originPolicy) wildcard() bool {
+	return p.anyOrigin && !p.credentials
+}
+
+// listed reports whether origin is named in the allowlist.
+func (p originPolicy) listed(origin string) bool {
+	return p.allowed[strings.ToLower(origin)]
+}
+
+// checkWebSocketOrigin lets a WebSocket be opened from the origins
+// corsMiddleware shares responses with, and by clients sending no Origin.
+func (s *Server) checkWebSocketOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || s.origins.wildcard() || s.origins.listed(origin)
+}
+
+// corsMiddleware handles CORS headers. Only origins in AllowedOrigins are
+// named in Access-Control-Allow-Origin; other origins get no CORS headers,
+// so browsers refuse to share responses with them. A "*" entry allows
+// every origin without credentials.
 func (s *Server) corsMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
+		// Responses differ by origin, so caches must not share them
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		switch {
+		case origin == "":
+		case s.origins.wildcard():
+			c.Header("Access-Control-Allow-Origin", "*")
+		case s.origins.listed(origin):
+			c.Header("Access-Control-Allow-Origin", origin)
+			if s.config.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
 		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
 		c.Header("Access-Control-Allow-Methods", "POST, HEAD, PATCH, OPTIONS, GET, PUT, DELETE")
 
@@ -105,50 +205,276 @@ func (s *Server) corsMiddleware() gin.HandlerFunc {
 	}
 }
 
-// TODO: Implement these middleware functions when needed
-// // rateLimitMiddleware implements rate limiting.
-// func (s *Server) rateLimitMiddleware(limit int, window time.Duration) gin.HandlerFunc {
-// 	// TODO: Implement rate limiting
-// 	return func(c *gin.Context) {
-// 		c.Next()
-// 	}
-// }
-
-// // authMiddleware implements authentication.
-// func (s *Server) authMiddleware() gin.HandlerFunc {
-// 	return func(c *gin.Context) {
-// 		// TODO: Implement authentication for write operations
-// 		token := c.GetHeader("Authorization")
-// 		if token == "" {
-// 			c.JSON(401, ErrorResponse{
-// 				Error:     "unauthorized",
-// 				Message:   "Authentication required",
-// 				RequestID: c.GetString("request_id"),
-// 				Timestamp: time.Now().Unix(),
-// 			})
-// 			c.Abort()
-// 			return
-// 		}
-
-// 		// Validate token
-// 		if !s.validateToken(token) {
-// 			c.JSON(401, ErrorResponse{
-// 				Error:     "invalid_token",
-// 				Message:   "Invalid authentication token",
-// 				RequestID: c.GetString("request_id"),
-// 				Timestamp: time.Now().Unix(),
-// 			})
-// 			c.Abort()
-// 			return
-// 		}
-
-// 		c.Next()
-// 	}
-// }
-
-// // validateToken validates an authentication token.
-// func (s *Server) validateToken(token string) bool {
-// 	// TODO: Implement proper token validation
-// 	expectedToken := fmt.Sprintf("Bearer %s", s.config.ClaudeAPIKey)
-// 	return token == expectedToken
-// }
+// bodyLimitMiddleware rejects request bodies larger than limit with 413.
+// Bodies without a Content-Length are capped while they are read; handlers
+// detect that with isBodyTooLarge.
+func (s *Server) bodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			s.abortBodyTooLarge(c, limit)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+func (s *Server) abortBodyTooLarge(c *gin.Context, limit int64) {
+	c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{
+		Error:     "request_too_large",
+		Message:   fmt.Sprintf("Request body exceeds %d bytes", limit),
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+	c.Abort()
+}
+
+// isBodyTooLarge reports whether err came from reading past the body limit.
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+// compressionMiddleware gzips responses of at least MinCompressSize bytes
+// for clients that send Accept-Encoding: gzip. Smaller responses, responses
+// the handler already encoded and WebSocket upgrades are sent as they are.
+func (s *Server) compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Upgrade") != "" {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &gzipWriter{ResponseWriter: c.Writer, minSize: s.config.MinCompressSize}
+		c.Writer = writer
+		defer func() {
+			if err := writer.finish(); err != nil {
+				s.logger.Debug().Err(err).Msg("Failed to write compressed response")
+			}
+			if writer.compressed {
+				s.compressedResponses.Add(1)
+				s.bytesSaved.Add(writer.rawBytes - writer.compressedBytes)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if !strings.EqualFold(strings.TrimSpace(coding), "gzip") {
+			continue
+		}
+		q, ok := strings.CutPrefix(strings.ReplaceAll(params, " ", ""), "q=")
+		if !ok {
+			return true
+		}
+		weight, err := strconv.ParseFloat(q, 64)
+		return err == nil && weight > 0
+	}
+	return false
+}
+
+// gzipWriter holds back the start of a response until it is known to reach
+// minSize bytes, then gzips it. Shorter responses are written unchanged
+// when the handler returns.
+type gzipWriter struct {
+	gin.ResponseWriter
+
+	minSize int
+	pending []byte
+	gz      *gzip.Writer
+	done    bool // the response is being compressed or passed through
+
+	compressed      bool
+	rawBytes        int64
+	compressedBytes int64
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.done {
+		if w.gz == nil {
+			return w.ResponseWriter.Write(data)
+		}
+		w.rawBytes += int64(len(data))
+		return w.gz.Write(data)
+	}
+
+	w.pending = append(w.pending, data...)
+	if len(w.pending) >= w.minSize {
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *gzipWriter) Written() bool {
+	return len(w.pending) > 0 || w.ResponseWriter.Written()
+}
+
+// Flush sends what has been written so far, compressing a streamed
+// response even before it reaches minSize.
+func (w *gzipWriter) Flush() {
+	if !w.done {
+		if err := w.start(true); err != nil {
+			return
+		}
+	}
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return
+		}
+	}
+	w.ResponseWriter.Flush()
+}
+
+// start writes the pending bytes, compressed if compress is set and the
+// response can be.
+func (w *gzipWriter) start(compress bool) error {
+	w.done = true
+	pending := w.pending
+	w.pending = nil
+
+	header := w.ResponseWriter.Header()
+	status := w.ResponseWriter.Status()
+	if compress && header.Get("Content-Encoding") == "" && bodyAllowed(status) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.gz = gzip.NewWriter(countingWriter{w: w.ResponseWriter, n: &w.compressedBytes})
+		w.compressed = true
+		w.rawBytes += int64(len(pending))
+		_, err := w.gz.Write(pending)
+		return err
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(pending)
+	return err
+}
+
+// finish completes the response once the handler has returned.
+func (w *gzipWriter) finish() error {
+	if !w.done {
+		return w.start(false)
+	}
+	if w.gz == nil {
+		return nil
+	}
+	return w.gz.Close()
+}
+
+// bodyAllowed reports whether a response with status may have a body.
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// countingWriter counts the bytes written through it into n.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c countingWriter) Write(data []byte) (int, error) {
+	n, err := c.w.Write(data)
+	*c.n += int64(n)
+	return n, err
+}
+
+// authMiddleware restricts a route that writes or deletes cache entries to
+// callers presenting one of the configured API keys, or an admin API key,
+// as a bearer token.
+func (s *Server) authMiddleware() gin.HandlerFunc {
+	return s.bearerMiddleware("write", s.config.APIKeys, s.config.AdminAPIKeys)
+}
+
+// adminMiddleware restricts a route to callers presenting one of the
+// configured admin API keys as a bearer token.
+func (s *Server) adminMiddleware() gin.HandlerFunc {
+	return s.bearerMiddleware("admin", s.config.AdminAPIKeys)
+}
+
+// bearerMiddleware rejects requests without an "Authorization: Bearer"
+// header, or whose bearer token is not in any of keySets, with 401. scope
+// names the kind of route in the rejection log.
+func (s *Server) bearerMiddleware(scope string, keySets ...[]string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:     "unauthorized",
+				Message:   "Authentication required",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			c.Abort()
+			return
+		}
+
+		if !validateToken(token, keySets...) {
+			s.logger.Warn().
+				Str("request_id", c.GetString("request_id")).
+				Str("client_ip", c.ClientIP()).
+				Str("path", c.Request.URL.Path).
+				Str("scope", scope).
+				Msg("Rejected request with invalid token")
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Error:     "invalid_token",
+				Message:   "Invalid authentication token",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set("actor", s.actorFor(token))
+		c.Next()
+	}
+}
+
+// actorFor names the caller presenting token in the audit log by the kind
+// of key and a fingerprint of it, never the key itself.
+func (s *Server) actorFor(token string) string {
+	kind := "api"
+	if validateToken(token, s.config.AdminAPIKeys) {
+		kind = "admin"
+	}
+	sum := sha256.Sum256([]byte(token))
+	return kind + ":" + hex.EncodeToString(sum[:4])
+}
+
+// validateToken checks a token against every key in keySets in constant
+// time.
+func validateToken(token string, keySets ...[]string) bool {
+	valid := false
+	for _, keys := range keySets {
+		for _, key := range keys {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+				valid = true
+			}
+		}
+	}
+	return valid
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ryanrussell/claude-cache-service/internal/telemetry/telemetrytest"
+)
+
+func TestCompressionMiddleware(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	server.config.MinCompressSize = 1024
+	server = NewServer(server.config, cacheManager, server.worker, server.logger)
+
+	features := make([]string, 200)
+	for i := range features {
+		features[i] = `"feature"`
+	}
+	analysis := `{"language":"go","features":[` + strings.Join(features, ",") + `]}`
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", analysis, time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-tiny", `{"language":"go"}`, time.Hour))
+
+	get := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	plain := get("/api/v1/cache/sdk/sentry-go/raw", "")
+	require.Equal(t, http.StatusOK, plain.Code)
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+	assert.Contains(t, plain.Header().Values("Vary"), "Accept-Encoding")
+
+	compressed := get("/api/v1/cache/sdk/sentry-go/raw", "br, gzip")
+	require.Equal(t, http.StatusOK, compressed.Code)
+	assert.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+	assert.Contains(t, compressed.Header().Values("Vary"), "Accept-Encoding")
+	assert.Equal(t, "application/json", compressed.Header().Get("Content-Type"))
+	assert.Less(t, compressed.Body.Len(), plain.Body.Len())
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed.Body.Bytes()))
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, plain.Body.String(), string(body))
+
+	// The wrapped form decompresses to the same analysis
+	wrapped := get("/api/v1/cache/sdk/sentry-go", "gzip")
+	require.Equal(t, "gzip", wrapped.Header().Get("Content-Encoding"))
+	reader, err = gzip.NewReader(bytes.NewReader(wrapped.Body.Bytes()))
+	require.NoError(t, err)
+	var response SuccessResponse
+	require.NoError(t, json.NewDecoder(reader).Decode(&response))
+	assert.Equal(t, analysis, response.Data)
+
+	// Small responses, refused encodings and bodyless responses are not compressed
+	for _, w := range []*httptest.ResponseRecorder{
+		get("/api/v1/cache/sdk/sentry-tiny/raw", "gzip"),
+		get("/api/v1/cache/sdk/sentry-go/raw", "gzip;q=0"),
+	} {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.True(t, json.Valid(w.Body.Bytes()))
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-go/raw", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("If-None-Match", plain.Header().Get("ETag"))
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Zero(t, w.Body.Len())
+
+	stats := server.CompressionStats()
+	assert.Equal(t, int64(2), stats.CompressedResponses)
+	assert.Positive(t, stats.BytesSaved)
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip", true},
+		{"GZIP;q=0.5", true},
+		{"gzip; q=0", false},
+		{"gzip;q=0.0", false},
+		{"br", false},
+		{"x-gzip", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, acceptsGzip(tt.header), "header %q", tt.header)
+	}
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	recorder := telemetrytest.NewRecorder(t)
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", `{"sdk_name":"sentry-go"}`, time.Hour))
+
+	// The caller's trace is continued
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-go", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	requests := recorder.Named("GET /api/v1/cache/sdk/:name")
+	require.Len(t, requests, 1)
+	span := requests[0]
+	assert.Equal(t, traceID, span.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind)
+	attrs := telemetrytest.Attributes(span)
+	assert.Equal(t, "/api/v1/cache/sdk/:name", attrs["http.route"])
+	assert.Equal(t, int64(http.StatusOK), attrs["http.response.status_code"])
+	assert.Equal(t, w.Header().Get("X-Request-ID"), attrs["request_id"])
+
+	// Cache reads are children of the request
+	gets := recorder.Named("cache.Get")
+	require.NotEmpty(t, gets)
+	assert.True(t, telemetrytest.ChildOf(gets[0], span.SpanContext))
+	assert.Equal(t, "sdk:sentry-go", telemetrytest.Attributes(gets[0])["cache.key"])
+
+	// Requests without traceparent start a trace
+	recorder.Reset()
+	req, _ = http.NewRequest("GET", "/api/v1/cache/sdk/sentry-go", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	requests = recorder.Named("GET /api/v1/cache/sdk/:name")
+	require.Len(t, requests, 1)
+	assert.False(t, requests[0].Parent.IsValid())
+	assert.NotEqual(t, traceID, requests[0].SpanContext.TraceID().String())
+	assert.Equal(t, codes.Unset, requests[0].Status.Code)
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+)
+
+// handleTestNotification sends a test event through the configured webhook
+// and email notifiers.
+func (s *Server) handleTestNotification(c *gin.Context) {
+	notifier := s.worker.Notifier()
+	if notifier == nil {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     "notifications_disabled",
+			Message:   "No notifier is configured; set WEBHOOK_URL or SMTP_HOST",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	event := notify.TestEvent()
+	if err := notifier.Notify(c.Request.Context(), event); err != nil {
+		s.logger.Error().Err(err).Msg("Failed to send test notification")
+		c.JSON(http.StatusBadGateway, ErrorResponse{
+			Error:     "notification_failed",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      event,
+		Message:   "Test notification sent",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+)
+
+func TestTestNotificationEndpoint(t *testing.T) {
+	var payloads []notify.AnalysisEvent
+	status := http.StatusOK
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.AnalysisEvent
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		payloads = append(payloads, event)
+		w.WriteHeader(status)
+	}))
+	defer webhook.Close()
+
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	request := func(token string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/notify/test", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Nothing to send to yet
+	w := request(testAdminKey)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), "notifications_disabled")
+
+	server.worker.SetNotifier(notify.NewWebhookNotifier(webhook.URL, zerolog.Nop()))
+
+	assert.Equal(t, http.StatusUnauthorized, request(testAPIKeys[0]).Code)
+	assert.Empty(t, payloads)
+
+	w = request(testAdminKey)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Data notify.AnalysisEvent `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, notify.EventTest, response.Data.Type)
+
+	require.Len(t, payloads, 1)
+	assert.Equal(t, notify.EventTest, payloads[0].Type)
+	assert.True(t, payloads[0].AnalyzedAt.Equal(response.Data.AnalyzedAt))
+
+	// Delivery failures are reported
+	status = http.StatusInternalServerError
+	w = request(testAdminKey)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
+	assert.Contains(t, w.Body.String(), "webhook returned status 500")
+}
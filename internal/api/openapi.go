@@ -0,0 +1,1065 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+)
+
+//go:embed static/swagger-ui.html
+var swaggerUIPage []byte
+
+const bearerAuthScheme = "bearerAuth"
+
+// componentSchemas are the shared schemas referenced from operations.
+var componentSchemas = openapi3.Schemas{
+	"SuccessResponse":         openapi3.NewSchemaRef("", successResponseSchema()),
+	"ErrorResponse":           openapi3.NewSchemaRef("", errorResponseSchema()),
+	"HealthResponse":          openapi3.NewSchemaRef("", healthResponseSchema()),
+	"DeepHealthResponse":      openapi3.NewSchemaRef("", deepHealthResponseSchema()),
+	"ClaudeHealthResponse":    openapi3.NewSchemaRef("", claudeHealthResponseSchema()),
+	"TelemetryHealthResponse": openapi3.NewSchemaRef("", telemetryHealthResponseSchema()),
+	"RefreshRequest":          openapi3.NewSchemaRef("", refreshRequestSchema()),
+}
+
+// GenerateOpenAPISpec builds the OpenAPI 3.0 description of every route
+// served by the API.
+func GenerateOpenAPISpec(version string) *openapi3.T {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "Claude Cache Service API",
+			Description: "Caches Claude analyses of SDK and project repositories.",
+			Version:     version,
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: componentSchemas,
+			SecuritySchemes: openapi3.SecuritySchemes{
+				bearerAuthScheme: &openapi3.SecuritySchemeRef{
+					Value: openapi3.NewSecurityScheme().
+						WithType("http").
+						WithScheme("bearer").
+						WithDescription("API key sent as a bearer token"),
+				},
+			},
+		},
+	}
+
+	// Health
+	doc.AddOperation("/health", http.MethodGet, newOperation("getHealth", "System", "Service health check").
+		withResponse(http.StatusOK, "Service is healthy", schemaRef("HealthResponse")).
+		build())
+
+	doc.AddOperation("/api/v1/health/deep", http.MethodGet, newOperation("getDeepHealth", "System", "Check the cache database, git work directory, Claude API key and disk space").
+		withResponse(http.StatusOK, "Every check passed, or some are degraded", schemaRef("DeepHealthResponse")).
+		withResponse(http.StatusServiceUnavailable, "A check failed", schemaRef("DeepHealthResponse")).
+		build())
+
+	doc.AddOperation("/api/v1/health/claude", http.MethodGet, newOperation("getClaudeHealth", "System", "State of the circuit breaker guarding Claude API calls").
+		withResponse(http.StatusOK, "The circuit is closed or half-open, or Claude is not used", schemaRef("ClaudeHealthResponse")).
+		withResponse(http.StatusServiceUnavailable, "The circuit is open and analyses fail fast", schemaRef("ClaudeHealthResponse")).
+		build())
+
+	doc.AddOperation("/api/v1/health/telemetry", http.MethodGet, newOperation("getTelemetryHealth", "System", "Whether traces are exported to the OTLP collector").
+		withResponse(http.StatusOK, "Tracing is disabled, or the last export succeeded or has not happened yet", schemaRef("TelemetryHealthResponse")).
+		withResponse(http.StatusServiceUnavailable, "The last export failed", schemaRef("TelemetryHealthResponse")).
+		build())
+
+	doc.AddOperation("/metrics", http.MethodGet, newOperation("getPrometheusMetrics", "System", "Cache and worker metrics in the Prometheus text format").
+		withRawResponse(http.StatusOK, "Prometheus metrics", "text/plain").
+		build())
+
+	// Cache operations
+	doc.AddOperation("/api/v1/cache/summary", http.MethodGet, newOperation("getCacheSummary", "Cache", "Cache statistics and configuration").
+		withSuccess(http.StatusOK, "Cache summary", openapi3.NewObjectSchema().
+			WithProperty("statistics", cacheStatisticsSchema()).
+			WithProperty("configuration", openapi3.NewObjectSchema().
+				WithProperty("cache_dir", openapi3.NewStringSchema()).
+				WithProperty("max_size", openapi3.NewInt64Schema()).
+				WithProperty("ttl", openapi3.NewStringSchema())).
+			WithProperty("repositories", openapi3.NewObjectSchema().
+				WithAdditionalProperties(openapi3.NewInt64Schema()))).
+		build())
+
+	doc.AddOperation("/api/v1/cache/stats/histogram", http.MethodGet, newOperation("getHitCountHistogram", "Cache", "Number of cache entries by hit count").
+		withQueryParam("buckets", "Comma-separated ascending lower bounds of the buckets", openapi3.NewStringSchema().WithDefault("0,1,10,100,1000")).
+		withSuccess(http.StatusOK, "Hit count histogram", openapi3.NewObjectSchema().
+			WithProperty("histogram", openapi3.NewObjectSchema().
+				WithAdditionalProperties(openapi3.NewInt64Schema())).
+			WithProperty("ranges", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithProperty("total", openapi3.NewInt64Schema())).
+		withError(http.StatusBadRequest, "Invalid buckets").
+		build())
+
+	doc.AddOperation("/api/v1/cache/keys", http.MethodGet, newOperation("listCacheKeys", "Cache", "List cache keys by prefix").
+		withQueryParam("prefix", "Only list keys starting with this prefix, such as sdk:", openapi3.NewStringSchema()).
+		withQueryParam("limit", "Maximum keys per page", openapi3.NewIntegerSchema().WithMin(1).WithMax(maxKeysLimit).WithDefault(defaultKeysLimit)).
+		withQueryParam("cursor", "next_cursor from the previous page", openapi3.NewStringSchema()).
+		withSuccess(http.StatusOK, "Page of cache keys", openapi3.NewObjectSchema().
+			WithProperty("keys", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+				WithProperty("key", openapi3.NewStringSchema()).
+				WithProperty("size", openapi3.NewInt64Schema()).
+				WithProperty("ttl_remaining", openapi3.NewInt64Schema().WithNullable()).
+				WithProperty("hit_count", openapi3.NewInt64Schema()).
+				WithProperty("created_at", openapi3.NewDateTimeSchema()))).
+			WithProperty("next_cursor", openapi3.NewStringSchema()).
+			WithProperty("total", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid limit").
+		build())
+
+	doc.AddOperation("/api/v1/cache/keys", http.MethodDelete, newOperation("deleteCacheKeys", "Cache", "Delete every cache key with a prefix").
+		withQueryParam("prefix", "Delete keys starting with this prefix, such as sdk:", openapi3.NewStringSchema().WithMinLength(1)).
+		withSuccess(http.StatusOK, "Cache keys deleted", openapi3.NewObjectSchema().
+			WithProperty("prefix", openapi3.NewStringSchema()).
+			WithProperty("deleted", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Missing prefix").
+		withError(http.StatusInternalServerError, "Failed to delete cache keys").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/project/{name}", http.MethodGet, newOperation("getProjectCache", "Cache", "Cached analysis for a project").
+		withPathParam("name", "Project name").
+		withSuccess(http.StatusOK, "Project cache entry", openapi3.NewStringSchema()).
+		withError(http.StatusNotFound, "Project cache not found").
+		withError(http.StatusInternalServerError, "Cache read failed").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}", http.MethodGet, newOperation("getSDKCache", "Cache", "Cached analysis for an SDK").
+		withPathParam("name", "SDK name").
+		withQueryParam("compare_to", "Analysis version to compare the cached analysis with", openapi3.NewStringSchema()).
+		withSuccess(http.StatusOK, "SDK analysis JSON, including its quality_score; with compare_to, both analyses and the diff from the version", openapi3.NewOneOfSchema(
+			openapi3.NewStringSchema(),
+			openapi3.NewObjectSchema().
+				WithProperty("sdk", openapi3.NewStringSchema()).
+				WithProperty("comparison", openapi3.NewObjectSchema().
+					WithProperty("compare_to", openapi3.NewStringSchema()).
+					WithProperty("current", openapi3.NewObjectSchema()).
+					WithProperty("compared", openapi3.NewObjectSchema()).
+					WithProperty("diff", diffSchema())),
+		)).
+		withError(http.StatusNotFound, "SDK cache or the compared version not found").
+		withError(http.StatusInternalServerError, "Cache read failed").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}", http.MethodPatch, newOperation("patchSDKCache", "Cache", "Update some fields of an SDK's cached analysis without analyzing it again").
+		withPathParam("name", "SDK name").
+		withJSONBody(openapi3.NewObjectSchema().
+			WithProperty("protocol_version", openapi3.NewStringSchema()).
+			WithProperty("features", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithAnyAdditionalProperties()).
+		withSuccess(http.StatusOK, "Patched SDK analysis, with a new analysis_version", openapi3.NewObjectSchema()).
+		withError(http.StatusBadRequest, "Unknown, read-only or invalid analysis fields").
+		withError(http.StatusNotFound, "SDK cache not found").
+		withError(http.StatusConflict, "SDK analysis changed while it was patched").
+		withError(http.StatusInternalServerError, "Failed to patch SDK analysis").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/raw", http.MethodGet, newOperation("getSDKCacheRaw", "Cache", "Cached analysis for an SDK as unwrapped JSON").
+		withPathParam("name", "SDK name").
+		withHeaderParam("If-None-Match", "ETag of a previously fetched analysis").
+		withHeaderParam("If-Modified-Since", "Last-Modified of a previously fetched analysis").
+		withRawResponse(http.StatusOK, "Cached analysis JSON", "application/json").
+		withEmptyResponse(http.StatusNotModified, "Analysis unchanged").
+		withError(http.StatusNotFound, "SDK cache not found").
+		withError(http.StatusInternalServerError, "Cache read failed or cached analysis is not valid JSON").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/analyze", http.MethodPost, newOperation("analyzeSDK", "Cache", "Analyze one SDK now and cache the result").
+		withPathParam("name", "SDK name").
+		withQueryParam("force", "Re-analyze the SDK even if it has not changed", openapi3.NewBoolSchema()).
+		withQueryParam("async", "Return a job ID instead of waiting for the analysis", openapi3.NewBoolSchema()).
+		withSuccess(http.StatusOK, "SDK analysis", openapi3.NewObjectSchema()).
+		withSuccess(http.StatusAccepted, "Analysis started in the background", openapi3.NewObjectSchema().
+			WithProperty("job_id", openapi3.NewStringSchema())).
+		withError(http.StatusBadRequest, "Invalid request").
+		withError(http.StatusNotFound, "Unknown SDK").
+		withError(http.StatusTooManyRequests, "Claude API rate limit reached; see Retry-After").
+		withError(http.StatusInternalServerError, "Analysis could not be cached").
+		withError(http.StatusBadGateway, "Analysis failed").
+		withError(http.StatusServiceUnavailable, "Worker is shutting down").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/versions", http.MethodGet, newOperation("listSDKVersions", "Cache", "Cached analysis versions of an SDK, newest first").
+		withPathParam("name", "SDK name").
+		withQueryParam("limit", "Maximum versions per page", openapi3.NewIntegerSchema().WithMin(1).WithMax(maxVersionsLimit).WithDefault(defaultVersionsLimit)).
+		withQueryParam("offset", "Versions to skip", openapi3.NewIntegerSchema().WithMin(0).WithDefault(0)).
+		withSuccess(http.StatusOK, "Page of SDK versions", openapi3.NewObjectSchema().
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("versions", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+				WithProperty("version", openapi3.NewStringSchema()).
+				WithProperty("analyzed_at", openapi3.NewDateTimeSchema()))).
+			WithProperty("total", openapi3.NewIntegerSchema()).
+			WithProperty("limit", openapi3.NewIntegerSchema()).
+			WithProperty("offset", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid limit or offset").
+		withError(http.StatusNotFound, "No cached versions").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/changelog", http.MethodGet, newOperation("getSDKChangelog", "Cache", "Commits to an SDK's repository since its last analysis, newest first").
+		withPathParam("name", "SDK name").
+		withQueryParam("since", "List commits since this RFC 3339 time instead of the last analysis", openapi3.NewDateTimeSchema()).
+		withSuccess(http.StatusOK, "Up to 100 commits", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("hash", openapi3.NewStringSchema()).
+			WithProperty("author", openapi3.NewStringSchema()).
+			WithProperty("message", openapi3.NewStringSchema()).
+			WithProperty("files_changed", openapi3.NewIntegerSchema()).
+			WithProperty("timestamp", openapi3.NewDateTimeSchema()))).
+		withError(http.StatusBadRequest, "Invalid since").
+		withError(http.StatusNotFound, "SDK not analyzed yet or unknown").
+		withError(http.StatusConflict, "SDK repository not cloned").
+		withError(http.StatusInternalServerError, "Cache or repository read failed").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/token-savings", http.MethodGet, newOperation("getSDKTokenSavings", "Cache", "Tokens saved by reads of an SDK's cached analysis since it was written").
+		withPathParam("name", "SDK name").
+		withSuccess(http.StatusOK, "SDK token savings", openapi3.NewObjectSchema().
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("estimated_input_tokens", openapi3.NewInt64Schema()).
+			WithProperty("times_served_from_cache", openapi3.NewInt64Schema()).
+			WithProperty("total_tokens_saved", openapi3.NewInt64Schema())).
+		withError(http.StatusNotFound, "SDK cache not found").
+		withError(http.StatusInternalServerError, "Cache read failed").
+		build())
+
+	doc.AddOperation("/api/v1/cache/sdk/{name}/diff", http.MethodGet, newOperation("diffSDKAnalyses", "Cache", "Diff two cached analysis versions of an SDK").
+		withPathParam("name", "SDK name").
+		withQueryParam("from", "Analysis version to compare from", openapi3.NewStringSchema()).
+		withQueryParam("to", "Analysis version to compare to; defaults to the latest analysis", openapi3.NewStringSchema()).
+		withSuccess(http.StatusOK, "Analysis diff", analysisDiffSchema("from", "to")).
+		withError(http.StatusBadRequest, "from is missing").
+		withError(http.StatusNotFound, "One of the analyses is not cached").
+		withError(http.StatusInternalServerError, "Cache read failed or a cached analysis is invalid").
+		build())
+
+	doc.AddOperation("/api/v1/cache/refresh", http.MethodPost, newOperation("refreshCache", "Cache", "Queue a cache refresh").
+		withOptionalJSONBodyRef(schemaRef("RefreshRequest")).
+		withSuccess(http.StatusAccepted, "Refresh queued", openapi3.NewObjectSchema().
+			WithProperty("job_id", openapi3.NewStringSchema()).
+			WithProperty("type", openapi3.NewStringSchema()).
+			WithProperty("targets", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithProperty("force", openapi3.NewBoolSchema()).
+			WithProperty("force_sdk", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+			WithProperty("position", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid request or unknown SDK").
+		withError(http.StatusRequestEntityTooLarge, "Request body over the limit").
+		withError(http.StatusServiceUnavailable, "Refresh queue is full or worker is shutting down").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/refresh/{job_id}", http.MethodGet, newOperation("getRefreshStatus", "Cache", "Status of a queued cache refresh").
+		withPathParam("job_id", "Refresh job ID").
+		withSuccess(http.StatusOK, "Refresh job status", jobProgressSchema()).
+		withError(http.StatusNotFound, "Refresh job not found").
+		build())
+
+	doc.AddOperation("/api/v1/cache/key/{key}", http.MethodDelete, newOperation("deleteCacheKey", "Cache", "Delete a cache key").
+		withPathParam("key", "Cache key").
+		withSuccess(http.StatusOK, "Cache key deleted", openapi3.NewObjectSchema().
+			WithProperty("deleted", openapi3.NewStringSchema())).
+		withError(http.StatusInternalServerError, "Failed to delete cache key").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/key/{key}/touch", http.MethodPost, newOperation("touchCacheKey", "Cache", "Restart the TTL of a cache key without changing its value").
+		withPathParam("key", "Cache key").
+		withOptionalJSONBody(openapi3.NewObjectSchema().
+			WithProperty("ttl", openapi3.NewStringSchema())).
+		withSuccess(http.StatusOK, "Cache key touched", openapi3.NewObjectSchema().
+			WithProperty("touched", openapi3.NewStringSchema())).
+		withError(http.StatusBadRequest, "Invalid ttl").
+		withError(http.StatusNotFound, "Cache key not found").
+		withError(http.StatusInternalServerError, "Failed to touch cache key").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/warmup", http.MethodPost, newOperation("warmupCache", "Cache", "Analyze every missing or stale SDK and wait for the results").
+		withQueryParam("dry_run", "Estimate the cost instead of analyzing", openapi3.NewBoolSchema()).
+		withSuccess(http.StatusOK, "Warmup summary", warmupSummarySchema()).
+		withError(http.StatusBadRequest, "Invalid request").
+		withError(http.StatusServiceUnavailable, "Worker is shutting down").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/warm", http.MethodPost, newOperation("warmCache", "Cache", "Analyze and cache SDKs in the background").
+		withQueryParam("force", "Re-analyze SDKs that are still fresh", openapi3.NewBoolSchema()).
+		withJSONBody(openapi3.NewObjectSchema().
+			WithProperty("sdks", openapi3.NewArraySchema().
+				WithItems(openapi3.NewStringSchema()).
+				WithMinItems(1)).
+			WithRequired([]string{"sdks"})).
+		withSuccess(http.StatusAccepted, "Warm job started", openapi3.NewObjectSchema().
+			WithProperty("job_id", openapi3.NewStringSchema())).
+		withError(http.StatusBadRequest, "Invalid request").
+		withError(http.StatusNotFound, "Unknown SDK").
+		withError(http.StatusRequestEntityTooLarge, "Request body over the limit").
+		withError(http.StatusServiceUnavailable, "Worker is shutting down").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/maintenance/shrink", http.MethodPost, newOperation("shrinkCache", "Cache", "Compact the cache database file").
+		withSuccess(http.StatusOK, "Cache compacted", compactResultSchema()).
+		withError(http.StatusInternalServerError, "Failed to compact cache database").
+		withError(http.StatusNotImplemented, "Cache backend has no database file to compact").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/cache/export", http.MethodGet, newOperation("exportCache", "Cache", "Export every unexpired cache entry").
+		withHeaderParam("Accept", "application/x-ndjson (default) for one JSON cache entry per line, text/csv for one row per entry without its value, or application/zip for one file per value").
+		withRawResponse(http.StatusOK, "Cache entries in the accepted format", exportFormats...).
+		withError(http.StatusNotAcceptable, "No export format is acceptable").
+		withBearerAuth().
+		build())
+
+	ndjson := openapi3.NewStringSchema().WithFormat("binary")
+	doc.AddOperation("/api/v1/cache/import", http.MethodPost, newOperation("importCache", "Cache", "Import a cache export").
+		withQueryParam("overwrite", "Replace keys that are already cached", openapi3.NewBoolSchema()).
+		withContentBody(openapi3.Content{
+			"application/x-ndjson": openapi3.NewMediaType().WithSchema(ndjson),
+			"multipart/form-data": openapi3.NewMediaType().WithSchema(openapi3.NewObjectSchema().
+				WithProperty("file", ndjson).
+				WithRequired([]string{"file"})),
+		}).
+		withSuccess(http.StatusOK, "Cache imported", openapi3.NewObjectSchema().
+			WithProperty("imported", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid export; entries before the invalid record are kept").
+		withError(http.StatusRequestEntityTooLarge, "Request body over the limit; entries before it are kept").
+		withBearerAuth().
+		build())
+
+	// SDK reports
+	doc.AddOperation("/api/v1/sdk/list", http.MethodGet, newOperation("listSDKs", "SDKs", "Configured SDKs and when each was last analyzed").
+		withQueryParam("language", "Only list SDKs in this language", openapi3.NewStringSchema()).
+		withQueryParam("active", "Only list active or inactive SDKs", openapi3.NewBoolSchema()).
+		withSuccess(http.StatusOK, "Configured SDKs", openapi3.NewObjectSchema().
+			WithProperty("sdks", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+				WithProperty("name", openapi3.NewStringSchema()).
+				WithProperty("url", openapi3.NewStringSchema()).
+				WithProperty("language", openapi3.NewStringSchema()).
+				WithProperty("active", openapi3.NewBoolSchema()).
+				WithProperty("last_analyzed", openapi3.NewDateTimeSchema().WithNullable()))).
+			WithProperty("total", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid active filter").
+		build())
+
+	doc.AddOperation("/api/v1/sdk/{name}/toggle", http.MethodPut, newOperation("toggleSDK", "SDKs", "Enable or disable an SDK until restart").
+		withPathParam("name", "SDK name").
+		withJSONBody(openapi3.NewObjectSchema().
+			WithProperty("active", openapi3.NewBoolSchema()).
+			WithRequired([]string{"active"})).
+		withSuccess(http.StatusOK, "SDK updated", openapi3.NewObjectSchema().
+			WithProperty("name", openapi3.NewStringSchema()).
+			WithProperty("active", openapi3.NewBoolSchema()).
+			WithProperty("previous", openapi3.NewBoolSchema())).
+		withError(http.StatusBadRequest, "Invalid request body").
+		withError(http.StatusNotFound, "Unknown SDK").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/sdk/register", http.MethodPost, newOperation("registerSDK", "SDKs", "Add an SDK, kept across restarts").
+		withJSONBody(sdkConfigSchema().
+			WithRequired([]string{"name", "url", "language", "patterns"})).
+		withSuccess(http.StatusCreated, "SDK registered", sdkConfigSchema()).
+		withError(http.StatusBadRequest, "Invalid SDK configuration").
+		withError(http.StatusConflict, "An SDK with this name or URL exists").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/sdk/{name}", http.MethodDelete, newOperation("unregisterSDK", "SDKs", "Remove an SDK, kept across restarts").
+		withPathParam("name", "SDK name").
+		withSuccess(http.StatusOK, "SDK unregistered", openapi3.NewObjectSchema().
+			WithProperty("unregistered", openapi3.NewStringSchema())).
+		withError(http.StatusNotFound, "Unknown SDK").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/sdk/{name}/clean", http.MethodPost, newOperation("cleanSDKRepo", "SDKs", "Remove the cloned repository of an inactive SDK").
+		withPathParam("name", "SDK name").
+		withSuccess(http.StatusOK, "Repository cleaned", openapi3.NewObjectSchema().
+			WithProperty("name", openapi3.NewStringSchema()).
+			WithProperty("removed", openapi3.NewBoolSchema())).
+		withError(http.StatusNotFound, "Unknown SDK").
+		withError(http.StatusConflict, "SDK is active").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/sdk/{name}/estimate-cost", http.MethodPost, newOperation("estimateSDKCost", "SDKs", "Estimate the tokens and price of analyzing an SDK at its current HEAD").
+		withPathParam("name", "SDK name").
+		withSuccess(http.StatusOK, "Cost estimate", costEstimateSchema()).
+		withError(http.StatusNotFound, "Unknown SDK").
+		withError(http.StatusServiceUnavailable, "SDK analyzer is not available").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/sdks/{name}/compliance", http.MethodGet, newOperation("getSDKCompliance", "SDKs", "Sentry protocol compliance of an SDK").
+		withPathParam("name", "SDK name").
+		withSuccess(http.StatusOK, "Compliance report", openapi3.NewObjectSchema().
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("compliant", openapi3.NewBoolSchema()).
+			WithProperty("violations", openapi3.NewArraySchema().
+				WithItems(openapi3.NewObjectSchema().
+					WithProperty("rule", openapi3.NewStringSchema()).
+					WithProperty("severity", openapi3.NewStringSchema().WithEnum("error", "warning")).
+					WithProperty("description", openapi3.NewStringSchema())))).
+		withError(http.StatusNotFound, "SDK analysis not found").
+		withError(http.StatusInternalServerError, "Cache read failed or cached analysis is invalid").
+		build())
+	doc.AddOperation("/api/v1/sdks/{name}/compare", http.MethodGet, newOperation("compareSDKAnalyses", "SDKs", "Diff two cached analyses of an SDK").
+		withPathParam("name", "SDK name").
+		withQueryParam("version1", "Analysis version to compare from", openapi3.NewStringSchema()).
+		withQueryParam("version2", "Analysis version to compare to; defaults to the latest analysis", openapi3.NewStringSchema()).
+		withSuccess(http.StatusOK, "Analysis diff", analysisDiffSchema("version1", "version2")).
+		withError(http.StatusBadRequest, "version1 is missing").
+		withError(http.StatusNotFound, "One of the analyses is not cached").
+		withError(http.StatusInternalServerError, "Cache read failed or a cached analysis is invalid").
+		build())
+
+	// Ad-hoc analysis
+	doc.AddOperation("/api/v1/batch/analyze", http.MethodPost, newOperation("batchAnalyze", "Analysis", "Analyze code that is not in a git repository").
+		withJSONBody(openapi3.NewObjectSchema().
+			WithProperty("analyses", openapi3.NewArraySchema().
+				WithItems(openapi3.NewObjectSchema().
+					WithProperty("sdk_name", openapi3.NewStringSchema()).
+					WithProperty("version", openapi3.NewStringSchema()).
+					WithProperty("code", openapi3.NewObjectSchema().
+						WithAdditionalProperties(openapi3.NewStringSchema())).
+					WithRequired([]string{"sdk_name", "code"})).
+				WithMinItems(1)).
+			WithRequired([]string{"analyses"})).
+		withSuccess(http.StatusOK, "Analysis results", batchAnalysisResultSchema()).
+		withSuccess(http.StatusAccepted, "Analysis started in the background", batchAnalysisResultSchema()).
+		withError(http.StatusBadRequest, "Invalid request").
+		withError(http.StatusRequestEntityTooLarge, "Request body or estimated tokens over the limit").
+		withError(http.StatusServiceUnavailable, "Worker is shutting down").
+		withBearerAuth().
+		build())
+
+	// Background jobs
+	doc.AddOperation("/api/v1/jobs/{job_id}/progress", http.MethodGet, newOperation("getJobProgress", "Jobs", "Progress of a background job").
+		withPathParam("job_id", "Job ID").
+		withSuccess(http.StatusOK, "Job progress", jobProgressSchema()).
+		withError(http.StatusNotFound, "Job not found").
+		build())
+
+	// Analytics
+	doc.AddOperation("/api/v1/analytics/usage", http.MethodGet, newOperation("getUsageAnalytics", "Analytics", "Token savings and request counts").
+		withQueryParam("since", "Only count savings on entries last active at or after this RFC 3339 time", openapi3.NewDateTimeSchema()).
+		withQueryParam("until", "Only count savings on entries last active at or before this RFC 3339 time", openapi3.NewDateTimeSchema()).
+		withQueryParam("from", "Start of the hourly time series; defaults to a day before to", openapi3.NewDateTimeSchema()).
+		withQueryParam("to", "End of the hourly time series; defaults to now", openapi3.NewDateTimeSchema()).
+		withSuccess(http.StatusOK, "Usage analytics", openapi3.NewObjectSchema().
+			WithProperty("token_savings", openapi3.NewObjectSchema().
+				WithProperty("total", openapi3.NewInt64Schema()).
+				WithProperty("percentage", openapi3.NewFloat64Schema()).
+				WithProperty("by_sdk", openapi3.NewObjectSchema().
+					WithAdditionalProperties(openapi3.NewInt64Schema()))).
+			WithProperty("requests", openapi3.NewObjectSchema().
+				WithProperty("total", openapi3.NewInt64Schema()).
+				WithProperty("cached", openapi3.NewInt64Schema())).
+			WithProperty("window", openapi3.NewObjectSchema().
+				WithProperty("since", openapi3.NewDateTimeSchema().WithNullable()).
+				WithProperty("until", openapi3.NewDateTimeSchema().WithNullable())).
+			WithProperty("timeseries", openapi3.NewObjectSchema().WithNullable().
+				WithProperty("from", openapi3.NewDateTimeSchema()).
+				WithProperty("to", openapi3.NewDateTimeSchema()).
+				WithProperty("buckets", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+					WithProperty("start", openapi3.NewDateTimeSchema()).
+					WithProperty("hits", openapi3.NewInt64Schema()).
+					WithProperty("misses", openapi3.NewInt64Schema()).
+					WithProperty("tokens_saved", openapi3.NewInt64Schema()))))).
+		withError(http.StatusBadRequest, "Invalid since, until, from or to").
+		build())
+
+	doc.AddOperation("/api/v1/analytics/performance", http.MethodGet, newOperation("getPerformanceAnalytics", "Analytics", "Response time and cache latency").
+		withSuccess(http.StatusOK, "Performance analytics", openapi3.NewObjectSchema().
+			WithProperty("response_times", openapi3.NewObjectSchema().
+				WithProperty("p50", openapi3.NewFloat64Schema()).
+				WithProperty("p95", openapi3.NewFloat64Schema()).
+				WithProperty("p99", openapi3.NewFloat64Schema()).
+				WithProperty("samples", openapi3.NewIntegerSchema())).
+			WithProperty("cache_performance", openapi3.NewObjectSchema().
+				WithProperty("hit_rate", openapi3.NewFloat64Schema()).
+				WithProperty("avg_latency_ms", openapi3.NewFloat64Schema()).
+				WithProperty("avg_set_latency_ms", openapi3.NewFloat64Schema())).
+			WithProperty("compression", openapi3.NewObjectSchema().
+				WithProperty("compressed_responses", openapi3.NewInt64Schema()).
+				WithProperty("bytes_saved", openapi3.NewInt64Schema()))).
+		build())
+
+	// Update worker
+	doc.AddOperation("/api/v1/worker/metrics", http.MethodGet, newOperation("getWorkerMetrics", "Worker", "Get moving averages over recent update runs").
+		withSuccess(http.StatusOK, "Worker metrics", workerMetricsSchema()).
+		build())
+	doc.AddOperation("/api/v1/worker/status", http.MethodGet, newOperation("getWorkerStatus", "Worker", "Get whether an update is running, the last run and the next scheduled run").
+		withSuccess(http.StatusOK, "Worker status", openapi3.NewObjectSchema().
+			WithProperty("running", openapi3.NewBoolSchema()).
+			WithProperty("last_run_at", openapi3.NewDateTimeSchema().WithNullable()).
+			WithProperty("last_run_duration_seconds", openapi3.NewFloat64Schema()).
+			WithProperty("last_run_success_count", openapi3.NewIntegerSchema()).
+			WithProperty("last_run_error_count", openapi3.NewIntegerSchema()).
+			WithProperty("next_run_at", openapi3.NewDateTimeSchema().WithNullable()).
+			WithProperty("schedule", openapi3.NewStringSchema())).
+		build())
+	doc.AddOperation("/api/v1/worker/pool-stats", http.MethodGet, newOperation("getWorkerPoolStats", "Worker", "Get analysis slot usage per priority tier").
+		withSuccess(http.StatusOK, "Worker pool stats", openapi3.NewObjectSchema().
+			WithProperty("tiers", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+				WithProperty("priority", openapi3.NewStringSchema().WithEnum("high", "normal", "low")).
+				WithProperty("slots", openapi3.NewIntegerSchema()).
+				WithProperty("slots_used", openapi3.NewIntegerSchema()).
+				WithProperty("active", openapi3.NewIntegerSchema()).
+				WithProperty("waiting", openapi3.NewIntegerSchema()).
+				WithProperty("completed", openapi3.NewIntegerSchema())))).
+		build())
+	doc.AddOperation("/api/v1/worker/reset-backoff", http.MethodPost, newOperation("resetWorkerBackoff", "Worker", "Clear the update worker failure backoff").
+		withSuccess(http.StatusOK, "Backoff reset", openapi3.NewObjectSchema().
+			WithProperty("previous_consecutive_failures", openapi3.NewIntegerSchema())).
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/worker/dead-letter", http.MethodGet, newOperation("listDeadLetters", "Worker", "List SDKs whose analyses failed, most recent failure first").
+		withSuccess(http.StatusOK, "Failed analyses", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("failed_at", openapi3.NewDateTimeSchema()).
+			WithProperty("first_failed_at", openapi3.NewDateTimeSchema()).
+			WithProperty("error", openapi3.NewStringSchema()).
+			WithProperty("attempts", openapi3.NewIntegerSchema()))).
+		withError(http.StatusInternalServerError, "Failed to list failed analyses").
+		build())
+	doc.AddOperation("/api/v1/worker/dead-letter/{sdk}/retry", http.MethodPost, newOperation("retryDeadLetter", "Worker", "Requeue the analysis of an SDK whose analyses failed, enabling it again if they disabled it").
+		withPathParam("sdk", "SDK name").
+		withSuccess(http.StatusAccepted, "Retry queued", openapi3.NewObjectSchema().
+			WithProperty("job_id", openapi3.NewStringSchema()).
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("position", openapi3.NewIntegerSchema())).
+		withError(http.StatusNotFound, "SDK has no failed analyses").
+		withError(http.StatusServiceUnavailable, "Worker is shutting down or the refresh queue is full").
+		withBearerAuth().
+		build())
+
+	// System maintenance
+	doc.AddOperation("/api/v1/system/cache/compact", http.MethodPost, newOperation("compactCache", "System", "Compact the cache database file").
+		withSuccess(http.StatusOK, "Cache compacted", compactResultSchema()).
+		withError(http.StatusInternalServerError, "Failed to compact cache database").
+		withError(http.StatusNotImplemented, "Cache backend has no database file to compact").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/system/git/prune", http.MethodPost, newOperation("pruneRepos", "System", "Remove cloned repositories of inactive SDKs").
+		withSuccess(http.StatusOK, "Repositories pruned", openapi3.NewObjectSchema().
+			WithProperty("removed", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))).
+		withError(http.StatusInternalServerError, "Failed to prune repositories").
+		withBearerAuth().
+		build())
+	doc.AddOperation("/api/v1/system/config", http.MethodGet, newOperation("getSystemConfig", "System", "Effective configuration with secrets masked").
+		withSuccess(http.StatusOK, "Effective configuration", configIntrospectionSchema()).
+		withBearerAuth().
+		build())
+
+	// Administration
+	doc.AddOperation("/api/v1/admin/features", http.MethodGet, newOperation("listFeatures", "Admin", "List feature flags").
+		withSuccess(http.StatusOK, "Feature flags", openapi3.NewObjectSchema().
+			WithProperty("features", openapi3.NewObjectSchema().
+				WithAdditionalProperties(openapi3.NewBoolSchema()))).
+		build())
+
+	doc.AddOperation("/api/v1/admin/features/{name}", http.MethodPatch, newOperation("setFeature", "Admin", "Toggle a feature flag at runtime").
+		withPathParam("name", "Feature flag name").
+		withJSONBody(openapi3.NewObjectSchema().
+			WithProperty("enabled", openapi3.NewBoolSchema()).
+			WithRequired([]string{"enabled"})).
+		withSuccess(http.StatusOK, "Feature flag updated", openapi3.NewObjectSchema().
+			WithProperty("name", openapi3.NewStringSchema()).
+			WithProperty("enabled", openapi3.NewBoolSchema()).
+			WithProperty("previous", openapi3.NewBoolSchema())).
+		withError(http.StatusBadRequest, "Invalid request body").
+		withError(http.StatusNotFound, "Unknown feature flag").
+		withBearerAuth().
+		build())
+
+	doc.AddOperation("/api/v1/admin/analytics/retention", http.MethodGet, newOperation("getAnalyticsRetention", "Admin", "Analytics retention policy and last prune result").
+		withSuccess(http.StatusOK, "Retention policy", openapi3.NewObjectSchema().
+			WithProperty("enabled", openapi3.NewBoolSchema()).
+			WithProperty("policy", retentionPolicySchema()).
+			WithProperty("last_prune", pruneResultSchema().WithNullable())).
+		build())
+
+	doc.AddOperation("/api/v1/admin/analytics/prune", http.MethodPost, newOperation("pruneAnalytics", "Admin", "Prune analytics events past their retention").
+		withSuccess(http.StatusOK, "Prune result", pruneResultSchema()).
+		withError(http.StatusServiceUnavailable, "Analytics is disabled").
+		withError(http.StatusInternalServerError, "Failed to prune analytics").
+		withBearerAuth().
+		build())
+
+	// Prompts
+	doc.AddOperation("/api/v1/prompts/{name}", http.MethodGet, newOperation("getPrompt", "Prompts", "Analysis prompt template in use").
+		withPathParam("name", "Prompt template name: sdk_analysis or incremental_analysis").
+		withSuccess(http.StatusOK, "Prompt template and where it was loaded from", openapi3.NewObjectSchema().
+			WithProperty("name", openapi3.NewStringSchema()).
+			WithProperty("source", openapi3.NewStringSchema()).
+			WithProperty("template", openapi3.NewStringSchema())).
+		withError(http.StatusNotFound, "Unknown prompt template").
+		build())
+
+	// Audit
+	doc.AddOperation("/api/v1/audit", http.MethodGet, newOperation("listAuditEvents", "Audit", "Recent mutating operations, newest first").
+		withQueryParam("limit", "Maximum events to return", openapi3.NewIntegerSchema().WithMin(1).WithMax(maxAuditLimit).WithDefault(defaultAuditLimit)).
+		withQueryParam("action", "Only return events with this action, such as delete or import", openapi3.NewStringSchema()).
+		withSuccess(http.StatusOK, "Audit events", openapi3.NewObjectSchema().
+			WithProperty("events", openapi3.NewArraySchema().WithItems(auditEventSchema())).
+			WithProperty("count", openapi3.NewIntegerSchema())).
+		withError(http.StatusBadRequest, "Invalid limit").
+		withError(http.StatusServiceUnavailable, "Analytics is disabled").
+		withError(http.StatusInternalServerError, "Failed to query audit log").
+		withBearerAuth().
+		build())
+
+	// Notifications
+	doc.AddOperation("/api/v1/notify/test", http.MethodPost, newOperation("testNotification", "Notifications", "Send a test notification to the configured webhook and email recipients").
+		withSuccess(http.StatusOK, "The test event that was sent, in the shape of webhook payloads", analysisEventSchema()).
+		withError(http.StatusBadGateway, "A notifier failed to deliver the event").
+		withError(http.StatusServiceUnavailable, "No notifier is configured").
+		withBearerAuth().
+		build())
+
+	// Documentation
+	doc.AddOperation("/api/v1/openapi.json", http.MethodGet, newOperation("getOpenAPIJSON", "Documentation", "OpenAPI spec as JSON").
+		withRawResponse(http.StatusOK, "OpenAPI document", "application/json").
+		build())
+
+	doc.AddOperation("/api/v1/openapi.yaml", http.MethodGet, newOperation("getOpenAPIYAML", "Documentation", "OpenAPI spec as YAML").
+		withRawResponse(http.StatusOK, "OpenAPI document", "application/yaml").
+		build())
+
+	doc.AddOperation("/api/v1/swagger-ui", http.MethodGet, newOperation("getSwaggerUI", "Documentation", "Interactive API documentation").
+		withRawResponse(http.StatusOK, "Swagger UI page", "text/html").
+		build())
+
+	// WebSocket endpoints
+	doc.AddOperation("/ws/updates", http.MethodGet, newOperation("subscribeUpdates", "WebSocket", "Stream cache updates over a WebSocket").
+		withUpgradeResponse().
+		build())
+
+	doc.AddOperation("/ws/project/{name}", http.MethodGet, newOperation("subscribeProject", "WebSocket", "Stream updates for a project over a WebSocket").
+		withPathParam("name", "Project name").
+		withUpgradeResponse().
+		build())
+
+	return doc
+}
+
+// operationBuilder assembles an operation with the repo's standard
+// response envelopes.
+type operationBuilder struct {
+	op *openapi3.Operation
+}
+
+func newOperation(id, tag, summary string) *operationBuilder {
+	op := openapi3.NewOperation()
+	op.OperationID = id
+	op.Tags = []string{tag}
+	op.Summary = summary
+	op.Responses = openapi3.NewResponses()
+	op.Responses.Delete("default")
+	return &operationBuilder{op: op}
+}
+
+func (b *operationBuilder) withPathParam(name, description string) *operationBuilder {
+	b.op.AddParameter(openapi3.NewPathParameter(name).
+		WithDescription(description).
+		WithSchema(openapi3.NewStringSchema()))
+	return b
+}
+
+func (b *operationBuilder) withQueryParam(name, description string, schema *openapi3.Schema) *operationBuilder {
+	b.op.AddParameter(openapi3.NewQueryParameter(name).
+		WithDescription(description).
+		WithSchema(schema))
+	return b
+}
+
+func (b *operationBuilder) withHeaderParam(name, description string) *operationBuilder {
+	b.op.AddParameter(openapi3.NewHeaderParameter(name).
+		WithDescription(description).
+		WithSchema(openapi3.NewStringSchema()))
+	return b
+}
+
+func (b *operationBuilder) withJSONBody(schema *openapi3.Schema) *operationBuilder {
+	b.op.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithJSONSchema(schema),
+	}
+	return b
+}
+
+func (b *operationBuilder) withOptionalJSONBody(schema *openapi3.Schema) *operationBuilder {
+	b.op.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithJSONSchema(schema),
+	}
+	return b
+}
+
+func (b *operationBuilder) withOptionalJSONBodyRef(schema *openapi3.SchemaRef) *operationBuilder {
+	b.op.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithJSONSchemaRef(schema),
+	}
+	return b
+}
+
+// withContentBody documents a required request body in the given media types.
+func (b *operationBuilder) withContentBody(content openapi3.Content) *operationBuilder {
+	b.op.RequestBody = &openapi3.RequestBodyRef{
+		Value: openapi3.NewRequestBody().
+			WithRequired(true).
+			WithContent(content),
+	}
+	return b
+}
+
+func (b *operationBuilder) withResponse(status int, description string, schema *openapi3.SchemaRef) *operationBuilder {
+	b.op.AddResponse(status, openapi3.NewResponse().
+		WithDescription(description).
+		WithJSONSchemaRef(schema))
+	return b
+}
+
+// withSuccess documents a SuccessResponse envelope whose data field has the given schema.
+func (b *operationBuilder) withSuccess(status int, description string, data *openapi3.Schema) *operationBuilder {
+	envelope := openapi3.NewAllOfSchema()
+	envelope.AllOf = openapi3.SchemaRefs{
+		schemaRef("SuccessResponse"),
+		openapi3.NewSchemaRef("", openapi3.NewObjectSchema().WithProperty("data", data)),
+	}
+	return b.withResponse(status, description, openapi3.NewSchemaRef("", envelope))
+}
+
+func (b *operationBuilder) withError(status int, description string) *operationBuilder {
+	return b.withResponse(status, description, schemaRef("ErrorResponse"))
+}
+
+func (b *operationBuilder) withRawResponse(status int, description string, contentTypes ...string) *operationBuilder {
+	b.op.AddResponse(status, openapi3.NewResponse().
+		WithDescription(description).
+		WithContent(openapi3.NewContentWithSchema(openapi3.NewStringSchema(), contentTypes)))
+	return b
+}
+
+func (b *operationBuilder) withEmptyResponse(status int, description string) *operationBuilder {
+	b.op.AddResponse(status, openapi3.NewResponse().WithDescription(description))
+	return b
+}
+
+func (b *operationBuilder) withUpgradeResponse() *operationBuilder {
+	b.op.AddResponse(http.StatusSwitchingProtocols, openapi3.NewResponse().
+		WithDescription("Switching to the WebSocket protocol"))
+	return b
+}
+
+// withBearerAuth marks the operation as requiring an API key and documents
+// the 401 returned without one.
+func (b *operationBuilder) withBearerAuth() *operationBuilder {
+	b.op.Security = openapi3.NewSecurityRequirements().
+		With(openapi3.NewSecurityRequirement().Authenticate(bearerAuthScheme))
+	return b.withError(http.StatusUnauthorized, "Missing or invalid API key")
+}
+
+func (b *operationBuilder) build() *openapi3.Operation {
+	return b.op
+}
+
+// schemaRef references a component schema, carrying its resolved value so
+// the generated document validates without a loader pass.
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, componentSchemas[name].Value)
+}
+
+func successResponseSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("data", &openapi3.Schema{}).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("request_id", openapi3.NewStringSchema()).
+		WithProperty("timestamp", openapi3.NewInt64Schema()).
+		WithRequired([]string{"data", "message", "request_id", "timestamp"})
+}
+
+func errorResponseSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("error", openapi3.NewStringSchema()).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("request_id", openapi3.NewStringSchema()).
+		WithProperty("timestamp", openapi3.NewInt64Schema()).
+		WithRequired([]string{"error", "message", "request_id", "timestamp"})
+}
+
+func healthResponseSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewStringSchema()).
+		WithProperty("version", openapi3.NewStringSchema()).
+		WithProperty("cache", openapi3.NewObjectSchema().
+			WithProperty("items", openapi3.NewInt64Schema()).
+			WithProperty("size", openapi3.NewInt64Schema()).
+			WithProperty("hit_rate", openapi3.NewFloat64Schema())).
+		WithProperty("timestamp", openapi3.NewInt64Schema())
+}
+
+func deepHealthResponseSchema() *openapi3.Schema {
+	check := openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewStringSchema().WithEnum(healthOK, healthDegraded, healthError)).
+		WithProperty("message", openapi3.NewStringSchema())
+
+	return openapi3.NewObjectSchema().
+		WithProperty("status", openapi3.NewStringSchema().WithEnum(healthOK, healthDegraded, healthError)).
+		WithProperty("version", openapi3.NewStringSchema()).
+		WithProperty("checks", openapi3.NewObjectSchema().
+			WithProperty("cache", check).
+			WithProperty("git_work_dir", check).
+			WithProperty("claude_api_key", check).
+			WithProperty("disk_space", check)).
+		WithProperty("timestamp", openapi3.NewInt64Schema())
+}
+
+func telemetryHealthResponseSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("enabled", openapi3.NewBoolSchema()).
+		WithProperty("endpoint", openapi3.NewStringSchema()).
+		WithProperty("connected", openapi3.NewBoolSchema()).
+		WithProperty("last_export_at", openapi3.NewDateTimeSchema().WithNullable()).
+		WithProperty("last_error", openapi3.NewStringSchema()).
+		WithProperty("timestamp", openapi3.NewInt64Schema())
+}
+
+func claudeHealthResponseSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("state", openapi3.NewStringSchema().WithEnum(
+			string(claude.CircuitClosed), string(claude.CircuitOpen), string(claude.CircuitHalfOpen), circuitDisabled)).
+		WithProperty("message", openapi3.NewStringSchema()).
+		WithProperty("consecutive_failures", openapi3.NewIntegerSchema()).
+		WithProperty("failure_threshold", openapi3.NewIntegerSchema()).
+		WithProperty("opened_at", openapi3.NewDateTimeSchema().WithNullable()).
+		WithProperty("retry_at", openapi3.NewDateTimeSchema().WithNullable()).
+		WithProperty("timestamp", openapi3.NewInt64Schema())
+}
+
+func refreshRequestSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema().WithEnum("full", "incremental", "specific")).
+		WithProperty("targets", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("force", openapi3.NewBoolSchema()).
+		WithProperty("force_sdk", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))
+}
+
+func sdkConfigSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("url", openapi3.NewStringSchema()).
+		WithProperty("language", openapi3.NewStringSchema()).
+		WithProperty("patterns", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("key_files", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("branch", openapi3.NewStringSchema()).
+		WithProperty("active", openapi3.NewBoolSchema().WithDefault(true)).
+		WithProperty("is_private", openapi3.NewBoolSchema()).
+		WithProperty("estimated_size", openapi3.NewInt64Schema()).
+		WithProperty("priority", openapi3.NewIntegerSchema().WithMin(0).WithMax(3)).
+		WithProperty("max_file_size", openapi3.NewInt64Schema()).
+		WithProperty("max_files", openapi3.NewIntegerSchema()).
+		WithProperty("max_total_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("exclude_patterns", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("schedule", openapi3.NewStringSchema())
+}
+
+func cacheStatisticsSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("hits", openapi3.NewInt64Schema()).
+		WithProperty("misses", openapi3.NewInt64Schema()).
+		WithProperty("sets", openapi3.NewInt64Schema()).
+		WithProperty("deletes", openapi3.NewInt64Schema()).
+		WithProperty("touches", openapi3.NewInt64Schema()).
+		WithProperty("l1_hits", openapi3.NewInt64Schema()).
+		WithProperty("l2_hits", openapi3.NewInt64Schema()).
+		WithProperty("total_size", openapi3.NewInt64Schema()).
+		WithProperty("item_count", openapi3.NewInt64Schema()).
+		WithProperty("ejected_count", openapi3.NewInt64Schema()).
+		WithProperty("compression_ratio", openapi3.NewFloat64Schema()).
+		WithProperty("hit_rate", openapi3.NewFloat64Schema()).
+		WithProperty("last_compaction", openapi3.NewDateTimeSchema().WithNullable()).
+		WithProperty("bytes_reclaimed", openapi3.NewInt64Schema())
+}
+
+func compactResultSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("before_bytes", openapi3.NewInt64Schema()).
+		WithProperty("after_bytes", openapi3.NewInt64Schema()).
+		WithProperty("savings_bytes", openapi3.NewInt64Schema())
+}
+
+func retentionPolicySchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("token_event_days", openapi3.NewIntegerSchema()).
+		WithProperty("cache_event_days", openapi3.NewIntegerSchema()).
+		WithProperty("audit_log_days", openapi3.NewIntegerSchema())
+}
+
+func pruneResultSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("token_events_deleted", openapi3.NewIntegerSchema()).
+		WithProperty("cache_events_deleted", openapi3.NewIntegerSchema()).
+		WithProperty("audit_events_deleted", openapi3.NewIntegerSchema()).
+		WithProperty("buckets_deleted", openapi3.NewIntegerSchema()).
+		WithProperty("pruned_at", openapi3.NewDateTimeSchema())
+}
+
+func auditEventSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("timestamp", openapi3.NewDateTimeSchema()).
+		WithProperty("request_id", openapi3.NewStringSchema()).
+		WithProperty("client_ip", openapi3.NewStringSchema()).
+		WithProperty("action", openapi3.NewStringSchema()).
+		WithProperty("key", openapi3.NewStringSchema()).
+		WithProperty("actor", openapi3.NewStringSchema()).
+		WithProperty("success", openapi3.NewBoolSchema())
+}
+
+func analysisEventSchema() *openapi3.Schema {
+	diff := openapi3.NewObjectSchema()
+	diff.Description = "Changes since the previous analysis, as returned by diffSDKAnalyses; absent for first analyses and test events"
+
+	return openapi3.NewObjectSchema().
+		WithProperty("type", openapi3.NewStringSchema().WithEnum(notify.EventAnalysisUpdated, notify.EventTest)).
+		WithProperty("sdk", openapi3.NewStringSchema()).
+		WithProperty("analysis_version", openapi3.NewStringSchema()).
+		WithProperty("analyzed_at", openapi3.NewDateTimeSchema()).
+		WithProperty("incremental", openapi3.NewBoolSchema()).
+		WithProperty("tokens_used", openapi3.NewIntegerSchema()).
+		WithProperty("diff", diff)
+}
+
+func jobProgressSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("job_id", openapi3.NewStringSchema()).
+		WithProperty("kind", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewStringSchema().WithEnum("queued", "running", "completed", "cancelled")).
+		WithProperty("completed", openapi3.NewIntegerSchema()).
+		WithProperty("total", openapi3.NewIntegerSchema()).
+		WithProperty("errors", openapi3.NewIntegerSchema()).
+		WithProperty("skipped", openapi3.NewIntegerSchema()).
+		WithProperty("percent", openapi3.NewFloat64Schema()).
+		WithProperty("started_at", openapi3.NewDateTimeSchema()).
+		WithProperty("finished_at", openapi3.NewDateTimeSchema())
+}
+
+func costEstimateSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("sdk", openapi3.NewStringSchema()).
+		WithProperty("version", openapi3.NewStringSchema()).
+		WithProperty("estimated_input_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("estimated_output_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("estimated_cost_usd", openapi3.NewFloat64Schema()).
+		WithProperty("file_count", openapi3.NewIntegerSchema()).
+		WithProperty("total_bytes", openapi3.NewIntegerSchema()).
+		WithProperty("passes", openapi3.NewIntegerSchema())
+}
+
+func warmupSummarySchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("dry_run", openapi3.NewBoolSchema()).
+		WithProperty("total", openapi3.NewIntegerSchema()).
+		WithProperty("cached", openapi3.NewIntegerSchema()).
+		WithProperty("estimated", openapi3.NewIntegerSchema()).
+		WithProperty("skipped", openapi3.NewIntegerSchema()).
+		WithProperty("failed", openapi3.NewIntegerSchema()).
+		WithProperty("tokens_used", openapi3.NewIntegerSchema()).
+		WithProperty("estimated_input_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("estimated_output_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("estimated_cost_usd", openapi3.NewFloat64Schema()).
+		WithProperty("sdks", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("sdk", openapi3.NewStringSchema()).
+			WithProperty("status", openapi3.NewStringSchema().WithEnum("analyzed", "estimated", "skipped", "failed")).
+			WithProperty("tokens_used", openapi3.NewIntegerSchema()).
+			WithProperty("estimate", costEstimateSchema()).
+			WithProperty("error", openapi3.NewStringSchema())))
+}
+
+func batchAnalysisResultSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("job_id", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewStringSchema().WithEnum("processing", "completed")).
+		WithProperty("results", openapi3.NewObjectSchema().
+			WithAdditionalProperties(openapi3.NewObjectSchema())).
+		WithProperty("errors", openapi3.NewObjectSchema().
+			WithAdditionalProperties(openapi3.NewStringSchema())).
+		WithProperty("total_tokens", openapi3.NewIntegerSchema()).
+		WithProperty("completed_at", openapi3.NewDateTimeSchema())
+}
+
+func workerMetricsSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("success_rate_ema", openapi3.NewFloat64Schema()).
+		WithProperty("avg_duration_ema_seconds", openapi3.NewFloat64Schema()).
+		WithProperty("tokens_per_run_ema", openapi3.NewFloat64Schema()).
+		WithProperty("runs", openapi3.NewIntegerSchema()).
+		WithProperty("incremental_updates", openapi3.NewIntegerSchema()).
+		WithProperty("repos_cleaned", openapi3.NewIntegerSchema()).
+		WithProperty("last_runs", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("started_at", openapi3.NewDateTimeSchema()).
+			WithProperty("duration_seconds", openapi3.NewFloat64Schema()).
+			WithProperty("succeeded", openapi3.NewIntegerSchema()).
+			WithProperty("failed", openapi3.NewIntegerSchema()).
+			WithProperty("skipped", openapi3.NewIntegerSchema()).
+			WithProperty("tokens_used", openapi3.NewIntegerSchema()).
+			WithProperty("incremental_updates", openapi3.NewIntegerSchema()).
+			WithProperty("error", openapi3.NewStringSchema())))
+}
+
+// analysisDiffSchema describes a diff response naming the compared versions
+// in the fromField and toField properties.
+func analysisDiffSchema(fromField, toField string) *openapi3.Schema {
+	return diffSchema().
+		WithProperty("sdk", openapi3.NewStringSchema()).
+		WithProperty(fromField, openapi3.NewStringSchema()).
+		WithProperty(toField, openapi3.NewStringSchema())
+}
+
+// diffSchema describes an analyzer.AnalysisDiff.
+func diffSchema() *openapi3.Schema {
+	listDiff := openapi3.NewObjectSchema().
+		WithProperty("added", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("removed", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema()))
+
+	return openapi3.NewObjectSchema().
+		WithProperty("changed", openapi3.NewArraySchema().WithItems(openapi3.NewObjectSchema().
+			WithProperty("field", openapi3.NewStringSchema()).
+			WithProperty("old", openapi3.NewStringSchema()).
+			WithProperty("new", openapi3.NewStringSchema()))).
+		WithProperty("lists", openapi3.NewObjectSchema().WithAdditionalProperties(listDiff)).
+		WithProperty("similarity_score", openapi3.NewFloat64Schema().WithMin(0).WithMax(1))
+}
+
+func configIntrospectionSchema() *openapi3.Schema {
+	return openapi3.NewObjectSchema().
+		WithProperty("config", openapi3.NewObjectSchema().
+			WithAnyAdditionalProperties()).
+		WithProperty("effective_cache_ttl", openapi3.NewStringSchema()).
+		WithProperty("active_sdk_count", openapi3.NewIntegerSchema()).
+		WithProperty("disabled_feature_flags", openapi3.NewArraySchema().WithItems(openapi3.NewStringSchema())).
+		WithProperty("next_scheduled_update", openapi3.NewDateTimeSchema()).
+		WithProperty("config_source", openapi3.NewObjectSchema().
+			WithAdditionalProperties(openapi3.NewStringSchema().WithEnum("env", "file", "default")))
+}
+
+// Handlers
+
+func (s *Server) handleOpenAPIJSON(c *gin.Context) {
+	c.JSON(http.StatusOK, s.openapi)
+}
+
+func (s *Server) handleOpenAPIYAML(c *gin.Context) {
+	c.YAML(http.StatusOK, s.openapi)
+}
+
+func (s *Server) handleSwaggerUI(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUIPage)
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateOpenAPISpec(t *testing.T) {
+	doc := GenerateOpenAPISpec("test")
+	require.NoError(t, doc.Validate(context.Background()))
+
+	assert.Equal(t, "test", doc.Info.Version)
+	assert.Contains(t, doc.Components.SecuritySchemes, bearerAuthScheme)
+
+	path := doc.Paths.Find("/api/v1/cache/sdk/{name}")
+	require.NotNil(t, path, "SDK cache path should be documented")
+	require.NotNil(t, path.Get)
+
+	require.Len(t, path.Get.Parameters, 2)
+	assert.Equal(t, "name", path.Get.Parameters[0].Value.Name)
+	assert.Equal(t, openapi3.ParameterInPath, path.Get.Parameters[0].Value.In)
+	assert.Equal(t, "compare_to", path.Get.Parameters[1].Value.Name)
+	assert.Equal(t, openapi3.ParameterInQuery, path.Get.Parameters[1].Value.In)
+
+	ok := path.Get.Responses.Status(http.StatusOK)
+	require.NotNil(t, ok)
+	okSchema := ok.Value.Content.Get("application/json").Schema.Value
+	require.Len(t, okSchema.AllOf, 2)
+	assert.Equal(t, "#/components/schemas/SuccessResponse", okSchema.AllOf[0].Ref)
+
+	notFound := path.Get.Responses.Status(http.StatusNotFound)
+	require.NotNil(t, notFound)
+	assert.Equal(t, "#/components/schemas/ErrorResponse", notFound.Value.Content.Get("application/json").Schema.Ref)
+
+	resetBackoff := doc.Paths.Find("/api/v1/worker/reset-backoff")
+	require.NotNil(t, resetBackoff)
+	require.NotNil(t, resetBackoff.Post)
+	require.NotNil(t, resetBackoff.Post.Security)
+	assert.Contains(t, (*resetBackoff.Post.Security)[0], bearerAuthScheme)
+	assert.NotNil(t, resetBackoff.Post.Responses.Status(http.StatusUnauthorized))
+}
+
+func TestOpenAPIEndpoints(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		unmarshal   func([]byte) (*openapi3.T, error)
+	}{
+		{
+			name:        "json",
+			path:        "/api/v1/openapi.json",
+			contentType: "application/json",
+			unmarshal:   openapi3.NewLoader().LoadFromData,
+		},
+		{
+			name:        "yaml",
+			path:        "/api/v1/openapi.yaml",
+			contentType: "application/yaml",
+			unmarshal:   openapi3.NewLoader().LoadFromData,
+		},
+		{
+			name:        "swagger ui",
+			path:        "/api/v1/swagger-ui",
+			contentType: "text/html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), tt.contentType)
+
+			if tt.unmarshal == nil {
+				assert.Contains(t, w.Body.String(), "swagger-ui")
+				return
+			}
+
+			doc, err := tt.unmarshal(w.Body.Bytes())
+			require.NoError(t, err)
+			require.NoError(t, doc.Validate(context.Background()))
+			assert.NotNil(t, doc.Paths.Find("/api/v1/cache/sdk/{name}"))
+		})
+	}
+}
+
+func TestOpenAPISpecCoversRoutes(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	doc := GenerateOpenAPISpec("test")
+	for _, route := range server.router.Routes() {
+		path := ginPathToOpenAPI(route.Path)
+		item := doc.Paths.Value(path)
+		if !assert.NotNil(t, item, "%s %s is not documented", route.Method, route.Path) {
+			continue
+		}
+		assert.NotNil(t, item.GetOperation(route.Method), "%s %s is not documented", route.Method, route.Path)
+	}
+}
+
+// ginPathToOpenAPI converts gin's :param and *param segments to {param}
+func ginPathToOpenAPI(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
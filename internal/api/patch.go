@@ -0,0 +1,252 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// maxPatchRetries bounds how often a patch is reapplied when the cached
+// analysis changes while it is being patched.
+const maxPatchRetries = 3
+
+// readOnlyAnalysisFields are set by the analyzer or derived from the other
+// fields, so patches cannot change them.
+var readOnlyAnalysisFields = map[string]bool{
+	"tokens_used":           true,
+	"tokens_saved_by_cache": true,
+	"pass_count":            true,
+	"lfs_files_skipped":     true,
+	"analyzed_at":           true,
+	"analysis_version":      true,
+	"sdk_version":           true,
+	"compliance_report":     true,
+	"quality_score":         true,
+}
+
+// analysisFields are the JSON names of the SDKAnalysis fields.
+var analysisFields = jsonFieldNames(reflect.TypeOf(analyzer.SDKAnalysis{}))
+
+// errPatchConflict is returned by patchAnalysis when the cached analysis
+// kept changing while it was patched.
+var errPatchConflict = errors.New("cached analysis changed concurrently")
+
+// handlePatchSDKCache updates some fields of an SDK's cached analysis
+// without analyzing it again. The body is a JSON object of SDKAnalysis
+// fields; null fields are left as they are. The patched analysis gets a new
+// analysis version, is also cached under that version and is returned in
+// full.
+func (s *Server) handlePatchSDKCache(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	var fields map[string]json.RawMessage
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be a JSON object of analysis fields",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if message := validatePatchFields(fields); message != "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   message,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	analysis, err := s.patchAnalysis(sdkName, fields)
+	var invalid *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   fmt.Sprintf("Field %s must be %s", invalid.Field, invalid.Type),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case errors.Is(err, errPatchConflict):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     "conflict",
+			Message:   "SDK analysis changed while it was patched, try again",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case errors.Is(err, cache.ErrNotFound):
+		s.respondCacheError(c, err, "SDK cache not found")
+		return
+	case err != nil:
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to patch SDK analysis")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to patch SDK analysis",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      analysis,
+		Message:   "SDK analysis patched successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// validatePatchFields returns why fields cannot patch an analysis, or ""
+// if they can.
+func validatePatchFields(fields map[string]json.RawMessage) string {
+	if len(fields) == 0 {
+		return "Request body must set at least one analysis field"
+	}
+
+	var unknown, readOnly []string
+	for name := range fields {
+		switch {
+		case !analysisFields[name]:
+			unknown = append(unknown, name)
+		case readOnlyAnalysisFields[name]:
+			readOnly = append(readOnly, name)
+		}
+	}
+	sort.Strings(unknown)
+	sort.Strings(readOnly)
+
+	switch {
+	case len(unknown) > 0:
+		return "Unknown analysis fields: " + strings.Join(unknown, ", ")
+	case len(readOnly) > 0:
+		return "Analysis fields cannot be patched: " + strings.Join(readOnly, ", ")
+	}
+	return ""
+}
+
+// jsonFieldNames returns the names the exported fields of struct type t
+// are encoded under as JSON.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names[name] = true
+	}
+	return names
+}
+
+// patchAnalysis merges fields into the cached analysis of an SDK, rescores
+// it and writes it back under its latest and new version keys. The write
+// is conditional on the version read, so a patch never overwrites an
+// analysis stored meanwhile; it is reapplied up to maxPatchRetries times.
+func (s *Server) patchAnalysis(sdkName string, fields map[string]json.RawMessage) (*analyzer.SDKAnalysis, error) {
+	key := "sdk:" + sdkName
+
+	for retries := 0; ; retries++ {
+		// Patching is not serving the analysis, so it is not a cache hit
+		current, version, err := s.cache.PeekWithVersion(key)
+		if err != nil {
+			return nil, err
+		}
+
+		analysis, err := mergeAnalysisFields(current, fields)
+		if err != nil {
+			return nil, err
+		}
+		analysis.AnalysisVersion = patchedVersion(analysis.AnalysisVersion)
+		analysis.ComplianceReport = analyzer.NewProtocolChecker().Check(analysis)
+		analysis.QualityScore, _ = analyzer.Score(analysis)
+
+		analysisJSON, err := json.Marshal(analysis)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal analysis: %w", err)
+		}
+
+		// The patch does not use any tokens, so the cached analysis
+		// still saves as many as the analysis it was made from
+		opts := []cache.SetOption{
+			cache.WithTokensCached(analysis.TokensUsed),
+			cache.WithTokenHint(int64(analysis.TokensUsed)),
+		}
+		_, err = s.cache.SetWithVersion(key, string(analysisJSON), s.config.CacheTTL, version, opts...)
+		if errors.Is(err, cache.ErrVersionConflict) {
+			if retries < maxPatchRetries {
+				continue
+			}
+			return nil, errPatchConflict
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		versionKey := fmt.Sprintf("%s:%s", key, analysis.AnalysisVersion)
+		if err := s.cache.Set(versionKey, string(analysisJSON), s.config.CacheTTL, opts...); err != nil {
+			return nil, err
+		}
+		return analysis, nil
+	}
+}
+
+// mergeAnalysisFields decodes a cached analysis with the non-null fields
+// replaced.
+func mergeAnalysisFields(analysisJSON string, fields map[string]json.RawMessage) (*analyzer.SDKAnalysis, error) {
+	var merged map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(analysisJSON), &merged); err != nil {
+		return nil, fmt.Errorf("cached analysis is invalid: %w", err)
+	}
+	for name, value := range fields {
+		if string(value) != "null" {
+			merged[name] = value
+		}
+	}
+
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge analysis: %w", err)
+	}
+	var analysis analyzer.SDKAnalysis
+	if err := json.Unmarshal(mergedJSON, &analysis); err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+// patchedVersion returns the analysis version of a patch of an analysis
+// at version, counting patches in a "+patch.N" suffix so that patched
+// analyses are never mistaken for ones the analyzer made.
+func patchedVersion(version string) string {
+	base, patch, found := strings.Cut(version, "+patch.")
+	if n, err := strconv.Atoi(patch); found && err == nil {
+		return fmt.Sprintf("%s+patch.%d", base, n+1)
+	}
+	return version + "+patch.1"
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+func TestPatchSDKCacheEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	analyzedAt := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+	cached := analyzer.SDKAnalysis{
+		Language:        "go",
+		Transport:       analyzer.TransportDetails{Type: "http"},
+		Features:        []string{"breadcrumbs", "sessions"},
+		ProtocolVersion: "7",
+		TokensUsed:      1500,
+		PassCount:       1,
+		AnalyzedAt:      analyzedAt,
+		AnalysisVersion: "1.0.0",
+	}
+	cachedJSON, err := json.Marshal(cached)
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", string(cachedJSON), 0))
+
+	patch := func(sdkName, token, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/api/v1/cache/sdk/"+sdkName, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := patch("sentry-go", "", `{"protocol_version": "8"}`)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = patch("sentry-go", testAPIKeys[0], `{"protocol_version": "8", "features": null}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data analyzer.SDKAnalysis `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	patched := response.Data
+
+	// Only the patched field changes; null fields are ignored
+	assert.Equal(t, "8", patched.ProtocolVersion)
+	assert.Equal(t, cached.Language, patched.Language)
+	assert.Equal(t, cached.Transport, patched.Transport)
+	assert.Equal(t, cached.Features, patched.Features)
+	assert.Equal(t, cached.TokensUsed, patched.TokensUsed)
+	assert.Equal(t, cached.PassCount, patched.PassCount)
+	assert.True(t, patched.AnalyzedAt.Equal(analyzedAt))
+	assert.Equal(t, "1.0.0+patch.1", patched.AnalysisVersion)
+
+	// Patching does not serve the cached analysis, so it is not a hit
+	assert.Zero(t, cacheManager.GetStats().Hits)
+
+	// The full merged analysis is cached as the latest and as its version
+	for _, key := range []string{"sdk:sentry-go", "sdk:sentry-go:1.0.0+patch.1"} {
+		value, err := cacheManager.Get(key)
+		require.NoError(t, err)
+		var stored analyzer.SDKAnalysis
+		require.NoError(t, json.Unmarshal([]byte(value), &stored))
+		assert.Equal(t, patched, stored, key)
+	}
+	savings, err := cacheManager.TokenSavings("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, int64(cached.TokensUsed), savings.EstimatedInputTokens)
+
+	w = patch("sentry-go", testAPIKeys[0], `{"integrations": ["net/http"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"net/http"}, response.Data.Integrations)
+	assert.Equal(t, "8", response.Data.ProtocolVersion)
+	assert.Equal(t, "1.0.0+patch.2", response.Data.AnalysisVersion)
+
+	tests := []struct {
+		name    string
+		sdk     string
+		body    string
+		code    int
+		message string
+	}{
+		{"unknown field", "sentry-go", `{"protocol": "8"}`, http.StatusBadRequest, "Unknown analysis fields: protocol"},
+		{"read-only field", "sentry-go", `{"tokens_used": 0}`, http.StatusBadRequest, "Analysis fields cannot be patched: tokens_used"},
+		{"wrong type", "sentry-go", `{"features": "tracing"}`, http.StatusBadRequest, "Field features must be []string"},
+		{"empty", "sentry-go", `{}`, http.StatusBadRequest, "Request body must set at least one analysis field"},
+		{"not an object", "sentry-go", `["protocol_version"]`, http.StatusBadRequest, "Request body must be a JSON object of analysis fields"},
+		{"not cached", "sentry-python", `{"protocol_version": "8"}`, http.StatusNotFound, "SDK cache not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := patch(tt.sdk, testAPIKeys[0], tt.body)
+			require.Equal(t, tt.code, w.Code, w.Body.String())
+
+			var errResponse ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+			assert.Equal(t, tt.message, errResponse.Message)
+		})
+	}
+}
+
+func TestPatchedVersion(t *testing.T) {
+	assert.Equal(t, "1.0.0+patch.1", patchedVersion("1.0.0"))
+	assert.Equal(t, "1.0.0+patch.3", patchedVersion("1.0.0+patch.2"))
+	assert.Equal(t, "+patch.1", patchedVersion(""))
+}
+
+func TestAnalysisFields(t *testing.T) {
+	// omitempty fields are known even when empty
+	for _, name := range []string{"language", "sdk_version", "validation_errors", "lfs_files_skipped"} {
+		assert.True(t, analysisFields[name], name)
+	}
+	assert.False(t, analysisFields["SDKVersion"])
+	assert.Len(t, analysisFields, reflect.TypeOf(analyzer.SDKAnalysis{}).NumField())
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+)
+
+// handleGetPrompt returns a prompt template in use, with where it was
+// loaded from.
+func (s *Server) handleGetPrompt(c *gin.Context) {
+	name := c.Param("name")
+
+	prompt, ok := claude.GetPromptTemplate(name)
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown prompt template " + name + " (available: " + strings.Join(claude.PromptTemplateNames(), ", ") + ")",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      prompt,
+		Message:   "Prompt template retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+)
+
+func TestGetPromptEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	get := func(name string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/prompts/"+name, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get(claude.PromptSDKAnalysis)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Name     string `json:"name"`
+			Source   string `json:"source"`
+			Template string `json:"template"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, claude.PromptSDKAnalysis, response.Data.Name)
+	assert.Equal(t, "embedded", response.Data.Source)
+	assert.Contains(t, response.Data.Template, "{{.SDKName}}")
+
+	w = get("summary")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	var errResponse ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResponse))
+	assert.Equal(t, "Unknown prompt template summary (available: incremental_analysis, sdk_analysis)", errResponse.Message)
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// idleBucketTTL is how long an unused client's buckets are kept.
+const idleBucketTTL = time.Hour
+
+// TokenBucket holds up to capacity tokens and refills them continuously at
+// refillRate tokens per second. It is safe for concurrent use.
+type TokenBucket struct {
+	mu         sync.Mutex
+	capacity   int
+	tokens     float64
+	refillRate float64
+	lastRefill time.Time
+}
+
+// NewTokenBucket returns a full bucket.
+func NewTokenBucket(capacity int, refillRate float64) *TokenBucket {
+	return newTokenBucketAt(capacity, refillRate, time.Now())
+}
+
+func newTokenBucketAt(capacity int, refillRate float64, now time.Time) *TokenBucket {
+	return &TokenBucket{
+		capacity:   capacity,
+		tokens:     float64(capacity),
+		refillRate: refillRate,
+		lastRefill: now,
+	}
+}
+
+// Allow refills the bucket for the time elapsed since the last call and
+// takes cost tokens from it, reporting whether there were enough.
+func (b *TokenBucket) Allow(cost int) bool {
+	allowed, _ := b.allowAt(time.Now(), cost)
+	return allowed
+}
+
+// allowAt is Allow at now. When it refuses, it also returns how long until
+// the bucket holds cost tokens.
+func (b *TokenBucket) allowAt(now time.Time, cost int) (bool, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if wait := b.refillAt(now, cost); wait > 0 {
+		return false, wait
+	}
+	b.tokens -= float64(cost)
+	return true, 0
+}
+
+// waitAt refills the bucket like allowAt and returns how long until it
+// holds cost tokens, without taking any.
+func (b *TokenBucket) waitAt(now time.Time, cost int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.refillAt(now, cost)
+}
+
+// refillAt refills the bucket for the time elapsed until now and returns
+// how long until it holds cost tokens. b.mu must be held.
+func (b *TokenBucket) refillAt(now time.Time, cost int) time.Duration {
+	if elapsed := now.Sub(b.lastRefill); elapsed > 0 {
+		b.tokens = math.Min(float64(b.capacity), b.tokens+elapsed.Seconds()*b.refillRate)
+		b.lastRefill = now
+	}
+
+	need := float64(cost)
+	if b.tokens >= need {
+		return 0
+	}
+	if b.refillRate <= 0 || need > float64(b.capacity) {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration((need - b.tokens) / b.refillRate * float64(time.Second))
+}
+
+// idleSince reports whether b has been untouched since before cutoff.
+func (b *TokenBucket) idleSince(cutoff time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.lastRefill.Before(cutoff)
+}
+
+// RateLimitMiddleware limits requests per minute across all clients and per
+// client IP. Each limit's bucket holds a minute's worth of requests, so
+// short bursts up to the limit are allowed. Zero disables a limit. Requests
+// over a limit get a 429 with a Retry-After header.
+func RateLimitMiddleware(globalRPM, perIPRPM int) gin.HandlerFunc {
+	return newRateLimiter(globalRPM, perIPRPM, time.Now).handle
+}
+
+// rateLimiter holds the global bucket and one bucket per client IP.
+type rateLimiter struct {
+	global   *TokenBucket
+	perIPRPM int
+	now      func() time.Time
+
+	clients    sync.Map // client IP -> *TokenBucket
+	pruneMu    sync.Mutex
+	lastPruned time.Time
+}
+
+func newRateLimiter(globalRPM, perIPRPM int, now func() time.Time) *rateLimiter {
+	l := &rateLimiter{
+		perIPRPM:   perIPRPM,
+		now:        now,
+		lastPruned: now(),
+	}
+	if globalRPM > 0 {
+		l.global = newTokenBucketAt(globalRPM, float64(globalRPM)/60, now())
+	}
+	return l
+}
+
+func (l *rateLimiter) handle(c *gin.Context) {
+	now := l.now()
+	l.pruneIdle(now)
+
+	// The client's own bucket is charged first so one client cannot drain
+	// the global bucket once it is over its own limit
+	if l.perIPRPM > 0 {
+		bucket, ok := l.clients.Load(c.ClientIP())
+		if !ok {
+			bucket, _ = l.clients.LoadOrStore(c.ClientIP(), newTokenBucketAt(l.perIPRPM, float64(l.perIPRPM)/60, now))
+		}
+		if allowed, retryAfter := bucket.(*TokenBucket).allowAt(now, 1); !allowed {
+			abortRateLimited(c, retryAfter)
+			return
+		}
+	}
+
+	if l.global != nil {
+		if allowed, retryAfter := l.global.allowAt(now, 1); !allowed {
+			abortRateLimited(c, retryAfter)
+			return
+		}
+	}
+
+	c.Next()
+}
+
+// pruneIdle drops client buckets untouched for a minute. They have refilled
+// completely by then, so a new bucket behaves the same.
+func (l *rateLimiter) pruneIdle(now time.Time) {
+	l.pruneMu.Lock()
+	if now.Sub(l.lastPruned) < time.Minute {
+		l.pruneMu.Unlock()
+		return
+	}
+	l.lastPruned = now
+	l.pruneMu.Unlock()
+
+	cutoff := now.Add(-time.Minute)
+	l.clients.Range(func(ip, bucket any) bool {
+		if bucket.(*TokenBucket).idleSince(cutoff) {
+			l.clients.Delete(ip)
+		}
+		return true
+	})
+}
+
+// EndpointRateLimitMiddleware limits each client per route. Limits are keyed
+// by "METHOD:route" using the Gin route pattern, e.g.
+// "POST:/api/v1/cache/refresh"; routes without a limit are not throttled.
+// Requests over a limit get a 429 with a Retry-After header.
+func EndpointRateLimitMiddleware(limits map[string]config.RateLimit) gin.HandlerFunc {
+	return newEndpointRateLimiter(limits, time.Now).handle
+}
+
+// endpointRateLimiter holds one set of token buckets per route and client IP.
+type endpointRateLimiter struct {
+	limits map[string]config.RateLimit
+	now    func() time.Time
+
+	mu         sync.Mutex
+	clients    map[string]*clientBuckets
+	lastPruned time.Time
+}
+
+// clientBuckets enforce a RateLimit for one route and client.
+type clientBuckets struct {
+	buckets  []*TokenBucket
+	lastSeen time.Time
+}
+
+func newEndpointRateLimiter(limits map[string]config.RateLimit, now func() time.Time) *endpointRateLimiter {
+	return &endpointRateLimiter{
+		limits:     limits,
+		now:        now,
+		clients:    make(map[string]*clientBuckets),
+		lastPruned: now(),
+	}
+}
+
+func (l *endpointRateLimiter) handle(c *gin.Context) {
+	route := c.Request.Method + ":" + c.FullPath()
+	limit, ok := l.limits[route]
+	if !ok {
+		c.Next()
+		return
+	}
+
+	allowed, retryAfter := l.allow(route+"|"+c.ClientIP(), limit)
+	if !allowed {
+		abortRateLimited(c, retryAfter)
+		return
+	}
+
+	c.Next()
+}
+
+// abortRateLimited rejects a request with 429, telling the client to retry
+// after retryAfter.
+func abortRateLimited(c *gin.Context, retryAfter time.Duration) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	c.JSON(http.StatusTooManyRequests, ErrorResponse{
+		Error:     "rate_limited",
+		Message:   "Too many requests, retry after " + retryAfter.Round(time.Second).String(),
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+	c.Abort()
+}
+
+// allow takes a token from every bucket for key, or reports how long until
+// all of them have one.
+func (l *endpointRateLimiter) allow(key string, limit config.RateLimit) (bool, time.Duration) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruneIdle(now)
+
+	client, ok := l.clients[key]
+	if !ok {
+		client = &clientBuckets{buckets: newBuckets(limit, now)}
+		l.clients[key] = client
+	}
+	client.lastSeen = now
+
+	// Tokens are only taken once every bucket has one, so a request refused
+	// by one window does not use up the others
+	var wait time.Duration
+	for _, b := range client.buckets {
+		wait = max(wait, b.waitAt(now, 1))
+	}
+	if wait > 0 {
+		return false, wait
+	}
+
+	for _, b := range client.buckets {
+		b.allowAt(now, 1)
+	}
+	return true, 0
+}
+
+// pruneIdle drops clients that have not been seen for idleBucketTTL. Their
+// buckets would have refilled completely anyway.
+func (l *endpointRateLimiter) pruneIdle(now time.Time) {
+	if now.Sub(l.lastPruned) < time.Minute {
+		return
+	}
+	l.lastPruned = now
+
+	for key, client := range l.clients {
+		if now.Sub(client.lastSeen) > idleBucketTTL {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// newBuckets returns a full bucket for each window of limit that is set.
+func newBuckets(limit config.RateLimit, now time.Time) []*TokenBucket {
+	var buckets []*TokenBucket
+	for _, window := range []struct {
+		size   int
+		period time.Duration
+	}{
+		{limit.BurstSize, time.Second},
+		{limit.RPM, time.Minute},
+		{limit.RPH, time.Hour},
+	} {
+		if window.size > 0 {
+			// Each bucket holds size tokens and refills them evenly over period
+			buckets = append(buckets, newTokenBucketAt(window.size, float64(window.size)/window.period.Seconds(), now))
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// fakeClock is a manually advanced time source.
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) Advance(d time.Duration) { f.now = f.now.Add(d) }
+
+func setupRateLimitRouter(limits map[string]config.RateLimit, clock *fakeClock) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(newEndpointRateLimiter(limits, clock.Now).handle)
+	r.POST("/api/v1/cache/refresh", func(c *gin.Context) { c.Status(http.StatusAccepted) })
+	r.GET("/health", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.GET("/api/v1/cache/summary", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func doRequest(r *gin.Engine, method, path, clientIP string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, path, nil)
+	req.RemoteAddr = clientIP + ":12345"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestEndpointRateLimitRefresh(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupRateLimitRouter(config.DefaultEndpointRateLimits(), clock)
+
+	// Two per second stays within the burst size
+	for i := 0; i < 10; i++ {
+		w := doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1")
+		require.Equal(t, http.StatusAccepted, w.Code, "request %d", i+1)
+		clock.Advance(500 * time.Millisecond)
+	}
+
+	w := doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate_limited")
+
+	// Other clients have their own buckets
+	w = doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.2")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	// A token refills every six seconds
+	clock.Advance(time.Second)
+	w = doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1")
+	assert.Equal(t, http.StatusAccepted, w.Code)
+}
+
+func TestEndpointRateLimitHealth(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupRateLimitRouter(config.DefaultEndpointRateLimits(), clock)
+
+	for i := 0; i < 1000; i++ {
+		w := doRequest(r, "GET", "/health", "10.0.0.1")
+		require.Equal(t, http.StatusOK, w.Code, "request %d", i+1)
+		clock.Advance(50 * time.Millisecond)
+	}
+}
+
+func TestEndpointRateLimitBurst(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupRateLimitRouter(map[string]config.RateLimit{
+		"POST:/api/v1/cache/refresh": {RPM: 10, BurstSize: 2},
+	}, clock)
+
+	assert.Equal(t, http.StatusAccepted, doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusAccepted, doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(r, "POST", "/api/v1/cache/refresh", "10.0.0.1").Code)
+}
+
+func TestEndpointRateLimitHourly(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupRateLimitRouter(map[string]config.RateLimit{
+		"GET:/api/v1/cache/summary": {RPM: 10, RPH: 15},
+	}, clock)
+
+	for i := 0; i < 15; i++ {
+		require.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code, "request %d", i+1)
+		clock.Advance(10 * time.Second)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code)
+}
+
+func TestEndpointRateLimitUnlimitedRoutes(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupRateLimitRouter(map[string]config.RateLimit{
+		"POST:/api/v1/cache/refresh": {RPM: 1},
+	}, clock)
+
+	for i := 0; i < 50; i++ {
+		require.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code)
+	}
+}
+
+func TestEndpointRateLimitPrunesIdleClients(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	limiter := newEndpointRateLimiter(map[string]config.RateLimit{}, clock.Now)
+
+	limit := config.RateLimit{RPM: 1}
+	allowed, _ := limiter.allow("GET:/health|10.0.0.1", limit)
+	require.True(t, allowed)
+
+	clock.Advance(2 * time.Hour)
+	allowed, _ = limiter.allow("GET:/health|10.0.0.2", limit)
+	require.True(t, allowed)
+
+	assert.Len(t, limiter.clients, 1)
+}
+
+func TestTokenBucketRefill(t *testing.T) {
+	start := time.Now()
+	bucket := newTokenBucketAt(3, 1, start)
+
+	allowed, _ := bucket.allowAt(start, 2)
+	require.True(t, allowed)
+	allowed, wait := bucket.allowAt(start, 2)
+	require.False(t, allowed)
+	assert.Equal(t, time.Second, wait)
+
+	// Refills never exceed capacity
+	allowed, _ = bucket.allowAt(start.Add(time.Hour), 3)
+	assert.True(t, allowed)
+	allowed, _ = bucket.allowAt(start.Add(time.Hour), 1)
+	assert.False(t, allowed)
+}
+
+func TestTokenBucketConcurrentAllow(t *testing.T) {
+	// Without refills exactly capacity calls succeed however they interleave
+	bucket := NewTokenBucket(100, 0)
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if bucket.Allow(1) {
+					allowed.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(100), allowed.Load())
+}
+
+func setupGlobalRateLimitRouter(globalRPM, perIPRPM int, now func() time.Time) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(newRateLimiter(globalRPM, perIPRPM, now).handle)
+	r.GET("/api/v1/cache/summary", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestRateLimitPerIP(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupGlobalRateLimitRouter(0, 60, clock.Now)
+
+	for i := 0; i < 60; i++ {
+		require.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code, "request %d", i+1)
+	}
+
+	w := doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "rate_limited")
+
+	// Other clients have their own buckets
+	assert.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.2").Code)
+
+	// One token refills every second
+	clock.Advance(time.Second)
+	assert.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code)
+}
+
+func TestRateLimitGlobal(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	r := setupGlobalRateLimitRouter(6, 0, clock.Now)
+
+	for i := 0; i < 6; i++ {
+		ip := fmt.Sprintf("10.0.0.%d", i)
+		require.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", ip).Code, "request %d", i+1)
+	}
+
+	w := doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.99")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "10", w.Header().Get("Retry-After"))
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	r := setupGlobalRateLimitRouter(0, 0, time.Now)
+
+	for i := 0; i < 100; i++ {
+		require.Equal(t, http.StatusOK, doRequest(r, "GET", "/api/v1/cache/summary", "10.0.0.1").Code)
+	}
+}
+
+func TestRateLimitConcurrentClients(t *testing.T) {
+	// Refills during the test add at most a few requests per client
+	const clients, perClient, rpm = 20, 50, 30
+	r := setupGlobalRateLimitRouter(0, rpm, time.Now)
+
+	var ok [clients]atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		for j := 0; j < perClient; j++ {
+			wg.Add(1)
+			go func(client int) {
+				defer wg.Done()
+				if doRequest(r, "GET", "/api/v1/cache/summary", fmt.Sprintf("10.0.1.%d", client)).Code == http.StatusOK {
+					ok[client].Add(1)
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	for i := range ok {
+		assert.GreaterOrEqual(t, ok[i].Load(), int64(rpm), "client %d", i)
+		assert.LessOrEqual(t, ok[i].Load(), int64(rpm+2), "client %d", i)
+	}
+}
+
+func TestRateLimitPrunesIdleClients(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	limiter := newRateLimiter(0, 60, clock.Now)
+	limiter.clients.Store("10.0.0.1", newTokenBucketAt(60, 1, clock.Now().Add(-2*time.Minute)))
+	limiter.clients.Store("10.0.0.2", newTokenBucketAt(60, 1, clock.Now()))
+
+	clock.Advance(time.Minute)
+	limiter.pruneIdle(clock.Now())
+
+	_, ok := limiter.clients.Load("10.0.0.1")
+	assert.False(t, ok)
+	_, ok = limiter.clients.Load("10.0.0.2")
+	assert.True(t, ok)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// handleGetSDKCacheRaw serves the cached analysis of an SDK as the JSON
+// document itself rather than a string inside SuccessResponse. Responses
+// carry an ETag of the SHA-256 of the entry and, once the SDK has been
+// analyzed, a Last-Modified header, and conditional requests that match
+// either get 304 Not Modified.
+func (s *Server) handleGetSDKCacheRaw(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	value, err := s.cache.GetContext(c.Request.Context(), "sdk:"+sdkName)
+	if err != nil {
+		s.respondCacheError(c, err, "SDK cache not found")
+		return
+	}
+
+	var document json.RawMessage
+	if err := json.Unmarshal([]byte(value), &document); err != nil {
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Cached SDK analysis is not valid JSON")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "invalid_cache_entry",
+			Message:   "Cached SDK analysis is not valid JSON",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	c.Header("ETag", etag)
+
+	lastModified, hasLastModified := s.sdkLastAnalyzed(sdkName)
+	if hasLastModified {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(c.Request, etag, lastModified, hasLastModified) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", []byte(value))
+}
+
+// sdkLastAnalyzed returns when an SDK was last analyzed, if known.
+func (s *Server) sdkLastAnalyzed(sdkName string) (time.Time, bool) {
+	value, err := s.cache.Get("sdk:" + sdkName + ":last_analyzed")
+	if err != nil {
+		return time.Time{}, false
+	}
+	analyzedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		s.logger.Debug().Err(err).Str("sdk", sdkName).Msg("Invalid last analyzed timestamp")
+		return time.Time{}, false
+	}
+	return analyzedAt, true
+}
+
+// notModified evaluates the conditional headers of r as RFC 9110 does for
+// GET: If-None-Match, when present, takes precedence over
+// If-Modified-Since.
+func notModified(r *http.Request, etag string, lastModified time.Time, hasLastModified bool) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for _, candidate := range strings.Split(header, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if !hasLastModified {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	// HTTP dates have one-second precision
+	return !lastModified.Truncate(time.Second).After(since)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetSDKCacheRaw(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	analyzedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", `{"language":"go","features":["breadcrumbs"]}`, time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", analyzedAt.Format(time.RFC3339), time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-broken", `{"language":`, time.Hour))
+
+	get := func(name string, headers map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/"+name+"/raw", nil)
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("sentry-go", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, analyzedAt.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{64}"$`, etag)
+
+	var analysis struct {
+		Language string   `json:"language"`
+		Features []string `json:"features"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &analysis))
+	assert.Equal(t, "go", analysis.Language)
+	assert.Equal(t, []string{"breadcrumbs"}, analysis.Features)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected int
+	}{
+		{name: "matching etag", headers: map[string]string{"If-None-Match": etag}, expected: http.StatusNotModified},
+		{name: "weak etag in list", headers: map[string]string{"If-None-Match": `"other", W/` + etag}, expected: http.StatusNotModified},
+		{name: "stale etag", headers: map[string]string{"If-None-Match": `"other"`}, expected: http.StatusOK},
+		{name: "not modified since", headers: map[string]string{"If-Modified-Since": analyzedAt.Format(http.TimeFormat)}, expected: http.StatusNotModified},
+		{name: "modified since", headers: map[string]string{"If-Modified-Since": analyzedAt.Add(-time.Hour).Format(http.TimeFormat)}, expected: http.StatusOK},
+		{
+			name: "etag takes precedence",
+			headers: map[string]string{
+				"If-None-Match":     `"other"`,
+				"If-Modified-Since": analyzedAt.Format(http.TimeFormat),
+			},
+			expected: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := get("sentry-go", tt.headers)
+			assert.Equal(t, tt.expected, w.Code)
+			assert.Equal(t, etag, w.Header().Get("ETag"))
+			if tt.expected == http.StatusNotModified {
+				assert.Empty(t, w.Body.Bytes())
+			}
+		})
+	}
+
+	t.Run("changed entry", func(t *testing.T) {
+		require.NoError(t, cacheManager.Set("sdk:sentry-go", `{"language":"go","features":[]}`, time.Hour))
+
+		w := get("sentry-go", map[string]string{"If-None-Match": etag})
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, get("sentry-missing", nil).Code)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, get("sentry-broken", nil).Code)
+	})
+}
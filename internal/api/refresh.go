@@ -0,0 +1,104 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// handleRefreshCache queues a cache refresh. An empty body requests a full
+// refresh.
+func (s *Server) handleRefreshCache(c *gin.Context) {
+	var req worker.RefreshRequest
+	if c.Request.Body == nil {
+		c.Request.Body = http.NoBody
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"type\": \"full|incremental|specific\", \"targets\": [...], \"force\": bool, \"force_sdk\": [...]}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	job, position, err := s.worker.EnqueueRefresh(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, worker.ErrInvalidRefresh), errors.Is(err, worker.ErrUnknownSDK):
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "invalid_request",
+				Message:   err.Error(),
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		case errors.Is(err, worker.ErrDraining):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Worker is shutting down",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		case errors.Is(err, worker.ErrQueueFull):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Refresh queue is full, try again later",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		default:
+			s.logger.Error().Err(err).Msg("Failed to queue cache refresh")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "internal_error",
+				Message:   "Failed to queue cache refresh",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Data: gin.H{
+			"job_id":    job.ID,
+			"type":      job.Type,
+			"targets":   job.Targets,
+			"force":     job.Force,
+			"force_sdk": job.ForceSDK,
+			"position":  position,
+		},
+		Message:   "Cache refresh initiated",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handleRefreshStatus(c *gin.Context) {
+	job, ok := s.worker.Jobs().Get(c.Param("job_id"))
+	if !ok || job.Progress().Kind != worker.JobKindRefresh {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Refresh job not found",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      job.Progress(),
+		Message:   "Refresh job status retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+func (s *Server) handleGetRetention(c *gin.Context) {
+	data := gin.H{
+		"enabled":    s.worker.AnalyticsStore() != nil,
+		"policy":     s.config.Retention,
+		"last_prune": nil,
+	}
+	if store := s.worker.AnalyticsStore(); store != nil {
+		if last := store.LastPrune(); last != nil {
+			data["last_prune"] = last
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      data,
+		Message:   "Analytics retention retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handlePruneAnalytics(c *gin.Context) {
+	result, err := s.worker.PruneAnalytics(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, worker.ErrAnalyticsDisabled) {
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "analytics_disabled",
+				Message:   "Analytics is disabled",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		s.logger.Error().Err(err).Msg("Failed to prune analytics")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to prune analytics",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      result,
+		Message:   "Analytics pruned successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
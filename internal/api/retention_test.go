@@ -0,0 +1,96 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func TestGetRetentionEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	server.config.Retention = config.RetentionPolicy{TokenEventDays: 90, CacheEventDays: 30, AuditLogDays: 365}
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/analytics/retention", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Enabled   bool                   `json:"enabled"`
+			Policy    config.RetentionPolicy `json:"policy"`
+			LastPrune *analytics.PruneResult `json:"last_prune"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Enabled)
+	assert.Equal(t, server.config.Retention, response.Data.Policy)
+	assert.Nil(t, response.Data.LastPrune)
+}
+
+func TestPruneAnalyticsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	server.config.Retention = config.RetentionPolicy{TokenEventDays: 30}
+
+	prune := func(authHeader string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/admin/analytics/prune", nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Admin only
+	w := prune("")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Analytics disabled
+	w = prune("Bearer " + testAdminKey)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	store, err := analytics.Open(filepath.Join(t.TempDir(), "analytics.db"), zerolog.Nop())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	server.worker.SetAnalyticsStore(store)
+
+	require.NoError(t, store.RecordTokenUsage(analytics.TokenEvent{SDK: "sentry-go", Tokens: 10, Timestamp: time.Now().AddDate(0, 0, -45)}))
+	require.NoError(t, store.RecordTokenUsage(analytics.TokenEvent{SDK: "sentry-go", Tokens: 10}))
+
+	w = prune("Bearer " + testAdminKey)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data analytics.PruneResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 1, response.Data.TokenEventsDeleted)
+
+	// The retention endpoint now reports the last prune
+	req, _ := http.NewRequest("GET", "/api/v1/admin/analytics/retention", nil)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Contains(t, w.Body.String(), `"token_events_deleted":1`)
+}
@@ -0,0 +1,385 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// toggleSDKRequest is the body of PUT /api/v1/sdk/:name/toggle.
+type toggleSDKRequest struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+// registerSDKRequest is the body of POST /api/v1/sdk/register. SDKs are
+// active unless active is false.
+type registerSDKRequest struct {
+	sdk.Config
+	Active *bool `json:"active"`
+}
+
+// handleListSDKs lists the configured SDKs with when each was last
+// analyzed, optionally filtered by the language and active query
+// parameters.
+func (s *Server) handleListSDKs(c *gin.Context) {
+	language := c.Query("language")
+	var active *bool
+	if raw := c.Query("active"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:     "invalid_request",
+				Message:   "active must be true or false",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+		active = &parsed
+	}
+
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to load SDK configurations")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to load SDK configurations",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	var matched []sdk.Config
+	for _, cfg := range configs.SDKs {
+		if language != "" && !strings.EqualFold(cfg.Language, language) {
+			continue
+		}
+		if active != nil && cfg.Active != *active {
+			continue
+		}
+		matched = append(matched, cfg)
+	}
+
+	keys := make([]string, len(matched))
+	for i, cfg := range matched {
+		keys[i] = fmt.Sprintf("sdk:%s:last_analyzed", cfg.Name)
+	}
+	lastAnalyzed, errs := s.cache.GetMulti(keys)
+	for _, err := range errs {
+		// Missing keys are expected for SDKs that were never analyzed
+		if !errors.Is(err, cache.ErrNotFound) {
+			s.logger.Warn().Err(err).Msg("Failed to read SDK last analyzed time")
+		}
+	}
+
+	sdks := make([]gin.H, len(matched))
+	for i, cfg := range matched {
+		var analyzedAt *time.Time
+		if value, ok := lastAnalyzed[keys[i]]; ok {
+			if t, err := time.Parse(time.RFC3339, value); err == nil {
+				analyzedAt = &t
+			}
+		}
+		sdks[i] = gin.H{
+			"name":          cfg.Name,
+			"url":           cfg.URL,
+			"language":      cfg.Language,
+			"active":        cfg.Active,
+			"last_analyzed": analyzedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdks":  sdks,
+			"total": len(sdks),
+		},
+		Message:   "SDKs retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleToggleSDK enables or disables an SDK until the service restarts.
+// Disabled SDKs are left out of scheduled and full updates.
+func (s *Server) handleToggleSDK(c *gin.Context) {
+	name := c.Param("name")
+
+	var req toggleSDKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"active\": true|false}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	previous, err := sdk.SetActive(name, *req.Active)
+	if errors.Is(err, sdk.ErrUnknownSDK) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("sdk", name).Msg("Failed to toggle SDK")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to toggle SDK",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	// Remove the repository of a disabled SDK without waiting for it
+	if !*req.Active {
+		s.worker.RequestRepoCleanup()
+	}
+	// Start or stop analyzing the SDK on its own schedule
+	s.worker.RequestSDKReschedule()
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Str("sdk", name).
+		Bool("previous", previous).
+		Bool("active", *req.Active).
+		Msg("SDK toggled")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"name":     name,
+			"active":   *req.Active,
+			"previous": previous,
+		},
+		Message:   "SDK updated successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleCleanSDKRepo removes the cloned repository of an inactive SDK now,
+// rather than waiting for the background cleanup.
+func (s *Server) handleCleanSDKRepo(c *gin.Context) {
+	name := c.Param("name")
+
+	removed, err := s.worker.CleanRepo(name)
+	if errors.Is(err, worker.ErrUnknownSDK) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if errors.Is(err, worker.ErrSDKActive) {
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     "conflict",
+			Message:   "SDK " + name + " is active; disable it before cleaning its repository",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("sdk", name).Msg("Failed to clean SDK repository")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to clean SDK repository",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Str("sdk", name).
+		Bool("removed", removed).
+		Msg("SDK repository cleaned on request")
+
+	message := "SDK repository removed successfully"
+	if !removed {
+		message = "SDK has no cloned repository"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"name":    name,
+			"removed": removed,
+		},
+		Message:   message,
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleEstimateSDKCost estimates the tokens and price of analyzing an SDK
+// at its current HEAD without analyzing it.
+func (s *Server) handleEstimateSDKCost(c *gin.Context) {
+	name := c.Param("name")
+
+	estimate, err := s.worker.EstimateSDKCost(c.Request.Context(), name)
+	if errors.Is(err, worker.ErrUnknownSDK) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if errors.Is(err, worker.ErrSDKAnalyzerUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     "unavailable",
+			Message:   "SDK analyzer is not available",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("sdk", name).Msg("Failed to estimate SDK analysis cost")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to estimate SDK analysis cost",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      estimate,
+		Message:   "SDK analysis cost estimated successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleRegisterSDK adds an SDK to the registry. Registrations are kept in
+// CacheDir/sdk_registry.json, so they survive restarts.
+func (s *Server) handleRegisterSDK(c *gin.Context) {
+	var req registerSDKRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be an SDK configuration: {\"name\", \"url\", \"language\", \"patterns\": [...], ...}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	cfg := req.Config
+	cfg.Active = req.Active == nil || *req.Active
+
+	err := sdk.DefaultRegistry().Register(cfg)
+	switch {
+	case errors.Is(err, sdk.ErrInvalidConfig):
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case errors.Is(err, sdk.ErrSDKExists):
+		c.JSON(http.StatusConflict, ErrorResponse{
+			Error:     "conflict",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	case err != nil:
+		s.logger.Error().Err(err).Str("sdk", cfg.Name).Msg("Failed to register SDK")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to register SDK",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	// Analyze the SDK on its own schedule, if it has one
+	s.worker.RequestSDKReschedule()
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Str("sdk", cfg.Name).
+		Str("url", cfg.URL).
+		Msg("SDK registered")
+
+	c.JSON(http.StatusCreated, SuccessResponse{
+		Data:      cfg,
+		Message:   "SDK registered successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleUnregisterSDK removes an SDK from the registry, whether it was
+// registered at runtime or embedded. Its cached analyses are kept; its
+// repository is removed in the background.
+func (s *Server) handleUnregisterSDK(c *gin.Context) {
+	name := c.Param("name")
+
+	err := sdk.DefaultRegistry().Unregister(name)
+	var notFound *apperrors.SDKNotFoundError
+	if errors.As(err, &notFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Unknown SDK: " + notFound.Name,
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("sdk", name).Msg("Failed to unregister SDK")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to unregister SDK",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.worker.RequestRepoCleanup()
+	s.worker.RequestSDKReschedule()
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Str("sdk", name).
+		Msg("SDK unregistered")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      gin.H{"unregistered": name},
+		Message:   "SDK unregistered successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
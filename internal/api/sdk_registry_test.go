@@ -0,0 +1,277 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+type sdkListResponse struct {
+	Data struct {
+		SDKs []struct {
+			Name         string     `json:"name"`
+			Language     string     `json:"language"`
+			Active       bool       `json:"active"`
+			LastAnalyzed *time.Time `json:"last_analyzed"`
+		} `json:"sdks"`
+		Total int `json:"total"`
+	} `json:"data"`
+}
+
+func listSDKs(t *testing.T, server *Server, query string) (int, sdkListResponse) {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", "/api/v1/sdk/list"+query, nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	var response sdkListResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestListSDKs(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+
+	analyzedAt := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", analyzedAt.Format(time.RFC3339), 0))
+
+	code, response := listSDKs(t, server, "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, len(configs.SDKs), response.Data.Total)
+
+	code, response = listSDKs(t, server, "?language=go")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Data.SDKs, 1)
+	assert.Equal(t, "sentry-go", response.Data.SDKs[0].Name)
+	require.NotNil(t, response.Data.SDKs[0].LastAnalyzed)
+	assert.True(t, analyzedAt.Equal(*response.Data.SDKs[0].LastAnalyzed))
+
+	code, response = listSDKs(t, server, "?active=true")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, len(configs.GetActiveSDKs()), response.Data.Total)
+	for _, s := range response.Data.SDKs {
+		assert.True(t, s.Active, s.Name)
+	}
+
+	code, response = listSDKs(t, server, "?active=false&language=go")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Data.SDKs)
+
+	code, _ = listSDKs(t, server, "?active=sometimes")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestToggleSDK(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	t.Cleanup(func() {
+		_, err := sdk.SetActive("sentry-go", true)
+		require.NoError(t, err)
+	})
+
+	toggle := func(name, body, auth string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PUT", "/api/v1/sdk/"+name+"/toggle", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, toggle("sentry-go", `{"active":false}`, testAPIKeys[0]).Code)
+	assert.Equal(t, http.StatusBadRequest, toggle("sentry-go", `{}`, testAdminKey).Code)
+	assert.Equal(t, http.StatusNotFound, toggle("sentry-cobol", `{"active":false}`, testAdminKey).Code)
+
+	w := toggle("sentry-go", `{"active":false}`, testAdminKey)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Active   bool `json:"active"`
+			Previous bool `json:"previous"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Active)
+	assert.True(t, response.Data.Previous)
+
+	// The override persists across calls
+	_, list := listSDKs(t, server, "?language=go")
+	require.Len(t, list.Data.SDKs, 1)
+	assert.False(t, list.Data.SDKs[0].Active)
+
+	_, list = listSDKs(t, server, "?active=true")
+	for _, s := range list.Data.SDKs {
+		assert.NotEqual(t, "sentry-go", s.Name)
+	}
+
+	w = toggle("sentry-go", `{"active":true}`, testAdminKey)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Previous)
+
+	_, list = listSDKs(t, server, "?language=go")
+	assert.True(t, list.Data.SDKs[0].Active)
+}
+
+func TestCleanSDKRepo(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	conn := dialUpdates(t, ts)
+	defer conn.Close()
+	waitForClients(t, cacheManager, "probe", conn)
+
+	repoPath := filepath.Join(server.config.CacheDir, "repos", "sentry-electron")
+	require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "dummy"), 0o755))
+
+	clean := func(name, auth string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/sdk/"+name+"/clean", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, clean("sentry-electron", testAPIKeys[0]).Code)
+	assert.Equal(t, http.StatusNotFound, clean("sentry-cobol", testAdminKey).Code)
+	assert.Equal(t, http.StatusConflict, clean("sentry-go", testAdminKey).Code)
+	assert.DirExists(t, repoPath)
+
+	w := clean("sentry-electron", testAdminKey)
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Data struct {
+			Removed bool `json:"removed"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Data.Removed)
+	assert.NoDirExists(t, repoPath)
+	assert.Equal(t, int64(1), server.worker.Metrics().RepoCleaned)
+
+	for {
+		event := readEvent(t, conn)
+		if event.Key == "probe" {
+			continue
+		}
+		assert.Equal(t, EventRepoCleaned, event.Type)
+		assert.Equal(t, "repo:sentry-electron", event.Key)
+		break
+	}
+
+	w = clean("sentry-electron", testAdminKey)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.False(t, response.Data.Removed)
+}
+
+func TestEstimateSDKCostEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	estimate := func(name, auth string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/sdk/"+name+"/estimate-cost", nil)
+		req.Header.Set("Authorization", "Bearer "+auth)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, estimate("sentry-go", testAPIKeys[0]).Code)
+	assert.Equal(t, http.StatusNotFound, estimate("sentry-cobol", testAdminKey).Code)
+}
+
+func TestRegisterAndUnregisterSDK(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	previous := sdk.DefaultRegistry()
+	t.Cleanup(func() { sdk.SetDefaultRegistry(previous) })
+	path := filepath.Join(t.TempDir(), "sdk_registry.json")
+	registry, err := sdk.OpenRegistry(path)
+	require.NoError(t, err)
+	sdk.SetDefaultRegistry(registry)
+
+	send := func(method, path, body, auth string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	crystal := `{"name":"sentry-crystal","url":"https://github.com/getsentry/sentry-crystal","language":"crystal","patterns":["src/**/*.cr"]}`
+
+	assert.Equal(t, http.StatusUnauthorized, send("POST", "/api/v1/sdk/register", crystal, testAPIKeys[0]).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v1/sdk/register", `{"name":"sentry-crystal"`, testAdminKey).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/api/v1/sdk/register",
+		`{"name":"sentry-crystal","url":"not a url","language":"crystal","patterns":["src/**/*.cr"]}`, testAdminKey).Code)
+
+	w := send("POST", "/api/v1/sdk/register", crystal, testAdminKey)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var response struct {
+		Data sdk.Config `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "sentry-crystal", response.Data.Name)
+	assert.True(t, response.Data.Active)
+
+	assert.Equal(t, http.StatusConflict, send("POST", "/api/v1/sdk/register", crystal, testAdminKey).Code)
+
+	_, list := listSDKs(t, server, "?language=crystal")
+	require.Len(t, list.Data.SDKs, 1)
+	assert.Equal(t, "sentry-crystal", list.Data.SDKs[0].Name)
+
+	// The registration is on disk for the next start
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusUnauthorized, send("DELETE", "/api/v1/sdk/sentry-crystal", "", testAPIKeys[0]).Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v1/sdk/sentry-cobol", "", testAdminKey).Code)
+	assert.Equal(t, http.StatusOK, send("DELETE", "/api/v1/sdk/sentry-crystal", "", testAdminKey).Code)
+	assert.Equal(t, http.StatusNotFound, send("DELETE", "/api/v1/sdk/sentry-crystal", "", testAdminKey).Code)
+
+	_, list = listSDKs(t, server, "?language=crystal")
+	assert.Empty(t, list.Data.SDKs)
+}
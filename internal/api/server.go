@@ -2,24 +2,44 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/getkin/kin-openapi/openapi3"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/zerolog"
 
+	"github.com/ryanrussell/claude-cache-service/internal/audit"
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
 	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
 )
 
 // Server represents the API server.
 type Server struct {
 	config   *config.Config
 	cache    *cache.Manager
+	worker   *worker.UpdateWorker
 	logger   zerolog.Logger
 	router   *gin.Engine
+	http     *http.Server
+	redirect *http.Server // HTTP to HTTPS redirects; nil without Let's Encrypt
 	upgrader websocket.Upgrader
+	origins  originPolicy
+	openapi  *openapi3.T
+	metrics  *prometheus.Registry
+	hub      *Hub
+	audit    *audit.AuditLogger // nil when analytics is disabled
+
+	// Responses gzipped by compressionMiddleware and the bytes it saved
+	compressedResponses atomic.Int64
+	bytesSaved          atomic.Int64
 }
 
 // ErrorResponse represents an error response.
@@ -39,20 +59,55 @@ type SuccessResponse struct {
 }
 
 // NewServer creates a new API server.
-func NewServer(cfg *config.Config, cacheManager *cache.Manager, logger zerolog.Logger) *Server {
+func NewServer(cfg *config.Config, cacheManager *cache.Manager, updateWorker *worker.UpdateWorker, logger zerolog.Logger) *Server {
 	s := &Server{
-		config: cfg,
-		cache:  cacheManager,
-		logger: logger,
-		upgrader: websocket.Upgrader{
-			CheckOrigin: func(r *http.Request) bool {
-				// TODO: Implement proper CORS check for production
-				return true
-			},
-		},
+		config:  cfg,
+		cache:   cacheManager,
+		worker:  updateWorker,
+		logger:  logger,
+		openapi: GenerateOpenAPISpec(cfg.Version),
+		metrics: prometheus.NewRegistry(),
+		hub:     NewHub(logger),
+		origins: newOriginPolicy(cfg.AllowedOrigins, cfg.AllowCredentials),
+	}
+	s.upgrader = websocket.Upgrader{CheckOrigin: s.checkWebSocketOrigin}
+
+	if store := updateWorker.AnalyticsStore(); store != nil {
+		s.audit = audit.NewAuditLogger(store, logger)
+		cacheManager.SetAuditRecorder(s.audit)
+	}
+
+	updateWorker.OnRepoCleaned(func(event worker.RepoCleanedEvent) {
+		s.hub.Publish(cache.CacheEvent{
+			Type:      EventRepoCleaned,
+			Key:       "repo:" + event.Repo,
+			Timestamp: event.CleanedAt.Unix(),
+		})
+	})
+
+	if err := updateWorker.RegisterMetrics(s.metrics); err != nil {
+		logger.Error().Err(err).Msg("Failed to register worker metrics")
+	}
+
+	cacheMetrics := newCacheMetrics()
+	if err := cacheMetrics.register(s.metrics); err != nil {
+		logger.Error().Err(err).Msg("Failed to register cache metrics")
+	} else {
+		cacheManager.SetMetricsCollector(cacheMetrics)
+	}
+
+	// The subscription ends when the cache manager is closed, which stops
+	// the hub and disconnects its clients
+	events, err := cacheManager.Subscribe(context.Background(), nil)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to subscribe to cache events")
+	} else {
+		go s.hub.Run(events)
 	}
 
 	s.setupRouter()
+	s.http = &http.Server{Handler: s.router}
+	s.setupTLS()
 	return s
 }
 
@@ -68,32 +123,131 @@ func (s *Server) setupRouter() {
 
 	// Middleware
 	r.Use(s.requestIDMiddleware())
+	r.Use(s.tracingMiddleware())
 	r.Use(s.loggingMiddleware())
 	r.Use(s.recoveryMiddleware())
 	r.Use(s.corsMiddleware())
+	r.Use(s.compressionMiddleware())
+	r.Use(RateLimitMiddleware(s.config.GlobalRPM, s.config.PerIPRPM))
+	r.Use(EndpointRateLimitMiddleware(s.config.EndpointRateLimits))
+	r.Use(TimeoutMiddleware(s.config.EndpointTimeouts, s.config.DefaultEndpointTimeout, s.logger))
 
 	// Health check
 	r.GET("/health", s.handleHealth)
 
+	// Prometheus metrics
+	r.GET("/metrics", s.handlePrometheusMetrics)
+
 	// API v1 routes
-	v1 := r.Group("/api/v1")
+	v1 := r.Group("/api/v1", s.bodyLimitMiddleware(s.config.MaxRequestBodyBytes))
 	{
+		// Dependency checks
+		v1.GET("/health/deep", s.handleDeepHealth)
+		v1.GET("/health/claude", s.handleClaudeHealth)
+		v1.GET("/health/telemetry", s.handleTelemetryHealth)
+
 		// Cache operations
 		cache := v1.Group("/cache")
 		{
 			cache.GET("/summary", s.handleCacheSummary)
+			cache.GET("/keys", s.handleListCacheKeys)
+			cache.GET("/stats/histogram", s.handleHitCountHistogram)
+			cache.DELETE("/keys", s.auditMiddleware(audit.ActionDeleteKeys, auditQuery("prefix")), s.authMiddleware(), s.handleDeleteCacheKeys)
 			cache.GET("/project/:name", s.handleGetProjectCache)
 			cache.GET("/sdk/:name", s.handleGetSDKCache)
-			cache.POST("/refresh", s.handleRefreshCache)
-			cache.DELETE("/key/:key", s.handleDeleteCacheKey)
+			cache.PATCH("/sdk/:name", s.auditMiddleware(audit.ActionPatchSDK, auditParam("name")), s.authMiddleware(), s.handlePatchSDKCache)
+			cache.GET("/sdk/:name/raw", s.handleGetSDKCacheRaw)
+			cache.POST("/sdk/:name/analyze", s.auditMiddleware(audit.ActionAnalyzeSDK, auditParam("name")), s.adminMiddleware(), s.handleAnalyzeSDK)
+			cache.GET("/sdk/:name/versions", s.handleListSDKVersions)
+			cache.GET("/sdk/:name/diff", s.handleSDKDiff)
+			cache.GET("/sdk/:name/changelog", s.handleSDKChangelog)
+			cache.GET("/sdk/:name/token-savings", s.handleSDKTokenSavings)
+			cache.POST("/refresh", s.auditMiddleware(audit.ActionRefresh, nil), s.authMiddleware(), s.handleRefreshCache)
+			cache.GET("/refresh/:job_id", s.handleRefreshStatus)
+			cache.DELETE("/key/:key", s.auditMiddleware(audit.ActionDeleteKey, auditParam("key")), s.authMiddleware(), s.handleDeleteCacheKey)
+			cache.POST("/key/:key/touch", s.auditMiddleware(audit.ActionTouchKey, auditParam("key")), s.authMiddleware(), s.handleTouchCacheKey)
+			cache.POST("/warm", s.auditMiddleware(audit.ActionWarm, nil), s.adminMiddleware(), s.handleWarmCache)
+			cache.POST("/warmup", s.auditMiddleware(audit.ActionWarmup, nil), s.adminMiddleware(), s.handleWarmup)
+			cache.GET("/export", s.adminMiddleware(), s.handleExportCache)
+			cache.POST("/import", s.auditMiddleware(audit.ActionImport, nil), s.adminMiddleware(), s.handleImportCache)
+			cache.POST("/maintenance/shrink", s.auditMiddleware(audit.ActionCompact, nil), s.adminMiddleware(), s.handleCompactCache)
+		}
+
+		// SDK registry
+		sdkRegistry := v1.Group("/sdk")
+		{
+			sdkRegistry.GET("/list", s.handleListSDKs)
+			sdkRegistry.POST("/register", s.auditMiddleware(audit.ActionRegisterSDK, nil), s.adminMiddleware(), s.handleRegisterSDK)
+			sdkRegistry.DELETE("/:name", s.auditMiddleware(audit.ActionUnregisterSDK, auditParam("name")), s.adminMiddleware(), s.handleUnregisterSDK)
+			sdkRegistry.PUT("/:name/toggle", s.auditMiddleware(audit.ActionToggleSDK, auditParam("name")), s.adminMiddleware(), s.handleToggleSDK)
+			sdkRegistry.POST("/:name/clean", s.auditMiddleware(audit.ActionCleanRepo, auditParam("name")), s.adminMiddleware(), s.handleCleanSDKRepo)
+			sdkRegistry.POST("/:name/estimate-cost", s.adminMiddleware(), s.handleEstimateSDKCost)
+		}
+
+		// SDK reports
+		sdks := v1.Group("/sdks")
+		{
+			sdks.GET("/:name/compliance", s.handleSDKCompliance)
+			sdks.GET("/:name/compare", s.handleSDKCompare)
+		}
+
+		// Ad-hoc analysis
+		batch := v1.Group("/batch")
+		{
+			batch.POST("/analyze", s.auditMiddleware(audit.ActionBatchAnalyze, nil), s.adminMiddleware(), s.handleBatchAnalyze)
 		}
 
+		// Background jobs
+		v1.GET("/jobs/:job_id/progress", s.handleJobProgress)
+
 		// Analytics
 		analytics := v1.Group("/analytics")
 		{
 			analytics.GET("/usage", s.handleUsageAnalytics)
 			analytics.GET("/performance", s.handlePerformanceAnalytics)
 		}
+
+		// Update worker
+		worker := v1.Group("/worker")
+		{
+			worker.GET("/metrics", s.handleWorkerMetrics)
+			worker.GET("/status", s.handleWorkerStatus)
+			worker.GET("/pool-stats", s.handlePoolStats)
+			worker.POST("/reset-backoff", s.auditMiddleware(audit.ActionResetBackoff, nil), s.adminMiddleware(), s.handleResetBackoff)
+			worker.GET("/dead-letter", s.handleListDeadLetters)
+			worker.POST("/dead-letter/:sdk/retry", s.auditMiddleware(audit.ActionRetryAnalysis, auditParam("sdk")), s.adminMiddleware(), s.handleRetryDeadLetter)
+		}
+
+		// System maintenance
+		system := v1.Group("/system")
+		{
+			system.POST("/cache/compact", s.auditMiddleware(audit.ActionCompact, nil), s.adminMiddleware(), s.handleCompactCache)
+			system.POST("/git/prune", s.auditMiddleware(audit.ActionPruneRepos, nil), s.adminMiddleware(), s.handlePruneRepos)
+			system.GET("/config", s.adminMiddleware(), s.handleSystemConfig)
+		}
+
+		// Administration
+		admin := v1.Group("/admin")
+		{
+			admin.GET("/features", s.handleListFeatures)
+			admin.PATCH("/features/:name", s.auditMiddleware(audit.ActionSetFeature, auditParam("name")), s.adminMiddleware(), s.handleSetFeature)
+			admin.GET("/analytics/retention", s.handleGetRetention)
+			admin.POST("/analytics/prune", s.auditMiddleware(audit.ActionPruneAnalytics, nil), s.adminMiddleware(), s.handlePruneAnalytics)
+		}
+
+		// Analysis prompt templates
+		v1.GET("/prompts/:name", s.handleGetPrompt)
+
+		// Audit log
+		v1.GET("/audit", s.adminMiddleware(), s.handleListAuditEvents)
+
+		// Notifications
+		v1.POST("/notify/test", s.auditMiddleware(audit.ActionTestNotify, nil), s.adminMiddleware(), s.handleTestNotification)
+
+		// API documentation
+		v1.GET("/openapi.json", s.handleOpenAPIJSON)
+		v1.GET("/openapi.yaml", s.handleOpenAPIYAML)
+		v1.GET("/swagger-ui", s.handleSwaggerUI)
 	}
 
 	// WebSocket endpoints
@@ -103,14 +257,71 @@ func (s *Server) setupRouter() {
 	s.router = r
 }
 
-// Run starts the server.
-func (s *Server) Run(addr string) error {
-	return s.router.Run(addr)
+// Run serves requests until Shutdown is called, when it returns nil. With
+// TLSEnabled and a certificate it serves HTTPS on HTTPSPort, and for Let's
+// Encrypt also HTTP on HTTPPort; otherwise it serves plain HTTP on Port.
+func (s *Server) Run() error {
+	addr := ":" + s.config.Port
+	switch {
+	case s.usesTLS():
+		addr = ":" + s.config.HTTPSPort
+	case s.config.TLSEnabled:
+		s.logger.Warn().Msg("TLS is enabled without TLS_DOMAIN or TLS_CERT_FILE and TLS_KEY_FILE, serving plain HTTP")
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.logger.Info().Str("address", addr).Bool("tls", s.usesTLS()).Msg("Starting API server")
+
+	if s.redirect != nil {
+		go func() {
+			s.logger.Info().Str("address", s.redirect.Addr).Msg("Redirecting HTTP to HTTPS")
+			if err := s.redirect.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error().Err(err).Msg("HTTP redirect server failed, Let's Encrypt challenges cannot be answered")
+			}
+		}()
+	}
+
+	if s.usesTLS() {
+		return s.ServeTLS(listener)
+	}
+	return s.Serve(listener)
 }
 
-// Shutdown gracefully shuts down the server.
+// Serve serves requests on listener until Shutdown is called, when it
+// returns nil.
+func (s *Server) Serve(listener net.Listener) error {
+	if err := s.http.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown stops accepting connections, waits for in-flight requests to
+// finish and then for the update worker to drain. WebSocket connections
+// are not waited for; they close with the cache manager.
 func (s *Server) Shutdown(ctx context.Context) error {
-	// TODO: Implement graceful shutdown
+	if err := s.http.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to drain HTTP requests: %w", err)
+	}
+	if err := s.shutdownRedirect(ctx); err != nil {
+		return err
+	}
+
+	// Wait for the update worker to finish in-flight analyses
+	select {
+	case status := <-s.worker.DrainStatus():
+		s.logger.Info().
+			Int("completed", len(status.Completed)).
+			Int("abandoned", len(status.Abandoned)).
+			Bool("timed_out", status.TimedOut).
+			Msg("Update worker drained")
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for update worker to drain: %w", ctx.Err())
+	}
+
 	return nil
 }
 
@@ -134,22 +345,40 @@ func (s *Server) handleHealth(c *gin.Context) {
 func (s *Server) handleCacheSummary(c *gin.Context) {
 	stats := s.cache.GetStats()
 
+	var lastCompaction *time.Time
+	if !stats.LastShrinkAt.IsZero() {
+		lastCompaction = &stats.LastShrinkAt
+	}
+
+	repoSizes, err := s.worker.RepoDiskUsage()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to measure repository disk usage")
+	}
+
 	response := SuccessResponse{
 		Data: gin.H{
 			"statistics": gin.H{
-				"hits":       stats.Hits,
-				"misses":     stats.Misses,
-				"sets":       stats.Sets,
-				"deletes":    stats.Deletes,
-				"total_size": stats.TotalSize,
-				"item_count": stats.ItemCount,
-				"hit_rate":   calculateHitRate(stats.Hits, stats.Misses),
+				"hits":              stats.Hits,
+				"misses":            stats.Misses,
+				"sets":              stats.Sets,
+				"deletes":           stats.Deletes,
+				"touches":           stats.Touches,
+				"l1_hits":           stats.L1Hits,
+				"l2_hits":           stats.L2Hits,
+				"total_size":        stats.TotalSize,
+				"item_count":        stats.ItemCount,
+				"ejected_count":     stats.EjectedCount,
+				"compression_ratio": stats.CompressionRatio,
+				"hit_rate":          calculateHitRate(stats.Hits, stats.Misses),
+				"last_compaction":   lastCompaction,
+				"bytes_reclaimed":   stats.BytesReclaimed,
 			},
 			"configuration": gin.H{
 				"cache_dir": s.config.CacheDir,
 				"max_size":  s.config.MaxCacheSize,
 				"ttl":       s.config.CacheTTL.String(),
 			},
+			"repositories": repoSizes,
 		},
 		Message:   "Cache summary retrieved successfully",
 		RequestID: c.GetString("request_id"),
@@ -163,16 +392,10 @@ func (s *Server) handleGetProjectCache(c *gin.Context) {
 	projectName := c.Param("name")
 
 	cacheKey := "project:" + projectName
-	value, err := s.cache.Get(cacheKey)
+	value, err := s.cache.GetContext(c.Request.Context(), cacheKey)
 
 	if err != nil {
-		s.logger.Error().Err(err).Str("project", projectName).Msg("Failed to get project cache")
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:     "not_found",
-			Message:   "Project cache not found",
-			RequestID: c.GetString("request_id"),
-			Timestamp: time.Now().Unix(),
-		})
+		s.respondCacheError(c, err, "Project cache not found")
 		return
 	}
 
@@ -184,20 +407,22 @@ func (s *Server) handleGetProjectCache(c *gin.Context) {
 	})
 }
 
+// handleGetSDKCache returns the cached analysis of an SDK. With the
+// compare_to query parameter it instead returns the analysis and that of the
+// given version, decoded, with the diff from the version to the analysis.
 func (s *Server) handleGetSDKCache(c *gin.Context) {
 	sdkName := c.Param("name")
 
+	if version := c.Query("compare_to"); version != "" {
+		s.respondSDKComparison(c, sdkName, version)
+		return
+	}
+
 	cacheKey := "sdk:" + sdkName
-	value, err := s.cache.Get(cacheKey)
+	value, err := s.cache.GetContext(c.Request.Context(), cacheKey)
 
 	if err != nil {
-		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to get SDK cache")
-		c.JSON(http.StatusNotFound, ErrorResponse{
-			Error:     "not_found",
-			Message:   "SDK cache not found",
-			RequestID: c.GetString("request_id"),
-			Timestamp: time.Now().Unix(),
-		})
+		s.respondCacheError(c, err, "SDK cache not found")
 		return
 	}
 
@@ -209,16 +434,6 @@ func (s *Server) handleGetSDKCache(c *gin.Context) {
 	})
 }
 
-func (s *Server) handleRefreshCache(c *gin.Context) {
-	// TODO: Implement cache refresh logic
-	c.JSON(http.StatusAccepted, SuccessResponse{
-		Data:      gin.H{"status": "refresh_initiated"},
-		Message:   "Cache refresh initiated",
-		RequestID: c.GetString("request_id"),
-		Timestamp: time.Now().Unix(),
-	})
-}
-
 func (s *Server) handleDeleteCacheKey(c *gin.Context) {
 	key := c.Param("key")
 
@@ -241,40 +456,63 @@ func (s *Server) handleDeleteCacheKey(c *gin.Context) {
 	})
 }
 
-func (s *Server) handleUsageAnalytics(c *gin.Context) {
-	// TODO: Implement usage analytics
+func (s *Server) handlePerformanceAnalytics(c *gin.Context) {
+	stats := s.cache.GetStats()
+	gets := s.cache.GetLatency()
+	sets := s.cache.SetLatency()
+
 	c.JSON(http.StatusOK, SuccessResponse{
 		Data: gin.H{
-			"token_savings": gin.H{
-				"total":      1234567,
-				"percentage": 89.5,
+			"response_times": gin.H{
+				"p50":     durationMillis(gets.Percentile(50)),
+				"p95":     durationMillis(gets.Percentile(95)),
+				"p99":     durationMillis(gets.Percentile(99)),
+				"samples": gets.Count(),
 			},
-			"requests": gin.H{
-				"total":  10000,
-				"cached": 8500,
+			"cache_performance": gin.H{
+				"hit_rate":           calculateHitRate(stats.Hits, stats.Misses),
+				"avg_latency_ms":     durationMillis(gets.Mean()),
+				"avg_set_latency_ms": durationMillis(sets.Mean()),
 			},
+			"compression": s.CompressionStats(),
 		},
-		Message:   "Usage analytics retrieved successfully",
+		Message:   "Performance analytics retrieved successfully",
 		RequestID: c.GetString("request_id"),
 		Timestamp: time.Now().Unix(),
 	})
 }
 
-func (s *Server) handlePerformanceAnalytics(c *gin.Context) {
-	// TODO: Implement performance analytics
+// CompressionStats counts the responses gzipped by the server.
+type CompressionStats struct {
+	CompressedResponses int64 `json:"compressed_responses"`
+	BytesSaved          int64 `json:"bytes_saved"`
+}
+
+// CompressionStats returns how many responses were gzipped and how many
+// bytes that saved.
+func (s *Server) CompressionStats() CompressionStats {
+	return CompressionStats{
+		CompressedResponses: s.compressedResponses.Load(),
+		BytesSaved:          s.bytesSaved.Load(),
+	}
+}
+
+func (s *Server) handleResetBackoff(c *gin.Context) {
+	failures := s.worker.ConsecutiveFailures()
+	s.worker.ResetBackoff()
+
 	c.JSON(http.StatusOK, SuccessResponse{
-		Data: gin.H{
-			"response_times": gin.H{
-				"p50": 10,
-				"p95": 50,
-				"p99": 100,
-			},
-			"cache_performance": gin.H{
-				"hit_rate":       85.5,
-				"avg_latency_ms": 2.5,
-			},
-		},
-		Message:   "Performance analytics retrieved successfully",
+		Data:      gin.H{"previous_consecutive_failures": failures},
+		Message:   "Worker backoff reset successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handlePoolStats(c *gin.Context) {
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      gin.H{"tiers": s.worker.PoolStats()},
+		Message:   "Worker pool stats retrieved successfully",
 		RequestID: c.GetString("request_id"),
 		Timestamp: time.Now().Unix(),
 	})
@@ -286,14 +524,11 @@ func (s *Server) handleWebSocketUpdates(c *gin.Context) {
 		s.logger.Error().Err(err).Msg("Failed to upgrade WebSocket connection")
 		return
 	}
-	defer func() {
-		if err := conn.Close(); err != nil {
-			s.logger.Error().Err(err).Msg("Failed to close WebSocket connection")
-		}
-	}()
 
-	// TODO: Implement WebSocket updates
-	s.logger.Info().Str("remote", conn.RemoteAddr().String()).Msg("WebSocket connection established")
+	remote := conn.RemoteAddr().String()
+	s.logger.Info().Str("remote", remote).Msg("WebSocket connection established")
+	s.hub.Serve(conn, "")
+	s.logger.Info().Str("remote", remote).Msg("WebSocket connection closed")
 }
 
 func (s *Server) handleWebSocketProject(c *gin.Context) {
@@ -304,21 +539,26 @@ func (s *Server) handleWebSocketProject(c *gin.Context) {
 		s.logger.Error().Err(err).Msg("Failed to upgrade WebSocket connection")
 		return
 	}
-	defer func() {
-		if err := conn.Close(); err != nil {
-			s.logger.Error().Err(err).Msg("Failed to close WebSocket connection")
-		}
-	}()
 
-	// TODO: Implement project-specific WebSocket updates
+	remote := conn.RemoteAddr().String()
 	s.logger.Info().
-		Str("remote", conn.RemoteAddr().String()).
+		Str("remote", remote).
 		Str("project", projectName).
 		Msg("Project WebSocket connection established")
+	s.hub.Serve(conn, "project:"+projectName)
+	s.logger.Info().
+		Str("remote", remote).
+		Str("project", projectName).
+		Msg("Project WebSocket connection closed")
 }
 
 // Helper functions
 
+// durationMillis converts d to fractional milliseconds.
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
 func calculateHitRate(hits, misses int64) float64 {
 	total := hits + misses
 	if total == 0 {
@@ -2,8 +2,12 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
@@ -13,8 +17,14 @@ import (
 
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
 	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
 )
 
+const testAdminKey = "test-admin-key"
+
+// testAPIKeys authorize cache writes in test servers.
+var testAPIKeys = []string{"test-api-key", "test-api-key-2"}
+
 func setupTestServer(t *testing.T) (*Server, *cache.Manager) {
 	gin.SetMode(gin.TestMode)
 
@@ -22,15 +32,21 @@ func setupTestServer(t *testing.T) (*Server, *cache.Manager) {
 	logger := zerolog.New(zerolog.NewConsoleWriter()).Level(zerolog.Disabled)
 
 	cfg := &config.Config{
-		Port:    "8080",
-		Version: "test",
-		Debug:   false,
+		Port:                   "8080",
+		Version:                "test",
+		Debug:                  false,
+		CacheDir:               tempDir,
+		UpdateSchedule:         "0 2 * * 0",
+		MaxConsecutiveFailures: 3,
+		APIKeys:                testAPIKeys,
+		AdminAPIKeys:           []string{testAdminKey},
 	}
 
 	cacheManager, err := cache.NewManager(tempDir, logger)
 	require.NoError(t, err)
 
-	server := NewServer(cfg, cacheManager, logger)
+	updateWorker := worker.NewUpdateWorker(cacheManager, logger, cfg)
+	server := NewServer(cfg, cacheManager, updateWorker, logger)
 	return server, cacheManager
 }
 
@@ -81,6 +97,31 @@ func TestCacheSummaryEndpoint(t *testing.T) {
 	assert.NotNil(t, response.Data)
 }
 
+func TestCacheSummaryRepositories(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	repoPath := filepath.Join(server.config.CacheDir, "repos", "sentry-go")
+	require.NoError(t, os.MkdirAll(repoPath, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(repoPath, "client.go"), make([]byte, 128), 0o644))
+
+	req, _ := http.NewRequest("GET", "/api/v1/cache/summary", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Repositories map[string]int64 `json:"repositories"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, map[string]int64{"sentry-go": 128}, response.Data.Repositories)
+}
+
 func TestGetProjectCache(t *testing.T) {
 	server, cacheManager := setupTestServer(t)
 	defer func() {
@@ -156,6 +197,7 @@ func TestDeleteCacheKey(t *testing.T) {
 
 	// Delete the key
 	req, _ := http.NewRequest("DELETE", "/api/v1/cache/key/delete-me", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
 	w := httptest.NewRecorder()
 	server.router.ServeHTTP(w, req)
 
@@ -196,45 +238,263 @@ func TestRequestIDMiddleware(t *testing.T) {
 }
 
 func TestCORSMiddleware(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowedOrigins   []string
+		allowCredentials bool
+		origin           string
+		wantOrigin       string
+		wantCredentials  string
+	}{
+		{name: "no allowed origins", origin: "https://app.example.com"},
+		{name: "allowed origin", allowedOrigins: []string{"https://app.example.com"}, origin: "https://app.example.com", wantOrigin: "https://app.example.com"},
+		{name: "allowed origin ignoring case and trailing slash", allowedOrigins: []string{"https://App.example.com/"}, origin: "https://app.example.com", wantOrigin: "https://app.example.com"},
+		{name: "disallowed origin", allowedOrigins: []string{"https://app.example.com"}, origin: "https://evil.example.com"},
+		{name: "no origin header", allowedOrigins: []string{"https://app.example.com"}},
+		{name: "credentials", allowedOrigins: []string{"https://app.example.com"}, allowCredentials: true, origin: "https://app.example.com", wantOrigin: "https://app.example.com", wantCredentials: "true"},
+		{name: "credentials for disallowed origin", allowedOrigins: []string{"https://app.example.com"}, allowCredentials: true, origin: "https://evil.example.com"},
+		{name: "any origin", allowedOrigins: []string{"*"}, origin: "https://evil.example.com", wantOrigin: "*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, cacheManager := setupTestServer(t)
+			defer func() {
+				err := cacheManager.Close()
+				require.NoError(t, err)
+			}()
+			server.config.AllowedOrigins = tt.allowedOrigins
+			server.config.AllowCredentials = tt.allowCredentials
+			server = NewServer(server.config, cacheManager, server.worker, server.logger)
+
+			for _, method := range []string{"GET", "OPTIONS"} {
+				req, _ := http.NewRequest(method, "/health", nil)
+				if tt.origin != "" {
+					req.Header.Set("Origin", tt.origin)
+				}
+				w := httptest.NewRecorder()
+				server.router.ServeHTTP(w, req)
+
+				assert.Equal(t, tt.wantOrigin, w.Header().Get("Access-Control-Allow-Origin"), method)
+				assert.Equal(t, tt.wantCredentials, w.Header().Get("Access-Control-Allow-Credentials"), method)
+				assert.Contains(t, w.Header().Values("Vary"), "Origin", method)
+				if method == "OPTIONS" {
+					assert.Equal(t, http.StatusNoContent, w.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
 	server, cacheManager := setupTestServer(t)
 	defer func() {
 		err := cacheManager.Close()
 		require.NoError(t, err)
 	}()
 
-	// Test CORS headers
-	req, _ := http.NewRequest("GET", "/health", nil)
+	endpoints := []struct {
+		method string
+		path   string
+	}{
+		{method: "POST", path: "/api/v1/cache/refresh"},
+		{method: "DELETE", path: "/api/v1/cache/key/some-key"},
+		{method: "DELETE", path: "/api/v1/cache/keys?prefix=sdk:"},
+	}
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{name: "missing token", authHeader: "", expectedStatus: http.StatusUnauthorized, expectedError: "unauthorized"},
+		{name: "bare scheme", authHeader: "Bearer ", expectedStatus: http.StatusUnauthorized, expectedError: "unauthorized"},
+		{name: "missing scheme", authHeader: testAPIKeys[0], expectedStatus: http.StatusUnauthorized, expectedError: "unauthorized"},
+		{name: "invalid token", authHeader: "Bearer wrong-key", expectedStatus: http.StatusUnauthorized, expectedError: "invalid_token"},
+		{name: "first API key", authHeader: "Bearer " + testAPIKeys[0]},
+		{name: "second API key", authHeader: "Bearer " + testAPIKeys[1]},
+		{name: "admin key", authHeader: "Bearer " + testAdminKey},
+	}
+
+	for _, endpoint := range endpoints {
+		for _, tt := range tests {
+			t.Run(endpoint.method+" "+endpoint.path+"/"+tt.name, func(t *testing.T) {
+				req, _ := http.NewRequest(endpoint.method, endpoint.path, nil)
+				if tt.authHeader != "" {
+					req.Header.Set("Authorization", tt.authHeader)
+				}
+				w := httptest.NewRecorder()
+				server.router.ServeHTTP(w, req)
+
+				if tt.expectedStatus == 0 {
+					assert.NotEqual(t, http.StatusUnauthorized, w.Code)
+					return
+				}
+				require.Equal(t, tt.expectedStatus, w.Code)
+
+				var response ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+				assert.Equal(t, tt.expectedError, response.Error)
+				assert.NotEmpty(t, response.RequestID)
+			})
+		}
+	}
+
+	// API keys do not open admin endpoints
+	req, _ := http.NewRequest("POST", "/api/v1/system/cache/compact", nil)
+	req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
 	w := httptest.NewRecorder()
 	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
 
-	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
-	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+func TestRefreshCache(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
 
-	// Test OPTIONS request
-	req, _ = http.NewRequest("OPTIONS", "/health", nil)
-	w = httptest.NewRecorder()
-	server.router.ServeHTTP(w, req)
+	tests := []struct {
+		name            string
+		body            string
+		expectedStatus  int
+		expectedType    string
+		expectedMessage string
+	}{
+		{name: "empty body", body: "", expectedStatus: http.StatusAccepted, expectedType: "full"},
+		{name: "specific", body: `{"targets": ["sentry-go"], "force": true}`, expectedStatus: http.StatusAccepted, expectedType: "specific"},
+		{name: "incremental", body: `{"type": "incremental"}`, expectedStatus: http.StatusAccepted, expectedType: "incremental"},
+		{name: "unknown type", body: `{"type": "partial"}`, expectedStatus: http.StatusBadRequest, expectedMessage: `unknown type "partial", must be full, incremental or specific`},
+		{name: "malformed", body: `{"type":`, expectedStatus: http.StatusBadRequest},
+		{name: "unknown SDK", body: `{"targets": ["no-such-sdk"]}`, expectedStatus: http.StatusBadRequest, expectedMessage: "unknown SDK: no-such-sdk"},
+		{name: "unknown force_sdk", body: `{"force_sdk": ["no-such-sdk"]}`, expectedStatus: http.StatusBadRequest, expectedMessage: "unknown SDK: no-such-sdk"},
+	}
 
-	assert.Equal(t, http.StatusNoContent, w.Code)
+	position := 0
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/cache/refresh", strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+testAPIKeys[0])
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusAccepted {
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Equal(t, "invalid_request", errorResponse.Error)
+				assert.Contains(t, errorResponse.Message, tt.expectedMessage)
+				return
+			}
+			position++
+
+			var response struct {
+				Data struct {
+					JobID    string `json:"job_id"`
+					Type     string `json:"type"`
+					Position int    `json:"position"`
+				} `json:"data"`
+				Message string `json:"message"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "Cache refresh initiated", response.Message)
+			assert.Equal(t, tt.expectedType, response.Data.Type)
+			assert.Equal(t, position, response.Data.Position)
+
+			// The worker is not started, so the job stays queued
+			req, _ = http.NewRequest("GET", "/api/v1/cache/refresh/"+response.Data.JobID, nil)
+			w = httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var status struct {
+				Data worker.JobProgress `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+			assert.Equal(t, response.Data.JobID, status.Data.JobID)
+			assert.Equal(t, worker.JobQueued, status.Data.Status)
+		})
+	}
 }
 
-func TestRefreshCache(t *testing.T) {
+func TestRequestBodyLimit(t *testing.T) {
 	server, cacheManager := setupTestServer(t)
 	defer func() {
 		err := cacheManager.Close()
 		require.NoError(t, err)
 	}()
 
-	req, _ := http.NewRequest("POST", "/api/v1/cache/refresh", nil)
+	server.config.MaxRequestBodyBytes = 64
+	server = NewServer(server.config, cacheManager, server.worker, server.logger)
+
+	targets := make([]string, 20)
+	for i := range targets {
+		targets[i] = `"sentry-go"`
+	}
+	refreshBody := `{"type": "specific", "targets": [` + strings.Join(targets, ", ") + `]}`
+	record := `{"key": "sdk:sentry-go", "value": "` + strings.Repeat("x", 100) + `"}` + "\n"
+
+	tests := []struct {
+		name string
+		path string
+		body string
+	}{
+		{name: "refresh", path: "/api/v1/cache/refresh", body: refreshBody},
+		{name: "warm", path: "/api/v1/cache/warm", body: `{"sdks": [` + strings.Join(targets, ", ") + `]}`},
+		{name: "import", path: "/api/v1/cache/import", body: record},
+	}
+
+	for _, tt := range tests {
+		for _, chunked := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s chunked=%t", tt.name, chunked), func(t *testing.T) {
+				req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+				if chunked {
+					// Without a Content-Length the limit applies while reading
+					req.ContentLength = -1
+				}
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("Authorization", "Bearer "+testAdminKey)
+				w := httptest.NewRecorder()
+				server.router.ServeHTTP(w, req)
+
+				assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+
+				var errorResponse ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+				assert.Equal(t, "request_too_large", errorResponse.Error)
+			})
+		}
+	}
+
+	// Bodies within the limit are accepted
+	req, _ := http.NewRequest("POST", "/api/v1/cache/refresh", strings.NewReader(`{"targets": ["sentry-go"]}`))
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
 	w := httptest.NewRecorder()
 	server.router.ServeHTTP(w, req)
-
 	assert.Equal(t, http.StatusAccepted, w.Code)
 
-	var response SuccessResponse
-	err := json.Unmarshal(w.Body.Bytes(), &response)
-	require.NoError(t, err)
-	assert.Equal(t, "Cache refresh initiated", response.Message)
+	_, err := cacheManager.Get("sdk:sentry-go")
+	assert.Error(t, err, "oversized imports store nothing")
+}
+
+func TestRefreshStatusNotFound(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	// Jobs of other kinds are not refresh jobs
+	job := server.worker.Jobs().Start(worker.JobKindCacheWarm, 1)
+
+	for _, id := range []string{"missing", job.ID()} {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/refresh/"+id, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	}
 }
 
 func TestAnalyticsEndpoints(t *testing.T) {
@@ -258,3 +518,86 @@ func TestAnalyticsEndpoints(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code)
 }
+
+func TestResetBackoffEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+		{
+			name:           "invalid token",
+			authHeader:     "Bearer wrong-key",
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "invalid_token",
+		},
+		{
+			name:           "admin token",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/worker/reset-backoff", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			if tt.expectedError != "" {
+				var errorResponse ErrorResponse
+				err := json.Unmarshal(w.Body.Bytes(), &errorResponse)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedError, errorResponse.Error)
+				return
+			}
+
+			assert.Equal(t, 0, server.worker.ConsecutiveFailures())
+		})
+	}
+}
+
+func TestPoolStatsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/worker/pool-stats", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Tiers []worker.TierStats `json:"tiers"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Data.Tiers, 3)
+	assert.Equal(t, "high", response.Data.Tiers[0].Priority)
+	assert.Equal(t, "normal", response.Data.Tiers[1].Priority)
+	assert.Equal(t, "low", response.Data.Tiers[2].Priority)
+	for _, tier := range response.Data.Tiers {
+		assert.Positive(t, tier.Slots)
+	}
+}
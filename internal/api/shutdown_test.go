@@ -0,0 +1,80 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestShutdownDrainsInFlightRequests(t *testing.T) {
+	// BuntDB's background goroutine only notices Close on its next
+	// once-a-second tick
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent(),
+		goleak.IgnoreTopFunction("github.com/tidwall/buntdb.(*DB).backgroundManager"))
+
+	server, cacheManager := setupTestServer(t)
+	started := make(chan struct{})
+	server.router.GET("/test/slow", func(c *gin.Context) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+
+	served := make(chan error, 1)
+	go func() { served <- server.Serve(listener) }()
+
+	client := &http.Client{Transport: &http.Transport{}}
+	defer client.CloseIdleConnections()
+
+	type result struct {
+		status int
+		body   string
+		err    error
+	}
+	slow := make(chan result, 1)
+	go func() {
+		resp, err := client.Get(url + "/test/slow")
+		if err != nil {
+			slow <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- result{status: resp.StatusCode, body: string(body), err: err}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+
+	// Shutdown returned only after the slow request completed
+	select {
+	case res := <-slow:
+		require.NoError(t, res.err)
+		assert.Equal(t, http.StatusOK, res.status)
+		assert.Equal(t, "done", res.body)
+	default:
+		t.Fatal("Shutdown returned before the in-flight request completed")
+	}
+	require.NoError(t, <-served)
+
+	// New connections are refused
+	_, err = client.Get(url + "/health")
+	assert.Error(t, err)
+
+	client.CloseIdleConnections()
+	require.NoError(t, cacheManager.Close())
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func (s *Server) handleCompactCache(c *gin.Context) {
+	before, err := s.cache.FileSize()
+	if err == nil {
+		err = s.cache.ShrinkDB()
+	}
+	var after int64
+	if err == nil {
+		after, err = s.cache.FileSize()
+	}
+	if errors.Is(err, cache.ErrNotSupported) {
+		c.JSON(http.StatusNotImplemented, ErrorResponse{
+			Error:     "not_supported",
+			Message:   "Cache backend does not support compaction",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to compact cache database")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to compact cache database",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Int64("before_bytes", before).
+		Int64("after_bytes", after).
+		Msg("Cache database compacted on request")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"before_bytes":  before,
+			"after_bytes":   after,
+			"savings_bytes": before - after,
+		},
+		Message:   "Cache database compacted successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handlePruneRepos(c *gin.Context) {
+	removed, err := s.worker.PruneInactiveRepos(c.Request.Context())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to prune inactive repositories")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to prune inactive repositories",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if removed == nil {
+		removed = []string{}
+	}
+
+	s.logger.Info().
+		Str("request_id", c.GetString("request_id")).
+		Int("removed", len(removed)).
+		Msg("Inactive repositories pruned on request")
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      gin.H{"removed": removed},
+		Message:   "Inactive repositories pruned successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handleSystemConfig(c *gin.Context) {
+	activeSDKs := 0
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to load SDK configurations")
+	} else {
+		activeSDKs = len(configs.GetActiveSDKs())
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      s.config.Introspect(activeSDKs),
+		Message:   "Effective configuration retrieved",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
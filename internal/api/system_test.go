@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompactCacheEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name           string
+		authHeader     string
+		expectedStatus int
+	}{
+		{
+			name:           "missing token",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "wrong token",
+			authHeader:     "Bearer wrong-key",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "admin token",
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/system/cache/compact", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+
+	for _, path := range []string{"/api/v1/system/cache/compact", "/api/v1/cache/maintenance/shrink"} {
+		t.Run("reports savings at "+path, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				require.NoError(t, cacheManager.Set("compact-key", "value", 0))
+			}
+
+			req, _ := http.NewRequest("POST", path, nil)
+			req.Header.Set("Authorization", "Bearer "+testAdminKey)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Data struct {
+					BeforeBytes  int64 `json:"before_bytes"`
+					AfterBytes   int64 `json:"after_bytes"`
+					SavingsBytes int64 `json:"savings_bytes"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Positive(t, response.Data.BeforeBytes)
+			assert.Positive(t, response.Data.AfterBytes)
+			assert.Equal(t, response.Data.BeforeBytes-response.Data.AfterBytes, response.Data.SavingsBytes)
+			assert.Positive(t, response.Data.SavingsBytes)
+		})
+	}
+
+	t.Run("summary reports last compaction", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/summary", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response struct {
+			Data struct {
+				Statistics map[string]interface{} `json:"statistics"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotNil(t, response.Data.Statistics["last_compaction"])
+		assert.Positive(t, response.Data.Statistics["bytes_reclaimed"])
+	})
+}
+
+func TestPruneReposEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	reposDir := filepath.Join(server.config.CacheDir, "repos")
+	for _, repo := range []string{"sentry-go", "sentry-electron"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(reposDir, repo), 0o755))
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/system/git/prune", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.DirExists(t, filepath.Join(reposDir, "sentry-electron"))
+
+	req, _ = http.NewRequest("POST", "/api/v1/system/git/prune", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Data struct {
+			Removed []string `json:"removed"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{filepath.Join(reposDir, "sentry-electron")}, response.Data.Removed)
+	assert.DirExists(t, filepath.Join(reposDir, "sentry-go"))
+}
+
+func TestSystemConfigEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	server.config.ClaudeAPIKey = "sk-ant-secret-value"
+	server.config.RedisURL = "redis://:hunter2@cache-redis:6379/0"
+
+	req, _ := http.NewRequest("GET", "/api/v1/system/config", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/system/config", nil)
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	body := w.Body.String()
+	for _, secret := range []string{"sk-ant-secret-value", "hunter2", testAdminKey} {
+		assert.NotContains(t, body, secret)
+	}
+
+	var response struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	for _, field := range []string{
+		"config", "effective_cache_ttl", "active_sdk_count",
+		"disabled_feature_flags", "next_scheduled_update", "config_source",
+	} {
+		assert.Contains(t, response.Data, field)
+	}
+
+	var activeSDKs int
+	require.NoError(t, json.Unmarshal(response.Data["active_sdk_count"], &activeSDKs))
+	assert.Positive(t, activeSDKs)
+
+	var cfg map[string]any
+	require.NoError(t, json.Unmarshal(response.Data["config"], &cfg))
+	assert.Equal(t, "8080", cfg["Port"])
+	assert.Equal(t, []any{"********"}, cfg["AdminAPIKeys"])
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// TimeoutMiddleware bounds each request by the timeout configured for its
+// route pattern. Patterns are exact Gin routes ("/api/v1/cache/refresh") or
+// prefixes ending in "/*" ("/api/v1/cache/*"); the exact match wins, then
+// the longest prefix, then defaultTimeout. A timeout of zero disables the
+// limit, which WebSocket routes rely on.
+//
+// Handlers run synchronously under the deadline and must honour the request
+// context. Their response is buffered and replaced with a 504 if the deadline
+// passes before they return. Failures to send the buffered response are
+// logged to logger.
+func TimeoutMiddleware(timeouts map[string]time.Duration, defaultTimeout time.Duration, logger zerolog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := routeTimeout(timeouts, c.FullPath(), defaultTimeout)
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		buffered := newTimeoutWriter(original)
+		c.Writer = buffered
+
+		c.Next()
+
+		c.Writer = original
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.JSON(http.StatusGatewayTimeout, ErrorResponse{
+				Error:     "gateway_timeout",
+				Message:   "Request timed out after " + timeout.String(),
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+			return
+		}
+
+		if err := buffered.flush(); err != nil {
+			logger.Debug().
+				Err(err).
+				Str("request_id", c.GetString("request_id")).
+				Msg("Failed to write buffered response")
+		}
+	}
+}
+
+// routeTimeout returns the timeout configured for a Gin route pattern.
+func routeTimeout(timeouts map[string]time.Duration, route string, defaultTimeout time.Duration) time.Duration {
+	if timeout, ok := timeouts[route]; ok {
+		return timeout
+	}
+
+	best := -1
+	timeout := defaultTimeout
+	for pattern, t := range timeouts {
+		prefix, ok := strings.CutSuffix(pattern, "*")
+		if !ok || !strings.HasPrefix(route, prefix) {
+			continue
+		}
+		if len(prefix) > best {
+			best = len(prefix)
+			timeout = t
+		}
+	}
+	return timeout
+}
+
+// timeoutWriter buffers a response so it can be discarded on timeout.
+type timeoutWriter struct {
+	gin.ResponseWriter
+
+	header  http.Header
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         make(http.Header),
+		status:         http.StatusOK,
+	}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if code > 0 && !w.written {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.body.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op: the response is only sent once the handler returns.
+func (w *timeoutWriter) Flush() {}
+
+// flush copies the buffered response to the underlying writer.
+func (w *timeoutWriter) flush() error {
+	dst := w.ResponseWriter.Header()
+	for k, v := range w.header {
+		dst[k] = v
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if w.body.Len() > 0 {
+		if _, err := w.ResponseWriter.Write(w.body.Bytes()); err != nil {
+			return fmt.Errorf("failed to write response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func TestRouteTimeout(t *testing.T) {
+	timeouts := config.DefaultEndpointTimeouts()
+
+	tests := []struct {
+		route    string
+		expected time.Duration
+	}{
+		{"/health", 5 * time.Second},
+		{"/api/v1/cache/summary", 10 * time.Second},
+		{"/api/v1/cache/sdk/:name", 10 * time.Second},
+		{"/api/v1/cache/refresh", 15 * time.Minute},
+		{"/api/v1/analytics/usage", 30 * time.Second},
+		{"/api/v1/admin/features", time.Minute},
+		{"/ws/updates", 0},
+		{"", time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			assert.Equal(t, tt.expected, routeTimeout(timeouts, tt.route, time.Minute))
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	timeouts := map[string]time.Duration{
+		"/fast/*":    time.Second,
+		"/fast/slow": 20 * time.Millisecond,
+		"/unbounded": 0,
+	}
+
+	deadlines := make(map[string]time.Duration)
+	recordDeadline := func(c *gin.Context) {
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			deadlines[c.FullPath()] = time.Until(deadline).Round(time.Second)
+		} else {
+			deadlines[c.FullPath()] = 0
+		}
+		c.Header("X-Handler", "ran")
+		c.JSON(http.StatusCreated, gin.H{"ok": true})
+	}
+
+	r := gin.New()
+	r.Use(TimeoutMiddleware(timeouts, 3*time.Second, zerolog.Nop()))
+	r.GET("/fast/ok", recordDeadline)
+	r.GET("/other", recordDeadline)
+	r.GET("/unbounded", recordDeadline)
+	r.GET("/fast/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+		c.Header("X-Handler", "ran")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "cancelled"})
+	})
+
+	t.Run("applies route timeouts", func(t *testing.T) {
+		for _, path := range []string{"/fast/ok", "/other", "/unbounded"} {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", path, nil)
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, "ran", w.Header().Get("X-Handler"))
+			assert.JSONEq(t, `{"ok":true}`, w.Body.String())
+		}
+
+		assert.Equal(t, time.Second, deadlines["/fast/ok"])
+		assert.Equal(t, 3*time.Second, deadlines["/other"])
+		assert.Equal(t, time.Duration(0), deadlines["/unbounded"])
+	})
+
+	t.Run("returns 504 on expiry", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/fast/slow", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.Empty(t, w.Header().Get("X-Handler"))
+
+		var errorResponse ErrorResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+		assert.Equal(t, "gateway_timeout", errorResponse.Error)
+	})
+}
+
+// failingWriter is a ResponseWriter whose body writes fail, like one of a
+// client that has gone away.
+type failingWriter struct {
+	*httptest.ResponseRecorder
+}
+
+func (w failingWriter) Write(data []byte) (int, error) {
+	return 0, errors.New("connection reset by peer")
+}
+
+func TestTimeoutWriterFlushError(t *testing.T) {
+	c, _ := gin.CreateTestContext(failingWriter{httptest.NewRecorder()})
+
+	w := newTimeoutWriter(c.Writer)
+	_, err := w.WriteString(`{"ok":true}`)
+	require.NoError(t, err)
+	assert.ErrorContains(t, w.flush(), "connection reset by peer")
+}
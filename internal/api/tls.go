@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocertDir is where Let's Encrypt certificates are kept, under CacheDir,
+// so restarts reuse them instead of hitting issuance rate limits.
+const autocertDir = "autocert"
+
+// setupTLS prepares certificates from Let's Encrypt when TLS is enabled for
+// a domain: the HTTPS server gets them on demand, and a second server on
+// HTTPPort answers the ACME challenges and redirects to HTTPS.
+func (s *Server) setupTLS() {
+	if !s.config.TLSEnabled || s.config.TLSDomain == "" {
+		return
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(s.config.TLSDomain),
+		Cache:      autocert.DirCache(filepath.Join(s.config.CacheDir, autocertDir)),
+	}
+	s.http.TLSConfig = manager.TLSConfig()
+	s.redirect = &http.Server{
+		Addr:    ":" + s.config.HTTPPort,
+		Handler: manager.HTTPHandler(httpsRedirect(s.config.HTTPSPort)),
+	}
+}
+
+// httpsRedirect redirects requests to the same host and path over HTTPS on
+// httpsPort.
+func httpsRedirect(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			// No port in the Host header
+			host = r.Host
+		}
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	})
+}
+
+// usesTLS reports whether TLS is enabled and the server has a certificate
+// to serve HTTPS with, from Let's Encrypt or from files.
+func (s *Server) usesTLS() bool {
+	if !s.config.TLSEnabled {
+		return false
+	}
+	return s.http.TLSConfig != nil || (s.config.TLSCertFile != "" && s.config.TLSKeyFile != "")
+}
+
+// ServeTLS is like Serve over TLS, with the certificate from Let's Encrypt
+// or TLSCertFile and TLSKeyFile.
+func (s *Server) ServeTLS(listener net.Listener) error {
+	certFile, keyFile := s.config.TLSCertFile, s.config.TLSKeyFile
+	if s.http.TLSConfig != nil {
+		// Certificates come from TLSConfig.GetCertificate
+		certFile, keyFile = "", ""
+	}
+	if err := s.http.ServeTLS(listener, certFile, keyFile); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// shutdownRedirect stops the HTTP to HTTPS redirect server, if there is one.
+func (s *Server) shutdownRedirect(ctx context.Context) error {
+	if s.redirect == nil {
+		return nil
+	}
+	if err := s.redirect.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop HTTP redirect server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSignedCert writes a certificate for 127.0.0.1 and its key to
+// dir and returns their paths along with the parsed certificate.
+func writeSelfSignedCert(t *testing.T, dir string) (string, string, *x509.Certificate) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "claude-cache-service test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile, cert
+}
+
+func TestServeTLSWithCertFiles(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	certFile, keyFile, cert := writeSelfSignedCert(t, t.TempDir())
+	server.config.TLSEnabled = true
+	server.config.TLSCertFile = certFile
+	server.config.TLSKeyFile = keyFile
+	server = NewServer(server.config, cacheManager, server.worker, server.logger)
+	require.True(t, server.usesTLS())
+	assert.Nil(t, server.redirect)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- server.ServeTLS(listener) }()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+	defer client.CloseIdleConnections()
+
+	resp, err := client.Get("https://" + listener.Addr().String() + "/health")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, resp.TLS)
+	assert.True(t, resp.TLS.HandshakeComplete)
+	assert.Equal(t, cert.Raw, resp.TLS.PeerCertificates[0].Raw)
+
+	client.CloseIdleConnections()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	require.NoError(t, <-served)
+}
+
+func TestTLSModes(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+	assert.False(t, server.usesTLS())
+
+	// Enabled without a domain or certificate falls back to plain HTTP
+	server.config.TLSEnabled = true
+	plain := NewServer(server.config, cacheManager, server.worker, server.logger)
+	assert.False(t, plain.usesTLS())
+	assert.Nil(t, plain.redirect)
+
+	server.config.TLSDomain = "cache.example.com"
+	server.config.HTTPPort = "8080"
+	acme := NewServer(server.config, cacheManager, server.worker, server.logger)
+	assert.True(t, acme.usesTLS())
+	require.NotNil(t, acme.http.TLSConfig)
+	assert.Contains(t, acme.http.TLSConfig.NextProtos, "acme-tls/1")
+	require.NotNil(t, acme.redirect)
+	assert.Equal(t, ":8080", acme.redirect.Addr)
+}
+
+func TestHTTPSRedirect(t *testing.T) {
+	tests := []struct {
+		httpsPort string
+		host      string
+		want      string
+	}{
+		{httpsPort: "443", host: "cache.example.com", want: "https://cache.example.com/api/v1/sdk/list?active=true"},
+		{httpsPort: "443", host: "cache.example.com:80", want: "https://cache.example.com/api/v1/sdk/list?active=true"},
+		{httpsPort: "8443", host: "cache.example.com:8080", want: "https://cache.example.com:8443/api/v1/sdk/list?active=true"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/api/v1/sdk/list?active=true", nil)
+		req.Host = tt.host
+		w := httptest.NewRecorder()
+		httpsRedirect(tt.httpsPort).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMovedPermanently, w.Code)
+		assert.Equal(t, tt.want, w.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// defaultTimeSeriesRange is how far back the usage time series reaches
+// when from is not given.
+const defaultTimeSeriesRange = 24 * time.Hour
+
+// handleUsageAnalytics reports the tokens saved by serving SDK analyses
+// from cache. Savings can be limited to entries last active between the
+// since and until query parameters; request counts cover the process
+// lifetime. With analytics enabled, timeseries holds hourly hit, miss and
+// token savings counts between the from and to query parameters, which
+// default to the last 24 hours.
+func (s *Server) handleUsageAnalytics(c *gin.Context) {
+	window, err := parseTimeWindow(c)
+	var from, to time.Time
+	if err == nil {
+		from, to, err = parseTimeSeriesRange(c)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	bySDK := s.cache.TokenSavingsBySDKIn(window)
+	var totalSaved int64
+	for _, tokens := range bySDK {
+		totalSaved += tokens
+	}
+
+	stats := s.cache.GetStats()
+
+	var timeSeries gin.H
+	if store := s.worker.AnalyticsStore(); store != nil {
+		timeSeries = gin.H{
+			"from":    from,
+			"to":      to,
+			"buckets": store.Buckets().GetRange(from, to),
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"token_savings": gin.H{
+				"total":      totalSaved,
+				"percentage": calculateHitRate(stats.Hits, stats.Misses),
+				"by_sdk":     bySDK,
+			},
+			"requests": gin.H{
+				"total":  stats.Hits + stats.Misses,
+				"cached": stats.Hits,
+			},
+			"window": gin.H{
+				"since": optionalTime(window.Since),
+				"until": optionalTime(window.Until),
+			},
+			"timeseries": timeSeries,
+		},
+		Message:   "Usage analytics retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// maxHistogramBuckets bounds the buckets a hit count histogram may ask for.
+const maxHistogramBuckets = 50
+
+// handleSDKTokenSavings reports the tokens callers saved by reading an
+// SDK's cached analysis rather than having it analyzed again, since the
+// analysis was last written.
+func (s *Server) handleSDKTokenSavings(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	savings, err := s.cache.TokenSavings("sdk:" + sdkName)
+	if err != nil {
+		s.respondCacheError(c, err, "SDK cache not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk":                     sdkName,
+			"estimated_input_tokens":  savings.EstimatedInputTokens,
+			"times_served_from_cache": savings.TimesServedFromCache,
+			"total_tokens_saved":      savings.TotalTokensSaved,
+		},
+		Message:   "SDK token savings retrieved",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleHitCountHistogram reports how many cache entries have each range of
+// hit counts, to spot entries that are never read and could use a shorter
+// TTL. The buckets query parameter lists the ascending lower bounds of the
+// buckets, defaulting to 0,1,10,100,1000.
+func (s *Server) handleHitCountHistogram(c *gin.Context) {
+	buckets, err := parseHistogramBuckets(c.Query("buckets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   err.Error(),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	histogram := s.cache.HitCountHistogram(buckets)
+	var total int64
+	for _, count := range histogram {
+		total += count
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"histogram": histogram,
+			"ranges":    cache.HitCountBucketLabels(buckets),
+			"total":     total,
+		},
+		Message:   "Hit count histogram retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// parseHistogramBuckets reads comma-separated, strictly ascending,
+// non-negative bucket bounds. An empty string gives the default buckets.
+func parseHistogramBuckets(raw string) ([]int, error) {
+	if raw == "" {
+		return cache.DefaultHitCountBuckets, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) > maxHistogramBuckets {
+		return nil, fmt.Errorf("buckets must list at most %d bounds", maxHistogramBuckets)
+	}
+	buckets := make([]int, 0, len(parts))
+	for _, part := range parts {
+		bound, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || bound < 0 {
+			return nil, fmt.Errorf("buckets must be comma-separated non-negative integers, got %q", part)
+		}
+		if len(buckets) > 0 && bound <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("buckets must be in ascending order, got %d after %d", bound, buckets[len(buckets)-1])
+		}
+		buckets = append(buckets, bound)
+	}
+	return buckets, nil
+}
+
+// parseTimeWindow reads the RFC 3339 since and until query parameters.
+// Missing bounds are left open.
+func parseTimeWindow(c *gin.Context) (cache.TimeWindow, error) {
+	var window cache.TimeWindow
+	for _, bound := range []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"since", &window.Since},
+		{"until", &window.Until},
+	} {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return cache.TimeWindow{}, fmt.Errorf("%s must be an RFC 3339 timestamp", bound.name)
+		}
+		*bound.dst = t
+	}
+
+	if !window.Since.IsZero() && !window.Until.IsZero() && window.Until.Before(window.Since) {
+		return cache.TimeWindow{}, errors.New("until must not be before since")
+	}
+	return window, nil
+}
+
+// parseTimeSeriesRange reads the RFC 3339 from and to query parameters.
+// to defaults to now and from to a day before to.
+func parseTimeSeriesRange(c *gin.Context) (time.Time, time.Time, error) {
+	bounds := [2]time.Time{}
+	for i, name := range []string{"from", "to"} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+		bounds[i] = t
+	}
+
+	from, to := bounds[0], bounds[1]
+	if to.IsZero() {
+		to = time.Now().UTC()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultTimeSeriesRange)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, errors.New("to must not be before from")
+	}
+	return from, to, nil
+}
+
+// optionalTime returns nil for the zero time so it encodes as null.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
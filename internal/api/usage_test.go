@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func TestUsageAnalyticsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", time.Hour, cache.WithTokensCached(1000)))
+	for i := 0; i < 3; i++ {
+		_, err := cacheManager.Get("sdk:sentry-go")
+		require.NoError(t, err)
+	}
+	_, err := cacheManager.Get("sdk:sentry-python")
+	require.Error(t, err)
+	require.Eventually(t, func() bool {
+		return cacheManager.TokenSavingsBySDK()["sentry-go"] == 3000
+	}, time.Second, 10*time.Millisecond)
+
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expectedTotal  int64
+	}{
+		{name: "all time", query: "", expectedStatus: http.StatusOK, expectedTotal: 3000},
+		{name: "window excludes entries", query: "?since=" + future, expectedStatus: http.StatusOK, expectedTotal: 0},
+		{name: "invalid since", query: "?since=yesterday", expectedStatus: http.StatusBadRequest},
+		{name: "until before since", query: "?since=" + future + "&until=2020-01-01T00:00:00Z", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/analytics/usage"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					TokenSavings struct {
+						Total      int64            `json:"total"`
+						Percentage float64          `json:"percentage"`
+						BySDK      map[string]int64 `json:"by_sdk"`
+					} `json:"token_savings"`
+					Requests struct {
+						Total  int64 `json:"total"`
+						Cached int64 `json:"cached"`
+					} `json:"requests"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedTotal, response.Data.TokenSavings.Total)
+			assert.InDelta(t, 75.0, response.Data.TokenSavings.Percentage, 0.01)
+			assert.Equal(t, int64(4), response.Data.Requests.Total)
+			assert.Equal(t, int64(3), response.Data.Requests.Cached)
+			if tt.expectedTotal > 0 {
+				assert.Equal(t, map[string]int64{"sentry-go": tt.expectedTotal}, response.Data.TokenSavings.BySDK)
+			}
+		})
+	}
+}
+
+func TestUsageAnalyticsTimeSeries(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	get := func(query string) (int, map[string]interface{}) {
+		req, _ := http.NewRequest("GET", "/api/v1/analytics/usage"+query, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response.Data
+	}
+
+	// Without an analytics store there is no time series
+	code, data := get("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Nil(t, data["timeseries"])
+
+	store, err := analytics.Open(filepath.Join(t.TempDir(), "analytics.db"), zerolog.Nop())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+	server.worker.SetAnalyticsStore(store)
+	cacheManager.SetUsageRecorder(store.Buckets())
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", time.Hour, cache.WithTokensCached(500)))
+	for i := 0; i < 2; i++ {
+		_, err := cacheManager.Get("sdk:sentry-go")
+		require.NoError(t, err)
+	}
+	_, err = cacheManager.Get("sdk:sentry-ruby")
+	require.Error(t, err)
+
+	code, data = get("")
+	require.Equal(t, http.StatusOK, code)
+	series, ok := data["timeseries"].(map[string]interface{})
+	require.True(t, ok)
+	buckets, ok := series["buckets"].([]interface{})
+	require.True(t, ok)
+	require.Len(t, buckets, 1)
+	bucket := buckets[0].(map[string]interface{})
+	assert.Equal(t, 2.0, bucket["hits"])
+	assert.Equal(t, 1.0, bucket["misses"])
+	assert.Equal(t, 1000.0, bucket["tokens_saved"])
+
+	// A range before any events is empty
+	code, data = get("?from=2020-01-01T00:00:00Z&to=2020-01-02T00:00:00Z")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, data["timeseries"].(map[string]interface{})["buckets"])
+
+	for _, query := range []string{"?from=yesterday", "?to=2020-01-01T00:00:00Z&from=2021-01-01T00:00:00Z"} {
+		code, _ = get(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+}
+
+func TestHitCountHistogramEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", "{}", time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python", "{}", time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-ruby", "{}", time.Hour))
+	for i := 0; i < 12; i++ {
+		_, err := cacheManager.Get("sdk:sentry-ruby")
+		require.NoError(t, err)
+	}
+	_, err := cacheManager.Get("sdk:sentry-python")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		hits := cacheManager.HitsByPrefix("sdk:")
+		return hits["sdk:sentry-ruby"] == 12 && hits["sdk:sentry-python"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	tests := []struct {
+		name              string
+		query             string
+		expectedStatus    int
+		expectedHistogram map[string]int64
+		expectedRanges    []string
+	}{
+		{
+			name:              "default buckets",
+			query:             "",
+			expectedStatus:    http.StatusOK,
+			expectedHistogram: map[string]int64{"0": 1, "1-9": 1, "10-99": 1, "100-999": 0, "1000+": 0},
+			expectedRanges:    []string{"0", "1-9", "10-99", "100-999", "1000+"},
+		},
+		{
+			name:              "custom buckets",
+			query:             "?buckets=0,2",
+			expectedStatus:    http.StatusOK,
+			expectedHistogram: map[string]int64{"0-1": 2, "2+": 1},
+			expectedRanges:    []string{"0-1", "2+"},
+		},
+		{name: "not a number", query: "?buckets=0,ten", expectedStatus: http.StatusBadRequest},
+		{name: "negative", query: "?buckets=-1,10", expectedStatus: http.StatusBadRequest},
+		{name: "descending", query: "?buckets=10,1", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/api/v1/cache/stats/histogram"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response struct {
+				Data struct {
+					Histogram map[string]int64 `json:"histogram"`
+					Ranges    []string         `json:"ranges"`
+					Total     int64            `json:"total"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedHistogram, response.Data.Histogram)
+			assert.Equal(t, tt.expectedRanges, response.Data.Ranges)
+			assert.Equal(t, int64(3), response.Data.Total)
+		})
+	}
+}
+
+func TestSDKTokenSavingsEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+	savings := func() map[string]any {
+		w := get("/api/v1/cache/sdk/sentry-go/token-savings")
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Data map[string]any `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Data
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/api/v1/cache/sdk/sentry-go/token-savings").Code)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", `{"language":"go"}`, time.Hour, cache.WithTokenHint(2500)))
+	assert.Equal(t, float64(0), savings()["total_tokens_saved"])
+
+	// Reading the savings is not a read of the analysis
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, get("/api/v1/cache/sdk/sentry-go").Code)
+	}
+	require.Eventually(t, func() bool {
+		return savings()["times_served_from_cache"] == float64(3)
+	}, time.Second, 10*time.Millisecond)
+
+	data := savings()
+	assert.Equal(t, "sentry-go", data["sdk"])
+	assert.Equal(t, float64(2500), data["estimated_input_tokens"])
+	assert.Equal(t, float64(7500), data["total_tokens_saved"])
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page sizes for GET /api/v1/cache/sdk/:name/versions.
+const (
+	defaultVersionsLimit = 20
+	maxVersionsLimit     = 100
+)
+
+// handleListSDKVersions lists the cached analysis versions of an SDK, most
+// recently stored first, paged with limit and offset. total counts every
+// cached version.
+func (s *Server) handleListSDKVersions(c *gin.Context) {
+	sdkName := c.Param("name")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultVersionsLimit)))
+	if err != nil || limit < 1 || limit > maxVersionsLimit {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   fmt.Sprintf("limit must be between 1 and %d", maxVersionsLimit),
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "offset must be a non-negative integer",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	entries, err := s.cache.VersionEntries(sdkName)
+	if err != nil {
+		s.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to list SDK versions")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to list SDK versions",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if len(entries) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "No cached versions found for SDK",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	page := entries[min(offset, len(entries)):]
+	if len(page) > limit {
+		page = page[:limit]
+	}
+
+	prefix := "sdk:" + sdkName + ":"
+	versions := make([]gin.H, len(page))
+	for i, entry := range page {
+		versions[i] = gin.H{
+			"version":     entry.Key[len(prefix):],
+			"analyzed_at": entry.CreatedAt,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data: gin.H{
+			"sdk":      sdkName,
+			"versions": versions,
+			"total":    len(entries),
+			"limit":    limit,
+			"offset":   offset,
+		},
+		Message:   "SDK versions retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionsResponse struct {
+	Data struct {
+		SDK      string `json:"sdk"`
+		Versions []struct {
+			Version    string    `json:"version"`
+			AnalyzedAt time.Time `json:"analyzed_at"`
+		} `json:"versions"`
+		Total int `json:"total"`
+	} `json:"data"`
+}
+
+func TestListSDKVersions(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	stored := []string{"1.0.0", "1.1.0", "2.0.0"}
+	for _, version := range stored {
+		require.NoError(t, cacheManager.Set("sdk:sentry-go:"+version, `{"language":"go"}`, time.Hour))
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", `{"language":"go"}`, time.Hour))
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", time.Now().Format(time.RFC3339), time.Hour))
+
+	get := func(query string) (int, versionsResponse) {
+		req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-go/versions"+query, nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+
+		var response versionsResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+
+	code, response := get("")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "sentry-go", response.Data.SDK)
+	assert.Equal(t, 3, response.Data.Total)
+	require.Len(t, response.Data.Versions, 3)
+	for i, version := range []string{"2.0.0", "1.1.0", "1.0.0"} {
+		assert.Equal(t, version, response.Data.Versions[i].Version)
+		assert.WithinDuration(t, time.Now(), response.Data.Versions[i].AnalyzedAt, time.Minute)
+	}
+	assert.True(t, response.Data.Versions[0].AnalyzedAt.After(response.Data.Versions[2].AnalyzedAt))
+
+	// Paging
+	code, response = get("?limit=2&offset=1")
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, response.Data.Versions, 2)
+	assert.Equal(t, "1.1.0", response.Data.Versions[0].Version)
+	assert.Equal(t, "1.0.0", response.Data.Versions[1].Version)
+
+	code, response = get("?offset=10")
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response.Data.Versions)
+	assert.Equal(t, 3, response.Data.Total)
+
+	for _, query := range []string{"?limit=0", "?limit=101", "?offset=-1", "?offset=x"} {
+		code, _ = get(query)
+		assert.Equal(t, http.StatusBadRequest, code, query)
+	}
+
+	req, _ := http.NewRequest("GET", "/api/v1/cache/sdk/sentry-ruby/versions", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
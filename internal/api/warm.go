@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+// warmCacheRequest is the body of POST /api/v1/cache/warm.
+type warmCacheRequest struct {
+	SDKs []string `json:"sdks" binding:"required,min=1"`
+}
+
+func (s *Server) handleWarmCache(c *gin.Context) {
+	var req warmCacheRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if isBodyTooLarge(err) {
+			s.abortBodyTooLarge(c, s.config.MaxRequestBodyBytes)
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "Request body must be {\"sdks\": [\"name\", ...]}",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	force, err := strconv.ParseBool(c.DefaultQuery("force", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "force must be true or false",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	job, err := s.worker.WarmSDKs(req.SDKs, force)
+	if err != nil {
+		switch {
+		case errors.Is(err, worker.ErrUnknownSDK):
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:     "not_found",
+				Message:   err.Error(),
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		case errors.Is(err, worker.ErrDraining):
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:     "unavailable",
+				Message:   "Worker is shutting down",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		default:
+			s.logger.Error().Err(err).Msg("Failed to start cache warm")
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:     "internal_error",
+				Message:   "Failed to start cache warm",
+				RequestID: c.GetString("request_id"),
+				Timestamp: time.Now().Unix(),
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, SuccessResponse{
+		Data:      gin.H{"job_id": job.ID()},
+		Message:   "Cache warm started",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+// handleWarmup analyzes every active SDK that is missing or stale and
+// waits for the results. ?dry_run=true estimates the cost instead.
+func (s *Server) handleWarmup(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:     "invalid_request",
+			Message:   "dry_run must be true or false",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	summary, err := s.worker.Warmup(c.Request.Context(), dryRun)
+	if errors.Is(err, worker.ErrDraining) {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:     "unavailable",
+			Message:   "Worker is shutting down",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Msg("Failed to warm up cache")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:     "internal_error",
+			Message:   "Failed to warm up cache",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	message := "Cache warmed up"
+	if dryRun {
+		message = "Cache warmup estimated"
+	}
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      summary,
+		Message:   message,
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
+
+func (s *Server) handleJobProgress(c *gin.Context) {
+	job, ok := s.worker.Jobs().Get(c.Param("job_id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:     "not_found",
+			Message:   "Job not found",
+			RequestID: c.GetString("request_id"),
+			Timestamp: time.Now().Unix(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SuccessResponse{
+		Data:      job.Progress(),
+		Message:   "Job progress retrieved successfully",
+		RequestID: c.GetString("request_id"),
+		Timestamp: time.Now().Unix(),
+	})
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+	"github.com/ryanrussell/claude-cache-service/internal/worker"
+)
+
+func TestWarmCacheEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		authHeader     string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "missing token",
+			body:           `{"sdks": ["sentry-go"]}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedError:  "unauthorized",
+		},
+		{
+			name:           "empty sdk list",
+			body:           `{"sdks": []}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "invalid force",
+			query:          "?force=maybe",
+			body:           `{"sdks": ["sentry-go"]}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid_request",
+		},
+		{
+			name:           "unknown sdk",
+			body:           `{"sdks": ["sentry-go", "no-such-sdk"]}`,
+			authHeader:     "Bearer " + testAdminKey,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  "not_found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest("POST", "/api/v1/cache/warm"+tt.query, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			w := httptest.NewRecorder()
+			server.router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var errorResponse ErrorResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errorResponse))
+			assert.Equal(t, tt.expectedError, errorResponse.Error)
+		})
+	}
+}
+
+func TestWarmCacheJobProgress(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	// Fresh SDKs are skipped, so the job completes without analyzing
+	server.config.StaleThreshold = time.Hour
+	for _, name := range []string{"sentry-go", "sentry-python"} {
+		require.NoError(t, cacheManager.Set("sdk:"+name+":last_analyzed", time.Now().Format(time.RFC3339), 0))
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/cache/warm", strings.NewReader(`{"sdks": ["sentry-go", "sentry-python"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+testAdminKey)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started struct {
+		Data struct {
+			JobID string `json:"job_id"`
+		} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	require.NotEmpty(t, started.Data.JobID)
+
+	var progress struct {
+		Data worker.JobProgress `json:"data"`
+	}
+	require.Eventually(t, func() bool {
+		req, _ := http.NewRequest("GET", "/api/v1/jobs/"+started.Data.JobID+"/progress", nil)
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return false
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &progress))
+		return progress.Data.Status == worker.JobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, started.Data.JobID, progress.Data.JobID)
+	assert.Equal(t, 2, progress.Data.Completed)
+	assert.Equal(t, 2, progress.Data.Total)
+	assert.Equal(t, 2, progress.Data.Skipped)
+	assert.Equal(t, 0, progress.Data.Errors)
+	assert.Equal(t, 100.0, progress.Data.Percent)
+}
+
+func TestJobProgressNotFound(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	req, _ := http.NewRequest("GET", "/api/v1/jobs/missing/progress", nil)
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestWarmupEndpoint(t *testing.T) {
+	server, cacheManager := setupTestServer(t)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	post := func(query, authHeader string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/api/v1/cache/warmup"+query, nil)
+		if authHeader != "" {
+			req.Header.Set("Authorization", authHeader)
+		}
+		w := httptest.NewRecorder()
+		server.router.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, post("", "").Code)
+	assert.Equal(t, http.StatusBadRequest, post("?dry_run=maybe", "Bearer "+testAdminKey).Code)
+
+	// Every SDK is fresh, so nothing is cloned or analyzed
+	server.config.StaleThreshold = time.Hour
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	active := configs.GetActiveSDKs()
+	for _, target := range active {
+		require.NoError(t, cacheManager.Set("sdk:"+target.Name+":last_analyzed", time.Now().Format(time.RFC3339), 0))
+	}
+
+	for _, query := range []string{"", "?dry_run=true"} {
+		w := post(query, "Bearer "+testAdminKey)
+		require.Equal(t, http.StatusOK, w.Code, query)
+
+		var response struct {
+			Data worker.WarmupSummary `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, query != "", response.Data.DryRun)
+		assert.Equal(t, len(active), response.Data.Total)
+		assert.Equal(t, len(active), response.Data.Skipped)
+		assert.Zero(t, response.Data.Cached)
+		assert.Zero(t, response.Data.Failed)
+		require.Len(t, response.Data.SDKs, len(active))
+		assert.Equal(t, worker.WarmupSkipped, response.Data.SDKs[0].Status)
+	}
+}
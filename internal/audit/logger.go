@@ -0,0 +1,123 @@
+// Package audit keeps a queryable trail of the operations that change the
+// cache and the service's state.
+package audit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// ActorSystem is the actor of mutations not tied to an API request, and of
+// cache writes and deletes recorded by the cache manager itself.
+const ActorSystem = "system"
+
+// Actions recorded for API requests. Writes and deletes made through the
+// cache manager are recorded as "set" and "delete".
+const (
+	ActionDeleteKey      = "delete_key"
+	ActionDeleteKeys     = "delete_keys"
+	ActionRefresh        = "refresh"
+	ActionWarm           = "warm"
+	ActionImport         = "import"
+	ActionCompact        = "compact"
+	ActionToggleSDK      = "toggle_sdk"
+	ActionCleanRepo      = "clean_repo"
+	ActionBatchAnalyze   = "batch_analyze"
+	ActionResetBackoff   = "reset_backoff"
+	ActionPruneRepos     = "prune_repos"
+	ActionSetFeature     = "set_feature"
+	ActionPruneAnalytics = "prune_analytics"
+	ActionTestNotify     = "test_notify"
+	ActionAnalyzeSDK     = "analyze_sdk"
+	ActionWarmup         = "warmup"
+	ActionRegisterSDK    = "register_sdk"
+	ActionUnregisterSDK  = "unregister_sdk"
+	ActionTouchKey       = "touch_key"
+	ActionRetryAnalysis  = "retry_analysis"
+	ActionPatchSDK       = "patch_sdk"
+)
+
+// AuditEvent is one recorded mutation.
+type AuditEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	RequestID string    `json:"request_id,omitempty"`
+	ClientIP  string    `json:"client_ip,omitempty"`
+	Action    string    `json:"action"`
+	// Key is the cache key, prefix or SDK the action applied to, if any
+	Key     string `json:"key,omitempty"`
+	Actor   string `json:"actor"`
+	Success bool   `json:"success"`
+}
+
+// AuditLogger records audit events in the analytics store, where they are
+// kept for the configured AuditLogDays. A nil AuditLogger discards events.
+type AuditLogger struct {
+	store  *analytics.Store
+	logger zerolog.Logger
+}
+
+// NewAuditLogger returns an AuditLogger writing to store.
+func NewAuditLogger(store *analytics.Store, logger zerolog.Logger) *AuditLogger {
+	return &AuditLogger{store: store, logger: logger}
+}
+
+// Log records event, stamping it with the current time if it has none.
+// Failures are logged rather than returned so they never fail the
+// operation being audited.
+func (l *AuditLogger) Log(event AuditEvent) {
+	if l == nil {
+		return
+	}
+
+	err := l.store.RecordAuditEvent(analytics.AuditEvent{
+		Action:    event.Action,
+		Actor:     event.Actor,
+		Resource:  event.Key,
+		RequestID: event.RequestID,
+		ClientIP:  event.ClientIP,
+		Success:   event.Success,
+		Timestamp: event.Timestamp,
+	})
+	if err != nil {
+		l.logger.Error().Err(err).Str("action", event.Action).Str("key", event.Key).Msg("Failed to record audit event")
+	}
+}
+
+// RecordMutation records a cache write or delete made through the cache
+// manager, implementing cache.AuditRecorder.
+func (l *AuditLogger) RecordMutation(action cache.EventType, key string, err error) {
+	l.Log(AuditEvent{
+		Action:  string(action),
+		Key:     key,
+		Actor:   ActorSystem,
+		Success: err == nil,
+	})
+}
+
+// Recent returns up to limit audit events, newest first. A non-empty
+// action keeps only events with that action.
+func (l *AuditLogger) Recent(limit int, action string) ([]AuditEvent, error) {
+	stored, err := l.store.AuditEvents(limit, action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+
+	events := make([]AuditEvent, len(stored))
+	for i, e := range stored {
+		events[i] = AuditEvent{
+			Timestamp: e.Timestamp,
+			RequestID: e.RequestID,
+			ClientIP:  e.ClientIP,
+			Action:    e.Action,
+			Key:       e.Resource,
+			Actor:     e.Actor,
+			Success:   e.Success,
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,73 @@
+package audit
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func newTestLogger(t *testing.T) *AuditLogger {
+	t.Helper()
+
+	store, err := analytics.Open(filepath.Join(t.TempDir(), "analytics.db"), zerolog.Nop())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, store.Close())
+	})
+	return NewAuditLogger(store, zerolog.Nop())
+}
+
+func TestAuditLogger(t *testing.T) {
+	logger := newTestLogger(t)
+
+	ts := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	logger.Log(AuditEvent{
+		Timestamp: ts,
+		RequestID: "req-1",
+		ClientIP:  "203.0.113.7",
+		Action:    ActionDeleteKey,
+		Key:       "sdk:sentry-go",
+		Actor:     "api:0123abcd",
+		Success:   true,
+	})
+	logger.RecordMutation(cache.EventSet, "sdk:sentry-go", nil)
+	logger.RecordMutation(cache.EventDelete, "sdk:sentry-go", errors.New("disk full"))
+
+	events, err := logger.Recent(10, "")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, AuditEvent{Action: "delete", Key: "sdk:sentry-go", Actor: ActorSystem, Success: false, Timestamp: events[0].Timestamp}, events[0])
+	assert.Equal(t, "set", events[1].Action)
+	assert.True(t, events[1].Success)
+	assert.Equal(t, AuditEvent{
+		Timestamp: events[2].Timestamp,
+		RequestID: "req-1",
+		ClientIP:  "203.0.113.7",
+		Action:    ActionDeleteKey,
+		Key:       "sdk:sentry-go",
+		Actor:     "api:0123abcd",
+		Success:   true,
+	}, events[2])
+	assert.True(t, ts.Equal(events[2].Timestamp))
+
+	events, err = logger.Recent(10, ActionDeleteKey)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
+
+func TestNilAuditLogger(t *testing.T) {
+	var logger *AuditLogger
+	assert.NotPanics(t, func() {
+		logger.Log(AuditEvent{Action: ActionImport})
+		logger.RecordMutation(cache.EventSet, "key", nil)
+	})
+}
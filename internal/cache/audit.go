@@ -0,0 +1,33 @@
+package cache
+
+// AuditRecorder keeps a trail of the writes and deletes made through the
+// Manager. RecordMutation is called synchronously after every Set and
+// Delete, successful or not, with action EventSet or EventDelete; deletes
+// of missing keys, expiry and eviction are not recorded.
+type AuditRecorder interface {
+	RecordMutation(action EventType, key string, err error)
+}
+
+// auditHolder lets an AuditRecorder be swapped atomically.
+type auditHolder struct {
+	AuditRecorder
+}
+
+// SetAuditRecorder sends every later write and delete to r. It is safe to
+// call while the cache is in use.
+func (m *Manager) SetAuditRecorder(r AuditRecorder) {
+	m.audit.Store(&auditHolder{r})
+}
+
+// auditRecorder returns the current recorder, or one that discards
+// everything.
+func (m *Manager) auditRecorder() AuditRecorder {
+	if h := m.audit.Load(); h != nil {
+		return h.AuditRecorder
+	}
+	return nopAuditRecorder{}
+}
+
+type nopAuditRecorder struct{}
+
+func (nopAuditRecorder) RecordMutation(EventType, string, error) {}
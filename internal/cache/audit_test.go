@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditedMutation is a call to RecordMutation.
+type auditedMutation struct {
+	action  EventType
+	key     string
+	success bool
+}
+
+// listAuditRecorder keeps every mutation it receives.
+type listAuditRecorder struct {
+	mu        sync.Mutex
+	mutations []auditedMutation
+}
+
+func (r *listAuditRecorder) RecordMutation(action EventType, key string, err error) {
+	r.mu.Lock()
+	r.mutations = append(r.mutations, auditedMutation{action, key, err == nil})
+	r.mu.Unlock()
+}
+
+func TestAuditRecorder(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	recorder := &listAuditRecorder{}
+	manager.SetAuditRecorder(recorder)
+
+	require.NoError(t, manager.Set("a", "1", 0))
+	require.NoError(t, manager.SetMulti([]CacheEntry{{Key: "b", Value: "2"}}))
+	_, err := manager.SetWithVersion("a", "3", 0, 1)
+	require.NoError(t, err)
+	_, err = manager.SetWithVersion("a", "4", 0, 1)
+	require.ErrorIs(t, err, ErrVersionConflict)
+	require.NoError(t, manager.Delete("a"))
+	require.NoError(t, manager.Delete("a"))
+	_, err = manager.DeleteByPrefix("b")
+	require.NoError(t, err)
+
+	// Reads and deletes of missing keys are not recorded
+	_, err = manager.Get("missing")
+	require.Error(t, err)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, []auditedMutation{
+		{EventSet, "a", true},
+		{EventSet, "b", true},
+		{EventSet, "a", true},
+		{EventSet, "a", false},
+		{EventDelete, "a", true},
+		{EventDelete, "b", true},
+	}, recorder.mutations)
+}
@@ -0,0 +1,469 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tidwall/buntdb"
+	"github.com/tidwall/gjson"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+// Backend names accepted by WithBackend.
+const (
+	BackendBuntDB = "buntdb"
+	BackendRedis  = "redis"
+)
+
+var (
+	// ErrNotFound is returned by a Backend for keys it does not hold. The
+	// Manager reports misses as *apperrors.CacheMissError, which matches it.
+	ErrNotFound = apperrors.ErrCacheMiss
+
+	// ErrNotSupported is returned for operations the backend cannot perform.
+	ErrNotSupported = errors.New("operation not supported by cache backend")
+
+	// ErrVersionConflict is returned when a key's version is not the one a
+	// conditional write expected.
+	ErrVersionConflict = errors.New("cache entry version conflict")
+)
+
+// Backend stores cache entries. Entries with a TTL expire TTL after their
+// UpdatedAt time. Every write sets the entry's Version to one more than the
+// version it replaced, or to 1 for a new key. Entries returned for replaced
+// or removed keys carry only Key, Size and Version. Implementations must be
+// safe for concurrent use.
+type Backend interface {
+	// Get returns the entry stored under key, or ErrNotFound.
+	Get(key string) (CacheEntry, error)
+	// Set stores entry under entry.Key and returns the entry it replaced,
+	// or nil for a new key.
+	Set(entry CacheEntry) (*CacheEntry, error)
+	// GetMulti returns the entries stored under keys. Missing keys are
+	// absent from the map.
+	GetMulti(keys []string) (map[string]CacheEntry, error)
+	// SetMulti stores every entry in one transaction and returns the
+	// entries they replaced, in order, with nil for new keys.
+	SetMulti(entries []CacheEntry) ([]*CacheEntry, error)
+	// SetIfVersion stores entry like Set, but only if the key is at
+	// version expected, where a missing key is at version 0. Otherwise it
+	// returns ErrVersionConflict and stores nothing.
+	SetIfVersion(entry CacheEntry, expected int64) (*CacheEntry, error)
+	// Replace overwrites the stored entry with entry, keeping its version,
+	// if the key is still at entry.Version. It is meant for metadata such
+	// as hit counts and returns ErrVersionConflict if the key was written
+	// since entry was read, or ErrNotFound if it is gone.
+	Replace(entry CacheEntry) error
+	// Touch restarts the TTL of the entry stored under key at now, setting
+	// it to ttl unless ttl is 0, in one transaction that keeps the value,
+	// version and hit count. It returns the touched entry, or ErrNotFound
+	// if the key is missing or has expired by now.
+	Touch(key string, ttl time.Duration, now time.Time) (CacheEntry, error)
+	// Delete removes key and returns the removed entry, or ErrNotFound.
+	Delete(key string) (CacheEntry, error)
+	// DeletePrefix removes every key starting with prefix in one
+	// transaction and returns the removed entries.
+	DeletePrefix(prefix string) ([]CacheEntry, error)
+	// Keys returns every stored key starting with prefix.
+	Keys(prefix string) ([]string, error)
+	// Close releases the backend's resources.
+	Close() error
+}
+
+// compactor is implemented by backends backed by a file that can be shrunk.
+type compactor interface {
+	Shrink() error
+	FileSize() (int64, error)
+}
+
+// recencyIndexer is implemented by backends that index entries by
+// UpdatedAt. AscendUpdated calls fn with the key and size of each entry,
+// least recently updated first, until fn returns false.
+type recencyIndexer interface {
+	AscendUpdated(fn func(key string, size int64) bool) error
+}
+
+// expiryNotifier is implemented by backends that report expired entries.
+type expiryNotifier interface {
+	OnExpire(fn func(entry CacheEntry)) error
+}
+
+// updatedAtIndex orders BuntDB entries by UpdatedAt.
+const updatedAtIndex = "updated_at"
+
+// BuntDBBackend stores entries in a BuntDB file.
+type BuntDBBackend struct {
+	db   *buntdb.DB
+	path string
+}
+
+// NewBuntDBBackend opens or creates the BuntDB database at path.
+func NewBuntDBBackend(path string) (*BuntDBBackend, error) {
+	db, err := buntdb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+	if err := db.CreateIndex(updatedAtIndex, "*", updatedAtLess); err != nil && err != buntdb.ErrIndexExists {
+		if closeErr := db.Close(); closeErr != nil {
+			err = errors.Join(err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to create cache index: %w", err)
+	}
+	return &BuntDBBackend{db: db, path: path}, nil
+}
+
+// updatedAtLess orders stored entries by their updated_at time. Times are
+// compared parsed, as their text does not sort across time zones.
+func updatedAtLess(a, b string) bool {
+	return updatedAt(a).Before(updatedAt(b))
+}
+
+func updatedAt(value string) time.Time {
+	t, err := time.Parse(time.RFC3339Nano, gjson.Get(value, "updated_at").String())
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// Get returns the entry stored under key.
+func (b *BuntDBBackend) Get(key string) (CacheEntry, error) {
+	var entry CacheEntry
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(key)
+		if err != nil {
+			return err
+		}
+		return decodeEntry(val, &entry)
+	})
+	if err == buntdb.ErrNotFound {
+		return CacheEntry{}, ErrNotFound
+	}
+	return entry, err
+}
+
+// Set stores entry, expiring it with buntdb's TTL support.
+func (b *BuntDBBackend) Set(entry CacheEntry) (*CacheEntry, error) {
+	previous, err := b.SetMulti([]CacheEntry{entry})
+	if err != nil {
+		return nil, err
+	}
+	return previous[0], nil
+}
+
+// GetMulti returns the entries stored under keys in one read transaction.
+func (b *BuntDBBackend) GetMulti(keys []string) (map[string]CacheEntry, error) {
+	entries := make(map[string]CacheEntry, len(keys))
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		for _, key := range keys {
+			val, err := tx.Get(key)
+			if err == buntdb.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			var entry CacheEntry
+			if err := decodeEntry(val, &entry); err != nil {
+				return err
+			}
+			entries[key] = entry
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// SetMulti stores entries in one write transaction.
+func (b *BuntDBBackend) SetMulti(entries []CacheEntry) ([]*CacheEntry, error) {
+	previous := make([]*CacheEntry, len(entries))
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		for i, entry := range entries {
+			replaced, err := setInTx(tx, entry)
+			if err != nil {
+				return err
+			}
+			previous[i] = replaced
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// SetIfVersion stores entry if its key is at version expected, checking
+// and writing in one transaction.
+func (b *BuntDBBackend) SetIfVersion(entry CacheEntry, expected int64) (*CacheEntry, error) {
+	var previous *CacheEntry
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		version, err := versionInTx(tx, entry.Key)
+		if err != nil {
+			return err
+		}
+		if version != expected {
+			return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, entry.Key, version, expected)
+		}
+
+		previous, err = setInTx(tx, entry)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return previous, nil
+}
+
+// Replace overwrites entry if its key is still at entry.Version.
+func (b *BuntDBBackend) Replace(entry CacheEntry) error {
+	return b.db.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(entry.Key)
+		if err == buntdb.ErrNotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if version := gjson.Get(val, "version").Int(); version != entry.Version {
+			return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, entry.Key, version, entry.Version)
+		}
+
+		_, err = writeInTx(tx, entry)
+		return err
+	})
+}
+
+// Touch restarts the TTL of key, reading and writing it in one
+// transaction.
+func (b *BuntDBBackend) Touch(key string, ttl time.Duration, now time.Time) (CacheEntry, error) {
+	var entry CacheEntry
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(key)
+		if err == buntdb.ErrNotFound {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := decodeEntry(val, &entry); err != nil {
+			return err
+		}
+		if entry.expired(now) {
+			return ErrNotFound
+		}
+
+		touchEntry(&entry, ttl, now)
+		_, err = writeInTx(tx, entry)
+		return err
+	})
+	if err != nil {
+		return CacheEntry{}, err
+	}
+	return entry, nil
+}
+
+// touchEntry restarts the TTL of entry at now, replacing it with ttl
+// unless ttl is 0.
+func touchEntry(entry *CacheEntry, ttl time.Duration, now time.Time) {
+	entry.UpdatedAt = now
+	if ttl > 0 {
+		entry.TTL = ttl
+	}
+}
+
+// setInTx stores entry with the version after the one it replaces.
+func setInTx(tx *buntdb.Tx, entry CacheEntry) (*CacheEntry, error) {
+	version, err := versionInTx(tx, entry.Key)
+	if err != nil {
+		return nil, err
+	}
+	entry.Version = version + 1
+	return writeInTx(tx, entry)
+}
+
+// writeInTx stores entry as it is, expiring it with buntdb's TTL support.
+func writeInTx(tx *buntdb.Tx, entry CacheEntry) (*CacheEntry, error) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	var opts *buntdb.SetOptions
+	if entry.TTL > 0 {
+		opts = &buntdb.SetOptions{Expires: true, TTL: time.Until(entry.UpdatedAt.Add(entry.TTL))}
+	}
+
+	val, replaced, err := tx.Set(entry.Key, string(data), opts)
+	if err != nil {
+		return nil, err
+	}
+	if !replaced {
+		return nil, nil
+	}
+	return replacedEntry(entry.Key, val), nil
+}
+
+// versionInTx returns the version of the entry stored under key, or 0 if
+// there is none.
+func versionInTx(tx *buntdb.Tx, key string) (int64, error) {
+	val, err := tx.Get(key)
+	if err == buntdb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return gjson.Get(val, "version").Int(), nil
+}
+
+// Delete removes key and returns the removed entry.
+func (b *BuntDBBackend) Delete(key string) (CacheEntry, error) {
+	var entry CacheEntry
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Delete(key)
+		if err != nil {
+			return err
+		}
+		entry = *replacedEntry(key, val)
+		return nil
+	})
+	if err == buntdb.ErrNotFound {
+		return CacheEntry{}, ErrNotFound
+	}
+	return entry, err
+}
+
+// DeletePrefix removes every key starting with prefix in a single update
+// transaction.
+func (b *BuntDBBackend) DeletePrefix(prefix string) ([]CacheEntry, error) {
+	var removed []CacheEntry
+	err := b.db.Update(func(tx *buntdb.Tx) error {
+		// Keys cannot be deleted while ascending, so collect them first
+		var keys []string
+		err := tx.AscendKeys(prefix+"*", func(key, _ string) bool {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			val, err := tx.Delete(key)
+			if err == buntdb.ErrNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			removed = append(removed, *replacedEntry(key, val))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// Keys returns every stored key starting with prefix.
+func (b *BuntDBBackend) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		return tx.AscendKeys(prefix+"*", func(key, _ string) bool {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+			return true
+		})
+	})
+	return keys, err
+}
+
+// AscendUpdated walks the updated_at index, least recently updated first.
+func (b *BuntDBBackend) AscendUpdated(fn func(key string, size int64) bool) error {
+	return b.db.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend(updatedAtIndex, func(key, value string) bool {
+			return fn(key, gjson.Get(value, "size").Int())
+		})
+	})
+}
+
+// OnExpire calls fn, inside buntdb's expiry transaction, for every entry
+// whose TTL runs out.
+func (b *BuntDBBackend) OnExpire(fn func(entry CacheEntry)) error {
+	var cfg buntdb.Config
+	if err := b.db.ReadConfig(&cfg); err != nil {
+		return fmt.Errorf("failed to read cache database config: %w", err)
+	}
+
+	cfg.OnExpiredSync = func(key, value string, tx *buntdb.Tx) error {
+		// Delete reports ErrNotFound for items that have already expired
+		if _, err := tx.Delete(key); err != nil && err != buntdb.ErrNotFound {
+			return err
+		}
+		fn(*replacedEntry(key, value))
+		return nil
+	}
+
+	if err := b.db.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to set cache database config: %w", err)
+	}
+	return nil
+}
+
+// Shrink compacts the database file.
+func (b *BuntDBBackend) Shrink() error {
+	if err := b.db.Shrink(); err != nil {
+		return fmt.Errorf("failed to shrink cache database: %w", err)
+	}
+	return nil
+}
+
+// FileSize returns the size of the database file in bytes.
+func (b *BuntDBBackend) FileSize() (int64, error) {
+	info, err := os.Stat(b.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat cache database: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// Close closes the database file.
+func (b *BuntDBBackend) Close() error {
+	if err := b.db.Close(); err != nil {
+		return fmt.Errorf("failed to close cache database: %w", err)
+	}
+	return nil
+}
+
+// replacedEntry returns the key, size and version of an entry that has
+// already been overwritten or removed, which is all size accounting needs. Skipping the
+// rest of the entry keeps batch writes over existing keys cheap. An
+// unreadable value yields a zero size rather than failing the write.
+func replacedEntry(key, value string) *CacheEntry {
+	return &CacheEntry{
+		Key:     key,
+		Size:    gjson.Get(value, "size").Int(),
+		Version: gjson.Get(value, "version").Int(),
+	}
+}
+
+func decodeEntry(value string, entry *CacheEntry) error {
+	if err := json.Unmarshal([]byte(value), entry); err != nil {
+		return fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	}
+	return nil
+}
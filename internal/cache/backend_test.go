@@ -0,0 +1,293 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBackends opens each Backend implementation for the shared suite.
+func testBackends(t *testing.T) map[string]Backend {
+	t.Helper()
+
+	bunt, err := NewBuntDBBackend(filepath.Join(t.TempDir(), "cache.db"))
+	require.NoError(t, err)
+
+	redis, err := NewRedisBackend("redis://" + miniredis.RunT(t).Addr())
+	require.NoError(t, err)
+
+	backends := map[string]Backend{
+		BackendBuntDB: bunt,
+		BackendRedis:  redis,
+	}
+	t.Cleanup(func() {
+		for _, b := range backends {
+			require.NoError(t, b.Close())
+		}
+	})
+	return backends
+}
+
+func testEntry(key, value string) CacheEntry {
+	now := time.Now().UTC().Truncate(time.Second)
+	return CacheEntry{
+		Key:       key,
+		Value:     value,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Size:      int64(len(value)),
+	}
+}
+
+func TestBackends(t *testing.T) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, b Backend)
+	}{
+		{
+			name: "get missing key",
+			run: func(t *testing.T, b Backend) {
+				_, err := b.Get("missing")
+				assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name: "set and get",
+			run: func(t *testing.T, b Backend) {
+				entry := testEntry("sdk:sentry-go", "analysis")
+				entry.TTL = time.Hour
+				entry.TokensCached = 42
+
+				previous, err := b.Set(entry)
+				require.NoError(t, err)
+				assert.Nil(t, previous)
+
+				got, err := b.Get("sdk:sentry-go")
+				require.NoError(t, err)
+				entry.Version = 1
+				assert.Equal(t, entry, got)
+			},
+		},
+		{
+			name: "overwrite returns previous entry",
+			run: func(t *testing.T, b Backend) {
+				_, err := b.Set(testEntry("key", "old"))
+				require.NoError(t, err)
+
+				previous, err := b.Set(testEntry("key", "newer"))
+				require.NoError(t, err)
+				require.NotNil(t, previous)
+				assert.Equal(t, "key", previous.Key)
+				assert.Equal(t, int64(3), previous.Size)
+				assert.Equal(t, int64(1), previous.Version)
+
+				got, err := b.Get("key")
+				require.NoError(t, err)
+				assert.Equal(t, int64(2), got.Version)
+			},
+		},
+		{
+			name: "multi set and get",
+			run: func(t *testing.T, b Backend) {
+				_, err := b.Set(testEntry("b", "old"))
+				require.NoError(t, err)
+
+				previous, err := b.SetMulti([]CacheEntry{testEntry("a", "1"), testEntry("b", "2")})
+				require.NoError(t, err)
+				require.Len(t, previous, 2)
+				assert.Nil(t, previous[0])
+				require.NotNil(t, previous[1])
+				assert.Equal(t, int64(3), previous[1].Size)
+
+				entries, err := b.GetMulti([]string{"a", "b", "missing"})
+				require.NoError(t, err)
+				a, updated := testEntry("a", "1"), testEntry("b", "2")
+				a.Version = 1
+				updated.Version = 2
+				assert.Equal(t, map[string]CacheEntry{"a": a, "b": updated}, entries)
+			},
+		},
+		{
+			name: "set if version",
+			run: func(t *testing.T, b Backend) {
+				// A missing key is at version 0
+				_, err := b.SetIfVersion(testEntry("key", "first"), 1)
+				assert.ErrorIs(t, err, ErrVersionConflict)
+				previous, err := b.SetIfVersion(testEntry("key", "first"), 0)
+				require.NoError(t, err)
+				assert.Nil(t, previous)
+
+				_, err = b.SetIfVersion(testEntry("key", "stale"), 0)
+				assert.ErrorIs(t, err, ErrVersionConflict)
+				previous, err = b.SetIfVersion(testEntry("key", "second"), 1)
+				require.NoError(t, err)
+				require.NotNil(t, previous)
+				assert.Equal(t, int64(1), previous.Version)
+
+				got, err := b.Get("key")
+				require.NoError(t, err)
+				assert.Equal(t, "second", got.Value)
+				assert.Equal(t, int64(2), got.Version)
+
+				// Versions start again once the key is deleted
+				_, err = b.Delete("key")
+				require.NoError(t, err)
+				_, err = b.SetIfVersion(testEntry("key", "third"), 0)
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "delete returns removed entry",
+			run: func(t *testing.T, b Backend) {
+				_, err := b.Set(testEntry("key", "value"))
+				require.NoError(t, err)
+
+				removed, err := b.Delete("key")
+				require.NoError(t, err)
+				assert.Equal(t, int64(5), removed.Size)
+
+				_, err = b.Get("key")
+				assert.ErrorIs(t, err, ErrNotFound)
+				_, err = b.Delete("key")
+				assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name: "delete by prefix",
+			run: func(t *testing.T, b Backend) {
+				for _, key := range []string{"sdk:a", "sdk:b", "sdk*:c", "sdks", "project:sdk:a"} {
+					_, err := b.Set(testEntry(key, "v"))
+					require.NoError(t, err)
+				}
+
+				removed, err := b.DeletePrefix("sdk:")
+				require.NoError(t, err)
+				sort.Slice(removed, func(i, j int) bool { return removed[i].Key < removed[j].Key })
+				assert.Equal(t, []CacheEntry{{Key: "sdk:a", Size: 1, Version: 1}, {Key: "sdk:b", Size: 1, Version: 1}}, removed)
+
+				keys, err := b.Keys("")
+				require.NoError(t, err)
+				sort.Strings(keys)
+				assert.Equal(t, []string{"project:sdk:a", "sdk*:c", "sdks"}, keys)
+
+				removed, err = b.DeletePrefix("missing:")
+				require.NoError(t, err)
+				assert.Empty(t, removed)
+			},
+		},
+		{
+			name: "keys by prefix",
+			run: func(t *testing.T, b Backend) {
+				for _, key := range []string{"sdk:a", "sdk:b", "sdk*:c", "project:a"} {
+					_, err := b.Set(testEntry(key, "v"))
+					require.NoError(t, err)
+				}
+
+				keys, err := b.Keys("sdk:")
+				require.NoError(t, err)
+				sort.Strings(keys)
+				assert.Equal(t, []string{"sdk:a", "sdk:b"}, keys)
+
+				keys, err = b.Keys("sdk*")
+				require.NoError(t, err)
+				assert.Equal(t, []string{"sdk*:c"}, keys)
+
+				keys, err = b.Keys("")
+				require.NoError(t, err)
+				assert.Len(t, keys, 4)
+			},
+		},
+		{
+			name: "touch",
+			run: func(t *testing.T, b Backend) {
+				entry := testEntry("key", "value")
+				entry.UpdatedAt = entry.UpdatedAt.Add(-50 * time.Minute)
+				entry.TTL = time.Hour
+				entry.HitCount = 4
+				_, err := b.Set(entry)
+				require.NoError(t, err)
+
+				now := time.Now().UTC().Truncate(time.Second)
+				touched, err := b.Touch("key", 0, now)
+				require.NoError(t, err)
+				assert.Equal(t, now, touched.UpdatedAt)
+				assert.Equal(t, time.Hour, touched.TTL)
+
+				got, err := b.Get("key")
+				require.NoError(t, err)
+				assert.Equal(t, touched, got)
+				assert.Equal(t, "value", got.Value)
+				assert.Equal(t, int64(4), got.HitCount)
+				assert.Equal(t, int64(1), got.Version)
+
+				_, err = b.Touch("key", 2*time.Hour, now)
+				require.NoError(t, err)
+				got, err = b.Get("key")
+				require.NoError(t, err)
+				assert.Equal(t, 2*time.Hour, got.TTL)
+
+				_, err = b.Touch("key", 0, now.Add(3*time.Hour))
+				assert.ErrorIs(t, err, ErrNotFound, "expired by then")
+				_, err = b.Touch("missing", 0, now)
+				assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name: "expired entries are gone",
+			run: func(t *testing.T, b Backend) {
+				entry := testEntry("key", "value")
+				entry.UpdatedAt = entry.UpdatedAt.Add(-2 * time.Hour)
+				entry.TTL = time.Hour
+				_, err := b.Set(entry)
+				require.NoError(t, err)
+
+				_, err = b.Get("key")
+				assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		for name, backend := range testBackends(t) {
+			t.Run(name+"/"+tt.name, func(t *testing.T) {
+				tt.run(t, backend)
+			})
+		}
+	}
+}
+
+func TestManagerWithRedisBackend(t *testing.T) {
+	redis := miniredis.RunT(t)
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	manager, err := NewManager(t.TempDir(), logger, WithBackend(BackendRedis, "redis://"+redis.Addr()))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, manager.Close())
+	}()
+
+	require.NoError(t, manager.Set("key", "value", time.Hour))
+	value, err := manager.Get("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+	assert.True(t, redis.Exists(redisKeyPrefix+"key"))
+	assert.Equal(t, time.Hour, redis.TTL(redisKeyPrefix+"key").Round(time.Minute))
+
+	require.NoError(t, manager.Delete("key"))
+	_, err = manager.Get("key")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.ErrorIs(t, manager.ShrinkDB(), ErrNotSupported)
+}
+
+func TestNewManagerUnknownBackend(t *testing.T) {
+	_, err := NewManager(t.TempDir(), zerolog.Nop(), WithBackend("memcached", ""))
+	assert.ErrorContains(t, err, "unknown cache backend")
+}
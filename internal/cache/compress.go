@@ -0,0 +1,52 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// WithCompressThreshold gzips values longer than threshold bytes before
+// storing them. Zero or less stores every value as is.
+func WithCompressThreshold(threshold int64) Option {
+	return func(m *Manager) {
+		m.compressThreshold = threshold
+	}
+}
+
+// compressValue gzips value and encodes the result as base64 so it can be
+// stored in the entry's JSON.
+func compressValue(value string) (string, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(zw, value); err != nil {
+		return "", fmt.Errorf("failed to compress cache value: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("failed to compress cache value: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// decompressValue reverses compressValue.
+func decompressValue(stored string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode compressed cache value: %w", err)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress cache value: %w", err)
+	}
+	value, err := io.ReadAll(zr)
+	if err == nil {
+		err = zr.Close()
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress cache value: %w", err)
+	}
+	return string(value), nil
+}
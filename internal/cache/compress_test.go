@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressingManager(t *testing.T, threshold int64) *Manager {
+	t.Helper()
+
+	manager, err := NewManager(t.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled), WithCompressThreshold(threshold))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, manager.Close())
+	})
+	return manager
+}
+
+func TestCompressionRoundTrip(t *testing.T) {
+	manager := newCompressingManager(t, 4096)
+
+	value := `{"language":"go","features":[` + strings.Repeat(`"breadcrumbs","sessions",`, 2000) + `"tracing"]}`
+	require.NoError(t, manager.Set("sdk:sentry-go", value, 0))
+
+	got, err := manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, value, got)
+
+	stored, err := manager.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.True(t, stored.Compressed)
+	assert.Equal(t, int64(len(value)), stored.Size)
+	assert.Equal(t, int64(len(stored.Value)), stored.CompressedSize)
+	assert.Less(t, stored.CompressedSize, stored.Size)
+
+	assert.Greater(t, manager.GetStats().CompressionRatio, 1.0)
+}
+
+func TestCompressionSkipsSmallValues(t *testing.T) {
+	manager := newCompressingManager(t, 4096)
+
+	require.NoError(t, manager.Set("small", "value", 0))
+
+	stored, err := manager.backend.Get("small")
+	require.NoError(t, err)
+	assert.False(t, stored.Compressed)
+	assert.Equal(t, "value", stored.Value)
+	assert.Zero(t, manager.GetStats().CompressionRatio)
+}
+
+func TestCompressionDisabled(t *testing.T) {
+	manager := newCompressingManager(t, 0)
+
+	value := strings.Repeat("x", 10000)
+	require.NoError(t, manager.Set("key", value, 0))
+
+	stored, err := manager.backend.Get("key")
+	require.NoError(t, err)
+	assert.False(t, stored.Compressed)
+	assert.Equal(t, value, stored.Value)
+}
+
+func TestCompressValueRoundTrip(t *testing.T) {
+	for _, value := range []string{"", "short", strings.Repeat("abc", 5000), "unicode ✓ \x00 bytes"} {
+		compressed, err := compressValue(value)
+		require.NoError(t, err)
+
+		got, err := decompressValue(compressed)
+		require.NoError(t, err)
+		assert.Equal(t, value, got)
+	}
+
+	_, err := decompressValue("not base64!")
+	assert.Error(t, err)
+}
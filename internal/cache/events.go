@@ -0,0 +1,155 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// EventType describes what happened to a cache key.
+type EventType string
+
+// Cache event types.
+const (
+	EventSet    EventType = "set"
+	EventDelete EventType = "delete"
+	EventExpire EventType = "expire"
+)
+
+// eventBufferSize is how many events a subscriber may fall behind by before
+// further events are dropped.
+const eventBufferSize = 64
+
+// CacheEvent is a change to a single cache key.
+type CacheEvent struct {
+	Type      EventType `json:"event"`
+	Key       string    `json:"key"`
+	Timestamp int64     `json:"timestamp"`
+}
+
+// subscription receives the events for keys accepted by match.
+type subscription struct {
+	ch    chan CacheEvent
+	match func(key string) bool
+	stop  func() bool
+
+	// remaining, when non-nil, holds the keys whose removal ends the
+	// subscription
+	remaining map[string]bool
+}
+
+// subscribers is the set of active subscriptions on a Manager.
+type subscribers struct {
+	mu   sync.Mutex
+	subs map[*subscription]struct{}
+}
+
+// Subscribe returns a channel receiving events for every key accepted by
+// match, or for all keys when match is nil. The channel is closed when ctx
+// is cancelled or the manager is closed. Events are dropped for
+// subscribers that fall too far behind.
+func (m *Manager) Subscribe(ctx context.Context, match func(key string) bool) (<-chan CacheEvent, error) {
+	return m.subscribe(ctx, match, nil)
+}
+
+// Watch returns a channel receiving events for exactly key. The channel is
+// closed when ctx is cancelled or after the key is deleted or expires.
+func (m *Manager) Watch(ctx context.Context, key string) (<-chan CacheEvent, error) {
+	return m.WatchMany(ctx, []string{key})
+}
+
+// WatchMany is like Watch for several keys. The channel is closed when ctx
+// is cancelled or once every watched key has been deleted or expired.
+func (m *Manager) WatchMany(ctx context.Context, keys []string) (<-chan CacheEvent, error) {
+	watched := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		watched[key] = true
+	}
+
+	return m.subscribe(ctx, func(key string) bool { return watched[key] }, keys)
+}
+
+func (m *Manager) subscribe(ctx context.Context, match func(key string) bool, untilRemoved []string) (<-chan CacheEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sub := &subscription{
+		ch:    make(chan CacheEvent, eventBufferSize),
+		match: match,
+	}
+	if len(untilRemoved) > 0 {
+		sub.remaining = make(map[string]bool, len(untilRemoved))
+		for _, key := range untilRemoved {
+			sub.remaining[key] = true
+		}
+	}
+
+	m.events.mu.Lock()
+	if m.events.subs == nil {
+		m.events.subs = make(map[*subscription]struct{})
+	}
+	m.events.subs[sub] = struct{}{}
+	sub.stop = context.AfterFunc(ctx, func() { m.unsubscribe(sub) })
+	m.events.mu.Unlock()
+
+	return sub.ch, nil
+}
+
+func (m *Manager) unsubscribe(sub *subscription) {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	if _, ok := m.events.subs[sub]; ok {
+		delete(m.events.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// emit delivers an event to every matching subscriber without blocking.
+func (m *Manager) emit(eventType EventType, key string) {
+	event := CacheEvent{
+		Type:      eventType,
+		Key:       key,
+		Timestamp: time.Now().Unix(),
+	}
+
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	for sub := range m.events.subs {
+		if sub.match != nil && !sub.match(key) {
+			continue
+		}
+
+		select {
+		case sub.ch <- event:
+		default:
+			m.logger.Warn().
+				Str("key", key).
+				Str("event", string(eventType)).
+				Msg("Dropped cache event for slow subscriber")
+		}
+
+		if sub.remaining != nil && eventType != EventSet {
+			delete(sub.remaining, key)
+			if len(sub.remaining) == 0 {
+				sub.stop()
+				delete(m.events.subs, sub)
+				close(sub.ch)
+			}
+		}
+	}
+}
+
+// closeSubscriptions closes every subscriber channel.
+func (m *Manager) closeSubscriptions() {
+	m.events.mu.Lock()
+	defer m.events.mu.Unlock()
+
+	for sub := range m.events.subs {
+		sub.stop()
+		delete(m.events.subs, sub)
+		close(sub.ch)
+	}
+}
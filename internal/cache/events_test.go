@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEventsTestManager(t *testing.T) *Manager {
+	t.Helper()
+
+	manager, err := NewManager(t.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, manager.Close())
+	})
+	return manager
+}
+
+// collect reads events until the channel closes or timeout passes.
+func collect(ch <-chan CacheEvent, timeout time.Duration) ([]CacheEvent, bool) {
+	var events []CacheEvent
+	deadline := time.After(timeout)
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return events, true
+			}
+			events = append(events, event)
+		case <-deadline:
+			return events, false
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	manager := newEventsTestManager(t)
+	require.NoError(t, manager.Set("sdk:sentry-go", "v0", 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := manager.Watch(ctx, "sdk:sentry-go")
+	require.NoError(t, err)
+
+	go func() {
+		for i := 1; i <= 5; i++ {
+			if err := manager.Set("sdk:sentry-go", fmt.Sprintf("v%d", i), 0); err != nil {
+				t.Errorf("set failed: %v", err)
+			}
+			// Changes to other keys are not delivered
+			if err := manager.Set("sdk:sentry-python", "other", 0); err != nil {
+				t.Errorf("set failed: %v", err)
+			}
+		}
+	}()
+
+	received, closed := collect(events, 200*time.Millisecond)
+	assert.False(t, closed)
+	require.Len(t, received, 5)
+	for _, event := range received {
+		assert.Equal(t, EventSet, event.Type)
+		assert.Equal(t, "sdk:sentry-go", event.Key)
+		assert.NotZero(t, event.Timestamp)
+	}
+}
+
+func TestWatchClosesOnDelete(t *testing.T) {
+	manager := newEventsTestManager(t)
+	require.NoError(t, manager.Set("project:foo", "value", 0))
+
+	events, err := manager.Watch(context.Background(), "project:foo")
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Delete("project:foo"))
+
+	received, closed := collect(events, time.Second)
+	assert.True(t, closed)
+	require.Len(t, received, 1)
+	assert.Equal(t, EventDelete, received[0].Type)
+}
+
+func TestWatchClosesOnCancel(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := manager.Watch(ctx, "project:foo")
+	require.NoError(t, err)
+
+	cancel()
+	received, closed := collect(events, time.Second)
+	assert.True(t, closed)
+	assert.Empty(t, received)
+
+	// Changes after cancellation must not send on the closed channel
+	require.NoError(t, manager.Set("project:foo", "value", 0))
+
+	_, err = manager.Watch(ctx, "project:foo")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWatchExpire(t *testing.T) {
+	manager := newEventsTestManager(t)
+	require.NoError(t, manager.Set("project:short", "value", 50*time.Millisecond))
+
+	events, err := manager.Watch(context.Background(), "project:short")
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, manager.cleanup())
+
+	received, closed := collect(events, 3*time.Second)
+	assert.True(t, closed)
+	require.Len(t, received, 1)
+	assert.Equal(t, EventExpire, received[0].Type)
+}
+
+func TestWatchMany(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	events, err := manager.WatchMany(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Set("a", "1", 0))
+	require.NoError(t, manager.Set("b", "1", 0))
+	require.NoError(t, manager.Set("c", "1", 0))
+	require.NoError(t, manager.Delete("a"))
+
+	// Still open while b exists
+	received, closed := collect(events, 100*time.Millisecond)
+	assert.False(t, closed)
+	require.Len(t, received, 3)
+
+	require.NoError(t, manager.Delete("b"))
+	received, closed = collect(events, time.Second)
+	assert.True(t, closed)
+	require.Len(t, received, 1)
+	assert.Equal(t, CacheEvent{Type: EventDelete, Key: "b", Timestamp: received[0].Timestamp}, received[0])
+}
+
+func TestSubscribe(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := manager.Subscribe(ctx, func(key string) bool {
+		return strings.HasPrefix(key, "project:")
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, manager.Set("project:foo", "1", 0))
+	require.NoError(t, manager.Set("sdk:sentry-go", "1", 0))
+	require.NoError(t, manager.Delete("project:foo"))
+
+	// Deleting keys does not end a plain subscription
+	received, closed := collect(events, 100*time.Millisecond)
+	assert.False(t, closed)
+	require.Len(t, received, 2)
+	assert.Equal(t, EventSet, received[0].Type)
+	assert.Equal(t, EventDelete, received[1].Type)
+}
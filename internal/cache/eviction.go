@@ -0,0 +1,136 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog"
+)
+
+// EvictionChannel is the Redis pub/sub channel eviction notices are sent on.
+const EvictionChannel = "cache:eviction"
+
+// EvictionNotifier broadcasts cache evictions between service instances so
+// each one can drop its local copy of an evicted key.
+type EvictionNotifier interface {
+	// Publish announces that key was evicted by this instance.
+	Publish(key string) error
+	// Subscribe registers fn to be called for keys evicted by other instances.
+	Subscribe(fn func(key string)) error
+}
+
+// evictionMessage is the payload sent on the eviction channel.
+type evictionMessage struct {
+	Instance string `json:"instance"`
+	Key      string `json:"key"`
+}
+
+// RedisEvictionNotifier implements EvictionNotifier using Redis pub/sub.
+type RedisEvictionNotifier struct {
+	client   *redis.Client
+	instance string
+	logger   zerolog.Logger
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+}
+
+// NewRedisEvictionNotifier creates a notifier from a Redis URL such as
+// redis://host:6379/0.
+func NewRedisEvictionNotifier(redisURL string, logger zerolog.Logger) (*RedisEvictionNotifier, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+	}
+
+	instance, err := newInstanceID()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedisEvictionNotifier{
+		client:   redis.NewClient(opts),
+		instance: instance,
+		logger:   logger,
+	}, nil
+}
+
+// Publish announces an eviction to all subscribed instances.
+func (n *RedisEvictionNotifier) Publish(key string) error {
+	payload, err := json.Marshal(evictionMessage{Instance: n.instance, Key: key})
+	if err != nil {
+		return fmt.Errorf("failed to marshal eviction message: %w", err)
+	}
+
+	if err := n.client.Publish(context.Background(), EvictionChannel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish eviction: %w", err)
+	}
+	return nil
+}
+
+// Subscribe listens for evictions from other instances. Notices published by
+// this instance are ignored. It returns once the subscription is active.
+func (n *RedisEvictionNotifier) Subscribe(fn func(key string)) error {
+	ctx := context.Background()
+
+	pubsub := n.client.Subscribe(ctx, EvictionChannel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		if closeErr := pubsub.Close(); closeErr != nil {
+			n.logger.Error().Err(closeErr).Msg("Failed to close eviction subscription")
+		}
+		return fmt.Errorf("failed to subscribe to evictions: %w", err)
+	}
+
+	n.mu.Lock()
+	n.pubsub = pubsub
+	n.mu.Unlock()
+
+	go func() {
+		for msg := range pubsub.Channel() {
+			var notice evictionMessage
+			if err := json.Unmarshal([]byte(msg.Payload), &notice); err != nil {
+				n.logger.Warn().Err(err).Str("payload", msg.Payload).Msg("Ignoring malformed eviction message")
+				continue
+			}
+
+			if notice.Instance == n.instance {
+				continue
+			}
+			fn(notice.Key)
+		}
+	}()
+
+	return nil
+}
+
+// Close stops the subscription and closes the Redis connection.
+func (n *RedisEvictionNotifier) Close() error {
+	n.mu.Lock()
+	pubsub := n.pubsub
+	n.pubsub = nil
+	n.mu.Unlock()
+
+	if pubsub != nil {
+		if err := pubsub.Close(); err != nil {
+			n.logger.Error().Err(err).Msg("Failed to close eviction subscription")
+		}
+	}
+
+	if err := n.client.Close(); err != nil {
+		return fmt.Errorf("failed to close redis client: %w", err)
+	}
+	return nil
+}
+
+func newInstanceID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate instance id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNotifier(t *testing.T, redis *miniredis.Miniredis) *RedisEvictionNotifier {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	notifier, err := NewRedisEvictionNotifier("redis://"+redis.Addr(), logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, notifier.Close())
+	})
+	return notifier
+}
+
+func newNotifiedManager(t *testing.T, redis *miniredis.Miniredis) *Manager {
+	t.Helper()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	manager, err := NewManager(t.TempDir(), logger, WithEvictionNotifier(newTestNotifier(t, redis)))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, manager.Close())
+	})
+	return manager
+}
+
+func TestRedisEvictionNotifierIgnoresOwnMessages(t *testing.T) {
+	redis := miniredis.RunT(t)
+
+	publisher := newTestNotifier(t, redis)
+	subscriber := newTestNotifier(t, redis)
+
+	var mu sync.Mutex
+	received := map[string][]string{}
+	record := func(name string) func(string) {
+		return func(key string) {
+			mu.Lock()
+			defer mu.Unlock()
+			received[name] = append(received[name], key)
+		}
+	}
+
+	require.NoError(t, publisher.Subscribe(record("publisher")))
+	require.NoError(t, subscriber.Subscribe(record("subscriber")))
+
+	require.NoError(t, publisher.Publish("sdk:sentry-go"))
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received["subscriber"]) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"sdk:sentry-go"}, received["subscriber"])
+	assert.Empty(t, received["publisher"])
+}
+
+func TestEvictionInvalidatesOtherInstances(t *testing.T) {
+	redis := miniredis.RunT(t)
+
+	evicting := newNotifiedManager(t, redis)
+	other := newNotifiedManager(t, redis)
+
+	// The other instance holds a copy without a TTL of its own
+	require.NoError(t, evicting.Set("sdk:sentry-go", "analysis", 100*time.Millisecond))
+	require.NoError(t, other.Set("sdk:sentry-go", "analysis", 0))
+	require.NoError(t, other.Set("sdk:sentry-python", "analysis", 0))
+
+	assert.Eventually(t, func() bool {
+		_, err := other.Get("sdk:sentry-go")
+		return err != nil
+	}, 5*time.Second, 50*time.Millisecond, "expired key should be dropped from the other instance")
+
+	value, err := other.Get("sdk:sentry-python")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", value)
+}
+
+func TestLRUEvictionInvalidatesOtherInstances(t *testing.T) {
+	redis := miniredis.RunT(t)
+
+	evicting := newNotifiedManager(t, redis)
+	other := newNotifiedManager(t, redis)
+
+	require.NoError(t, evicting.Set("sdk:sentry-go", "analysis", 0))
+	require.NoError(t, other.Set("sdk:sentry-go", "analysis", 0))
+	require.NoError(t, other.Set("sdk:sentry-python", "analysis", 0))
+
+	// Shrinking the limit evicts sdk:sentry-go from the evicting instance
+	require.NoError(t, evicting.SetMaxSize(1))
+
+	assert.Eventually(t, func() bool {
+		_, err := other.Get("sdk:sentry-go")
+		return err != nil
+	}, 5*time.Second, 50*time.Millisecond, "evicted key should be dropped from the other instance")
+
+	value, err := other.Get("sdk:sentry-python")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", value)
+}
+
+func TestNewManagerWithUnreachableRedis(t *testing.T) {
+	redis := miniredis.RunT(t)
+	notifier := newTestNotifier(t, redis)
+	redis.Close()
+
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	_, err := NewManager(t.TempDir(), logger, WithEvictionNotifier(notifier))
+	assert.Error(t, err)
+}
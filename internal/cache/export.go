@@ -0,0 +1,177 @@
+package cache
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// ExportCache writes every unexpired entry to w as newline-delimited JSON
+// CacheEntry records, in key order. Values are written decompressed, so an
+// export can be imported into a cache with any compression threshold.
+func (m *Manager) ExportCache(ctx context.Context, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	return m.exportEntries(ctx, func(entry CacheEntry) error {
+		return enc.Encode(entry)
+	})
+}
+
+// csvExportHeader is the header row written by ExportCacheCSV.
+var csvExportHeader = []string{"key", "value_length", "hit_count", "created_at", "ttl_seconds"}
+
+// ExportCacheCSV writes one CSV row per unexpired entry to w, in key order,
+// after a header row. Rows describe entries without their values: the
+// value length, hit count, creation time (RFC 3339) and remaining TTL in
+// whole seconds, empty for entries without a TTL.
+func (m *Manager) ExportCacheCSV(ctx context.Context, w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvExportHeader); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	err := m.exportEntries(ctx, func(entry CacheEntry) error {
+		ttl := ""
+		if entry.TTL > 0 {
+			ttl = strconv.FormatInt(int64(entry.TTLRemaining(time.Now())/time.Second), 10)
+		}
+		return cw.Write([]string{
+			entry.Key,
+			strconv.Itoa(len(entry.Value)),
+			strconv.FormatInt(entry.HitCount, 10),
+			entry.CreatedAt.UTC().Format(time.RFC3339),
+			ttl,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ExportCacheZip writes every unexpired value to w as a ZIP archive with
+// one file per entry, in key order. File names are the keys, path-escaped
+// so that keys containing slashes do not become directories.
+func (m *Manager) ExportCacheZip(ctx context.Context, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	err := m.exportEntries(ctx, func(entry CacheEntry) error {
+		file, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     url.PathEscape(entry.Key),
+			Method:   zip.Deflate,
+			Modified: entry.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(file, entry.Value)
+		return err
+	})
+	if err != nil {
+		// Leave the archive without its central directory, so a
+		// truncated export is not mistaken for a complete one
+		return err
+	}
+	return zw.Close()
+}
+
+// exportEntries calls write with every unexpired entry, in key order, with
+// its value decompressed.
+func (m *Manager) exportEntries(ctx context.Context, write func(CacheEntry) error) error {
+	keys, err := m.keys("")
+	if err != nil {
+		return fmt.Errorf("failed to list cache keys: %w", err)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entry, err := m.backend.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			// Deleted or expired since the keys were listed
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read key %s: %w", key, err)
+		}
+		if entry.expired(time.Now()) {
+			continue
+		}
+
+		value, err := entry.value()
+		if err != nil {
+			return fmt.Errorf("failed to read key %s: %w", key, err)
+		}
+		entry.Value = value
+		entry.Compressed = false
+		entry.CompressedSize = 0
+
+		if err := write(entry); err != nil {
+			return fmt.Errorf("failed to write key %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ImportCache reads entries in the format written by ExportCache from r and
+// stores each with Set, keeping whatever was left of its TTL when it was
+// exported. Entries that have expired since are skipped, as are keys already
+// in the cache unless overwrite is set. It returns how many entries were
+// stored, including when it stops early on an invalid record.
+func (m *Manager) ImportCache(ctx context.Context, r io.Reader, overwrite bool) (int, error) {
+	dec := json.NewDecoder(r)
+	imported := 0
+	for record := 1; ; record++ {
+		if err := ctx.Err(); err != nil {
+			return imported, err
+		}
+
+		var entry CacheEntry
+		if err := dec.Decode(&entry); err == io.EOF {
+			return imported, nil
+		} else if err != nil {
+			return imported, fmt.Errorf("invalid record %d: %w", record, err)
+		}
+		if entry.Key == "" {
+			return imported, fmt.Errorf("invalid record %d: missing key", record)
+		}
+
+		ttl := entry.TTL
+		if ttl > 0 && !entry.UpdatedAt.IsZero() {
+			ttl = entry.TTLRemaining(time.Now())
+			if ttl <= 0 {
+				continue
+			}
+		}
+
+		if !overwrite {
+			existing, err := m.backend.Get(entry.Key)
+			if err == nil && !existing.expired(time.Now()) {
+				continue
+			}
+			if err != nil && !errors.Is(err, ErrNotFound) {
+				return imported, fmt.Errorf("failed to read key %s: %w", entry.Key, err)
+			}
+		}
+
+		value, err := entry.value()
+		if err != nil {
+			return imported, fmt.Errorf("invalid record %d: %w", record, err)
+		}
+		if err := m.Set(entry.Key, value, ttl, WithTokensCached(entry.TokensCached), WithTokenHint(entry.TokenSavings.EstimatedInputTokens)); err != nil {
+			return imported, err
+		}
+		imported++
+	}
+}
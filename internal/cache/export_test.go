@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	manager := newCompressingManager(t, 64)
+
+	dataset := map[string]string{
+		"sdk:sentry-go":     `{"language":"go","features":[` + strings.Repeat(`"tracing",`, 50) + `"sessions"]}`,
+		"sdk:sentry-python": `{"language":"python"}`,
+		"project:gremlin":   "arrow flight",
+	}
+	for key, value := range dataset {
+		require.NoError(t, manager.Set(key, value, time.Hour, WithTokensCached(1500)))
+	}
+	require.NoError(t, manager.Set("sdks:total", "29", 0))
+	dataset["sdks:total"] = "29"
+
+	// Expired entries are left out of the export
+	require.NoError(t, manager.Set("expired", "gone", time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	var buf bytes.Buffer
+	require.NoError(t, manager.ExportCache(context.Background(), &buf))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(dataset))
+	for _, line := range lines {
+		var entry CacheEntry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		assert.False(t, entry.Compressed, entry.Key)
+		assert.Equal(t, dataset[entry.Key], entry.Value)
+	}
+
+	_, err := manager.DeleteByPrefix("")
+	require.NoError(t, err)
+	_, err = manager.Get("sdk:sentry-go")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	imported, err := manager.ImportCache(context.Background(), bytes.NewReader(buf.Bytes()), false)
+	require.NoError(t, err)
+	assert.Equal(t, len(dataset), imported)
+
+	for key, value := range dataset {
+		got, err := manager.Get(key)
+		require.NoError(t, err, key)
+		assert.Equal(t, value, got, key)
+	}
+
+	stored, err := manager.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.True(t, stored.Compressed)
+	assert.Equal(t, 1500, stored.TokensCached)
+	assert.InDelta(t, time.Hour, stored.TTL, float64(time.Minute))
+
+	persistent, err := manager.backend.Get("sdks:total")
+	require.NoError(t, err)
+	assert.Zero(t, persistent.TTL)
+}
+
+func TestImportCacheOverwrite(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	export := `{"key":"sdk:sentry-go","value":"imported","ttl":0}
+{"key":"sdk:sentry-ruby","value":"imported","ttl":0}
+`
+	require.NoError(t, manager.Set("sdk:sentry-go", "local", 0))
+
+	imported, err := manager.ImportCache(context.Background(), strings.NewReader(export), false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+	got, err := manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "local", got)
+
+	imported, err = manager.ImportCache(context.Background(), strings.NewReader(export), true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	got, err = manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "imported", got)
+}
+
+func TestImportCacheSkipsExpired(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	// Exported with a minute left an hour ago
+	updated := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+	export := `{"key":"stale","value":"v","updated_at":"` + updated + `","ttl":60000000000}` + "\n"
+
+	imported, err := manager.ImportCache(context.Background(), strings.NewReader(export), true)
+	require.NoError(t, err)
+	assert.Equal(t, 0, imported)
+	_, err = manager.Get("stale")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestImportCacheInvalid(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		imported     int
+		errorMessage string
+	}{
+		{
+			name:         "malformed json",
+			input:        `{"key":"a","value":"1"}` + "\n{not json\n",
+			imported:     1,
+			errorMessage: "invalid record 2",
+		},
+		{
+			name:         "missing key",
+			input:        `{"value":"1"}`,
+			errorMessage: "invalid record 1: missing key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newEventsTestManager(t)
+
+			imported, err := manager.ImportCache(context.Background(), strings.NewReader(tt.input), false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMessage)
+			assert.Equal(t, tt.imported, imported)
+		})
+	}
+}
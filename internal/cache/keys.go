@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ListKeys returns up to limit entries whose keys start with prefix, in key
+// order, beginning after cursor, with their values left empty. The returned
+// cursor continues the listing and is empty once every key has been
+// returned. A limit of zero or less returns every key.
+func (m *Manager) ListKeys(prefix string, limit int, cursor string) ([]CacheEntry, string, error) {
+	keys, err := m.keys(prefix)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list cache keys: %w", err)
+	}
+	sort.Strings(keys)
+
+	start := sort.SearchStrings(keys, cursor)
+	if start < len(keys) && keys[start] == cursor {
+		start++
+	}
+	page := keys[start:]
+
+	next := ""
+	if limit > 0 && len(page) > limit {
+		page = page[:limit]
+		next = page[len(page)-1]
+	}
+
+	entries, err := m.backend.GetMulti(page)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read cache keys: %w", err)
+	}
+
+	now := time.Now()
+	listed := make([]CacheEntry, 0, len(page))
+	for _, key := range page {
+		entry, ok := entries[key]
+		if !ok || entry.expired(now) {
+			continue
+		}
+		entry.Value = ""
+		listed = append(listed, entry)
+	}
+	return listed, next, nil
+}
+
+// CountKeys returns how many keys start with prefix.
+func (m *Manager) CountKeys(prefix string) (int, error) {
+	keys, err := m.keys(prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list cache keys: %w", err)
+	}
+	return len(keys), nil
+}
+
+// TTLRemaining returns how long until the entry expires, or zero if it has
+// no TTL.
+func (e CacheEntry) TTLRemaining(now time.Time) time.Duration {
+	if e.TTL <= 0 {
+		return 0
+	}
+	return max(e.UpdatedAt.Add(e.TTL).Sub(now), 0)
+}
+
+// DeleteByPrefix removes every key starting with prefix and returns how
+// many were removed. An empty prefix clears the whole cache.
+func (m *Manager) DeleteByPrefix(prefix string) (int, error) {
+	endWrite := m.l1.beginWrite()
+	removed, err := m.backend.DeletePrefix(prefix)
+	m.l1.RemovePrefix(prefix)
+	endWrite()
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete keys with prefix %q: %w", prefix, err)
+	}
+
+	// Flush writes the persisted counters again, so they are not counted
+	removed = slices.DeleteFunc(removed, func(entry CacheEntry) bool { return entry.Key == statsKey })
+	for _, entry := range removed {
+		m.recordDelete(entry.Size)
+		m.emit(EventDelete, entry.Key)
+		m.auditRecorder().RecordMutation(EventDelete, entry.Key, nil)
+	}
+
+	if len(removed) > 0 {
+		m.logger.Info().Str("prefix", prefix).Int("count", len(removed)).Msg("Deleted cache keys by prefix")
+	}
+	return len(removed), nil
+}
@@ -0,0 +1,83 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteByPrefix(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	for _, key := range []string{"sdk:sentry-go", "sdk:sentry-go:1.0", "sdk:sentry-python", "sdks:total", "project:sdk:sentry-go"} {
+		require.NoError(t, manager.Set(key, "value", 0))
+	}
+
+	// A partial prefix removes every key it starts
+	count, err := manager.DeleteByPrefix("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	_, err = manager.Get("sdk:sentry-go:1.0")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = manager.Get("sdk:sentry-python")
+	assert.NoError(t, err)
+
+	count, err = manager.DeleteByPrefix("sdk:")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Keys that merely contain or extend the prefix's namespace survive
+	for _, key := range []string{"sdks:total", "project:sdk:sentry-go"} {
+		_, err := manager.Get(key)
+		assert.NoError(t, err, key)
+	}
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(3), stats.Deletes)
+	assert.Equal(t, int64(2), stats.ItemCount)
+	assert.Equal(t, int64(10), stats.TotalSize)
+}
+
+func TestListKeys(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	require.NoError(t, manager.Set("sdk:c", "ccc", time.Hour))
+	require.NoError(t, manager.Set("sdk:a", "a", 0))
+	require.NoError(t, manager.Set("sdk:b", "bb", 0))
+	require.NoError(t, manager.Set("project:a", "a", 0))
+
+	entries, next, err := manager.ListKeys("sdk:", 2, "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "sdk:a", entries[0].Key)
+	assert.Equal(t, int64(1), entries[0].Size)
+	assert.Empty(t, entries[0].Value)
+	assert.Equal(t, "sdk:b", entries[1].Key)
+	assert.Equal(t, "sdk:b", next)
+
+	entries, next, err = manager.ListKeys("sdk:", 2, next)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "sdk:c", entries[0].Key)
+	assert.Equal(t, int64(3), entries[0].Size)
+	assert.InDelta(t, time.Hour, entries[0].TTLRemaining(time.Now()), float64(time.Minute))
+	assert.Empty(t, next)
+
+	entries, _, err = manager.ListKeys("", 0, "")
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+
+	count, err := manager.CountKeys("sdk:")
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
+
+func TestTTLRemaining(t *testing.T) {
+	now := time.Now()
+	assert.Zero(t, CacheEntry{UpdatedAt: now}.TTLRemaining(now))
+	assert.Equal(t, time.Minute, CacheEntry{UpdatedAt: now, TTL: time.Hour}.TTLRemaining(now.Add(59*time.Minute)))
+	assert.Zero(t, CacheEntry{UpdatedAt: now, TTL: time.Hour}.TTLRemaining(now.Add(2*time.Hour)))
+}
@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"math"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// latencyWindow is how many recent observations a LatencyTracker keeps.
+const latencyWindow = 10000
+
+// LatencyTracker records operation durations in a fixed-size ring buffer.
+// Record is lock-free, so it is cheap enough to call on every cache
+// operation; readers see a best-effort snapshot of the most recent
+// observations.
+type LatencyTracker struct {
+	next    atomic.Uint64
+	samples [latencyWindow]atomic.Int64
+}
+
+// NewLatencyTracker creates an empty tracker.
+func NewLatencyTracker() *LatencyTracker {
+	return &LatencyTracker{}
+}
+
+// Record adds an observation, overwriting the oldest once the buffer is
+// full.
+func (t *LatencyTracker) Record(d time.Duration) {
+	i := t.next.Add(1) - 1
+	t.samples[i%latencyWindow].Store(int64(d))
+}
+
+// Count returns the number of observations held, at most 10 000.
+func (t *LatencyTracker) Count() int {
+	n := t.next.Load()
+	if n > latencyWindow {
+		return latencyWindow
+	}
+	return int(n)
+}
+
+// Percentile returns the p-th percentile (0-100) of the held observations
+// using the nearest-rank method, or zero when there are none.
+func (t *LatencyTracker) Percentile(p float64) time.Duration {
+	samples := t.snapshot()
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(samples))))
+	rank = max(1, min(rank, len(samples)))
+	return time.Duration(samples[rank-1])
+}
+
+// Mean returns the average of the held observations, or zero when there
+// are none.
+func (t *LatencyTracker) Mean() time.Duration {
+	samples := t.snapshot()
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var total int64
+	for _, s := range samples {
+		total += s
+	}
+	return time.Duration(total / int64(len(samples)))
+}
+
+func (t *LatencyTracker) snapshot() []int64 {
+	samples := make([]int64, t.Count())
+	for i := range samples {
+		samples[i] = t.samples[i].Load()
+	}
+	return samples
+}
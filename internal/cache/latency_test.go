@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyTrackerPercentile(t *testing.T) {
+	tracker := NewLatencyTracker()
+	assert.Zero(t, tracker.Percentile(50))
+	assert.Zero(t, tracker.Mean())
+
+	for i := 1; i <= 100; i++ {
+		tracker.Record(time.Duration(i) * time.Millisecond)
+	}
+
+	assert.Equal(t, 100, tracker.Count())
+	assert.Equal(t, 50*time.Millisecond, tracker.Percentile(50))
+	assert.Equal(t, 95*time.Millisecond, tracker.Percentile(95))
+	assert.Equal(t, 99*time.Millisecond, tracker.Percentile(99))
+	assert.Equal(t, time.Millisecond, tracker.Percentile(0))
+	assert.Equal(t, 100*time.Millisecond, tracker.Percentile(100))
+	assert.Equal(t, 50500*time.Microsecond, tracker.Mean())
+}
+
+func TestLatencyTrackerKeepsRecentObservations(t *testing.T) {
+	tracker := NewLatencyTracker()
+
+	for i := 0; i < latencyWindow; i++ {
+		tracker.Record(time.Second)
+	}
+	for i := 0; i < latencyWindow; i++ {
+		tracker.Record(time.Millisecond)
+	}
+
+	assert.Equal(t, latencyWindow, tracker.Count())
+	assert.Equal(t, time.Millisecond, tracker.Percentile(100))
+}
+
+func TestLatencyTrackerConcurrentRecord(t *testing.T) {
+	tracker := NewLatencyTracker()
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				tracker.Record(time.Millisecond)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 8000, tracker.Count())
+	assert.Equal(t, time.Millisecond, tracker.Percentile(99))
+}
+
+func TestManagerRecordsLatency(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("key", "value", 0))
+	_, err := m.Get("key")
+	require.NoError(t, err)
+	_, err = m.Get("missing")
+	require.Error(t, err)
+
+	assert.Equal(t, 1, m.SetLatency().Count())
+	assert.Equal(t, 2, m.GetLatency().Count())
+	assert.Positive(t, m.GetLatency().Percentile(99))
+}
+
+// BenchmarkLatencyTrackerRecord measures the overhead Record adds to each
+// cache operation; it should stay well under a microsecond.
+func BenchmarkLatencyTrackerRecord(b *testing.B) {
+	tracker := NewLatencyTracker()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			start := time.Now()
+			tracker.Record(time.Since(start))
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// evictionTarget is the fraction of the size limit eviction shrinks the
+// cache to, so a full cache does not evict on every write.
+const evictionTarget = 0.9
+
+// WithMaxSize evicts least-recently-used entries once the cache holds more
+// than maxBytes of values. Zero or less leaves the cache unbounded.
+func WithMaxSize(maxBytes int64) Option {
+	return func(m *Manager) {
+		m.maxSize.Store(maxBytes)
+	}
+}
+
+// SetMaxSize changes the size limit and evicts entries at once if the
+// cache holds more than maxBytes. Zero or less removes the limit.
+func (m *Manager) SetMaxSize(maxBytes int64) error {
+	m.maxSize.Store(maxBytes)
+	return m.evictIfNeeded()
+}
+
+// WatchConfig applies MaxCacheSize changes from changes until the channel
+// is closed or ctx is cancelled.
+func (m *Manager) WatchConfig(ctx context.Context, changes <-chan config.ConfigChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			if !change.Changed("MaxCacheSize") {
+				continue
+			}
+
+			m.logger.Info().
+				Int64("max_size", change.New.MaxCacheSize).
+				Msg("Applying new cache size limit")
+			if err := m.SetMaxSize(change.New.MaxCacheSize); err != nil {
+				m.logger.Error().Err(err).Msg("Failed to apply new cache size limit")
+			}
+		}
+	}
+}
+
+// loadStats counts the entries already stored so size limits hold across
+// restarts, and restores the hit and miss counters saved by Flush.
+func (m *Manager) loadStats() error {
+	var count, size int64
+	err := m.forEachEntry("", func(entry CacheEntry) {
+		count++
+		size += entry.Size
+	})
+	if err != nil {
+		return fmt.Errorf("failed to load cache statistics: %w", err)
+	}
+
+	m.stats.mu.Lock()
+	m.stats.ItemCount = count
+	m.stats.TotalSize = size
+	m.stats.mu.Unlock()
+	return m.loadCounters()
+}
+
+// evictIfNeeded removes the least-recently-used entries, oldest UpdatedAt
+// first, until TotalSize is under 90% of the size limit, and tells other
+// instances about each eviction.
+func (m *Manager) evictIfNeeded() error {
+	maxSize := m.maxSize.Load()
+	if maxSize <= 0 {
+		return nil
+	}
+
+	m.evictMu.Lock()
+	defer m.evictMu.Unlock()
+
+	total := m.GetStats().TotalSize
+	if total <= maxSize {
+		return nil
+	}
+	target := int64(float64(maxSize) * evictionTarget)
+
+	candidates, err := m.evictionCandidates(total - target)
+	if err != nil {
+		return fmt.Errorf("failed to evict cache entries: %w", err)
+	}
+
+	var evicted []string
+	var freed int64
+	for _, key := range candidates {
+		endWrite := m.l1.beginWrite()
+		removed, err := m.backend.Delete(key)
+		m.l1.Remove(key)
+		endWrite()
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				m.logger.Error().Err(err).Str("key", key).Msg("Failed to evict cache entry")
+			}
+			continue
+		}
+		evicted = append(evicted, key)
+		freed += removed.Size
+		m.recordEject(removed.Size)
+	}
+
+	for _, key := range evicted {
+		m.emit(EventDelete, key)
+		m.publishEviction(key)
+	}
+
+	m.logger.Info().
+		Int("count", len(evicted)).
+		Int64("freed_bytes", freed).
+		Int64("max_size", maxSize).
+		Msg("Evicted least-recently-used cache entries")
+	return nil
+}
+
+// evictionCandidates returns the least recently updated keys holding more
+// than excess bytes. Backends with an UpdatedAt index are walked only as
+// far as needed; the others are read and sorted in full.
+func (m *Manager) evictionCandidates(excess int64) ([]string, error) {
+	var keys []string
+	var size int64
+	add := func(key string, entrySize int64) bool {
+		if key == statsKey {
+			return true
+		}
+		keys = append(keys, key)
+		size += entrySize
+		return size <= excess
+	}
+
+	if indexer, ok := m.backend.(recencyIndexer); ok {
+		if err := indexer.AscendUpdated(add); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	}
+
+	var entries []CacheEntry
+	if err := m.forEachEntry("", func(entry CacheEntry) {
+		entries = append(entries, entry)
+	}); err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].UpdatedAt.Before(entries[j].UpdatedAt)
+	})
+	for _, entry := range entries {
+		if !add(entry.Key, entry.Size) {
+			break
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvictLeastRecentlyUsed(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled), WithMaxSize(1000))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, manager.Close())
+	}()
+
+	value := strings.Repeat("x", 100)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, manager.Set(fmt.Sprintf("key-%d", i), value, 0))
+		time.Sleep(time.Millisecond)
+	}
+	stats := manager.GetStats()
+	assert.Equal(t, int64(1000), stats.TotalSize)
+	assert.Zero(t, stats.EjectedCount)
+
+	// Reading key-0 makes key-1 the least recently used
+	_, err = manager.Get("key-0")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return manager.HitsByPrefix("key-0")["key-0"] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Going over the limit evicts the oldest entries until under 90%
+	require.NoError(t, manager.Set("key-10", value, 0))
+
+	stats = manager.GetStats()
+	assert.Equal(t, int64(3), stats.EjectedCount)
+	assert.Equal(t, int64(800), stats.TotalSize)
+	assert.Equal(t, int64(8), stats.ItemCount)
+
+	for _, key := range []string{"key-1", "key-2", "key-3"} {
+		_, err := manager.Get(key)
+		assert.Error(t, err, key)
+	}
+	_, err = manager.Get("key-0")
+	assert.NoError(t, err)
+	for i := 4; i <= 10; i++ {
+		_, err := manager.Get(fmt.Sprintf("key-%d", i))
+		assert.NoError(t, err)
+	}
+}
+
+func TestSizeAccounting(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	require.NoError(t, manager.Set("key", strings.Repeat("x", 100), 0))
+	require.NoError(t, manager.Set("key", strings.Repeat("x", 40), 0))
+	assert.Equal(t, int64(40), manager.GetStats().TotalSize)
+	assert.Equal(t, int64(1), manager.GetStats().ItemCount)
+
+	require.NoError(t, manager.Delete("key"))
+	assert.Zero(t, manager.GetStats().TotalSize)
+	assert.Zero(t, manager.GetStats().ItemCount)
+
+	// Deleting a missing key changes nothing
+	deletes := manager.GetStats().Deletes
+	require.NoError(t, manager.Delete("key"))
+	stats := manager.GetStats()
+	assert.Zero(t, stats.ItemCount)
+	assert.Zero(t, stats.TotalSize)
+	assert.Equal(t, deletes, stats.Deletes)
+}
+
+func TestStatsSurviveRestart(t *testing.T) {
+	dir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	manager, err := NewManager(dir, logger)
+	require.NoError(t, err)
+	require.NoError(t, manager.Set("a", "12345", 0))
+	require.NoError(t, manager.Set("b", "123", 0))
+	require.NoError(t, manager.Close())
+
+	manager, err = NewManager(dir, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, manager.Close())
+	}()
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(2), stats.ItemCount)
+	assert.Equal(t, int64(8), stats.TotalSize)
+}
+
+func TestSetMaxSizeEvictsImmediately(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled), WithMaxSize(1000))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, manager.Close())
+	}()
+
+	value := strings.Repeat("x", 100)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, manager.Set(fmt.Sprintf("key-%d", i), value, 0))
+		time.Sleep(time.Millisecond)
+	}
+
+	// Shrinking the limit evicts down to 90% of it without another write
+	require.NoError(t, manager.SetMaxSize(300))
+	stats := manager.GetStats()
+	assert.Equal(t, int64(200), stats.TotalSize)
+	assert.Equal(t, int64(3), stats.EjectedCount)
+
+	_, err = manager.Get("key-4")
+	assert.NoError(t, err)
+	_, err = manager.Get("key-0")
+	assert.Error(t, err)
+}
@@ -1,13 +1,23 @@
 package cache
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog"
-	"github.com/tidwall/buntdb"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
 )
 
 // CacheEntry represents a cached item.
@@ -19,13 +29,97 @@ type CacheEntry struct {
 	HitCount  int64         `json:"hit_count"`
 	Size      int64         `json:"size"`
 	TTL       time.Duration `json:"ttl"`
+
+	// TokensCached is the estimated number of tokens each hit saves
+	TokensCached int `json:"tokens_cached,omitempty"`
+
+	// TokenSavings counts the tokens reads of an SDK entry saved callers
+	TokenSavings TokenSavings `json:"token_savings"`
+
+	// Compressed entries hold their value gzipped and base64-encoded;
+	// CompressedSize is the length of that encoding. Size is always the
+	// length of the original value.
+	Compressed     bool  `json:"compressed,omitempty"`
+	CompressedSize int64 `json:"compressed_size,omitempty"`
+
+	// Version counts the writes to the key, starting at 1 for a new key.
+	// Backends assign it; SetWithVersion compares against it.
+	Version int64 `json:"version,omitempty"`
 }
 
+// minShrinkFileSize is the database file size below which cleanup does
+// not compact the file automatically.
+const minShrinkFileSize = 1 << 20
+
 // Manager handles all cache operations.
 type Manager struct {
-	db     *buntdb.DB
-	logger zerolog.Logger
-	stats  *Statistics
+	backend  Backend
+	logger   zerolog.Logger
+	stats    *Statistics
+	notifier EvictionNotifier
+	features config.FeatureChecker
+	events   subscribers
+
+	// maxSize bounds TotalSize; zero disables eviction
+	maxSize atomic.Int64
+
+	// compressThreshold is the value length above which values are
+	// gzipped; zero disables compression
+	compressThreshold int64
+	evictMu           sync.Mutex
+
+	// backendName and redisURL choose the Backend NewManager opens
+	backendName string
+	redisURL    string
+
+	// l1 keeps hot entries in memory in front of the backend; nil unless
+	// l1Size is positive and the backend is BuntDB
+	l1Size int
+	l1     *MemoryCache
+
+	getLatency *LatencyTracker
+	setLatency *LatencyTracker
+
+	// collector receives operation metrics; see SetMetricsCollector
+	collector atomic.Pointer[collectorHolder]
+
+	// usage counts hits and misses over time; see SetUsageRecorder
+	usage atomic.Pointer[usageHolder]
+
+	// audit records writes and deletes; see SetAuditRecorder
+	audit atomic.Pointer[auditHolder]
+
+	// done is closed by Close to stop the cleanup routine
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// Option configures a Manager.
+type Option func(*Manager)
+
+// WithEvictionNotifier broadcasts this instance's evictions through n and
+// drops keys evicted by other instances from the local store.
+func WithEvictionNotifier(n EvictionNotifier) Option {
+	return func(m *Manager) {
+		m.notifier = n
+	}
+}
+
+// WithBackend selects the storage backend by name: BackendBuntDB (the
+// default) keeps entries in a file under the cache directory, BackendRedis
+// keeps them in the Redis server at redisURL.
+func WithBackend(name, redisURL string) Option {
+	return func(m *Manager) {
+		m.backendName = name
+		m.redisURL = redisURL
+	}
+}
+
+// WithFeatureFlags makes the manager honour runtime feature flags.
+func WithFeatureFlags(features config.FeatureChecker) Option {
+	return func(m *Manager) {
+		m.features = features
+	}
 }
 
 // Statistics tracks cache performance.
@@ -35,138 +129,454 @@ type Statistics struct {
 	Misses    int64
 	Sets      int64
 	Deletes   int64
+	Touches   int64
 	TotalSize int64
 	ItemCount int64
+
+	// L1Hits and L2Hits split the hits of Get between the memory cache and
+	// the backend
+	L1Hits int64
+	L2Hits int64
+
+	// EjectedCount is how many entries were evicted to stay under the
+	// size limit
+	EjectedCount int64
+
+	// CompressionRatio is the original size of every compressed value
+	// written divided by its compressed size, or zero if none were
+	CompressionRatio float64
+	compressedIn     int64
+	compressedOut    int64
+
+	// LastShrinkAt is when the database file was last shrunk, and
+	// BytesReclaimed how much smaller shrinking has made it in total
+	LastShrinkAt   time.Time
+	BytesReclaimed int64
 }
 
 // NewManager creates a new cache manager.
-func NewManager(cacheDir string, logger zerolog.Logger) (*Manager, error) {
-	dbPath := fmt.Sprintf("%s/cache.db", cacheDir)
+func NewManager(cacheDir string, logger zerolog.Logger, opts ...Option) (*Manager, error) {
+	m := &Manager{
+		logger: logger,
+		stats:  &Statistics{},
+		done:   make(chan struct{}),
 
-	db, err := buntdb.Open(dbPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open cache database: %w", err)
+		getLatency: NewLatencyTracker(),
+		setLatency: NewLatencyTracker(),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
 
-	// Create indexes
-	if err := db.CreateIndex("ttl", "*", buntdb.IndexJSON("updated_at")); err != nil && err != buntdb.ErrIndexExists {
-		return nil, fmt.Errorf("failed to create ttl index: %w", err)
+	// Only file paths are logged; the Redis URL may carry credentials
+	var dbPath string
+	switch m.backendName {
+	case "", BackendBuntDB:
+		m.backendName = BackendBuntDB
+		dbPath = fmt.Sprintf("%s/cache.db", cacheDir)
+		backend, err := NewBuntDBBackend(dbPath)
+		if err != nil {
+			return nil, err
+		}
+		m.backend = backend
+	case BackendRedis:
+		backend, err := NewRedisBackend(m.redisURL)
+		if err != nil {
+			return nil, err
+		}
+		m.backend = backend
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", m.backendName)
 	}
 
-	if err := db.CreateIndex("size", "*", buntdb.IndexJSON("size")); err != nil && err != buntdb.ErrIndexExists {
-		return nil, fmt.Errorf("failed to create size index: %w", err)
+	if m.l1Size > 0 && m.backendName == BackendBuntDB {
+		l1, err := NewMemoryCache(m.l1Size)
+		if err != nil {
+			if closeErr := m.backend.Close(); closeErr != nil {
+				logger.Error().Err(closeErr).Msg("Failed to close cache backend")
+			}
+			return nil, err
+		}
+		m.l1 = l1
 	}
 
-	m := &Manager{
-		db:     db,
-		logger: logger,
-		stats:  &Statistics{},
+	if err := m.init(); err != nil {
+		if closeErr := m.backend.Close(); closeErr != nil {
+			logger.Error().Err(closeErr).Msg("Failed to close cache backend")
+		}
+		return nil, err
 	}
 
 	// Start cleanup routine
 	go m.cleanupRoutine()
 
-	logger.Info().Str("path", dbPath).Msg("Cache manager initialized")
+	logger.Info().
+		Str("backend", m.backendName).
+		Str("path", dbPath).
+		Msg("Cache manager initialized")
 	return m, nil
 }
 
-// Get retrieves a value from the cache.
-func (m *Manager) Get(key string) (string, error) {
-	var value string
-	var entry CacheEntry
+// init loads statistics and hooks expiry and remote eviction notices.
+func (m *Manager) init() error {
+	if err := m.loadStats(); err != nil {
+		return err
+	}
 
-	err := m.db.View(func(tx *buntdb.Tx) error {
-		val, err := tx.Get(key)
-		if err != nil {
+	if expirer, ok := m.backend.(expiryNotifier); ok {
+		if err := expirer.OnExpire(m.handleExpiry); err != nil {
 			return err
 		}
+	}
 
-		if err := json.Unmarshal([]byte(val), &entry); err != nil {
-			return fmt.Errorf("failed to unmarshal cache entry: %w", err)
+	if m.notifier != nil {
+		if err := m.notifier.Subscribe(m.handleRemoteEviction); err != nil {
+			return fmt.Errorf("failed to subscribe to cache evictions: %w", err)
 		}
+	}
+	return nil
+}
 
-		// Check if entry is expired
-		if entry.TTL > 0 && time.Since(entry.UpdatedAt) > entry.TTL {
-			return buntdb.ErrNotFound
-		}
+// tracerName names the tracer of cache operation spans.
+const tracerName = "github.com/ryanrussell/claude-cache-service/internal/cache"
 
-		value = entry.Value
-		return nil
-	})
+// Get retrieves a value from the cache.
+func (m *Manager) Get(key string) (string, error) {
+	return m.GetContext(context.Background(), key)
+}
+
+// GetContext is like Get, recording the read as a span under the trace in
+// ctx, if any.
+func (m *Manager) GetContext(ctx context.Context, key string) (string, error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "cache.Get", trace.WithAttributes(
+		attribute.String("cache.key", key),
+	))
+	defer span.End()
+
+	value, _, err := m.GetWithVersion(key)
+	var miss *apperrors.CacheMissError
+	switch {
+	case errors.As(err, &miss):
+		span.SetAttributes(attribute.Bool("cache.hit", false))
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	default:
+		span.SetAttributes(
+			attribute.Bool("cache.hit", true),
+			attribute.Int("cache.value_size", len(value)),
+		)
+	}
+	return value, err
+}
 
+// GetMulti retrieves several values with a single backend read. Keys that
+// are missing, expired or unreadable are left out of the map, with one
+// error each.
+func (m *Manager) GetMulti(keys []string) (map[string]string, []error) {
+	entries, err := m.backend.GetMulti(keys)
 	if err != nil {
-		if err == buntdb.ErrNotFound {
+		return nil, []error{fmt.Errorf("failed to get keys: %w", err)}
+	}
+
+	now := time.Now()
+	values := make(map[string]string, len(entries))
+	var errs []error
+	var hits []string
+	for _, key := range keys {
+		entry, ok := entries[key]
+		if !ok || entry.expired(now) {
 			m.recordMiss()
-			return "", fmt.Errorf("key not found: %s", key)
+			errs = append(errs, &apperrors.CacheMissError{Key: key})
+			continue
 		}
-		return "", fmt.Errorf("failed to get key: %w", err)
-	}
 
-	// Update hit count
-	go func() {
-		if err := m.incrementHitCount(key); err != nil {
-			m.logger.Error().Err(err).Str("key", key).Msg("Failed to increment hit count")
+		value, err := entry.value()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			continue
 		}
-	}()
+		values[key] = value
+		hits = append(hits, key)
+		m.recordHit(entry)
+	}
 
-	m.recordHit()
-	return value, nil
+	m.trackHits(hits...)
+	return values, errs
+}
+
+// SetOption configures a single Set call.
+type SetOption func(*CacheEntry)
+
+// WithTokensCached records how many Claude tokens each hit on the entry
+// saves.
+func WithTokensCached(tokens int) SetOption {
+	return func(e *CacheEntry) {
+		e.TokensCached = tokens
+	}
+}
+
+// WithTokenHint records the estimated number of input tokens a caller
+// would send Claude to produce the entry, which each read of an SDK entry
+// adds to its TokenSavings.
+func WithTokenHint(tokens int64) SetOption {
+	return func(e *CacheEntry) {
+		e.TokenSavings.EstimatedInputTokens = tokens
+	}
 }
 
 // Set stores a value in the cache.
-func (m *Manager) Set(key, value string, ttl time.Duration) error {
+func (m *Manager) Set(key, value string, ttl time.Duration, opts ...SetOption) error {
+	return m.SetContext(context.Background(), key, value, ttl, opts...)
+}
+
+// SetContext is like Set, recording the write as a span under the trace in
+// ctx, if any.
+func (m *Manager) SetContext(ctx context.Context, key, value string, ttl time.Duration, opts ...SetOption) (err error) {
+	_, span := otel.Tracer(tracerName).Start(ctx, "cache.Set", trace.WithAttributes(
+		attribute.String("cache.key", key),
+		attribute.Int("cache.value_size", len(value)),
+		attribute.Int64("cache.ttl_seconds", int64(ttl/time.Second)),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		m.setLatency.Record(elapsed)
+		m.metrics().ObserveSetLatency(elapsed)
+	}()
+
 	entry := CacheEntry{
-		Key:       key,
-		Value:     value,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-		HitCount:  0,
-		Size:      int64(len(value)),
-		TTL:       ttl,
+		Key:   key,
+		Value: value,
+		TTL:   ttl,
+	}
+	for _, opt := range opts {
+		opt(&entry)
 	}
 
-	data, err := json.Marshal(entry)
+	if err := m.prepareEntry(&entry); err != nil {
+		m.auditRecorder().RecordMutation(EventSet, key, err)
+		return err
+	}
+
+	endWrite := m.l1.beginWrite()
+	previous, err := m.backend.Set(entry)
 	if err != nil {
-		return fmt.Errorf("failed to marshal cache entry: %w", err)
+		endWrite()
+		err = fmt.Errorf("failed to set key: %w", err)
+		m.auditRecorder().RecordMutation(EventSet, key, err)
+		return err
+	}
+	m.l1.Add(memoryEntry(entry, value, nextVersion(previous)))
+	endWrite()
+
+	m.recordWrite(entry, previous)
+	if err := m.evictIfNeeded(); err != nil {
+		m.logger.Error().Err(err).Msg("Failed to evict cache entries")
+	}
+	return nil
+}
+
+// SetMulti stores several entries in a single backend transaction. Only
+// Key, Value, TTL, TokensCached and TokenSavings.EstimatedInputTokens are
+// read from each entry; the rest is filled in as by Set.
+func (m *Manager) SetMulti(entries []CacheEntry) error {
+	if len(entries) == 0 {
+		return nil
 	}
 
-	err = m.db.Update(func(tx *buntdb.Tx) error {
-		opts := &buntdb.SetOptions{}
-		if ttl > 0 {
-			opts.Expires = true
-			opts.TTL = ttl
+	prepared := make([]CacheEntry, len(entries))
+	for i, e := range entries {
+		prepared[i] = CacheEntry{
+			Key:          e.Key,
+			Value:        e.Value,
+			TTL:          e.TTL,
+			TokensCached: e.TokensCached,
+			TokenSavings: TokenSavings{EstimatedInputTokens: e.TokenSavings.EstimatedInputTokens},
+		}
+		if err := m.prepareEntry(&prepared[i]); err != nil {
+			m.auditFailedWrites(prepared[:i+1], err)
+			return err
 		}
+	}
 
-		_, _, err := tx.Set(key, string(data), opts)
+	endWrite := m.l1.beginWrite()
+	previous, err := m.backend.SetMulti(prepared)
+	if err != nil {
+		endWrite()
+		err = fmt.Errorf("failed to set keys: %w", err)
+		m.auditFailedWrites(prepared, err)
 		return err
-	})
+	}
+	for i, entry := range prepared {
+		m.l1.Add(memoryEntry(entry, entries[i].Value, nextVersion(previous[i])))
+	}
+	endWrite()
 
-	if err != nil {
-		return fmt.Errorf("failed to set key: %w", err)
+	for i, entry := range prepared {
+		m.recordWrite(entry, previous[i])
+	}
+	if err := m.evictIfNeeded(); err != nil {
+		m.logger.Error().Err(err).Msg("Failed to evict cache entries")
 	}
+	return nil
+}
 
-	m.recordSet(entry.Size)
+// prepareEntry stamps a new entry and compresses its value if it is over
+// the threshold.
+func (m *Manager) prepareEntry(entry *CacheEntry) error {
+	now := time.Now()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	entry.HitCount = 0
+	entry.TokenSavings.TimesServedFromCache = 0
+	entry.TokenSavings.TotalTokensSaved = 0
+	entry.Size = int64(len(entry.Value))
+
+	if m.compressThreshold > 0 && entry.Size > m.compressThreshold {
+		compressed, err := compressValue(entry.Value)
+		if err != nil {
+			return err
+		}
+		entry.Value = compressed
+		entry.Compressed = true
+		entry.CompressedSize = int64(len(compressed))
+	}
+	return nil
+}
+
+// recordWrite updates statistics and notifies subscribers after entry
+// replaced previous, which is nil for a new key.
+func (m *Manager) recordWrite(entry CacheEntry, previous *CacheEntry) {
+	previousSize := int64(-1)
+	if previous != nil {
+		previousSize = previous.Size
+	}
+	m.recordSet(entry.Size, previousSize)
+	if entry.Compressed {
+		m.recordCompression(entry.Size, entry.CompressedSize)
+	}
+	m.emit(EventSet, entry.Key)
+	m.auditRecorder().RecordMutation(EventSet, entry.Key, nil)
 	m.logger.Debug().
-		Str("key", key).
+		Str("key", entry.Key).
 		Int64("size", entry.Size).
-		Dur("ttl", ttl).
+		Dur("ttl", entry.TTL).
 		Msg("Cache entry set")
+}
 
-	return nil
+// auditFailedWrites records a failed write of each of entries, which were
+// stored together.
+func (m *Manager) auditFailedWrites(entries []CacheEntry, err error) {
+	for _, entry := range entries {
+		m.auditRecorder().RecordMutation(EventSet, entry.Key, err)
+	}
+}
+
+// expired reports whether the entry's TTL had run out at now.
+func (e CacheEntry) expired(now time.Time) bool {
+	return e.TTL > 0 && now.Sub(e.UpdatedAt) > e.TTL
+}
+
+// value returns the entry's original value, decompressing it if needed.
+func (e CacheEntry) value() (string, error) {
+	if !e.Compressed {
+		return e.Value, nil
+	}
+	return decompressValue(e.Value)
+}
+
+// trackHits bumps the hit counts of keys in the background.
+func (m *Manager) trackHits(keys ...string) {
+	if len(keys) == 0 || !config.FeatureEnabled(m.features, config.FlagCacheHitTracking) {
+		return
+	}
+
+	go func() {
+		for _, key := range keys {
+			if err := m.incrementHitCount(key); err != nil {
+				m.logger.Error().Err(err).Str("key", key).Msg("Failed to increment hit count")
+			}
+		}
+	}()
 }
 
 // Delete removes a value from the cache.
 func (m *Manager) Delete(key string) error {
-	err := m.db.Update(func(tx *buntdb.Tx) error {
-		_, err := tx.Delete(key)
+	endWrite := m.l1.beginWrite()
+	entry, err := m.backend.Delete(key)
+	m.l1.Remove(key)
+	endWrite()
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		err = fmt.Errorf("failed to delete key: %w", err)
+		m.auditRecorder().RecordMutation(EventDelete, key, err)
 		return err
-	})
+	}
+
+	if err != nil {
+		// Nothing was deleted, so there is nothing to audit
+		return nil
+	}
+
+	m.recordDelete(entry.Size)
+	m.emit(EventDelete, key)
+	m.auditRecorder().RecordMutation(EventDelete, key, nil)
+	return nil
+}
+
+// Touch restarts the TTL of key from now without changing its value or hit
+// count, setting the TTL to newTTL, or keeping the current one if newTTL is
+// 0. Missing and expired keys return a *apperrors.CacheMissError.
+func (m *Manager) Touch(key string, newTTL time.Duration) error {
+	endWrite := m.l1.beginWrite()
+	_, err := m.backend.Touch(key, newTTL, time.Now())
+	// A shorter TTL must not be outlived by the entry kept in memory
+	m.l1.Remove(key)
+	endWrite()
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &apperrors.CacheMissError{Key: key}
+		}
+		return fmt.Errorf("failed to touch key: %w", err)
+	}
+
+	m.stats.mu.Lock()
+	m.stats.Touches++
+	m.stats.mu.Unlock()
+	return nil
+}
+
+// healthKey is written and removed by CheckHealth.
+const healthKey = "_health"
+
+// CheckHealth verifies the backend can store, read back and delete an entry.
+// It goes to the backend directly, so the probe is not counted in the
+// statistics or sent to subscribers.
+func (m *Manager) CheckHealth() error {
+	value := strconv.FormatInt(time.Now().UnixNano(), 10)
+	if _, err := m.backend.Set(CacheEntry{Key: healthKey, Value: value, TTL: time.Minute, CreatedAt: time.Now(), UpdatedAt: time.Now()}); err != nil {
+		return fmt.Errorf("failed to write health check key: %w", err)
+	}
 
-	if err != nil && err != buntdb.ErrNotFound {
-		return fmt.Errorf("failed to delete key: %w", err)
+	entry, err := m.backend.Get(healthKey)
+	if err != nil {
+		return fmt.Errorf("failed to read health check key: %w", err)
+	}
+	if entry.Value != value {
+		return fmt.Errorf("health check key read back %q, wrote %q", entry.Value, value)
 	}
 
-	m.recordDelete()
+	if _, err := m.backend.Delete(healthKey); err != nil {
+		return fmt.Errorf("failed to delete health check key: %w", err)
+	}
 	return nil
 }
 
@@ -179,126 +589,342 @@ func (m *Manager) GetStats() Statistics {
 		Misses:    m.stats.Misses,
 		Sets:      m.stats.Sets,
 		Deletes:   m.stats.Deletes,
+		Touches:   m.stats.Touches,
 		TotalSize: m.stats.TotalSize,
 		ItemCount: m.stats.ItemCount,
+		L1Hits:    m.stats.L1Hits,
+		L2Hits:    m.stats.L2Hits,
+
+		EjectedCount:     m.stats.EjectedCount,
+		CompressionRatio: m.stats.compressionRatio(),
+
+		LastShrinkAt:   m.stats.LastShrinkAt,
+		BytesReclaimed: m.stats.BytesReclaimed,
 	}
 }
 
-// Close closes the cache database.
-func (m *Manager) Close() error {
-	if err := m.db.Close(); err != nil {
-		return fmt.Errorf("failed to close cache database: %w", err)
+// GetLatency returns the tracker of recent Get durations.
+func (m *Manager) GetLatency() *LatencyTracker {
+	return m.getLatency
+}
+
+// SetLatency returns the tracker of recent Set durations.
+func (m *Manager) SetLatency() *LatencyTracker {
+	return m.setLatency
+}
+
+// ShrinkDB compacts the database file, dropping the space held by deleted
+// and overwritten entries, or returns ErrNotSupported for backends without
+// a local file.
+func (m *Manager) ShrinkDB() error {
+	c, ok := m.backend.(compactor)
+	if !ok {
+		return ErrNotSupported
+	}
+
+	before, err := c.FileSize()
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	if err := c.Shrink(); err != nil {
+		return err
 	}
+	after, err := c.FileSize()
+	if err != nil {
+		return err
+	}
+
+	m.stats.mu.Lock()
+	m.stats.LastShrinkAt = time.Now()
+	m.stats.BytesReclaimed += max(before-after, 0)
+	m.stats.mu.Unlock()
+
+	m.logger.Info().
+		Int64("before_bytes", before).
+		Int64("after_bytes", after).
+		Dur("duration", time.Since(start)).
+		Msg("Cache database compacted")
 	return nil
 }
 
-// Helper methods
+// shrinkIfFragmented compacts the database file once more than half of it
+// is dead space, taking the live entries to need TotalSize bytes. Files
+// under minShrinkFileSize are left alone, since per-entry overhead alone
+// can double the size of a small cache.
+func (m *Manager) shrinkIfFragmented() error {
+	size, err := m.FileSize()
+	if errors.Is(err, ErrNotSupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
 
-func (m *Manager) incrementHitCount(key string) error {
-	return m.db.Update(func(tx *buntdb.Tx) error {
-		val, err := tx.Get(key)
-		if err != nil {
-			return err
-		}
+	live := m.GetStats().TotalSize
+	if size < minShrinkFileSize || size <= 2*live {
+		return nil
+	}
 
-		var entry CacheEntry
-		if err := json.Unmarshal([]byte(val), &entry); err != nil {
-			return err
-		}
+	m.logger.Info().
+		Int64("file_bytes", size).
+		Int64("live_bytes", live).
+		Msg("Cache database is fragmented, compacting")
+	return m.ShrinkDB()
+}
 
-		entry.HitCount++
-		entry.UpdatedAt = time.Now()
+// FileSize returns the size of the database file in bytes, or
+// ErrNotSupported for backends without a local file.
+func (m *Manager) FileSize() (int64, error) {
+	c, ok := m.backend.(compactor)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return c.FileSize()
+}
 
-		data, err := json.Marshal(entry)
-		if err != nil {
-			return err
-		}
+// Close stops the cleanup routine, persists the hit and miss counters and
+// closes the cache backend and every subscription.
+func (m *Manager) Close() error {
+	m.closeOnce.Do(func() { close(m.done) })
+	m.closeSubscriptions()
+	if err := m.Flush(); err != nil {
+		m.logger.Error().Err(err).Msg("Failed to persist cache statistics")
+	}
+	return m.backend.Close()
+}
+
+// Helper methods
 
-		_, _, err = tx.Set(key, string(data), nil)
+// incrementHitCount bumps an entry's hit count and refreshes its UpdatedAt,
+// which also extends its TTL. Concurrent increments may be lost.
+func (m *Manager) incrementHitCount(key string) error {
+	entry, err := m.backend.Get(key)
+	if err != nil {
 		return err
-	})
+	}
+
+	entry.HitCount++
+	entry.UpdatedAt = time.Now()
+	if strings.HasPrefix(key, sdkKeyPrefix) {
+		entry.TokenSavings.TimesServedFromCache++
+		entry.TokenSavings.TotalTokensSaved += entry.TokenSavings.EstimatedInputTokens
+	}
+
+	// A write since the read replaced the entry and reset its hit count
+	err = m.backend.Replace(entry)
+	if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
 }
 
 func (m *Manager) cleanupRoutine() {
 	ticker := time.NewTicker(1 * time.Hour)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		if err := m.cleanup(); err != nil {
-			m.logger.Error().Err(err).Msg("Failed to run cache cleanup")
+	for {
+		select {
+		case <-m.done:
+			return
+		case <-ticker.C:
+			if err := m.cleanup(); err != nil {
+				m.logger.Error().Err(err).Msg("Failed to run cache cleanup")
+			}
+			if err := m.shrinkIfFragmented(); err != nil {
+				m.logger.Error().Err(err).Msg("Failed to compact cache database")
+			}
 		}
 	}
 }
 
 func (m *Manager) cleanup() error {
-	count := 0
-	err := m.db.Update(func(tx *buntdb.Tx) error {
-		now := time.Now()
-		var keysToDelete []string
-
-		err := tx.Ascend("ttl", func(key, value string) bool {
-			var entry CacheEntry
-			if err := json.Unmarshal([]byte(value), &entry); err != nil {
-				return true // Continue iteration
-			}
-
-			if entry.TTL > 0 && now.Sub(entry.UpdatedAt) > entry.TTL {
-				keysToDelete = append(keysToDelete, key)
-			}
-			return true
-		})
-
-		if err != nil {
-			return err
+	now := time.Now()
+	var evicted []string
+	err := m.forEachEntry("", func(entry CacheEntry) {
+		if entry.TTL <= 0 || now.Sub(entry.UpdatedAt) <= entry.TTL {
+			return
 		}
 
-		for _, key := range keysToDelete {
-			if _, err := tx.Delete(key); err != nil {
-				m.logger.Error().Err(err).Str("key", key).Msg("Failed to delete expired key")
-			} else {
-				count++
+		endWrite := m.l1.beginWrite()
+		removed, err := m.backend.Delete(entry.Key)
+		m.l1.Remove(entry.Key)
+		endWrite()
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				m.logger.Error().Err(err).Str("key", entry.Key).Msg("Failed to delete expired key")
 			}
+			return
 		}
-
-		return nil
+		evicted = append(evicted, entry.Key)
+		m.recordExpire(removed.Size)
 	})
 
 	if err != nil {
 		return err
 	}
 
-	if count > 0 {
-		m.logger.Info().Int("count", count).Msg("Cleaned up expired cache entries")
+	if len(evicted) > 0 {
+		m.logger.Info().Int("count", len(evicted)).Msg("Cleaned up expired cache entries")
+	}
+
+	for _, key := range evicted {
+		m.emit(EventExpire, key)
+		m.publishEviction(key)
+	}
+
+	return nil
+}
+
+// forEachEntry calls fn for every readable entry whose key starts with
+// prefix. Keys removed during the walk are skipped.
+func (m *Manager) forEachEntry(prefix string, fn func(CacheEntry)) error {
+	keys, err := m.keys(prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list cache keys: %w", err)
 	}
 
+	for _, key := range keys {
+		entry, err := m.backend.Get(key)
+		if err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				m.logger.Debug().Err(err).Str("key", key).Msg("Skipping unreadable cache entry")
+			}
+			continue
+		}
+		fn(entry)
+	}
 	return nil
 }
 
+// handleExpiry accounts for an entry the backend expired.
+func (m *Manager) handleExpiry(entry CacheEntry) {
+	m.l1.Remove(entry.Key)
+	m.recordExpire(entry.Size)
+	m.emit(EventExpire, entry.Key)
+	go m.publishEviction(entry.Key)
+}
+
+// publishEviction tells other instances that key was evicted here.
+func (m *Manager) publishEviction(key string) {
+	if m.notifier == nil {
+		return
+	}
+	if err := m.notifier.Publish(key); err != nil {
+		m.logger.Error().Err(err).Str("key", key).Msg("Failed to publish cache eviction")
+	}
+}
+
+// handleRemoteEviction drops a key another instance evicted. It does not
+// publish, so notices never echo between instances.
+func (m *Manager) handleRemoteEviction(key string) {
+	endWrite := m.l1.beginWrite()
+	entry, err := m.backend.Delete(key)
+	m.l1.Remove(key)
+	endWrite()
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return
+	case err != nil:
+		m.logger.Error().Err(err).Str("key", key).Msg("Failed to drop remotely evicted key")
+		return
+	}
+
+	m.recordDelete(entry.Size)
+	m.emit(EventDelete, key)
+	m.logger.Debug().Str("key", key).Msg("Dropped key evicted by another instance")
+}
+
 // Statistics helpers
 
-func (m *Manager) recordHit() {
+// compressionRatio must be called with s.mu held.
+func (s *Statistics) compressionRatio() float64 {
+	if s.compressedOut == 0 {
+		return 0
+	}
+	return float64(s.compressedIn) / float64(s.compressedOut)
+}
+
+func (m *Manager) recordHit(entry CacheEntry) {
 	m.stats.mu.Lock()
 	m.stats.Hits++
 	m.stats.mu.Unlock()
+	m.metrics().ObserveHit()
+	m.usageRecorder().RecordHit(int64(entry.TokensCached))
+}
+
+// recordLayerHit counts a hit of Get served by the memory cache, if l1 is
+// set, or by the backend.
+func (m *Manager) recordLayerHit(l1 bool) {
+	m.stats.mu.Lock()
+	if l1 {
+		m.stats.L1Hits++
+	} else {
+		m.stats.L2Hits++
+	}
+	m.stats.mu.Unlock()
 }
 
 func (m *Manager) recordMiss() {
 	m.stats.mu.Lock()
 	m.stats.Misses++
 	m.stats.mu.Unlock()
+	m.metrics().ObserveMiss()
+	m.usageRecorder().RecordMiss()
 }
 
-func (m *Manager) recordSet(size int64) {
+// recordSet counts a write of size bytes. previousSize is the size of the
+// entry it replaced, or -1 for a new key.
+func (m *Manager) recordSet(size, previousSize int64) {
 	m.stats.mu.Lock()
 	m.stats.Sets++
 	m.stats.TotalSize += size
-	m.stats.ItemCount++
+	if previousSize >= 0 {
+		m.stats.TotalSize -= previousSize
+	} else {
+		m.stats.ItemCount++
+	}
+	m.observeSizeLocked()
 	m.stats.mu.Unlock()
+	m.metrics().ObserveSet()
 }
 
-func (m *Manager) recordDelete() {
+func (m *Manager) recordCompression(size, compressedSize int64) {
+	m.stats.mu.Lock()
+	m.stats.compressedIn += size
+	m.stats.compressedOut += compressedSize
+	m.stats.mu.Unlock()
+}
+
+func (m *Manager) recordDelete(size int64) {
 	m.stats.mu.Lock()
 	m.stats.Deletes++
 	m.stats.ItemCount--
+	m.stats.TotalSize -= size
+	m.observeSizeLocked()
+	m.stats.mu.Unlock()
+	m.metrics().ObserveDelete()
+}
+
+func (m *Manager) recordExpire(size int64) {
+	m.stats.mu.Lock()
+	m.stats.ItemCount--
+	m.stats.TotalSize -= size
+	m.observeSizeLocked()
+	m.stats.mu.Unlock()
+}
+
+func (m *Manager) recordEject(size int64) {
+	m.stats.mu.Lock()
+	m.stats.EjectedCount++
+	m.stats.ItemCount--
+	m.stats.TotalSize -= size
+	m.observeSizeLocked()
 	m.stats.mu.Unlock()
 }
+
+// observeSizeLocked reports the current size while stats.mu is held, so
+// concurrent changes reach the collector in order.
+func (m *Manager) observeSizeLocked() {
+	m.metrics().ObserveSize(m.stats.ItemCount, m.stats.TotalSize)
+}
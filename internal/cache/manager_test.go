@@ -1,13 +1,22 @@
 package cache
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/telemetry/telemetrytest"
 )
 
 func TestNewManager(t *testing.T) {
@@ -52,6 +61,11 @@ func TestCacheOperations(t *testing.T) {
 		_, err := manager.Get("non-existent")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "key not found")
+
+		var miss *apperrors.CacheMissError
+		require.True(t, errors.As(err, &miss))
+		assert.Equal(t, "non-existent", miss.Key)
+		assert.ErrorIs(t, err, ErrNotFound)
 	})
 
 	t.Run("Delete Key", func(t *testing.T) {
@@ -142,6 +156,46 @@ func TestCacheStatistics(t *testing.T) {
 	assert.Equal(t, int64(0), stats.ItemCount)
 }
 
+func TestTouch(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "analysis", time.Hour))
+	hit(t, m, "sdk:sentry-go", 3)
+
+	// Age the entry to seconds before it expires
+	entry, err := m.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	entry.UpdatedAt = time.Now().Add(-time.Hour + 5*time.Second)
+	require.NoError(t, m.backend.Replace(entry))
+
+	before := time.Now()
+	require.NoError(t, m.Touch("sdk:sentry-go", 0))
+
+	touched, err := m.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.False(t, touched.UpdatedAt.Before(before), "TTL restarts now")
+	assert.Equal(t, time.Hour, touched.TTL, "TTL is kept")
+	assert.Equal(t, int64(3), touched.HitCount)
+	assert.Equal(t, entry.Version, touched.Version)
+	assert.Equal(t, entry.CreatedAt, touched.CreatedAt)
+
+	require.NoError(t, m.Touch("sdk:sentry-go", 24*time.Hour))
+	touched, err = m.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, touched.TTL)
+
+	value, err := m.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "analysis", value)
+	assert.Equal(t, int64(2), m.GetStats().Touches)
+
+	// Missing keys
+	err = m.Touch("sdk:missing", time.Hour)
+	var miss *apperrors.CacheMissError
+	assert.True(t, errors.As(err, &miss))
+	assert.Equal(t, int64(2), m.GetStats().Touches)
+}
+
 func TestConcurrentAccess(t *testing.T) {
 	tempDir := t.TempDir()
 	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
@@ -182,6 +236,117 @@ func TestConcurrentAccess(t *testing.T) {
 	assert.True(t, stats.Hits+stats.Misses > 0)
 }
 
+func TestStatsPersistAcrossRestarts(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	manager, err := NewManager(tempDir, logger)
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, manager.Set(fmt.Sprintf("key-%d", i), "value", 0))
+	}
+	_, err = manager.Get("key-0")
+	require.NoError(t, err)
+	_, err = manager.Get("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+	before := manager.GetStats()
+	require.NoError(t, manager.Close())
+
+	manager, err = NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, manager.Close())
+	}()
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(5), stats.ItemCount)
+	assert.Equal(t, before.TotalSize, stats.TotalSize)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+
+	// The persisted counters are not a cache entry
+	count, err := manager.CountKeys("")
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+	entries, _, err := manager.ListKeys("_", 0, "")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	deleted, err := manager.DeleteByPrefix("")
+	require.NoError(t, err)
+	assert.Equal(t, 5, deleted)
+	stats = manager.GetStats()
+	assert.Equal(t, int64(0), stats.ItemCount)
+	assert.Equal(t, int64(0), stats.TotalSize)
+	assert.Equal(t, int64(5), stats.Deletes)
+}
+
+func TestShrinkDB(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	manager, err := NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		err := manager.Close()
+		require.NoError(t, err)
+	}()
+
+	value := strings.Repeat("x", 512)
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, manager.Set(fmt.Sprintf("key-%d", i), value, 0))
+	}
+	for i := 0; i < 900; i++ {
+		require.NoError(t, manager.Delete(fmt.Sprintf("key-%d", i)))
+	}
+
+	before, err := manager.FileSize()
+	require.NoError(t, err)
+	assert.True(t, manager.GetStats().LastShrinkAt.IsZero())
+
+	require.NoError(t, manager.ShrinkDB())
+
+	after, err := manager.FileSize()
+	require.NoError(t, err)
+	assert.Less(t, after, before)
+	assert.WithinDuration(t, time.Now(), manager.GetStats().LastShrinkAt, time.Minute)
+	assert.Equal(t, before-after, manager.GetStats().BytesReclaimed)
+
+	// Surviving entries are intact
+	result, err := manager.Get("key-999")
+	require.NoError(t, err)
+	assert.Equal(t, value, result)
+}
+
+func TestShrinkIfFragmented(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	value := strings.Repeat("x", 4096)
+	for i := 0; i < 400; i++ {
+		require.NoError(t, manager.Set(fmt.Sprintf("key-%d", i), value, 0))
+	}
+
+	// A file of mostly live entries is left alone
+	require.NoError(t, manager.shrinkIfFragmented())
+	assert.True(t, manager.GetStats().LastShrinkAt.IsZero())
+
+	for i := 0; i < 300; i++ {
+		require.NoError(t, manager.Delete(fmt.Sprintf("key-%d", i)))
+	}
+	before, err := manager.FileSize()
+	require.NoError(t, err)
+	require.Greater(t, before, 2*manager.GetStats().TotalSize)
+
+	require.NoError(t, manager.shrinkIfFragmented())
+	after, err := manager.FileSize()
+	require.NoError(t, err)
+	assert.Less(t, after, before)
+
+	stats := manager.GetStats()
+	assert.False(t, stats.LastShrinkAt.IsZero())
+	assert.Equal(t, before-after, stats.BytesReclaimed)
+}
+
 func BenchmarkCacheSet(b *testing.B) {
 	tempDir := b.TempDir()
 	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
@@ -220,3 +385,60 @@ func BenchmarkCacheGet(b *testing.B) {
 		_, _ = manager.Get(key)
 	}
 }
+
+func TestCheckHealth(t *testing.T) {
+	manager, err := NewManager(t.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled))
+	require.NoError(t, err)
+
+	require.NoError(t, manager.CheckHealth())
+
+	// The probe leaves nothing behind
+	stats := manager.GetStats()
+	assert.Zero(t, stats.Sets)
+	assert.Zero(t, stats.ItemCount)
+	_, err = manager.backend.Get(healthKey)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, manager.Close())
+	assert.Error(t, manager.CheckHealth())
+}
+
+func TestCacheOperationSpans(t *testing.T) {
+	recorder := telemetrytest.NewRecorder(t)
+	m := newEventsTestManager(t)
+
+	ctx, request := otel.Tracer("test").Start(context.Background(), "request")
+	require.NoError(t, m.SetContext(ctx, "sdk:sentry-go", `{"language":"go"}`, time.Hour))
+	_, err := m.GetContext(ctx, "sdk:sentry-go")
+	require.NoError(t, err)
+	_, err = m.GetContext(ctx, "sdk:sentry-cobol")
+	require.Error(t, err)
+	request.End()
+
+	sets := recorder.Named("cache.Set")
+	require.Len(t, sets, 1)
+	assert.True(t, telemetrytest.ChildOf(sets[0], request.SpanContext()))
+	assert.Equal(t, map[string]any{
+		"cache.key":         "sdk:sentry-go",
+		"cache.value_size":  int64(17),
+		"cache.ttl_seconds": int64(3600),
+	}, telemetrytest.Attributes(sets[0]))
+
+	gets := recorder.Named("cache.Get")
+	require.Len(t, gets, 2)
+	for _, span := range gets {
+		assert.True(t, telemetrytest.ChildOf(span, request.SpanContext()))
+	}
+	assert.Equal(t, map[string]any{
+		"cache.key":        "sdk:sentry-go",
+		"cache.hit":        true,
+		"cache.value_size": int64(17),
+	}, telemetrytest.Attributes(gets[0]))
+	assert.Equal(t, map[string]any{
+		"cache.key": "sdk:sentry-cobol",
+		"cache.hit": false,
+	}, telemetrytest.Attributes(gets[1]))
+
+	// A miss is an answer, not a failed read
+	assert.Equal(t, codes.Unset, gets[1].Status.Code)
+}
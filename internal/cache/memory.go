@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// WithL1CacheSize keeps up to size recently read or written entries in
+// memory in front of the BuntDB backend. Zero or less, or the Redis
+// backend, which other instances may write to, disables the memory cache.
+func WithL1CacheSize(size int) Option {
+	return func(m *Manager) {
+		m.l1Size = size
+	}
+}
+
+// MemoryCache is a fixed-capacity LRU of entries kept in front of the
+// backend, with their values decompressed. A nil MemoryCache holds nothing.
+type MemoryCache struct {
+	entries *lru.Cache[string, CacheEntry]
+
+	// fills keeps a read that missed from adding an entry a concurrent
+	// write to the backend replaced: reads hold it shared while they read
+	// the backend and fill the cache, writes hold it exclusively
+	fills sync.RWMutex
+}
+
+// NewMemoryCache creates an empty memory cache holding up to size entries.
+func NewMemoryCache(size int) (*MemoryCache, error) {
+	entries, err := lru.New[string, CacheEntry](size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create memory cache: %w", err)
+	}
+	return &MemoryCache{entries: entries}, nil
+}
+
+// Get returns the entry of key unless it is missing or had expired at now.
+func (c *MemoryCache) Get(key string, now time.Time) (CacheEntry, bool) {
+	if c == nil {
+		return CacheEntry{}, false
+	}
+
+	entry, ok := c.entries.Get(key)
+	if !ok {
+		return CacheEntry{}, false
+	}
+	if entry.expired(now) {
+		c.entries.Remove(key)
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Add stores entry, whose value must not be compressed, evicting the least
+// recently used entry if the cache is full.
+func (c *MemoryCache) Add(entry CacheEntry) {
+	if c == nil {
+		return
+	}
+	c.entries.Add(entry.Key, entry)
+}
+
+// Remove drops keys from the cache.
+func (c *MemoryCache) Remove(keys ...string) {
+	if c == nil {
+		return
+	}
+	for _, key := range keys {
+		c.entries.Remove(key)
+	}
+}
+
+// RemovePrefix drops every key starting with prefix.
+func (c *MemoryCache) RemovePrefix(prefix string) {
+	if c == nil {
+		return
+	}
+	for _, key := range c.entries.Keys() {
+		if strings.HasPrefix(key, prefix) {
+			c.entries.Remove(key)
+		}
+	}
+}
+
+// Len returns the number of entries in the cache.
+func (c *MemoryCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.entries.Len()
+}
+
+// beginFill is called before a read that missed reads the backend, and the
+// returned function once it has added what it read.
+func (c *MemoryCache) beginFill() func() {
+	if c == nil {
+		return func() {}
+	}
+	c.fills.RLock()
+	return c.fills.RUnlock
+}
+
+// beginWrite is called before writing to the backend, and the returned
+// function once the cache has been updated to match.
+func (c *MemoryCache) beginWrite() func() {
+	if c == nil {
+		return func() {}
+	}
+	c.fills.Lock()
+	return c.fills.Unlock
+}
+
+// memoryEntry returns entry as the memory cache keeps it: with its original
+// value and the version the backend gave it.
+func memoryEntry(entry CacheEntry, value string, version int64) CacheEntry {
+	entry.Value = value
+	entry.Compressed = false
+	entry.CompressedSize = 0
+	entry.Version = version
+	return entry
+}
+
+// nextVersion returns the version of an entry written over previous, which
+// is nil for a new key.
+func nextVersion(previous *CacheEntry) int64 {
+	if previous == nil {
+		return 1
+	}
+	return previous.Version + 1
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+func newL1Manager(tb testing.TB, size int, opts ...Option) *Manager {
+	tb.Helper()
+
+	opts = append(opts, WithL1CacheSize(size))
+	manager, err := NewManager(tb.TempDir(), zerolog.New(os.Stderr).Level(zerolog.Disabled), opts...)
+	require.NoError(tb, err)
+	tb.Cleanup(func() {
+		require.NoError(tb, manager.Close())
+	})
+	return manager
+}
+
+func TestMemoryCache(t *testing.T) {
+	c, err := NewMemoryCache(2)
+	require.NoError(t, err)
+	now := time.Now()
+
+	c.Add(CacheEntry{Key: "sdk:sentry-go", Value: "go", UpdatedAt: now})
+	c.Add(CacheEntry{Key: "sdk:sentry-python", Value: "python", UpdatedAt: now})
+	_, ok := c.Get("sdk:sentry-go", now)
+	require.True(t, ok)
+
+	// The least recently used entry makes room
+	c.Add(CacheEntry{Key: "project:gui", Value: "gui", UpdatedAt: now})
+	assert.Equal(t, 2, c.Len())
+	_, ok = c.Get("sdk:sentry-python", now)
+	assert.False(t, ok)
+
+	c.RemovePrefix("sdk:")
+	_, ok = c.Get("sdk:sentry-go", now)
+	assert.False(t, ok)
+	assert.Equal(t, 1, c.Len())
+
+	c.Add(CacheEntry{Key: "ttl", Value: "v", UpdatedAt: now, TTL: time.Minute})
+	_, ok = c.Get("ttl", now.Add(30*time.Second))
+	assert.True(t, ok)
+	_, ok = c.Get("ttl", now.Add(2*time.Minute))
+	assert.False(t, ok, "expired entries are not returned")
+
+	var disabled *MemoryCache
+	disabled.Add(CacheEntry{Key: "k"})
+	_, ok = disabled.Get("k", now)
+	assert.False(t, ok)
+	assert.Zero(t, disabled.Len())
+
+	_, err = NewMemoryCache(0)
+	assert.Error(t, err)
+}
+
+func TestL1Cache(t *testing.T) {
+	m := newL1Manager(t, 10, WithCompressThreshold(16))
+	value := strings.Repeat("sentry ", 10)
+
+	// Writes go to both layers, so the next read is served from memory
+	require.NoError(t, m.Set("sdk:sentry-go", value, time.Hour))
+	got, err := m.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, value, got)
+	stats := m.GetStats()
+	assert.Equal(t, int64(1), stats.L1Hits)
+	assert.Zero(t, stats.L2Hits)
+
+	// A read from the backend decompresses the value and promotes it
+	m.l1.Remove("sdk:sentry-go")
+	for range 2 {
+		got, err = m.Get("sdk:sentry-go")
+		require.NoError(t, err)
+		assert.Equal(t, value, got)
+	}
+	stats = m.GetStats()
+	assert.Equal(t, int64(2), stats.L1Hits)
+	assert.Equal(t, int64(1), stats.L2Hits)
+	assert.Equal(t, int64(3), stats.Hits)
+
+	// Versions read from memory match the backend's
+	_, version, err := m.GetWithVersion("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+	version, err = m.SetWithVersion("sdk:sentry-go", "v2", time.Hour, version)
+	require.NoError(t, err)
+	got, cachedVersion, err := m.GetWithVersion("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", got)
+	assert.Equal(t, version, cachedVersion)
+
+	// A shorter TTL is not outlived by the copy in memory
+	require.NoError(t, m.Touch("sdk:sentry-go", time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, err = m.Get("sdk:sentry-go")
+	var miss *apperrors.CacheMissError
+	assert.ErrorAs(t, err, &miss)
+
+	// Deletes remove keys from both layers
+	require.NoError(t, m.SetMulti([]CacheEntry{
+		{Key: "sdk:sentry-python", Value: "python"},
+		{Key: "sdk:sentry-ruby", Value: "ruby"},
+		{Key: "project:gui", Value: "gui"},
+	}))
+	assert.Equal(t, 3, m.l1.Len())
+	require.NoError(t, m.Delete("project:gui"))
+	_, err = m.Get("project:gui")
+	assert.ErrorAs(t, err, &miss)
+	_, err = m.DeleteByPrefix("sdk:")
+	require.NoError(t, err)
+	_, err = m.Get("sdk:sentry-python")
+	assert.ErrorAs(t, err, &miss)
+	assert.Zero(t, m.l1.Len())
+}
+
+func TestL1CacheDisabled(t *testing.T) {
+	assert.Nil(t, newL1Manager(t, 0).l1)
+
+	redis := miniredis.RunT(t)
+	m := newL1Manager(t, 10, WithBackend(BackendRedis, "redis://"+redis.Addr()))
+	assert.Nil(t, m.l1, "other instances may write to Redis")
+
+	require.NoError(t, m.Set("key", "value", 0))
+	_, err := m.Get("key")
+	require.NoError(t, err)
+	stats := m.GetStats()
+	assert.Zero(t, stats.L1Hits)
+	assert.Equal(t, int64(1), stats.L2Hits)
+}
+
+// noHitTracking disables per-entry hit counts, which update the backend in
+// the background on every read.
+type noHitTracking struct{}
+
+func (noHitTracking) IsEnabled(flag string) bool {
+	return flag != config.FlagCacheHitTracking
+}
+
+// BenchmarkGetLayers reads a hot SDK analysis from the memory cache and,
+// with it disabled, from BuntDB.
+func BenchmarkGetLayers(b *testing.B) {
+	value := strings.Repeat(`{"language":"go","features":["breadcrumbs"]}`, 50)
+	for name, size := range map[string]int{"l1": 1000, "l2": 0} {
+		b.Run(name, func(b *testing.B) {
+			m := newL1Manager(b, size, WithFeatureFlags(noHitTracking{}))
+			require.NoError(b, m.Set("sdk:sentry-go", value, 0))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := m.Get("sdk:sentry-go"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cache
+
+import "time"
+
+// MetricsCollector observes cache operations, letting callers export
+// metrics without the cache depending on a metrics library. Methods are
+// called synchronously, some while statistics are locked, so they must be
+// fast and must not call back into the Manager.
+type MetricsCollector interface {
+	ObserveHit()
+	ObserveMiss()
+	ObserveSet()
+	ObserveDelete()
+	ObserveGetLatency(d time.Duration)
+	ObserveSetLatency(d time.Duration)
+	// ObserveSize reports the item count and total size after a change
+	ObserveSize(itemCount, totalSize int64)
+}
+
+// collectorHolder lets a MetricsCollector be swapped atomically.
+type collectorHolder struct {
+	MetricsCollector
+}
+
+// SetMetricsCollector sends every later cache operation to c and reports
+// the current size to it. It is safe to call while the cache is in use.
+func (m *Manager) SetMetricsCollector(c MetricsCollector) {
+	m.stats.mu.Lock()
+	defer m.stats.mu.Unlock()
+
+	m.collector.Store(&collectorHolder{c})
+	c.ObserveSize(m.stats.ItemCount, m.stats.TotalSize)
+}
+
+// metrics returns the current collector, or one that discards everything.
+func (m *Manager) metrics() MetricsCollector {
+	if h := m.collector.Load(); h != nil {
+		return h.MetricsCollector
+	}
+	return nopCollector{}
+}
+
+type nopCollector struct{}
+
+func (nopCollector) ObserveHit()                     {}
+func (nopCollector) ObserveMiss()                    {}
+func (nopCollector) ObserveSet()                     {}
+func (nopCollector) ObserveDelete()                  {}
+func (nopCollector) ObserveGetLatency(time.Duration) {}
+func (nopCollector) ObserveSetLatency(time.Duration) {}
+func (nopCollector) ObserveSize(int64, int64)        {}
+
+// UsageRecorder counts cache lookups over time, such as for usage
+// analytics. Like MetricsCollector, its methods are called synchronously
+// and must be fast.
+type UsageRecorder interface {
+	// RecordHit counts a hit on an entry that saves tokensSaved tokens
+	RecordHit(tokensSaved int64)
+	RecordMiss()
+}
+
+// usageHolder lets a UsageRecorder be swapped atomically.
+type usageHolder struct {
+	UsageRecorder
+}
+
+// SetUsageRecorder sends every later cache hit and miss to r. It is safe
+// to call while the cache is in use.
+func (m *Manager) SetUsageRecorder(r UsageRecorder) {
+	m.usage.Store(&usageHolder{r})
+}
+
+// usageRecorder returns the current recorder, or one that discards
+// everything.
+func (m *Manager) usageRecorder() UsageRecorder {
+	if h := m.usage.Load(); h != nil {
+		return h.UsageRecorder
+	}
+	return nopUsageRecorder{}
+}
+
+type nopUsageRecorder struct{}
+
+func (nopUsageRecorder) RecordHit(int64) {}
+func (nopUsageRecorder) RecordMiss()     {}
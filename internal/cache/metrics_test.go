@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCollector records every observation it receives.
+type countingCollector struct {
+	mu                          sync.Mutex
+	hits, misses, sets, deletes int
+	getLatencies, setLatencies  int
+	itemCount, totalSize        int64
+}
+
+func (c *countingCollector) ObserveHit()    { c.mu.Lock(); c.hits++; c.mu.Unlock() }
+func (c *countingCollector) ObserveMiss()   { c.mu.Lock(); c.misses++; c.mu.Unlock() }
+func (c *countingCollector) ObserveSet()    { c.mu.Lock(); c.sets++; c.mu.Unlock() }
+func (c *countingCollector) ObserveDelete() { c.mu.Lock(); c.deletes++; c.mu.Unlock() }
+
+func (c *countingCollector) ObserveGetLatency(time.Duration) {
+	c.mu.Lock()
+	c.getLatencies++
+	c.mu.Unlock()
+}
+
+func (c *countingCollector) ObserveSetLatency(time.Duration) {
+	c.mu.Lock()
+	c.setLatencies++
+	c.mu.Unlock()
+}
+
+func (c *countingCollector) ObserveSize(itemCount, totalSize int64) {
+	c.mu.Lock()
+	c.itemCount, c.totalSize = itemCount, totalSize
+	c.mu.Unlock()
+}
+
+func TestMetricsCollector(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	// Entries written before the collector is attached count toward size
+	require.NoError(t, manager.Set("existing", "1234", 0))
+
+	collector := &countingCollector{}
+	manager.SetMetricsCollector(collector)
+	assert.Equal(t, int64(1), collector.itemCount)
+	assert.Equal(t, int64(4), collector.totalSize)
+
+	require.NoError(t, manager.Set("key", "value", 0))
+	_, err := manager.Get("key")
+	require.NoError(t, err)
+	_, err = manager.Get("missing")
+	require.Error(t, err)
+	require.NoError(t, manager.Delete("existing"))
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	assert.Equal(t, 1, collector.hits)
+	assert.Equal(t, 1, collector.misses)
+	assert.Equal(t, 1, collector.sets)
+	assert.Equal(t, 1, collector.deletes)
+	assert.Equal(t, 2, collector.getLatencies)
+	assert.Equal(t, 1, collector.setLatencies)
+	assert.Equal(t, int64(1), collector.itemCount)
+	assert.Equal(t, int64(5), collector.totalSize)
+}
+
+// countingUsageRecorder records every hit and miss it receives.
+type countingUsageRecorder struct {
+	mu           sync.Mutex
+	hits, misses int
+	tokensSaved  int64
+}
+
+func (r *countingUsageRecorder) RecordHit(tokensSaved int64) {
+	r.mu.Lock()
+	r.hits++
+	r.tokensSaved += tokensSaved
+	r.mu.Unlock()
+}
+
+func (r *countingUsageRecorder) RecordMiss() { r.mu.Lock(); r.misses++; r.mu.Unlock() }
+
+func TestUsageRecorder(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	recorder := &countingUsageRecorder{}
+	manager.SetUsageRecorder(recorder)
+
+	require.NoError(t, manager.Set("sdk:sentry-go", "{}", 0, WithTokensCached(1200)))
+	require.NoError(t, manager.Set("plain", "value", 0))
+
+	_, err := manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	_, errs := manager.GetMulti([]string{"sdk:sentry-go", "plain", "missing"})
+	require.Len(t, errs, 1)
+	_, err = manager.Get("missing")
+	require.Error(t, err)
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	assert.Equal(t, 3, recorder.hits)
+	assert.Equal(t, 2, recorder.misses)
+	assert.Equal(t, int64(2400), recorder.tokensSaved)
+}
@@ -0,0 +1,118 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMultiGetMulti(t *testing.T) {
+	manager := newCompressingManager(t, 100)
+
+	large := strings.Repeat("analysis ", 100)
+	require.NoError(t, manager.SetMulti([]CacheEntry{
+		{Key: "sdk:sentry-go", Value: large, TTL: time.Hour, TokensCached: 500},
+		{Key: "sdk:sentry-python", Value: "small", TTL: time.Hour},
+		{Key: "expired", Value: "gone", TTL: time.Nanosecond},
+	}))
+	time.Sleep(time.Millisecond)
+
+	values, errs := manager.GetMulti([]string{"sdk:sentry-go", "sdk:sentry-python", "expired", "missing"})
+	assert.Equal(t, map[string]string{
+		"sdk:sentry-go":     large,
+		"sdk:sentry-python": "small",
+	}, values)
+	require.Len(t, errs, 2)
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrNotFound)
+	}
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(3), stats.Sets)
+	assert.Equal(t, int64(2), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, int64(len(large)+len("small")+len("gone")), stats.TotalSize)
+
+	stored, err := manager.backend.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.True(t, stored.Compressed)
+	assert.Equal(t, 500, stored.TokensCached)
+}
+
+func TestSetMultiEmpty(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	require.NoError(t, manager.SetMulti(nil))
+	assert.Zero(t, manager.GetStats().Sets)
+}
+
+const batchSize = 100
+
+func benchmarkEntries() []CacheEntry {
+	entries := make([]CacheEntry, batchSize)
+	for i := range entries {
+		entries[i] = CacheEntry{
+			Key:   fmt.Sprintf("sdk:bench-%d", i),
+			Value: "bench-value",
+			TTL:   time.Hour,
+		}
+	}
+	return entries
+}
+
+// benchmarkManagers opens a manager on each backend for the batch
+// benchmarks. Redis is served by miniredis, so each round trip stays local.
+func benchmarkManagers(b *testing.B) map[string]*Manager {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+	redisURL := "redis://" + miniredis.RunT(b).Addr()
+
+	managers := make(map[string]*Manager)
+	for _, backend := range []string{BackendBuntDB, BackendRedis} {
+		manager, err := NewManager(b.TempDir(), logger, WithBackend(backend, redisURL))
+		require.NoError(b, err)
+		b.Cleanup(func() {
+			if err := manager.Close(); err != nil {
+				b.Error(err)
+			}
+		})
+		managers[backend] = manager
+	}
+	return managers
+}
+
+// BenchmarkSetIndividual writes 100 entries with one Set call each.
+func BenchmarkSetIndividual(b *testing.B) {
+	entries := benchmarkEntries()
+	for name, manager := range benchmarkManagers(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, entry := range entries {
+					if err := manager.Set(entry.Key, entry.Value, entry.TTL); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkSetMulti writes the same 100 entries in one SetMulti call.
+func BenchmarkSetMulti(b *testing.B) {
+	entries := benchmarkEntries()
+	for name, manager := range benchmarkManagers(b) {
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := manager.SetMulti(entries); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,373 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces cache entries within the Redis keyspace.
+const redisKeyPrefix = "cache:entry:"
+
+// redisScanCount is the COUNT hint passed to SCAN.
+const redisScanCount = 1000
+
+// redisTxRetries bounds how often a write is retried after another client
+// changed one of its keys mid-transaction.
+const redisTxRetries = 10
+
+// RedisBackend stores entries as JSON strings in Redis, letting Redis
+// expire them with EXPIREAT.
+type RedisBackend struct {
+	client *redis.Client
+}
+
+// NewRedisBackend connects to the Redis server at a URL such as
+// redis://host:6379/0.
+func NewRedisBackend(redisURL string) (*RedisBackend, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
+	}
+
+	client := redis.NewClient(opts)
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			err = fmt.Errorf("%w (close: %v)", err, closeErr)
+		}
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	return &RedisBackend{client: client}, nil
+}
+
+// Get returns the entry stored under key.
+func (b *RedisBackend) Get(key string) (CacheEntry, error) {
+	val, err := b.client.Get(context.Background(), redisKeyPrefix+key).Result()
+	if err == redis.Nil {
+		return CacheEntry{}, ErrNotFound
+	}
+	if err != nil {
+		return CacheEntry{}, fmt.Errorf("failed to get key from redis: %w", err)
+	}
+
+	var entry CacheEntry
+	if err := decodeEntry(val, &entry); err != nil {
+		return CacheEntry{}, err
+	}
+	return entry, nil
+}
+
+// Set stores entry, setting EXPIREAT in the same transaction when it has a
+// TTL. A plain SET clears any expiry the replaced entry had.
+func (b *RedisBackend) Set(entry CacheEntry) (*CacheEntry, error) {
+	previous, err := b.SetMulti([]CacheEntry{entry})
+	if err != nil {
+		return nil, err
+	}
+	return previous[0], nil
+}
+
+// GetMulti returns the entries stored under keys with a single MGET.
+func (b *RedisBackend) GetMulti(keys []string) (map[string]CacheEntry, error) {
+	entries := make(map[string]CacheEntry, len(keys))
+	if len(keys) == 0 {
+		return entries, nil
+	}
+
+	prefixed := make([]string, len(keys))
+	for i, key := range keys {
+		prefixed[i] = redisKeyPrefix + key
+	}
+
+	values, err := b.client.MGet(context.Background(), prefixed...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys from redis: %w", err)
+	}
+
+	for i, value := range values {
+		val, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var entry CacheEntry
+		if err := decodeEntry(val, &entry); err != nil {
+			return nil, err
+		}
+		entries[keys[i]] = entry
+	}
+	return entries, nil
+}
+
+// SetMulti stores entries in one MULTI/EXEC transaction, like Set.
+func (b *RedisBackend) SetMulti(entries []CacheEntry) ([]*CacheEntry, error) {
+	return b.setVersioned(entries, nil)
+}
+
+// SetIfVersion stores entry, like Set, if its key is at version expected.
+func (b *RedisBackend) SetIfVersion(entry CacheEntry, expected int64) (*CacheEntry, error) {
+	previous, err := b.setVersioned([]CacheEntry{entry}, &expected)
+	if err != nil {
+		return nil, err
+	}
+	return previous[0], nil
+}
+
+// Replace overwrites entry if its key is still at entry.Version, checking
+// under WATCH like setVersioned.
+func (b *RedisBackend) Replace(entry CacheEntry) error {
+	ctx := context.Background()
+	key := redisKeyPrefix + entry.Key
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+
+	err = b.client.Watch(ctx, func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get key from redis: %w", err)
+		}
+		if version := replacedEntry(entry.Key, val).Version; version != entry.Version {
+			return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, entry.Key, version, entry.Version)
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			writeEntry(ctx, pipe, key, data, entry)
+			return nil
+		})
+		return err
+	}, key)
+	// A write between WATCH and EXEC changed the version too
+	if err == redis.TxFailedErr {
+		return fmt.Errorf("%w: %s was written concurrently", ErrVersionConflict, entry.Key)
+	}
+	if err != nil && !errors.Is(err, ErrVersionConflict) && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to replace key in redis: %w", err)
+	}
+	return err
+}
+
+// Touch restarts the TTL of key, reading it under WATCH and writing it in
+// a MULTI/EXEC transaction. The transaction is retried if another client
+// writes the key first.
+func (b *RedisBackend) Touch(key string, ttl time.Duration, now time.Time) (CacheEntry, error) {
+	ctx := context.Background()
+	redisKey := redisKeyPrefix + key
+
+	var entry CacheEntry
+	txn := func(tx *redis.Tx) error {
+		val, err := tx.Get(ctx, redisKey).Result()
+		if err == redis.Nil {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get key from redis: %w", err)
+		}
+		entry = CacheEntry{}
+		if err := decodeEntry(val, &entry); err != nil {
+			return err
+		}
+		if entry.expired(now) {
+			return ErrNotFound
+		}
+
+		touchEntry(&entry, ttl, now)
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal cache entry: %w", err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			writeEntry(ctx, pipe, redisKey, data, entry)
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < redisTxRetries; attempt++ {
+		err := b.client.Watch(ctx, txn, redisKey)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if errors.Is(err, ErrNotFound) {
+			return CacheEntry{}, err
+		}
+		if err != nil {
+			return CacheEntry{}, fmt.Errorf("failed to touch key in redis: %w", err)
+		}
+		return entry, nil
+	}
+	return CacheEntry{}, fmt.Errorf("failed to touch key in redis: key changed during %d attempts", redisTxRetries)
+}
+
+// setVersioned reads the versions of the entries' keys and writes the
+// entries in a MULTI/EXEC transaction under WATCH, so the versions cannot
+// change in between. The transaction is retried if another client writes
+// one of the keys first. With expected set, a single entry is only written
+// if its key is at that version.
+func (b *RedisBackend) setVersioned(entries []CacheEntry, expected *int64) ([]*CacheEntry, error) {
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = redisKeyPrefix + entry.Key
+	}
+
+	ctx := context.Background()
+	var previous []*CacheEntry
+	txn := func(tx *redis.Tx) error {
+		values, err := tx.MGet(ctx, keys...).Result()
+		if err != nil {
+			return fmt.Errorf("failed to get keys from redis: %w", err)
+		}
+
+		previous = make([]*CacheEntry, len(entries))
+		versions := make(map[string]int64, len(entries))
+		data := make([][]byte, len(entries))
+		for i, entry := range entries {
+			version, seen := versions[entry.Key]
+			if val, ok := values[i].(string); ok && !seen {
+				previous[i] = replacedEntry(entry.Key, val)
+				version = previous[i].Version
+			}
+			if expected != nil && version != *expected {
+				return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, entry.Key, version, *expected)
+			}
+
+			entry.Version = version + 1
+			versions[entry.Key] = entry.Version
+			data[i], err = json.Marshal(entry)
+			if err != nil {
+				return fmt.Errorf("failed to marshal cache entry: %w", err)
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, entry := range entries {
+				writeEntry(ctx, pipe, keys[i], data[i], entry)
+			}
+			return nil
+		})
+		return err
+	}
+
+	for attempt := 0; attempt < redisTxRetries; attempt++ {
+		err := b.client.Watch(ctx, txn, keys...)
+		if err == redis.TxFailedErr {
+			continue
+		}
+		if errors.Is(err, ErrVersionConflict) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to set keys in redis: %w", err)
+		}
+		return previous, nil
+	}
+	return nil, fmt.Errorf("failed to set keys in redis: keys changed during %d attempts", redisTxRetries)
+}
+
+// writeEntry queues the commands storing an encoded entry under key. A
+// plain SET clears any expiry the replaced entry had, so EXPIREAT follows
+// it for entries with a TTL.
+func writeEntry(ctx context.Context, pipe redis.Pipeliner, key string, data []byte, entry CacheEntry) {
+	pipe.Set(ctx, key, data, 0)
+	if entry.TTL > 0 {
+		pipe.ExpireAt(ctx, key, entry.UpdatedAt.Add(entry.TTL))
+	}
+}
+
+// Delete removes key and returns the removed entry.
+func (b *RedisBackend) Delete(key string) (CacheEntry, error) {
+	val, err := b.client.GetDel(context.Background(), redisKeyPrefix+key).Result()
+	if err == redis.Nil {
+		return CacheEntry{}, ErrNotFound
+	}
+	if err != nil {
+		return CacheEntry{}, fmt.Errorf("failed to delete key from redis: %w", err)
+	}
+	return *replacedEntry(key, val), nil
+}
+
+// DeletePrefix removes every key starting with prefix. The keys are found
+// with SCAN and removed in one MULTI/EXEC transaction, so keys written
+// during the scan may survive.
+func (b *RedisBackend) DeletePrefix(prefix string) ([]CacheEntry, error) {
+	keys, err := b.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	dels := make([]*redis.StringCmd, len(keys))
+	_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			dels[i] = pipe.GetDel(ctx, redisKeyPrefix+key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to delete keys from redis: %w", err)
+	}
+
+	var removed []CacheEntry
+	for i, del := range dels {
+		val, err := del.Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete key from redis: %w", err)
+		}
+		removed = append(removed, *replacedEntry(keys[i], val))
+	}
+	return removed, nil
+}
+
+// Keys returns every stored key starting with prefix, using SCAN so large
+// keyspaces do not block the server.
+func (b *RedisBackend) Keys(prefix string) ([]string, error) {
+	ctx := context.Background()
+	pattern := redisKeyPrefix + escapeGlob(prefix) + "*"
+
+	var keys []string
+	iter := b.client.Scan(ctx, 0, pattern, redisScanCount).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, strings.TrimPrefix(iter.Val(), redisKeyPrefix))
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan redis keys: %w", err)
+	}
+	return keys, nil
+}
+
+// Close closes the Redis client.
+func (b *RedisBackend) Close() error {
+	if err := b.client.Close(); err != nil {
+		return fmt.Errorf("failed to close redis client: %w", err)
+	}
+	return nil
+}
+
+// escapeGlob escapes the characters Redis treats as glob syntax in MATCH
+// patterns.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// lastAnalyzedSuffix names the key recording when an SDK was last analyzed,
+// which shares the sdk:<name>: prefix with its versioned analyses.
+const lastAnalyzedSuffix = "last_analyzed"
+
+// GetVersions returns the versions of sdkName's analysis that are cached
+// under sdk:<name>:<version>, most recently stored first. Reading or
+// touching a version does not change its place.
+func (m *Manager) GetVersions(sdkName string) ([]string, error) {
+	entries, err := m.VersionEntries(sdkName)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := sdkKeyPrefix + sdkName + ":"
+	versions := make([]string, len(entries))
+	for i, entry := range entries {
+		versions[i] = strings.TrimPrefix(entry.Key, prefix)
+	}
+	return versions, nil
+}
+
+// VersionEntries is like GetVersions but returns the cache entries of the
+// versions, with their values left empty.
+func (m *Manager) VersionEntries(sdkName string) ([]CacheEntry, error) {
+	prefix := sdkKeyPrefix + sdkName + ":"
+	keys, err := m.keys(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cache keys: %w", err)
+	}
+
+	// Keys nested deeper than sdk:<name>:<version>, such as the analyzer's
+	// per-commit entries, are not versions
+	keys = slices.DeleteFunc(keys, func(key string) bool {
+		version := strings.TrimPrefix(key, prefix)
+		return version == "" || version == lastAnalyzedSuffix || strings.Contains(version, ":")
+	})
+
+	entries, err := m.backend.GetMulti(keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache keys: %w", err)
+	}
+
+	now := time.Now()
+	versions := make([]CacheEntry, 0, len(keys))
+	for _, key := range keys {
+		entry, ok := entries[key]
+		if !ok || entry.expired(now) {
+			continue
+		}
+		entry.Value = ""
+		versions = append(versions, entry)
+	}
+
+	// CreatedAt is set by each write; hits and touches move UpdatedAt
+	sort.Slice(versions, func(i, j int) bool {
+		if !versions[i].CreatedAt.Equal(versions[j].CreatedAt) {
+			return versions[i].CreatedAt.After(versions[j].CreatedAt)
+		}
+		return versions[i].Key > versions[j].Key
+	})
+	return versions, nil
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVersions(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	for _, version := range []string{"1.0.0", "0.9.0", "1.1.0"} {
+		require.NoError(t, manager.Set("sdk:sentry-go:"+version, `{"language":"go"}`, 0))
+		time.Sleep(2 * time.Millisecond)
+	}
+	require.NoError(t, manager.Set("sdk:sentry-go", `{"language":"go"}`, 0))
+	require.NoError(t, manager.Set("sdk:sentry-go:last_analyzed", time.Now().Format(time.RFC3339), 0))
+	require.NoError(t, manager.Set("sdk:sentry-go:commit:abc123:0123456789abcdef", `{"language":"go"}`, 0))
+	require.NoError(t, manager.Set("sdk:sentry-go-extra:2.0.0", `{"language":"go"}`, 0))
+	require.NoError(t, manager.Set("sdk:sentry-go:0.1.0", `{"language":"go"}`, time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+
+	versions, err := manager.GetVersions("sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1.0", "0.9.0", "1.0.0"}, versions)
+
+	// Reading or touching an older version keeps the order
+	_, err = manager.Get("sdk:sentry-go:1.0.0")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return manager.HitsByPrefix("sdk:sentry-go:1.0.0")["sdk:sentry-go:1.0.0"] == 1
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, manager.Touch("sdk:sentry-go:0.9.0", 0))
+
+	versions, err = manager.GetVersions("sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.1.0", "0.9.0", "1.0.0"}, versions)
+
+	versions, err = manager.GetVersions("sentry-python")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
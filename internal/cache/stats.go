@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// statsKey holds the hit and miss counters between restarts. It is hidden
+// from key listings and left out of the statistics it stores.
+const statsKey = "_stats"
+
+// persistedStats is the value stored under statsKey.
+type persistedStats struct {
+	Hits   int64 `json:"hits"`
+	Misses int64 `json:"misses"`
+}
+
+// Flush stores the hit and miss counters so the next manager opened on the
+// same cache starts from them. Close calls it. Managers sharing a Redis
+// backend overwrite each other's counters; the last to close wins.
+func (m *Manager) Flush() error {
+	stats := m.GetStats()
+	data, err := json.Marshal(persistedStats{Hits: stats.Hits, Misses: stats.Misses})
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache statistics: %w", err)
+	}
+
+	now := time.Now()
+	_, err = m.backend.Set(CacheEntry{
+		Key:       statsKey,
+		Value:     string(data),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Size:      int64(len(data)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist cache statistics: %w", err)
+	}
+	return nil
+}
+
+// loadCounters restores the hit and miss counters stored by Flush.
+func (m *Manager) loadCounters() error {
+	entry, err := m.backend.Get(statsKey)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load cache statistics: %w", err)
+	}
+
+	var persisted persistedStats
+	if err := json.Unmarshal([]byte(entry.Value), &persisted); err != nil {
+		// Counters are informational, so a bad record only costs history
+		m.logger.Warn().Err(err).Msg("Ignoring unreadable persisted cache statistics")
+		return nil
+	}
+
+	m.stats.mu.Lock()
+	m.stats.Hits = persisted.Hits
+	m.stats.Misses = persisted.Misses
+	m.stats.mu.Unlock()
+	return nil
+}
+
+// keys returns every stored key starting with prefix except statsKey.
+func (m *Manager) keys(prefix string) ([]string, error) {
+	keys, err := m.backend.Keys(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(keys, func(key string) bool { return key == statsKey }), nil
+}
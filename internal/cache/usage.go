@@ -0,0 +1,175 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+// sdkKeyPrefix is the key prefix of SDK analyses.
+const sdkKeyPrefix = "sdk:"
+
+// TimeWindow selects entries last active between Since and Until. A zero
+// bound is open.
+type TimeWindow struct {
+	Since time.Time
+	Until time.Time
+}
+
+// Contains reports whether t falls within the window.
+func (w TimeWindow) Contains(t time.Time) bool {
+	if !w.Since.IsZero() && t.Before(w.Since) {
+		return false
+	}
+	if !w.Until.IsZero() && t.After(w.Until) {
+		return false
+	}
+	return true
+}
+
+// HitsByPrefix returns the hit count of every live key starting with prefix.
+func (m *Manager) HitsByPrefix(prefix string) map[string]int64 {
+	return m.HitsByPrefixIn(prefix, TimeWindow{})
+}
+
+// HitsByPrefixIn is like HitsByPrefix for entries last active within
+// window. Hits are not timestamped individually, so an entry's whole count
+// is included when its last hit or write falls in the window.
+func (m *Manager) HitsByPrefixIn(prefix string, window TimeWindow) map[string]int64 {
+	hits := make(map[string]int64)
+	m.scanEntries(prefix, window, func(entry CacheEntry) {
+		hits[entry.Key] = entry.HitCount
+	})
+	return hits
+}
+
+// TokenSavings tracks the Claude tokens an SDK entry saved: each read
+// served from the cache spares a caller the EstimatedInputTokens of sending
+// the SDK's source to Claude. The counts restart when the entry is
+// rewritten.
+type TokenSavings struct {
+	EstimatedInputTokens int64 `json:"estimated_input_tokens"`
+	TimesServedFromCache int64 `json:"times_served_from_cache"`
+	TotalTokensSaved     int64 `json:"total_tokens_saved"`
+}
+
+// TokenSavings returns the token savings of the entry stored under key
+// without counting the lookup as a read. Missing and expired keys return a
+// *apperrors.CacheMissError.
+func (m *Manager) TokenSavings(key string) (TokenSavings, error) {
+	entry, err := m.backend.Get(key)
+	if err == nil && entry.expired(time.Now()) {
+		err = ErrNotFound
+	}
+	if errors.Is(err, ErrNotFound) {
+		return TokenSavings{}, &apperrors.CacheMissError{Key: key}
+	}
+	if err != nil {
+		return TokenSavings{}, fmt.Errorf("failed to get key: %w", err)
+	}
+	return entry.TokenSavings, nil
+}
+
+// TokenSavingsBySDK returns the tokens saved by cache hits on each SDK's
+// analyses, estimated as hits times the tokens cached with the entry.
+func (m *Manager) TokenSavingsBySDK() map[string]int64 {
+	return m.TokenSavingsBySDKIn(TimeWindow{})
+}
+
+// TokenSavingsBySDKIn is like TokenSavingsBySDK for entries last active
+// within window.
+func (m *Manager) TokenSavingsBySDKIn(window TimeWindow) map[string]int64 {
+	savings := make(map[string]int64)
+	m.scanEntries(sdkKeyPrefix, window, func(entry CacheEntry) {
+		if entry.TokensCached == 0 || entry.HitCount == 0 {
+			return
+		}
+		name, _, _ := strings.Cut(strings.TrimPrefix(entry.Key, sdkKeyPrefix), ":")
+		savings[name] += entry.HitCount * int64(entry.TokensCached)
+	})
+	return savings
+}
+
+// DefaultHitCountBuckets are the bucket bounds HitCountHistogram callers
+// use when none are given: never hit, one hit, then powers of ten.
+var DefaultHitCountBuckets = []int{0, 1, 10, 100, 1000}
+
+// HitCountHistogram counts the live entries in each hit count bucket.
+// buckets are the lower bounds of the buckets, each reaching up to the next
+// bound and the last open-ended; a bucket from zero is added when the first
+// bound is above zero, and negative bounds are ignored. The result has an
+// entry for every bucket, keyed by its HitCountBucketLabels label.
+func (m *Manager) HitCountHistogram(buckets []int) map[string]int64 {
+	bounds := hitCountBounds(buckets)
+	labels := bucketLabels(bounds)
+
+	histogram := make(map[string]int64, len(labels))
+	for _, label := range labels {
+		histogram[label] = 0
+	}
+	m.scanEntries("", TimeWindow{}, func(entry CacheEntry) {
+		i := sort.Search(len(bounds), func(i int) bool {
+			return int64(bounds[i]) > entry.HitCount
+		})
+		histogram[labels[i-1]]++
+	})
+	return histogram
+}
+
+// HitCountBucketLabels returns the labels of the buckets HitCountHistogram
+// counts for buckets, in ascending order: "0" for a bucket of one hit
+// count, "1-9" for a range and "1000+" for the last bucket.
+func HitCountBucketLabels(buckets []int) []string {
+	return bucketLabels(hitCountBounds(buckets))
+}
+
+// hitCountBounds returns the distinct non-negative bounds of buckets in
+// ascending order, starting at zero.
+func hitCountBounds(buckets []int) []int {
+	bounds := []int{0}
+	for _, bound := range slices.Sorted(slices.Values(buckets)) {
+		if bound > bounds[len(bounds)-1] {
+			bounds = append(bounds, bound)
+		}
+	}
+	return bounds
+}
+
+func bucketLabels(bounds []int) []string {
+	labels := make([]string, len(bounds))
+	for i, lower := range bounds {
+		switch {
+		case i == len(bounds)-1:
+			labels[i] = strconv.Itoa(lower) + "+"
+		case bounds[i+1]-1 == lower:
+			labels[i] = strconv.Itoa(lower)
+		default:
+			labels[i] = fmt.Sprintf("%d-%d", lower, bounds[i+1]-1)
+		}
+	}
+	return labels
+}
+
+// scanEntries calls fn for every live entry whose key starts with prefix
+// and whose last activity falls within window.
+func (m *Manager) scanEntries(prefix string, window TimeWindow, fn func(CacheEntry)) {
+	now := time.Now()
+	err := m.forEachEntry(prefix, func(entry CacheEntry) {
+		if entry.TTL > 0 && now.Sub(entry.UpdatedAt) > entry.TTL {
+			return
+		}
+		if !window.Contains(entry.UpdatedAt) {
+			return
+		}
+		fn(entry)
+	})
+	if err != nil {
+		m.logger.Error().Err(err).Msg("Failed to scan cache entries")
+	}
+}
@@ -0,0 +1,164 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hit reads key n times and waits for the hit counts to be recorded.
+func hit(t *testing.T, m *Manager, key string, n int64) {
+	t.Helper()
+
+	for i := int64(0); i < n; i++ {
+		_, err := m.Get(key)
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return m.HitsByPrefix(key)[key] == n
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestHitsByPrefix(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "a", time.Hour))
+	require.NoError(t, m.Set("sdk:sentry-python", "b", time.Hour))
+	require.NoError(t, m.Set("other", "c", time.Hour))
+	hit(t, m, "sdk:sentry-go", 3)
+	hit(t, m, "other", 1)
+
+	assert.Equal(t, map[string]int64{
+		"sdk:sentry-go":     3,
+		"sdk:sentry-python": 0,
+	}, m.HitsByPrefix("sdk:"))
+	assert.Len(t, m.HitsByPrefix(""), 3)
+}
+
+func TestTokenSavingsBySDK(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "a", time.Hour, WithTokensCached(100)))
+	require.NoError(t, m.Set("sdk:sentry-go:v2", "a", time.Hour, WithTokensCached(100)))
+	require.NoError(t, m.Set("sdk:sentry-python", "b", time.Hour, WithTokensCached(50)))
+	require.NoError(t, m.Set("sdk:sentry-ruby", "c", time.Hour))
+	hit(t, m, "sdk:sentry-go", 2)
+	hit(t, m, "sdk:sentry-go:v2", 1)
+	hit(t, m, "sdk:sentry-ruby", 4)
+
+	assert.Equal(t, map[string]int64{"sentry-go": 300}, m.TokenSavingsBySDK())
+}
+
+func TestTokenSavings(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "analysis", time.Hour, WithTokenHint(1200)))
+	savings, err := m.TokenSavings("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, TokenSavings{EstimatedInputTokens: 1200}, savings)
+
+	// Savings accumulate with every read
+	hit(t, m, "sdk:sentry-go", 3)
+	savings, err = m.TokenSavings("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, TokenSavings{EstimatedInputTokens: 1200, TimesServedFromCache: 3, TotalTokensSaved: 3600}, savings)
+
+	_, errs := m.GetMulti([]string{"sdk:sentry-go"})
+	require.Empty(t, errs)
+	require.Eventually(t, func() bool {
+		savings, err = m.TokenSavings("sdk:sentry-go")
+		return err == nil && savings.TimesServedFromCache == 4
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int64(4800), savings.TotalTokensSaved)
+
+	// Rewriting the entry starts over
+	require.NoError(t, m.Set("sdk:sentry-go", "analysis", time.Hour, WithTokenHint(900)))
+	savings, err = m.TokenSavings("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, TokenSavings{EstimatedInputTokens: 900}, savings)
+
+	// Only SDK entries save tokens
+	require.NoError(t, m.Set("project:sentry", "summary", time.Hour, WithTokenHint(500)))
+	hit(t, m, "project:sentry", 2)
+	savings, err = m.TokenSavings("project:sentry")
+	require.NoError(t, err)
+	assert.Equal(t, TokenSavings{EstimatedInputTokens: 500}, savings)
+
+	_, err = m.TokenSavings("sdk:missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestUsageSkipsExpiredEntries(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "a", 50*time.Millisecond, WithTokensCached(100)))
+	hit(t, m, "sdk:sentry-go", 1)
+	time.Sleep(100 * time.Millisecond)
+
+	assert.Empty(t, m.HitsByPrefix("sdk:"))
+	assert.Empty(t, m.TokenSavingsBySDK())
+}
+
+func TestUsageTimeWindow(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	require.NoError(t, m.Set("sdk:sentry-go", "a", time.Hour, WithTokensCached(100)))
+	hit(t, m, "sdk:sentry-go", 1)
+	now := time.Now()
+
+	assert.Equal(t, map[string]int64{"sentry-go": 100},
+		m.TokenSavingsBySDKIn(TimeWindow{Since: now.Add(-time.Minute), Until: now.Add(time.Minute)}))
+	assert.Empty(t, m.TokenSavingsBySDKIn(TimeWindow{Since: now.Add(time.Minute)}))
+	assert.Empty(t, m.HitsByPrefixIn("sdk:", TimeWindow{Until: now.Add(-time.Minute)}))
+}
+
+func TestTimeWindowContains(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, TimeWindow{}.Contains(now))
+	assert.True(t, TimeWindow{Since: now, Until: now}.Contains(now))
+	assert.False(t, TimeWindow{Since: now}.Contains(now.Add(-time.Second)))
+	assert.False(t, TimeWindow{Until: now}.Contains(now.Add(time.Second)))
+}
+
+func TestHitCountHistogram(t *testing.T) {
+	m := newEventsTestManager(t)
+
+	hits := map[string]int64{
+		"sdk:sentry-go":     0,
+		"sdk:sentry-python": 0,
+		"sdk:sentry-ruby":   1,
+		"sdk:sentry-java":   5,
+		"sdk:sentry-rust":   12,
+	}
+	for key, n := range hits {
+		require.NoError(t, m.Set(key, "{}", time.Hour))
+		if n > 0 {
+			hit(t, m, key, n)
+		}
+	}
+
+	assert.Equal(t, map[string]int64{
+		"0":     2,
+		"1-9":   2,
+		"10-99": 1,
+		"100+":  0,
+	}, m.HitCountHistogram([]int{0, 1, 10, 100}))
+
+	// Unsorted, duplicate and negative bounds are tidied, and entries below
+	// the first bound get a bucket of their own
+	assert.Equal(t, map[string]int64{
+		"0-4":  3,
+		"5-11": 1,
+		"12+":  1,
+	}, m.HitCountHistogram([]int{12, 5, 5, -1}))
+
+	assert.Equal(t, map[string]int64{"0+": 5}, m.HitCountHistogram(nil))
+}
+
+func TestHitCountBucketLabels(t *testing.T) {
+	assert.Equal(t, []string{"0", "1-9", "10-99", "100-999", "1000+"}, HitCountBucketLabels(DefaultHitCountBuckets))
+	assert.Equal(t, []string{"0-2", "3", "4+"}, HitCountBucketLabels([]int{3, 4}))
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+// GetWithVersion retrieves a value from the cache with its version, for a
+// later SetWithVersion. Missing keys return a *apperrors.CacheMissError,
+// which matches ErrNotFound; writers creating them pass version 0.
+func (m *Manager) GetWithVersion(key string) (string, int64, error) {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		m.getLatency.Record(elapsed)
+		m.metrics().ObserveGetLatency(elapsed)
+	}()
+
+	if entry, ok := m.l1.Get(key, time.Now()); ok {
+		m.trackHits(key)
+		m.recordHit(entry)
+		m.recordLayerHit(true)
+		return entry.Value, entry.Version, nil
+	}
+
+	endFill := m.l1.beginFill()
+	entry, err := m.backend.Get(key)
+	if err == nil && entry.expired(time.Now()) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		endFill()
+		if errors.Is(err, ErrNotFound) {
+			m.recordMiss()
+			return "", 0, &apperrors.CacheMissError{Key: key}
+		}
+		return "", 0, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	value, err := entry.value()
+	if err != nil {
+		endFill()
+		return "", 0, err
+	}
+	m.l1.Add(memoryEntry(entry, value, entry.Version))
+	endFill()
+
+	m.trackHits(key)
+	m.recordHit(entry)
+	m.recordLayerHit(false)
+	return value, entry.Version, nil
+}
+
+// PeekWithVersion is GetWithVersion for the service's own reads, such as
+// those of read-modify-write cycles. It does not count as a hit or miss,
+// and leaves the key's hit count and tokens saved alone.
+func (m *Manager) PeekWithVersion(key string) (string, int64, error) {
+	if entry, ok := m.l1.Get(key, time.Now()); ok {
+		return entry.Value, entry.Version, nil
+	}
+
+	entry, err := m.backend.Get(key)
+	if err == nil && entry.expired(time.Now()) {
+		err = ErrNotFound
+	}
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return "", 0, &apperrors.CacheMissError{Key: key}
+		}
+		return "", 0, fmt.Errorf("failed to get key: %w", err)
+	}
+
+	value, err := entry.value()
+	if err != nil {
+		return "", 0, err
+	}
+	return value, entry.Version, nil
+}
+
+// Peek is Get without counting the read, like PeekWithVersion.
+func (m *Manager) Peek(key string) (string, error) {
+	value, _, err := m.PeekWithVersion(key)
+	return value, err
+}
+
+// SetWithVersion stores a value like Set, but only if the key is still at
+// expectedVersion, as returned by GetWithVersion. It returns the key's new
+// version, or ErrVersionConflict if another write got there first; callers
+// read the key again and retry.
+func (m *Manager) SetWithVersion(key, value string, ttl time.Duration, expectedVersion int64, opts ...SetOption) (int64, error) {
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		m.setLatency.Record(elapsed)
+		m.metrics().ObserveSetLatency(elapsed)
+	}()
+
+	entry := CacheEntry{
+		Key:   key,
+		Value: value,
+		TTL:   ttl,
+	}
+	for _, opt := range opts {
+		opt(&entry)
+	}
+
+	if err := m.prepareEntry(&entry); err != nil {
+		m.auditRecorder().RecordMutation(EventSet, key, err)
+		return 0, err
+	}
+
+	endWrite := m.l1.beginWrite()
+	previous, err := m.backend.SetIfVersion(entry, expectedVersion)
+	if err == nil {
+		m.l1.Add(memoryEntry(entry, value, nextVersion(previous)))
+	}
+	endWrite()
+	if err != nil && !errors.Is(err, ErrVersionConflict) {
+		err = fmt.Errorf("failed to set key: %w", err)
+	}
+	if err != nil {
+		m.auditRecorder().RecordMutation(EventSet, key, err)
+		return 0, err
+	}
+
+	m.recordWrite(entry, previous)
+	if err := m.evictIfNeeded(); err != nil {
+		m.logger.Error().Err(err).Msg("Failed to evict cache entries")
+	}
+	return expectedVersion + 1, nil
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetWithVersion(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	_, _, err := manager.GetWithVersion("sdk:sentry-go")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	version, err := manager.SetWithVersion("sdk:sentry-go", "first", time.Hour, 0, WithTokensCached(100))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	value, version, err := manager.GetWithVersion("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+	assert.Equal(t, int64(1), version)
+
+	// A stale version is rejected and the value is left alone
+	_, err = manager.SetWithVersion("sdk:sentry-go", "stale", time.Hour, 0)
+	require.ErrorIs(t, err, ErrVersionConflict)
+	value, err = manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	// Plain writes bump the version too
+	require.NoError(t, manager.Set("sdk:sentry-go", "second", time.Hour))
+	_, err = manager.SetWithVersion("sdk:sentry-go", "third", time.Hour, 1)
+	require.ErrorIs(t, err, ErrVersionConflict)
+	version, err = manager.SetWithVersion("sdk:sentry-go", "third", time.Hour, 2)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	stats := manager.GetStats()
+	assert.Equal(t, int64(1), stats.ItemCount)
+	assert.Equal(t, int64(3), stats.Sets)
+}
+
+func TestSetWithVersionConcurrent(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	const writers = 10
+	const writesEach = 20
+
+	var mu sync.Mutex
+	var versions []int64
+
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(writer int) {
+			defer wg.Done()
+			for n := 0; n < writesEach; {
+				_, version, err := manager.GetWithVersion("counter")
+				if err != nil && !errors.Is(err, ErrNotFound) {
+					t.Errorf("get failed: %v", err)
+					return
+				}
+
+				newVersion, err := manager.SetWithVersion("counter", strconv.Itoa(writer), 0, version)
+				if errors.Is(err, ErrVersionConflict) {
+					continue
+				}
+				if err != nil {
+					t.Errorf("set failed: %v", err)
+					return
+				}
+
+				mu.Lock()
+				versions = append(versions, newVersion)
+				mu.Unlock()
+				n++
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Every successful write got its own version, with none skipped
+	require.Len(t, versions, writers*writesEach)
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+	for i, version := range versions {
+		require.Equal(t, int64(i+1), version)
+	}
+
+	_, version, err := manager.GetWithVersion("counter")
+	require.NoError(t, err)
+	assert.Equal(t, int64(writers*writesEach), version)
+}
+
+func TestPeekWithVersion(t *testing.T) {
+	manager := newEventsTestManager(t)
+
+	_, _, err := manager.PeekWithVersion("sdk:sentry-go")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	version, err := manager.SetWithVersion("sdk:sentry-go", "first", time.Hour, 0, WithTokensCached(100))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		value, peeked, err := manager.PeekWithVersion("sdk:sentry-go")
+		require.NoError(t, err)
+		assert.Equal(t, "first", value)
+		assert.Equal(t, version, peeked)
+	}
+	value, err := manager.Peek("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	// Only the Get is counted, as a hit and a read served from the cache
+	_, err = manager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	stats := manager.GetStats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Zero(t, stats.Misses)
+	require.Eventually(t, func() bool {
+		savings, err := manager.TokenSavings("sdk:sentry-go")
+		return err == nil && savings.TimesServedFromCache > 0
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	savings, err := manager.TokenSavings("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), savings.TimesServedFromCache)
+}
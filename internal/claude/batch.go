@@ -43,6 +43,22 @@ type BatchResult struct {
 	} `json:"error,omitempty"`
 }
 
+// NewBatchRequest wraps a Messages API request for the batch API, using the
+// client's model.
+func (c *Client) NewBatchRequest(customID string, messages []Message, system string, maxTokens int) BatchRequest {
+	return BatchRequest{
+		CustomID: customID,
+		Method:   "POST",
+		URL:      "/v1/messages",
+		Body: Request{
+			Model:     c.model,
+			Messages:  messages,
+			MaxTokens: maxTokens,
+			System:    system,
+		},
+	}
+}
+
 // CreateBatch creates a new batch job
 func (c *Client) CreateBatch(ctx context.Context, requests []BatchRequest) (*BatchResponse, error) {
 	payload := map[string]interface{}{
@@ -0,0 +1,184 @@
+package claude
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Circuit breaker defaults
+const (
+	DefaultFailureThreshold = 5
+	DefaultOpenDuration     = 60 * time.Second
+)
+
+// ErrCircuitOpen is returned without contacting the API while the circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open: Claude API is unavailable")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState string
+
+// Circuit breaker states
+const (
+	// CircuitClosed lets every request through
+	CircuitClosed CircuitState = "closed"
+
+	// CircuitOpen rejects every request with ErrCircuitOpen
+	CircuitOpen CircuitState = "open"
+
+	// CircuitHalfOpen lets one request through to probe the API
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// CircuitStatus is a snapshot of a CircuitBreaker.
+type CircuitStatus struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutive_failures"`
+	FailureThreshold    int          `json:"failure_threshold"`
+
+	// OpenedAt is when the circuit last opened, and RetryAt when it lets a
+	// probe through; both are nil while it is closed
+	OpenedAt *time.Time `json:"opened_at,omitempty"`
+	RetryAt  *time.Time `json:"retry_at,omitempty"`
+}
+
+// CircuitBreaker fails requests fast while the API is down. After
+// FailureThreshold consecutive failures it opens, rejecting requests for
+// OpenDuration. It then half-opens and lets a single request through: the
+// circuit closes if it succeeds and opens again if it fails.
+type CircuitBreaker struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+
+	mu       sync.Mutex
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	probing  bool
+	logger   zerolog.Logger
+
+	// now returns the current time; replaced in tests
+	now func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker. Non-positive values
+// use DefaultFailureThreshold and DefaultOpenDuration.
+func NewCircuitBreaker(failureThreshold int, openDuration time.Duration, logger zerolog.Logger) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+	if openDuration <= 0 {
+		openDuration = DefaultOpenDuration
+	}
+	return &CircuitBreaker{
+		FailureThreshold: failureThreshold,
+		OpenDuration:     openDuration,
+		state:            CircuitClosed,
+		logger:           logger,
+		now:              time.Now,
+	}
+}
+
+// Allow reports whether a request may be sent, returning ErrCircuitOpen
+// if not. Every allowed request must be followed by Success, Failure or
+// Cancel.
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfDue()
+	switch b.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Success records that the API answered, closing the circuit.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != CircuitClosed {
+		b.logger.Info().Msg("Claude API circuit breaker closed")
+	}
+	b.state = CircuitClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure records that the API was unavailable, opening the circuit after
+// FailureThreshold consecutive failures or a failed probe.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == CircuitHalfOpen || b.failures >= b.FailureThreshold {
+		if b.state != CircuitOpen {
+			b.logger.Warn().
+				Int("failures", b.failures).
+				Dur("open_duration", b.OpenDuration).
+				Msg("Claude API circuit breaker opened")
+		}
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// Cancel records that an allowed request ended, for instance because its
+// context was cancelled, without showing whether the API is available. A
+// half-open circuit lets another probe through.
+func (b *CircuitBreaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// State returns the current state.
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfDue()
+	return b.state
+}
+
+// Status returns a snapshot of the circuit breaker.
+func (b *CircuitBreaker) Status() CircuitStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.halfOpenIfDue()
+	status := CircuitStatus{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		FailureThreshold:    b.FailureThreshold,
+	}
+	if b.state != CircuitClosed {
+		openedAt := b.openedAt
+		retryAt := openedAt.Add(b.OpenDuration)
+		status.OpenedAt = &openedAt
+		status.RetryAt = &retryAt
+	}
+	return status
+}
+
+// halfOpenIfDue moves an open circuit to half-open once OpenDuration has
+// passed. b.mu must be held.
+func (b *CircuitBreaker) halfOpenIfDue() {
+	if b.state == CircuitOpen && b.now().Sub(b.openedAt) >= b.OpenDuration {
+		b.state = CircuitHalfOpen
+		b.probing = false
+	}
+}
@@ -0,0 +1,194 @@
+package claude
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
+)
+
+// newTestCircuitBreaker returns a breaker opening after three failures for
+// a minute, and a function advancing its clock.
+func newTestCircuitBreaker() (*CircuitBreaker, func(time.Duration)) {
+	b := NewCircuitBreaker(3, time.Minute, zerolog.Nop())
+	now := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+	b.now = func() time.Time { return now }
+	return b, func(d time.Duration) { now = now.Add(d) }
+}
+
+// openCircuit fails requests until b opens.
+func openCircuit(t *testing.T, b *CircuitBreaker) {
+	t.Helper()
+	for i := 0; i < b.FailureThreshold; i++ {
+		require.NoError(t, b.Allow())
+		b.Failure()
+	}
+	require.Equal(t, CircuitOpen, b.State())
+}
+
+func TestNewCircuitBreakerDefaults(t *testing.T) {
+	b := NewCircuitBreaker(0, 0, zerolog.Nop())
+	assert.Equal(t, DefaultFailureThreshold, b.FailureThreshold)
+	assert.Equal(t, DefaultOpenDuration, b.OpenDuration)
+	assert.Equal(t, CircuitClosed, b.State())
+}
+
+func TestCircuitBreakerClosedToOpen(t *testing.T) {
+	b, _ := newTestCircuitBreaker()
+
+	// Failures below the threshold keep it closed, and a success resets them
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Failure()
+	}
+	assert.Equal(t, CircuitClosed, b.State())
+	assert.Equal(t, 2, b.Status().ConsecutiveFailures)
+	require.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, 0, b.Status().ConsecutiveFailures)
+
+	openCircuit(t, b)
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	status := b.Status()
+	assert.Equal(t, 3, status.ConsecutiveFailures)
+	require.NotNil(t, status.OpenedAt)
+	require.NotNil(t, status.RetryAt)
+	assert.Equal(t, time.Minute, status.RetryAt.Sub(*status.OpenedAt))
+}
+
+func TestCircuitBreakerOpenToHalfOpen(t *testing.T) {
+	b, advance := newTestCircuitBreaker()
+	openCircuit(t, b)
+
+	advance(time.Minute - time.Second)
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	advance(time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+
+	// Only one probe is let through
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+}
+
+func TestCircuitBreakerHalfOpenToClosed(t *testing.T) {
+	b, advance := newTestCircuitBreaker()
+	openCircuit(t, b)
+	advance(time.Minute)
+
+	require.NoError(t, b.Allow())
+	b.Success()
+
+	status := b.Status()
+	assert.Equal(t, CircuitClosed, status.State)
+	assert.Zero(t, status.ConsecutiveFailures)
+	assert.Nil(t, status.OpenedAt)
+	assert.NoError(t, b.Allow())
+}
+
+func TestCircuitBreakerHalfOpenToOpen(t *testing.T) {
+	b, advance := newTestCircuitBreaker()
+	openCircuit(t, b)
+	advance(time.Minute)
+
+	require.NoError(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, CircuitOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrCircuitOpen)
+
+	// The open period starts again from the failed probe
+	advance(time.Minute - time.Second)
+	assert.Equal(t, CircuitOpen, b.State())
+	advance(time.Second)
+	assert.Equal(t, CircuitHalfOpen, b.State())
+}
+
+func TestCircuitBreakerCancelledProbe(t *testing.T) {
+	b, advance := newTestCircuitBreaker()
+	openCircuit(t, b)
+	advance(time.Minute)
+
+	require.NoError(t, b.Allow())
+	b.Cancel()
+	assert.Equal(t, CircuitHalfOpen, b.State())
+	assert.NoError(t, b.Allow(), "another probe is let through")
+}
+
+func TestSendMessageCircuitBreaker(t *testing.T) {
+	originalDelay := RetryDelay
+	RetryDelay = time.Millisecond
+	defer func() { RetryDelay = originalDelay }()
+
+	server := mockserver.NewMockServer(t)
+	server.SetError(http.StatusServiceUnavailable, mockserver.ErrorResponse{
+		Type:    "overloaded_error",
+		Message: "Service unavailable",
+	})
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+	client.breaker.FailureThreshold = 4
+	messages := []Message{{Role: "user", Content: "Test"}}
+
+	// The second message's retries trip the breaker
+	_, err := client.SendMessage(context.Background(), messages, "", 100)
+	require.Error(t, err)
+	assert.Equal(t, CircuitClosed, client.CircuitBreaker().State())
+
+	_, err = client.SendMessage(context.Background(), messages, "", 100)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 4, server.CallCount())
+
+	// Further messages fail without reaching the API
+	_, err = client.SendMessage(context.Background(), messages, "", 100)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 4, server.CallCount())
+}
+
+func TestSendMessageStreamCircuitBreaker(t *testing.T) {
+	server := mockserver.NewMockServer(t)
+	server.SetError(http.StatusServiceUnavailable, mockserver.ErrorResponse{
+		Type:    "overloaded_error",
+		Message: "Service unavailable",
+	})
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+	client.breaker.FailureThreshold = 2
+	messages := []Message{{Role: "user", Content: "Test"}}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.SendMessageStream(context.Background(), messages, "", 100)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrCircuitOpen)
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitBreaker().State())
+
+	// Further streams fail without reaching the API
+	_, err := client.SendMessageStream(context.Background(), messages, "", 100)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, 2, server.CallCount())
+}
+
+func TestSendMessageClientErrorsKeepCircuitClosed(t *testing.T) {
+	server := mockserver.NewMockServer(t)
+	server.SetError(http.StatusBadRequest, mockserver.ErrorResponse{
+		Type:    "invalid_request_error",
+		Message: "Invalid request",
+	})
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+	client.breaker.FailureThreshold = 1
+
+	_, err := client.SendMessage(context.Background(), []Message{{Role: "user", Content: "Test"}}, "", 100)
+	require.Error(t, err)
+	assert.Equal(t, CircuitClosed, client.CircuitBreaker().State())
+}
@@ -4,13 +4,22 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
 )
 
 const (
@@ -23,6 +32,14 @@ var (
 	RetryDelay = time.Second // Exported for testing
 )
 
+// Connection pool defaults, sized for the API's concurrency limits
+const (
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultMaxConnsPerHost     = 20
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+)
+
 // Client represents a Claude API client
 type Client struct {
 	apiKey     string
@@ -31,31 +48,158 @@ type Client struct {
 	limiter    *rate.Limiter
 	logger     zerolog.Logger
 	model      string
+
+	// breaker fails messages fast while the API is down
+	breaker *CircuitBreaker
+
+	// UseAPITokenCounting makes CountTokens ask the API for exact counts
+	// instead of estimating them locally
+	UseAPITokenCounting bool
+
+	// MaxIdleConnsPerHost, MaxConnsPerHost and IdleConnTimeout are the
+	// connection pool settings of the client's transport, and KeepAlive
+	// the TCP keep-alive period of its connections, which is zero for
+	// transports given to NewClientWithTransport. The constructors set
+	// them; changing them afterwards has no effect.
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	KeepAlive           time.Duration
 }
 
-// NewClient creates a new Claude API client
+// NewClient creates a new Claude API client with the default connection
+// pool
 func NewClient(apiKey, model string, logger zerolog.Logger) *Client {
+	return NewClientWithTransport(apiKey, model, nil, logger)
+}
+
+// NewClientWithTransport creates a new Claude API client sending requests
+// through transport, typically one built by NewTransport. A nil transport
+// uses the default connection pool.
+func NewClientWithTransport(apiKey, model string, transport *http.Transport, logger zerolog.Logger) *Client {
 	if model == "" {
 		model = "claude-3-opus-20240229"
 	}
 
+	var keepAlive time.Duration
+	if transport == nil {
+		transport = NewTransport(DefaultMaxIdleConnsPerHost, DefaultMaxConnsPerHost, DefaultIdleConnTimeout, DefaultKeepAlive)
+		keepAlive = DefaultKeepAlive
+	}
+
 	return &Client{
 		apiKey:  apiKey,
 		BaseURL: defaultBaseURL,
 		httpClient: &http.Client{
-			Timeout: 120 * time.Second,
+			Timeout:   120 * time.Second,
+			Transport: transport,
 		},
 		// Claude API limits: 50 RPM for tier 1
 		limiter: rate.NewLimiter(rate.Every(time.Minute/50), 5), // 50 RPM with burst of 5
 		logger:  logger,
 		model:   model,
+		breaker: NewCircuitBreaker(DefaultFailureThreshold, DefaultOpenDuration, logger),
+
+		MaxIdleConnsPerHost: transport.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     transport.MaxConnsPerHost,
+		IdleConnTimeout:     transport.IdleConnTimeout,
+		KeepAlive:           keepAlive,
 	}
 }
 
+// NewTransport returns an HTTP transport keeping up to maxIdleConnsPerHost
+// idle connections to the API for idleConnTimeout, opening at most
+// maxConnsPerHost at once (zero for no limit), with TCP keep-alives every
+// keepAlive. Its other settings, such as proxies and HTTP/2, are those of
+// http.DefaultTransport.
+func NewTransport(maxIdleConnsPerHost, maxConnsPerHost int, idleConnTimeout, keepAlive time.Duration) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: keepAlive,
+	}).DialContext
+	transport.MaxIdleConns = max(transport.MaxIdleConns, maxIdleConnsPerHost)
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.MaxConnsPerHost = maxConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+	return transport
+}
+
+// CircuitBreaker returns the circuit breaker guarding message requests
+func (c *Client) CircuitBreaker() *CircuitBreaker {
+	return c.breaker
+}
+
 // Message represents a message in the Claude API
 type Message struct {
 	Role    string `json:"role"`
 	Content string `json:"content"`
+
+	// CacheControl marks the conversation up to and including this message
+	// for prompt caching
+	CacheControl *CacheControl `json:"-"`
+}
+
+// CacheControl configures prompt caching for a message
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// EphemeralCache caches a prompt prefix for a few minutes, the only cache
+// type the API supports
+var EphemeralCache = &CacheControl{Type: "ephemeral"}
+
+// MarshalJSON sends messages marked for caching as a single text block,
+// since the API only accepts cache_control on content blocks.
+func (m Message) MarshalJSON() ([]byte, error) {
+	type plain Message
+	if m.CacheControl == nil {
+		return json.Marshal(plain(m))
+	}
+
+	type textBlock struct {
+		Type         string        `json:"type"`
+		Text         string        `json:"text"`
+		CacheControl *CacheControl `json:"cache_control"`
+	}
+	return json.Marshal(struct {
+		Role    string      `json:"role"`
+		Content []textBlock `json:"content"`
+	}{
+		Role:    m.Role,
+		Content: []textBlock{{Type: "text", Text: m.Content, CacheControl: m.CacheControl}},
+	})
+}
+
+// UnmarshalJSON accepts content either as a string or as text blocks,
+// which are joined.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = Message{Role: raw.Role}
+
+	if err := json.Unmarshal(raw.Content, &m.Content); err == nil {
+		return nil
+	}
+	var blocks []struct {
+		Text         string        `json:"text"`
+		CacheControl *CacheControl `json:"cache_control"`
+	}
+	if err := json.Unmarshal(raw.Content, &blocks); err != nil {
+		return fmt.Errorf("failed to unmarshal message content: %w", err)
+	}
+	for _, block := range blocks {
+		m.Content += block.Text
+		if block.CacheControl != nil {
+			m.CacheControl = block.CacheControl
+		}
+	}
+	return nil
 }
 
 // Request represents a Claude API request
@@ -65,16 +209,18 @@ type Request struct {
 	MaxTokens   int       `json:"max_tokens"`
 	Temperature float64   `json:"temperature,omitempty"`
 	System      string    `json:"system,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // Response represents a Claude API response
 type Response struct {
-	ID      string         `json:"id"`
-	Type    string         `json:"type"`
-	Role    string         `json:"role"`
-	Content []ContentBlock `json:"content"`
-	Model   string         `json:"model"`
-	Usage   Usage          `json:"usage"`
+	ID         string         `json:"id"`
+	Type       string         `json:"type"`
+	Role       string         `json:"role"`
+	Content    []ContentBlock `json:"content"`
+	Model      string         `json:"model"`
+	StopReason string         `json:"stop_reason,omitempty"`
+	Usage      Usage          `json:"usage"`
 }
 
 // ContentBlock represents a content block in the response
@@ -83,10 +229,19 @@ type ContentBlock struct {
 	Text string `json:"text"`
 }
 
-// Usage represents token usage information
+// Usage represents token usage information. InputTokens excludes tokens
+// written to or read from the prompt cache.
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// TotalTokens returns every input and output token processed, whether or
+// not it came from the prompt cache.
+func (u Usage) TotalTokens() int {
+	return u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens + u.OutputTokens
 }
 
 // ErrorResponse represents an API error response
@@ -95,7 +250,8 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-// SendMessage sends a message to Claude API
+// SendMessage sends a message to Claude API. If the API is still rate
+// limiting after the last retry, the error wraps an *apperrors.RateLimitError.
 func (c *Client) SendMessage(ctx context.Context, messages []Message, system string, maxTokens int) (*Response, error) {
 	// Rate limiting
 	if err := c.limiter.Wait(ctx); err != nil {
@@ -111,7 +267,11 @@ func (c *Client) SendMessage(ctx context.Context, messages []Message, system str
 
 	var lastErr error
 	for attempt := 0; attempt < maxRetries; attempt++ {
+		if err := c.breaker.Allow(); err != nil {
+			return nil, err
+		}
 		resp, err := c.doRequest(ctx, "/v1/messages", request)
+		c.recordResult(err)
 		if err == nil {
 			return resp, nil
 		}
@@ -139,10 +299,48 @@ func (c *Client) SendMessage(ctx context.Context, messages []Message, system str
 		}
 	}
 
+	var apiErr *APIError
+	if errors.As(lastErr, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+		rateLimited := &apperrors.RateLimitError{RetryAfter: apiErr.RetryAfter}
+		return nil, fmt.Errorf("max retries exceeded: %w: %w", rateLimited, lastErr)
+	}
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-func (c *Client) doRequest(ctx context.Context, endpoint string, payload interface{}) (*Response, error) {
+// tracerName names the tracer of Claude API request spans.
+const tracerName = "github.com/ryanrussell/claude-cache-service/internal/claude"
+
+// sdkNameKey is the context key of the SDK a request analyzes.
+type sdkNameKey struct{}
+
+// WithSDKName returns a copy of ctx noting that its requests analyze the
+// named SDK, which their spans record.
+func WithSDKName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, sdkNameKey{}, name)
+}
+
+// doRequest sends one request, recorded as a span with the model, the SDK
+// from WithSDKName and the tokens used.
+func (c *Client) doRequest(ctx context.Context, endpoint string, payload interface{}) (_ *Response, err error) {
+	attrs := []attribute.KeyValue{
+		attribute.String("gen_ai.system", "anthropic"),
+		attribute.String("gen_ai.request.model", c.model),
+	}
+	if name, ok := ctx.Value(sdkNameKey{}).(string); ok {
+		attrs = append(attrs, attribute.String("sdk.name", name))
+	}
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "claude POST "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
@@ -181,6 +379,7 @@ func (c *Client) doRequest(ctx context.Context, endpoint string, payload interfa
 			StatusCode: resp.StatusCode,
 			Type:       errResp.Type,
 			Message:    errResp.Message,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
 		}
 	}
 
@@ -189,20 +388,64 @@ func (c *Client) doRequest(ctx context.Context, endpoint string, payload interfa
 		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
+	span.SetAttributes(
+		attribute.String("gen_ai.response.model", response.Model),
+		attribute.Int("gen_ai.usage.input_tokens", response.Usage.InputTokens),
+		attribute.Int("gen_ai.usage.output_tokens", response.Usage.OutputTokens),
+		attribute.Int("claude.total_tokens", response.Usage.TotalTokens()),
+	)
 	return &response, nil
 }
 
+// recordResult reports the outcome of a request to the circuit breaker.
+// Only errors worth retrying count as failures: any other error response
+// shows the API is up.
+func (c *Client) recordResult(err error) {
+	switch {
+	case err == nil:
+		c.breaker.Success()
+	case errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded):
+		c.breaker.Cancel()
+	case isRetryableError(err):
+		c.breaker.Failure()
+	default:
+		c.breaker.Success()
+	}
+}
+
 // APIError represents a Claude API error
 type APIError struct {
 	StatusCode int
 	Type       string
 	Message    string
+
+	// RetryAfter is the wait the API asked for in its Retry-After header,
+	// or zero when it sent none
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("Claude API error %d (%s): %s", e.StatusCode, e.Type, e.Message)
 }
 
+// parseRetryAfter reads a Retry-After header, given either as seconds or as
+// an HTTP date. It returns zero for a missing or malformed header.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
+
 func isRetryableError(err error) bool {
 	apiErr, ok := err.(*APIError)
 	if !ok {
@@ -213,10 +456,21 @@ func isRetryableError(err error) bool {
 	return apiErr.StatusCode == 429 || apiErr.StatusCode >= 500
 }
 
-// CountTokens estimates token count for messages
+// countTokensRequest is a count_tokens request, which takes the fields of
+// a Messages API request except max_tokens
+type countTokensRequest struct {
+	Model    string    `json:"model"`
+	Messages []Message `json:"messages"`
+}
+
+// CountTokens returns the input token count for messages. With
+// UseAPITokenCounting it asks the count_tokens endpoint; otherwise it
+// estimates about four characters per token.
 func (c *Client) CountTokens(ctx context.Context, messages []Message) (int, error) {
-	// Simple approximation: ~4 characters per token
-	// In production, use tiktoken or Claude's token counting endpoint
+	if c.UseAPITokenCounting {
+		return c.countTokensAPI(ctx, messages)
+	}
+
 	totalChars := 0
 	for _, msg := range messages {
 		totalChars += len(msg.Role) + len(msg.Content) + 10 // overhead
@@ -224,6 +478,45 @@ func (c *Client) CountTokens(ctx context.Context, messages []Message) (int, erro
 	return totalChars / 4, nil
 }
 
+// countTokensAPI counts tokens with the count_tokens endpoint, which does
+// not use the messages rate limit.
+func (c *Client) countTokensAPI(ctx context.Context, messages []Message) (int, error) {
+	jsonData, err := json.Marshal(countTokensRequest{
+		Model:    c.model,
+		Messages: messages,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal count tokens request: %w", err)
+	}
+
+	req, err := c.createRequest(ctx, "/v1/messages/count_tokens", jsonData)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("count tokens request failed: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to close response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, c.handleErrorResponse(resp)
+	}
+
+	var count struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&count); err != nil {
+		return 0, fmt.Errorf("failed to decode token count: %w", err)
+	}
+	return count.InputTokens, nil
+}
+
 // createRequest creates a new HTTP request with auth headers
 func (c *Client) createRequest(ctx context.Context, endpoint string, body []byte) (*http.Request, error) {
 	var req *http.Request
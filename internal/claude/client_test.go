@@ -3,14 +3,24 @@ package claude
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"golang.org/x/time/rate"
+
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/telemetry/telemetrytest"
 )
 
 func TestNewClient(t *testing.T) {
@@ -22,36 +32,97 @@ func TestNewClient(t *testing.T) {
 	assert.Equal(t, "claude-3-opus", client.model)
 	assert.NotNil(t, client.httpClient)
 	assert.NotNil(t, client.limiter)
+	assert.Equal(t, DefaultMaxIdleConnsPerHost, client.MaxIdleConnsPerHost)
+	assert.Equal(t, DefaultMaxConnsPerHost, client.MaxConnsPerHost)
+	assert.Equal(t, DefaultIdleConnTimeout, client.IdleConnTimeout)
+	assert.Equal(t, DefaultKeepAlive, client.KeepAlive)
+}
+
+func TestConnectionReuseUnderLoad(t *testing.T) {
+	const (
+		goroutines          = 50
+		requestsPerRoutine  = 4
+		maxConnsPerHost     = 20
+		maxIdleConnsPerHost = 20
+	)
+
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse("ok", 10, 20))
+	server.SetDelay(5 * time.Millisecond)
+
+	transport := NewTransport(maxIdleConnsPerHost, maxConnsPerHost, DefaultIdleConnTimeout, DefaultKeepAlive)
+	t.Cleanup(transport.CloseIdleConnections)
+	client := NewClientWithTransport("test-api-key", "claude-3-opus", transport, zerolog.Nop())
+	client.BaseURL = server.URL
+	client.limiter = rate.NewLimiter(rate.Inf, 0)
+	assert.Equal(t, maxConnsPerHost, client.MaxConnsPerHost)
+	assert.Zero(t, client.KeepAlive)
+
+	var reused, opened atomic.Int64
+	ctx := httptrace.WithClientTrace(context.Background(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				reused.Add(1)
+			} else {
+				opened.Add(1)
+			}
+		},
+	})
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines*requestsPerRoutine)
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range requestsPerRoutine {
+				_, err := client.SendMessage(ctx, []Message{{Role: "user", Content: "hi"}}, "", 10)
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	total := goroutines * requestsPerRoutine
+	assert.Equal(t, total, server.CallCount())
+	assert.Equal(t, int64(total), reused.Load()+opened.Load())
+	assert.LessOrEqual(t, opened.Load(), int64(maxConnsPerHost), "connections are capped per host")
+	assert.GreaterOrEqual(t, reused.Load(), int64(total-maxConnsPerHost), "idle connections are reused")
+	t.Logf("%d requests over %d connections (%d reused)", total, opened.Load(), reused.Load())
 }
 
 func TestSendMessage(t *testing.T) {
 	tests := []struct {
-		name           string
-		messages       []Message
-		serverResponse interface{}
-		statusCode     int
-		expectedError  bool
-		errorMessage   string
+		name          string
+		messages      []Message
+		response      *mockserver.Response
+		statusCode    int
+		errResponse   mockserver.ErrorResponse
+		expectedError bool
+		errorMessage  string
 	}{
 		{
 			name: "successful request",
 			messages: []Message{
 				{Role: "user", Content: "Hello"},
 			},
-			serverResponse: Response{
+			response: &mockserver.Response{
 				ID:   "msg_123",
 				Type: "message",
 				Role: "assistant",
-				Content: []ContentBlock{
+				Content: []mockserver.ContentBlock{
 					{Type: "text", Text: "Hello! How can I help you?"},
 				},
 				Model: "claude-3-opus",
-				Usage: Usage{
+				Usage: mockserver.Usage{
 					InputTokens:  10,
 					OutputTokens: 20,
 				},
 			},
-			statusCode:    http.StatusOK,
 			expectedError: false,
 		},
 		{
@@ -59,11 +130,11 @@ func TestSendMessage(t *testing.T) {
 			messages: []Message{
 				{Role: "user", Content: "Hello"},
 			},
-			serverResponse: ErrorResponse{
+			statusCode: http.StatusTooManyRequests,
+			errResponse: mockserver.ErrorResponse{
 				Type:    "rate_limit_error",
 				Message: "Rate limit exceeded",
 			},
-			statusCode:    http.StatusTooManyRequests,
 			expectedError: true,
 			errorMessage:  "Rate limit exceeded",
 		},
@@ -72,32 +143,29 @@ func TestSendMessage(t *testing.T) {
 			messages: []Message{
 				{Role: "user", Content: "Hello"},
 			},
-			serverResponse: ErrorResponse{
+			statusCode: http.StatusUnauthorized,
+			errResponse: mockserver.ErrorResponse{
 				Type:    "authentication_error",
 				Message: "Invalid API key",
 			},
-			statusCode:    http.StatusUnauthorized,
 			expectedError: true,
 			errorMessage:  "Invalid API key",
 		},
 	}
 
+	// Override retry delay for faster tests
+	originalDelay := RetryDelay
+	RetryDelay = 10 * time.Millisecond
+	defer func() { RetryDelay = originalDelay }()
+
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create test server
-			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-				// Verify headers
-				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
-				assert.Equal(t, "test-api-key", r.Header.Get("x-api-key"))
-				assert.Equal(t, apiVersion, r.Header.Get("anthropic-version"))
-
-				// Send response
-				w.WriteHeader(tt.statusCode)
-				if err := json.NewEncoder(w).Encode(tt.serverResponse); err != nil {
-					t.Fatalf("Failed to encode response: %v", err)
-				}
-			}))
-			defer server.Close()
+			server := mockserver.NewMockServer(t)
+			if tt.response != nil {
+				server.SetResponse(*tt.response)
+			} else {
+				server.SetError(tt.statusCode, tt.errResponse)
+			}
 
 			// Create client with test server
 			logger := zerolog.Nop()
@@ -108,6 +176,14 @@ func TestSendMessage(t *testing.T) {
 			ctx := context.Background()
 			resp, err := client.SendMessage(ctx, tt.messages, "", 100)
 
+			// Verify headers
+			request := server.LastRequest()
+			assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+			assert.Equal(t, "test-api-key", request.Header.Get("x-api-key"))
+			assert.Equal(t, apiVersion, request.Header.Get("anthropic-version"))
+			assert.Equal(t, "claude-3-opus", request.Model)
+			assert.Equal(t, 100, request.MaxTokens)
+
 			if tt.expectedError {
 				require.Error(t, err)
 				assert.Contains(t, err.Error(), tt.errorMessage)
@@ -123,37 +199,15 @@ func TestSendMessage(t *testing.T) {
 }
 
 func TestRetryLogic(t *testing.T) {
-	callCount := 0
-
-	// Create test server that fails twice then succeeds
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-
-		if callCount < 3 {
-			// Fail with server error (retryable)
-			w.WriteHeader(http.StatusInternalServerError)
-			if err := json.NewEncoder(w).Encode(ErrorResponse{
-				Type:    "internal_server_error",
-				Message: "Server error",
-			}); err != nil {
-				t.Fatalf("Failed to encode error response: %v", err)
-			}
-		} else {
-			// Succeed on third attempt
-			w.WriteHeader(http.StatusOK)
-			if err := json.NewEncoder(w).Encode(Response{
-				ID:   "msg_123",
-				Type: "message",
-				Role: "assistant",
-				Content: []ContentBlock{
-					{Type: "text", Text: "Success after retries"},
-				},
-			}); err != nil {
-				t.Fatalf("Failed to encode response: %v", err)
-			}
-		}
-	}))
-	defer server.Close()
+	// Fail twice with a retryable server error, then succeed
+	server := mockserver.NewMockServer(t)
+	for i := 0; i < 2; i++ {
+		server.QueueError(http.StatusInternalServerError, mockserver.ErrorResponse{
+			Type:    "internal_server_error",
+			Message: "Server error",
+		})
+	}
+	server.SetResponse(mockserver.TextResponse("Success after retries", 0, 0))
 
 	// Create client with test server
 	logger := zerolog.Nop()
@@ -172,7 +226,86 @@ func TestRetryLogic(t *testing.T) {
 	// Should succeed after retries
 	require.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, 3, callCount)
+	assert.Equal(t, "Success after retries", resp.Content[0].Text)
+	assert.Equal(t, 3, server.CallCount())
+}
+
+func TestSendMessageRateLimitError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "7")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type":"rate_limit_error","message":"Rate limit exceeded"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	originalDelay := RetryDelay
+	RetryDelay = time.Millisecond
+	defer func() { RetryDelay = originalDelay }()
+
+	_, err := client.SendMessage(context.Background(), []Message{{Role: "user", Content: "Test"}}, "", 100)
+	require.Error(t, err)
+
+	var rateLimited *apperrors.RateLimitError
+	require.True(t, errors.As(err, &rateLimited))
+	assert.Equal(t, 7*time.Second, rateLimited.RetryAfter)
+	assert.ErrorIs(t, err, apperrors.ErrRateLimited)
+
+	// The API's own error is still reachable
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, "Rate limit exceeded", apiErr.Message)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30", now))
+	assert.Equal(t, 90*time.Second, parseRetryAfter(now.Add(90*time.Second).Format(http.TimeFormat), now))
+	assert.Zero(t, parseRetryAfter("", now))
+	assert.Zero(t, parseRetryAfter("-5", now))
+	assert.Zero(t, parseRetryAfter("soon", now))
+	assert.Zero(t, parseRetryAfter(now.Add(-time.Minute).Format(http.TimeFormat), now))
+}
+
+func TestMessageJSON(t *testing.T) {
+	plain, err := json.Marshal(Message{Role: "user", Content: "hi"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":"hi"}`, string(plain))
+
+	cached, err := json.Marshal(Message{Role: "user", Content: "hi", CacheControl: EphemeralCache})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role":"user","content":[{"type":"text","text":"hi","cache_control":{"type":"ephemeral"}}]}`, string(cached))
+
+	var decoded Message
+	require.NoError(t, json.Unmarshal(cached, &decoded))
+	assert.Equal(t, Message{Role: "user", Content: "hi", CacheControl: EphemeralCache}, decoded)
+}
+
+func TestPromptCaching(t *testing.T) {
+	server := mockserver.NewMockServer(t)
+	server.EnablePromptCaching()
+	server.SetResponse(mockserver.TextResponse("ok", 10, 20))
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	messages, err := SDKAnalysisPrompt("sentry-go", "1.0.0", map[string]string{"main.go": "package main"})
+	require.NoError(t, err)
+	require.NotNil(t, messages[0].CacheControl)
+
+	first, err := client.SendMessage(context.Background(), messages, "", 100)
+	require.NoError(t, err)
+	assert.Positive(t, first.Usage.CacheCreationInputTokens)
+	assert.Zero(t, first.Usage.CacheReadInputTokens)
+
+	second, err := client.SendMessage(context.Background(), messages, "", 100)
+	require.NoError(t, err)
+	assert.Zero(t, second.Usage.CacheCreationInputTokens)
+	assert.Equal(t, first.Usage.CacheCreationInputTokens, second.Usage.CacheReadInputTokens)
+	assert.Equal(t, 10+20+second.Usage.CacheReadInputTokens, second.Usage.TotalTokens())
 }
 
 func TestCountTokens(t *testing.T) {
@@ -193,6 +326,51 @@ func TestCountTokens(t *testing.T) {
 	assert.Less(t, count, 30)
 }
 
+func TestCountTokensAPI(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/count_tokens", r.URL.Path)
+		assert.Equal(t, "test-api-key", r.Header.Get("x-api-key"))
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		if body["model"] == "bad-model" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"type":"invalid_request_error","message":"unknown model"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"input_tokens": 1234}`))
+	}))
+	defer server.Close()
+
+	messages := []Message{{Role: "user", Content: "Hello, how are you?"}}
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+	client.UseAPITokenCounting = true
+
+	count, err := client.CountTokens(context.Background(), messages)
+	require.NoError(t, err)
+	assert.Equal(t, 1234, count)
+	assert.Equal(t, "claude-3-opus", body["model"])
+	assert.NotContains(t, body, "max_tokens")
+
+	// The heuristic is used when API counting is off
+	client.UseAPITokenCounting = false
+	count, err = client.CountTokens(context.Background(), messages)
+	require.NoError(t, err)
+	assert.NotEqual(t, 1234, count)
+
+	// API errors are returned rather than falling back to the heuristic
+	badClient := NewClient("test-api-key", "bad-model", zerolog.Nop())
+	badClient.BaseURL = server.URL
+	badClient.UseAPITokenCounting = true
+	_, err = badClient.CountTokens(context.Background(), messages)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
+
 func TestAPIError(t *testing.T) {
 	err := &APIError{
 		StatusCode: 429,
@@ -247,3 +425,39 @@ func TestIsRetryableError(t *testing.T) {
 		})
 	}
 }
+
+func TestSendMessageSpan(t *testing.T) {
+	recorder := telemetrytest.NewRecorder(t)
+
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse("ok", 120, 30))
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	ctx := WithSDKName(context.Background(), "sentry-go")
+	_, err := client.SendMessage(ctx, []Message{{Role: "user", Content: "hi"}}, "", 100)
+	require.NoError(t, err)
+
+	spans := recorder.Named("claude POST /v1/messages")
+	require.Len(t, spans, 1)
+	attrs := telemetrytest.Attributes(spans[0])
+	assert.Equal(t, "anthropic", attrs["gen_ai.system"])
+	assert.Equal(t, "claude-3-opus", attrs["gen_ai.request.model"])
+	assert.Equal(t, "sentry-go", attrs["sdk.name"])
+	assert.Equal(t, int64(120), attrs["gen_ai.usage.input_tokens"])
+	assert.Equal(t, int64(30), attrs["gen_ai.usage.output_tokens"])
+	assert.Equal(t, int64(150), attrs["claude.total_tokens"])
+	assert.Equal(t, codes.Unset, spans[0].Status.Code)
+
+	// Failed requests are recorded as errors
+	recorder.Reset()
+	server.SetError(http.StatusBadRequest, mockserver.ErrorResponse{Type: "invalid_request_error", Message: "bad"})
+	_, err = client.SendMessage(context.Background(), []Message{{Role: "user", Content: "hi"}}, "", 100)
+	require.Error(t, err)
+
+	spans = recorder.Named("claude POST /v1/messages")
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+	assert.NotContains(t, telemetrytest.Attributes(spans[0]), "sdk.name")
+}
@@ -0,0 +1,291 @@
+// Package mockserver provides an in-process fake of the Claude Messages API
+// for hermetic tests.
+package mockserver
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Message mirrors a message in the Claude API wire format.
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+
+	// CacheControl is set when a content block was marked for caching
+	CacheControl *CacheControl `json:"-"`
+}
+
+// CacheControl mirrors a content block's cache_control.
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// UnmarshalJSON accepts content either as a string or as text blocks,
+// which are joined.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	m.Role = raw.Role
+
+	if err := json.Unmarshal(raw.Content, &m.Content); err == nil {
+		return nil
+	}
+	var blocks []struct {
+		Text         string        `json:"text"`
+		CacheControl *CacheControl `json:"cache_control"`
+	}
+	if err := json.Unmarshal(raw.Content, &blocks); err != nil {
+		return err
+	}
+	for _, block := range blocks {
+		m.Content += block.Text
+		if block.CacheControl != nil {
+			m.CacheControl = block.CacheControl
+		}
+	}
+	return nil
+}
+
+// Request is a Messages API request received by the mock server.
+type Request struct {
+	Model       string      `json:"model"`
+	Messages    []Message   `json:"messages"`
+	MaxTokens   int         `json:"max_tokens"`
+	Temperature float64     `json:"temperature,omitempty"`
+	System      string      `json:"system,omitempty"`
+	Path        string      `json:"-"`
+	Header      http.Header `json:"-"`
+}
+
+// Response is a Messages API response returned by the mock server.
+type Response struct {
+	ID      string         `json:"id"`
+	Type    string         `json:"type"`
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+	Model   string         `json:"model"`
+	Usage   Usage          `json:"usage"`
+}
+
+// ContentBlock mirrors a content block in the Claude API wire format.
+type ContentBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Usage mirrors token usage in the Claude API wire format.
+type Usage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+}
+
+// ErrorResponse is an API error body returned by the mock server.
+type ErrorResponse struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// TextResponse builds a successful response with a single text block.
+func TextResponse(text string, inputTokens, outputTokens int) Response {
+	return Response{
+		ID:   "msg_mock",
+		Type: "message",
+		Role: "assistant",
+		Content: []ContentBlock{
+			{Type: "text", Text: text},
+		},
+		Usage: Usage{
+			InputTokens:  inputTokens,
+			OutputTokens: outputTokens,
+		},
+	}
+}
+
+// reply is a canned HTTP response.
+type reply struct {
+	status int
+	body   interface{}
+}
+
+// MockServer is an httptest.Server that answers Claude API requests with
+// configurable responses and records what it received.
+type MockServer struct {
+	*httptest.Server
+
+	t        *testing.T
+	mu       sync.Mutex
+	fallback reply
+	queue    []reply
+	delay    time.Duration
+	calls    int
+	last     Request
+	cached   map[string]bool
+}
+
+// NewMockServer starts a mock server that is closed when the test finishes.
+// Until configured it answers every request with an empty text response.
+func NewMockServer(t *testing.T) *MockServer {
+	t.Helper()
+
+	m := &MockServer{
+		t:        t,
+		fallback: reply{status: http.StatusOK, body: TextResponse("", 0, 0)},
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// SetResponse sets the response returned once the queue is empty.
+func (m *MockServer) SetResponse(r Response) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = reply{status: http.StatusOK, body: r}
+}
+
+// SetError makes the server fail with the given status once the queue is empty.
+func (m *MockServer) SetError(statusCode int, err ErrorResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.fallback = reply{status: statusCode, body: err}
+}
+
+// QueueResponses queues responses to be returned, in order, before the
+// default response.
+func (m *MockServer) QueueResponses(responses []Response) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range responses {
+		m.queue = append(m.queue, reply{status: http.StatusOK, body: r})
+	}
+}
+
+// QueueError queues a single error response.
+func (m *MockServer) QueueError(statusCode int, err ErrorResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queue = append(m.queue, reply{status: statusCode, body: err})
+}
+
+// SetDelay delays every response by d, or until the client gives up.
+func (m *MockServer) SetDelay(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.delay = d
+}
+
+// EnablePromptCaching simulates prompt caching: the first request with a
+// message marked for caching reports the cached prefix as cache creation
+// tokens, and later requests with the same prefix report it as cache read
+// tokens.
+func (m *MockServer) EnablePromptCaching() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.cached == nil {
+		m.cached = make(map[string]bool)
+	}
+}
+
+// CallCount returns the number of requests received.
+func (m *MockServer) CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// LastRequest returns the most recently received request.
+func (m *MockServer) LastRequest() Request {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+func (m *MockServer) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		m.t.Errorf("mockserver: failed to read request body: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var req Request
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &req); err != nil {
+			m.t.Errorf("mockserver: failed to decode request: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+	req.Path = r.URL.Path
+	req.Header = r.Header.Clone()
+
+	m.mu.Lock()
+	m.calls++
+	m.last = req
+	next := m.fallback
+	if len(m.queue) > 0 {
+		next = m.queue[0]
+		m.queue = m.queue[1:]
+	}
+	if resp, ok := next.body.(Response); ok && m.cached != nil {
+		next.body = m.applyPromptCache(req, resp)
+	}
+	delay := m.delay
+	m.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(next.status)
+	if err := json.NewEncoder(w).Encode(next.body); err != nil {
+		m.t.Errorf("mockserver: failed to encode response: %v", err)
+	}
+}
+
+// applyPromptCache reports the request's cacheable prefix, at roughly four
+// characters per token, as written to or read from the cache. The caller
+// must hold m.mu.
+func (m *MockServer) applyPromptCache(req Request, resp Response) Response {
+	prefix := req.System
+	end := -1
+	for i, msg := range req.Messages {
+		if msg.CacheControl != nil {
+			end = i
+		}
+	}
+	if end < 0 {
+		return resp
+	}
+	for _, msg := range req.Messages[:end+1] {
+		prefix += "\x00" + msg.Role + "\x00" + msg.Content
+	}
+
+	tokens := len(prefix) / 4
+	if m.cached[prefix] {
+		resp.Usage.CacheReadInputTokens = tokens
+	} else {
+		resp.Usage.CacheCreationInputTokens = tokens
+		m.cached[prefix] = true
+	}
+	return resp
+}
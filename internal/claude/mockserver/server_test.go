@@ -0,0 +1,110 @@
+package mockserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(t *testing.T, m *MockServer, req Request) (int, []byte) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	resp, err := http.Post(m.URL+"/v1/messages", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, resp.Body.Close())
+	}()
+
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, buf.Bytes()
+}
+
+func TestQueueResponsesInOrder(t *testing.T) {
+	m := NewMockServer(t)
+	m.SetResponse(TextResponse("default", 1, 1))
+	m.QueueResponses([]Response{
+		TextResponse("first", 1, 1),
+		TextResponse("second", 1, 1),
+		TextResponse("third", 1, 1),
+	})
+
+	for _, expected := range []string{"first", "second", "third", "default", "default"} {
+		status, body := post(t, m, Request{Messages: []Message{{Role: "user", Content: "hi"}}})
+		require.Equal(t, http.StatusOK, status)
+
+		var resp Response
+		require.NoError(t, json.Unmarshal(body, &resp))
+		assert.Equal(t, expected, resp.Content[0].Text)
+	}
+
+	assert.Equal(t, 5, m.CallCount())
+}
+
+func TestCallCountAndLastRequest(t *testing.T) {
+	m := NewMockServer(t)
+	assert.Equal(t, 0, m.CallCount())
+
+	for i := 1; i <= 3; i++ {
+		post(t, m, Request{
+			Model:     "claude-test",
+			MaxTokens: i * 100,
+			Messages:  []Message{{Role: "user", Content: "hello"}},
+		})
+		assert.Equal(t, i, m.CallCount())
+	}
+
+	last := m.LastRequest()
+	assert.Equal(t, "claude-test", last.Model)
+	assert.Equal(t, 300, last.MaxTokens)
+	assert.Equal(t, "/v1/messages", last.Path)
+	assert.Equal(t, "application/json", last.Header.Get("Content-Type"))
+	require.Len(t, last.Messages, 1)
+	assert.Equal(t, "hello", last.Messages[0].Content)
+}
+
+func TestSetError(t *testing.T) {
+	m := NewMockServer(t)
+	m.QueueError(http.StatusInternalServerError, ErrorResponse{Type: "api_error", Message: "queued"})
+	m.SetError(http.StatusTooManyRequests, ErrorResponse{Type: "rate_limit_error", Message: "slow down"})
+
+	status, body := post(t, m, Request{})
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Contains(t, string(body), "queued")
+
+	status, body = post(t, m, Request{})
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	var errResp ErrorResponse
+	require.NoError(t, json.Unmarshal(body, &errResp))
+	assert.Equal(t, "rate_limit_error", errResp.Type)
+	assert.Equal(t, "slow down", errResp.Message)
+}
+
+func TestSetDelay(t *testing.T) {
+	m := NewMockServer(t)
+	m.SetDelay(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.URL+"/v1/messages", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = http.DefaultClient.Do(req)
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 1, m.CallCount())
+}
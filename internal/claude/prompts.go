@@ -5,8 +5,49 @@ import (
 	"strings"
 )
 
-// SDKAnalysisPrompt generates a prompt for analyzing SDK code
-func SDKAnalysisPrompt(sdkName, version string, codeFiles map[string]string) string {
+// SDKAnalysisPrompt generates the messages for analyzing SDK code with the
+// sdk_analysis template. The static instructions come first and are marked
+// for prompt caching, so repeated analyses only pay full price for the
+// SDK's code.
+func SDKAnalysisPrompt(sdkName, version string, codeFiles map[string]string) ([]Message, error) {
+	t, _ := GetPromptTemplate(PromptSDKAnalysis)
+	return AnalysisPrompt(t, PromptData{
+		SDKName: sdkName,
+		Version: version,
+		Code:    FormatCodeFiles(codeFiles),
+	})
+}
+
+// IncrementalAnalysisPrompt generates the messages for updating an SDK's
+// previous analysis, given as JSON, from the files changed since it was
+// made, with the incremental_analysis template. It shares the cacheable
+// instructions with SDKAnalysisPrompt.
+func IncrementalAnalysisPrompt(sdkName, version string, changedFiles map[string]string, previousAnalysis string) ([]Message, error) {
+	t, _ := GetPromptTemplate(PromptIncrementalAnalysis)
+	return AnalysisPrompt(t, PromptData{
+		SDKName:          sdkName,
+		Version:          version,
+		Code:             FormatCodeFiles(changedFiles),
+		PreviousAnalysis: previousAnalysis,
+	})
+}
+
+// AnalysisPrompt generates the messages for an analysis, following the
+// cacheable instructions with t rendered for data.
+func AnalysisPrompt(t *PromptTemplate, data PromptData) ([]Message, error) {
+	userPrompt, err := t.Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Message{
+		{Role: "user", Content: sdkAnalysisInstructions, CacheControl: EphemeralCache},
+		{Role: "user", Content: userPrompt},
+	}, nil
+}
+
+// FormatCodeFiles renders code files for a prompt, truncating long ones.
+func FormatCodeFiles(codeFiles map[string]string) string {
 	var codeSnippets []string
 	for filename, content := range codeFiles {
 		// Limit file content to prevent token overflow
@@ -16,54 +57,7 @@ func SDKAnalysisPrompt(sdkName, version string, codeFiles map[string]string) str
 		}
 		codeSnippets = append(codeSnippets, fmt.Sprintf("File: %s\n```\n%s\n```", filename, truncatedContent))
 	}
-
-	systemPrompt := `You are an expert SDK analyzer specializing in Sentry SDKs. Your task is to analyze SDK code and extract key patterns and implementation details.
-
-Focus on:
-1. Envelope format and structure
-2. Transport implementation (HTTP, queue, retry logic)
-3. Error handling patterns
-4. Protocol versions and compatibility
-5. Caching strategies
-6. Key features and integrations
-
-Provide a structured analysis in JSON format.`
-
-	userPrompt := fmt.Sprintf(`Analyze the following %s SDK (version %s) code and extract implementation patterns:
-
-%s
-
-Provide your analysis in the following JSON format:
-{
-  "language": "detected programming language",
-  "envelope_format": "description of envelope format used",
-  "transport": {
-    "type": "http/grpc/other",
-    "protocols": ["list of protocols"],
-    "retry_mechanism": "description of retry logic",
-    "queue_implementation": "description of queue if any"
-  },
-  "event_types": ["list of supported event types"],
-  "error_patterns": [
-    {
-      "name": "pattern name",
-      "pattern": "code pattern",
-      "description": "what it does"
-    }
-  ],
-  "integrations": ["list of framework integrations"],
-  "features": ["list of key features"],
-  "protocol_version": "detected protocol version",
-  "caching_patterns": [
-    {
-      "type": "cache type",
-      "location": "where it's used",
-      "description": "how it works"
-    }
-  ]
-}`, sdkName, version, strings.Join(codeSnippets, "\n\n"))
-
-	return systemPrompt + "\n\n" + userPrompt
+	return strings.Join(codeSnippets, "\n\n")
 }
 
 // BatchAnalysisPrompt creates a prompt for batch SDK analysis
@@ -0,0 +1,188 @@
+package claude
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxStreamLine bounds a single line of a streamed response
+const maxStreamLine = 1 << 20
+
+// StreamEvent is an event from a streamed Claude API response
+type StreamEvent struct {
+	// Type is the API event type: content_block_delta, message_delta,
+	// message_stop or error
+	Type string
+	// Delta is the text added by a content_block_delta event
+	Delta string
+	// StopReason is set on message_delta events
+	StopReason string
+	// Response is the accumulated message, set on the message_stop event
+	Response *Response
+	// Err is set on error events, after which the channel is closed
+	Err error
+}
+
+// streamFrame is the payload of a "data:" line in a streamed response
+type streamFrame struct {
+	Type         string         `json:"type"`
+	Index        int            `json:"index"`
+	Message      *Response      `json:"message"`
+	ContentBlock *ContentBlock  `json:"content_block"`
+	Usage        *Usage         `json:"usage"`
+	Error        *ErrorResponse `json:"error"`
+	Delta        struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"`
+	} `json:"delta"`
+}
+
+// SendMessageStream sends a message to Claude API with streaming enabled
+// and emits text deltas as they arrive. The channel is closed after the
+// message_stop event, which carries the accumulated response, after an
+// error event, or when ctx is cancelled. Streamed requests are not retried;
+// like SendMessage they fail with ErrCircuitOpen while the circuit breaker
+// is open, and only the response status counts towards it.
+func (c *Client) SendMessageStream(ctx context.Context, messages []Message, system string, maxTokens int) (<-chan StreamEvent, error) {
+	// Rate limiting
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	jsonData, err := json.Marshal(Request{
+		Model:     c.model,
+		Messages:  messages,
+		MaxTokens: maxTokens,
+		System:    system,
+		Stream:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := c.createRequest(ctx, "/v1/messages", jsonData)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	if err := c.breaker.Allow(); err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("request failed: %w", err)
+		c.recordResult(err)
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				c.logger.Error().Err(err).Msg("Failed to close response body")
+			}
+		}()
+		err := c.handleErrorResponse(resp)
+		c.recordResult(err)
+		return nil, err
+	}
+	c.recordResult(nil)
+
+	events := make(chan StreamEvent)
+	go c.readStream(ctx, resp, events)
+	return events, nil
+}
+
+// readStream parses server-sent events from resp into events until the
+// message ends, the stream fails or ctx is cancelled.
+func (c *Client) readStream(ctx context.Context, resp *http.Response, events chan<- StreamEvent) {
+	defer close(events)
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.logger.Error().Err(err).Msg("Failed to close response body")
+		}
+	}()
+
+	emit := func(event StreamEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	fail := func(err error) {
+		emit(StreamEvent{Type: "error", Err: err})
+	}
+
+	var message Response
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStreamLine)
+	for scanner.Scan() {
+		// Only data lines carry payloads; event names are repeated in them
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+
+		var frame streamFrame
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &frame); err != nil {
+			fail(fmt.Errorf("failed to parse stream event: %w", err))
+			return
+		}
+
+		switch frame.Type {
+		case "message_start":
+			if frame.Message != nil {
+				message = *frame.Message
+			}
+		case "content_block_start":
+			if frame.ContentBlock != nil {
+				message.Content = append(message.Content, *frame.ContentBlock)
+			}
+		case "content_block_delta":
+			if frame.Delta.Type != "text_delta" {
+				continue
+			}
+			for len(message.Content) <= frame.Index {
+				message.Content = append(message.Content, ContentBlock{Type: "text"})
+			}
+			message.Content[frame.Index].Text += frame.Delta.Text
+			if !emit(StreamEvent{Type: frame.Type, Delta: frame.Delta.Text}) {
+				return
+			}
+		case "message_delta":
+			message.StopReason = frame.Delta.StopReason
+			if frame.Usage != nil {
+				message.Usage.OutputTokens = frame.Usage.OutputTokens
+			}
+			if !emit(StreamEvent{Type: frame.Type, StopReason: frame.Delta.StopReason}) {
+				return
+			}
+		case "message_stop":
+			emit(StreamEvent{Type: frame.Type, StopReason: message.StopReason, Response: &message})
+			return
+		case "error":
+			if frame.Error == nil {
+				fail(fmt.Errorf("API stream error"))
+				return
+			}
+			fail(fmt.Errorf("API stream error (%s): %s", frame.Error.Type, frame.Error.Message))
+			return
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if err := scanner.Err(); err != nil {
+		fail(fmt.Errorf("failed to read stream: %w", err))
+		return
+	}
+	fail(fmt.Errorf("stream ended before message_stop"))
+}
@@ -0,0 +1,204 @@
+package claude
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sseFrames is a canned streamed response that writes "Hello, world".
+var sseFrames = []string{
+	`event: message_start
+data: {"type":"message_start","message":{"id":"msg_stream","type":"message","role":"assistant","content":[],"model":"claude-3-opus","usage":{"input_tokens":12,"output_tokens":1}}}`,
+	`event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+	`event: ping
+data: {"type":"ping"}`,
+	`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+	`event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":", world"}}`,
+	`event: content_block_stop
+data: {"type":"content_block_stop","index":0}`,
+	`event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":5}}`,
+	`event: message_stop
+data: {"type":"message_stop"}`,
+}
+
+// newSSEServer serves frames as a text/event-stream, flushing after each.
+func newSSEServer(t *testing.T, frames []string, requests chan<- Request) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("failed to read request: %v", err)
+			return
+		}
+		var req Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+			return
+		}
+		if requests != nil {
+			requests <- req
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		for _, frame := range frames {
+			if _, err := fmt.Fprintf(w, "%s\n\n", frame); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func collectStream(t *testing.T, events <-chan StreamEvent) []StreamEvent {
+	t.Helper()
+
+	var collected []StreamEvent
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return collected
+			}
+			collected = append(collected, event)
+		case <-timeout:
+			t.Fatal("stream was not closed")
+		}
+	}
+}
+
+func TestSendMessageStream(t *testing.T) {
+	requests := make(chan Request, 1)
+	server := newSSEServer(t, sseFrames, requests)
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	events, err := client.SendMessageStream(context.Background(), []Message{{Role: "user", Content: "Hi"}}, "", 100)
+	require.NoError(t, err)
+	collected := collectStream(t, events)
+
+	req := <-requests
+	assert.True(t, req.Stream)
+	assert.Equal(t, 100, req.MaxTokens)
+
+	require.Len(t, collected, 4)
+	assert.Equal(t, StreamEvent{Type: "content_block_delta", Delta: "Hello"}, collected[0])
+	assert.Equal(t, StreamEvent{Type: "content_block_delta", Delta: ", world"}, collected[1])
+	assert.Equal(t, StreamEvent{Type: "message_delta", StopReason: "end_turn"}, collected[2])
+
+	stop := collected[3]
+	assert.Equal(t, "message_stop", stop.Type)
+	require.NoError(t, stop.Err)
+	require.NotNil(t, stop.Response)
+	assert.Equal(t, "msg_stream", stop.Response.ID)
+	require.Len(t, stop.Response.Content, 1)
+	assert.Equal(t, "Hello, world", stop.Response.Content[0].Text)
+	assert.Equal(t, "end_turn", stop.Response.StopReason)
+	assert.Equal(t, 12, stop.Response.Usage.InputTokens)
+	assert.Equal(t, 5, stop.Response.Usage.OutputTokens)
+}
+
+func TestSendMessageStreamErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		frames       []string
+		errorMessage string
+	}{
+		{
+			name: "error event",
+			frames: append(sseFrames[:4:4], `event: error
+data: {"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`),
+			errorMessage: "Overloaded",
+		},
+		{
+			name:         "stream ends early",
+			frames:       sseFrames[:4],
+			errorMessage: "stream ended before message_stop",
+		},
+		{
+			name:         "malformed event",
+			frames:       []string{"data: {not json"},
+			errorMessage: "failed to parse stream event",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newSSEServer(t, tt.frames, nil)
+			client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+			client.BaseURL = server.URL
+
+			events, err := client.SendMessageStream(context.Background(), []Message{{Role: "user", Content: "Hi"}}, "", 100)
+			require.NoError(t, err)
+			collected := collectStream(t, events)
+
+			require.NotEmpty(t, collected)
+			last := collected[len(collected)-1]
+			assert.Equal(t, "error", last.Type)
+			require.Error(t, last.Err)
+			assert.Contains(t, last.Err.Error(), tt.errorMessage)
+		})
+	}
+}
+
+func TestSendMessageStreamAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"type":"authentication_error","message":"Invalid API key"}`)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	_, err := client.SendMessageStream(context.Background(), []Message{{Role: "user", Content: "Hi"}}, "", 100)
+	require.Error(t, err)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestSendMessageStreamCancel(t *testing.T) {
+	// Send one delta, then hold the stream open until the client goes away
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, strings.Join(sseFrames[:4], "\n\n")+"\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key", "claude-3-opus", zerolog.Nop())
+	client.BaseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := client.SendMessageStream(ctx, []Message{{Role: "user", Content: "Hi"}}, "", 100)
+	require.NoError(t, err)
+
+	first := <-events
+	assert.Equal(t, "Hello", first.Delta)
+
+	cancel()
+	collectStream(t, events)
+}
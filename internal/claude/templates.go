@@ -0,0 +1,147 @@
+package claude
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Names of the analysis prompt templates.
+const (
+	PromptSDKAnalysis         = "sdk_analysis"
+	PromptIncrementalAnalysis = "incremental_analysis"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// sdkAnalysisInstructions is the part of the SDK analysis prompt that is
+// the same for every SDK, sent first so it can be served from the prompt
+// cache. It is not a template so that it stays the same.
+var sdkAnalysisInstructions = strings.TrimSuffix(mustReadTemplateFile("templates/instructions.txt"), "\n")
+
+// PromptData is what analysis prompt templates are rendered with.
+type PromptData struct {
+	SDKName string
+	Version string
+
+	// Code holds the SDK's files formatted for the prompt
+	Code string
+
+	// PreviousAnalysis is the JSON of the analysis an incremental analysis
+	// updates, and empty otherwise
+	PreviousAnalysis string
+}
+
+// PromptTemplate is a text/template rendering the SDK-specific part of an
+// analysis prompt from PromptData.
+type PromptTemplate struct {
+	Name string `json:"name"`
+
+	// Source is "embedded" for the built-in templates, the file an override
+	// was loaded from, or "request" for a request's PromptOverride
+	Source string `json:"source"`
+	Text   string `json:"template"`
+
+	tmpl *template.Template
+}
+
+// ParsePromptTemplate parses text as the named prompt template. A trailing
+// newline, as most files end with, is not part of the prompt.
+func ParsePromptTemplate(name, source, text string) (*PromptTemplate, error) {
+	text = strings.TrimSuffix(text, "\n")
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", name, err)
+	}
+
+	// Catch references to fields PromptData does not have now rather than
+	// when the first SDK is analyzed
+	if err := tmpl.Execute(&strings.Builder{}, PromptData{}); err != nil {
+		return nil, fmt.Errorf("invalid prompt template %s: %w", name, err)
+	}
+	return &PromptTemplate{Name: name, Source: source, Text: text, tmpl: tmpl}, nil
+}
+
+// LoadPromptTemplate reads the named prompt template from path.
+func LoadPromptTemplate(name, path string) (*PromptTemplate, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompt template: %w", err)
+	}
+	return ParsePromptTemplate(name, path, string(text))
+}
+
+// Render returns the prompt for data.
+func (t *PromptTemplate) Render(data PromptData) (string, error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", t.Name, err)
+	}
+	return b.String(), nil
+}
+
+// promptTemplates holds the templates in use, the embedded ones unless
+// SetPromptTemplate replaced them.
+var promptTemplates = struct {
+	mu      sync.RWMutex
+	current map[string]*PromptTemplate
+}{
+	current: map[string]*PromptTemplate{
+		PromptSDKAnalysis:         mustEmbeddedTemplate(PromptSDKAnalysis),
+		PromptIncrementalAnalysis: mustEmbeddedTemplate(PromptIncrementalAnalysis),
+	},
+}
+
+// GetPromptTemplate returns the named prompt template in use.
+func GetPromptTemplate(name string) (*PromptTemplate, bool) {
+	promptTemplates.mu.RLock()
+	defer promptTemplates.mu.RUnlock()
+	t, ok := promptTemplates.current[name]
+	return t, ok
+}
+
+// PromptTemplateNames returns the names of the prompt templates, sorted.
+func PromptTemplateNames() []string {
+	promptTemplates.mu.RLock()
+	defer promptTemplates.mu.RUnlock()
+	names := make([]string, 0, len(promptTemplates.current))
+	for name := range promptTemplates.current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetPromptTemplate replaces the prompt template named t.Name for every
+// analysis made after it returns. Only the names of embedded templates are
+// used.
+func SetPromptTemplate(t *PromptTemplate) error {
+	promptTemplates.mu.Lock()
+	defer promptTemplates.mu.Unlock()
+	if _, ok := promptTemplates.current[t.Name]; !ok {
+		return fmt.Errorf("unknown prompt template %s", t.Name)
+	}
+	promptTemplates.current[t.Name] = t
+	return nil
+}
+
+// mustEmbeddedTemplate parses the embedded template of name, panicking if
+// it is invalid so that a broken build fails at startup.
+func mustEmbeddedTemplate(name string) *PromptTemplate {
+	text := strings.TrimSuffix(mustReadTemplateFile("templates/"+name+".tmpl"), "\n")
+	tmpl := template.Must(template.New(name).Parse(text))
+	return &PromptTemplate{Name: name, Source: "embedded", Text: text, tmpl: tmpl}
+}
+
+func mustReadTemplateFile(path string) string {
+	text, err := templateFiles.ReadFile(path)
+	if err != nil {
+		panic(err)
+	}
+	return string(text)
+}
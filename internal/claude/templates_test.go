@@ -0,0 +1,82 @@
+package claude
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPromptTemplateRender(t *testing.T) {
+	data := PromptData{
+		SDKName:          "sentry-go",
+		Version:          "0.29.1",
+		Code:             FormatCodeFiles(map[string]string{"client.go": "package sentry"}),
+		PreviousAnalysis: `{"language":"go"}`,
+	}
+
+	for _, name := range PromptTemplateNames() {
+		t.Run(name, func(t *testing.T) {
+			prompt, ok := GetPromptTemplate(name)
+			require.True(t, ok)
+			assert.Equal(t, "embedded", prompt.Source)
+
+			rendered, err := prompt.Render(data)
+			require.NoError(t, err)
+			assert.Contains(t, rendered, "sentry-go SDK")
+			assert.Contains(t, rendered, "version 0.29.1")
+			assert.Contains(t, rendered, "File: client.go")
+			assert.NotContains(t, rendered, "{{")
+		})
+	}
+
+	messages, err := IncrementalAnalysisPrompt("sentry-go", "0.29.1", map[string]string{"client.go": "package sentry"}, `{"language":"go"}`)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, sdkAnalysisInstructions, messages[0].Content)
+	assert.Contains(t, messages[1].Content, `{"language":"go"}`)
+}
+
+func TestParsePromptTemplate(t *testing.T) {
+	prompt, err := ParsePromptTemplate("custom", "request", "Summarize {{.SDKName}} {{.Version}}\n")
+	require.NoError(t, err)
+	rendered, err := prompt.Render(PromptData{SDKName: "sentry-ruby", Version: "5.0.0"})
+	require.NoError(t, err)
+	assert.Equal(t, "Summarize sentry-ruby 5.0.0", rendered)
+
+	_, err = ParsePromptTemplate("custom", "request", "Summarize {{.SDKName")
+	assert.Error(t, err)
+
+	// Fields PromptData does not have are caught when parsing
+	_, err = ParsePromptTemplate("custom", "request", "Summarize {{.Name}}")
+	assert.Error(t, err)
+}
+
+func TestSetPromptTemplate(t *testing.T) {
+	embedded, ok := GetPromptTemplate(PromptSDKAnalysis)
+	require.True(t, ok)
+	t.Cleanup(func() {
+		require.NoError(t, SetPromptTemplate(embedded))
+	})
+
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte("Only check {{.SDKName}} at {{.Version}}.\n"), 0o600))
+	override, err := LoadPromptTemplate(PromptSDKAnalysis, path)
+	require.NoError(t, err)
+	assert.Equal(t, path, override.Source)
+	require.NoError(t, SetPromptTemplate(override))
+
+	messages, err := SDKAnalysisPrompt("sentry-go", "0.29.1", nil)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "Only check sentry-go at 0.29.1.", messages[1].Content)
+
+	unknown, err := ParsePromptTemplate("unknown", "request", "{{.SDKName}}")
+	require.NoError(t, err)
+	assert.Error(t, SetPromptTemplate(unknown))
+
+	_, err = LoadPromptTemplate(PromptSDKAnalysis, filepath.Join(t.TempDir(), "missing.tmpl"))
+	assert.Error(t, err)
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/joho/godotenv"
+	"github.com/rs/zerolog"
 )
 
 // Config holds all configuration for the service.
@@ -15,40 +21,262 @@ type Config struct {
 	Version string
 	Debug   bool
 
+	// TLS configuration. With TLSEnabled, HTTPS is served on HTTPSPort
+	// instead of Port, with a Let's Encrypt certificate for TLSDomain or
+	// else the certificate in TLSCertFile and TLSKeyFile. Let's Encrypt
+	// challenges are answered on HTTPPort, which redirects to HTTPS.
+	TLSEnabled  bool
+	TLSDomain   string
+	TLSCertFile string
+	TLSKeyFile  string
+	HTTPPort    string
+	HTTPSPort   string
+
 	// Cache configuration
 	CacheDir       string
 	UpdateSchedule string
 	CacheTTL       time.Duration
 	MaxCacheSize   int64
 
+	// Values longer than CompressThreshold bytes are stored gzipped; zero
+	// disables compression
+	CompressThreshold int64
+
+	// L1CacheSize is how many hot entries are kept in memory in front of
+	// the BuntDB backend; zero disables the memory cache
+	L1CacheSize int
+
+	// CacheBackend stores entries in "buntdb" (a file in CacheDir) or
+	// "redis" (the server at RedisURL)
+	CacheBackend string
+
+	// SDK analyses younger than StaleThreshold are not re-warmed
+	StaleThreshold time.Duration
+
+	// Periodic compaction of the cache database file
+	AutoCompact     bool
+	CompactSchedule string
+
+	// Free disk space that must remain after cloning a repository
+	MinFreeDiskBytes int64
+
+	// Free space at CacheDir below which /api/v1/health/deep reports degraded
+	HealthMinFreeDiskBytes int64
+
+	// Remove clones of inactive SDKs after each update cycle
+	AutoPruneInactiveRepos bool
+
+	// Number of commits to clone SDK repositories with; 0 clones full history
+	GitCloneDepth int
+
+	// Times a pull is retried after a network error
+	MaxGitRetries int
+
+	// Private key, and its passphrase, for SSH repository URLs
+	GitSSHKeyPath       string
+	GitSSHKeyPassphrase string
+
+	// Personal access token for private GitHub repositories over HTTPS
+	GitHubToken string
+
+	// Minimum interval between git clone/pull progress log lines
+	ProgressInterval time.Duration
+
+	// Redis URL for the redis cache backend, or for sharing cache evictions
+	// between instances using buntdb (optional)
+	RedisURL string
+
 	// Claude API configuration
 	ClaudeAPIKey  string
 	ClaudeModel   string
 	ClaudeTimeout time.Duration
 
+	// Claude pricing in US dollars per million input and output tokens,
+	// used to estimate the cost of an analysis
+	ClaudeCostPerInputMTok  float64
+	ClaudeCostPerOutputMTok float64
+
+	// AnalyzerProvider names the analyzer.Registry provider used for analysis
+	AnalyzerProvider string
+
+	// DefaultPromptTemplate is a file overriding the embedded template of
+	// the SDK analysis prompt; empty uses the embedded one
+	DefaultPromptTemplate string
+
 	// Performance configuration
 	MaxConcurrent  int
 	WorkerPoolSize int
 
+	// SDKs with more files than MultiPassThreshold are analyzed in passes
+	// of MaxFilesPerPass files
+	MultiPassThreshold int
+	MaxFilesPerPass    int
+
+	// SDKs with fewer than IncrementalThreshold changed files since their
+	// last analysis only have those files re-analyzed; 0 disables this
+	IncrementalThreshold int
+
+	// Ad-hoc analysis limits: requests estimated above MaxAdhocTokens are
+	// rejected and those above AdhocSyncTokens run in the background
+	MaxAdhocTokens      int
+	AdhocSyncTokens     int
+	MaxRequestBodyBytes int64
+
+	// Responses of at least MinCompressSize bytes are gzipped for clients
+	// that accept it; 0 compresses every response
+	MinCompressSize int
+
+	// Request timeouts keyed by Gin route pattern; patterns ending in "/*"
+	// match every route under that prefix. Zero disables the timeout.
+	EndpointTimeouts       map[string]time.Duration
+	DefaultEndpointTimeout time.Duration
+
+	// Rate limits keyed by "METHOD:route", e.g. "POST:/api/v1/cache/refresh"
+	EndpointRateLimits map[string]RateLimit
+
+	// Requests per minute across all clients and per client IP; zero
+	// disables a limit
+	GlobalRPM int
+	PerIPRPM  int
+
+	// Worker backoff configuration
+	MaxConsecutiveFailures int
+	MaxBackoffInterval     time.Duration
+	DrainTimeout           time.Duration
+
+	// Security configuration. APIKeys authorize cache writes and deletes;
+	// AdminAPIKeys authorize those and the admin endpoints.
+	APIKeys      []string
+	AdminAPIKeys []string
+
+	// Origins allowed to make cross-origin requests; "*" allows any origin
+	// but not together with AllowCredentials
+	AllowedOrigins   []string
+	AllowCredentials bool
+
+	// Notifications of new SDK analyses: a JSON POST to WebhookURL and an
+	// email from SMTPFrom to SMTPTo through SMTPHost. Each is off when its
+	// URL or host is unset.
+	WebhookURL string
+	SMTPHost   string
+	SMTPPort   int
+	SMTPFrom   string
+	SMTPTo     []string
+
 	// Analytics configuration
 	EnableAnalytics bool
 	AnalyticsDBPath string
+	Retention       RetentionPolicy
+
+	// Tracing exports OpenTelemetry spans of requests, cache operations
+	// and Claude API calls over OTLP/HTTP to OTLPEndpoint, such as a
+	// Jaeger or Tempo collector
+	TracingEnabled bool
+	OTLPEndpoint   string
+
+	// Feature flags loaded from FEATURE_FLAGS
+	FeatureFlags map[string]bool
+
+	// Runtime feature flag overrides (map[string]bool), replaced on write
+	features   atomic.Value
+	featuresMu sync.Mutex
+
+	// Environment variables whose values were loaded from .env
+	fileKeys map[string]bool
+}
+
+// DefaultEndpointTimeouts returns the built-in per-route request timeouts.
+func DefaultEndpointTimeouts() map[string]time.Duration {
+	return map[string]time.Duration{
+		"/health":                         5 * time.Second,
+		"/api/v1/cache/*":                 10 * time.Second,
+		"/api/v1/analytics/*":             30 * time.Second,
+		"/api/v1/cache/refresh":           15 * time.Minute,
+		"/api/v1/cache/warmup":            30 * time.Minute, // Analyzes every stale SDK
+		"/api/v1/cache/export":            0,                // Streamed; ends when the client goes away
+		"/api/v1/cache/import":            5 * time.Minute,
+		"/api/v1/cache/maintenance/*":     5 * time.Minute,
+		"/api/v1/system/*":                5 * time.Minute,
+		"/api/v1/batch/analyze":           5 * time.Minute,
+		"/api/v1/cache/sdk/:name/analyze": 15 * time.Minute, // Clones and analyzes the repository
+		"/api/v1/sdk/:name/estimate-cost": 5 * time.Minute,  // Clones the repository
+		"/ws/*":                           0,                // Long-lived connections
+	}
+}
+
+// RateLimit caps requests per client. Zero disables a limit.
+type RateLimit struct {
+	RPM       int `json:"rpm" yaml:"rpm" toml:"rpm"`
+	RPH       int `json:"rph" yaml:"rph" toml:"rph"`
+	BurstSize int `json:"burst_size" yaml:"burst_size" toml:"burst_size"` // Requests per second
+}
+
+// DefaultEndpointRateLimits returns the built-in per-route rate limits.
+func DefaultEndpointRateLimits() map[string]RateLimit {
+	return map[string]RateLimit{
+		"POST:/api/v1/cache/refresh": {RPM: 10, RPH: 100, BurstSize: 2},
+		"GET:/health":                {RPM: 1000, BurstSize: 50},
+	}
+}
+
+// RetentionPolicy sets how many days of each analytics event kind are kept.
+// Zero keeps events forever.
+type RetentionPolicy struct {
+	TokenEventDays int `json:"token_event_days"`
+	CacheEventDays int `json:"cache_event_days"`
+	AuditLogDays   int `json:"audit_log_days"`
 }
 
-// Load loads configuration from environment variables.
-func Load() (*Config, error) {
-	// Load .env file if it exists
-	_ = godotenv.Load()
+// Load loads configuration from environment variables, falling back to
+// .env and then to the YAML or TOML file named by CONFIG_FILE for variables
+// that are unset. A .env that cannot be read is logged and skipped.
+func Load(logger zerolog.Logger) (*Config, error) {
+	// Load .env file if it exists, noting which values it supplies
+	dotenv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logger.Warn().Err(err).Str("file", dotenvFile).Msg("Failed to read .env, ignoring it")
+	}
+	fileKeys := dotenvKeys(dotenv)
+	for key, value := range dotenv {
+		if _, set := os.LookupEnv(key); set {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return nil, fmt.Errorf("failed to apply .env value %s: %w", key, err)
+		}
+	}
+
+	// Apply the config file the same way
+	fileValues, err := readConfigFile(os.Getenv("CONFIG_FILE"))
+	if err != nil {
+		return nil, err
+	}
+	for key := range dotenvKeys(fileValues) {
+		if err := os.Setenv(key, fileValues[key]); err != nil {
+			return nil, fmt.Errorf("failed to apply config file value %s: %w", key, err)
+		}
+		fileKeys[key] = true
+	}
 
 	cfg := &Config{
 		// Defaults
 		Port:            getEnv("PORT", "8080"),
 		Version:         getEnv("VERSION", "1.0.0"),
 		Debug:           getBoolEnv("DEBUG", false),
+		TLSEnabled:      getBoolEnv("TLS_ENABLED", false),
+		TLSDomain:       getEnv("TLS_DOMAIN", ""),
+		TLSCertFile:     getEnv("TLS_CERT_FILE", ""),
+		TLSKeyFile:      getEnv("TLS_KEY_FILE", ""),
+		HTTPPort:        getEnv("HTTP_PORT", "80"),
+		HTTPSPort:       getEnv("HTTPS_PORT", "443"),
 		CacheDir:        getEnv("CACHE_DIR", "./cache"),
 		UpdateSchedule:  getEnv("UPDATE_SCHEDULE", "0 2 * * 0"), // Weekly at 2 AM
 		CacheTTL:        getDurationEnv("CACHE_TTL", 7*24*time.Hour),
 		MaxCacheSize:    getInt64Env("MAX_CACHE_SIZE", 1<<30), // 1GB
+		CacheBackend:    getEnv("CACHE_BACKEND", "buntdb"),
+		StaleThreshold:  getDurationEnv("STALE_THRESHOLD", 24*time.Hour),
+		AutoCompact:     getBoolEnv("AUTO_COMPACT", true),
+		CompactSchedule: getEnv("COMPACT_SCHEDULE", "0 3 * * 0"), // Weekly at 3 AM
 		ClaudeAPIKey:    getEnv("CLAUDE_API_KEY", ""),
 		ClaudeModel:     getEnv("CLAUDE_MODEL", "claude-3-5-sonnet-20241022"),
 		ClaudeTimeout:   getDurationEnv("CLAUDE_TIMEOUT", 5*time.Minute),
@@ -56,6 +284,56 @@ func Load() (*Config, error) {
 		WorkerPoolSize:  getIntEnv("WORKER_POOL_SIZE", 5),
 		EnableAnalytics: getBoolEnv("ENABLE_ANALYTICS", true),
 		AnalyticsDBPath: getEnv("ANALYTICS_DB_PATH", "./analytics.db"),
+		TracingEnabled:  getBoolEnv("TRACING_ENABLED", false),
+		OTLPEndpoint:    getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+
+		MaxConsecutiveFailures:  getIntEnv("MAX_CONSECUTIVE_FAILURES", 3),
+		MaxBackoffInterval:      getDurationEnv("MAX_BACKOFF_INTERVAL", 7*24*time.Hour),
+		DrainTimeout:            getDurationEnv("DRAIN_TIMEOUT", 2*time.Minute),
+		APIKeys:                 getSliceEnv("API_KEYS"),
+		AdminAPIKeys:            getSliceEnv("ADMIN_API_KEYS"),
+		AllowedOrigins:          getSliceEnv("CORS_ALLOWED_ORIGINS"),
+		AllowCredentials:        getBoolEnv("CORS_ALLOW_CREDENTIALS", false),
+		RedisURL:                getEnv("REDIS_URL", ""),
+		WebhookURL:              getEnv("WEBHOOK_URL", ""),
+		SMTPHost:                getEnv("SMTP_HOST", ""),
+		SMTPPort:                getIntEnv("SMTP_PORT", 587),
+		SMTPFrom:                getEnv("SMTP_FROM", ""),
+		SMTPTo:                  getSliceEnv("SMTP_TO"),
+		AnalyzerProvider:        getEnv("ANALYZER_PROVIDER", "claude"),
+		DefaultPromptTemplate:   getEnv("DEFAULT_PROMPT_TEMPLATE", ""),
+		ProgressInterval:        getDurationEnv("GIT_PROGRESS_INTERVAL", 5*time.Second),
+		MinFreeDiskBytes:        getInt64Env("MIN_FREE_DISK_BYTES", 512<<20),        // 512MB
+		HealthMinFreeDiskBytes:  getInt64Env("HEALTH_MIN_FREE_DISK_BYTES", 100<<20), // 100MB
+		AutoPruneInactiveRepos:  getBoolEnv("AUTO_PRUNE_INACTIVE_REPOS", false),
+		GitCloneDepth:           getIntEnv("GIT_CLONE_DEPTH", 1),
+		MaxGitRetries:           getIntEnv("MAX_GIT_RETRIES", 3),
+		GitSSHKeyPath:           getEnv("GIT_SSH_KEY_PATH", ""),
+		GitSSHKeyPassphrase:     getEnv("GIT_SSH_KEY_PASSPHRASE", ""),
+		GitHubToken:             getEnv("GITHUB_TOKEN", ""),
+		MultiPassThreshold:      getIntEnv("MULTI_PASS_THRESHOLD", 50),
+		MaxFilesPerPass:         getIntEnv("MAX_FILES_PER_PASS", 50),
+		IncrementalThreshold:    getIntEnv("INCREMENTAL_THRESHOLD", 10),
+		MaxAdhocTokens:          getIntEnv("MAX_ADHOC_TOKENS", 200000),
+		AdhocSyncTokens:         getIntEnv("ADHOC_SYNC_TOKENS", 20000),
+		MaxRequestBodyBytes:     getInt64Env("MAX_REQUEST_BODY_BYTES", 10<<20), // 10MB
+		MinCompressSize:         getIntEnv("MIN_COMPRESS_SIZE", 1024),
+		CompressThreshold:       getInt64Env("COMPRESS_THRESHOLD", 4096),
+		L1CacheSize:             getIntEnv("L1_CACHE_SIZE", 1000),
+		FeatureFlags:            getFeatureFlagsEnv("FEATURE_FLAGS"),
+		EndpointTimeouts:        getEndpointTimeoutsEnv("ENDPOINT_TIMEOUTS"),
+		DefaultEndpointTimeout:  getDurationEnv("DEFAULT_ENDPOINT_TIMEOUT", 30*time.Second),
+		EndpointRateLimits:      getEndpointRateLimitsEnv("ENDPOINT_RATE_LIMITS"),
+		ClaudeCostPerInputMTok:  getFloatEnv("CLAUDE_COST_PER_INPUT_MTOKEN", 3),
+		ClaudeCostPerOutputMTok: getFloatEnv("CLAUDE_COST_PER_OUTPUT_MTOKEN", 15),
+		GlobalRPM:               getIntEnv("GLOBAL_RPM", 6000),
+		PerIPRPM:                getIntEnv("PER_IP_RPM", 600),
+		Retention: RetentionPolicy{
+			TokenEventDays: getIntEnv("ANALYTICS_TOKEN_RETENTION_DAYS", 90),
+			CacheEventDays: getIntEnv("ANALYTICS_CACHE_RETENTION_DAYS", 30),
+			AuditLogDays:   getIntEnv("AUDIT_LOG_RETENTION_DAYS", 365),
+		},
+		fileKeys: fileKeys,
 	}
 
 	// Validate required configuration
@@ -120,6 +398,18 @@ func getInt64Env(key string, defaultValue int64) int64 {
 	return int64Value
 }
 
+func getFloatEnv(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return defaultValue
+	}
+	return floatValue
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	value := os.Getenv(key)
 	if value == "" {
@@ -131,3 +421,71 @@ func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	}
 	return duration
 }
+
+func getSliceEnv(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// getEndpointRateLimitsEnv parses "method:route:rpm:rph:burst" entries
+// separated by commas, e.g. "POST:/api/v1/cache/refresh:10:100:2", over
+// DefaultEndpointRateLimits. Routes may contain colons ("/sdk/:name").
+// Malformed entries are ignored.
+func getEndpointRateLimitsEnv(key string) map[string]RateLimit {
+	limits := DefaultEndpointRateLimits()
+	for _, item := range getSliceEnv(key) {
+		parts := strings.Split(item, ":")
+		if len(parts) < 5 {
+			continue
+		}
+
+		n := len(parts)
+		values := make([]int, 3)
+		valid := true
+		for i, part := range parts[n-3:] {
+			value, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || value < 0 {
+				valid = false
+				break
+			}
+			values[i] = value
+		}
+		if !valid {
+			continue
+		}
+
+		method := strings.ToUpper(strings.TrimSpace(parts[0]))
+		route := strings.TrimSpace(strings.Join(parts[1:n-3], ":"))
+		limits[method+":"+route] = RateLimit{RPM: values[0], RPH: values[1], BurstSize: values[2]}
+	}
+	return limits
+}
+
+// getEndpointTimeoutsEnv parses "pattern=duration" pairs separated by commas,
+// e.g. "/health=2s,/api/v1/cache/*=20s", over DefaultEndpointTimeouts.
+// Malformed entries are ignored.
+func getEndpointTimeoutsEnv(key string) map[string]time.Duration {
+	timeouts := DefaultEndpointTimeouts()
+	for _, item := range getSliceEnv(key) {
+		pattern, value, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		timeouts[strings.TrimSpace(pattern)] = duration
+	}
+	return timeouts
+}
@@ -5,13 +5,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestLoadConfig(t *testing.T) {
 	// Test default configuration
-	cfg, err := Load()
+	cfg, err := Load(zerolog.Nop())
 	assert.NoError(t, err)
 	assert.NotNil(t, cfg)
 
@@ -23,6 +24,16 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "0 2 * * 0", cfg.UpdateSchedule)
 	assert.Equal(t, 7*24*time.Hour, cfg.CacheTTL)
 	assert.Equal(t, int64(1<<30), cfg.MaxCacheSize)
+	assert.Equal(t, 3, cfg.MaxConsecutiveFailures)
+	assert.Equal(t, 7*24*time.Hour, cfg.MaxBackoffInterval)
+	assert.Equal(t, 2*time.Minute, cfg.DrainTimeout)
+	assert.Equal(t, 6000, cfg.GlobalRPM)
+	assert.Equal(t, 600, cfg.PerIPRPM)
+	assert.Empty(t, cfg.APIKeys)
+	assert.Empty(t, cfg.AdminAPIKeys)
+	assert.Empty(t, cfg.RedisURL)
+	assert.Empty(t, cfg.FeatureFlags)
+	assert.Equal(t, RetentionPolicy{TokenEventDays: 90, CacheEventDays: 30, AuditLogDays: 365}, cfg.Retention)
 }
 
 func TestLoadConfigWithEnvVars(t *testing.T) {
@@ -41,6 +52,20 @@ func TestLoadConfigWithEnvVars(t *testing.T) {
 		"WORKER_POOL_SIZE":  "10",
 		"ENABLE_ANALYTICS":  "false",
 		"ANALYTICS_DB_PATH": "/tmp/analytics.db",
+
+		"MAX_CONSECUTIVE_FAILURES": "5",
+		"MAX_BACKOFF_INTERVAL":     "48h",
+		"DRAIN_TIMEOUT":            "30s",
+		"GLOBAL_RPM":               "1200",
+		"PER_IP_RPM":               "0",
+		"API_KEYS":                 "writer-one,writer-two",
+		"ADMIN_API_KEYS":           "admin-one, admin-two",
+		"REDIS_URL":                "redis://cache-redis:6379/0",
+		"FEATURE_FLAGS":            "prompt_caching:true,streaming:false",
+
+		"ANALYTICS_TOKEN_RETENTION_DAYS": "60",
+		"ANALYTICS_CACHE_RETENTION_DAYS": "7",
+		"AUDIT_LOG_RETENTION_DAYS":       "0",
 	}
 
 	// Set env vars
@@ -51,7 +76,7 @@ func TestLoadConfigWithEnvVars(t *testing.T) {
 		}(k)
 	}
 
-	cfg, err := Load()
+	cfg, err := Load(zerolog.Nop())
 	assert.NoError(t, err)
 
 	// Check overridden values
@@ -68,6 +93,17 @@ func TestLoadConfigWithEnvVars(t *testing.T) {
 	assert.Equal(t, 10, cfg.WorkerPoolSize)
 	assert.False(t, cfg.EnableAnalytics)
 	assert.Equal(t, "/tmp/analytics.db", cfg.AnalyticsDBPath)
+	assert.Equal(t, 5, cfg.MaxConsecutiveFailures)
+	assert.Equal(t, 48*time.Hour, cfg.MaxBackoffInterval)
+	assert.Equal(t, 30*time.Second, cfg.DrainTimeout)
+	assert.Equal(t, 1200, cfg.GlobalRPM)
+	assert.Zero(t, cfg.PerIPRPM)
+	assert.Equal(t, []string{"writer-one", "writer-two"}, cfg.APIKeys)
+	assert.Equal(t, []string{"admin-one", "admin-two"}, cfg.AdminAPIKeys)
+	assert.Equal(t, "redis://cache-redis:6379/0", cfg.RedisURL)
+	assert.Equal(t, map[string]bool{"prompt_caching": true, "streaming": false}, cfg.FeatureFlags)
+	assert.True(t, cfg.IsEnabled("prompt_caching"))
+	assert.Equal(t, RetentionPolicy{TokenEventDays: 60, CacheEventDays: 7, AuditLogDays: 0}, cfg.Retention)
 }
 
 func TestLoadConfigWithAlternativeAPIKey(t *testing.T) {
@@ -77,7 +113,7 @@ func TestLoadConfigWithAlternativeAPIKey(t *testing.T) {
 		require.NoError(t, os.Unsetenv("ANTHROPIC_API_KEY"))
 	}()
 
-	cfg, err := Load()
+	cfg, err := Load(zerolog.Nop())
 	assert.NoError(t, err)
 	assert.Equal(t, "anthropic-key", cfg.ClaudeAPIKey)
 }
@@ -163,6 +199,22 @@ func TestGetInt64Env(t *testing.T) {
 	assert.Equal(t, int64(100), value)
 }
 
+func TestGetFloatEnv(t *testing.T) {
+	// Test valid float
+	require.NoError(t, os.Setenv("FLOAT_VAR", "0.25"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("FLOAT_VAR"))
+	}()
+
+	value := getFloatEnv("FLOAT_VAR", 0)
+	assert.Equal(t, 0.25, value)
+
+	// Test invalid float
+	require.NoError(t, os.Setenv("FLOAT_VAR", "invalid"))
+	value = getFloatEnv("FLOAT_VAR", 3)
+	assert.Equal(t, 3.0, value)
+}
+
 func TestGetDurationEnv(t *testing.T) {
 	// Test valid duration
 	require.NoError(t, os.Setenv("DURATION_VAR", "5m30s"))
@@ -182,3 +234,46 @@ func TestGetDurationEnv(t *testing.T) {
 	value = getDurationEnv("NON_EXISTENT", time.Minute)
 	assert.Equal(t, time.Minute, value)
 }
+
+func TestGetSliceEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("SLICE_VAR", "a, b,,c "))
+	defer func() {
+		require.NoError(t, os.Unsetenv("SLICE_VAR"))
+	}()
+
+	assert.Equal(t, []string{"a", "b", "c"}, getSliceEnv("SLICE_VAR"))
+	assert.Nil(t, getSliceEnv("NON_EXISTENT"))
+}
+
+func TestGetEndpointTimeoutsEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("TIMEOUTS_VAR", "/health=2s, /api/v1/admin/*=1m,bogus,/x=notaduration"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("TIMEOUTS_VAR"))
+	}()
+
+	timeouts := getEndpointTimeoutsEnv("TIMEOUTS_VAR")
+	assert.Equal(t, 2*time.Second, timeouts["/health"])
+	assert.Equal(t, time.Minute, timeouts["/api/v1/admin/*"])
+	assert.Equal(t, 15*time.Minute, timeouts["/api/v1/cache/refresh"])
+	assert.NotContains(t, timeouts, "/x")
+	assert.NotContains(t, timeouts, "bogus")
+
+	assert.Equal(t, DefaultEndpointTimeouts(), getEndpointTimeoutsEnv("NON_EXISTENT"))
+}
+
+func TestGetEndpointRateLimitsEnv(t *testing.T) {
+	require.NoError(t, os.Setenv("RATE_LIMITS_VAR", "POST:/api/v1/cache/refresh:5:50:1, get:/api/v1/cache/sdk/:name:60:0:10,GET:/x:1:2,GET:/y:a:1:1,GET:/z:-1:0:0"))
+	defer func() {
+		require.NoError(t, os.Unsetenv("RATE_LIMITS_VAR"))
+	}()
+
+	limits := getEndpointRateLimitsEnv("RATE_LIMITS_VAR")
+	assert.Equal(t, RateLimit{RPM: 5, RPH: 50, BurstSize: 1}, limits["POST:/api/v1/cache/refresh"])
+	assert.Equal(t, RateLimit{RPM: 60, BurstSize: 10}, limits["GET:/api/v1/cache/sdk/:name"])
+	assert.Equal(t, RateLimit{RPM: 1000, BurstSize: 50}, limits["GET:/health"])
+	assert.NotContains(t, limits, "GET:/x")
+	assert.NotContains(t, limits, "GET:/y")
+	assert.NotContains(t, limits, "GET:/z")
+
+	assert.Equal(t, DefaultEndpointRateLimits(), getEndpointRateLimitsEnv("NON_EXISTENT"))
+}
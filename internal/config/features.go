@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Feature flag names.
+const (
+	// FlagCacheHitTracking records per-entry hit counts on cache reads.
+	FlagCacheHitTracking = "cache_hit_tracking"
+	// FlagGitSubmodules clones SDK repositories with their submodules.
+	FlagGitSubmodules = "git_submodules"
+	// FlagTokenEstimation estimates prompt tokens before each Claude request.
+	FlagTokenEstimation = "token_estimation"
+	// FlagPromptCaching enables Claude prompt caching.
+	FlagPromptCaching = "prompt_caching"
+	// FlagStreaming enables streamed Claude responses.
+	FlagStreaming = "streaming"
+)
+
+// defaultFeatureFlags lists every known flag and its default state. Flags not
+// listed here are disabled unless explicitly configured.
+var defaultFeatureFlags = map[string]bool{
+	FlagCacheHitTracking: true,
+	FlagGitSubmodules:    true,
+	FlagTokenEstimation:  true,
+	FlagPromptCaching:    false,
+	FlagStreaming:        false,
+}
+
+// FeatureChecker reports whether a feature flag is enabled.
+type FeatureChecker interface {
+	IsEnabled(flag string) bool
+}
+
+// FeatureEnabled checks flag against checker, falling back to the flag's
+// default when no checker is configured.
+func FeatureEnabled(checker FeatureChecker, flag string) bool {
+	if checker == nil {
+		return defaultFeatureFlags[flag]
+	}
+	return checker.IsEnabled(flag)
+}
+
+// IsKnownFeature reports whether flag is a recognised feature flag.
+func IsKnownFeature(flag string) bool {
+	_, ok := defaultFeatureFlags[flag]
+	return ok
+}
+
+// IsEnabled reports whether flag is enabled. Runtime overrides take
+// precedence over FEATURE_FLAGS, which takes precedence over the defaults.
+// Unknown flags are disabled.
+func (c *Config) IsEnabled(flag string) bool {
+	if enabled, ok := c.featureOverrides()[flag]; ok {
+		return enabled
+	}
+	if enabled, ok := c.FeatureFlags[flag]; ok {
+		return enabled
+	}
+	return defaultFeatureFlags[flag]
+}
+
+// Features returns the effective state of every known or configured flag.
+func (c *Config) Features() map[string]bool {
+	features := make(map[string]bool, len(defaultFeatureFlags))
+	for flag := range defaultFeatureFlags {
+		features[flag] = c.IsEnabled(flag)
+	}
+	for flag := range c.FeatureFlags {
+		features[flag] = c.IsEnabled(flag)
+	}
+	for flag := range c.featureOverrides() {
+		features[flag] = c.IsEnabled(flag)
+	}
+	return features
+}
+
+// FeatureNames returns the sorted names of every known or configured flag.
+func (c *Config) FeatureNames() []string {
+	features := c.Features()
+	names := make([]string, 0, len(features))
+	for flag := range features {
+		names = append(names, flag)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetFeature toggles flag at runtime and returns its previous state.
+func (c *Config) SetFeature(flag string, enabled bool) bool {
+	c.featuresMu.Lock()
+	defer c.featuresMu.Unlock()
+
+	previous := c.IsEnabled(flag)
+
+	// Copy on write so readers never see a partially updated map
+	current := c.featureOverrides()
+	updated := make(map[string]bool, len(current)+1)
+	for k, v := range current {
+		updated[k] = v
+	}
+	updated[flag] = enabled
+	c.features.Store(updated)
+
+	return previous
+}
+
+func (c *Config) featureOverrides() map[string]bool {
+	overrides, _ := c.features.Load().(map[string]bool)
+	return overrides
+}
+
+// getFeatureFlagsEnv parses comma-delimited name:bool pairs, e.g.
+// "prompt_caching:true,streaming:false". Malformed pairs are skipped.
+func getFeatureFlagsEnv(key string) map[string]bool {
+	flags := make(map[string]bool)
+
+	value := os.Getenv(key)
+	if value == "" {
+		return flags
+	}
+
+	for _, pair := range strings.Split(value, ",") {
+		name, raw, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok {
+			continue
+		}
+
+		name = strings.TrimSpace(name)
+		enabled, err := strconv.ParseBool(strings.TrimSpace(raw))
+		if name == "" || err != nil {
+			continue
+		}
+		flags[name] = enabled
+	}
+	return flags
+}
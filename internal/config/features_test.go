@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFeatureFlagsEnv(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]bool
+	}{
+		{
+			name:     "unset",
+			value:    "",
+			expected: map[string]bool{},
+		},
+		{
+			name:  "pairs",
+			value: "prompt_caching:true,streaming:false",
+			expected: map[string]bool{
+				"prompt_caching": true,
+				"streaming":      false,
+			},
+		},
+		{
+			name:  "whitespace and bool forms",
+			value: " prompt_caching : 1 , streaming:FALSE ",
+			expected: map[string]bool{
+				"prompt_caching": true,
+				"streaming":      false,
+			},
+		},
+		{
+			name:  "malformed pairs skipped",
+			value: "prompt_caching,streaming:maybe,:true,git_submodules:false",
+			expected: map[string]bool{
+				"git_submodules": false,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.Setenv("TEST_FEATURE_FLAGS", tt.value))
+			defer func() {
+				require.NoError(t, os.Unsetenv("TEST_FEATURE_FLAGS"))
+			}()
+
+			assert.Equal(t, tt.expected, getFeatureFlagsEnv("TEST_FEATURE_FLAGS"))
+		})
+	}
+}
+
+func TestIsEnabledDefaults(t *testing.T) {
+	cfg := &Config{}
+
+	assert.True(t, cfg.IsEnabled(FlagCacheHitTracking))
+	assert.True(t, cfg.IsEnabled(FlagGitSubmodules))
+	assert.False(t, cfg.IsEnabled(FlagPromptCaching))
+	assert.False(t, cfg.IsEnabled("no_such_flag"), "unknown flags default to false")
+
+	cfg.FeatureFlags = map[string]bool{
+		FlagPromptCaching:    true,
+		FlagCacheHitTracking: false,
+	}
+	assert.True(t, cfg.IsEnabled(FlagPromptCaching))
+	assert.False(t, cfg.IsEnabled(FlagCacheHitTracking))
+}
+
+func TestFeatureEnabledWithoutChecker(t *testing.T) {
+	assert.True(t, FeatureEnabled(nil, FlagCacheHitTracking))
+	assert.False(t, FeatureEnabled(nil, FlagStreaming))
+	assert.False(t, FeatureEnabled(nil, "no_such_flag"))
+}
+
+func TestSetFeature(t *testing.T) {
+	cfg := &Config{FeatureFlags: map[string]bool{FlagStreaming: true}}
+
+	previous := cfg.SetFeature(FlagStreaming, false)
+	assert.True(t, previous)
+	assert.False(t, cfg.IsEnabled(FlagStreaming))
+
+	previous = cfg.SetFeature(FlagPromptCaching, true)
+	assert.False(t, previous)
+	assert.True(t, cfg.IsEnabled(FlagPromptCaching))
+
+	// The loaded configuration is left untouched
+	assert.True(t, cfg.FeatureFlags[FlagStreaming])
+
+	features := cfg.Features()
+	assert.False(t, features[FlagStreaming])
+	assert.True(t, features[FlagPromptCaching])
+	assert.True(t, features[FlagCacheHitTracking])
+	assert.Contains(t, cfg.FeatureNames(), FlagGitSubmodules)
+}
+
+func TestSetFeatureConcurrent(t *testing.T) {
+	cfg := &Config{}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(2)
+		go func(enabled bool) {
+			defer wg.Done()
+			cfg.SetFeature(FlagStreaming, enabled)
+		}(i%2 == 0)
+		go func() {
+			defer wg.Done()
+			cfg.IsEnabled(FlagStreaming)
+		}()
+	}
+	wg.Wait()
+
+	cfg.SetFeature(FlagStreaming, true)
+	assert.True(t, cfg.IsEnabled(FlagStreaming))
+}
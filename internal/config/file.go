@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile mirrors Config for the file named by CONFIG_FILE. Each field
+// names the environment variable it stands in for; values are applied only
+// when that variable is unset, so the environment always takes precedence.
+type configFile struct {
+	Port    *string `yaml:"port" toml:"port" env:"PORT"`
+	Version *string `yaml:"version" toml:"version" env:"VERSION"`
+	Debug   *bool   `yaml:"debug" toml:"debug" env:"DEBUG"`
+
+	TLSEnabled  *bool   `yaml:"tls_enabled" toml:"tls_enabled" env:"TLS_ENABLED"`
+	TLSDomain   *string `yaml:"tls_domain" toml:"tls_domain" env:"TLS_DOMAIN"`
+	TLSCertFile *string `yaml:"tls_cert_file" toml:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile  *string `yaml:"tls_key_file" toml:"tls_key_file" env:"TLS_KEY_FILE"`
+	HTTPPort    *string `yaml:"http_port" toml:"http_port" env:"HTTP_PORT"`
+	HTTPSPort   *string `yaml:"https_port" toml:"https_port" env:"HTTPS_PORT"`
+
+	CacheDir          *string        `yaml:"cache_dir" toml:"cache_dir" env:"CACHE_DIR"`
+	CacheBackend      *string        `yaml:"cache_backend" toml:"cache_backend" env:"CACHE_BACKEND"`
+	UpdateSchedule    *string        `yaml:"update_schedule" toml:"update_schedule" env:"UPDATE_SCHEDULE"`
+	CacheTTL          *time.Duration `yaml:"cache_ttl" toml:"cache_ttl" env:"CACHE_TTL"`
+	MaxCacheSize      *int64         `yaml:"max_cache_size" toml:"max_cache_size" env:"MAX_CACHE_SIZE"`
+	CompressThreshold *int64         `yaml:"compress_threshold" toml:"compress_threshold" env:"COMPRESS_THRESHOLD"`
+	L1CacheSize       *int           `yaml:"l1_cache_size" toml:"l1_cache_size" env:"L1_CACHE_SIZE"`
+	StaleThreshold    *time.Duration `yaml:"stale_threshold" toml:"stale_threshold" env:"STALE_THRESHOLD"`
+	AutoCompact       *bool          `yaml:"auto_compact" toml:"auto_compact" env:"AUTO_COMPACT"`
+	CompactSchedule   *string        `yaml:"compact_schedule" toml:"compact_schedule" env:"COMPACT_SCHEDULE"`
+	RedisURL          *string        `yaml:"redis_url" toml:"redis_url" env:"REDIS_URL"`
+
+	MinFreeDiskBytes       *int64         `yaml:"min_free_disk_bytes" toml:"min_free_disk_bytes" env:"MIN_FREE_DISK_BYTES"`
+	HealthMinFreeDiskBytes *int64         `yaml:"health_min_free_disk_bytes" toml:"health_min_free_disk_bytes" env:"HEALTH_MIN_FREE_DISK_BYTES"`
+	AutoPruneInactiveRepos *bool          `yaml:"auto_prune_inactive_repos" toml:"auto_prune_inactive_repos" env:"AUTO_PRUNE_INACTIVE_REPOS"`
+	GitCloneDepth          *int           `yaml:"git_clone_depth" toml:"git_clone_depth" env:"GIT_CLONE_DEPTH"`
+	MaxGitRetries          *int           `yaml:"max_git_retries" toml:"max_git_retries" env:"MAX_GIT_RETRIES"`
+	GitSSHKeyPath          *string        `yaml:"git_ssh_key_path" toml:"git_ssh_key_path" env:"GIT_SSH_KEY_PATH"`
+	GitSSHKeyPassphrase    *string        `yaml:"git_ssh_key_passphrase" toml:"git_ssh_key_passphrase" env:"GIT_SSH_KEY_PASSPHRASE"`
+	GitHubToken            *string        `yaml:"github_token" toml:"github_token" env:"GITHUB_TOKEN"`
+	ProgressInterval       *time.Duration `yaml:"git_progress_interval" toml:"git_progress_interval" env:"GIT_PROGRESS_INTERVAL"`
+
+	ClaudeAPIKey     *string        `yaml:"claude_api_key" toml:"claude_api_key" env:"CLAUDE_API_KEY"`
+	ClaudeModel      *string        `yaml:"claude_model" toml:"claude_model" env:"CLAUDE_MODEL"`
+	ClaudeTimeout    *time.Duration `yaml:"claude_timeout" toml:"claude_timeout" env:"CLAUDE_TIMEOUT"`
+	AnalyzerProvider *string        `yaml:"analyzer_provider" toml:"analyzer_provider" env:"ANALYZER_PROVIDER"`
+
+	DefaultPromptTemplate *string `yaml:"default_prompt_template" toml:"default_prompt_template" env:"DEFAULT_PROMPT_TEMPLATE"`
+
+	ClaudeCostPerInputMTok  *float64 `yaml:"claude_cost_per_input_mtoken" toml:"claude_cost_per_input_mtoken" env:"CLAUDE_COST_PER_INPUT_MTOKEN"`
+	ClaudeCostPerOutputMTok *float64 `yaml:"claude_cost_per_output_mtoken" toml:"claude_cost_per_output_mtoken" env:"CLAUDE_COST_PER_OUTPUT_MTOKEN"`
+
+	MaxConcurrent        *int `yaml:"max_concurrent" toml:"max_concurrent" env:"MAX_CONCURRENT"`
+	WorkerPoolSize       *int `yaml:"worker_pool_size" toml:"worker_pool_size" env:"WORKER_POOL_SIZE"`
+	MultiPassThreshold   *int `yaml:"multi_pass_threshold" toml:"multi_pass_threshold" env:"MULTI_PASS_THRESHOLD"`
+	MaxFilesPerPass      *int `yaml:"max_files_per_pass" toml:"max_files_per_pass" env:"MAX_FILES_PER_PASS"`
+	IncrementalThreshold *int `yaml:"incremental_threshold" toml:"incremental_threshold" env:"INCREMENTAL_THRESHOLD"`
+
+	MaxAdhocTokens      *int   `yaml:"max_adhoc_tokens" toml:"max_adhoc_tokens" env:"MAX_ADHOC_TOKENS"`
+	AdhocSyncTokens     *int   `yaml:"adhoc_sync_tokens" toml:"adhoc_sync_tokens" env:"ADHOC_SYNC_TOKENS"`
+	MaxRequestBodyBytes *int64 `yaml:"max_request_body_bytes" toml:"max_request_body_bytes" env:"MAX_REQUEST_BODY_BYTES"`
+	MinCompressSize     *int   `yaml:"min_compress_size" toml:"min_compress_size" env:"MIN_COMPRESS_SIZE"`
+
+	EndpointTimeouts       map[string]time.Duration `yaml:"endpoint_timeouts" toml:"endpoint_timeouts" env:"ENDPOINT_TIMEOUTS"`
+	DefaultEndpointTimeout *time.Duration           `yaml:"default_endpoint_timeout" toml:"default_endpoint_timeout" env:"DEFAULT_ENDPOINT_TIMEOUT"`
+	EndpointRateLimits     map[string]RateLimit     `yaml:"endpoint_rate_limits" toml:"endpoint_rate_limits" env:"ENDPOINT_RATE_LIMITS"`
+	GlobalRPM              *int                     `yaml:"global_rpm" toml:"global_rpm" env:"GLOBAL_RPM"`
+	PerIPRPM               *int                     `yaml:"per_ip_rpm" toml:"per_ip_rpm" env:"PER_IP_RPM"`
+
+	MaxConsecutiveFailures *int           `yaml:"max_consecutive_failures" toml:"max_consecutive_failures" env:"MAX_CONSECUTIVE_FAILURES"`
+	MaxBackoffInterval     *time.Duration `yaml:"max_backoff_interval" toml:"max_backoff_interval" env:"MAX_BACKOFF_INTERVAL"`
+	DrainTimeout           *time.Duration `yaml:"drain_timeout" toml:"drain_timeout" env:"DRAIN_TIMEOUT"`
+
+	APIKeys      []string `yaml:"api_keys" toml:"api_keys" env:"API_KEYS"`
+	AdminAPIKeys []string `yaml:"admin_api_keys" toml:"admin_api_keys" env:"ADMIN_API_KEYS"`
+
+	AllowedOrigins   []string `yaml:"cors_allowed_origins" toml:"cors_allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	AllowCredentials *bool    `yaml:"cors_allow_credentials" toml:"cors_allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+
+	WebhookURL *string  `yaml:"webhook_url" toml:"webhook_url" env:"WEBHOOK_URL"`
+	SMTPHost   *string  `yaml:"smtp_host" toml:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort   *int     `yaml:"smtp_port" toml:"smtp_port" env:"SMTP_PORT"`
+	SMTPFrom   *string  `yaml:"smtp_from" toml:"smtp_from" env:"SMTP_FROM"`
+	SMTPTo     []string `yaml:"smtp_to" toml:"smtp_to" env:"SMTP_TO"`
+
+	EnableAnalytics *bool   `yaml:"enable_analytics" toml:"enable_analytics" env:"ENABLE_ANALYTICS"`
+	AnalyticsDBPath *string `yaml:"analytics_db_path" toml:"analytics_db_path" env:"ANALYTICS_DB_PATH"`
+	Retention       *struct {
+		TokenEventDays *int `yaml:"token_event_days" toml:"token_event_days" env:"ANALYTICS_TOKEN_RETENTION_DAYS"`
+		CacheEventDays *int `yaml:"cache_event_days" toml:"cache_event_days" env:"ANALYTICS_CACHE_RETENTION_DAYS"`
+		AuditLogDays   *int `yaml:"audit_log_days" toml:"audit_log_days" env:"AUDIT_LOG_RETENTION_DAYS"`
+	} `yaml:"retention" toml:"retention"`
+
+	TracingEnabled *bool   `yaml:"tracing_enabled" toml:"tracing_enabled" env:"TRACING_ENABLED"`
+	OTLPEndpoint   *string `yaml:"otel_exporter_otlp_endpoint" toml:"otel_exporter_otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+
+	FeatureFlags map[string]bool `yaml:"feature_flags" toml:"feature_flags" env:"FEATURE_FLAGS"`
+}
+
+// readConfigFile parses the YAML or TOML file at path, chosen by its
+// extension, and returns its values keyed by environment variable in the
+// format Load parses them from. A missing file has no values.
+func readConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file configFile
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".toml":
+		meta, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			return nil, fmt.Errorf("failed to parse config file %s: unknown key %s", path, undecoded[0])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q, use .yaml, .yml or .toml", ext)
+	}
+
+	values := make(map[string]string)
+	collectEnvValues(reflect.ValueOf(file), values)
+	return values, nil
+}
+
+// collectEnvValues adds the set fields of a configFile section to values.
+func collectEnvValues(section reflect.Value, values map[string]string) {
+	t := section.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := section.Field(i)
+		if field.IsNil() {
+			continue
+		}
+
+		key := t.Field(i).Tag.Get("env")
+		if key == "" {
+			collectEnvValues(field.Elem(), values)
+			continue
+		}
+		if field.Kind() == reflect.Pointer {
+			field = field.Elem()
+		}
+		values[key] = envString(field.Interface())
+	}
+}
+
+// envString formats a configFile value as its environment variable would
+// hold it.
+func envString(value any) string {
+	var items []string
+	switch value := value.(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case map[string]bool:
+		for name, enabled := range value {
+			items = append(items, name+":"+strconv.FormatBool(enabled))
+		}
+	case map[string]time.Duration:
+		for pattern, timeout := range value {
+			items = append(items, pattern+"="+timeout.String())
+		}
+	case map[string]RateLimit:
+		for route, limit := range value {
+			items = append(items, fmt.Sprintf("%s:%d:%d:%d", route, limit.RPM, limit.RPH, limit.BurstSize))
+		}
+	default:
+		return fmt.Sprint(value)
+	}
+
+	sort.Strings(items)
+	return strings.Join(items, ",")
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// configFileKeys are the variables set by the config files in these tests.
+var configFileKeys = []string{
+	"CONFIG_FILE", "PORT", "UPDATE_SCHEDULE", "CACHE_TTL", "MAX_CONCURRENT", "WORKER_POOL_SIZE",
+	"API_KEYS", "ENDPOINT_TIMEOUTS", "ENDPOINT_RATE_LIMITS", "FEATURE_FLAGS",
+	"ANALYTICS_TOKEN_RETENTION_DAYS", "AUTO_COMPACT",
+}
+
+const yamlConfig = `port: "9000"
+update_schedule: "0 4 * * *"
+cache_ttl: 2h
+max_concurrent: 3
+worker_pool_size: 8
+auto_compact: false
+api_keys: [file-key-one, file-key-two]
+endpoint_timeouts:
+  /health: 2s
+endpoint_rate_limits:
+  "GET:/api/v1/cache/sdk/:name": {rpm: 60, rph: 0, burst_size: 10}
+feature_flags:
+  streaming: true
+retention:
+  token_event_days: 14
+`
+
+const tomlConfig = `port = "9000"
+update_schedule = "0 4 * * *"
+cache_ttl = "2h"
+max_concurrent = 3
+worker_pool_size = 8
+auto_compact = false
+api_keys = ["file-key-one", "file-key-two"]
+
+[endpoint_timeouts]
+"/health" = "2s"
+
+[endpoint_rate_limits."GET:/api/v1/cache/sdk/:name"]
+rpm = 60
+rph = 0
+burst_size = 10
+
+[feature_flags]
+streaming = true
+
+[retention]
+token_event_days = 14
+`
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+	}{
+		{name: "yaml", filename: "config.yaml", content: yamlConfig},
+		{name: "yml", filename: "config.yml", content: yamlConfig},
+		{name: "toml", filename: "config.toml", content: tomlConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t, configFileKeys...)
+			path := filepath.Join(dir, tt.filename)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			t.Setenv("CONFIG_FILE", path)
+
+			// The environment takes precedence over the file
+			t.Setenv("WORKER_POOL_SIZE", "2")
+
+			cfg, err := Load(zerolog.Nop())
+			require.NoError(t, err)
+
+			assert.Equal(t, "9000", cfg.Port)
+			assert.Equal(t, "0 4 * * *", cfg.UpdateSchedule)
+			assert.Equal(t, 2*time.Hour, cfg.CacheTTL)
+			assert.Equal(t, 3, cfg.MaxConcurrent)
+			assert.Equal(t, 2, cfg.WorkerPoolSize)
+			assert.False(t, cfg.AutoCompact)
+			assert.Equal(t, []string{"file-key-one", "file-key-two"}, cfg.APIKeys)
+			assert.Equal(t, 2*time.Second, cfg.EndpointTimeouts["/health"])
+			assert.Equal(t, RateLimit{RPM: 60, BurstSize: 10}, cfg.EndpointRateLimits["GET:/api/v1/cache/sdk/:name"])
+			assert.Equal(t, map[string]bool{"streaming": true}, cfg.FeatureFlags)
+			assert.Equal(t, 14, cfg.Retention.TokenEventDays)
+			assert.Equal(t, 30, cfg.Retention.CacheEventDays, "unset values keep their defaults")
+
+			sources := cfg.Introspect(0).ConfigSource
+			assert.Equal(t, SourceFile, sources["PORT"])
+			assert.Equal(t, SourceEnv, sources["WORKER_POOL_SIZE"])
+			assert.Equal(t, SourceDefault, sources["CLAUDE_MODEL"])
+		})
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		errorMsg string
+	}{
+		{name: "unknown yaml key", filename: "config.yaml", content: "max_concurent: 3\n", errorMsg: "max_concurent"},
+		{name: "unknown toml key", filename: "config.toml", content: "max_concurent = 3\n", errorMsg: "max_concurent"},
+		{name: "malformed toml", filename: "config.toml", content: "port = \n", errorMsg: "failed to parse config file"},
+		{name: "wrong type", filename: "config.yaml", content: "max_concurrent: many\n", errorMsg: "failed to parse config file"},
+		{name: "unsupported format", filename: "config.json", content: "{}", errorMsg: "unsupported config file format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := chdirTemp(t, configFileKeys...)
+			path := filepath.Join(dir, tt.filename)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			t.Setenv("CONFIG_FILE", path)
+
+			_, err := Load(zerolog.Nop())
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestLoadMissingConfigFile(t *testing.T) {
+	dir := chdirTemp(t, configFileKeys...)
+	t.Setenv("CONFIG_FILE", filepath.Join(dir, "missing.yaml"))
+
+	cfg, err := Load(zerolog.Nop())
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Port)
+}
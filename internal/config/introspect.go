@@ -0,0 +1,177 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Config source values reported by Introspect.
+const (
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// maskedValue replaces sensitive values in sanitized output.
+const maskedValue = "********"
+
+// sensitiveFields lists Config fields that hold credentials. RedisURL may
+// embed a password and WebhookURL a token.
+var sensitiveFields = map[string]bool{
+	"ClaudeAPIKey":        true,
+	"GitSSHKeyPassphrase": true,
+	"GitHubToken":         true,
+	"APIKeys":             true,
+	"AdminAPIKeys":        true,
+	"RedisURL":            true,
+	"WebhookURL":          true,
+}
+
+// envKeys lists every environment variable read by Load.
+var envKeys = []string{
+	"CONFIG_FILE", "PORT", "VERSION", "DEBUG",
+	"TLS_ENABLED", "TLS_DOMAIN", "TLS_CERT_FILE", "TLS_KEY_FILE", "HTTP_PORT", "HTTPS_PORT",
+	"CACHE_DIR", "CACHE_BACKEND", "UPDATE_SCHEDULE", "CACHE_TTL", "MAX_CACHE_SIZE", "L1_CACHE_SIZE", "COMPRESS_THRESHOLD", "STALE_THRESHOLD",
+	"AUTO_COMPACT", "COMPACT_SCHEDULE", "MIN_FREE_DISK_BYTES", "HEALTH_MIN_FREE_DISK_BYTES", "AUTO_PRUNE_INACTIVE_REPOS",
+	"GIT_PROGRESS_INTERVAL", "GIT_CLONE_DEPTH", "MAX_GIT_RETRIES", "GIT_SSH_KEY_PATH", "GIT_SSH_KEY_PASSPHRASE", "GITHUB_TOKEN", "REDIS_URL",
+	"CLAUDE_API_KEY", "ANTHROPIC_API_KEY", "CLAUDE_MODEL", "CLAUDE_TIMEOUT", "ANALYZER_PROVIDER",
+	"CLAUDE_COST_PER_INPUT_MTOKEN", "CLAUDE_COST_PER_OUTPUT_MTOKEN", "DEFAULT_PROMPT_TEMPLATE",
+	"MAX_CONCURRENT", "WORKER_POOL_SIZE", "MULTI_PASS_THRESHOLD", "MAX_FILES_PER_PASS", "INCREMENTAL_THRESHOLD",
+	"MAX_ADHOC_TOKENS", "ADHOC_SYNC_TOKENS", "MAX_REQUEST_BODY_BYTES", "MIN_COMPRESS_SIZE",
+	"ENDPOINT_TIMEOUTS", "DEFAULT_ENDPOINT_TIMEOUT", "ENDPOINT_RATE_LIMITS", "GLOBAL_RPM", "PER_IP_RPM",
+	"MAX_CONSECUTIVE_FAILURES", "MAX_BACKOFF_INTERVAL", "DRAIN_TIMEOUT",
+	"API_KEYS", "ADMIN_API_KEYS", "CORS_ALLOWED_ORIGINS", "CORS_ALLOW_CREDENTIALS",
+	"WEBHOOK_URL", "SMTP_HOST", "SMTP_PORT", "SMTP_FROM", "SMTP_TO",
+	"ENABLE_ANALYTICS", "ANALYTICS_DB_PATH",
+	"ANALYTICS_TOKEN_RETENTION_DAYS", "ANALYTICS_CACHE_RETENTION_DAYS", "AUDIT_LOG_RETENTION_DAYS",
+	"TRACING_ENABLED", "OTEL_EXPORTER_OTLP_ENDPOINT",
+	"FEATURE_FLAGS",
+}
+
+// Introspection describes the effective configuration of a running service.
+type Introspection struct {
+	// Config is the sanitized configuration, keyed by field name
+	Config map[string]any `json:"config"`
+
+	// EffectiveCacheTTL is the TTL applied to SDK analyses. There are no
+	// per-SDK overrides, so every SDK uses the global CACHE_TTL.
+	EffectiveCacheTTL string `json:"effective_cache_ttl"`
+
+	ActiveSDKCount       int       `json:"active_sdk_count"`
+	DisabledFeatureFlags []string  `json:"disabled_feature_flags"`
+	NextScheduledUpdate  time.Time `json:"next_scheduled_update"`
+
+	// ConfigSource maps each environment variable to where its value came
+	// from: SourceEnv, SourceFile or SourceDefault
+	ConfigSource map[string]string `json:"config_source"`
+}
+
+// Sanitize returns the exported configuration keyed by field name, with
+// credentials masked and durations rendered as strings.
+func (c *Config) Sanitize() map[string]any {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+
+	sanitized := make(map[string]any, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		sanitized[field.Name] = sanitizeValue(field.Name, v.Field(i).Interface())
+	}
+	return sanitized
+}
+
+func sanitizeValue(name string, value any) any {
+	if sensitiveFields[name] {
+		switch value := value.(type) {
+		case string:
+			if value == "" {
+				return ""
+			}
+			return maskedValue
+		case []string:
+			masked := make([]string, len(value))
+			for i := range masked {
+				masked[i] = maskedValue
+			}
+			return masked
+		default:
+			return maskedValue
+		}
+	}
+
+	switch value := value.(type) {
+	case time.Duration:
+		return value.String()
+	case map[string]time.Duration:
+		durations := make(map[string]string, len(value))
+		for key, d := range value {
+			durations[key] = d.String()
+		}
+		return durations
+	}
+	return value
+}
+
+// Introspect gathers the sanitized configuration together with values
+// derived from it. activeSDKCount is supplied by the caller because SDK
+// definitions live outside this package.
+func (c *Config) Introspect(activeSDKCount int) Introspection {
+	disabled := []string{}
+	for flag, enabled := range c.Features() {
+		if !enabled {
+			disabled = append(disabled, flag)
+		}
+	}
+	sort.Strings(disabled)
+
+	var next time.Time
+	if sched, err := cron.ParseStandard(c.UpdateSchedule); err == nil {
+		next = sched.Next(time.Now())
+	}
+
+	return Introspection{
+		Config:               c.Sanitize(),
+		EffectiveCacheTTL:    c.CacheTTL.String(),
+		ActiveSDKCount:       activeSDKCount,
+		DisabledFeatureFlags: disabled,
+		NextScheduledUpdate:  next,
+		ConfigSource:         c.configSources(),
+	}
+}
+
+// configSources reports where each environment variable's value came from.
+// Values loaded from .env are indistinguishable from the environment once
+// loaded, so Load records them in fileKeys.
+func (c *Config) configSources() map[string]string {
+	sources := make(map[string]string, len(envKeys))
+	for _, key := range envKeys {
+		switch {
+		case c.fileKeys[key]:
+			sources[key] = SourceFile
+		case os.Getenv(key) != "":
+			sources[key] = SourceEnv
+		default:
+			sources[key] = SourceDefault
+		}
+	}
+	return sources
+}
+
+// dotenvKeys returns the keys that .env will set because they are not
+// already present in the environment.
+func dotenvKeys(values map[string]string) map[string]bool {
+	keys := make(map[string]bool, len(values))
+	for key, value := range values {
+		if _, set := os.LookupEnv(key); value != "" && !set {
+			keys[key] = true
+		}
+	}
+	return keys
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitize(t *testing.T) {
+	cfg := &Config{
+		Port:             "8080",
+		CacheTTL:         time.Hour,
+		ClaudeAPIKey:     "sk-ant-secret",
+		AdminAPIKeys:     []string{"admin-one", "admin-two"},
+		RedisURL:         "redis://:hunter2@localhost:6379/0",
+		EndpointTimeouts: map[string]time.Duration{"/health": 5 * time.Second},
+		fileKeys:         map[string]bool{"PORT": true},
+	}
+
+	sanitized := cfg.Sanitize()
+
+	assert.Equal(t, "8080", sanitized["Port"])
+	assert.Equal(t, "1h0m0s", sanitized["CacheTTL"])
+	assert.Equal(t, map[string]string{"/health": "5s"}, sanitized["EndpointTimeouts"])
+	assert.Equal(t, maskedValue, sanitized["ClaudeAPIKey"])
+	assert.Equal(t, []string{maskedValue, maskedValue}, sanitized["AdminAPIKeys"])
+	assert.Equal(t, maskedValue, sanitized["RedisURL"])
+	assert.NotContains(t, sanitized, "features")
+	assert.NotContains(t, sanitized, "fileKeys")
+
+	// Unset credentials stay empty so operators can tell they are missing
+	assert.Equal(t, "", (&Config{}).Sanitize()["ClaudeAPIKey"])
+}
+
+func TestIntrospect(t *testing.T) {
+	t.Setenv("PORT", "9090")
+	t.Setenv("L1_CACHE_SIZE", "500")
+	t.Setenv("DEFAULT_PROMPT_TEMPLATE", "security")
+	cfg := &Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       24 * time.Hour,
+		FeatureFlags:   map[string]bool{FlagStreaming: true},
+		fileKeys:       map[string]bool{"CACHE_DIR": true},
+	}
+
+	before := time.Now()
+	info := cfg.Introspect(4)
+
+	assert.Equal(t, "24h0m0s", info.EffectiveCacheTTL)
+	assert.Equal(t, 4, info.ActiveSDKCount)
+	assert.Equal(t, []string{FlagPromptCaching}, info.DisabledFeatureFlags)
+	assert.True(t, info.NextScheduledUpdate.After(before))
+	assert.Equal(t, time.Sunday, info.NextScheduledUpdate.Weekday())
+
+	assert.Equal(t, SourceEnv, info.ConfigSource["PORT"])
+	assert.Equal(t, SourceEnv, info.ConfigSource["L1_CACHE_SIZE"])
+	assert.Equal(t, SourceEnv, info.ConfigSource["DEFAULT_PROMPT_TEMPLATE"])
+	assert.Equal(t, SourceFile, info.ConfigSource["CACHE_DIR"])
+	assert.Equal(t, SourceDefault, info.ConfigSource["CLAUDE_MODEL"])
+	assert.Len(t, info.ConfigSource, len(envKeys))
+}
+
+func TestLoadRecordsDotenvSource(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("COMPACT_SCHEDULE=0 4 * * 0\nPORT=7070\n"), 0o644))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+	t.Setenv("PORT", "9090")
+	// Load sets COMPACT_SCHEDULE from .env; t.Setenv restores it afterwards
+	t.Setenv("COMPACT_SCHEDULE", "")
+	require.NoError(t, os.Unsetenv("COMPACT_SCHEDULE"))
+
+	cfg, err := Load(zerolog.Nop())
+	require.NoError(t, err)
+
+	assert.Equal(t, "0 4 * * 0", cfg.CompactSchedule)
+	assert.Equal(t, "9090", cfg.Port)
+
+	sources := cfg.Introspect(0).ConfigSource
+	assert.Equal(t, SourceFile, sources["COMPACT_SCHEDULE"])
+	assert.Equal(t, SourceEnv, sources["PORT"])
+}
+
+func TestLoadLogsMalformedDotenv(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("COMPACT_SCHEDULE=\"0 4 * * 0\nPORT\n"), 0o644))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+	t.Setenv("COMPACT_SCHEDULE", "")
+	require.NoError(t, os.Unsetenv("COMPACT_SCHEDULE"))
+
+	var logs bytes.Buffer
+	cfg, err := Load(zerolog.New(&logs))
+	require.NoError(t, err)
+
+	// Nothing in the file is applied or reported as coming from it
+	assert.Contains(t, logs.String(), "Failed to read .env")
+	assert.Equal(t, SourceDefault, cfg.Introspect(0).ConfigSource["COMPACT_SCHEDULE"])
+}
@@ -0,0 +1,159 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Validate reports values that the service cannot run with. Each error
+// names the environment variable to fix and what it expects; all problems
+// are returned together.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if !validPort(c.Port) {
+		errs = append(errs, fmt.Errorf("PORT=%q: must be a port number from 1 to 65535", c.Port))
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together"))
+	}
+	if c.TLSEnabled {
+		if !validPort(c.HTTPSPort) {
+			errs = append(errs, fmt.Errorf("HTTPS_PORT=%q: must be a port number from 1 to 65535", c.HTTPSPort))
+		}
+		if c.TLSDomain != "" && !validPort(c.HTTPPort) {
+			errs = append(errs, fmt.Errorf("HTTP_PORT=%q: must be a port number from 1 to 65535", c.HTTPPort))
+		}
+	}
+	if err := validateSchedule(c.UpdateSchedule); err != nil {
+		errs = append(errs, fmt.Errorf("UPDATE_SCHEDULE=%q: %w", c.UpdateSchedule, err))
+	}
+	if c.AutoCompact {
+		if err := validateSchedule(c.CompactSchedule); err != nil {
+			errs = append(errs, fmt.Errorf("COMPACT_SCHEDULE=%q: %w (or set AUTO_COMPACT=false)", c.CompactSchedule, err))
+		}
+	}
+	if c.CacheTTL <= 0 {
+		errs = append(errs, fmt.Errorf("CACHE_TTL=%s: must be a positive duration such as 168h", c.CacheTTL))
+	}
+	if c.MaxCacheSize <= 0 {
+		errs = append(errs, fmt.Errorf("MAX_CACHE_SIZE=%d: must be a positive number of bytes such as 1073741824 (1GB)", c.MaxCacheSize))
+	}
+	if c.ClaudeModel == "" {
+		errs = append(errs, errors.New("CLAUDE_MODEL is empty: set it to a Claude model name such as claude-3-5-sonnet-20241022"))
+	}
+	if c.WorkerPoolSize < 1 {
+		errs = append(errs, fmt.Errorf("WORKER_POOL_SIZE=%d: must be at least 1", c.WorkerPoolSize))
+	}
+	if c.MaxConcurrent < 1 {
+		errs = append(errs, fmt.Errorf("MAX_CONCURRENT=%d: must be at least 1", c.MaxConcurrent))
+	}
+
+	if c.AllowCredentials && slices.Contains(c.AllowedOrigins, "*") {
+		errs = append(errs, errors.New(`CORS_ALLOWED_ORIGINS="*": browsers reject credentialed requests to any origin, list the allowed origins or set CORS_ALLOW_CREDENTIALS=false`))
+	}
+
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("WEBHOOK_URL: must be an http or https URL"))
+		}
+	}
+	if c.TracingEnabled {
+		if u, err := url.Parse(c.OTLPEndpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, errors.New("OTEL_EXPORTER_OTLP_ENDPOINT: must be an http or https URL when TRACING_ENABLED is set"))
+		}
+	}
+	if c.SMTPHost != "" {
+		if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+			errs = append(errs, fmt.Errorf("SMTP_PORT=%d: must be a port number from 1 to 65535", c.SMTPPort))
+		}
+		if c.SMTPFrom == "" || len(c.SMTPTo) == 0 {
+			errs = append(errs, errors.New("SMTP_HOST is set: set SMTP_FROM to the sender and SMTP_TO to the recipients"))
+		}
+	}
+
+	switch c.CacheBackend {
+	case "buntdb":
+	case "redis":
+		if c.RedisURL == "" {
+			errs = append(errs, errors.New("CACHE_BACKEND=redis: set REDIS_URL to the Redis server, e.g. redis://localhost:6379/0"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("CACHE_BACKEND=%q: must be buntdb or redis", c.CacheBackend))
+	}
+
+	for _, check := range []struct {
+		name  string
+		value int64
+	}{
+		{"COMPRESS_THRESHOLD", c.CompressThreshold},
+		{"L1_CACHE_SIZE", int64(c.L1CacheSize)},
+		{"MIN_FREE_DISK_BYTES", c.MinFreeDiskBytes},
+		{"HEALTH_MIN_FREE_DISK_BYTES", c.HealthMinFreeDiskBytes},
+		{"GIT_CLONE_DEPTH", int64(c.GitCloneDepth)},
+		{"MAX_GIT_RETRIES", int64(c.MaxGitRetries)},
+		{"MULTI_PASS_THRESHOLD", int64(c.MultiPassThreshold)},
+		{"MAX_FILES_PER_PASS", int64(c.MaxFilesPerPass)},
+		{"INCREMENTAL_THRESHOLD", int64(c.IncrementalThreshold)},
+		{"MAX_ADHOC_TOKENS", int64(c.MaxAdhocTokens)},
+		{"ADHOC_SYNC_TOKENS", int64(c.AdhocSyncTokens)},
+		{"MAX_REQUEST_BODY_BYTES", c.MaxRequestBodyBytes},
+		{"MIN_COMPRESS_SIZE", int64(c.MinCompressSize)},
+		{"GLOBAL_RPM", int64(c.GlobalRPM)},
+		{"PER_IP_RPM", int64(c.PerIPRPM)},
+		{"MAX_CONSECUTIVE_FAILURES", int64(c.MaxConsecutiveFailures)},
+	} {
+		if check.value < 0 {
+			errs = append(errs, fmt.Errorf("%s=%d: must not be negative (0 disables it)", check.name, check.value))
+		}
+	}
+
+	for _, check := range []struct {
+		name  string
+		value float64
+	}{
+		{"CLAUDE_COST_PER_INPUT_MTOKEN", c.ClaudeCostPerInputMTok},
+		{"CLAUDE_COST_PER_OUTPUT_MTOKEN", c.ClaudeCostPerOutputMTok},
+	} {
+		if check.value < 0 {
+			errs = append(errs, fmt.Errorf("%s=%g: must not be negative", check.name, check.value))
+		}
+	}
+
+	for _, check := range []struct {
+		name  string
+		value time.Duration
+	}{
+		{"STALE_THRESHOLD", c.StaleThreshold},
+		{"CLAUDE_TIMEOUT", c.ClaudeTimeout},
+		{"DEFAULT_ENDPOINT_TIMEOUT", c.DefaultEndpointTimeout},
+		{"MAX_BACKOFF_INTERVAL", c.MaxBackoffInterval},
+		{"DRAIN_TIMEOUT", c.DrainTimeout},
+	} {
+		if check.value < 0 {
+			errs = append(errs, fmt.Errorf("%s=%s: must not be negative", check.name, check.value))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validPort reports whether port is a TCP port number.
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// validateSchedule checks a standard five-field cron expression.
+func validateSchedule(spec string) error {
+	if _, err := cron.ParseStandard(spec); err != nil {
+		return fmt.Errorf("must be a cron expression with five fields (minute hour day-of-month month day-of-week) such as \"0 2 * * 0\": %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Port:            "8080",
+		UpdateSchedule:  "0 2 * * 0",
+		AutoCompact:     true,
+		CompactSchedule: "0 3 * * 0",
+		CacheBackend:    "buntdb",
+		CacheTTL:        time.Hour,
+		MaxCacheSize:    1 << 30,
+		ClaudeModel:     "claude-3-5-sonnet-20241022",
+		MaxConcurrent:   10,
+		WorkerPoolSize:  5,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(*Config)
+		errorMsg string
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{name: "invalid schedule", modify: func(c *Config) { c.UpdateSchedule = "every sunday" }, errorMsg: `UPDATE_SCHEDULE="every sunday": must be a cron expression with five fields`},
+		{name: "seconds field in schedule", modify: func(c *Config) { c.UpdateSchedule = "0 0 2 * * 0" }, errorMsg: "UPDATE_SCHEDULE"},
+		{name: "invalid compact schedule", modify: func(c *Config) { c.CompactSchedule = "0 3 * *" }, errorMsg: "or set AUTO_COMPACT=false"},
+		{name: "compact schedule ignored when disabled", modify: func(c *Config) { c.AutoCompact = false; c.CompactSchedule = "" }},
+		{name: "zero cache TTL", modify: func(c *Config) { c.CacheTTL = 0 }, errorMsg: "CACHE_TTL=0s: must be a positive duration"},
+		{name: "negative cache TTL", modify: func(c *Config) { c.CacheTTL = -time.Minute }, errorMsg: "CACHE_TTL=-1m0s"},
+		{name: "zero max cache size", modify: func(c *Config) { c.MaxCacheSize = 0 }, errorMsg: "MAX_CACHE_SIZE=0: must be a positive number of bytes"},
+		{name: "non-numeric port", modify: func(c *Config) { c.Port = "http" }, errorMsg: `PORT="http": must be a port number from 1 to 65535`},
+		{name: "port zero", modify: func(c *Config) { c.Port = "0" }, errorMsg: "PORT"},
+		{name: "port too large", modify: func(c *Config) { c.Port = "65536" }, errorMsg: "PORT"},
+		{name: "certificate without key", modify: func(c *Config) { c.TLSCertFile = "cert.pem" }, errorMsg: "TLS_CERT_FILE and TLS_KEY_FILE must be set together"},
+		{name: "invalid HTTPS port", modify: func(c *Config) { c.TLSEnabled = true; c.HTTPSPort = "https" }, errorMsg: `HTTPS_PORT="https"`},
+		{name: "invalid HTTP port for Let's Encrypt", modify: func(c *Config) {
+			c.TLSEnabled = true
+			c.TLSDomain = "cache.example.com"
+			c.HTTPSPort = "443"
+			c.HTTPPort = "0"
+		}, errorMsg: `HTTP_PORT="0"`},
+		{name: "TLS ports ignored when disabled", modify: func(c *Config) { c.HTTPSPort = "https" }},
+		{name: "empty Claude model", modify: func(c *Config) { c.ClaudeModel = "" }, errorMsg: "CLAUDE_MODEL is empty"},
+		{name: "zero worker pool", modify: func(c *Config) { c.WorkerPoolSize = 0 }, errorMsg: "WORKER_POOL_SIZE=0: must be at least 1"},
+		{name: "negative worker pool", modify: func(c *Config) { c.WorkerPoolSize = -1 }, errorMsg: "WORKER_POOL_SIZE=-1"},
+		{name: "zero concurrency", modify: func(c *Config) { c.MaxConcurrent = 0 }, errorMsg: "MAX_CONCURRENT=0: must be at least 1"},
+		{name: "unknown backend", modify: func(c *Config) { c.CacheBackend = "memcached" }, errorMsg: `CACHE_BACKEND="memcached"`},
+		{name: "redis without URL", modify: func(c *Config) { c.CacheBackend = "redis" }, errorMsg: "set REDIS_URL"},
+		{name: "negative limit", modify: func(c *Config) { c.GlobalRPM = -1 }, errorMsg: "GLOBAL_RPM=-1: must not be negative"},
+		{name: "negative git retries", modify: func(c *Config) { c.MaxGitRetries = -1 }, errorMsg: "MAX_GIT_RETRIES=-1: must not be negative"},
+		{name: "negative compress size", modify: func(c *Config) { c.MinCompressSize = -1 }, errorMsg: "MIN_COMPRESS_SIZE=-1: must not be negative"},
+		{name: "negative health disk threshold", modify: func(c *Config) { c.HealthMinFreeDiskBytes = -1 }, errorMsg: "HEALTH_MIN_FREE_DISK_BYTES=-1: must not be negative"},
+		{name: "negative price", modify: func(c *Config) { c.ClaudeCostPerInputMTok = -0.5 }, errorMsg: "CLAUDE_COST_PER_INPUT_MTOKEN=-0.5: must not be negative"},
+		{name: "credentials with any origin", modify: func(c *Config) { c.AllowedOrigins = []string{"*"}; c.AllowCredentials = true }, errorMsg: `CORS_ALLOWED_ORIGINS="*"`},
+		{name: "credentials with listed origins", modify: func(c *Config) { c.AllowedOrigins = []string{"https://app.example.com"}; c.AllowCredentials = true }},
+		{name: "any origin without credentials", modify: func(c *Config) { c.AllowedOrigins = []string{"*"} }},
+		{name: "webhook URL without scheme", modify: func(c *Config) { c.WebhookURL = "hooks.example.com/cache" }, errorMsg: "WEBHOOK_URL: must be an http or https URL"},
+		{name: "webhook URL", modify: func(c *Config) { c.WebhookURL = "https://hooks.example.com/cache" }},
+		{name: "SMTP without recipients", modify: func(c *Config) { c.SMTPHost = "smtp.example.com"; c.SMTPPort = 587; c.SMTPFrom = "cache@example.com" }, errorMsg: "set SMTP_FROM to the sender and SMTP_TO to the recipients"},
+		{name: "invalid SMTP port", modify: func(c *Config) {
+			c.SMTPHost = "smtp.example.com"
+			c.SMTPFrom = "cache@example.com"
+			c.SMTPTo = []string{"sdk-team@example.com"}
+		}, errorMsg: "SMTP_PORT=0"},
+		{name: "negative timeout", modify: func(c *Config) { c.DrainTimeout = -time.Second }, errorMsg: "DRAIN_TIMEOUT=-1s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	cfg := validConfig()
+	cfg.WorkerPoolSize = 0
+	cfg.UpdateSchedule = "never"
+	cfg.ClaudeModel = ""
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	var joined interface{ Unwrap() []error }
+	require.True(t, errors.As(err, &joined))
+	assert.Len(t, joined.Unwrap(), 3)
+}
+
+func TestValidateDefaults(t *testing.T) {
+	chdirTemp(t)
+
+	cfg, err := Load(zerolog.Nop())
+	require.NoError(t, err)
+	assert.NoError(t, cfg.Validate())
+}
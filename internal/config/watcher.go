@@ -0,0 +1,201 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+)
+
+// dotenvFile is the file in the working directory that Load reads
+const dotenvFile = ".env"
+
+// reloadDelay is how long .env must go without changes before it is
+// reloaded, so a file that is still being written is not read half-empty
+const reloadDelay = 100 * time.Millisecond
+
+// ConfigChange describes a reload of .env that changed the configuration.
+type ConfigChange struct {
+	Old *Config
+	New *Config
+
+	// Fields names the Config fields whose values changed
+	Fields []string
+}
+
+// Changed reports whether the named Config field changed.
+func (c ConfigChange) Changed(field string) bool {
+	return slices.Contains(c.Fields, field)
+}
+
+// Watcher reloads the configuration when .env changes and sends the
+// differences to its subscribers. Only values loaded from .env can change;
+// variables set in the environment keep precedence over the file.
+type Watcher struct {
+	logger  zerolog.Logger
+	path    string
+	watcher *fsnotify.Watcher
+
+	mu          sync.Mutex
+	current     *Config
+	subscribers []chan ConfigChange
+}
+
+// NewWatcher watches .env in the working directory for changes to current,
+// which must have been returned by Load. The file need not exist yet.
+func NewWatcher(current *Config, logger zerolog.Logger) (*Watcher, error) {
+	path, err := filepath.Abs(dotenvFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", dotenvFile, err)
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+	// Watch the directory, since editors often replace the file rather
+	// than write to it
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		if closeErr := watcher.Close(); closeErr != nil {
+			logger.Error().Err(closeErr).Msg("Failed to close file watcher")
+		}
+		return nil, fmt.Errorf("failed to watch %s: %w", filepath.Dir(path), err)
+	}
+
+	return &Watcher{
+		logger:  logger,
+		path:    path,
+		watcher: watcher,
+		current: current,
+	}, nil
+}
+
+// Subscribe returns a channel that receives every configuration change.
+// It is closed when Run returns. Subscribe before calling Run.
+func (w *Watcher) Subscribe() <-chan ConfigChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	ch := make(chan ConfigChange, 1)
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+// Current returns the most recently loaded configuration.
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Run reloads the configuration on every change to .env until ctx is
+// cancelled.
+func (w *Watcher) Run(ctx context.Context) {
+	defer func() {
+		if err := w.watcher.Close(); err != nil {
+			w.logger.Error().Err(err).Msg("Failed to close file watcher")
+		}
+
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		for _, ch := range w.subscribers {
+			close(ch)
+		}
+		w.subscribers = nil
+	}()
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name != w.path || event.Op == fsnotify.Chmod {
+				continue
+			}
+			timer.Reset(reloadDelay)
+		case <-timer.C:
+			w.reload(ctx)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error().Err(err).Msg("Configuration watcher error")
+		}
+	}
+}
+
+// reload loads the configuration again and notifies subscribers if it
+// changed.
+func (w *Watcher) reload(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Load does not override variables that are already set, so clear
+	// those the previous .env supplied
+	for key := range w.current.fileKeys {
+		if err := os.Unsetenv(key); err != nil {
+			w.logger.Error().Err(err).Str("key", key).Msg("Failed to clear configuration value")
+		}
+	}
+
+	next, err := Load(w.logger)
+	if err == nil {
+		err = next.Validate()
+	}
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to reload configuration, keeping current values")
+		return
+	}
+
+	fields := changedFields(w.current, next)
+	if len(fields) == 0 {
+		return
+	}
+	change := ConfigChange{Old: w.current, New: next, Fields: fields}
+	w.current = next
+
+	w.logger.Info().
+		Strs("fields", fields).
+		Msg("Configuration reloaded")
+
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- change:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// changedFields returns the names of the exported fields that differ
+// between old and new.
+func changedFields(old, new *Config) []string {
+	oldValue := reflect.ValueOf(old).Elem()
+	newValue := reflect.ValueOf(new).Elem()
+	t := oldValue.Type()
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			fields = append(fields, t.Field(i).Name)
+		}
+	}
+	return fields
+}
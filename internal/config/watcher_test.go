@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chdirTemp changes into a new temporary directory for the test and unsets
+// keys, restoring both afterwards.
+func chdirTemp(t *testing.T, keys ...string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+
+	// Load sets these from .env; t.Setenv restores them afterwards
+	for _, key := range keys {
+		t.Setenv(key, "")
+		require.NoError(t, os.Unsetenv(key))
+	}
+	return dir
+}
+
+func TestWatcherReloadsDotenv(t *testing.T) {
+	dir := chdirTemp(t, "UPDATE_SCHEDULE", "MAX_CACHE_SIZE", "CACHE_TTL")
+	t.Setenv("PORT", "9090")
+	dotenv := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(dotenv, []byte("UPDATE_SCHEDULE=0 2 * * 0\nMAX_CACHE_SIZE=1000\nCACHE_TTL=1h\n"), 0o644))
+
+	cfg, err := Load(zerolog.Nop())
+	require.NoError(t, err)
+	require.Equal(t, "0 2 * * 0", cfg.UpdateSchedule)
+
+	watcher, err := NewWatcher(cfg, zerolog.Nop())
+	require.NoError(t, err)
+	changes := watcher.Subscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		watcher.Run(ctx)
+		close(done)
+	}()
+
+	// Invalid values are not applied
+	require.NoError(t, os.WriteFile(dotenv, []byte("UPDATE_SCHEDULE=weekly\nMAX_CACHE_SIZE=1000\nCACHE_TTL=1h\n"), 0o644))
+	select {
+	case change := <-changes:
+		t.Fatalf("invalid configuration was applied: %v", change.Fields)
+	case <-time.After(200 * time.Millisecond):
+	}
+	assert.Same(t, cfg, watcher.Current())
+
+	// CACHE_TTL is dropped from the file; PORT stays with the environment
+	require.NoError(t, os.WriteFile(dotenv, []byte("UPDATE_SCHEDULE=*/5 * * * *\nMAX_CACHE_SIZE=2000\nPORT=7070\n"), 0o644))
+
+	select {
+	case change := <-changes:
+		assert.ElementsMatch(t, []string{"UpdateSchedule", "MaxCacheSize", "CacheTTL"}, change.Fields)
+		assert.True(t, change.Changed("UpdateSchedule"))
+		assert.False(t, change.Changed("Port"))
+		assert.Same(t, cfg, change.Old)
+		assert.Equal(t, "*/5 * * * *", change.New.UpdateSchedule)
+		assert.Equal(t, int64(2000), change.New.MaxCacheSize)
+		assert.Equal(t, 7*24*time.Hour, change.New.CacheTTL)
+		assert.Equal(t, "9090", change.New.Port)
+		assert.Same(t, change.New, watcher.Current())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no configuration change was sent")
+	}
+
+	cancel()
+	<-done
+	_, open := <-changes
+	assert.False(t, open, "subscriptions are closed when the watcher stops")
+}
+
+func TestNewWatcherWithoutDotenv(t *testing.T) {
+	chdirTemp(t)
+
+	cfg, err := Load(zerolog.Nop())
+	require.NoError(t, err)
+	watcher, err := NewWatcher(cfg, zerolog.Nop())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watcher.Run(ctx)
+}
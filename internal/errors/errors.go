@@ -0,0 +1,93 @@
+// Package errors defines the typed errors shared across the service, so
+// callers can tell failures apart with errors.As instead of matching
+// message strings. Each type also matches a sentinel with errors.Is, which
+// keeps checks written against the older sentinel errors working.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrCacheMiss is matched by CacheMissError.
+	ErrCacheMiss = errors.New("key not found")
+
+	// ErrSDKNotFound is matched by SDKNotFoundError.
+	ErrSDKNotFound = errors.New("unknown SDK")
+
+	// ErrAnalysisFailed is matched by AnalysisFailedError.
+	ErrAnalysisFailed = errors.New("analysis failed")
+
+	// ErrRateLimited is matched by RateLimitError.
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// CacheMissError is returned when a cache key is missing or expired.
+type CacheMissError struct {
+	Key string
+}
+
+func (e *CacheMissError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrCacheMiss, e.Key)
+}
+
+// Is reports whether target is ErrCacheMiss.
+func (e *CacheMissError) Is(target error) bool {
+	return target == ErrCacheMiss
+}
+
+// SDKNotFoundError is returned for SDK names that are not configured.
+type SDKNotFoundError struct {
+	Name string
+}
+
+func (e *SDKNotFoundError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrSDKNotFound, e.Name)
+}
+
+// Is reports whether target is ErrSDKNotFound.
+func (e *SDKNotFoundError) Is(target error) bool {
+	return target == ErrSDKNotFound
+}
+
+// AnalysisFailedError is returned when analyzing an SDK fails. Cause is
+// the underlying error, such as a failed clone or a Claude API error.
+type AnalysisFailedError struct {
+	SDK   string
+	Cause error
+}
+
+func (e *AnalysisFailedError) Error() string {
+	return fmt.Sprintf("%s for %s: %v", ErrAnalysisFailed, e.SDK, e.Cause)
+}
+
+// Is reports whether target is ErrAnalysisFailed.
+func (e *AnalysisFailedError) Is(target error) bool {
+	return target == ErrAnalysisFailed
+}
+
+// Unwrap returns the underlying error.
+func (e *AnalysisFailedError) Unwrap() error {
+	return e.Cause
+}
+
+// RateLimitError is returned when the Claude API keeps rejecting requests
+// for exceeding its rate limit. RetryAfter is how long the API asked
+// callers to wait, or zero when it did not say.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter <= 0 {
+		return ErrRateLimited.Error()
+	}
+	return fmt.Sprintf("%s: retry after %s", ErrRateLimited, e.RetryAfter)
+}
+
+// Is reports whether target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
@@ -0,0 +1,80 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+		message  string
+	}{
+		{
+			name:     "cache miss",
+			err:      &CacheMissError{Key: "sdk:sentry-go"},
+			sentinel: ErrCacheMiss,
+			message:  "key not found: sdk:sentry-go",
+		},
+		{
+			name:     "sdk not found",
+			err:      &SDKNotFoundError{Name: "sentry-cobol"},
+			sentinel: ErrSDKNotFound,
+			message:  "unknown SDK: sentry-cobol",
+		},
+		{
+			name:     "analysis failed",
+			err:      &AnalysisFailedError{SDK: "sentry-go", Cause: errors.New("clone failed")},
+			sentinel: ErrAnalysisFailed,
+			message:  "analysis failed for sentry-go: clone failed",
+		},
+		{
+			name:     "rate limited",
+			err:      &RateLimitError{RetryAfter: 30 * time.Second},
+			sentinel: ErrRateLimited,
+			message:  "rate limited: retry after 30s",
+		},
+		{
+			name:     "rate limited without retry after",
+			err:      &RateLimitError{},
+			sentinel: ErrRateLimited,
+			message:  "rate limited",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.message, tt.err.Error())
+
+			wrapped := fmt.Errorf("request failed: %w", tt.err)
+			assert.ErrorIs(t, wrapped, tt.sentinel)
+		})
+	}
+}
+
+func TestAnalysisFailedErrorUnwrap(t *testing.T) {
+	rateLimited := &RateLimitError{RetryAfter: time.Second}
+	err := fmt.Errorf("warm failed: %w", &AnalysisFailedError{
+		SDK:   "sentry-go",
+		Cause: fmt.Errorf("max retries exceeded: %w", rateLimited),
+	})
+
+	var failed *AnalysisFailedError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, "sentry-go", failed.SDK)
+
+	var limited *RateLimitError
+	require.True(t, errors.As(err, &limited))
+	assert.Equal(t, time.Second, limited.RetryAfter)
+
+	canceled := &AnalysisFailedError{SDK: "sentry-go", Cause: context.Canceled}
+	assert.ErrorIs(t, canceled, context.Canceled)
+}
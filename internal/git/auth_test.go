@@ -0,0 +1,97 @@
+package git
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGitHubToken = "ghp_test"
+
+// newPrivateGitServer serves a new repository over smart HTTP to clients
+// authenticating with testGitHubToken, and returns its URL.
+func newPrivateGitServer(t *testing.T) string {
+	t.Helper()
+
+	root := t.TempDir()
+	sourcePath := filepath.Join(root, "private-repo")
+	source, err := git.PlainInit(sourcePath, false)
+	require.NoError(t, err)
+	commitFiles(t, source, sourcePath, "initial", 1)
+
+	backend := gitHTTPBackend(t, root)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != testGitHubToken {
+			w.Header().Set("WWW-Authenticate", `Basic realm="GitHub"`)
+			http.Error(w, "authentication required", http.StatusUnauthorized)
+			return
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/private-repo"
+}
+
+func TestCloneWithGitHubToken(t *testing.T) {
+	repoURL := newPrivateGitServer(t)
+	serverURL, err := url.Parse(repoURL)
+	require.NoError(t, err)
+
+	newClient := func(token string) *Client {
+		client := NewClient(t.TempDir(), zerolog.Nop())
+		client.GitHubToken = token
+		client.tokenHost = serverURL.Hostname()
+		return client
+	}
+
+	t.Run("valid token", func(t *testing.T) {
+		client := newClient(testGitHubToken)
+		require.NoError(t, client.Clone(context.Background(), repoURL, "master"))
+		assert.FileExists(t, filepath.Join(client.GetRepoPath(repoURL), "initial-0.txt"))
+
+		// Pulls authenticate with the token too
+		require.NoError(t, client.Clone(context.Background(), repoURL, "master"))
+	})
+
+	t.Run("no token", func(t *testing.T) {
+		client := newClient("")
+		err := client.Clone(context.Background(), repoURL, "master")
+		require.ErrorIs(t, err, transport.ErrAuthenticationRequired)
+		assert.NoDirExists(t, client.GetRepoPath(repoURL))
+	})
+
+	t.Run("wrong token", func(t *testing.T) {
+		client := newClient("ghp_wrong")
+		err := client.Clone(context.Background(), repoURL, "master")
+		require.Error(t, err)
+		assert.NoDirExists(t, client.GetRepoPath(repoURL))
+	})
+}
+
+func TestAuthGitHubToken(t *testing.T) {
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	client.GitHubToken = testGitHubToken
+
+	auth, err := client.auth("https://github.com/getsentry/sentry-go")
+	require.NoError(t, err)
+	assert.Equal(t, &githttp.BasicAuth{Username: "token", Password: testGitHubToken}, auth)
+
+	// The token is only sent to GitHub
+	auth, err = client.auth("https://gitlab.com/getsentry/sentry-go")
+	require.NoError(t, err)
+	assert.Nil(t, auth)
+
+	auth, err = client.auth("git@github.com:getsentry/sentry-go.git")
+	require.NoError(t, err)
+	assert.Nil(t, auth)
+}
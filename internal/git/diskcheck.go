@@ -0,0 +1,148 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrInsufficientDiskSpace is returned when a clone would not fit on disk.
+var ErrInsufficientDiskSpace = errors.New("insufficient disk space")
+
+// githubAPIURL is the GitHub REST API used to estimate repository sizes
+const githubAPIURL = "https://api.github.com"
+
+// githubHost is the host of GitHub repository URLs
+const githubHost = "github.com"
+
+// StatFS reports the free space of the filesystem holding a directory.
+type StatFS interface {
+	// Available returns the bytes available to unprivileged users
+	Available(dir string) (int64, error)
+}
+
+// CheckDiskSpace returns ErrInsufficientDiskSpace if the filesystem holding
+// dir has fewer than requiredBytes available. dir need not exist yet.
+func CheckDiskSpace(dir string, requiredBytes int64) error {
+	return checkDiskSpace(systemStatFS{}, dir, requiredBytes)
+}
+
+func checkDiskSpace(fs StatFS, dir string, requiredBytes int64) error {
+	available, err := fs.Available(existingParent(dir))
+	if err != nil {
+		return fmt.Errorf("failed to check disk space: %w", err)
+	}
+
+	if available < requiredBytes {
+		return fmt.Errorf("%w: %d bytes required in %s, %d available",
+			ErrInsufficientDiskSpace, requiredBytes, dir, available)
+	}
+	return nil
+}
+
+// existingParent returns dir or its nearest ancestor that exists
+func existingParent(dir string) string {
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return dir
+		}
+		dir = parent
+	}
+}
+
+// CheckCloneSpace verifies there is room to clone repoURL while keeping the
+// configured minimum free. estimatedSize is used when positive; otherwise
+// the size is looked up from the GitHub API for GitHub URLs. Repositories
+// that are already cloned are only pulled, so they always pass.
+func (g *Client) CheckCloneSpace(ctx context.Context, repoURL string, estimatedSize int64) error {
+	repoPath := g.GetRepoPath(repoURL)
+	if _, err := os.Stat(repoPath); err == nil {
+		return nil
+	}
+
+	if estimatedSize <= 0 {
+		size, err := g.estimateRepoSize(ctx, repoURL)
+		if err != nil {
+			g.logger.Debug().
+				Err(err).
+				Str("url", repoURL).
+				Msg("Could not estimate repository size")
+		}
+		estimatedSize = size
+	}
+
+	return checkDiskSpace(g.statfs, g.workDir, estimatedSize+g.minFreeBytes)
+}
+
+// estimateRepoSize returns the repository size reported by the GitHub API,
+// or zero for repositories hosted elsewhere
+func (g *Client) estimateRepoSize(ctx context.Context, repoURL string) (int64, error) {
+	owner, repo, ok := parseGitHubURL(repoURL)
+	if !ok {
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/repos/%s/%s", g.githubAPI, owner, repo), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if g.GitHubToken != "" {
+		// Private repositories are not found without it
+		req.Header.Set("Authorization", "Bearer "+g.GitHubToken)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query GitHub API: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			g.logger.Debug().Err(err).Msg("Failed to close GitHub API response body")
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var info struct {
+		Size int64 `json:"size"` // Kilobytes
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return 0, fmt.Errorf("failed to decode GitHub API response: %w", err)
+	}
+	return info.Size * 1024, nil
+}
+
+// parseGitHubURL extracts owner and repository from a GitHub URL
+func parseGitHubURL(repoURL string) (owner, repo string, ok bool) {
+	var path string
+	switch {
+	case strings.HasPrefix(repoURL, "https://github.com/"):
+		path = strings.TrimPrefix(repoURL, "https://github.com/")
+	case strings.HasPrefix(repoURL, "git@github.com:"):
+		path = strings.TrimPrefix(repoURL, "git@github.com:")
+	default:
+		return "", "", false
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
@@ -0,0 +1,186 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStatFS reports a fixed amount of free space.
+type mockStatFS struct {
+	available int64
+	err       error
+	dirs      []string
+}
+
+func (m *mockStatFS) Available(dir string) (int64, error) {
+	m.dirs = append(m.dirs, dir)
+	return m.available, m.err
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	tests := []struct {
+		name        string
+		statfs      *mockStatFS
+		required    int64
+		expectedErr error
+	}{
+		{
+			name:     "enough space",
+			statfs:   &mockStatFS{available: 2 << 30},
+			required: 1 << 30,
+		},
+		{
+			name:     "exactly enough space",
+			statfs:   &mockStatFS{available: 1 << 30},
+			required: 1 << 30,
+		},
+		{
+			name:        "insufficient space",
+			statfs:      &mockStatFS{available: 1 << 20},
+			required:    1 << 30,
+			expectedErr: ErrInsufficientDiskSpace,
+		},
+		{
+			name:     "statfs failure",
+			statfs:   &mockStatFS{err: errors.New("boom")},
+			required: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkDiskSpace(tt.statfs, t.TempDir(), tt.required)
+			switch {
+			case tt.expectedErr != nil:
+				assert.ErrorIs(t, err, tt.expectedErr)
+			case tt.statfs.err != nil:
+				assert.ErrorIs(t, err, tt.statfs.err)
+			default:
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckDiskSpaceMissingDir(t *testing.T) {
+	base := t.TempDir()
+	statfs := &mockStatFS{available: 1 << 30}
+
+	require.NoError(t, checkDiskSpace(statfs, filepath.Join(base, "repos", "sentry-go"), 1))
+	assert.Equal(t, []string{base}, statfs.dirs)
+}
+
+func TestCheckDiskSpaceSystem(t *testing.T) {
+	assert.NoError(t, CheckDiskSpace(t.TempDir(), 0))
+	assert.ErrorIs(t, CheckDiskSpace(t.TempDir(), 1<<62), ErrInsufficientDiskSpace)
+}
+
+func TestCheckCloneSpace(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/getsentry/sentry-javascript" {
+			http.NotFound(w, r)
+			return
+		}
+		_, err := w.Write([]byte(`{"size": 1024}`)) // 1MB
+		require.NoError(t, err)
+	}))
+	defer api.Close()
+
+	tests := []struct {
+		name          string
+		repoURL       string
+		estimatedSize int64
+		available     int64
+		expectedErr   error
+	}{
+		{
+			name:          "configured size fits",
+			repoURL:       "https://github.com/getsentry/sentry-javascript",
+			estimatedSize: 1 << 20,
+			available:     3 << 20,
+		},
+		{
+			name:          "configured size does not fit",
+			repoURL:       "https://github.com/getsentry/sentry-javascript",
+			estimatedSize: 2 << 20,
+			available:     3<<20 - 1,
+			expectedErr:   ErrInsufficientDiskSpace,
+		},
+		{
+			name:        "github size does not fit",
+			repoURL:     "https://github.com/getsentry/sentry-javascript.git",
+			available:   2<<20 - 1,
+			expectedErr: ErrInsufficientDiskSpace,
+		},
+		{
+			name:      "github lookup fails, minimum still enforced",
+			repoURL:   "https://github.com/getsentry/unknown",
+			available: 1 << 20,
+		},
+		{
+			name:        "non-github repository",
+			repoURL:     "https://gitlab.com/example/repo",
+			available:   1<<20 - 1,
+			expectedErr: ErrInsufficientDiskSpace,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(t.TempDir(), zerolog.Nop())
+			client.statfs = &mockStatFS{available: tt.available}
+			client.githubAPI = api.URL
+			client.SetMinFreeDiskBytes(1 << 20)
+
+			err := client.CheckCloneSpace(context.Background(), tt.repoURL, tt.estimatedSize)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCheckCloneSpaceExistingRepo(t *testing.T) {
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	client.statfs = &mockStatFS{available: 0}
+
+	repoURL := "https://github.com/getsentry/sentry-go"
+	require.NoError(t, os.MkdirAll(client.GetRepoPath(repoURL), 0755))
+
+	assert.NoError(t, client.CheckCloneSpace(context.Background(), repoURL, 1<<30))
+}
+
+func TestParseGitHubURL(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		owner   string
+		repo    string
+		ok      bool
+	}{
+		{"https://github.com/getsentry/sentry-go", "getsentry", "sentry-go", true},
+		{"https://github.com/getsentry/sentry-go.git", "getsentry", "sentry-go", true},
+		{"git@github.com:getsentry/sentry-python.git", "getsentry", "sentry-python", true},
+		{"https://gitlab.com/getsentry/sentry-go", "", "", false},
+		{"https://github.com/getsentry", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repoURL, func(t *testing.T) {
+			owner, repo, ok := parseGitHubURL(tt.repoURL)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.owner, owner)
+			assert.Equal(t, tt.repo, repo)
+		})
+	}
+}
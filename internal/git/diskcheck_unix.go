@@ -0,0 +1,16 @@
+//go:build !windows
+
+package git
+
+import "golang.org/x/sys/unix"
+
+// systemStatFS queries the operating system with statfs(2)
+type systemStatFS struct{}
+
+func (systemStatFS) Available(dir string) (int64, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
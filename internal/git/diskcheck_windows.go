@@ -0,0 +1,21 @@
+//go:build windows
+
+package git
+
+import "golang.org/x/sys/windows"
+
+// systemStatFS queries the operating system with GetDiskFreeSpaceEx
+type systemStatFS struct{}
+
+func (systemStatFS) Available(dir string) (int64, error) {
+	path, err := windows.UTF16PtrFromString(dir)
+	if err != nil {
+		return 0, err
+	}
+
+	var available, total, free uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &total, &free); err != nil {
+		return 0, err
+	}
+	return int64(available), nil
+}
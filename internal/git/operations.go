@@ -2,31 +2,160 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
 	"github.com/rs/zerolog"
+	"golang.org/x/mod/semver"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
 )
 
+// ErrRepoNotCloned is returned when reading a repository that has not been
+// cloned.
+var ErrRepoNotCloned = git.ErrRepositoryNotExists
+
+// ErrNoTags is returned by GetLatestTag for repositories without semantic
+// version tags.
+var ErrNoTags = errors.New("no semantic version tags")
+
 // Client handles Git operations for SDK repositories
 type Client struct {
-	workDir string
-	logger  zerolog.Logger
+	// Depth is the number of commits to clone; 0 clones full history
+	Depth int
+
+	// SSHKeyPath is the private key used for SSH repository URLs, and
+	// SSHKeyPassphrase decrypts it. Host keys are verified against the
+	// known_hosts files in SSH_KNOWN_HOSTS, or ~/.ssh/known_hosts and
+	// /etc/ssh/ssh_known_hosts when it is unset.
+	SSHKeyPath       string
+	SSHKeyPassphrase string
+
+	// GitHubToken is a personal access token sent as basic auth to
+	// GitHub HTTPS URLs, for private repositories
+	GitHubToken string
+
+	// MaxRetries is how many times Clone retries pulling an already cloned
+	// repository after a network error; see PullWithRetry
+	MaxRetries int
+
+	workDir  string
+	logger   zerolog.Logger
+	features config.FeatureChecker
+	progress ProgressReporter
+
+	// Disk space pre-check before cloning
+	statfs       StatFS
+	minFreeBytes int64
+	githubAPI    string
+
+	// tokenHost is the only host GitHubToken is sent to
+	tokenHost string
 }
 
 // NewClient creates a new Git client
 func NewClient(workDir string, logger zerolog.Logger) *Client {
 	return &Client{
-		workDir: workDir,
-		logger:  logger,
+		workDir:   workDir,
+		logger:    logger,
+		statfs:    systemStatFS{},
+		githubAPI: githubAPIURL,
+		tokenHost: githubHost,
 	}
 }
 
+// HasGitHubToken reports whether private GitHub repositories can be cloned
+func (g *Client) HasGitHubToken() bool {
+	return g.GitHubToken != ""
+}
+
+// SetFeatureFlags makes the client honour runtime feature flags
+func (g *Client) SetFeatureFlags(features config.FeatureChecker) {
+	g.features = features
+}
+
+// SetMinFreeDiskBytes sets the free space that must remain after a clone
+func (g *Client) SetMinFreeDiskBytes(n int64) {
+	g.minFreeBytes = n
+}
+
+// SetProgressReporter reports clone and pull progress to reporter
+func (g *Client) SetProgressReporter(reporter ProgressReporter) {
+	g.progress = reporter
+}
+
+// auth returns the authentication for repoURL: the SSH key for SSH URLs
+// and the GitHub token for GitHub HTTPS URLs when they are configured, and
+// nil otherwise
+func (g *Client) auth(repoURL string) (transport.AuthMethod, error) {
+	if g.SSHKeyPath == "" && g.GitHubToken == "" {
+		return nil, nil
+	}
+
+	endpoint, err := transport.NewEndpoint(repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository URL: %w", err)
+	}
+	switch endpoint.Protocol {
+	case "http", "https":
+		if g.GitHubToken == "" || endpoint.Host != g.tokenHost {
+			return nil, nil
+		}
+		// GitHub accepts any username with a token as the password
+		return &githttp.BasicAuth{Username: "token", Password: g.GitHubToken}, nil
+	case "ssh":
+	default:
+		return nil, nil
+	}
+	if g.SSHKeyPath == "" {
+		return nil, nil
+	}
+
+	user := endpoint.User
+	if user == "" {
+		user = "git"
+	}
+	keys, err := gitssh.NewPublicKeysFromFile(user, g.SSHKeyPath, g.SSHKeyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH key: %w", err)
+	}
+	return keys, nil
+}
+
+// remoteAuth returns the authentication for the origin remote of repo
+func (g *Client) remoteAuth(repo *git.Repository) (transport.AuthMethod, error) {
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get origin remote: %w", err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	return g.auth(urls[0])
+}
+
+// progressWriter returns the writer for go-git progress output, or nil to
+// suppress it when no reporter is set
+func (g *Client) progressWriter() io.Writer {
+	if g.progress == nil {
+		return nil
+	}
+	return newProgressWriter(g.progress)
+}
+
 // Clone clones a repository to the specified path
 func (g *Client) Clone(ctx context.Context, repoURL, branch string) error {
 	repoName := getRepoName(repoURL)
@@ -38,19 +167,41 @@ func (g *Client) Clone(ctx context.Context, repoURL, branch string) error {
 			Str("repo", repoName).
 			Str("path", repoPath).
 			Msg("Repository already exists, pulling latest changes")
-		return g.Pull(ctx, repoPath)
+		if err := g.PullWithRetry(ctx, repoPath, g.MaxRetries+1); err != nil {
+			g.logger.Warn().
+				Err(err).
+				Str("repo", repoName).
+				Msg("Pull failed, fetching instead")
+			return g.Fetch(ctx, repoPath)
+		}
+		return nil
 	}
 
 	g.logger.Info().
 		Str("repo", repoName).
 		Str("url", repoURL).
 		Str("branch", branch).
+		Int("depth", g.Depth).
 		Msg("Cloning repository")
 
+	auth, err := g.auth(repoURL)
+	if err != nil {
+		return err
+	}
+
 	opts := &git.CloneOptions{
-		URL:               repoURL,
-		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		Progress:          nil, // Suppress progress output
+		URL:        repoURL,
+		Auth:       auth,
+		Progress:   g.progressWriter(),
+		NoCheckout: false,
+		Tags:       git.TagFollowing,
+	}
+	if g.Depth > 0 {
+		opts.Depth = g.Depth
+	}
+
+	if config.FeatureEnabled(g.features, config.FlagGitSubmodules) {
+		opts.RecurseSubmodules = git.DefaultSubmoduleRecursionDepth
 	}
 
 	if branch != "" && branch != "main" && branch != "master" {
@@ -58,7 +209,7 @@ func (g *Client) Clone(ctx context.Context, repoURL, branch string) error {
 		opts.SingleBranch = true
 	}
 
-	_, err := git.PlainCloneContext(ctx, repoPath, false, opts)
+	_, err = git.PlainCloneContext(ctx, repoPath, false, opts)
 	if err != nil {
 		return fmt.Errorf("failed to clone repository: %w", err)
 	}
@@ -82,13 +233,19 @@ func (g *Client) Pull(ctx context.Context, repoPath string) error {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
+	auth, err := g.remoteAuth(repo)
+	if err != nil {
+		return err
+	}
+
 	g.logger.Debug().
 		Str("path", repoPath).
 		Msg("Pulling latest changes")
 
 	err = w.PullContext(ctx, &git.PullOptions{
 		RemoteName: "origin",
-		Progress:   nil,
+		Auth:       auth,
+		Progress:   g.progressWriter(),
 	})
 
 	if err != nil && err != git.NoErrAlreadyUpToDate {
@@ -108,6 +265,64 @@ func (g *Client) Pull(ctx context.Context, repoPath string) error {
 	return nil
 }
 
+// Fetch fetches the latest changes from origin, at most Depth commits deep,
+// and hard-resets the worktree to them. Unlike Pull it does not need the
+// local branch to fast-forward, so it also updates repositories whose
+// history was rewritten upstream or that were cloned at full depth.
+func (g *Client) Fetch(ctx context.Context, repoPath string) error {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	auth, err := g.remoteAuth(repo)
+	if err != nil {
+		return err
+	}
+
+	g.logger.Debug().
+		Str("path", repoPath).
+		Int("depth", g.Depth).
+		Msg("Fetching latest changes")
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+		Depth:      g.Depth,
+		Tags:       git.TagFollowing,
+		Force:      true,
+		Progress:   g.progressWriter(),
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch changes: %w", err)
+	}
+
+	remoteRef, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve remote branch: %w", err)
+	}
+
+	w, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := w.Reset(&git.ResetOptions{Commit: remoteRef.Hash(), Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("failed to reset worktree: %w", err)
+	}
+
+	g.logger.Info().
+		Str("path", repoPath).
+		Str("commit", remoteRef.Hash().String()).
+		Msg("Repository fetched successfully")
+
+	return nil
+}
+
 // Commit represents a git commit
 type Commit struct {
 	Hash      string
@@ -119,6 +334,12 @@ type Commit struct {
 
 // GetCommitsSince returns all commits since the specified time
 func (g *Client) GetCommitsSince(ctx context.Context, repoPath string, since time.Time) ([]Commit, error) {
+	return g.GetRecentCommits(ctx, repoPath, since, 0)
+}
+
+// GetRecentCommits is like GetCommitsSince but returns at most limit
+// commits, newest first; a limit of 0 returns them all.
+func (g *Client) GetRecentCommits(ctx context.Context, repoPath string, since time.Time, limit int) ([]Commit, error) {
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open repository: %w", err)
@@ -142,6 +363,9 @@ func (g *Client) GetCommitsSince(ctx context.Context, repoPath string, since tim
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
+		if limit > 0 && len(commits) == limit {
+			return storer.ErrStop
+		}
 
 		commit := Commit{
 			Hash:      c.Hash.String(),
@@ -243,6 +467,46 @@ func (g *Client) GetLatestCommit(ctx context.Context, repoPath string) (*Commit,
 	}, nil
 }
 
+// GetLatestTag returns the name of the repository's highest semantic
+// version tag, with or without a "v" prefix. Tags that are not semantic
+// versions are ignored; ErrNoTags is returned if no tag is left. Clones
+// only hold the tags of the commits they fetched, so shallow clones may
+// not know of older releases.
+func (g *Client) GetLatestTag(ctx context.Context, repoPath string) (string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open repository: %w", err)
+	}
+
+	tags, err := repo.Tags()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	var latest, latestVersion string
+	err = tags.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name().Short()
+		version := name
+		if !strings.HasPrefix(version, "v") {
+			version = "v" + version
+		}
+		if !semver.IsValid(version) {
+			return nil
+		}
+		if latest == "" || semver.Compare(version, latestVersion) > 0 {
+			latest, latestVersion = name, version
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list tags: %w", err)
+	}
+	if latest == "" {
+		return "", ErrNoTags
+	}
+	return latest, nil
+}
+
 // getRepoName extracts repository name from URL
 func getRepoName(repoURL string) string {
 	// Extract repo name from URL
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -110,6 +111,26 @@ func TestGitOperations(t *testing.T) {
 	assert.Contains(t, files, "test.txt")
 }
 
+func TestGetRecentCommits(t *testing.T) {
+	repoPath := strings.TrimPrefix(createHistoryRepo(t, 5), "file://")
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	ctx := context.Background()
+
+	commits, err := client.GetRecentCommits(ctx, repoPath, time.Time{}, 3)
+	require.NoError(t, err)
+	require.Len(t, commits, 3)
+	assert.Equal(t, "Add file004.go", commits[0].Message)
+	assert.Equal(t, []string{"file004.go"}, commits[0].Files)
+	assert.Equal(t, "Add file002.go", commits[2].Message)
+
+	commits, err = client.GetRecentCommits(ctx, repoPath, time.Time{}, 0)
+	require.NoError(t, err)
+	assert.Len(t, commits, 5)
+
+	_, err = client.GetRecentCommits(ctx, filepath.Join(t.TempDir(), "missing"), time.Time{}, 3)
+	assert.ErrorIs(t, err, ErrRepoNotCloned)
+}
+
 func TestCloneNonExistentRepo(t *testing.T) {
 	tempDir := t.TempDir()
 	logger := zerolog.Nop()
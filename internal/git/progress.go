@@ -0,0 +1,120 @@
+package git
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ProgressReporter receives progress updates from long-running git
+// operations. Phase is the lowercased remote phase name, e.g.
+// "counting objects" or "compressing objects".
+type ProgressReporter interface {
+	Update(phase string, completed, total int)
+}
+
+// progressLine matches remote progress such as "Counting objects:  45% (45/100)".
+var progressLine = regexp.MustCompile(`^([^:]+):\s+\d+% \((\d+)/(\d+)\)`)
+
+// progressWriter parses the sideband progress stream written by go-git and
+// forwards it to a ProgressReporter. Lines are terminated by \r or \n and
+// may be split across writes.
+type progressWriter struct {
+	reporter ProgressReporter
+	buf      []byte
+}
+
+func newProgressWriter(reporter ProgressReporter) *progressWriter {
+	return &progressWriter{reporter: reporter}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.parseLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *progressWriter) parseLine(line string) {
+	m := progressLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return
+	}
+
+	completed, err := strconv.Atoi(m[2])
+	if err != nil {
+		return
+	}
+	total, err := strconv.Atoi(m[3])
+	if err != nil {
+		return
+	}
+
+	w.reporter.Update(strings.ToLower(m[1]), completed, total)
+}
+
+// LogProgressReporter logs progress every 10% per phase, at most once per
+// interval. Completion of a phase is always logged.
+type LogProgressReporter struct {
+	logger   zerolog.Logger
+	interval time.Duration
+
+	mu      sync.Mutex
+	phase   string
+	percent int
+	logged  time.Time
+}
+
+// NewLogProgressReporter creates a reporter that logs to logger.
+func NewLogProgressReporter(logger zerolog.Logger, interval time.Duration) *LogProgressReporter {
+	return &LogProgressReporter{
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+// Update implements ProgressReporter.
+func (r *LogProgressReporter) Update(phase string, completed, total int) {
+	if total <= 0 {
+		return
+	}
+	percent := completed * 100 / total
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if phase != r.phase {
+		r.phase = phase
+		r.percent = -1
+		r.logged = time.Time{}
+	}
+
+	step := percent / 10 * 10
+	if step <= r.percent {
+		return
+	}
+	if percent < 100 && time.Since(r.logged) < r.interval {
+		return
+	}
+
+	r.percent = step
+	r.logged = time.Now()
+
+	r.logger.Info().
+		Str("phase", phase).
+		Int("completed", completed).
+		Int("total", total).
+		Int("percent", percent).
+		Msg("Git progress")
+}
@@ -0,0 +1,180 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type progressUpdate struct {
+	Phase     string
+	Completed int
+	Total     int
+}
+
+// captureReporter records every progress update it receives.
+type captureReporter struct {
+	mu      sync.Mutex
+	updates []progressUpdate
+}
+
+func (r *captureReporter) Update(phase string, completed, total int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.updates = append(r.updates, progressUpdate{phase, completed, total})
+}
+
+func (r *captureReporter) Updates() []progressUpdate {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]progressUpdate(nil), r.updates...)
+}
+
+// commitFiles writes count files to the worktree at path and commits them.
+func commitFiles(t *testing.T, repo *git.Repository, path, prefix string, count int) {
+	t.Helper()
+
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("%s-%d.txt", prefix, i)
+		require.NoError(t, os.WriteFile(filepath.Join(path, name), []byte(name), 0644))
+		_, err = w.Add(name)
+		require.NoError(t, err)
+	}
+
+	_, err = w.Commit("Add "+prefix+" files", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+}
+
+// assertOrdered checks that phases are not revisited and progress within
+// a phase never goes backwards.
+func assertOrdered(t *testing.T, updates []progressUpdate) {
+	t.Helper()
+
+	seen := make(map[string]bool)
+	for i, u := range updates {
+		if i > 0 && updates[i-1].Phase == u.Phase {
+			assert.GreaterOrEqual(t, u.Completed, updates[i-1].Completed, "progress went backwards in %q", u.Phase)
+			continue
+		}
+		assert.False(t, seen[u.Phase], "phase %q reported again after another phase", u.Phase)
+		seen[u.Phase] = true
+	}
+}
+
+func TestProgressWriter(t *testing.T) {
+	reporter := &captureReporter{}
+	w := newProgressWriter(reporter)
+
+	chunks := []string{
+		"Enumerating objects: 3, done.\n",
+		"Counting objects:  33% (1/3)\rCounting obj",
+		"ects:  66% (2/3)\rCounting objects: 100% (3/3)\r",
+		"Counting objects: 100% (3/3), done.\nCompressing objects: 100% (2/2), done.\n",
+		"Total 3 (delta 0), reused 0 (delta 0), pack-reused 0\n",
+	}
+	for _, chunk := range chunks {
+		n, err := w.Write([]byte(chunk))
+		require.NoError(t, err)
+		assert.Equal(t, len(chunk), n)
+	}
+
+	assert.Equal(t, []progressUpdate{
+		{"counting objects", 1, 3},
+		{"counting objects", 2, 3},
+		{"counting objects", 3, 3},
+		{"counting objects", 3, 3},
+		{"compressing objects", 2, 2},
+	}, reporter.Updates())
+}
+
+func TestCloneAndPullReportProgress(t *testing.T) {
+	sourcePath := filepath.Join(t.TempDir(), "source-repo")
+	source, err := git.PlainInit(sourcePath, false)
+	require.NoError(t, err)
+	commitFiles(t, source, sourcePath, "initial", 200)
+
+	reporter := &captureReporter{}
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	client.SetProgressReporter(reporter)
+
+	ctx := context.Background()
+	require.NoError(t, client.Clone(ctx, sourcePath, ""))
+
+	updates := reporter.Updates()
+	require.NotEmpty(t, updates)
+	assert.Equal(t, "counting objects", updates[0].Phase)
+	last := updates[len(updates)-1]
+	assert.Equal(t, last.Total, last.Completed)
+	assertOrdered(t, updates)
+
+	// A second clone of the same URL pulls instead
+	commitFiles(t, source, sourcePath, "update", 50)
+	cloned := len(updates)
+	require.NoError(t, client.Clone(ctx, sourcePath, ""))
+
+	updates = reporter.Updates()
+	require.Greater(t, len(updates), cloned)
+	assertOrdered(t, updates[cloned:])
+}
+
+func TestLogProgressReporter(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		expected []int
+	}{
+		{
+			name:     "every ten percent",
+			interval: 0,
+			expected: []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 100},
+		},
+		{
+			name:     "throttled by interval",
+			interval: time.Hour,
+			expected: []int{0, 100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			reporter := NewLogProgressReporter(zerolog.New(&buf), tt.interval)
+
+			for i := 0; i <= 200; i++ {
+				reporter.Update("receiving objects", i, 200)
+			}
+
+			var percents []int
+			decoder := json.NewDecoder(&buf)
+			for decoder.More() {
+				var entry struct {
+					Phase   string `json:"phase"`
+					Total   int    `json:"total"`
+					Percent int    `json:"percent"`
+				}
+				require.NoError(t, decoder.Decode(&entry))
+				assert.Equal(t, "receiving objects", entry.Phase)
+				assert.Equal(t, 200, entry.Total)
+				percents = append(percents, entry.Percent)
+			}
+			assert.Equal(t, tt.expected, percents)
+		})
+	}
+}
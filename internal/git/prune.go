@@ -0,0 +1,125 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// PruneInactiveRepos removes repositories in the work directory that do not
+// belong to any of activeURLs and returns the removed paths. Removal
+// continues past individual failures, which are returned together.
+func (g *Client) PruneInactiveRepos(ctx context.Context, activeURLs []string) ([]string, error) {
+	entries, err := os.ReadDir(g.workDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	active := make(map[string]bool, len(activeURLs))
+	for _, url := range activeURLs {
+		active[getRepoName(url)] = true
+	}
+
+	var removed []string
+	var errs []error
+	for _, entry := range entries {
+		if !entry.IsDir() || active[entry.Name()] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return removed, errors.Join(append(errs, err)...)
+		}
+
+		repoPath := filepath.Join(g.workDir, entry.Name())
+		if err := os.RemoveAll(repoPath); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", repoPath, err))
+			continue
+		}
+
+		g.logger.Info().
+			Str("repo", entry.Name()).
+			Str("path", repoPath).
+			Msg("Removed inactive repository")
+		removed = append(removed, repoPath)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// CleanStaleRepos removes repositories that do not belong to any of
+// activeURLs, like PruneInactiveRepos, and returns the names of the removed
+// repositories.
+func (g *Client) CleanStaleRepos(ctx context.Context, activeURLs []string) ([]string, error) {
+	removed, err := g.PruneInactiveRepos(ctx, activeURLs)
+	names := make([]string, 0, len(removed))
+	for _, repoPath := range removed {
+		names = append(names, filepath.Base(repoPath))
+	}
+	return names, err
+}
+
+// RemoveRepo removes the clone of repoURL from the work directory. It
+// reports false when there was no clone to remove.
+func (g *Client) RemoveRepo(repoURL string) (bool, error) {
+	repoPath := g.GetRepoPath(repoURL)
+	if _, err := os.Stat(repoPath); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to inspect %s: %w", repoPath, err)
+	}
+
+	if err := os.RemoveAll(repoPath); err != nil {
+		return false, fmt.Errorf("failed to remove %s: %w", repoPath, err)
+	}
+	g.logger.Info().
+		Str("repo", filepath.Base(repoPath)).
+		Str("path", repoPath).
+		Msg("Removed repository")
+	return true, nil
+}
+
+// GetDiskUsage returns the total size in bytes of the files in each
+// repository in the work directory, keyed by repository name.
+func (g *Client) GetDiskUsage() (map[string]int64, error) {
+	usage := make(map[string]int64)
+
+	entries, err := os.ReadDir(g.workDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return usage, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		var size int64
+		err := filepath.WalkDir(filepath.Join(g.workDir, entry.Name()), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure %s: %w", entry.Name(), err)
+		}
+		usage[entry.Name()] = size
+	}
+	return usage, nil
+}
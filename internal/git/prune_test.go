@@ -0,0 +1,131 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPruneInactiveRepos(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+
+	repos := []string{"sentry-go", "sentry-python", "sentry-perl", "sentry-clojure", "sentry-electron"}
+	for _, repo := range repos {
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, repo, ".git"), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, repo, "README.md"), []byte(repo), 0o644))
+	}
+	// Stray files are not repositories and are left alone
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "notes.txt"), []byte("keep"), 0o644))
+
+	active := []string{
+		"https://github.com/getsentry/sentry-go",
+		"https://github.com/getsentry/sentry-python.git",
+		"https://github.com/getsentry/sentry-javascript", // Not cloned yet
+	}
+
+	removed, err := client.PruneInactiveRepos(context.Background(), active)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{
+		filepath.Join(workDir, "sentry-perl"),
+		filepath.Join(workDir, "sentry-clojure"),
+		filepath.Join(workDir, "sentry-electron"),
+	}, removed)
+
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	var remaining []string
+	for _, entry := range entries {
+		remaining = append(remaining, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"sentry-go", "sentry-python", "notes.txt"}, remaining)
+
+	// Nothing left to prune
+	removed, err = client.PruneInactiveRepos(context.Background(), active)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestPruneInactiveReposMissingWorkDir(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "repos"), zerolog.Nop())
+
+	removed, err := client.PruneInactiveRepos(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestPruneInactiveReposCancelled(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, "sentry-perl"), 0o755))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	removed, err := client.PruneInactiveRepos(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, removed)
+	assert.DirExists(t, filepath.Join(workDir, "sentry-perl"))
+}
+
+func TestCleanStaleRepos(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+
+	for _, repo := range []string{"sentry-go", "sentry-perl", "sentry-clojure"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(workDir, repo, ".git"), 0o755))
+	}
+
+	removed, err := client.CleanStaleRepos(context.Background(), []string{"git@github.com:getsentry/sentry-go.git"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"sentry-perl", "sentry-clojure"}, removed)
+
+	assert.DirExists(t, filepath.Join(workDir, "sentry-go"))
+	assert.NoDirExists(t, filepath.Join(workDir, "sentry-perl"))
+	assert.NoDirExists(t, filepath.Join(workDir, "sentry-clojure"))
+}
+
+func TestRemoveRepo(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "sentry-perl", ".git"), 0o755))
+
+	removed, err := client.RemoveRepo("https://github.com/getsentry/sentry-perl")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoDirExists(t, filepath.Join(workDir, "sentry-perl"))
+
+	removed, err = client.RemoveRepo("https://github.com/getsentry/sentry-perl")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestGetDiskUsage(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "sentry-go", ".git", "objects"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "sentry-go", "client.go"), make([]byte, 300), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "sentry-go", ".git", "objects", "pack"), make([]byte, 1000), 0o644))
+	require.NoError(t, os.Mkdir(filepath.Join(workDir, "sentry-perl"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "notes.txt"), make([]byte, 50), 0o644))
+
+	usage, err := client.GetDiskUsage()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"sentry-go": 1300, "sentry-perl": 0}, usage)
+}
+
+func TestGetDiskUsageMissingWorkDir(t *testing.T) {
+	client := NewClient(filepath.Join(t.TempDir(), "repos"), zerolog.Nop())
+
+	usage, err := client.GetDiskUsage()
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+}
@@ -0,0 +1,102 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// RetryBaseDelay is the backoff before the first retry of a pull; each
+// further retry doubles it. Exported for testing.
+var RetryBaseDelay = time.Second
+
+// maxRetryDelay caps the backoff between pull attempts.
+const maxRetryDelay = 60 * time.Second
+
+// jitter returns a random number in [0, n); replaced in tests.
+var jitter = rand.Int64N
+
+// PullWithRetry is like Pull, but retries network errors up to maxAttempts
+// attempts in total, backing off exponentially with full jitter between
+// them. It gives up early, returning the last error, when ctx's deadline
+// would pass before the next attempt.
+func (g *Client) PullWithRetry(ctx context.Context, repoPath string, maxAttempts int) error {
+	for attempt := 1; ; attempt++ {
+		err := g.Pull(ctx, repoPath)
+		if err == nil || !isNetworkError(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			if attempt == 1 {
+				return err
+			}
+			return fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+
+		delay := retryDelay(attempt - 1)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return fmt.Errorf("not enough time left to retry: %w", err)
+		}
+
+		g.logger.Warn().
+			Err(err).
+			Str("path", repoPath).
+			Int("attempt", attempt).
+			Dur("delay", delay).
+			Msg("Retrying pull")
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// retryDelay returns a random delay of up to RetryBaseDelay * 2^retry,
+// capped at maxRetryDelay, where retry counts from zero.
+func retryDelay(retry int) time.Duration {
+	ceiling := maxRetryDelay
+	if retry < 32 {
+		ceiling = min(RetryBaseDelay<<retry, maxRetryDelay)
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(jitter(int64(ceiling)))
+}
+
+// isNetworkError reports whether err is a transient transport failure
+// worth retrying: a dropped or refused connection, or a 429 or 5xx
+// response from an HTTP remote.
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	// go-git wraps unexpected HTTP responses without Unwrap
+	var unexpected *plumbing.UnexpectedError
+	if errors.As(err, &unexpected) {
+		var httpErr *githttp.Err
+		if errors.As(unexpected.Err, &httpErr) {
+			status := httpErr.Response.StatusCode
+			return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+		}
+		err = unexpected.Err
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
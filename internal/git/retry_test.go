@@ -0,0 +1,182 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"net/url"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyGitServer serves the repositories under root over smart HTTP with
+// git http-backend.
+type flakyGitServer struct {
+	*httptest.Server
+
+	// failures is how many of the next attempts fail with 503
+	failures atomic.Int64
+	// attempts counts pulls and clones, each of which starts by fetching
+	// the ref advertisement
+	attempts atomic.Int64
+}
+
+// gitHTTPBackend returns a handler serving the repositories under root
+// over smart HTTP, skipping the test when git is not installed.
+func gitHTTPBackend(t *testing.T, root string) http.Handler {
+	t.Helper()
+
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+	return &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + root, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+}
+
+func newFlakyGitServer(t *testing.T, root string) *flakyGitServer {
+	t.Helper()
+
+	backend := gitHTTPBackend(t, root)
+	s := &flakyGitServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/info/refs") {
+			s.attempts.Add(1)
+			if s.failures.Add(-1) >= 0 {
+				http.Error(w, "try again later", http.StatusServiceUnavailable)
+				return
+			}
+		}
+		backend.ServeHTTP(w, r)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// cloneFromFlakyServer commits to a new repository served by a flaky git
+// server and clones it, returning the source path, the server, and the
+// client and path of the clone.
+func cloneFromFlakyServer(t *testing.T) (string, *flakyGitServer, *Client, string) {
+	t.Helper()
+
+	root := t.TempDir()
+	sourcePath := filepath.Join(root, "source-repo")
+	source, err := git.PlainInit(sourcePath, false)
+	require.NoError(t, err)
+	commitFiles(t, source, sourcePath, "initial", 1)
+
+	server := newFlakyGitServer(t, root)
+	repoURL := server.URL + "/source-repo"
+
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	require.NoError(t, client.Clone(context.Background(), repoURL, ""))
+	server.attempts.Store(0)
+
+	return sourcePath, server, client, client.GetRepoPath(repoURL)
+}
+
+func TestPullWithRetry(t *testing.T) {
+	defer func(delay time.Duration) { RetryBaseDelay = delay }(RetryBaseDelay)
+	RetryBaseDelay = time.Millisecond
+
+	sourcePath, server, client, repoPath := cloneFromFlakyServer(t)
+
+	source, err := git.PlainOpen(sourcePath)
+	require.NoError(t, err)
+	commitFiles(t, source, sourcePath, "update", 1)
+
+	// The first two attempts fail, the third pulls the new commit
+	server.failures.Store(2)
+	require.NoError(t, client.PullWithRetry(context.Background(), repoPath, 5))
+	assert.Equal(t, int64(3), server.attempts.Load())
+	assert.FileExists(t, filepath.Join(repoPath, "update-0.txt"))
+}
+
+func TestPullWithRetryGivesUp(t *testing.T) {
+	defer func(delay time.Duration) { RetryBaseDelay = delay }(RetryBaseDelay)
+	RetryBaseDelay = time.Millisecond
+
+	_, server, client, repoPath := cloneFromFlakyServer(t)
+
+	server.failures.Store(10)
+	err := client.PullWithRetry(context.Background(), repoPath, 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 3 attempts")
+	assert.Equal(t, int64(3), server.attempts.Load())
+}
+
+func TestPullWithRetryNotEnoughTime(t *testing.T) {
+	defer func(delay time.Duration) { RetryBaseDelay = delay }(RetryBaseDelay)
+	defer func(random func(int64) int64) { jitter = random }(jitter)
+	RetryBaseDelay = time.Hour
+	jitter = func(n int64) int64 { return n - 1 }
+
+	_, server, client, repoPath := cloneFromFlakyServer(t)
+
+	// The delay is just under the one minute cap, but less than that is left
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	server.failures.Store(10)
+	err := client.PullWithRetry(ctx, repoPath, 3)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not enough time left to retry")
+	assert.Equal(t, int64(1), server.attempts.Load())
+}
+
+func TestRetryDelay(t *testing.T) {
+	defer func(delay time.Duration) { RetryBaseDelay = delay }(RetryBaseDelay)
+	RetryBaseDelay = time.Second
+
+	for retry := 0; retry < 100; retry++ {
+		ceiling := min(time.Second<<min(retry, 31), maxRetryDelay)
+		delay := retryDelay(retry)
+		assert.GreaterOrEqual(t, delay, time.Duration(0))
+		assert.Less(t, delay, ceiling, "retry %d", retry)
+	}
+}
+
+func TestIsNetworkError(t *testing.T) {
+	httpStatus := func(status int) error {
+		response := &http.Response{StatusCode: status, Request: &http.Request{URL: &url.URL{}}}
+		return fmt.Errorf("failed to pull changes: %w", plumbing.NewUnexpectedError(&githttp.Err{Response: response}))
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", fmt.Errorf("failed to pull changes: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{"service unavailable", httpStatus(http.StatusServiceUnavailable), true},
+		{"rate limited", httpStatus(http.StatusTooManyRequests), true},
+		{"bad request", httpStatus(http.StatusBadRequest), false},
+		{"repository not found", fmt.Errorf("failed to pull changes: %w", transport.ErrRepositoryNotFound), false},
+		{"not a repository", fmt.Errorf("failed to open repository: %w", git.ErrRepositoryNotExists), false},
+		{"cancelled", context.Canceled, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isNetworkError(tt.err))
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createHistoryRepo creates a repository whose history has commits commits,
+// each changing its own file, and returns its file:// URL.
+func createHistoryRepo(tb testing.TB, commits int) string {
+	tb.Helper()
+
+	repoPath := filepath.Join(tb.TempDir(), "history.git")
+	repo, err := git.PlainInit(repoPath, false)
+	require.NoError(tb, err)
+	w, err := repo.Worktree()
+	require.NoError(tb, err)
+
+	start := time.Now().Add(-time.Duration(commits) * time.Hour)
+	for i := 0; i < commits; i++ {
+		name := fmt.Sprintf("file%03d.go", i)
+		content := fmt.Sprintf("package history\n\nconst Version%d = %d\n", i, i)
+		require.NoError(tb, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+		_, err = w.Add(name)
+		require.NoError(tb, err)
+		_, err = w.Commit(fmt.Sprintf("Add %s", name), &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  "Test",
+				Email: "test@example.com",
+				When:  start.Add(time.Duration(i) * time.Hour),
+			},
+		})
+		require.NoError(tb, err)
+	}
+
+	return "file://" + repoPath
+}
+
+// countObjects returns the number of git objects stored in a repository.
+func countObjects(tb testing.TB, repoPath string) int {
+	tb.Helper()
+
+	repo, err := git.PlainOpen(repoPath)
+	require.NoError(tb, err)
+	iter, err := repo.Storer.IterEncodedObjects(plumbing.AnyObject)
+	require.NoError(tb, err)
+
+	count := 0
+	err = iter.ForEach(func(plumbing.EncodedObject) error {
+		count++
+		return nil
+	})
+	if err != nil && err != storer.ErrStop {
+		require.NoError(tb, err)
+	}
+	return count
+}
+
+func TestShallowClone(t *testing.T) {
+	url := createHistoryRepo(t, 20)
+	ctx := context.Background()
+
+	full := NewClient(t.TempDir(), zerolog.Nop())
+	require.NoError(t, full.Clone(ctx, url, "master"))
+
+	shallow := NewClient(t.TempDir(), zerolog.Nop())
+	shallow.Depth = 1
+	require.NoError(t, shallow.Clone(ctx, url, "master"))
+
+	fullPath := full.GetRepoPath(url)
+	shallowPath := shallow.GetRepoPath(url)
+	assert.Less(t, countObjects(t, shallowPath), countObjects(t, fullPath))
+
+	// The shallow clone still has the full worktree and latest commit
+	assert.FileExists(t, filepath.Join(shallowPath, "file000.go"))
+	latest, err := shallow.GetLatestCommit(ctx, shallowPath)
+	require.NoError(t, err)
+	expected, err := full.GetLatestCommit(ctx, fullPath)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Hash, latest.Hash)
+	assert.Equal(t, "Add file019.go", latest.Message)
+}
+
+func TestFetch(t *testing.T) {
+	url := createHistoryRepo(t, 3)
+	ctx := context.Background()
+
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	require.NoError(t, client.Clone(ctx, url, "master"))
+	repoPath := client.GetRepoPath(url)
+
+	// Commit upstream, then fetch it into the full-depth clone
+	sourcePath := url[len("file://"):]
+	source, err := git.PlainOpen(sourcePath)
+	require.NoError(t, err)
+	w, err := source.Worktree()
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(sourcePath, "new.go"), []byte("package history\n"), 0644))
+	_, err = w.Add("new.go")
+	require.NoError(t, err)
+	upstream, err := w.Commit("Add new.go", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	client.Depth = 1
+	require.NoError(t, client.Fetch(ctx, repoPath))
+
+	latest, err := client.GetLatestCommit(ctx, repoPath)
+	require.NoError(t, err)
+	assert.Equal(t, upstream.String(), latest.Hash)
+	assert.FileExists(t, filepath.Join(repoPath, "new.go"))
+}
+
+func BenchmarkClone(b *testing.B) {
+	url := createHistoryRepo(b, 50)
+
+	for _, depth := range []int{0, 1} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				client := NewClient(b.TempDir(), zerolog.Nop())
+				client.Depth = depth
+				if err := client.Clone(context.Background(), url, "master"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
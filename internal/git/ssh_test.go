@@ -0,0 +1,267 @@
+package git
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshGitServer serves git-upload-pack over SSH to clients holding
+// authorizedKey, from repositories on the local filesystem.
+type sshGitServer struct {
+	listener      net.Listener
+	config        *ssh.ServerConfig
+	hostKey       ssh.PublicKey
+	authorizedKey ssh.PublicKey
+}
+
+func newSSHGitServer(t *testing.T, authorizedKey ssh.PublicKey) *sshGitServer {
+	t.Helper()
+
+	_, hostPriv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostSigner, err := ssh.NewSignerFromKey(hostPriv)
+	require.NoError(t, err)
+
+	s := &sshGitServer{hostKey: hostSigner.PublicKey(), authorizedKey: authorizedKey}
+	s.config = &ssh.ServerConfig{
+		PublicKeyCallback: func(conn ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(s.authorizedKey.Marshal()) {
+				return nil, assert.AnError
+			}
+			return nil, nil
+		},
+	}
+	s.config.AddHostKey(hostSigner)
+
+	s.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = s.listener.Close()
+	})
+
+	go s.serve()
+	return s
+}
+
+func (s *sshGitServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handleConn(conn)
+	}
+}
+
+func (s *sshGitServer) handleConn(conn net.Conn) {
+	_, channels, requests, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "session channels only")
+			continue
+		}
+		channel, channelRequests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.handleSession(channel, channelRequests)
+	}
+}
+
+// handleSession runs the session's exec request, which must be
+// git-upload-pack '<path>'.
+func (s *sshGitServer) handleSession(channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer func() {
+		_ = channel.Close()
+	}()
+
+	for req := range requests {
+		if req.Type != "exec" {
+			_ = req.Reply(false, nil)
+			continue
+		}
+
+		var payload struct{ Command string }
+		if err := ssh.Unmarshal(req.Payload, &payload); err != nil {
+			_ = req.Reply(false, nil)
+			return
+		}
+		path, ok := strings.CutPrefix(payload.Command, "git-upload-pack ")
+		if !ok {
+			_ = req.Reply(false, nil)
+			return
+		}
+		_ = req.Reply(true, nil)
+
+		status := uint32(0)
+		if err := uploadPack(channel, strings.Trim(path, "'")); err != nil {
+			status = 1
+		}
+		_, _ = channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{status}))
+		return
+	}
+}
+
+func uploadPack(channel ssh.Channel, path string) error {
+	// The filesystem loader expects the git directory itself
+	if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+		path = filepath.Join(path, ".git")
+	}
+	endpoint, err := transport.NewEndpoint(path)
+	if err != nil {
+		return err
+	}
+	session, err := server.DefaultServer.NewUploadPackSession(endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	refs, err := session.AdvertisedReferences()
+	if err != nil {
+		return err
+	}
+	if err := refs.Encode(channel); err != nil {
+		return err
+	}
+
+	// Clients that are up to date hang up after the references
+	req := packp.NewUploadPackRequest()
+	if err := req.Decode(channel); err != nil {
+		return nil
+	}
+	resp, err := session.UploadPack(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	return resp.Encode(channel)
+}
+
+// url returns the ssh:// URL of the repository at path.
+func (s *sshGitServer) url(path string) string {
+	return "ssh://git@" + s.listener.Addr().String() + path
+}
+
+// writeKnownHosts writes a known_hosts file trusting the server's host key
+// and points SSH_KNOWN_HOSTS at it.
+func (s *sshGitServer) writeKnownHosts(t *testing.T) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{knownhosts.Normalize(s.listener.Addr().String())}, s.hostKey)
+	require.NoError(t, os.WriteFile(path, []byte(line+"\n"), 0600))
+	t.Setenv("SSH_KNOWN_HOSTS", path)
+}
+
+// writeSSHKey writes a private key, encrypted when passphrase is set, and
+// returns its path and public key.
+func writeSSHKey(t *testing.T, passphrase string) (string, ssh.PublicKey) {
+	t.Helper()
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var block *pem.Block
+	if passphrase == "" {
+		block, err = ssh.MarshalPrivateKey(priv, "")
+	} else {
+		block, err = ssh.MarshalPrivateKeyWithPassphrase(priv, "", []byte(passphrase))
+	}
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "id_ed25519")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0600))
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	require.NoError(t, err)
+	return path, sshPub
+}
+
+func TestSSHAuth(t *testing.T) {
+	sourceURL := createHistoryRepo(t, 3)
+	sourcePath := strings.TrimPrefix(sourceURL, "file://")
+
+	keyPath, publicKey := writeSSHKey(t, "secret")
+	server := newSSHGitServer(t, publicKey)
+	server.writeKnownHosts(t)
+	repoURL := server.url(sourcePath)
+	ctx := context.Background()
+
+	t.Run("authorized key", func(t *testing.T) {
+		client := NewClient(t.TempDir(), zerolog.Nop())
+		client.SSHKeyPath = keyPath
+		client.SSHKeyPassphrase = "secret"
+
+		require.NoError(t, client.Clone(ctx, repoURL, "master"))
+		repoPath := client.GetRepoPath(repoURL)
+		assert.FileExists(t, filepath.Join(repoPath, "file002.go"))
+
+		// Pull authenticates with the same key
+		require.NoError(t, client.Pull(ctx, repoPath))
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		client := NewClient(t.TempDir(), zerolog.Nop())
+		client.SSHKeyPath = keyPath
+		client.SSHKeyPassphrase = "wrong"
+
+		err := client.Clone(ctx, repoURL, "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to load SSH key")
+	})
+
+	t.Run("unauthorized key", func(t *testing.T) {
+		otherKeyPath, _ := writeSSHKey(t, "")
+		client := NewClient(t.TempDir(), zerolog.Nop())
+		client.SSHKeyPath = otherKeyPath
+
+		err := client.Clone(ctx, repoURL, "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unable to authenticate")
+	})
+
+	t.Run("unknown host", func(t *testing.T) {
+		t.Setenv("SSH_KNOWN_HOSTS", filepath.Join(t.TempDir(), "empty_known_hosts"))
+		require.NoError(t, os.WriteFile(os.Getenv("SSH_KNOWN_HOSTS"), nil, 0600))
+
+		client := NewClient(t.TempDir(), zerolog.Nop())
+		client.SSHKeyPath = keyPath
+		client.SSHKeyPassphrase = "secret"
+
+		err := client.Clone(ctx, repoURL, "master")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "key is unknown")
+	})
+}
+
+func TestAuthIgnoresHTTPS(t *testing.T) {
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	client.SSHKeyPath = "/nonexistent/key"
+
+	auth, err := client.auth("https://github.com/getsentry/sentry-go")
+	require.NoError(t, err)
+	assert.Nil(t, auth)
+
+	_, err = client.auth("git@github.com:getsentry/sentry-go.git")
+	require.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tagHistory tags the commits of a repository created by createHistoryRepo,
+// oldest first, with tags; empty names leave a commit untagged. Tags whose
+// names start with "annotated/" are annotated and named without the prefix.
+func tagHistory(tb testing.TB, url string, tags ...string) {
+	tb.Helper()
+
+	repo, err := git.PlainOpen(url[len("file://"):])
+	require.NoError(tb, err)
+	iter, err := repo.Log(&git.LogOptions{Order: git.LogOrderCommitterTime})
+	require.NoError(tb, err)
+	var hashes []plumbing.Hash
+	require.NoError(tb, iter.ForEach(func(c *object.Commit) error {
+		hashes = append([]plumbing.Hash{c.Hash}, hashes...)
+		return nil
+	}))
+	require.GreaterOrEqual(tb, len(hashes), len(tags))
+
+	for i, name := range tags {
+		if name == "" {
+			continue
+		}
+		var opts *git.CreateTagOptions
+		if annotated, ok := strings.CutPrefix(name, "annotated/"); ok {
+			name = annotated
+			opts = &git.CreateTagOptions{
+				Message: "Release " + name,
+				Tagger:  &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+			}
+		}
+		_, err := repo.CreateTag(name, hashes[i], opts)
+		require.NoError(tb, err)
+	}
+}
+
+func TestGetLatestTag(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		tags []string
+		want string
+	}{
+		{
+			name: "sorted by semantic version",
+			tags: []string{"v0.9.0", "v1.2.0", "v1.10.0", "v1.9.0"},
+			want: "v1.10.0",
+		},
+		{
+			name: "with and without v prefix",
+			tags: []string{"1.2.0", "annotated/2.0.1", "v2.0.0", "2.0.0-beta.1"},
+			want: "2.0.1",
+		},
+		{
+			name: "other tags ignored",
+			tags: []string{"v1.0.0", "nightly", "annotated/release-2024", "@sentry/browser@9.0.0"},
+			want: "v1.0.0",
+		},
+		{
+			name: "prerelease of the next version",
+			tags: []string{"v1.0.0", "v1.1.0-rc.1"},
+			want: "v1.1.0-rc.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := createHistoryRepo(t, len(tt.tags))
+			tagHistory(t, url, tt.tags...)
+
+			client := NewClient(t.TempDir(), zerolog.Nop())
+			require.NoError(t, client.Clone(ctx, url, "master"))
+
+			tag, err := client.GetLatestTag(ctx, client.GetRepoPath(url))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, tag)
+		})
+	}
+}
+
+func TestGetLatestTagWithoutTags(t *testing.T) {
+	ctx := context.Background()
+	url := createHistoryRepo(t, 2)
+	tagHistory(t, url, "", "nightly")
+
+	client := NewClient(t.TempDir(), zerolog.Nop())
+	require.NoError(t, client.Clone(ctx, url, "master"))
+
+	_, err := client.GetLatestTag(ctx, client.GetRepoPath(url))
+	assert.ErrorIs(t, err, ErrNoTags)
+}
@@ -0,0 +1,38 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrWorkDirMissing is returned by CheckWorkDir until the first repository
+// has been cloned, which creates the work directory.
+var ErrWorkDirMissing = errors.New("work directory does not exist")
+
+// CheckWorkDir verifies that the work directory exists and that files can
+// be created in it, by creating and removing an empty one.
+func (g *Client) CheckWorkDir() error {
+	info, err := os.Stat(g.workDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrWorkDirMissing, g.workDir)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat work directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("work directory %s is not a directory", g.workDir)
+	}
+
+	probe, err := os.CreateTemp(g.workDir, ".health-*")
+	if err != nil {
+		return fmt.Errorf("work directory is not writable: %w", err)
+	}
+	if err := probe.Close(); err != nil {
+		g.logger.Error().Err(err).Str("path", probe.Name()).Msg("Failed to close work directory probe")
+	}
+	if err := os.Remove(probe.Name()); err != nil {
+		return fmt.Errorf("failed to remove work directory probe: %w", err)
+	}
+	return nil
+}
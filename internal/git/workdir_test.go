@@ -0,0 +1,31 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckWorkDir(t *testing.T) {
+	workDir := t.TempDir()
+	client := NewClient(workDir, zerolog.Nop())
+	require.NoError(t, client.CheckWorkDir())
+
+	// The probe file is removed
+	entries, err := os.ReadDir(workDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	missing := NewClient(filepath.Join(workDir, "repos"), zerolog.Nop())
+	assert.ErrorIs(t, missing.CheckWorkDir(), ErrWorkDirMissing)
+
+	file := filepath.Join(workDir, "file")
+	require.NoError(t, os.WriteFile(file, nil, 0644))
+	err = NewClient(file, zerolog.Nop()).CheckWorkDir()
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrWorkDirMissing)
+}
@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+// emailTimeout bounds each SMTP exchange
+const emailTimeout = 30 * time.Second
+
+// EmailNotifier mails each event as plain text through an SMTP server.
+type EmailNotifier struct {
+	addr string
+	from string
+	to   []string
+
+	// send delivers the message; replaced in tests
+	send func(ctx context.Context, addr, from string, to []string, msg []byte) error
+}
+
+// NewEmailNotifier creates a notifier mailing from one address to the to
+// addresses through the SMTP server at host:port.
+func NewEmailNotifier(host string, port int, from string, to []string) *EmailNotifier {
+	return &EmailNotifier{
+		addr: net.JoinHostPort(host, strconv.Itoa(port)),
+		from: from,
+		to:   to,
+		send: sendMail,
+	}
+}
+
+// Notify implements Notifier. The SMTP exchange must end within
+// emailTimeout and by the deadline of ctx, if it has one.
+func (n *EmailNotifier) Notify(ctx context.Context, event AnalysisEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := n.send(ctx, n.addr, n.from, n.to, n.message(event)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// sendMail delivers msg like smtp.SendMail, without authentication, but
+// over a connection that is closed once the exchange outlasts emailTimeout
+// or the deadline of ctx.
+func sendMail(ctx context.Context, addr, from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, emailTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return fmt.Errorf("failed to set SMTP deadline: %w", errors.Join(err, conn.Close()))
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return errors.Join(err, conn.Close())
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return errors.Join(err, conn.Close())
+	}
+	if err := deliver(c, host, from, to, msg); err != nil {
+		return errors.Join(err, c.Close())
+	}
+	return nil
+}
+
+// deliver sends msg over c and quits, which closes the connection.
+func deliver(c *smtp.Client, host, from string, to []string, msg []byte) error {
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return errors.Join(err, w.Close())
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message formats event as an RFC 5322 message.
+func (n *EmailNotifier) message(event AnalysisEvent) []byte {
+	subject := fmt.Sprintf("SDK analysis updated: %s", event.SDK)
+	switch event.Type {
+	case EventTest:
+		subject = "Test notification from claude-cache-service"
+	case EventSDKDisabled:
+		subject = fmt.Sprintf("SDK disabled after failed analyses: %s", event.SDK)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", n.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(emailBody(event), "\n", "\r\n"))
+	return []byte(b.String())
+}
+
+// emailBody summarizes event and what changed in the analysis.
+func emailBody(event AnalysisEvent) string {
+	switch event.Type {
+	case EventTest:
+		return "Notifications from claude-cache-service are set up correctly.\n"
+	case EventSDKDisabled:
+		return fmt.Sprintf("%s was disabled after its analysis failed %d times. The last failure was:\n\n%s\n",
+			event.SDK, event.Attempts, event.Error)
+	}
+
+	var b strings.Builder
+	kind := "analyzed"
+	if event.Incremental {
+		kind = "incrementally analyzed"
+	}
+	fmt.Fprintf(&b, "%s was %s on %s using %d tokens (analysis version %s).\n",
+		event.SDK, kind, event.AnalyzedAt.Format(time.RFC3339), event.TokensUsed, event.AnalysisVersion)
+
+	if event.Diff == nil {
+		b.WriteString("\nThis is the first analysis of the SDK.\n")
+		return b.String()
+	}
+	writeDiff(&b, event.Diff)
+	return b.String()
+}
+
+// writeDiff lists the changed fields and list items of diff.
+func writeDiff(b *strings.Builder, diff *analyzer.AnalysisDiff) {
+	if len(diff.Changed) == 0 && len(diff.Lists) == 0 {
+		b.WriteString("\nNothing changed since the previous analysis.\n")
+		return
+	}
+
+	for _, change := range diff.Changed {
+		fmt.Fprintf(b, "\n%s: %q -> %q", change.Field, change.Old, change.New)
+	}
+	if len(diff.Changed) > 0 {
+		b.WriteString("\n")
+	}
+
+	fields := make([]string, 0, len(diff.Lists))
+	for field := range diff.Lists {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+	for _, field := range fields {
+		fmt.Fprintf(b, "\n%s:\n", field)
+		for _, item := range diff.Lists[field].Added {
+			fmt.Fprintf(b, "  + %s\n", item)
+		}
+		for _, item := range diff.Lists[field].Removed {
+			fmt.Fprintf(b, "  - %s\n", item)
+		}
+	}
+
+	fmt.Fprintf(b, "\nFeature similarity to the previous analysis: %.2f\n", diff.SimilarityScore)
+}
@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+// sentMail records a message passed to EmailNotifier.send.
+type sentMail struct {
+	addr string
+	from string
+	to   []string
+	msg  string
+}
+
+func recordingEmailNotifier(sent *[]sentMail) *EmailNotifier {
+	n := NewEmailNotifier("smtp.example.com", 587, "cache@example.com", []string{"sdk-team@example.com", "oncall@example.com"})
+	n.send = func(ctx context.Context, addr, from string, to []string, msg []byte) error {
+		*sent = append(*sent, sentMail{addr: addr, from: from, to: to, msg: string(msg)})
+		return nil
+	}
+	return n
+}
+
+func TestEmailNotifier(t *testing.T) {
+	var sent []sentMail
+	notifier := recordingEmailNotifier(&sent)
+
+	previous := &analyzer.SDKAnalysis{ProtocolVersion: "7", Features: []string{"breadcrumbs", "profiling"}}
+	current := &analyzer.SDKAnalysis{ProtocolVersion: "8", Features: []string{"breadcrumbs", "sessions"}}
+	diff := analyzer.DiffAnalyses(previous, current)
+
+	err := notifier.Notify(context.Background(), AnalysisEvent{
+		Type:       EventAnalysisUpdated,
+		SDK:        "sentry-go",
+		AnalyzedAt: time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC),
+		TokensUsed: 1200,
+		Diff:       &diff,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sent, 1)
+	mail := sent[0]
+	assert.Equal(t, "smtp.example.com:587", mail.addr)
+	assert.Equal(t, "cache@example.com", mail.from)
+	assert.Equal(t, []string{"sdk-team@example.com", "oncall@example.com"}, mail.to)
+
+	header, body, ok := strings.Cut(mail.msg, "\r\n\r\n")
+	require.True(t, ok, "message has a header and body")
+	assert.Contains(t, header, "To: sdk-team@example.com, oncall@example.com\r\n")
+	assert.Contains(t, header, "Subject: SDK analysis updated: sentry-go\r\n")
+	assert.Contains(t, body, "sentry-go was analyzed on 2025-03-01T02:00:00Z using 1200 tokens")
+	assert.Contains(t, body, `protocol_version: "7" -> "8"`)
+	assert.Contains(t, body, "features:\r\n  + sessions\r\n  - profiling\r\n")
+	assert.NotContains(t, strings.ReplaceAll(mail.msg, "\r\n", ""), "\n", "lines end in CRLF")
+}
+
+func TestEmailBody(t *testing.T) {
+	first := emailBody(AnalysisEvent{Type: EventAnalysisUpdated, SDK: "sentry-go", Incremental: true})
+	assert.Contains(t, first, "sentry-go was incrementally analyzed")
+	assert.Contains(t, first, "This is the first analysis of the SDK")
+
+	unchanged := analyzer.DiffAnalyses(&analyzer.SDKAnalysis{}, &analyzer.SDKAnalysis{})
+	assert.Contains(t, emailBody(AnalysisEvent{SDK: "sentry-go", Diff: &unchanged}), "Nothing changed since the previous analysis")
+
+	assert.Contains(t, emailBody(TestEvent()), "set up correctly")
+
+	disabled := emailBody(AnalysisEvent{Type: EventSDKDisabled, SDK: "sentry-go", Attempts: 3, Error: "clone failed"})
+	assert.Contains(t, disabled, "sentry-go was disabled after its analysis failed 3 times")
+	assert.Contains(t, disabled, "clone failed")
+}
+
+func TestEmailNotifierErrors(t *testing.T) {
+	var sent []sentMail
+	notifier := recordingEmailNotifier(&sent)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, notifier.Notify(ctx, TestEvent()), context.Canceled)
+	assert.Empty(t, sent)
+
+	notifier.send = func(context.Context, string, string, []string, []byte) error {
+		return errors.New("554 relay denied")
+	}
+	err := notifier.Notify(context.Background(), TestEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send email: 554 relay denied")
+}
+
+func TestEmailNotifierHungServer(t *testing.T) {
+	// The server accepts connections but never sends its greeting
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, listener.Close())
+	}()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	addr := listener.Addr().(*net.TCPAddr)
+	notifier := NewEmailNotifier("127.0.0.1", addr.Port, "cache@example.com", []string{"oncall@example.com"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = notifier.Notify(ctx, TestEvent())
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.NoError(t, (<-accepted).Close())
+}
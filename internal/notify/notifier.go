@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// Event types
+const (
+	// EventAnalysisUpdated is sent when an SDK with new commits has been
+	// analyzed again
+	EventAnalysisUpdated = "analysis_updated"
+
+	// EventTest is sent by POST /api/v1/notify/test
+	EventTest = "test"
+
+	// EventSDKDisabled is sent when an SDK is disabled after its analysis
+	// failed repeatedly
+	EventSDKDisabled = "sdk_disabled"
+)
+
+// AnalysisEvent describes a new analysis of an SDK.
+type AnalysisEvent struct {
+	Type            string    `json:"type"`
+	SDK             string    `json:"sdk"`
+	AnalysisVersion string    `json:"analysis_version"`
+	AnalyzedAt      time.Time `json:"analyzed_at"`
+	Incremental     bool      `json:"incremental"`
+	TokensUsed      int       `json:"tokens_used"`
+
+	// Diff is what changed since the previous analysis; nil for the first
+	// analysis of an SDK
+	Diff *analyzer.AnalysisDiff `json:"diff,omitempty"`
+
+	// Error and Attempts describe the failures that disabled an SDK
+	Error    string `json:"error,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+// TestEvent returns the event sent to check that notifications arrive.
+func TestEvent() AnalysisEvent {
+	return AnalysisEvent{
+		Type:       EventTest,
+		SDK:        "test",
+		AnalyzedAt: time.Now().UTC(),
+	}
+}
+
+// Notifier delivers analysis events.
+type Notifier interface {
+	Notify(ctx context.Context, event AnalysisEvent) error
+}
+
+// Multi sends each event to every notifier in turn.
+type Multi []Notifier
+
+// Notify implements Notifier, returning the errors of all notifiers that
+// failed.
+func (m Multi) Notify(ctx context.Context, event AnalysisEvent) error {
+	var errs []error
+	for _, notifier := range m {
+		if err := notifier.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New returns a notifier for the webhook and email settings in cfg, or nil
+// when neither is configured.
+func New(cfg *config.Config, logger zerolog.Logger) Notifier {
+	var notifiers Multi
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhookNotifier(cfg.WebhookURL, logger))
+	}
+	if cfg.SMTPHost != "" {
+		notifiers = append(notifiers, NewEmailNotifier(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPFrom, cfg.SMTPTo))
+	}
+
+	switch len(notifiers) {
+	case 0:
+		return nil
+	case 1:
+		return notifiers[0]
+	}
+	return notifiers
+}
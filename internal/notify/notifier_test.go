@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// funcNotifier adapts a function to Notifier.
+type funcNotifier func(ctx context.Context, event AnalysisEvent) error
+
+func (f funcNotifier) Notify(ctx context.Context, event AnalysisEvent) error {
+	return f(ctx, event)
+}
+
+func TestNew(t *testing.T) {
+	assert.Nil(t, New(&config.Config{}, zerolog.Nop()))
+
+	webhook := New(&config.Config{WebhookURL: "https://hooks.example.com"}, zerolog.Nop())
+	assert.IsType(t, &WebhookNotifier{}, webhook)
+
+	email := New(&config.Config{SMTPHost: "smtp.example.com", SMTPPort: 25, SMTPFrom: "a@example.com", SMTPTo: []string{"b@example.com"}}, zerolog.Nop())
+	require.IsType(t, &EmailNotifier{}, email)
+	assert.Equal(t, "smtp.example.com:25", email.(*EmailNotifier).addr)
+
+	both := New(&config.Config{WebhookURL: "https://hooks.example.com", SMTPHost: "smtp.example.com"}, zerolog.Nop())
+	require.IsType(t, Multi{}, both)
+	assert.Len(t, both, 2)
+}
+
+func TestMulti(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) Notifier {
+		return funcNotifier(func(ctx context.Context, event AnalysisEvent) error {
+			calls = append(calls, name+":"+event.SDK)
+			return err
+		})
+	}
+
+	failure := errors.New("webhook down")
+	multi := Multi{record("webhook", failure), record("email", nil)}
+
+	err := multi.Notify(context.Background(), AnalysisEvent{SDK: "sentry-go"})
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, []string{"webhook:sentry-go", "email:sentry-go"}, calls, "a failure does not stop later notifiers")
+}
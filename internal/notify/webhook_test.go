@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received *http.Request
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	analyzedAt := time.Date(2025, 3, 1, 2, 0, 0, 0, time.UTC)
+	previous := &analyzer.SDKAnalysis{ProtocolVersion: "7", Features: []string{"breadcrumbs"}}
+	current := &analyzer.SDKAnalysis{ProtocolVersion: "8", Features: []string{"breadcrumbs", "sessions"}}
+	diff := analyzer.DiffAnalyses(previous, current)
+
+	notifier := NewWebhookNotifier(server.URL+"/hooks/sdk", zerolog.Nop())
+	err := notifier.Notify(context.Background(), AnalysisEvent{
+		Type:            EventAnalysisUpdated,
+		SDK:             "sentry-go",
+		AnalysisVersion: "1.0.0",
+		AnalyzedAt:      analyzedAt,
+		Incremental:     true,
+		TokensUsed:      1200,
+		Diff:            &diff,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, received)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "/hooks/sdk", received.URL.Path)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+
+	assert.Equal(t, "analysis_updated", payload["type"])
+	assert.Equal(t, "sentry-go", payload["sdk"])
+	assert.Equal(t, "1.0.0", payload["analysis_version"])
+	assert.Equal(t, "2025-03-01T02:00:00Z", payload["analyzed_at"])
+	assert.Equal(t, true, payload["incremental"])
+	assert.Equal(t, float64(1200), payload["tokens_used"])
+
+	require.IsType(t, map[string]any{}, payload["diff"])
+	payloadDiff := payload["diff"].(map[string]any)
+	assert.Equal(t, []any{map[string]any{"field": "protocol_version", "old": "7", "new": "8"}}, payloadDiff["changed"])
+	assert.Equal(t, map[string]any{
+		"features": map[string]any{"added": []any{"sessions"}, "removed": []any{}},
+	}, payloadDiff["lists"])
+	assert.Contains(t, payloadDiff, "similarity_score")
+}
+
+func TestWebhookNotifierFirstAnalysis(t *testing.T) {
+	var payload map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, zerolog.Nop())
+	require.NoError(t, notifier.Notify(context.Background(), AnalysisEvent{Type: EventAnalysisUpdated, SDK: "sentry-go"}))
+	assert.NotContains(t, payload, "diff")
+}
+
+func TestWebhookNotifierErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "gone", http.StatusGone)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, zerolog.Nop())
+	err := notifier.Notify(context.Background(), TestEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "webhook returned status 410")
+
+	server.Close()
+	err = notifier.Notify(context.Background(), TestEvent())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send webhook")
+}
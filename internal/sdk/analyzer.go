@@ -1,27 +1,112 @@
 package sdk
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rs/zerolog"
 
 	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
 	"github.com/ryanrussell/claude-cache-service/internal/git"
 )
 
+// ErrAnalysisSkipped is reported for SDKs that were not started because the
+// caller asked the analysis run to stop.
+var ErrAnalysisSkipped = errors.New("analysis skipped: run is stopping")
+
+// ErrPrivateRepo is reported for private SDKs when no GitHub token is
+// configured to clone them.
+var ErrPrivateRepo = errors.New("private repository")
+
+const (
+	// defaultMultiPassThreshold is the file count above which an SDK is
+	// analyzed in several passes
+	defaultMultiPassThreshold = 50
+
+	// defaultMaxFilesPerPass is the number of files sent to Claude per pass
+	defaultMaxFilesPerPass = 50
+
+	// maxExtractedFiles bounds the files read from a repository, and with
+	// it the number of passes
+	maxExtractedFiles = 500
+
+	// defaultMaxFileSize is the size in bytes above which files are skipped
+	defaultMaxFileSize = 100 * 1024
+
+	// defaultMaxTotalTokens bounds the estimated tokens of the files
+	// extracted from a repository, across all passes
+	defaultMaxTotalTokens = 1_000_000
+
+	// defaultConcurrency is the number of SDKs analyzed at once
+	defaultConcurrency = 5
+
+	// defaultIncrementalThreshold is the number of changed files below
+	// which an SDK is analyzed incrementally
+	defaultIncrementalThreshold = 10
+)
+
+// gitOperations is the subset of git.Client used for analysis
+type gitOperations interface {
+	CheckCloneSpace(ctx context.Context, repoURL string, estimatedSize int64) error
+	Clone(ctx context.Context, repoURL, branch string) error
+	Pull(ctx context.Context, repoPath string) error
+	GetRepoPath(repoURL string) string
+	GetLatestCommit(ctx context.Context, repoPath string) (*git.Commit, error)
+	GetLatestTag(ctx context.Context, repoPath string) (string, error)
+	GetCommitsSince(ctx context.Context, repoPath string, since time.Time) ([]git.Commit, error)
+	GetChangedFiles(ctx context.Context, repoPath string, since time.Time) ([]string, error)
+	HasGitHubToken() bool
+}
+
 // Analyzer handles SDK analysis operations
 type Analyzer struct {
-	git     *git.Client
-	claude  analyzer.Analyzer
-	cache   *cache.Manager
-	logger  zerolog.Logger
+	git    gitOperations
+	claude analyzer.Analyzer
+	cache  *cache.Manager
+	logger zerolog.Logger
+
+	// configs, when set, replaces the SDKs of the default registry
 	configs *ConfigList
+
+	multiPassThreshold int
+	maxFilesPerPass    int
+	concurrency        int
+
+	// slots, when set, limits AnalyzeSDKs instead of concurrency
+	slots SlotPool
+
+	incrementalThreshold int
+}
+
+// SlotPool hands out analysis slots by SDK priority. Acquire blocks until a
+// slot is free or ctx is done and returns the function releasing the slot.
+type SlotPool interface {
+	Acquire(ctx context.Context, priority int) (func(), error)
+}
+
+// semaphorePool is the SlotPool AnalyzeSDKs uses when none is set. It
+// ignores priorities.
+type semaphorePool chan struct{}
+
+func (p semaphorePool) Acquire(ctx context.Context, priority int) (func(), error) {
+	select {
+	case p <- struct{}{}:
+		return func() { <-p }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 // NewAnalyzer creates a new SDK analyzer
@@ -30,202 +115,411 @@ func NewAnalyzer(gitClient *git.Client, claudeAnalyzer analyzer.Analyzer, cacheM
 	if err != nil {
 		return nil, fmt.Errorf("failed to load SDK configs: %w", err)
 	}
+	if errs := configs.Validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid SDK configs: %w", errors.Join(errs...))
+	}
 
 	return &Analyzer{
-		git:     gitClient,
-		claude:  claudeAnalyzer,
-		cache:   cacheManager,
-		logger:  logger,
-		configs: configs,
+		git:    gitClient,
+		claude: claudeAnalyzer,
+		cache:  cacheManager,
+		logger: logger,
+
+		multiPassThreshold: defaultMultiPassThreshold,
+		maxFilesPerPass:    defaultMaxFilesPerPass,
+		concurrency:        defaultConcurrency,
+
+		incrementalThreshold: defaultIncrementalThreshold,
 	}, nil
 }
 
+// SetConcurrency sets how many SDKs AnalyzeAllSDKs clones and analyzes at
+// once. Non-positive values keep the default.
+func (a *Analyzer) SetConcurrency(n int) {
+	if n > 0 {
+		a.concurrency = n
+	}
+}
+
+// SetSlotPool makes AnalyzeSDKs take a slot of pool for every SDK it
+// analyzes, replacing the limit set by SetConcurrency. Forced SDKs take
+// high-priority slots.
+func (a *Analyzer) SetSlotPool(pool SlotPool) {
+	a.slots = pool
+}
+
+// SetMultiPass configures multi-pass analysis. SDKs with more than threshold
+// files are analyzed maxFilesPerPass files at a time. Non-positive values
+// keep the defaults.
+func (a *Analyzer) SetMultiPass(threshold, maxFilesPerPass int) {
+	if threshold > 0 {
+		a.multiPassThreshold = threshold
+	}
+	if maxFilesPerPass > 0 {
+		a.maxFilesPerPass = maxFilesPerPass
+	}
+}
+
 // AnalysisResult represents the result of analyzing an SDK
 type AnalysisResult struct {
 	SDK      Config
 	Analysis *analyzer.SDKAnalysis
 	Error    error
+
+	// Incremental is set when only the files changed since the previous
+	// analysis were analyzed
+	Incremental bool
 }
 
-// AnalyzeSDK analyzes a single SDK
+// AnalyzeSDK analyzes a single SDK. When few files changed since the cached
+// analysis only those are analyzed; otherwise SDKs with more relevant files
+// than the multi-pass threshold are analyzed in several passes. Failures
+// are returned as *apperrors.AnalysisFailedError.
 func (a *Analyzer) AnalyzeSDK(ctx context.Context, sdk Config) (*analyzer.SDKAnalysis, error) {
+	analysis, _, err := a.analyzeSDK(ctx, sdk)
+	if err != nil {
+		return nil, &apperrors.AnalysisFailedError{SDK: sdk.Name, Cause: err}
+	}
+	return analysis, nil
+}
+
+// analyzeSDK implements AnalyzeSDK and reports whether the analysis was
+// incremental.
+func (a *Analyzer) analyzeSDK(ctx context.Context, sdk Config) (*analyzer.SDKAnalysis, bool, error) {
 	a.logger.Info().
 		Str("sdk", sdk.Name).
 		Str("url", sdk.URL).
 		Msg("Starting SDK analysis")
 
-	// Clone or update the repository
+	repoPath, err := a.cloneRepo(ctx, sdk)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if analysis, ok, err := a.analyzeIncrementally(ctx, sdk, repoPath); ok {
+		return analysis, true, err
+	}
+
+	request, lfsSkipped, err := a.buildRequest(ctx, sdk, repoPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if a.needsMultiPass(request) {
+		analysis, err := a.analyzeInPasses(ctx, sdk, request)
+		if err != nil {
+			return nil, false, err
+		}
+		analysis.LFSFilesSkipped = lfsSkipped
+		return analysis, false, nil
+	}
+
+	// Analyze with Claude
+	analysis, err := a.claude.AnalyzeCode(ctx, request)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to analyze SDK: %w", err)
+	}
+
+	analysis.SDKVersion = request.Version
+	analysis.LFSFilesSkipped = lfsSkipped
+
+	a.logger.Info().
+		Str("sdk", sdk.Name).
+		Str("sdk_version", analysis.SDKVersion).
+		Int("tokens_used", analysis.TokensUsed).
+		Msg("SDK analysis completed")
+
+	return analysis, false, nil
+}
+
+// MultiPassAnalyze analyzes an SDK in passes of at most maxFilesPerPass
+// files, key files first, and merges the partial results.
+func (a *Analyzer) MultiPassAnalyze(ctx context.Context, sdk Config) (*analyzer.SDKAnalysis, error) {
+	a.logger.Info().
+		Str("sdk", sdk.Name).
+		Str("url", sdk.URL).
+		Msg("Starting multi-pass SDK analysis")
+
+	repoPath, err := a.cloneRepo(ctx, sdk)
+	if err != nil {
+		return nil, err
+	}
+	request, lfsSkipped, err := a.buildRequest(ctx, sdk, repoPath)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := a.analyzeInPasses(ctx, sdk, request)
+	if err != nil {
+		return nil, err
+	}
+	analysis.LFSFilesSkipped = lfsSkipped
+	return analysis, nil
+}
+
+// cloneRepo clones or updates the SDK repository and returns its path.
+func (a *Analyzer) cloneRepo(ctx context.Context, sdk Config) (string, error) {
+	if sdk.IsPrivate && !a.git.HasGitHubToken() {
+		return "", fmt.Errorf("%w: set GITHUB_TOKEN to analyze %s", ErrPrivateRepo, sdk.Name)
+	}
+
 	branch := sdk.Branch
 	if branch == "" {
 		branch = "main"
 	}
 
+	if err := a.git.CheckCloneSpace(ctx, sdk.URL, sdk.EstimatedSize); err != nil {
+		return "", fmt.Errorf("skipping clone: %w", err)
+	}
+
 	if err := a.git.Clone(ctx, sdk.URL, branch); err != nil {
-		return nil, fmt.Errorf("failed to clone/update repository: %w", err)
+		return "", fmt.Errorf("failed to clone/update repository: %w", err)
 	}
 
-	// Get repository path
-	repoPath := a.git.GetRepoPath(sdk.URL)
+	return a.git.GetRepoPath(sdk.URL), nil
+}
 
+// buildRequest builds the analysis request from the SDK's files and
+// returns it with the number of Git LFS pointer files left out.
+func (a *Analyzer) buildRequest(ctx context.Context, sdk Config, repoPath string) (analyzer.AnalysisRequest, int, error) {
 	// Extract relevant files
-	codeFiles, err := a.extractCodeFiles(repoPath, sdk)
+	codeFiles, lfsSkipped, err := a.extractCodeFiles(ctx, repoPath, sdk)
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract code files: %w", err)
+		return analyzer.AnalysisRequest{}, 0, fmt.Errorf("failed to extract code files: %w", err)
 	}
 
 	a.logger.Debug().
 		Str("sdk", sdk.Name).
 		Int("files", len(codeFiles)).
+		Int("lfs_files_skipped", lfsSkipped).
 		Msg("Extracted code files for analysis")
 
 	// Get latest commit info
 	latestCommit, err := a.git.GetLatestCommit(ctx, repoPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest commit: %w", err)
+		return analyzer.AnalysisRequest{}, 0, fmt.Errorf("failed to get latest commit: %w", err)
 	}
 
-	// Prepare analysis request
-	request := analyzer.AnalysisRequest{
+	return analyzer.AnalysisRequest{
 		SDKName:    sdk.Name,
-		Version:    latestCommit.Hash[:7], // Use short commit hash as version
+		Version:    a.sdkVersion(ctx, sdk, repoPath, latestCommit),
 		Code:       codeFiles,
 		CommitHash: latestCommit.Hash,
+	}, lfsSkipped, nil
+}
+
+// sdkVersion returns the version an SDK is analyzed at: its highest
+// semantic version tag, or the short hash of commit if it has none.
+func (a *Analyzer) sdkVersion(ctx context.Context, sdk Config, repoPath string, commit *git.Commit) string {
+	tag, err := a.git.GetLatestTag(ctx, repoPath)
+	if err == nil {
+		return tag
 	}
+	if !errors.Is(err, git.ErrNoTags) {
+		a.logger.Warn().
+			Err(err).
+			Str("sdk", sdk.Name).
+			Msg("Failed to read release tags, using commit hash as version")
+	}
+	return commit.Hash[:7]
+}
 
-	// Analyze with Claude
-	analysis, err := a.claude.AnalyzeCode(ctx, request)
-	if err != nil {
-		return nil, fmt.Errorf("failed to analyze SDK: %w", err)
+// needsMultiPass reports whether a request has too many files for one pass
+func (a *Analyzer) needsMultiPass(request analyzer.AnalysisRequest) bool {
+	return len(request.Code) > a.multiPassThreshold
+}
+
+// analyzeInPasses analyzes request in chunks and merges the results
+func (a *Analyzer) analyzeInPasses(ctx context.Context, sdk Config, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
+	passes := splitPasses(request.Code, sdk.KeyFiles, a.maxFilesPerPass)
+
+	partials := make([]*analyzer.SDKAnalysis, 0, len(passes))
+	for i, files := range passes {
+		a.logger.Info().
+			Str("sdk", sdk.Name).
+			Int("pass", i+1).
+			Int("passes", len(passes)).
+			Int("files", len(files)).
+			Msg("Analyzing SDK pass")
+
+		passRequest := request
+		passRequest.Code = files
+
+		partial, err := a.claude.AnalyzeCode(ctx, passRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to analyze SDK (pass %d/%d): %w", i+1, len(passes), err)
+		}
+		partials = append(partials, partial)
+	}
+
+	analysis := analyzer.MergeAnalyses(partials...)
+	if analysis == nil {
+		return nil, fmt.Errorf("no files to analyze")
 	}
+	analysis.SDKVersion = request.Version
 
 	a.logger.Info().
 		Str("sdk", sdk.Name).
+		Int("passes", analysis.PassCount).
 		Int("tokens_used", analysis.TokensUsed).
-		Msg("SDK analysis completed")
+		Msg("Multi-pass SDK analysis completed")
 
 	return analysis, nil
 }
 
-// AnalyzeAllSDKs analyzes all active SDKs
-func (a *Analyzer) AnalyzeAllSDKs(ctx context.Context) []AnalysisResult {
-	activeSDKs := a.configs.GetActiveSDKs()
-	results := make([]AnalysisResult, 0, len(activeSDKs))
+// splitPasses splits files into chunks of at most perPass files. Key files
+// come first, in configured order, followed by the rest sorted by path.
+func splitPasses(files map[string]string, keyFiles []string, perPass int) []map[string]string {
+	if perPass <= 0 {
+		perPass = len(files)
+	}
 
-	a.logger.Info().
-		Int("count", len(activeSDKs)).
-		Msg("Starting analysis of active SDKs")
+	order := make([]string, 0, len(files))
+	isKey := make(map[string]bool, len(keyFiles))
+	for _, name := range keyFiles {
+		if _, ok := files[name]; ok && !isKey[name] {
+			isKey[name] = true
+			order = append(order, name)
+		}
+	}
 
-	// Prepare batch requests for cost optimization
-	batchSize := 5 // Process 5 SDKs at a time
-	for i := 0; i < len(activeSDKs); i += batchSize {
-		end := i + batchSize
-		if end > len(activeSDKs) {
-			end = len(activeSDKs)
+	rest := make([]string, 0, len(files))
+	for name := range files {
+		if !isKey[name] {
+			rest = append(rest, name)
+		}
+	}
+	sort.Strings(rest)
+	order = append(order, rest...)
+
+	var passes []map[string]string
+	for start := 0; start < len(order); start += perPass {
+		end := min(start+perPass, len(order))
+		chunk := make(map[string]string, end-start)
+		for _, name := range order[start:end] {
+			chunk[name] = files[name]
 		}
+		passes = append(passes, chunk)
+	}
+	return passes
+}
 
-		batch := activeSDKs[i:end]
-		batchResults := a.analyzeBatch(ctx, batch)
-		results = append(results, batchResults...)
+// ActiveSDKs returns the configurations of the SDKs AnalyzeAllSDKs
+// analyzes: the active SDKs of the default registry, read on every call
+// so SDKs registered at runtime are included.
+func (a *Analyzer) ActiveSDKs() []Config {
+	if a.configs != nil {
+		return a.configs.GetActiveSDKs()
+	}
+	configs, err := LoadConfigs()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("Failed to load SDK configs")
+		return nil
 	}
+	return configs.GetActiveSDKs()
+}
 
-	return results
+// AnalyzeAllSDKs analyzes all active SDKs, cloning and analyzing up to
+// the configured concurrency at once, or as many as the slot pool allows.
+// SDKs named in force start first, followed by the rest in descending
+// priority; SDKs of equal priority start in the order of the SDK
+// configuration, which is also the order of the results. Once stop is
+// closed, SDKs that are already being analyzed run to completion and the
+// remaining ones are reported with ErrAnalysisSkipped.
+func (a *Analyzer) AnalyzeAllSDKs(ctx context.Context, stop <-chan struct{}, force ...string) []AnalysisResult {
+	return a.AnalyzeSDKs(ctx, stop, a.ActiveSDKs(), force...)
 }
 
-// analyzeBatch analyzes a batch of SDKs
-func (a *Analyzer) analyzeBatch(ctx context.Context, sdks []Config) []AnalysisResult {
-	var requests []analyzer.AnalysisRequest
-	sdkMap := make(map[string]Config)
+// AnalyzeSDKs analyzes sdks like AnalyzeAllSDKs, with results in the order
+// of sdks.
+func (a *Analyzer) AnalyzeSDKs(ctx context.Context, stop <-chan struct{}, sdks []Config, force ...string) []AnalysisResult {
+	results := make([]AnalysisResult, len(sdks))
+	queue := NewSDKPriorityQueue(sdks, force)
+
+	a.logger.Info().
+		Int("count", len(sdks)).
+		Int("concurrency", a.concurrency).
+		Strs("force", force).
+		Msg("Starting analysis of active SDKs")
 
-	// Prepare batch requests
-	for _, sdk := range sdks {
-		// Clone/update repository
-		branch := sdk.Branch
-		if branch == "" {
-			branch = "main"
+	pool := a.slots
+	if pool == nil {
+		pool = make(semaphorePool, max(a.concurrency, 1))
+	}
+
+	// acquireCtx ends waiting for a slot once stop is closed
+	acquireCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-acquireCtx.Done():
 		}
+	}()
 
-		if err := a.git.Clone(ctx, sdk.URL, branch); err != nil {
-			a.logger.Error().
-				Err(err).
-				Str("sdk", sdk.Name).
-				Msg("Failed to clone repository")
-			continue
+	var wg sync.WaitGroup
+	for {
+		sdk, i, ok := queue.Next()
+		if !ok {
+			break
 		}
 
-		// Extract code files
-		repoPath := a.git.GetRepoPath(sdk.URL)
-		codeFiles, err := a.extractCodeFiles(repoPath, sdk)
-		if err != nil {
-			a.logger.Error().
-				Err(err).
-				Str("sdk", sdk.Name).
-				Msg("Failed to extract code files")
-			continue
+		if isStopped(stop) {
+			skipQueued(results, sdk, i, queue)
+			break
 		}
 
-		// Get latest commit
-		latestCommit, err := a.git.GetLatestCommit(ctx, repoPath)
+		priority := sdk.EffectivePriority()
+		if slices.Contains(force, sdk.Name) {
+			priority = PriorityHigh
+		}
+		release, err := pool.Acquire(acquireCtx, priority)
 		if err != nil {
-			a.logger.Error().
-				Err(err).
-				Str("sdk", sdk.Name).
-				Msg("Failed to get latest commit")
-			continue
+			skipQueued(results, sdk, i, queue)
+			break
 		}
 
-		request := analyzer.AnalysisRequest{
-			SDKName:    sdk.Name,
-			Version:    latestCommit.Hash[:7],
-			Code:       codeFiles,
-			CommitHash: latestCommit.Hash,
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
 
-		requests = append(requests, request)
-		sdkMap[sdk.Name] = sdk
+			analysis, incremental, err := a.analyzeSDK(ctx, sdk)
+			if err != nil {
+				a.logger.Error().
+					Err(err).
+					Str("sdk", sdk.Name).
+					Msg("Failed to analyze SDK")
+			}
+			results[i] = AnalysisResult{
+				SDK:         sdk,
+				Analysis:    analysis,
+				Error:       err,
+				Incremental: incremental,
+			}
+		}()
 	}
 
-	// Batch analyze
-	batchResult, err := a.claude.BatchAnalyze(ctx, requests)
-	if err != nil {
-		a.logger.Error().
-			Err(err).
-			Msg("Batch analysis failed, falling back to individual analysis")
-
-		// Fall back to individual analysis
-		var results []AnalysisResult
-		for _, sdk := range sdks {
-			analysis, err := a.AnalyzeSDK(ctx, sdk)
-			results = append(results, AnalysisResult{
-				SDK:      sdk,
-				Analysis: analysis,
-				Error:    err,
-			})
-		}
-		return results
-	}
+	wg.Wait()
+	return results
+}
 
-	// Convert batch results to analysis results
-	var results []AnalysisResult
-	for sdkName, analysis := range batchResult.Results {
-		sdk := sdkMap[sdkName]
-		results = append(results, AnalysisResult{
-			SDK:      sdk,
-			Analysis: analysis,
-			Error:    nil,
-		})
+// skipQueued reports sdk, at index i of results, and every SDK left in
+// queue as skipped.
+func skipQueued(results []AnalysisResult, sdk Config, i int, queue *SDKPriorityQueue) {
+	for ok := true; ok; sdk, i, ok = queue.Next() {
+		results[i] = AnalysisResult{SDK: sdk, Error: ErrAnalysisSkipped}
 	}
+}
 
-	// Add errors
-	for sdkName, errMsg := range batchResult.Errors {
-		sdk := sdkMap[sdkName]
-		results = append(results, AnalysisResult{
-			SDK:      sdk,
-			Analysis: nil,
-			Error:    fmt.Errorf("%s", errMsg),
-		})
+// isStopped reports whether stop has been closed. A nil channel never stops.
+func isStopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
 	}
-
-	return results
 }
 
 // NeedsUpdate checks if an SDK needs to be updated
@@ -269,24 +563,113 @@ func (a *Analyzer) NeedsUpdate(ctx context.Context, sdk Config) (bool, error) {
 	return len(commits) > 0, nil
 }
 
-// extractCodeFiles extracts relevant code files from the repository
-func (a *Analyzer) extractCodeFiles(repoPath string, sdk Config) (map[string]string, error) {
+// extractCodeFiles extracts relevant code files from the repository. Key
+// files are read first, in configured order, followed by the files
+// matching the SDK's patterns from most to least useful by RankFile;
+// extraction stops once the SDK's file limit or token budget would be
+// exceeded, so the least useful files are the ones left out. Git LFS
+// pointer files are skipped unless the SDK sets SkipLFSFiles to false, and
+// the number skipped is returned.
+func (a *Analyzer) extractCodeFiles(ctx context.Context, repoPath string, sdk Config) (map[string]string, int, error) {
+	candidates, err := a.rankedCandidates(repoPath, sdk)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to walk repository: %w", err)
+	}
+
 	codeFiles := make(map[string]string)
+	budget := newTokenBudget(a, sdk)
+	lfsSkipped := 0
 
-	// If key files are specified, read those first
-	if len(sdk.KeyFiles) > 0 {
-		for _, keyFile := range sdk.KeyFiles {
-			filePath := filepath.Join(repoPath, keyFile)
-			content, err := os.ReadFile(filePath)
-			if err != nil {
-				a.logger.Warn().
-					Err(err).
-					Str("file", keyFile).
-					Msg("Failed to read key file")
-				continue
-			}
-			codeFiles[keyFile] = string(content)
+	add := func(relPath, content string) bool {
+		// Limit total files to prevent token overflow
+		if len(codeFiles) >= sdk.fileLimit() || !budget.add(ctx, relPath, content) {
+			return false
 		}
+		codeFiles[relPath] = content
+		return true
+	}
+
+	// Generated files can only be told apart by their content, so they are
+	// held back until every other candidate has been taken
+	type generatedFile struct{ path, content string }
+	var generated []generatedFile
+
+	full := false
+	for _, candidate := range candidates {
+		content, err := os.ReadFile(filepath.Join(repoPath, candidate.path))
+		if err != nil {
+			a.logger.Warn().
+				Err(err).
+				Str("file", candidate.path).
+				Msg("Failed to read file")
+			continue
+		}
+		if a.skipLFSPointer(sdk, candidate.path, content) {
+			lfsSkipped++
+			continue
+		}
+		if !candidate.key && isGenerated(string(content)) {
+			generated = append(generated, generatedFile{candidate.path, string(content)})
+			continue
+		}
+		if !add(candidate.path, string(content)) {
+			full = true
+			break
+		}
+	}
+	for _, file := range generated {
+		if full || !add(file.path, file.content) {
+			break
+		}
+	}
+
+	// If no files found, return error
+	if len(codeFiles) == 0 {
+		return nil, 0, fmt.Errorf("no matching files found in repository")
+	}
+
+	if budget.exhausted {
+		a.logger.Info().
+			Str("sdk", sdk.Name).
+			Int("files", len(codeFiles)).
+			Int("estimated_tokens", budget.used).
+			Int("token_budget", budget.limit).
+			Msg("Token budget reached, extracted files truncated")
+	}
+
+	return codeFiles, lfsSkipped, nil
+}
+
+// codeCandidate is a file extractCodeFiles may read.
+type codeCandidate struct {
+	path  string // relative to the repository root
+	size  int64
+	score int
+	key   bool
+}
+
+// rankedCandidates lists the files extractCodeFiles may read in the order
+// it reads them: the SDK's key files in configured order, then the files
+// matching its patterns by descending RankFile score, smaller files first
+// among equal scores.
+func (a *Analyzer) rankedCandidates(repoPath string, sdk Config) ([]codeCandidate, error) {
+	var keyFiles, files []codeCandidate
+	seen := make(map[string]bool)
+
+	for _, keyFile := range sdk.KeyFiles {
+		info, err := os.Stat(filepath.Join(repoPath, keyFile))
+		if err != nil {
+			a.logger.Warn().
+				Err(err).
+				Str("file", keyFile).
+				Msg("Failed to read key file")
+			continue
+		}
+		if seen[keyFile] || info.IsDir() {
+			continue
+		}
+		seen[keyFile] = true
+		keyFiles = append(keyFiles, codeCandidate{path: keyFile, size: info.Size(), key: true})
 	}
 
 	// Walk the repository and find matching files
@@ -295,6 +678,11 @@ func (a *Analyzer) extractCodeFiles(repoPath string, sdk Config) (map[string]str
 			return nil // Skip files we can't access
 		}
 
+		relPath, err := filepath.Rel(repoPath, path)
+		if err != nil {
+			return nil
+		}
+
 		// Skip directories
 		if info.IsDir() {
 			// Skip common non-code directories
@@ -305,56 +693,121 @@ func (a *Analyzer) extractCodeFiles(repoPath string, sdk Config) (map[string]str
 				strings.Contains(path, "/.pytest_cache/") {
 				return filepath.SkipDir
 			}
+			if relPath != "." && isExcluded(sdk.ExcludePatterns, relPath) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 
 		// Skip files that are too large
-		if info.Size() > 100*1024 { // 100KB limit per file
+		if info.Size() > sdk.fileSizeLimit() {
 			return nil
 		}
 
-		// Check if file matches patterns
-		relPath, err := filepath.Rel(repoPath, path)
-		if err != nil {
+		if !matchesPatterns(sdk.Patterns, path) || isExcluded(sdk.ExcludePatterns, relPath) {
 			return nil
 		}
-
-		for _, pattern := range sdk.Patterns {
-			matched, err := filepath.Match(pattern, filepath.Base(path))
-			if err != nil {
-				continue
-			}
-			if matched {
-				// Limit total files to prevent token overflow
-				if len(codeFiles) >= 50 {
-					return filepath.SkipAll
-				}
-
-				content, err := os.ReadFile(path)
-				if err != nil {
-					a.logger.Warn().
-						Err(err).
-						Str("file", relPath).
-						Msg("Failed to read file")
-					continue
-				}
-
-				codeFiles[relPath] = string(content)
-				break
-			}
+		if seen[relPath] {
+			return nil // Already listed as a key file
 		}
 
+		files = append(files, codeCandidate{path: relPath, size: info.Size(), score: RankFile(relPath, sdk)})
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
+	sort.Slice(files, func(i, j int) bool {
+		a, b := files[i], files[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if a.size != b.size {
+			return a.size < b.size
+		}
+		return a.path < b.path
+	})
+	return append(keyFiles, files...), nil
+}
+
+// lfsPointerPrefix starts the content of Git LFS pointer files, which stand
+// in for files stored on an LFS server.
+const lfsPointerPrefix = "version https://git-lfs.github.com"
+
+// skipLFSPointer reports whether the file at relPath is a Git LFS pointer
+// that the SDK's analysis leaves out.
+func (a *Analyzer) skipLFSPointer(sdk Config, relPath string, content []byte) bool {
+	if !sdk.skipsLFSFiles() || !bytes.HasPrefix(content, []byte(lfsPointerPrefix)) {
+		return false
+	}
+	a.logger.Debug().
+		Str("sdk", sdk.Name).
+		Str("file", relPath).
+		Msg("Skipping Git LFS pointer file")
+	return true
+}
+
+// tokenBudget tracks the estimated tokens of the files extracted so far.
+type tokenBudget struct {
+	analyzer  *Analyzer
+	sdk       Config
+	limit     int
+	used      int
+	exhausted bool
+}
+
+func newTokenBudget(a *Analyzer, sdk Config) *tokenBudget {
+	return &tokenBudget{analyzer: a, sdk: sdk, limit: sdk.tokenBudget()}
+}
+
+// add counts the tokens of a file and reports whether it fits in the
+// budget. Files are counted one at a time, so extraction asks the analyzer
+// about each file once rather than about every file read so far.
+func (b *tokenBudget) add(ctx context.Context, name, content string) bool {
+	if b.exhausted {
+		return false
+	}
+
+	tokens, err := b.analyzer.claude.CountTokens(ctx, analyzer.AnalysisRequest{
+		SDKName: b.sdk.Name,
+		Code:    map[string]string{name: content},
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to walk repository: %w", err)
+		b.analyzer.logger.Warn().Err(err).Str("file", name).Msg("Failed to count tokens, estimating")
+		tokens = estimateTokens(content)
 	}
 
-	// If no files found, return error
-	if len(codeFiles) == 0 {
-		return nil, fmt.Errorf("no matching files found in repository")
+	if b.used+tokens > b.limit {
+		b.exhausted = true
+		return false
+	}
+	b.used += tokens
+	return true
+}
+
+// isExcluded reports whether relPath, or its base name, matches one of the
+// SDK's exclude patterns.
+func isExcluded(patterns []string, relPath string) bool {
+	relPath = filepath.ToSlash(relPath)
+	for _, pattern := range patterns {
+		if matched, err := path.Match(pattern, relPath); err == nil && matched {
+			return true
+		}
+		if matched, err := path.Match(pattern, path.Base(relPath)); err == nil && matched {
+			return true
+		}
 	}
+	return false
+}
 
-	return codeFiles, nil
+// matchesPatterns reports whether the file name of path matches one of the
+// SDK's patterns.
+func matchesPatterns(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, filepath.Base(path)); err == nil && matched {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,819 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+)
+
+func TestNewAnalyzerInvalidConfigs(t *testing.T) {
+	previous := DefaultRegistry()
+	t.Cleanup(func() { SetDefaultRegistry(previous) })
+
+	// Register rejects invalid SDKs, but a registry file edited by hand can
+	// still hold them
+	path := filepath.Join(t.TempDir(), "sdk_registry.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"registered": [{"name": "sentry-go", "url": "https://github.com/getsentry/sentry-go"}]}`), 0o600))
+	r, err := OpenRegistry(path)
+	require.NoError(t, err)
+	SetDefaultRegistry(r)
+
+	_, err = NewAnalyzer(nil, nil, nil, zerolog.Nop())
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	assert.Contains(t, err.Error(), "name is used by more than one SDK")
+	assert.Contains(t, err.Error(), "language is required")
+	assert.Contains(t, err.Error(), "at least one pattern is required")
+}
+
+func TestAnalyzeAllSDKsStopped(t *testing.T) {
+	logger := zerolog.Nop()
+	gitClient := git.NewClient(t.TempDir(), logger)
+
+	a, err := NewAnalyzer(gitClient, nil, nil, logger)
+	require.NoError(t, err)
+
+	stop := make(chan struct{})
+	close(stop)
+
+	results := a.AnalyzeAllSDKs(context.Background(), stop)
+	require.Len(t, results, len(a.ActiveSDKs()))
+
+	for _, result := range results {
+		assert.True(t, errors.Is(result.Error, ErrAnalysisSkipped), "SDK %s should be skipped", result.SDK.Name)
+		assert.Nil(t, result.Analysis)
+	}
+}
+
+// concurrencyProbe counts SDKs between Clone and the end of AnalyzeCode and
+// records the peak. It fakes both git and the Claude analyzer: every SDK
+// lives in repoPath, changed is reported as changed, and the SDK named
+// failing fails analysis. hasToken is reported as HasGitHubToken.
+type concurrencyProbe struct {
+	recordingAnalyzer
+
+	repoPath string
+	changed  []string
+	failing  string
+	hasToken bool
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	requests []analyzer.AnalysisRequest
+}
+
+func (p *concurrencyProbe) CheckCloneSpace(ctx context.Context, repoURL string, estimatedSize int64) error {
+	return nil
+}
+
+func (p *concurrencyProbe) Clone(ctx context.Context, repoURL, branch string) error {
+	n := p.inFlight.Add(1)
+	for {
+		peak := p.peak.Load()
+		if n <= peak || p.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+func (p *concurrencyProbe) Pull(ctx context.Context, repoPath string) error {
+	return nil
+}
+
+func (p *concurrencyProbe) GetRepoPath(repoURL string) string {
+	return p.repoPath
+}
+
+func (p *concurrencyProbe) GetLatestCommit(ctx context.Context, repoPath string) (*git.Commit, error) {
+	return &git.Commit{Hash: "0123456789abcdef"}, nil
+}
+
+func (p *concurrencyProbe) GetLatestTag(ctx context.Context, repoPath string) (string, error) {
+	return "", git.ErrNoTags
+}
+
+func (p *concurrencyProbe) GetCommitsSince(ctx context.Context, repoPath string, since time.Time) ([]git.Commit, error) {
+	return nil, nil
+}
+
+func (p *concurrencyProbe) GetChangedFiles(ctx context.Context, repoPath string, since time.Time) ([]string, error) {
+	return p.changed, nil
+}
+
+func (p *concurrencyProbe) HasGitHubToken() bool {
+	return p.hasToken
+}
+
+func (p *concurrencyProbe) AnalyzeCode(ctx context.Context, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
+	defer p.inFlight.Add(-1)
+	time.Sleep(10 * time.Millisecond)
+
+	p.mu.Lock()
+	p.requests = append(p.requests, request)
+	p.mu.Unlock()
+
+	if request.SDKName == p.failing {
+		return nil, errors.New("analysis failed")
+	}
+	return &analyzer.SDKAnalysis{Language: request.SDKName, PassCount: 1, TokensUsed: 10}, nil
+}
+
+func TestAnalyzeAllSDKsConcurrency(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 3)
+	probe := &concurrencyProbe{repoPath: repoPath, failing: "sdk-04"}
+
+	logger := zerolog.Nop()
+	a, err := NewAnalyzer(nil, probe, nil, logger)
+	require.NoError(t, err)
+	a.git = probe
+	a.SetConcurrency(3)
+
+	a.configs = &ConfigList{}
+	for i := 0; i < 12; i++ {
+		a.configs.SDKs = append(a.configs.SDKs, Config{
+			Name:     fmt.Sprintf("sdk-%02d", i),
+			URL:      fmt.Sprintf("https://example.com/sdk-%02d", i),
+			Patterns: []string{"*.go"},
+			Active:   true,
+		})
+	}
+
+	results := a.AnalyzeAllSDKs(context.Background(), nil)
+
+	assert.Equal(t, int32(3), probe.peak.Load())
+	assert.Zero(t, probe.inFlight.Load())
+
+	// Results keep the configured order
+	require.Len(t, results, 12)
+	for i, result := range results {
+		name := fmt.Sprintf("sdk-%02d", i)
+		assert.Equal(t, name, result.SDK.Name)
+		if name == "sdk-04" {
+			assert.Error(t, result.Error)
+			assert.Nil(t, result.Analysis)
+			continue
+		}
+		require.NoError(t, result.Error)
+		assert.Equal(t, name, result.Analysis.Language)
+	}
+}
+
+func TestAnalyzeAllSDKsPriority(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 1)
+	probe := &concurrencyProbe{repoPath: repoPath}
+
+	logger := zerolog.Nop()
+	a, err := NewAnalyzer(nil, probe, nil, logger)
+	require.NoError(t, err)
+	a.git = probe
+	a.SetConcurrency(1)
+
+	a.configs = &ConfigList{SDKs: []Config{
+		{Name: "low", Priority: PriorityLow},
+		{Name: "normal"},
+		{Name: "high", Priority: PriorityHigh},
+		{Name: "forced", Priority: PriorityLow},
+	}}
+	for i := range a.configs.SDKs {
+		a.configs.SDKs[i].URL = "https://example.com/" + a.configs.SDKs[i].Name
+		a.configs.SDKs[i].Patterns = []string{"*.go"}
+		a.configs.SDKs[i].Active = true
+	}
+
+	results := a.AnalyzeAllSDKs(context.Background(), nil, "forced")
+
+	var analyzed []string
+	for _, request := range probe.requests {
+		analyzed = append(analyzed, request.SDKName)
+	}
+	assert.Equal(t, []string{"forced", "high", "normal", "low"}, analyzed)
+
+	// Results keep the configured order
+	require.Len(t, results, 4)
+	for i, result := range results {
+		require.NoError(t, result.Error)
+		assert.Equal(t, a.configs.SDKs[i].Name, result.SDK.Name)
+	}
+}
+
+// recordingPool is a SlotPool with one slot that records the priority of
+// every Acquire.
+type recordingPool struct {
+	semaphorePool
+
+	mu         sync.Mutex
+	priorities []int
+	released   int
+}
+
+func (p *recordingPool) Acquire(ctx context.Context, priority int) (func(), error) {
+	p.mu.Lock()
+	p.priorities = append(p.priorities, priority)
+	p.mu.Unlock()
+
+	release, err := p.semaphorePool.Acquire(ctx, priority)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		p.mu.Lock()
+		p.released++
+		p.mu.Unlock()
+		release()
+	}, nil
+}
+
+func TestAnalyzeAllSDKsSlotPool(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 1)
+	probe := &concurrencyProbe{repoPath: repoPath}
+
+	logger := zerolog.Nop()
+	a, err := NewAnalyzer(nil, probe, nil, logger)
+	require.NoError(t, err)
+	a.git = probe
+	a.SetConcurrency(4)
+
+	pool := &recordingPool{semaphorePool: make(semaphorePool, 1)}
+	a.SetSlotPool(pool)
+
+	a.configs = &ConfigList{SDKs: []Config{
+		{Name: "low", Priority: PriorityLow},
+		{Name: "normal"},
+		{Name: "high", Priority: PriorityHigh},
+		{Name: "forced", Priority: PriorityLow},
+	}}
+	for i := range a.configs.SDKs {
+		a.configs.SDKs[i].URL = "https://example.com/" + a.configs.SDKs[i].Name
+		a.configs.SDKs[i].Patterns = []string{"*.go"}
+		a.configs.SDKs[i].Active = true
+	}
+
+	results := a.AnalyzeAllSDKs(context.Background(), nil, "forced")
+	require.Len(t, results, 4)
+	for _, result := range results {
+		require.NoError(t, result.Error)
+	}
+
+	// The pool, not SetConcurrency, limits the analyses
+	assert.Equal(t, int32(1), probe.peak.Load())
+	assert.Equal(t, []int{PriorityHigh, PriorityHigh, PriorityNormal, PriorityLow}, pool.priorities)
+	assert.Equal(t, 4, pool.released)
+}
+
+func TestAnalyzeAllSDKsSlotPoolStopped(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 1)
+	probe := &concurrencyProbe{repoPath: repoPath}
+
+	logger := zerolog.Nop()
+	a, err := NewAnalyzer(nil, probe, nil, logger)
+	require.NoError(t, err)
+	a.git = probe
+
+	// A pool without free slots leaves every SDK waiting until stop closes
+	pool := &recordingPool{semaphorePool: make(semaphorePool, 1)}
+	pool.semaphorePool <- struct{}{}
+	a.SetSlotPool(pool)
+
+	a.configs = &ConfigList{SDKs: []Config{{Name: "a"}, {Name: "b"}}}
+	for i := range a.configs.SDKs {
+		a.configs.SDKs[i].URL = "https://example.com/" + a.configs.SDKs[i].Name
+		a.configs.SDKs[i].Patterns = []string{"*.go"}
+		a.configs.SDKs[i].Active = true
+	}
+
+	stop := make(chan struct{})
+	time.AfterFunc(20*time.Millisecond, func() { close(stop) })
+
+	results := a.AnalyzeAllSDKs(context.Background(), stop)
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.ErrorIs(t, result.Error, ErrAnalysisSkipped)
+	}
+	assert.Empty(t, probe.requests)
+}
+
+func TestAnalyzeAllSDKsIncremental(t *testing.T) {
+	repoPath, files := createMockSDK(t, 20)
+	changed := files[:3]
+
+	logger := zerolog.Nop()
+	cacheManager, err := cache.NewManager(t.TempDir(), logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	previousJSON, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:        "go",
+		ProtocolVersion: "7",
+		Features:        []string{"breadcrumbs"},
+		TokensUsed:      500,
+		PassCount:       1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-mock", string(previousJSON), 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-mock:last_analyzed", time.Now().Add(-time.Hour).Format(time.RFC3339), 0))
+
+	tests := []struct {
+		name        string
+		threshold   int
+		incremental bool
+		files       int
+	}{
+		{name: "few changes", threshold: 10, incremental: true, files: 3},
+		{name: "too many changes", threshold: 3, incremental: false, files: 20},
+		{name: "disabled", threshold: 0, incremental: false, files: 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := &concurrencyProbe{repoPath: repoPath, changed: changed}
+			a, err := NewAnalyzer(nil, probe, cacheManager, logger)
+			require.NoError(t, err)
+			a.git = probe
+			a.SetIncrementalThreshold(tt.threshold)
+			a.configs = &ConfigList{SDKs: []Config{
+				{Name: "sentry-mock", URL: repoPath, Patterns: []string{"*.go"}, Active: true},
+			}}
+
+			results := a.AnalyzeAllSDKs(context.Background(), nil)
+			require.Len(t, results, 1)
+			require.NoError(t, results[0].Error)
+			assert.Equal(t, tt.incremental, results[0].Incremental)
+
+			require.Len(t, probe.requests, 1)
+			request := probe.requests[0]
+			assert.Len(t, request.Code, tt.files)
+			if !tt.incremental {
+				assert.Nil(t, request.Previous)
+				return
+			}
+
+			// Only the changed files are sent, with the previous analysis
+			for _, name := range changed {
+				assert.Contains(t, request.Code, name)
+			}
+			require.NotNil(t, request.Previous)
+			assert.Equal(t, "go", request.Previous.Language)
+
+			// The update is merged into the previous analysis
+			analysis := results[0].Analysis
+			assert.Equal(t, "sentry-mock", analysis.Language)
+			assert.Equal(t, "7", analysis.ProtocolVersion)
+			assert.Equal(t, []string{"breadcrumbs"}, analysis.Features)
+			assert.Equal(t, 10, analysis.TokensUsed)
+		})
+	}
+}
+
+// recordingAnalyzer returns one feature per pass and records the files each
+// pass received.
+type recordingAnalyzer struct {
+	mu       sync.Mutex
+	passes   []map[string]string
+	versions []string
+}
+
+func (r *recordingAnalyzer) AnalyzeCode(ctx context.Context, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.passes = append(r.passes, request.Code)
+	r.versions = append(r.versions, request.Version)
+	return &analyzer.SDKAnalysis{
+		Language:   "go",
+		Features:   []string{fmt.Sprintf("feature-%d", len(r.passes))},
+		TokensUsed: 10,
+		PassCount:  1,
+		AnalyzedAt: time.Now(),
+	}, nil
+}
+
+func (r *recordingAnalyzer) BatchAnalyze(ctx context.Context, requests []analyzer.AnalysisRequest) (*analyzer.BatchAnalysisResult, error) {
+	return nil, errors.New("batch analysis not supported")
+}
+
+func (r *recordingAnalyzer) GetBatchStatus(ctx context.Context, jobID string) (*analyzer.BatchAnalysisResult, error) {
+	return nil, errors.New("batch analysis not supported")
+}
+
+func (r *recordingAnalyzer) CountTokens(ctx context.Context, request analyzer.AnalysisRequest) (int, error) {
+	return 0, nil
+}
+
+// createMockSDK commits count Go files to a new repository and returns its
+// path along with the file names.
+func createMockSDK(t *testing.T, count int) (string, []string) {
+	t.Helper()
+
+	repoPath := filepath.Join(t.TempDir(), "sentry-mock")
+	repo, err := gogit.PlainInit(repoPath, false)
+	require.NoError(t, err)
+	w, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var files []string
+	for i := 0; i < count; i++ {
+		name := fmt.Sprintf("pkg/file%03d.go", i)
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, "pkg"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte("package pkg\n"), 0644))
+		_, err := w.Add(name)
+		require.NoError(t, err)
+		files = append(files, name)
+	}
+
+	_, err = w.Commit("Add SDK files", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+
+	return repoPath, files
+}
+
+func TestAnalyzeSDKPrivateRepo(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 3)
+	sdk := Config{
+		Name:      "sentry-private",
+		URL:       "https://github.com/getsentry/sentry-private",
+		Patterns:  []string{"*.go"},
+		Active:    true,
+		IsPrivate: true,
+	}
+
+	t.Run("without token", func(t *testing.T) {
+		probe := &concurrencyProbe{repoPath: repoPath}
+		a, err := NewAnalyzer(nil, probe, nil, zerolog.Nop())
+		require.NoError(t, err)
+		a.git = probe
+
+		analysis, err := a.AnalyzeSDK(context.Background(), sdk)
+		require.ErrorIs(t, err, ErrPrivateRepo)
+		assert.Contains(t, err.Error(), "GITHUB_TOKEN")
+		assert.Nil(t, analysis)
+
+		var failed *apperrors.AnalysisFailedError
+		require.True(t, errors.As(err, &failed))
+		assert.Equal(t, sdk.Name, failed.SDK)
+		assert.Zero(t, probe.peak.Load(), "private repository should not be cloned")
+	})
+
+	t.Run("with token", func(t *testing.T) {
+		probe := &concurrencyProbe{repoPath: repoPath, hasToken: true}
+		a, err := NewAnalyzer(nil, probe, nil, zerolog.Nop())
+		require.NoError(t, err)
+		a.git = probe
+
+		analysis, err := a.AnalyzeSDK(context.Background(), sdk)
+		require.NoError(t, err)
+		assert.Equal(t, sdk.Name, analysis.Language)
+		assert.Equal(t, int32(1), probe.peak.Load())
+	})
+}
+
+func TestMultiPassAnalyze(t *testing.T) {
+	repoPath, files := createMockSDK(t, 150)
+	keyFiles := []string{"pkg/file149.go", "pkg/file100.go"}
+	sdk := Config{
+		Name:     "sentry-mock",
+		URL:      repoPath,
+		Language: "go",
+		Patterns: []string{"*.go"},
+		KeyFiles: keyFiles,
+		Branch:   "master",
+	}
+
+	tests := []struct {
+		name    string
+		analyze func(a *Analyzer) (*analyzer.SDKAnalysis, error)
+	}{
+		{
+			name: "explicit multi-pass",
+			analyze: func(a *Analyzer) (*analyzer.SDKAnalysis, error) {
+				return a.MultiPassAnalyze(context.Background(), sdk)
+			},
+		},
+		{
+			name: "above threshold",
+			analyze: func(a *Analyzer) (*analyzer.SDKAnalysis, error) {
+				return a.AnalyzeSDK(context.Background(), sdk)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			recorder := &recordingAnalyzer{}
+			a, err := NewAnalyzer(git.NewClient(t.TempDir(), logger), recorder, nil, logger)
+			require.NoError(t, err)
+			a.SetMultiPass(50, 50)
+
+			analysis, err := tt.analyze(a)
+			require.NoError(t, err)
+
+			require.Len(t, recorder.passes, 3)
+			assert.Equal(t, 3, analysis.PassCount)
+			assert.Equal(t, 30, analysis.TokensUsed)
+			assert.Equal(t, []string{"feature-1", "feature-2", "feature-3"}, analysis.Features)
+
+			// Key files are analyzed in the first pass
+			for _, keyFile := range keyFiles {
+				assert.Contains(t, recorder.passes[0], keyFile)
+			}
+
+			// Every file is covered exactly once
+			seen := make(map[string]int)
+			for _, pass := range recorder.passes {
+				assert.LessOrEqual(t, len(pass), 50)
+				for name := range pass {
+					seen[name]++
+				}
+			}
+			assert.Len(t, seen, len(files))
+			for _, name := range files {
+				assert.Equal(t, 1, seen[name], "file %s", name)
+			}
+		})
+	}
+}
+
+func TestAnalyzeSDKBelowThreshold(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 150)
+	logger := zerolog.Nop()
+	recorder := &recordingAnalyzer{}
+	a, err := NewAnalyzer(git.NewClient(t.TempDir(), logger), recorder, nil, logger)
+	require.NoError(t, err)
+	a.SetMultiPass(200, 50)
+
+	analysis, err := a.AnalyzeSDK(context.Background(), Config{
+		Name:     "sentry-mock",
+		URL:      repoPath,
+		Patterns: []string{"*.go"},
+		Branch:   "master",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, recorder.passes, 1)
+	assert.Len(t, recorder.passes[0], 150)
+	assert.Equal(t, 1, analysis.PassCount)
+}
+
+func TestAnalyzeSDKVersion(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 3)
+	sdk := Config{
+		Name:     "sentry-mock",
+		URL:      repoPath,
+		Patterns: []string{"*.go"},
+		Branch:   "master",
+	}
+	analyze := func(t *testing.T) (*analyzer.SDKAnalysis, *recordingAnalyzer) {
+		logger := zerolog.Nop()
+		recorder := &recordingAnalyzer{}
+		a, err := NewAnalyzer(git.NewClient(t.TempDir(), logger), recorder, nil, logger)
+		require.NoError(t, err)
+
+		analysis, err := a.AnalyzeSDK(context.Background(), sdk)
+		require.NoError(t, err)
+		return analysis, recorder
+	}
+
+	repo, err := gogit.PlainOpen(repoPath)
+	require.NoError(t, err)
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	// Without release tags the short commit hash is the version
+	analysis, recorder := analyze(t)
+	assert.Equal(t, []string{head.Hash().String()[:7]}, recorder.versions)
+	assert.Equal(t, head.Hash().String()[:7], analysis.SDKVersion)
+
+	for _, tag := range []string{"v1.9.0", "v1.10.0", "v1.2.0", "nightly"} {
+		_, err := repo.CreateTag(tag, head.Hash(), nil)
+		require.NoError(t, err)
+	}
+
+	analysis, recorder = analyze(t)
+	assert.Equal(t, []string{"v1.10.0"}, recorder.versions)
+	assert.Equal(t, "v1.10.0", analysis.SDKVersion)
+}
+
+func TestSplitPasses(t *testing.T) {
+	files := map[string]string{"a.go": "", "b.go": "", "c.go": "", "d.go": "", "e.go": ""}
+
+	passes := splitPasses(files, []string{"d.go", "missing.go"}, 2)
+	require.Len(t, passes, 3)
+	assert.Equal(t, map[string]string{"d.go": "", "a.go": ""}, passes[0])
+	assert.Equal(t, map[string]string{"b.go": "", "c.go": ""}, passes[1])
+	assert.Equal(t, map[string]string{"e.go": ""}, passes[2])
+
+	assert.Len(t, splitPasses(files, nil, 0), 1)
+}
+
+func TestExtractCodeFilesSkipsLFSPointers(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\n" +
+		"oid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\n" +
+		"size 12345\n"
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"client.go":         "package sentry",
+		"generated/lfs.go":  pointer,
+		"transport_lfs.go":  pointer,
+		"docs/version.go":   "// version https://git-lfs.github.com is only mentioned here",
+		"assets/binary.bin": pointer,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+
+	logger := zerolog.Nop()
+	a := &Analyzer{claude: analyzer.NewMockAnalyzer(logger), logger: logger}
+	sdk := Config{Name: "sentry-mock", Patterns: []string{"*.go"}, KeyFiles: []string{"transport_lfs.go"}}
+
+	codeFiles, lfsSkipped, err := a.extractCodeFiles(context.Background(), repoPath, sdk)
+	require.NoError(t, err)
+	assert.Len(t, codeFiles, 2)
+	assert.Contains(t, codeFiles, "client.go")
+	assert.Contains(t, codeFiles, filepath.Join("docs", "version.go"))
+	// The key file is skipped once, not again when the walk reaches it, and
+	// binary.bin does not match the patterns
+	assert.Equal(t, 2, lfsSkipped)
+
+	skip := false
+	sdk.SkipLFSFiles = &skip
+	codeFiles, lfsSkipped, err = a.extractCodeFiles(context.Background(), repoPath, sdk)
+	require.NoError(t, err)
+	assert.Len(t, codeFiles, 4)
+	assert.Equal(t, pointer, codeFiles["transport_lfs.go"])
+	assert.Zero(t, lfsSkipped)
+}
+
+func TestExtractCodeFilesLimits(t *testing.T) {
+	repoPath := t.TempDir()
+	files := map[string]string{
+		"client.go":            strings.Repeat("a", 400),
+		"client_test.go":       strings.Repeat("b", 400),
+		"transport.go":         strings.Repeat("c", 400),
+		"zz_large.go":          strings.Repeat("d", 2000),
+		"internal/testdata.go": strings.Repeat("e", 400),
+		"testdata/fixture.go":  strings.Repeat("f", 400),
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+
+	logger := zerolog.Nop()
+	a := &Analyzer{claude: analyzer.NewMockAnalyzer(logger), logger: logger}
+	base := Config{Name: "sentry-mock", Patterns: []string{"*.go"}}
+
+	tests := []struct {
+		name     string
+		modify   func(sdk *Config)
+		expected []string
+	}{
+		{
+			name: "defaults",
+			expected: []string{
+				"client.go", "client_test.go", "internal/testdata.go", "testdata/fixture.go", "transport.go", "zz_large.go",
+			},
+		},
+		{
+			name: "exclude patterns",
+			modify: func(sdk *Config) {
+				sdk.ExcludePatterns = []string{"*_test.go", "testdata"}
+			},
+			expected: []string{"client.go", "internal/testdata.go", "transport.go", "zz_large.go"},
+		},
+		{
+			name:     "max file size",
+			modify:   func(sdk *Config) { sdk.MaxFileSize = 1000 },
+			expected: []string{"client.go", "client_test.go", "internal/testdata.go", "testdata/fixture.go", "transport.go"},
+		},
+		{
+			// Tests rank below the other files
+			name:     "max files",
+			modify:   func(sdk *Config) { sdk.MaxFilesPerSDK = 2 },
+			expected: []string{"client.go", "internal/testdata.go"},
+		},
+		{
+			// 100 tokens per small file; extraction stops at the file that
+			// would exceed the budget
+			name:     "token budget",
+			modify:   func(sdk *Config) { sdk.MaxTotalTokens = 350 },
+			expected: []string{"client.go", "internal/testdata.go", "transport.go"},
+		},
+		{
+			name: "key files count toward the budget",
+			modify: func(sdk *Config) {
+				sdk.KeyFiles = []string{"transport.go"}
+				sdk.MaxTotalTokens = 250
+			},
+			expected: []string{"client.go", "transport.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := base
+			if tt.modify != nil {
+				tt.modify(&sdk)
+			}
+
+			codeFiles, _, err := a.extractCodeFiles(context.Background(), repoPath, sdk)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(codeFiles))
+			for name := range codeFiles {
+				names = append(names, filepath.ToSlash(name))
+			}
+			sort.Strings(names)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
+
+func TestReadChangedFilesLimits(t *testing.T) {
+	repoPath := t.TempDir()
+	changed := []string{"client.go", "client_test.go", "transport.go", "zz_large.go", "internal/testdata.go", "testdata/fixture.go"}
+	sizes := []int{400, 400, 400, 2000, 400, 400}
+	for i, name := range changed {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(strings.Repeat("a", sizes[i])), 0644))
+	}
+
+	logger := zerolog.Nop()
+	a := &Analyzer{claude: analyzer.NewMockAnalyzer(logger), logger: logger}
+	base := Config{Name: "sentry-mock", Patterns: []string{"*.go"}}
+
+	// Changed files are read in the order they are listed, under the same
+	// limits as a full analysis
+	tests := []struct {
+		name     string
+		modify   func(sdk *Config)
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"client.go", "client_test.go", "internal/testdata.go", "testdata/fixture.go", "transport.go", "zz_large.go"},
+		},
+		{
+			name:     "exclude patterns",
+			modify:   func(sdk *Config) { sdk.ExcludePatterns = []string{"*_test.go", "testdata"} },
+			expected: []string{"client.go", "internal/testdata.go", "transport.go", "zz_large.go"},
+		},
+		{
+			name:     "max file size",
+			modify:   func(sdk *Config) { sdk.MaxFileSize = 1000 },
+			expected: []string{"client.go", "client_test.go", "internal/testdata.go", "testdata/fixture.go", "transport.go"},
+		},
+		{
+			name:     "max files",
+			modify:   func(sdk *Config) { sdk.MaxFilesPerSDK = 2 },
+			expected: []string{"client.go", "client_test.go"},
+		},
+		{
+			name:     "token budget",
+			modify:   func(sdk *Config) { sdk.MaxTotalTokens = 350 },
+			expected: []string{"client.go", "client_test.go", "transport.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := base
+			if tt.modify != nil {
+				tt.modify(&sdk)
+			}
+
+			codeFiles := a.readChangedFiles(context.Background(), repoPath, sdk, changed)
+
+			names := make([]string, 0, len(codeFiles))
+			for name := range codeFiles {
+				names = append(names, filepath.ToSlash(name))
+			}
+			sort.Strings(names)
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
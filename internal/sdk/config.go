@@ -3,19 +3,101 @@ package sdk
 import (
 	_ "embed"
 	"fmt"
+	"sync"
 
+	"github.com/go-git/go-git/v5/plumbing"
 	"gopkg.in/yaml.v3"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
 )
 
 // Config represents an SDK configuration
 type Config struct {
-	Name     string   `yaml:"name"`
-	URL      string   `yaml:"url"`
-	Language string   `yaml:"language"`
-	Patterns []string `yaml:"patterns"`
-	KeyFiles []string `yaml:"key_files,omitempty"`
-	Branch   string   `yaml:"branch,omitempty"`
-	Active   bool     `yaml:"active"`
+	Name     string   `yaml:"name" json:"name"`
+	URL      string   `yaml:"url" json:"url"`
+	Language string   `yaml:"language" json:"language"`
+	Patterns []string `yaml:"patterns" json:"patterns"`
+	KeyFiles []string `yaml:"key_files,omitempty" json:"key_files,omitempty"`
+	Branch   string   `yaml:"branch,omitempty" json:"branch,omitempty"`
+	Active   bool     `yaml:"active" json:"active"`
+
+	// IsPrivate marks repositories that need GITHUB_TOKEN to clone; without
+	// it they are skipped
+	IsPrivate bool `yaml:"is_private,omitempty" json:"is_private,omitempty"`
+
+	// EstimatedSize is the expected clone size in bytes; when zero it is
+	// looked up from the GitHub API
+	EstimatedSize int64 `yaml:"estimated_size,omitempty" json:"estimated_size,omitempty"`
+
+	// Priority orders analyses, higher first, and reserves worker slots
+	// for high-priority SDKs; zero means PriorityNormal
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+
+	// MaxFileSize skips files larger than this many bytes, MaxFilesPerSDK
+	// bounds the files extracted, and MaxTotalTokens bounds their combined
+	// estimated tokens. Zero uses the analyzer defaults.
+	MaxFileSize    int64 `yaml:"max_file_size,omitempty" json:"max_file_size,omitempty"`
+	MaxFilesPerSDK int   `yaml:"max_files,omitempty" json:"max_files,omitempty"`
+	MaxTotalTokens int   `yaml:"max_total_tokens,omitempty" json:"max_total_tokens,omitempty"`
+
+	// ExcludePatterns are globs, such as *_test.go, for files and
+	// directories to skip. They match the base name or the path relative
+	// to the repository root.
+	ExcludePatterns []string `yaml:"exclude_patterns,omitempty" json:"exclude_patterns,omitempty"`
+
+	// SkipLFSFiles skips Git LFS pointer files, whose content is stored
+	// outside the repository; nil skips them too
+	SkipLFSFiles *bool `yaml:"skip_lfs_files,omitempty" json:"skip_lfs_files,omitempty"`
+
+	// Schedule is a cron spec, such as "0 3 * * 1" for Mondays at 03:00,
+	// on which the SDK is analyzed instead of with scheduled updates. A
+	// leading seconds field is allowed. Empty uses UPDATE_SCHEDULE.
+	Schedule string `yaml:"schedule,omitempty" json:"schedule,omitempty"`
+}
+
+// SDK analysis priorities
+const (
+	PriorityLow    = 1
+	PriorityNormal = 2
+	PriorityHigh   = 3
+)
+
+// EffectivePriority returns the SDK priority, defaulting to PriorityNormal
+// when unset or out of range.
+func (c Config) EffectivePriority() int {
+	if c.Priority < PriorityLow || c.Priority > PriorityHigh {
+		return PriorityNormal
+	}
+	return c.Priority
+}
+
+// fileSizeLimit returns MaxFileSize, defaulting to defaultMaxFileSize.
+func (c Config) fileSizeLimit() int64 {
+	if c.MaxFileSize <= 0 {
+		return defaultMaxFileSize
+	}
+	return c.MaxFileSize
+}
+
+// fileLimit returns MaxFilesPerSDK, defaulting to maxExtractedFiles.
+func (c Config) fileLimit() int {
+	if c.MaxFilesPerSDK <= 0 {
+		return maxExtractedFiles
+	}
+	return c.MaxFilesPerSDK
+}
+
+// tokenBudget returns MaxTotalTokens, defaulting to defaultMaxTotalTokens.
+func (c Config) tokenBudget() int {
+	if c.MaxTotalTokens <= 0 {
+		return defaultMaxTotalTokens
+	}
+	return c.MaxTotalTokens
+}
+
+// skipsLFSFiles returns SkipLFSFiles, defaulting to true.
+func (c Config) skipsLFSFiles() bool {
+	return c.SkipLFSFiles == nil || *c.SkipLFSFiles
 }
 
 // ConfigList represents the list of all SDK configurations
@@ -26,8 +108,44 @@ type ConfigList struct {
 //go:embed sdks.yaml
 var sdksYAML string
 
-// LoadConfigs loads the SDK configurations from the embedded YAML
+// ErrUnknownSDK matches the *apperrors.SDKNotFoundError returned for SDK
+// names that are not configured.
+var ErrUnknownSDK = apperrors.ErrSDKNotFound
+
+// activeOverrides replaces the Active setting of SDKs toggled at runtime.
+// Overrides are not persisted, so they last until the process exits.
+var activeOverrides = struct {
+	mu     sync.RWMutex
+	active map[string]bool
+}{active: make(map[string]bool)}
+
+// scheduleOverrides replaces the Schedule of SDKs rescheduled at runtime.
+var scheduleOverrides = struct {
+	mu       sync.RWMutex
+	schedule map[string]string
+}{schedule: make(map[string]string)}
+
+// LoadConfigs loads the SDK configurations of the default registry, with
+// SetActive and SetSchedule overrides applied
 func LoadConfigs() (*ConfigList, error) {
+	configs, err := parseConfigs()
+	if err != nil {
+		return nil, err
+	}
+	for i := range configs.SDKs {
+		configs.SDKs[i].Active = configs.SDKs[i].isActive()
+		configs.SDKs[i].Schedule = configs.SDKs[i].schedule()
+	}
+	return configs, nil
+}
+
+// parseConfigs returns the SDKs of the default registry as written
+func parseConfigs() (*ConfigList, error) {
+	return DefaultRegistry().Configs()
+}
+
+// parseEmbedded parses the embedded YAML as written
+func parseEmbedded() (*ConfigList, error) {
 	var configs ConfigList
 	if err := yaml.Unmarshal([]byte(sdksYAML), &configs); err != nil {
 		return nil, fmt.Errorf("failed to parse SDK configs: %w", err)
@@ -35,17 +153,146 @@ func LoadConfigs() (*ConfigList, error) {
 	return &configs, nil
 }
 
+// SetActive enables or disables a configured SDK until the process exits,
+// and returns whether it was active before.
+func SetActive(name string, active bool) (bool, error) {
+	configs, err := parseConfigs()
+	if err != nil {
+		return false, err
+	}
+	sdk, ok := configs.FindSDK(name)
+	if !ok {
+		return false, &apperrors.SDKNotFoundError{Name: name}
+	}
+
+	activeOverrides.mu.Lock()
+	defer activeOverrides.mu.Unlock()
+
+	previous, overridden := activeOverrides.active[name]
+	if !overridden {
+		previous = sdk.Active
+	}
+	activeOverrides.active[name] = active
+	return previous, nil
+}
+
+// SetSchedule replaces the Schedule of a configured SDK until the process
+// exits, and returns the schedule it had before. An empty schedule returns
+// the SDK to scheduled updates. The spec is not checked here; the worker
+// reports invalid schedules when it registers them.
+func SetSchedule(name, schedule string) (string, error) {
+	configs, err := parseConfigs()
+	if err != nil {
+		return "", err
+	}
+	sdk, ok := configs.FindSDK(name)
+	if !ok {
+		return "", &apperrors.SDKNotFoundError{Name: name}
+	}
+
+	scheduleOverrides.mu.Lock()
+	defer scheduleOverrides.mu.Unlock()
+
+	previous, overridden := scheduleOverrides.schedule[name]
+	if !overridden {
+		previous = sdk.Schedule
+	}
+	scheduleOverrides.schedule[name] = schedule
+	return previous, nil
+}
+
+// clearOverrides drops the SetActive and SetSchedule overrides of an SDK,
+// so one registered again under its name starts from its own settings.
+func clearOverrides(name string) {
+	activeOverrides.mu.Lock()
+	delete(activeOverrides.active, name)
+	activeOverrides.mu.Unlock()
+
+	scheduleOverrides.mu.Lock()
+	delete(scheduleOverrides.schedule, name)
+	scheduleOverrides.mu.Unlock()
+}
+
+// schedule returns the SDK's schedule, honouring SetSchedule
+func (c Config) schedule() string {
+	scheduleOverrides.mu.RLock()
+	defer scheduleOverrides.mu.RUnlock()
+
+	if schedule, ok := scheduleOverrides.schedule[c.Name]; ok {
+		return schedule
+	}
+	return c.Schedule
+}
+
+// isActive reports whether the SDK is active, honouring SetActive
+func (c Config) isActive() bool {
+	activeOverrides.mu.RLock()
+	defer activeOverrides.mu.RUnlock()
+
+	if active, ok := activeOverrides.active[c.Name]; ok {
+		return active
+	}
+	return c.Active
+}
+
 // GetActiveSDKs returns only the active SDK configurations
 func (c *ConfigList) GetActiveSDKs() []Config {
 	var active []Config
 	for _, sdk := range c.SDKs {
-		if sdk.Active {
+		if sdk.isActive() {
+			sdk.Active = true
+			sdk.Schedule = sdk.schedule()
 			active = append(active, sdk)
 		}
 	}
 	return active
 }
 
+// Validate checks every SDK configuration and returns all the problems
+// found, each wrapping ErrInvalidConfig, or nil if there are none. SDKs need
+// a unique name, a repository URL, a language and at least one pattern; a
+// branch, if set, must be a valid branch name.
+func (c *ConfigList) Validate() []error {
+	var errs []error
+	seen := make(map[string]bool, len(c.SDKs))
+	for i, cfg := range c.SDKs {
+		label := cfg.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		invalid := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("%w: SDK %s: %s", ErrInvalidConfig, label, fmt.Sprintf(format, args...)))
+		}
+
+		switch {
+		case cfg.Name == "":
+			invalid("name is required")
+		case seen[cfg.Name]:
+			invalid("name is used by more than one SDK")
+		}
+		seen[cfg.Name] = true
+
+		switch {
+		case cfg.URL == "":
+			invalid("url is required")
+		case !validRepoURL(cfg.URL):
+			invalid("url %q is not an http(s), ssh or git@host:path repository URL", cfg.URL)
+		}
+		if cfg.Language == "" {
+			invalid("language is required")
+		}
+		if len(cfg.Patterns) == 0 {
+			invalid("at least one pattern is required")
+		}
+		if cfg.Branch != "" {
+			if err := plumbing.NewBranchReferenceName(cfg.Branch).Validate(); err != nil {
+				invalid("branch %q is not a valid branch name", cfg.Branch)
+			}
+		}
+	}
+	return errs
+}
+
 // FindSDK finds an SDK configuration by name
 func (c *ConfigList) FindSDK(name string) (*Config, bool) {
 	for _, sdk := range c.SDKs {
@@ -1,10 +1,13 @@
 package sdk
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
 )
 
 func TestLoadConfigs(t *testing.T) {
@@ -24,10 +27,12 @@ func TestLoadConfigs(t *testing.T) {
 			foundGo = true
 			assert.Equal(t, "go", sdk.Language)
 			assert.Contains(t, sdk.Patterns, "*.go")
+			assert.Equal(t, PriorityHigh, sdk.EffectivePriority())
 		case "sentry-python":
 			foundPython = true
 			assert.Equal(t, "python", sdk.Language)
 			assert.Contains(t, sdk.Patterns, "*.py")
+			assert.Equal(t, PriorityNormal, sdk.EffectivePriority())
 		case "sentry-javascript":
 			foundJS = true
 			assert.Equal(t, "javascript", sdk.Language)
@@ -67,3 +72,142 @@ func TestFindSDK(t *testing.T) {
 	assert.False(t, found)
 	assert.Nil(t, sdk)
 }
+
+func TestEffectivePriority(t *testing.T) {
+	tests := []struct {
+		priority int
+		expected int
+	}{
+		{0, PriorityNormal},
+		{PriorityLow, PriorityLow},
+		{PriorityNormal, PriorityNormal},
+		{PriorityHigh, PriorityHigh},
+		{7, PriorityNormal},
+		{-1, PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Config{Priority: tt.priority}.EffectivePriority(), "priority %d", tt.priority)
+	}
+}
+
+func TestSetActive(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := SetActive("sentry-python", true)
+		require.NoError(t, err)
+	})
+
+	previous, err := SetActive("sentry-python", false)
+	require.NoError(t, err)
+	assert.True(t, previous)
+
+	configs, err := LoadConfigs()
+	require.NoError(t, err)
+	python, found := configs.FindSDK("sentry-python")
+	require.True(t, found)
+	assert.False(t, python.Active)
+	for _, sdk := range configs.GetActiveSDKs() {
+		assert.NotEqual(t, "sentry-python", sdk.Name)
+	}
+
+	// Lists built before the toggle honour it too
+	stale := &ConfigList{SDKs: []Config{{Name: "sentry-python", Active: true}}}
+	assert.Empty(t, stale.GetActiveSDKs())
+
+	previous, err = SetActive("sentry-python", true)
+	require.NoError(t, err)
+	assert.False(t, previous)
+
+	_, err = SetActive("sentry-cobol", false)
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+
+	var notFound *apperrors.SDKNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "sentry-cobol", notFound.Name)
+}
+
+func TestSetSchedule(t *testing.T) {
+	previous, err := SetSchedule("sentry-go", "0 3 * * 1")
+	require.NoError(t, err)
+	assert.Empty(t, previous)
+	t.Cleanup(func() {
+		_, err := SetSchedule("sentry-go", "")
+		require.NoError(t, err)
+	})
+
+	configs, err := LoadConfigs()
+	require.NoError(t, err)
+	goSDK, ok := configs.FindSDK("sentry-go")
+	require.True(t, ok)
+	assert.Equal(t, "0 3 * * 1", goSDK.Schedule)
+
+	previous, err = SetSchedule("sentry-go", "")
+	require.NoError(t, err)
+	assert.Equal(t, "0 3 * * 1", previous)
+
+	_, err = SetSchedule("sentry-cobol", "* * * * *")
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+}
+
+func TestValidateEmbeddedConfigs(t *testing.T) {
+	configs, err := parseEmbedded()
+	require.NoError(t, err)
+	assert.Empty(t, configs.Validate())
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		message string
+	}{
+		{"empty name", func(c *Config) { c.Name = "" }, "SDK #1: name is required"},
+		{"empty url", func(c *Config) { c.URL = "" }, "url is required"},
+		{"unparsable url", func(c *Config) { c.URL = "https://github.com/%zz" }, "is not an http(s), ssh or git@host:path repository URL"},
+		{"relative url", func(c *Config) { c.URL = "getsentry/sentry-crystal" }, "is not an http(s), ssh or git@host:path repository URL"},
+		{"empty language", func(c *Config) { c.Language = "" }, "language is required"},
+		{"no patterns", func(c *Config) { c.Patterns = nil }, "at least one pattern is required"},
+		{"branch with space", func(c *Config) { c.Branch = "release 1.0" }, `branch "release 1.0" is not a valid branch name`},
+		{"branch with double dot", func(c *Config) { c.Branch = "main..next" }, "is not a valid branch name"},
+		{"branch ending with slash", func(c *Config) { c.Branch = "feature/" }, "is not a valid branch name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRegistryConfig()
+			tt.modify(&cfg)
+			configs := &ConfigList{SDKs: []Config{cfg}}
+
+			errs := configs.Validate()
+			require.Len(t, errs, 1)
+			assert.ErrorIs(t, errs[0], ErrInvalidConfig)
+			assert.Contains(t, errs[0].Error(), tt.message)
+		})
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		cfg := testRegistryConfig()
+		cfg.Branch = "release/1.x"
+		scp := testRegistryConfig()
+		scp.Name = "sentry-crystal-mirror"
+		scp.URL = "git@github.com:getsentry/sentry-crystal.git"
+		configs := &ConfigList{SDKs: []Config{cfg, scp}}
+		assert.Empty(t, configs.Validate())
+	})
+
+	t.Run("duplicate names", func(t *testing.T) {
+		configs := &ConfigList{SDKs: []Config{testRegistryConfig(), testRegistryConfig()}}
+		errs := configs.Validate()
+		require.Len(t, errs, 1)
+		assert.Contains(t, errs[0].Error(), "SDK sentry-crystal: name is used by more than one SDK")
+	})
+
+	t.Run("all problems reported", func(t *testing.T) {
+		broken := Config{Name: "sentry-broken", URL: "not a url", Branch: "a..b"}
+		configs := &ConfigList{SDKs: []Config{testRegistryConfig(), broken, {}}}
+		errs := configs.Validate()
+		// sentry-broken: url, language, patterns and branch; #3: name, url,
+		// language and patterns
+		assert.Len(t, errs, 8)
+	})
+}
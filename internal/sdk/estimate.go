@@ -0,0 +1,57 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// InputEstimate describes what a full analysis of an SDK at its current
+// HEAD would send to the analyzer.
+type InputEstimate struct {
+	Version    string
+	Files      int
+	TotalBytes int64
+	Passes     int
+	Tokens     int
+}
+
+// EstimateInput clones or updates the SDK repository, extracts the files a
+// full analysis would send, and counts their input tokens without analyzing
+// them. SDKs above the multi-pass threshold are counted pass by pass, since
+// each pass repeats the prompt.
+func (a *Analyzer) EstimateInput(ctx context.Context, sdk Config) (*InputEstimate, error) {
+	repoPath, err := a.cloneRepo(ctx, sdk)
+	if err != nil {
+		return nil, err
+	}
+
+	request, _, err := a.buildRequest(ctx, sdk, repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &InputEstimate{
+		Version: request.Version,
+		Files:   len(request.Code),
+	}
+	for _, content := range request.Code {
+		estimate.TotalBytes += int64(len(content))
+	}
+
+	passes := []map[string]string{request.Code}
+	if a.needsMultiPass(request) {
+		passes = splitPasses(request.Code, sdk.KeyFiles, a.maxFilesPerPass)
+	}
+	for i, files := range passes {
+		passRequest := request
+		passRequest.Code = files
+
+		tokens, err := a.claude.CountTokens(ctx, passRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count tokens (pass %d/%d): %w", i+1, len(passes), err)
+		}
+		estimate.Tokens += tokens
+	}
+	estimate.Passes = len(passes)
+	return estimate, nil
+}
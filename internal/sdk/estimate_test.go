@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+)
+
+// fixedTokenAnalyzer counts the same number of tokens for every request.
+type fixedTokenAnalyzer struct {
+	recordingAnalyzer
+	tokens int
+}
+
+func (f *fixedTokenAnalyzer) CountTokens(ctx context.Context, request analyzer.AnalysisRequest) (int, error) {
+	return f.tokens, nil
+}
+
+func TestEstimateInput(t *testing.T) {
+	repoPath, _ := createMockSDK(t, 120)
+	sdk := Config{
+		Name:     "sentry-mock",
+		URL:      repoPath,
+		Patterns: []string{"*.go"},
+		Branch:   "master",
+	}
+
+	logger := zerolog.Nop()
+	counter := &fixedTokenAnalyzer{tokens: 2500}
+	a, err := NewAnalyzer(git.NewClient(t.TempDir(), logger), counter, nil, logger)
+	require.NoError(t, err)
+
+	// One pass below the multi-pass threshold
+	a.SetMultiPass(200, 50)
+	estimate, err := a.EstimateInput(context.Background(), sdk)
+	require.NoError(t, err)
+	assert.Equal(t, 120, estimate.Files)
+	assert.Equal(t, int64(120*len("package pkg\n")), estimate.TotalBytes)
+	assert.Equal(t, 1, estimate.Passes)
+	assert.Equal(t, 2500, estimate.Tokens)
+	assert.Len(t, estimate.Version, 7)
+
+	// Above it, every pass is counted
+	a.SetMultiPass(100, 50)
+	estimate, err = a.EstimateInput(context.Background(), sdk)
+	require.NoError(t, err)
+	assert.Equal(t, 3, estimate.Passes)
+	assert.Equal(t, 7500, estimate.Tokens)
+
+	// Nothing was analyzed
+	assert.Empty(t, counter.passes)
+}
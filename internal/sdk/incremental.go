@@ -0,0 +1,201 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+// skippedDirs are directories whose files are never analyzed
+var skippedDirs = map[string]bool{
+	".git":          true,
+	"node_modules":  true,
+	"vendor":        true,
+	"__pycache__":   true,
+	".pytest_cache": true,
+}
+
+// SetIncrementalThreshold sets the number of changed files below which an
+// SDK with a cached analysis is analyzed incrementally. Non-positive values
+// disable incremental analysis.
+func (a *Analyzer) SetIncrementalThreshold(n int) {
+	a.incrementalThreshold = n
+}
+
+// analyzeIncrementally updates the SDK's cached analysis from the files
+// changed since it was made. It reports false, without error, when the SDK
+// has to be analyzed in full: there is no cached analysis, the changes
+// cannot be listed, or too many files changed.
+func (a *Analyzer) analyzeIncrementally(ctx context.Context, sdk Config, repoPath string) (*analyzer.SDKAnalysis, bool, error) {
+	if a.incrementalThreshold <= 0 || a.cache == nil {
+		return nil, false, nil
+	}
+
+	previous, lastAnalyzed, ok := a.previousAnalysis(sdk.Name)
+	if !ok {
+		return nil, false, nil
+	}
+
+	changed, err := a.git.GetChangedFiles(ctx, repoPath, lastAnalyzed)
+	if err != nil {
+		a.logger.Warn().
+			Err(err).
+			Str("sdk", sdk.Name).
+			Msg("Failed to list changed files, analyzing in full")
+		return nil, false, nil
+	}
+	if len(changed) >= a.incrementalThreshold {
+		return nil, false, nil
+	}
+
+	latestCommit, err := a.git.GetLatestCommit(ctx, repoPath)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to get latest commit: %w", err)
+	}
+	version := a.sdkVersion(ctx, sdk, repoPath, latestCommit)
+
+	codeFiles := a.readChangedFiles(ctx, repoPath, sdk, changed)
+	if len(codeFiles) == 0 {
+		// None of the analyzed files changed, so the analysis still holds
+		analysis := *previous
+		analysis.TokensUsed = 0
+		analysis.TokensSavedByCache = 0
+		analysis.AnalyzedAt = time.Now()
+		analysis.SDKVersion = version
+
+		a.logger.Info().
+			Str("sdk", sdk.Name).
+			Int("changed_files", len(changed)).
+			Msg("No analyzed files changed, keeping previous analysis")
+		return &analysis, true, nil
+	}
+
+	a.logger.Info().
+		Str("sdk", sdk.Name).
+		Int("files", len(codeFiles)).
+		Msg("Analyzing changed files")
+
+	update, err := a.claude.AnalyzeCode(ctx, analyzer.AnalysisRequest{
+		SDKName:    sdk.Name,
+		Version:    version,
+		Code:       codeFiles,
+		CommitHash: latestCommit.Hash,
+		Previous:   previous,
+	})
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to analyze SDK changes: %w", err)
+	}
+
+	// The update's fields take precedence; lists keep previous entries too
+	analysis := analyzer.MergeAnalyses(update, previous)
+	analysis.TokensUsed = update.TokensUsed
+	analysis.TokensSavedByCache = update.TokensSavedByCache
+	analysis.PassCount = previous.PassCount
+	analysis.ValidationErrors = update.ValidationErrors
+	analysis.SDKVersion = version
+	// Only changed files were read, so the full analysis' count stands
+	analysis.LFSFilesSkipped = previous.LFSFilesSkipped
+
+	a.logger.Info().
+		Str("sdk", sdk.Name).
+		Int("tokens_used", analysis.TokensUsed).
+		Msg("Incremental SDK analysis completed")
+
+	return analysis, true, nil
+}
+
+// previousAnalysis returns the SDK's cached analysis and when it was made.
+func (a *Analyzer) previousAnalysis(sdkName string) (*analyzer.SDKAnalysis, time.Time, bool) {
+	lastAnalyzedStr, err := a.cache.Get(fmt.Sprintf("sdk:%s:last_analyzed", sdkName))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	lastAnalyzed, err := time.Parse(time.RFC3339, lastAnalyzedStr)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	analysisJSON, err := a.cache.Get(fmt.Sprintf("sdk:%s", sdkName))
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var previous analyzer.SDKAnalysis
+	if err := json.Unmarshal([]byte(analysisJSON), &previous); err != nil {
+		a.logger.Warn().
+			Err(err).
+			Str("sdk", sdkName).
+			Msg("Failed to decode cached analysis, analyzing in full")
+		return nil, time.Time{}, false
+	}
+
+	return &previous, lastAnalyzed, true
+}
+
+// readChangedFiles reads the changed files that a full analysis would
+// include, within the same file count and token limits. Deleted files and
+// Git LFS pointers are skipped.
+func (a *Analyzer) readChangedFiles(ctx context.Context, repoPath string, sdk Config, changed []string) map[string]string {
+	codeFiles := make(map[string]string)
+	budget := newTokenBudget(a, sdk)
+	for _, relPath := range changed {
+		if inSkippedDir(relPath) || !matchesPatterns(sdk.Patterns, relPath) || inExcludedPath(sdk.ExcludePatterns, relPath) {
+			continue
+		}
+
+		path := filepath.Join(repoPath, relPath)
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() || info.Size() > sdk.fileSizeLimit() {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			a.logger.Warn().
+				Err(err).
+				Str("file", relPath).
+				Msg("Failed to read changed file")
+			continue
+		}
+		if a.skipLFSPointer(sdk, relPath, content) {
+			continue
+		}
+		if len(codeFiles) >= sdk.fileLimit() || !budget.add(ctx, relPath, string(content)) {
+			a.logger.Info().
+				Str("sdk", sdk.Name).
+				Int("files", len(codeFiles)).
+				Msg("Changed files exceed the SDK limits, leaving out the rest")
+			break
+		}
+		codeFiles[relPath] = string(content)
+	}
+	return codeFiles
+}
+
+// inExcludedPath reports whether relPath or one of its parent directories
+// matches an exclude pattern, as a repository walk would find.
+func inExcludedPath(patterns []string, relPath string) bool {
+	for p := filepath.ToSlash(relPath); p != "." && p != "/"; p = path.Dir(p) {
+		if isExcluded(patterns, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// inSkippedDir reports whether a repository-relative path lies in one of
+// skippedDirs.
+func inSkippedDir(relPath string) bool {
+	for _, dir := range strings.Split(filepath.Dir(filepath.ToSlash(relPath)), "/") {
+		if skippedDirs[dir] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package sdk
+
+import "container/heap"
+
+// queuedSDK is an SDK waiting in an SDKPriorityQueue.
+type queuedSDK struct {
+	sdk   Config
+	index int
+
+	// force is the SDK's position in the forced list plus one, or zero if
+	// it was not forced
+	force int
+}
+
+// SDKPriorityQueue orders SDKs for analysis. Forced SDKs come first, in the
+// order they were forced, followed by the others in descending
+// EffectivePriority; SDKs of equal priority keep their declaration order.
+// It implements heap.Interface for container/heap; callers use Next.
+type SDKPriorityQueue struct {
+	items []queuedSDK
+}
+
+// NewSDKPriorityQueue queues sdks, moving the SDKs named in force ahead of
+// all others. Names in force that match no SDK are ignored.
+func NewSDKPriorityQueue(sdks []Config, force []string) *SDKPriorityQueue {
+	forced := make(map[string]int, len(force))
+	for i, name := range force {
+		if _, ok := forced[name]; !ok {
+			forced[name] = i + 1
+		}
+	}
+
+	q := &SDKPriorityQueue{items: make([]queuedSDK, 0, len(sdks))}
+	for i, sdk := range sdks {
+		q.items = append(q.items, queuedSDK{sdk: sdk, index: i, force: forced[sdk.Name]})
+	}
+	heap.Init(q)
+	return q
+}
+
+// Next removes and returns the SDK to analyze next, along with its index in
+// the slice the queue was created from; ok is false once the queue is
+// empty.
+func (q *SDKPriorityQueue) Next() (sdk Config, index int, ok bool) {
+	if q.Len() == 0 {
+		return Config{}, 0, false
+	}
+	item := heap.Pop(q).(queuedSDK)
+	return item.sdk, item.index, true
+}
+
+// Len implements heap.Interface.
+func (q *SDKPriorityQueue) Len() int { return len(q.items) }
+
+// Less implements heap.Interface.
+func (q *SDKPriorityQueue) Less(i, j int) bool {
+	a, b := q.items[i], q.items[j]
+	if a.force != b.force {
+		if a.force == 0 || b.force == 0 {
+			return a.force != 0
+		}
+		return a.force < b.force
+	}
+	if pa, pb := a.sdk.EffectivePriority(), b.sdk.EffectivePriority(); pa != pb {
+		return pa > pb
+	}
+	return a.index < b.index
+}
+
+// Swap implements heap.Interface.
+func (q *SDKPriorityQueue) Swap(i, j int) { q.items[i], q.items[j] = q.items[j], q.items[i] }
+
+// Push implements heap.Interface; x must be a queuedSDK.
+func (q *SDKPriorityQueue) Push(x any) { q.items = append(q.items, x.(queuedSDK)) }
+
+// Pop implements heap.Interface.
+func (q *SDKPriorityQueue) Pop() any {
+	last := len(q.items) - 1
+	item := q.items[last]
+	q.items = q.items[:last]
+	return item
+}
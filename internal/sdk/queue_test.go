@@ -0,0 +1,73 @@
+package sdk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// drain returns the names of the SDKs in q in the order Next returns them.
+func drain(q *SDKPriorityQueue) []string {
+	var names []string
+	for {
+		sdk, _, ok := q.Next()
+		if !ok {
+			return names
+		}
+		names = append(names, sdk.Name)
+	}
+}
+
+func TestSDKPriorityQueue(t *testing.T) {
+	sdks := []Config{
+		{Name: "low", Priority: PriorityLow},
+		{Name: "normal-a"},
+		{Name: "high-a", Priority: PriorityHigh},
+		{Name: "normal-b", Priority: PriorityNormal},
+		{Name: "high-b", Priority: PriorityHigh},
+	}
+
+	tests := []struct {
+		name     string
+		force    []string
+		expected []string
+	}{
+		{
+			name:     "by priority",
+			expected: []string{"high-a", "high-b", "normal-a", "normal-b", "low"},
+		},
+		{
+			name:     "forced first",
+			force:    []string{"low", "normal-b", "low"},
+			expected: []string{"low", "normal-b", "high-a", "high-b", "normal-a"},
+		},
+		{
+			name:     "unknown forced names",
+			force:    []string{"missing"},
+			expected: []string{"high-a", "high-b", "normal-a", "normal-b", "low"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, drain(NewSDKPriorityQueue(sdks, tt.force)))
+		})
+	}
+}
+
+func TestSDKPriorityQueueNext(t *testing.T) {
+	q := NewSDKPriorityQueue([]Config{{Name: "normal"}, {Name: "high", Priority: PriorityHigh}}, nil)
+
+	sdk, index, ok := q.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "high", sdk.Name)
+	assert.Equal(t, 1, index)
+
+	sdk, index, ok = q.Next()
+	assert.True(t, ok)
+	assert.Equal(t, "normal", sdk.Name)
+	assert.Equal(t, 0, index)
+
+	_, _, ok = q.Next()
+	assert.False(t, ok)
+}
@@ -0,0 +1,102 @@
+package sdk
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// File priority adjustments used by RankFile
+const (
+	keyFileScore     = 100
+	patternFileScore = 50
+	testFileScore    = -50
+	exampleFileScore = -30
+)
+
+// generatedHeaderLines is how many leading lines are searched for a
+// "// Code generated" marker
+const generatedHeaderLines = 5
+
+// RankFile scores how useful a file is for analyzing sdk. Key files and
+// files matching the SDK patterns rank higher; tests and examples rank
+// lower. Generated files can only be recognized from their content, so
+// extractCodeFiles demotes them once it has read them.
+func RankFile(filePath string, sdk Config) int {
+	filePath = filepath.ToSlash(filePath)
+	score := 0
+
+	for _, keyFile := range sdk.KeyFiles {
+		if filepath.ToSlash(keyFile) == filePath {
+			score += keyFileScore
+			break
+		}
+	}
+
+	base := path.Base(filePath)
+	for _, pattern := range sdk.Patterns {
+		if matched, err := filepath.Match(pattern, base); err == nil && matched {
+			score += patternFileScore
+			break
+		}
+	}
+
+	if isTestFile(filePath) {
+		score += testFileScore
+	}
+	if isExampleFile(filePath) {
+		score += exampleFileScore
+	}
+	return score
+}
+
+// estimateTokens approximates the token count of content at 4 characters
+// per token, matching the analyzers' CountTokens.
+func estimateTokens(content string) int {
+	return len(content) / 4
+}
+
+// isGenerated reports whether content starts with a "// Code generated"
+// header.
+func isGenerated(content string) bool {
+	lines := strings.SplitN(content, "\n", generatedHeaderLines+1)
+	for _, line := range lines[:min(len(lines), generatedHeaderLines)] {
+		if strings.HasPrefix(strings.TrimSpace(line), "// Code generated") {
+			return true
+		}
+	}
+	return false
+}
+
+func isTestFile(filePath string) bool {
+	if hasDir(filePath, "test", "tests", "__tests__", "spec", "testdata") {
+		return true
+	}
+
+	base := path.Base(filePath)
+	name := strings.TrimSuffix(base, path.Ext(base))
+	return strings.HasSuffix(name, "_test") ||
+		strings.HasSuffix(name, ".test") ||
+		strings.HasSuffix(name, ".spec") ||
+		strings.HasPrefix(name, "test_")
+}
+
+func isExampleFile(filePath string) bool {
+	if hasDir(filePath, "example", "examples", "samples") {
+		return true
+	}
+	return strings.HasPrefix(path.Base(filePath), "example_")
+}
+
+// hasDir reports whether any directory in filePath is one of names.
+func hasDir(filePath string, names ...string) bool {
+	dirs := strings.Split(path.Dir(filePath), "/")
+	for _, dir := range dirs {
+		for _, name := range names {
+			if dir == name {
+				return true
+			}
+		}
+	}
+	return false
+}
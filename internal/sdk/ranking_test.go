@@ -0,0 +1,147 @@
+package sdk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+)
+
+var rankingSDK = Config{
+	Name:     "sentry-go",
+	Language: "go",
+	Patterns: []string{"*.go"},
+	KeyFiles: []string{"client.go", "transport.go"},
+}
+
+func TestRankFile(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected int
+	}{
+		{"transport.go", 150},
+		{"envelope.go", 50},
+		{"integrations/http/handler.go", 50},
+		{"client_test.go", 0},
+		{"internal/testdata/fixture.go", 0},
+		{"examples/basic/main.go", 20},
+		{"example_usage.go", 20},
+		{"README.md", 0},
+		{"__tests__/client.spec.ts", -50},
+		{"sentry_sdk/test_transport.py", -50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RankFile(tt.path, rankingSDK))
+		})
+	}
+}
+
+func TestIsGenerated(t *testing.T) {
+	assert.True(t, isGenerated("// Code generated by protoc-gen-go. DO NOT EDIT.\npackage proto\n"))
+	assert.True(t, isGenerated("// Copyright 2024\n\n// Code generated by stringer; DO NOT EDIT.\n"))
+	assert.False(t, isGenerated("package sentry\n\nimport (\n\t\"fmt\"\n)\n\n// Code generated below is checked in\n"))
+	assert.False(t, isGenerated("package sentry\n"))
+}
+
+func TestExtractCodeFilesRanking(t *testing.T) {
+	// Production files and examples are 100 tokens each, tests only 10
+	source := strings.Repeat("x", 400)
+	small := strings.Repeat("t", 40)
+	generated := "// Code generated by protoc-gen-go. DO NOT EDIT.\n" + source
+
+	production := []string{
+		"client.go", "transport.go", "envelope.go", "scope.go", "hub.go",
+		"span.go", "tracing.go", "dsn.go", "event.go", "integrations/http.go",
+	}
+	examples := []string{"examples/basic/main.go", "examples/http/main.go"}
+	tests := []string{"client_test.go", "transport_test.go", "hub_test.go", "internal/testdata/fixture.go"}
+	files := map[string]string{
+		"internal/proto/envelope.pb.go": generated,
+		"zz_generated_types.go":         generated,
+		"README.md":                     source,
+		"CHANGELOG.md":                  source,
+	}
+	for _, name := range production {
+		files[name] = source
+	}
+	for _, name := range examples {
+		files[name] = source
+	}
+	for _, name := range tests {
+		files[name] = small
+	}
+
+	repoPath := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(repoPath, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0644))
+	}
+
+	logger := zerolog.Nop()
+	a := &Analyzer{claude: analyzer.NewMockAnalyzer(logger), logger: logger}
+	concat := func(lists ...[]string) []string {
+		var all []string
+		for _, list := range lists {
+			all = append(all, list...)
+		}
+		return all
+	}
+
+	cases := []struct {
+		name     string
+		budget   int
+		maxFiles int
+		expected []string
+	}{
+		{
+			name:     "production files fill the budget",
+			budget:   1000,
+			expected: production,
+		},
+		{
+			name:     "key files first",
+			budget:   200,
+			expected: []string{"client.go", "transport.go"},
+		},
+		{
+			name:     "examples and tests before generated files",
+			budget:   1250,
+			expected: concat(production, examples, tests),
+		},
+		{
+			name:     "file limit leaves out the least useful files",
+			maxFiles: 12,
+			expected: concat(production, examples),
+		},
+		{
+			name:     "generated files last",
+			expected: concat(production, examples, tests, []string{"internal/proto/envelope.pb.go", "zz_generated_types.go"}),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sdk := rankingSDK
+			sdk.MaxTotalTokens = tt.budget
+			sdk.MaxFilesPerSDK = tt.maxFiles
+
+			codeFiles, _, err := a.extractCodeFiles(context.Background(), repoPath, sdk)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(codeFiles))
+			for name := range codeFiles {
+				names = append(names, filepath.ToSlash(name))
+			}
+			assert.ElementsMatch(t, tt.expected, names)
+		})
+	}
+}
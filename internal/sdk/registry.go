@@ -0,0 +1,267 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sync"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+var (
+	// ErrInvalidConfig is returned when registering an SDK whose
+	// configuration is incomplete or malformed.
+	ErrInvalidConfig = errors.New("invalid SDK config")
+
+	// ErrSDKExists is returned when registering an SDK under a name that is
+	// already configured.
+	ErrSDKExists = errors.New("SDK already registered")
+)
+
+// sdkNamePattern matches valid SDK names, such as sentry-go.
+var sdkNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// scpURLPattern matches scp-style git URLs, such as git@github.com:org/repo.git.
+var scpURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[A-Za-z0-9._~/-]+$`)
+
+// Registry holds the SDK configurations: those embedded in sdks.yaml, plus
+// SDKs registered at runtime, minus those unregistered. With a path, the
+// runtime changes are written to that JSON file so they survive restarts.
+type Registry struct {
+	mu        sync.RWMutex
+	path      string
+	overrides registryOverrides
+}
+
+// registryOverrides are the changes a Registry makes to the embedded SDKs,
+// as stored in its file.
+type registryOverrides struct {
+	Registered   []Config `json:"registered,omitempty"`
+	Unregistered []string `json:"unregistered,omitempty"`
+}
+
+// registry is the Registry LoadConfigs reads.
+var registry = struct {
+	mu      sync.RWMutex
+	current *Registry
+}{current: NewRegistry()}
+
+// NewRegistry returns a registry of the embedded SDKs whose changes are
+// kept in memory only.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// OpenRegistry returns a registry persisting its changes to path, starting
+// from the changes already stored there, if any.
+func OpenRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SDK registry: %w", err)
+	}
+	if err := json.Unmarshal(data, &r.overrides); err != nil {
+		return nil, fmt.Errorf("failed to parse SDK registry %s: %w", path, err)
+	}
+	return r, nil
+}
+
+// DefaultRegistry returns the registry LoadConfigs reads.
+func DefaultRegistry() *Registry {
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+	return registry.current
+}
+
+// SetDefaultRegistry makes LoadConfigs read r, typically one opened with
+// OpenRegistry at startup.
+func SetDefaultRegistry(r *Registry) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.current = r
+}
+
+// Configs returns the embedded SDKs merged with the registry's changes, as
+// written: runtime SetActive and SetSchedule overrides are not applied.
+// Registered SDKs follow the embedded ones.
+func (r *Registry) Configs() (*ConfigList, error) {
+	embedded, err := parseEmbedded()
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.merge(embedded), nil
+}
+
+// merge applies the registry's changes to the embedded SDKs. The caller
+// holds r.mu.
+func (r *Registry) merge(embedded *ConfigList) *ConfigList {
+	configs := &ConfigList{SDKs: make([]Config, 0, len(embedded.SDKs)+len(r.overrides.Registered))}
+	for _, cfg := range embedded.SDKs {
+		if slices.Contains(r.overrides.Unregistered, cfg.Name) {
+			continue
+		}
+		configs.SDKs = append(configs.SDKs, cfg)
+	}
+	for _, cfg := range r.overrides.Registered {
+		cfg.Patterns = slices.Clone(cfg.Patterns)
+		cfg.KeyFiles = slices.Clone(cfg.KeyFiles)
+		cfg.ExcludePatterns = slices.Clone(cfg.ExcludePatterns)
+		configs.SDKs = append(configs.SDKs, cfg)
+	}
+	return configs
+}
+
+// Register adds an SDK. It returns ErrInvalidConfig if cfg is incomplete
+// or malformed and ErrSDKExists if its name or repository is taken.
+func (r *Registry) Register(cfg Config) error {
+	if err := validateConfig(cfg); err != nil {
+		return err
+	}
+	embedded, err := parseEmbedded()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	configs := r.merge(embedded)
+	if _, ok := configs.FindSDK(cfg.Name); ok {
+		return fmt.Errorf("%w: %s", ErrSDKExists, cfg.Name)
+	}
+	for _, existing := range configs.SDKs {
+		if existing.URL == cfg.URL {
+			return fmt.Errorf("%w: %s already uses %s", ErrSDKExists, existing.Name, cfg.URL)
+		}
+	}
+
+	// An unregistered embedded SDK stays hidden, so cfg replaces it
+	overrides := r.overrides.clone()
+	overrides.Registered = append(overrides.Registered, cfg)
+	return r.apply(overrides)
+}
+
+// Unregister removes an SDK, whether embedded or registered. Unknown names
+// return a *apperrors.SDKNotFoundError.
+func (r *Registry) Unregister(name string) error {
+	embedded, err := parseEmbedded()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.merge(embedded).FindSDK(name); !ok {
+		return &apperrors.SDKNotFoundError{Name: name}
+	}
+
+	overrides := r.overrides.clone()
+	overrides.Registered = slices.DeleteFunc(overrides.Registered, func(cfg Config) bool {
+		return cfg.Name == name
+	})
+	if _, ok := embedded.FindSDK(name); ok && !slices.Contains(overrides.Unregistered, name) {
+		overrides.Unregistered = append(overrides.Unregistered, name)
+	}
+	if err := r.apply(overrides); err != nil {
+		return err
+	}
+
+	clearOverrides(name)
+	return nil
+}
+
+// apply saves overrides to the registry file, if any, and then makes them
+// current, so a failed write leaves the registry unchanged. The caller
+// holds r.mu.
+func (r *Registry) apply(overrides registryOverrides) error {
+	if r.path != "" {
+		data, err := json.MarshalIndent(overrides, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode SDK registry: %w", err)
+		}
+		if err := writeFileAtomic(r.path, data); err != nil {
+			return fmt.Errorf("failed to save SDK registry: %w", err)
+		}
+	}
+	r.overrides = overrides
+	return nil
+}
+
+func (o registryOverrides) clone() registryOverrides {
+	return registryOverrides{
+		Registered:   slices.Clone(o.Registered),
+		Unregistered: slices.Clone(o.Unregistered),
+	}
+}
+
+// writeFileAtomic writes data to path through a temporary file, so readers
+// never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		return errors.Join(err, tmp.Close(), os.Remove(tmp.Name()))
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Join(err, os.Remove(tmp.Name()))
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Join(err, os.Remove(tmp.Name()))
+	}
+	return nil
+}
+
+// validateConfig checks that an SDK to register has a valid name and
+// repository URL, and the fields an analysis needs.
+func validateConfig(cfg Config) error {
+	switch {
+	case !sdkNamePattern.MatchString(cfg.Name):
+		return fmt.Errorf("%w: name %q must be 1-100 letters, digits, '.', '_' or '-', starting with a letter or digit", ErrInvalidConfig, cfg.Name)
+	case !validRepoURL(cfg.URL):
+		return fmt.Errorf("%w: url %q is not an http(s), ssh or git@host:path repository URL", ErrInvalidConfig, cfg.URL)
+	case cfg.Language == "":
+		return fmt.Errorf("%w: language is required", ErrInvalidConfig)
+	case len(cfg.Patterns) == 0:
+		return fmt.Errorf("%w: at least one pattern is required", ErrInvalidConfig)
+	case cfg.Priority < 0 || cfg.Priority > PriorityHigh:
+		return fmt.Errorf("%w: priority must be between %d and %d", ErrInvalidConfig, PriorityLow, PriorityHigh)
+	}
+	return nil
+}
+
+// validRepoURL reports whether raw is a URL the git client can clone.
+func validRepoURL(raw string) bool {
+	if scpURLPattern.MatchString(raw) {
+		return true
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch u.Scheme {
+	case "https", "http", "ssh", "git":
+	default:
+		return false
+	}
+	return u.Host != "" && len(u.Path) > 1
+}
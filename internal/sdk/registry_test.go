@@ -0,0 +1,150 @@
+package sdk
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+func testRegistryConfig() Config {
+	return Config{
+		Name:     "sentry-crystal",
+		URL:      "https://github.com/getsentry/sentry-crystal",
+		Language: "crystal",
+		Patterns: []string{"src/**/*.cr"},
+		Active:   true,
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	r := NewRegistry()
+
+	require.NoError(t, r.Register(testRegistryConfig()))
+
+	configs, err := r.Configs()
+	require.NoError(t, err)
+	crystal, ok := configs.FindSDK("sentry-crystal")
+	require.True(t, ok)
+	assert.Equal(t, "crystal", crystal.Language)
+	_, ok = configs.FindSDK("sentry-go")
+	assert.True(t, ok, "embedded SDKs are kept")
+
+	// Same name
+	err = r.Register(testRegistryConfig())
+	assert.ErrorIs(t, err, ErrSDKExists)
+
+	// Same repository as an embedded SDK
+	goSDK, ok := configs.FindSDK("sentry-go")
+	require.True(t, ok)
+	dup := testRegistryConfig()
+	dup.Name = "sentry-golang"
+	dup.URL = goSDK.URL
+	err = r.Register(dup)
+	assert.ErrorIs(t, err, ErrSDKExists)
+}
+
+func TestRegistryRegisterInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"empty name", func(c *Config) { c.Name = "" }},
+		{"name with slash", func(c *Config) { c.Name = "sentry/elixir" }},
+		{"relative url", func(c *Config) { c.URL = "getsentry/sentry-crystal" }},
+		{"unsupported scheme", func(c *Config) { c.URL = "ftp://github.com/getsentry/sentry-crystal" }},
+		{"url without path", func(c *Config) { c.URL = "https://github.com" }},
+		{"no language", func(c *Config) { c.Language = "" }},
+		{"no patterns", func(c *Config) { c.Patterns = nil }},
+		{"priority out of range", func(c *Config) { c.Priority = 7 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := testRegistryConfig()
+			tt.modify(&cfg)
+			assert.ErrorIs(t, NewRegistry().Register(cfg), ErrInvalidConfig)
+		})
+	}
+
+	cfg := testRegistryConfig()
+	cfg.URL = "git@github.com:getsentry/sentry-crystal.git"
+	assert.NoError(t, NewRegistry().Register(cfg))
+}
+
+func TestRegistryUnregister(t *testing.T) {
+	r := NewRegistry()
+
+	err := r.Unregister("sentry-cobol")
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+	var notFound *apperrors.SDKNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "sentry-cobol", notFound.Name)
+
+	// Registered SDKs
+	require.NoError(t, r.Register(testRegistryConfig()))
+	require.NoError(t, r.Unregister("sentry-crystal"))
+	assert.ErrorIs(t, r.Unregister("sentry-crystal"), ErrUnknownSDK)
+
+	// Embedded SDKs
+	require.NoError(t, r.Unregister("sentry-ruby"))
+	configs, err := r.Configs()
+	require.NoError(t, err)
+	_, ok := configs.FindSDK("sentry-ruby")
+	assert.False(t, ok)
+
+	// An unregistered embedded SDK can be registered again
+	ruby := testRegistryConfig()
+	ruby.Name = "sentry-ruby"
+	ruby.URL = "https://github.com/getsentry/sentry-ruby-fork"
+	require.NoError(t, r.Register(ruby))
+	configs, err = r.Configs()
+	require.NoError(t, err)
+	got, ok := configs.FindSDK("sentry-ruby")
+	require.True(t, ok)
+	assert.Equal(t, ruby.URL, got.URL)
+}
+
+func TestRegistryPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sdk_registry.json")
+
+	r, err := OpenRegistry(path)
+	require.NoError(t, err)
+	require.NoError(t, r.Register(testRegistryConfig()))
+	require.NoError(t, r.Unregister("sentry-ruby"))
+
+	// Reopening the file, as on restart, restores both changes on top of
+	// the embedded SDKs
+	reopened, err := OpenRegistry(path)
+	require.NoError(t, err)
+	configs, err := reopened.Configs()
+	require.NoError(t, err)
+
+	crystal, ok := configs.FindSDK("sentry-crystal")
+	require.True(t, ok)
+	assert.Equal(t, testRegistryConfig().Patterns, crystal.Patterns)
+	_, ok = configs.FindSDK("sentry-ruby")
+	assert.False(t, ok)
+	_, ok = configs.FindSDK("sentry-go")
+	assert.True(t, ok)
+
+	assert.ErrorIs(t, reopened.Register(testRegistryConfig()), ErrSDKExists)
+}
+
+func TestDefaultRegistry(t *testing.T) {
+	previous := DefaultRegistry()
+	t.Cleanup(func() { SetDefaultRegistry(previous) })
+
+	r := NewRegistry()
+	require.NoError(t, r.Register(testRegistryConfig()))
+	SetDefaultRegistry(r)
+
+	configs, err := LoadConfigs()
+	require.NoError(t, err)
+	_, ok := configs.FindSDK("sentry-crystal")
+	assert.True(t, ok)
+}
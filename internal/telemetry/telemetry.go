@@ -0,0 +1,165 @@
+// Package telemetry exports OpenTelemetry traces of the service over
+// OTLP/HTTP. Instrumented packages start spans from the global tracer
+// provider, which does nothing until Setup installs an exporting one.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ServiceName identifies the service's spans in trace backends.
+const ServiceName = "claude-cache-service"
+
+// Config selects where traces are exported.
+type Config struct {
+	Enabled bool
+
+	// Endpoint is the base URL of an OTLP/HTTP collector, such as
+	// http://localhost:4318; spans are posted to its /v1/traces
+	Endpoint string
+
+	// Version is reported as the service version of every span
+	Version string
+}
+
+// Status describes the trace exporter. Connected is true once the last
+// export reached the collector; LastError holds the failure otherwise.
+type Status struct {
+	Enabled      bool      `json:"enabled"`
+	Endpoint     string    `json:"endpoint,omitempty"`
+	Connected    bool      `json:"connected"`
+	LastExportAt time.Time `json:"last_export_at"`
+	LastError    string    `json:"last_error,omitempty"`
+}
+
+// Provider is an installed tracer provider and its exporter.
+type Provider struct {
+	config   Config
+	provider *sdktrace.TracerProvider
+	exporter *statusExporter
+}
+
+// current is the Provider Setup last installed.
+var current struct {
+	mu       sync.RWMutex
+	provider *Provider
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagation described by cfg. When tracing is disabled spans are not
+// recorded, but incoming trace context is still propagated. Callers shut
+// the provider down to flush pending spans.
+func Setup(ctx context.Context, cfg Config, logger zerolog.Logger) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	p := &Provider{config: cfg}
+	if cfg.Enabled {
+		exporter, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+		}
+		p.exporter = &statusExporter{SpanExporter: exporter}
+
+		res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+			attribute.String("service.name", ServiceName),
+			attribute.String("service.version", cfg.Version),
+		))
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to describe trace resource: %w", err), exporter.Shutdown(ctx))
+		}
+
+		p.provider = sdktrace.NewTracerProvider(
+			sdktrace.WithBatcher(p.exporter),
+			sdktrace.WithResource(res),
+		)
+		otel.SetTracerProvider(p.provider)
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+			logger.Warn().Err(err).Msg("OpenTelemetry error")
+		}))
+
+		logger.Info().Str("endpoint", cfg.Endpoint).Msg("Exporting traces")
+	}
+
+	current.mu.Lock()
+	current.provider = p
+	current.mu.Unlock()
+	return p, nil
+}
+
+// Shutdown flushes pending spans and stops the exporter.
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.provider == nil {
+		return nil
+	}
+	return p.provider.Shutdown(ctx)
+}
+
+// Status reports whether the provider exports traces and whether its last
+// export succeeded.
+func (p *Provider) Status() Status {
+	status := Status{Enabled: p.config.Enabled}
+	if !p.config.Enabled {
+		return status
+	}
+	status.Endpoint = p.config.Endpoint
+
+	p.exporter.mu.Lock()
+	defer p.exporter.mu.Unlock()
+	status.LastExportAt = p.exporter.lastExportAt
+	if p.exporter.lastErr != nil {
+		status.LastError = p.exporter.lastErr.Error()
+	} else {
+		status.Connected = !p.exporter.lastExportAt.IsZero()
+	}
+	return status
+}
+
+// CurrentStatus reports the status of the provider Setup installed, or a
+// disabled status before Setup.
+func CurrentStatus() Status {
+	current.mu.RLock()
+	p := current.provider
+	current.mu.RUnlock()
+
+	if p == nil {
+		return Status{}
+	}
+	return p.Status()
+}
+
+// statusExporter records the outcome of each export.
+type statusExporter struct {
+	sdktrace.SpanExporter
+
+	mu           sync.Mutex
+	lastExportAt time.Time
+	lastErr      error
+}
+
+func (e *statusExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastExportAt = time.Now()
+	e.lastErr = err
+	return err
+}
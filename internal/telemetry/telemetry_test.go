@@ -0,0 +1,93 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// setup calls Setup, and uninstalls the provider when the test finishes.
+func setup(t *testing.T, cfg Config) *Provider {
+	t.Helper()
+
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		current.mu.Lock()
+		current.provider = nil
+		current.mu.Unlock()
+	})
+
+	p, err := Setup(context.Background(), cfg, zerolog.Nop())
+	require.NoError(t, err)
+	return p
+}
+
+// collector starts an OTLP/HTTP collector answering trace exports with
+// status, and counts the exports.
+func collector(t *testing.T, status int) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var exports atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			exports.Add(1)
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &exports
+}
+
+func TestSetupDisabled(t *testing.T) {
+	assert.Equal(t, Status{}, CurrentStatus())
+
+	p := setup(t, Config{Enabled: false, Endpoint: "http://localhost:4318"})
+	assert.Equal(t, Status{}, p.Status())
+	assert.Equal(t, Status{}, CurrentStatus())
+	assert.NoError(t, p.Shutdown(context.Background()))
+}
+
+func TestStatusAfterExport(t *testing.T) {
+	server, exports := collector(t, http.StatusOK)
+	p := setup(t, Config{Enabled: true, Endpoint: server.URL + "/", Version: "test"})
+
+	status := CurrentStatus()
+	assert.True(t, status.Enabled)
+	assert.Equal(t, server.URL+"/", status.Endpoint)
+	assert.False(t, status.Connected, "nothing exported yet")
+	assert.True(t, status.LastExportAt.IsZero())
+
+	_, span := otel.Tracer("test").Start(context.Background(), "request")
+	span.End()
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Equal(t, int64(1), exports.Load())
+	status = CurrentStatus()
+	assert.True(t, status.Connected)
+	assert.False(t, status.LastExportAt.IsZero())
+	assert.Empty(t, status.LastError)
+}
+
+func TestStatusAfterFailedExport(t *testing.T) {
+	server, exports := collector(t, http.StatusBadRequest)
+	p := setup(t, Config{Enabled: true, Endpoint: server.URL})
+
+	_, span := otel.Tracer("test").Start(context.Background(), "request")
+	span.End()
+	// The batcher reports export failures to the error handler only
+	require.NoError(t, p.Shutdown(context.Background()))
+
+	assert.Equal(t, int64(1), exports.Load())
+	status := p.Status()
+	assert.False(t, status.Connected)
+	assert.False(t, status.LastExportAt.IsZero())
+	assert.NotEmpty(t, status.LastError)
+}
@@ -0,0 +1,66 @@
+// Package telemetrytest records the spans of instrumented code in tests.
+package telemetrytest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// Recorder holds the spans ended while it is installed.
+type Recorder struct {
+	*tracetest.InMemoryExporter
+}
+
+// NewRecorder installs a global tracer provider recording every span in
+// memory, replaced by a no-op provider when the test finishes, and W3C
+// trace context propagation. Tests using it must not run in parallel.
+func NewRecorder(t *testing.T) *Recorder {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		if err := provider.Shutdown(context.Background()); err != nil {
+			t.Errorf("failed to shut down tracer provider: %v", err)
+		}
+	})
+
+	return &Recorder{InMemoryExporter: exporter}
+}
+
+// Named returns the recorded spans with the given name, in the order they
+// ended.
+func (r *Recorder) Named(name string) tracetest.SpanStubs {
+	var spans tracetest.SpanStubs
+	for _, span := range r.GetSpans() {
+		if span.Name == name {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Attributes returns the attributes of span keyed by name, with their
+// values as Go values.
+func Attributes(span tracetest.SpanStub) map[string]any {
+	attrs := make(map[string]any, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return attrs
+}
+
+// ChildOf reports whether span's parent is parent.
+func ChildOf(span tracetest.SpanStub, parent trace.SpanContext) bool {
+	return span.Parent.SpanID() == parent.SpanID() && span.Parent.TraceID() == parent.TraceID()
+}
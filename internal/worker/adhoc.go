@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// JobKindAdhocAnalysis identifies background ad-hoc analyses in the JobRegistry.
+const JobKindAdhocAnalysis = "adhoc_analysis"
+
+// adhocTTL is how long ad-hoc analysis results stay cached.
+const adhocTTL = time.Hour
+
+// ErrTokenBudgetExceeded is returned when an ad-hoc request is estimated to
+// use more than MaxAdhocTokens.
+var ErrTokenBudgetExceeded = errors.New("token budget exceeded")
+
+// AdhocCacheKey returns the cache key for an ad-hoc analysis of the given
+// SDK name and version.
+func AdhocCacheKey(sdkName, version string) string {
+	sum := sha256.Sum256([]byte(sdkName + version))
+	return "adhoc:" + hex.EncodeToString(sum[:])
+}
+
+// EstimateAdhocTokens returns the estimated token usage of analyzing the
+// requests, or ErrTokenBudgetExceeded if it is above MaxAdhocTokens.
+func (w *UpdateWorker) EstimateAdhocTokens(ctx context.Context, requests []analyzer.AnalysisRequest) (int, error) {
+	total := 0
+	for _, req := range requests {
+		tokens, err := w.codeAnalyzer.CountTokens(ctx, req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to count tokens for %s: %w", req.SDKName, err)
+		}
+		total += tokens
+	}
+
+	if w.config.MaxAdhocTokens > 0 && total > w.config.MaxAdhocTokens {
+		return total, fmt.Errorf("%w: estimated %d tokens, limit is %d", ErrTokenBudgetExceeded, total, w.config.MaxAdhocTokens)
+	}
+	return total, nil
+}
+
+// AnalyzeAdhoc analyzes code that is not tracked in a git repository.
+// Cached results are reused; the rest are sent to the analyzer in a single
+// batch and cached for an hour.
+func (w *UpdateWorker) AnalyzeAdhoc(ctx context.Context, requests []analyzer.AnalysisRequest) (*analyzer.BatchAnalysisResult, error) {
+	result, pending := w.cachedAdhoc(requests)
+	if len(pending) == 0 {
+		now := time.Now()
+		result.Status = "completed"
+		result.CompletedAt = &now
+		return result, nil
+	}
+
+	batch, err := w.codeAnalyzer.BatchAnalyze(ctx, pending)
+	if err != nil {
+		return nil, fmt.Errorf("batch analysis failed: %w", err)
+	}
+
+	for _, req := range pending {
+		analysis, ok := batch.Results[req.SDKName]
+		if !ok {
+			continue
+		}
+		w.recordTokenUsage(req.SDKName, analysis.TokensUsed)
+		w.storeAdhoc(req, analysis)
+	}
+
+	for name, analysis := range result.Results {
+		batch.Results[name] = analysis
+	}
+	return batch, nil
+}
+
+// StartAdhocAnalysis runs AnalyzeAdhoc in the background and returns the
+// job tracking it.
+func (w *UpdateWorker) StartAdhocAnalysis(requests []analyzer.AnalysisRequest) (*Job, error) {
+	if !w.beginRun() {
+		return nil, ErrDraining
+	}
+
+	job := w.jobs.Start(JobKindAdhocAnalysis, len(requests))
+	w.logger.Info().
+		Str("job_id", job.ID()).
+		Int("analyses", len(requests)).
+		Msg("Starting ad-hoc analysis")
+
+	go func() {
+		defer w.endRun()
+
+		result, err := w.AnalyzeAdhoc(w.analysisContext(context.Background()), requests)
+		for _, req := range requests {
+			switch {
+			case err != nil:
+				job.Done(err)
+			case result.Errors[req.SDKName] != "":
+				job.Done(errors.New(result.Errors[req.SDKName]))
+			default:
+				job.Done(nil)
+			}
+		}
+		job.Finish()
+
+		if err != nil {
+			w.logger.Error().Err(err).Str("job_id", job.ID()).Msg("Ad-hoc analysis failed")
+			return
+		}
+		w.logger.Info().
+			Str("job_id", job.ID()).
+			Int("results", len(result.Results)).
+			Int("errors", len(result.Errors)).
+			Msg("Ad-hoc analysis completed")
+	}()
+
+	return job, nil
+}
+
+// cachedAdhoc splits requests into cached results and those still needing
+// analysis.
+func (w *UpdateWorker) cachedAdhoc(requests []analyzer.AnalysisRequest) (*analyzer.BatchAnalysisResult, []analyzer.AnalysisRequest) {
+	result := &analyzer.BatchAnalysisResult{
+		Results: make(map[string]*analyzer.SDKAnalysis),
+		Errors:  make(map[string]string),
+	}
+
+	var pending []analyzer.AnalysisRequest
+	for _, req := range requests {
+		value, err := w.cache.Get(AdhocCacheKey(req.SDKName, req.Version))
+		if err != nil {
+			pending = append(pending, req)
+			continue
+		}
+
+		var analysis analyzer.SDKAnalysis
+		if err := json.Unmarshal([]byte(value), &analysis); err != nil {
+			w.logger.Warn().Err(err).Str("sdk", req.SDKName).Msg("Ignoring unreadable cached ad-hoc analysis")
+			pending = append(pending, req)
+			continue
+		}
+		result.Results[req.SDKName] = &analysis
+	}
+	return result, pending
+}
+
+func (w *UpdateWorker) storeAdhoc(req analyzer.AnalysisRequest, analysis *analyzer.SDKAnalysis) {
+	analysisJSON, err := json.Marshal(analysis)
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", req.SDKName).Msg("Failed to marshal ad-hoc analysis")
+		return
+	}
+
+	if err := w.cache.Set(AdhocCacheKey(req.SDKName, req.Version), string(analysisJSON), adhocTTL, cache.WithTokensCached(analysis.TokensUsed)); err != nil {
+		w.logger.Error().Err(err).Str("sdk", req.SDKName).Msg("Failed to cache ad-hoc analysis")
+	}
+}
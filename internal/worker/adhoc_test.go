@@ -0,0 +1,126 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// countingAnalyzer records the SDKs sent to BatchAnalyze.
+type countingAnalyzer struct {
+	*analyzer.MockAnalyzer
+
+	mu      sync.Mutex
+	batched []string
+}
+
+func (c *countingAnalyzer) BatchAnalyze(ctx context.Context, requests []analyzer.AnalysisRequest) (*analyzer.BatchAnalysisResult, error) {
+	c.mu.Lock()
+	for _, req := range requests {
+		c.batched = append(c.batched, req.SDKName)
+	}
+	c.mu.Unlock()
+	return c.MockAnalyzer.BatchAnalyze(ctx, requests)
+}
+
+func newAdhocTestWorker(t *testing.T) (*UpdateWorker, *countingAnalyzer) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+		MaxAdhocTokens: 1000,
+	}
+
+	counting := &countingAnalyzer{MockAnalyzer: analyzer.NewMockAnalyzer(logger)}
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+	worker.sdkAnalyzer = nil
+	worker.codeAnalyzer = counting
+	return worker, counting
+}
+
+func TestAdhocCacheKey(t *testing.T) {
+	key := AdhocCacheKey("my-sdk", "1.0.0")
+	assert.True(t, strings.HasPrefix(key, "adhoc:"))
+	assert.Len(t, key, len("adhoc:")+64)
+	assert.Equal(t, key, AdhocCacheKey("my-sdk", "1.0.0"))
+	assert.NotEqual(t, key, AdhocCacheKey("my-sdk", "1.0.1"))
+}
+
+func TestEstimateAdhocTokens(t *testing.T) {
+	worker, _ := newAdhocTestWorker(t)
+
+	small := []analyzer.AnalysisRequest{
+		{SDKName: "a", Code: map[string]string{"a.go": strings.Repeat("x", 400)}},
+		{SDKName: "b", Code: map[string]string{"b.go": strings.Repeat("x", 400)}},
+	}
+	tokens, err := worker.EstimateAdhocTokens(context.Background(), small)
+	require.NoError(t, err)
+	assert.Equal(t, 200, tokens)
+
+	large := []analyzer.AnalysisRequest{
+		{SDKName: "a", Code: map[string]string{"a.go": strings.Repeat("x", 8000)}},
+	}
+	_, err = worker.EstimateAdhocTokens(context.Background(), large)
+	assert.ErrorIs(t, err, ErrTokenBudgetExceeded)
+}
+
+func TestAnalyzeAdhocReusesCache(t *testing.T) {
+	worker, counting := newAdhocTestWorker(t)
+	ctx := context.Background()
+
+	first := []analyzer.AnalysisRequest{
+		{SDKName: "my-sdk", Version: "1.0.0", Code: map[string]string{"main.go": "package main"}},
+	}
+	result, err := worker.AnalyzeAdhoc(ctx, first)
+	require.NoError(t, err)
+	assert.Contains(t, result.Results, "my-sdk")
+
+	second := append(first, analyzer.AnalysisRequest{
+		SDKName: "other-sdk", Version: "2.0.0", Code: map[string]string{"main.go": "package main"},
+	})
+	result, err = worker.AnalyzeAdhoc(ctx, second)
+	require.NoError(t, err)
+	assert.Contains(t, result.Results, "my-sdk")
+	assert.Contains(t, result.Results, "other-sdk")
+
+	// my-sdk was only analyzed once; the second request was served from cache
+	assert.Equal(t, []string{"my-sdk", "other-sdk"}, counting.batched)
+}
+
+func TestStartAdhocAnalysis(t *testing.T) {
+	worker, _ := newAdhocTestWorker(t)
+
+	job, err := worker.StartAdhocAnalysis([]analyzer.AnalysisRequest{
+		{SDKName: "my-sdk", Version: "1.0.0", Code: map[string]string{"main.go": "package main"}},
+	})
+	require.NoError(t, err)
+
+	progress := waitForProgress(t, job, 1)
+	assert.Equal(t, JobKindAdhocAnalysis, progress.Kind)
+	assert.Equal(t, 0, progress.Errors)
+
+	_, err = worker.cache.Get(AdhocCacheKey("my-sdk", "1.0.0"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// AnalyzeSDKNow analyzes the named SDK right away, without waiting for a
+// pool slot, caches the result and returns it. Unless force is set, an SDK
+// with nothing new since its last analysis is not analyzed again; its
+// cached analysis is returned instead, with analyzed false.
+func (w *UpdateWorker) AnalyzeSDKNow(ctx context.Context, name string, force bool) (analysis *analyzer.SDKAnalysis, analyzed bool, err error) {
+	targets, err := resolveSDKs([]string{name})
+	if err != nil {
+		return nil, false, err
+	}
+	target := targets[0]
+
+	if !w.beginRun() {
+		return nil, false, ErrDraining
+	}
+	defer w.endRun()
+
+	if !force && !w.needsUpdate(ctx, target) {
+		if cached := w.cachedAnalysis(target.Name); cached != nil {
+			w.logger.Debug().Str("sdk", target.Name).Msg("SDK analysis is up to date, skipping")
+			return cached, false, nil
+		}
+	}
+
+	w.logger.Info().
+		Str("sdk", target.Name).
+		Bool("force", force).
+		Msg("Analyzing SDK on demand")
+
+	analysis, err = w.warmSDK(ctx, target)
+	if err != nil {
+		return nil, false, err
+	}
+	return analysis, true, nil
+}
+
+// needsUpdate reports whether an SDK has changed since its last analysis.
+// Without the SDK analyzer, which checks the repository for new commits,
+// SDKs analyzed within StaleThreshold are considered unchanged.
+func (w *UpdateWorker) needsUpdate(ctx context.Context, target sdk.Config) bool {
+	if w.sdkAnalyzer == nil {
+		return !w.isFresh(target.Name)
+	}
+
+	needed, err := w.sdkAnalyzer.NeedsUpdate(ctx, target)
+	if err != nil {
+		w.logger.Warn().Err(err).Str("sdk", target.Name).Msg("Failed to check SDK for updates")
+		return true
+	}
+	return needed
+}
+
+// cachedAnalysis returns the latest cached analysis of an SDK, or nil if
+// there is none or it cannot be decoded. The read serves the analysis, so
+// it counts as a cache hit.
+func (w *UpdateWorker) cachedAnalysis(sdkName string) *analyzer.SDKAnalysis {
+	value, err := w.cache.Get(fmt.Sprintf("sdk:%s", sdkName))
+	if err != nil {
+		return nil
+	}
+	return w.decodeAnalysis(sdkName, value)
+}
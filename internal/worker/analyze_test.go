@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+)
+
+func TestAnalyzeSDKNow(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 1)
+	close(gated.release)
+	ctx := context.Background()
+
+	lastAnalyzed := func() time.Time {
+		value, err := cacheManager.Get("sdk:sentry-go:last_analyzed")
+		require.NoError(t, err)
+		analyzedAt, err := time.Parse(time.RFC3339, value)
+		require.NoError(t, err)
+		return analyzedAt
+	}
+
+	// Never analyzed: the analysis is cached with its timestamp
+	analysis, analyzed, err := worker.AnalyzeSDKNow(ctx, "sentry-go", false)
+	require.NoError(t, err)
+	assert.True(t, analyzed)
+	require.NotNil(t, analysis)
+	assert.Equal(t, []string{"sentry-go"}, gated.Calls())
+
+	cached := worker.cachedAnalysis("sentry-go")
+	require.NotNil(t, cached)
+	assert.Equal(t, analysis.AnalysisVersion, cached.AnalysisVersion)
+	assert.WithinDuration(t, time.Now(), lastAnalyzed(), 2*time.Second)
+
+	// Fresh: the cached analysis is returned without analyzing
+	analysis, analyzed, err = worker.AnalyzeSDKNow(ctx, "sentry-go", false)
+	require.NoError(t, err)
+	assert.False(t, analyzed)
+	assert.Equal(t, cached.AnalysisVersion, analysis.AnalysisVersion)
+	assert.Len(t, gated.Calls(), 1)
+
+	// Forced: analyzed again and the timestamp moves forward
+	earlier := time.Now().Add(-30 * time.Minute).Truncate(time.Second)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", earlier.Format(time.RFC3339), 0))
+
+	_, analyzed, err = worker.AnalyzeSDKNow(ctx, "sentry-go", true)
+	require.NoError(t, err)
+	assert.True(t, analyzed)
+	assert.Len(t, gated.Calls(), 2)
+	assert.True(t, lastAnalyzed().After(earlier))
+}
+
+func TestAnalyzeSDKNowErrors(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 1)
+	close(gated.release)
+
+	_, _, err := worker.AnalyzeSDKNow(context.Background(), "no-such-sdk", false)
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+	var notFound *apperrors.SDKNotFoundError
+	require.True(t, errors.As(err, &notFound))
+	assert.Equal(t, "no-such-sdk", notFound.Name)
+
+	_, _, err = worker.AnalyzeSDKNow(context.Background(), "sentry-ruby", false)
+	var failed *apperrors.AnalysisFailedError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, "sentry-ruby", failed.SDK)
+	_, err = cacheManager.Get("sdk:sentry-ruby")
+	assert.Error(t, err, "failed analyses are not cached")
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// runScheduledUpdate runs a cache update unless the worker is backing off
+// after repeated cycle failures.
+func (w *UpdateWorker) runScheduledUpdate(ctx context.Context) {
+	if !w.beginRun() {
+		w.logger.Debug().Msg("Skipping cache update while draining")
+		return
+	}
+	defer w.endRun()
+
+	now := time.Now()
+	if w.inBackoff(now) {
+		w.logger.Debug().
+			Time("backoff_until", w.backoffUntilTime()).
+			Msg("Skipping cache update while backing off")
+		return
+	}
+
+	w.updateMu.Lock()
+	err := w.updateCache(ctx, true)
+	w.updateMu.Unlock()
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to update cache")
+	}
+
+	// A run interrupted by shutdown says nothing about upstream health
+	if ctx.Err() != nil {
+		return
+	}
+	w.recordCycleResult(err, now)
+
+	if err == nil && w.config.AutoPruneInactiveRepos {
+		w.runScheduledRepoPrune(ctx)
+	}
+	// Remove repositories of SDKs disabled since the last cycle
+	w.RequestRepoCleanup()
+}
+
+// ConsecutiveFailures returns the number of update cycles that have failed in a row.
+func (w *UpdateWorker) ConsecutiveFailures() int {
+	w.backoffMu.RLock()
+	defer w.backoffMu.RUnlock()
+	return w.consecutiveFailures
+}
+
+// ResetBackoff clears the failure counter so the next scheduled cycle runs.
+func (w *UpdateWorker) ResetBackoff() {
+	w.backoffMu.Lock()
+	failures := w.consecutiveFailures
+	w.consecutiveFailures = 0
+	w.backoffUntil = time.Time{}
+	w.backoffMu.Unlock()
+
+	w.logger.Info().
+		Int("consecutive_failures", failures).
+		Msg("Update worker backoff reset")
+}
+
+// inBackoff reports whether the cycle starting at now should be skipped.
+func (w *UpdateWorker) inBackoff(now time.Time) bool {
+	w.backoffMu.RLock()
+	defer w.backoffMu.RUnlock()
+	return now.Before(w.backoffUntil)
+}
+
+func (w *UpdateWorker) backoffUntilTime() time.Time {
+	w.backoffMu.RLock()
+	defer w.backoffMu.RUnlock()
+	return w.backoffUntil
+}
+
+// recordCycleResult updates the failure counter for a cycle that started at
+// start and, once the failure threshold is reached, pushes the next allowed
+// run out by the effective backoff interval.
+func (w *UpdateWorker) recordCycleResult(err error, start time.Time) {
+	w.backoffMu.Lock()
+	defer w.backoffMu.Unlock()
+
+	if err == nil {
+		if w.consecutiveFailures > 0 {
+			w.logger.Info().
+				Int("consecutive_failures", w.consecutiveFailures).
+				Msg("Cache update succeeded, clearing backoff")
+		}
+		w.consecutiveFailures = 0
+		w.backoffUntil = time.Time{}
+		return
+	}
+
+	w.consecutiveFailures++
+
+	base, baseErr := scheduleInterval(w.UpdateSchedule(), start)
+	if baseErr != nil {
+		w.logger.Error().Err(baseErr).Msg("Failed to determine schedule interval for backoff")
+		return
+	}
+
+	interval := backoffInterval(base, w.consecutiveFailures, w.config.MaxConsecutiveFailures, w.config.MaxBackoffInterval)
+	if interval <= base {
+		return
+	}
+
+	// Cron fires on exact boundaries while start is recorded slightly later,
+	// so allow half an interval of slack to avoid skipping one cycle too many.
+	w.backoffUntil = start.Add(interval - base/2)
+	w.logger.Warn().
+		Int("consecutive_failures", w.consecutiveFailures).
+		Dur("base_interval", base).
+		Dur("effective_interval", interval).
+		Time("backoff_until", w.backoffUntil).
+		Msg("Backing off cache updates after repeated failures")
+}
+
+// backoffInterval returns the effective interval between update cycles. Below
+// the failure threshold the base interval is used; from the threshold onwards
+// it doubles with each additional failure, capped at maxInterval.
+func backoffInterval(base time.Duration, failures, threshold int, maxInterval time.Duration) time.Duration {
+	if threshold <= 0 || failures < threshold {
+		return base
+	}
+
+	interval := base
+	for i := threshold; i <= failures; i++ {
+		interval *= 2
+		if maxInterval > 0 && interval >= maxInterval {
+			return maxInterval
+		}
+	}
+	return interval
+}
+
+// scheduleInterval returns the time between two consecutive runs of the cron
+// schedule after from.
+func scheduleInterval(schedule string, from time.Time) (time.Duration, error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse schedule %q: %w", schedule, err)
+	}
+
+	next := sched.Next(from)
+	return sched.Next(next).Sub(next), nil
+}
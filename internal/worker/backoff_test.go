@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func newBackoffTestWorker(t *testing.T) *UpdateWorker {
+	t.Helper()
+
+	return &UpdateWorker{
+		logger: zerolog.New(os.Stderr).Level(zerolog.Disabled),
+		config: &config.Config{
+			UpdateSchedule:         "0 * * * *", // Hourly
+			MaxConsecutiveFailures: 3,
+			MaxBackoffInterval:     6 * time.Hour,
+		},
+	}
+}
+
+func TestBackoffInterval(t *testing.T) {
+	base := time.Hour
+	maxInterval := 6 * time.Hour
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: time.Hour},
+		{failures: 1, expected: time.Hour},
+		{failures: 2, expected: time.Hour},
+		{failures: 3, expected: 2 * time.Hour},
+		{failures: 4, expected: 4 * time.Hour},
+		{failures: 5, expected: 6 * time.Hour},
+		{failures: 20, expected: 6 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, backoffInterval(base, tt.failures, 3, maxInterval), "failures=%d", tt.failures)
+	}
+}
+
+func TestBackoffAfterConsecutiveFailures(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	cycleErr := errors.New("claude unavailable")
+
+	start := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	previous := time.Hour
+
+	for i := 1; i <= 5; i++ {
+		w.recordCycleResult(cycleErr, start)
+		assert.Equal(t, i, w.ConsecutiveFailures())
+
+		interval := backoffInterval(time.Hour, i, w.config.MaxConsecutiveFailures, w.config.MaxBackoffInterval)
+		assert.LessOrEqual(t, interval, w.config.MaxBackoffInterval)
+
+		if i < w.config.MaxConsecutiveFailures {
+			assert.Equal(t, time.Hour, interval)
+			assert.False(t, w.inBackoff(start.Add(time.Hour)), "failure %d should not back off", i)
+			continue
+		}
+
+		// Interval doubles with each failure until it reaches the cap
+		expected := previous * 2
+		if expected > w.config.MaxBackoffInterval {
+			expected = w.config.MaxBackoffInterval
+		}
+		assert.Equal(t, expected, interval, "failure %d", i)
+		previous = interval
+
+		// Cycles inside the backoff window are skipped, the one after it runs
+		assert.True(t, w.inBackoff(start.Add(interval-time.Hour)), "failure %d", i)
+		assert.False(t, w.inBackoff(start.Add(interval)), "failure %d", i)
+	}
+
+	assert.Equal(t, w.config.MaxBackoffInterval, previous)
+}
+
+func TestBackoffClearedOnSuccess(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	start := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		w.recordCycleResult(errors.New("failed"), start)
+	}
+	require.True(t, w.inBackoff(start.Add(time.Hour)))
+
+	w.recordCycleResult(nil, start)
+	assert.Equal(t, 0, w.ConsecutiveFailures())
+	assert.False(t, w.inBackoff(start.Add(time.Hour)))
+}
+
+func TestResetBackoff(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	start := time.Now()
+
+	for i := 0; i < 5; i++ {
+		w.recordCycleResult(errors.New("failed"), start)
+	}
+	require.True(t, w.inBackoff(start.Add(time.Minute)))
+
+	w.ResetBackoff()
+	assert.Equal(t, 0, w.ConsecutiveFailures())
+	assert.False(t, w.inBackoff(start.Add(time.Minute)))
+}
+
+func TestScheduleInterval(t *testing.T) {
+	from := time.Date(2024, 7, 1, 12, 30, 0, 0, time.UTC)
+
+	interval, err := scheduleInterval("0 2 * * 0", from)
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, interval)
+
+	_, err = scheduleInterval("not a schedule", from)
+	assert.Error(t, err)
+}
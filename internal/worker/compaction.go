@@ -0,0 +1,19 @@
+package worker
+
+import (
+	"errors"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+// runScheduledCompaction shrinks the cache database file.
+func (w *UpdateWorker) runScheduledCompaction() {
+	err := w.cache.ShrinkDB()
+	if errors.Is(err, cache.ErrNotSupported) {
+		w.logger.Debug().Msg("Cache backend does not support compaction")
+		return
+	}
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to compact cache database")
+	}
+}
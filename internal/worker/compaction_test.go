@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func TestRunScheduledCompaction(t *testing.T) {
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(t.TempDir(), logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	w := newBackoffTestWorker(t)
+	w.cache = cacheManager
+
+	w.runScheduledCompaction()
+
+	assert.False(t, cacheManager.GetStats().LastShrinkAt.IsZero())
+}
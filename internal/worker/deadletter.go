@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// deadLetterThreshold is how many failed analyses of an SDK within
+// deadLetterWindow disable it.
+const deadLetterThreshold = 3
+
+// deadLetterWindow is the period over which failed analyses are counted.
+const deadLetterWindow = 24 * time.Hour
+
+// ErrNotDeadLettered is returned when retrying an SDK without failed
+// analyses.
+var ErrNotDeadLettered = errors.New("SDK has no failed analyses")
+
+// DeadLetters returns the queue of SDKs whose analyses failed.
+func (w *UpdateWorker) DeadLetters() *analytics.DeadLetterQueue {
+	return w.deadLetters
+}
+
+// recordAnalysisFailure adds a failed analysis of an SDK to the dead-letter
+// queue. The deadLetterThreshold-th failure within deadLetterWindow disables
+// the SDK and sends a notification. Skipped analyses, private repositories
+// and cancelled runs are not failures of the SDK.
+func (w *UpdateWorker) recordAnalysisFailure(ctx context.Context, sdkName string, cause error) {
+	if errors.Is(cause, sdk.ErrAnalysisSkipped) || errors.Is(cause, sdk.ErrPrivateRepo) || ctx.Err() != nil {
+		return
+	}
+
+	failure, err := w.deadLetters.Record(sdkName, cause.Error(), time.Now())
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to record failed analysis")
+		return
+	}
+	if failure.Attempts < deadLetterThreshold {
+		return
+	}
+
+	wasActive, err := sdk.SetActive(sdkName, false)
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to disable SDK")
+		return
+	}
+	if !wasActive {
+		return
+	}
+	w.RequestRepoCleanup()
+	w.RequestSDKReschedule()
+
+	w.logger.Warn().
+		Str("sdk", sdkName).
+		Int("attempts", failure.Attempts).
+		Str("error", failure.Error).
+		Msg("SDK disabled after repeated analysis failures")
+
+	w.sendNotifications(ctx, []notify.AnalysisEvent{{
+		Type:     notify.EventSDKDisabled,
+		SDK:      sdkName,
+		Error:    failure.Error,
+		Attempts: failure.Attempts,
+	}})
+}
+
+// clearAnalysisFailures removes an SDK from the dead-letter queue after it
+// was analyzed successfully.
+func (w *UpdateWorker) clearAnalysisFailures(sdkName string) {
+	if _, err := w.deadLetters.Remove(sdkName); err != nil {
+		w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to clear failed analyses")
+	}
+}
+
+// RetryDeadLetter removes an SDK from the dead-letter queue and queues a
+// forced refresh of it, enabling it again if its failures disabled it.
+func (w *UpdateWorker) RetryDeadLetter(name string) (*RefreshJob, int, error) {
+	failure, ok, err := w.deadLetters.Get(name)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !ok {
+		return nil, 0, ErrNotDeadLettered
+	}
+
+	if failure.Attempts >= deadLetterThreshold {
+		wasActive, err := sdk.SetActive(name, true)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !wasActive {
+			w.RequestSDKReschedule()
+		}
+	}
+
+	if _, err := w.deadLetters.Remove(name); err != nil {
+		return nil, 0, err
+	}
+	return w.EnqueueRefresh(RefreshRequest{Type: RefreshSpecific, Targets: []string{name}, Force: true})
+}
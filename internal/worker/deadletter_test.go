@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// sdkActive reports whether the named SDK is active.
+func sdkActive(t *testing.T, name string) bool {
+	t.Helper()
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	cfg, ok := configs.FindSDK(name)
+	require.True(t, ok)
+	return cfg.Active
+}
+
+func TestDeadLetterDisablesSDK(t *testing.T) {
+	worker, _, gated := newWarmTestWorker(t, 1)
+	close(gated.release)
+	t.Cleanup(func() {
+		_, err := sdk.SetActive("sentry-ruby", true)
+		require.NoError(t, err)
+	})
+
+	targets, err := resolveSDKs([]string{"sentry-ruby", "sentry-go"})
+	require.NoError(t, err)
+	ruby, golang := targets[0], targets[1]
+
+	// A success clears earlier failures
+	_, err = worker.warmSDK(context.Background(), golang)
+	require.NoError(t, err)
+	gated.failing["sentry-go"] = true
+	_, err = worker.warmSDK(context.Background(), golang)
+	require.Error(t, err)
+	delete(gated.failing, "sentry-go")
+	_, err = worker.warmSDK(context.Background(), golang)
+	require.NoError(t, err)
+	_, ok, err := worker.DeadLetters().Get("sentry-go")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// Only the disabling is announced from here on, not analyses
+	notifier := &recordingNotifier{}
+	worker.SetNotifier(notifier)
+	for attempt := 1; attempt <= deadLetterThreshold; attempt++ {
+		assert.True(t, sdkActive(t, "sentry-ruby"), "disabled after %d failures", attempt-1)
+		_, err := worker.warmSDK(context.Background(), ruby)
+		require.Error(t, err)
+	}
+	assert.False(t, sdkActive(t, "sentry-ruby"))
+
+	failures, err := worker.DeadLetters().List()
+	require.NoError(t, err)
+	require.Len(t, failures, 1)
+	assert.Equal(t, "sentry-ruby", failures[0].SDK)
+	assert.Equal(t, deadLetterThreshold, failures[0].Attempts)
+	assert.Contains(t, failures[0].Error, "analysis failed")
+
+	require.Len(t, notifier.events, 1)
+	event := notifier.events[0]
+	assert.Equal(t, notify.EventSDKDisabled, event.Type)
+	assert.Equal(t, "sentry-ruby", event.SDK)
+	assert.Equal(t, deadLetterThreshold, event.Attempts)
+
+	// Further failures do not notify again
+	_, err = worker.warmSDK(context.Background(), ruby)
+	require.Error(t, err)
+	assert.Len(t, notifier.events, 1)
+
+	// Retrying enables the SDK again and queues a forced refresh of it
+	refresh, position, err := worker.RetryDeadLetter("sentry-ruby")
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+	assert.Equal(t, []string{"sentry-ruby"}, refresh.Targets)
+	assert.True(t, refresh.Force)
+	assert.True(t, sdkActive(t, "sentry-ruby"))
+	_, ok, err = worker.DeadLetters().Get("sentry-ruby")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, _, err = worker.RetryDeadLetter("sentry-ruby")
+	assert.ErrorIs(t, err, ErrNotDeadLettered)
+}
@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DrainStatus describes what happened to in-flight analyses when the worker stopped.
+type DrainStatus struct {
+	Completed []string      `json:"completed"`
+	Abandoned []string      `json:"abandoned"`
+	TimedOut  bool          `json:"timed_out"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// drainState tracks in-flight update runs so shutdown can wait for them.
+type drainState struct {
+	mu        sync.Mutex
+	draining  bool
+	inflight  sync.WaitGroup
+	completed []string
+	abandoned []string
+	status    DrainStatus
+	done      chan struct{}
+}
+
+// DrainStatus returns a channel that receives the drain result once the
+// worker has stopped. If the worker was never started the result is
+// delivered immediately.
+func (w *UpdateWorker) DrainStatus() <-chan DrainStatus {
+	ch := make(chan DrainStatus, 1)
+
+	if !w.started.Load() {
+		ch <- DrainStatus{}
+		return ch
+	}
+
+	go func() {
+		<-w.drain.done
+		w.drain.mu.Lock()
+		status := w.drain.status
+		w.drain.mu.Unlock()
+		ch <- status
+	}()
+	return ch
+}
+
+// beginRun registers an update run. It returns false once draining has begun.
+func (w *UpdateWorker) beginRun() bool {
+	w.drain.mu.Lock()
+	defer w.drain.mu.Unlock()
+
+	if w.drain.draining {
+		return false
+	}
+	w.drain.inflight.Add(1)
+	return true
+}
+
+func (w *UpdateWorker) endRun() {
+	w.drain.inflight.Done()
+}
+
+// analysisContext returns the context analyses run under. It outlives the
+// worker's stop signal so an in-progress SDK can finish during the drain.
+func (w *UpdateWorker) analysisContext(ctx context.Context) context.Context {
+	if w.workCtx != nil {
+		return w.workCtx
+	}
+	return context.WithoutCancel(ctx)
+}
+
+// recordDrainOutcome notes which SDKs finished and which were skipped in a
+// run that was interrupted by shutdown.
+func (w *UpdateWorker) recordDrainOutcome(completed, abandoned []string) {
+	w.drain.mu.Lock()
+	defer w.drain.mu.Unlock()
+
+	w.drain.completed = append(w.drain.completed, completed...)
+	w.drain.abandoned = append(w.drain.abandoned, abandoned...)
+}
+
+// drainInflight stops accepting new runs and waits up to DrainTimeout for
+// in-flight ones to finish, cancelling them if the timeout expires.
+func (w *UpdateWorker) drainInflight(cronDone <-chan struct{}) DrainStatus {
+	start := time.Now()
+
+	w.drain.mu.Lock()
+	w.drain.draining = true
+	w.drain.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		w.drain.inflight.Wait()
+		<-cronDone
+		close(finished)
+	}()
+
+	timedOut := false
+	timer := time.NewTimer(w.config.DrainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-finished:
+	case <-timer.C:
+		timedOut = true
+		w.logger.Warn().
+			Dur("drain_timeout", w.config.DrainTimeout).
+			Msg("Drain timeout expired, cancelling in-flight analyses")
+		w.cancelWork()
+		<-finished
+	}
+
+	w.drain.mu.Lock()
+	w.drain.status = DrainStatus{
+		Completed: w.drain.completed,
+		Abandoned: w.drain.abandoned,
+		TimedOut:  timedOut,
+		Duration:  time.Since(start),
+	}
+	status := w.drain.status
+	w.drain.mu.Unlock()
+
+	w.logger.Info().
+		Strs("completed", status.Completed).
+		Strs("abandoned", status.Abandoned).
+		Bool("timed_out", status.TimedOut).
+		Dur("duration", status.Duration).
+		Msg("Update worker drained")
+
+	close(w.drain.done)
+	return status
+}
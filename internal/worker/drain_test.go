@@ -0,0 +1,171 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// blockingAnalyzer holds every analysis until released.
+type blockingAnalyzer struct {
+	*analyzer.MockAnalyzer
+	started chan string
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func newBlockingAnalyzer() *blockingAnalyzer {
+	return &blockingAnalyzer{
+		MockAnalyzer: analyzer.NewMockAnalyzer(zerolog.Nop()),
+		started:      make(chan string, 10),
+		release:      make(chan struct{}),
+	}
+}
+
+func (b *blockingAnalyzer) AnalyzeCode(ctx context.Context, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
+	b.calls.Add(1)
+	b.started <- request.SDKName
+
+	select {
+	case <-b.release:
+		return b.MockAnalyzer.AnalyzeCode(ctx, request)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func newDrainTestWorker(t *testing.T, drainTimeout time.Duration) (*UpdateWorker, *cache.Manager, *blockingAnalyzer) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+		DrainTimeout:   drainTimeout,
+	}
+
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+	worker.sdkAnalyzer = nil
+
+	blocking := newBlockingAnalyzer()
+	worker.fallbackAnalyzer = blocking
+
+	return worker, cacheManager, blocking
+}
+
+func TestUpdateCacheDrainsInProgressSDK(t *testing.T) {
+	worker, cacheManager, blocking := newDrainTestWorker(t, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- worker.updateCache(ctx, true)
+	}()
+
+	// Cancel while the first SDK is mid-analysis, then let it finish
+	inProgress := <-blocking.started
+	cancel()
+	close(blocking.release)
+
+	err := <-errCh
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "update cancelled")
+
+	assert.Equal(t, "sentry-go", inProgress)
+	assert.Equal(t, int32(1), blocking.calls.Load())
+
+	_, err = cacheManager.Get("sdk:sentry-go")
+	assert.NoError(t, err, "in-progress SDK should be cached")
+
+	for _, skipped := range []string{"sentry-python", "sentry-javascript"} {
+		_, err = cacheManager.Get("sdk:" + skipped)
+		assert.Error(t, err, "SDK %s should have been skipped", skipped)
+	}
+
+	assert.Equal(t, []string{"sentry-go"}, worker.drain.completed)
+	assert.Equal(t, []string{"sentry-python", "sentry-javascript"}, worker.drain.abandoned)
+}
+
+func TestStartReportsDrainStatus(t *testing.T) {
+	worker, _, blocking := newDrainTestWorker(t, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		worker.Start(ctx)
+		close(done)
+	}()
+
+	<-blocking.started
+	cancel()
+
+	// The worker must not finish while the in-progress SDK is still running
+	select {
+	case <-done:
+		t.Fatal("worker stopped before in-flight analysis finished")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(blocking.release)
+
+	select {
+	case status := <-worker.DrainStatus():
+		assert.False(t, status.TimedOut)
+		assert.Equal(t, []string{"sentry-go"}, status.Completed)
+		assert.Equal(t, []string{"sentry-python", "sentry-javascript"}, status.Abandoned)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain status was not reported")
+	}
+
+	<-done
+}
+
+func TestDrainTimeoutCancelsInFlightAnalysis(t *testing.T) {
+	worker, _, blocking := newDrainTestWorker(t, 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go worker.Start(ctx)
+
+	<-blocking.started
+	cancel()
+
+	// Never release: the drain timeout must cancel the analysis
+	select {
+	case status := <-worker.DrainStatus():
+		assert.True(t, status.TimedOut)
+		assert.Equal(t, []string{"sentry-python", "sentry-javascript"}, status.Abandoned)
+	case <-time.After(5 * time.Second):
+		t.Fatal("drain did not time out")
+	}
+}
+
+func TestDrainStatusWithoutStart(t *testing.T) {
+	worker, _, _ := newDrainTestWorker(t, time.Minute)
+
+	select {
+	case status := <-worker.DrainStatus():
+		assert.Empty(t, status.Completed)
+		assert.Empty(t, status.Abandoned)
+	case <-time.After(time.Second):
+		t.Fatal("drain status should be immediate for a worker that never started")
+	}
+}
@@ -0,0 +1,68 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// ErrSDKAnalyzerUnavailable is returned for work that needs the SDK
+// analyzer when it failed to initialize.
+var ErrSDKAnalyzerUnavailable = errors.New("SDK analyzer is not available")
+
+// CostEstimate is the estimated token usage and price of a full analysis
+// of an SDK at its current HEAD.
+type CostEstimate struct {
+	SDK     string `json:"sdk"`
+	Version string `json:"version"`
+
+	EstimatedInputTokens int `json:"estimated_input_tokens"`
+	// EstimatedOutputTokens is an upper bound: each pass may use up to
+	// analyzer.MaxOutputTokens
+	EstimatedOutputTokens int     `json:"estimated_output_tokens"`
+	EstimatedCostUSD      float64 `json:"estimated_cost_usd"`
+
+	FileCount  int   `json:"file_count"`
+	TotalBytes int64 `json:"total_bytes"`
+	Passes     int   `json:"passes"`
+}
+
+// EstimateSDKCost clones or updates the named SDK's repository and
+// estimates what analyzing it now would cost, priced with the configured
+// ClaudeCostPerInputMTok and ClaudeCostPerOutputMTok. Nothing is sent for
+// analysis.
+func (w *UpdateWorker) EstimateSDKCost(ctx context.Context, name string) (*CostEstimate, error) {
+	targets, err := resolveSDKs([]string{name})
+	if err != nil {
+		return nil, err
+	}
+	if w.sdkAnalyzer == nil {
+		return nil, ErrSDKAnalyzerUnavailable
+	}
+
+	input, err := w.sdkAnalyzer.EstimateInput(ctx, targets[0])
+	if err != nil {
+		return nil, err
+	}
+	return w.costEstimate(name, input), nil
+}
+
+// costEstimate prices input at the configured rates.
+func (w *UpdateWorker) costEstimate(name string, input *sdk.InputEstimate) *CostEstimate {
+	outputTokens := input.Passes * analyzer.MaxOutputTokens
+	cost := float64(input.Tokens)*w.config.ClaudeCostPerInputMTok/1e6 +
+		float64(outputTokens)*w.config.ClaudeCostPerOutputMTok/1e6
+
+	return &CostEstimate{
+		SDK:                   name,
+		Version:               input.Version,
+		EstimatedInputTokens:  input.Tokens,
+		EstimatedOutputTokens: outputTokens,
+		EstimatedCostUSD:      cost,
+		FileCount:             input.Files,
+		TotalBytes:            input.TotalBytes,
+		Passes:                input.Passes,
+	}
+}
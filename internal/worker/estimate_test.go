@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func TestCostEstimate(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	w.config.ClaudeCostPerInputMTok = 3
+	w.config.ClaudeCostPerOutputMTok = 15
+
+	estimate := w.costEstimate("sentry-go", &sdk.InputEstimate{
+		Version:    "abc1234",
+		Files:      42,
+		TotalBytes: 84000,
+		Passes:     2,
+		Tokens:     500000,
+	})
+
+	assert.Equal(t, "sentry-go", estimate.SDK)
+	assert.Equal(t, "abc1234", estimate.Version)
+	assert.Equal(t, 500000, estimate.EstimatedInputTokens)
+	assert.Equal(t, 2*analyzer.MaxOutputTokens, estimate.EstimatedOutputTokens)
+	// 0.5M input tokens at $3 plus 8192 output tokens at $15 per million
+	assert.InDelta(t, 1.5+0.12288, estimate.EstimatedCostUSD, 1e-9)
+	assert.Equal(t, 42, estimate.FileCount)
+	assert.Equal(t, int64(84000), estimate.TotalBytes)
+	assert.Equal(t, 2, estimate.Passes)
+}
+
+func TestEstimateSDKCostErrors(t *testing.T) {
+	w := newBackoffTestWorker(t)
+
+	_, err := w.EstimateSDKCost(context.Background(), "sentry-cobol")
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+
+	_, err = w.EstimateSDKCost(context.Background(), "sentry-go")
+	require.ErrorIs(t, err, ErrSDKAnalyzerUnavailable)
+}
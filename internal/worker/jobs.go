@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses.
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobCancelled = "cancelled"
+)
+
+// finishedJobRetention is how long finished jobs stay queryable.
+const finishedJobRetention = time.Hour
+
+// JobProgress is a snapshot of a background job's progress.
+type JobProgress struct {
+	JobID      string     `json:"job_id"`
+	Kind       string     `json:"kind"`
+	Status     string     `json:"status"`
+	Completed  int        `json:"completed"`
+	Total      int        `json:"total"`
+	Errors     int        `json:"errors"`
+	Skipped    int        `json:"skipped"`
+	Percent    float64    `json:"percent"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Job tracks the progress of a background operation over a fixed number of
+// items. It is safe for concurrent use.
+type Job struct {
+	mu       sync.Mutex
+	progress JobProgress
+}
+
+// ID returns the job's identifier.
+func (j *Job) ID() string {
+	return j.progress.JobID
+}
+
+// Begin marks a queued job running over total items.
+func (j *Job) Begin(total int) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress.Status = JobRunning
+	j.progress.Total = total
+	j.progress.StartedAt = time.Now()
+}
+
+// Done records that an item finished, failing if err is non-nil.
+func (j *Job) Done(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress.Completed++
+	if err != nil {
+		j.progress.Errors++
+	}
+}
+
+// Skip records that an item needed no work.
+func (j *Job) Skip() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.progress.Completed++
+	j.progress.Skipped++
+}
+
+// Finish marks the job completed.
+func (j *Job) Finish() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.progress.Status = JobCompleted
+	j.progress.FinishedAt = &now
+}
+
+// Cancel marks a job that will not run cancelled.
+func (j *Job) Cancel() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	j.progress.Status = JobCancelled
+	j.progress.FinishedAt = &now
+}
+
+// Progress returns a snapshot of the job's progress.
+func (j *Job) Progress() JobProgress {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	progress := j.progress
+	switch {
+	case progress.StartedAt.IsZero():
+		// Queued, or cancelled before it started
+		progress.Percent = 0
+	case progress.Total > 0:
+		progress.Percent = float64(progress.Completed) * 100 / float64(progress.Total)
+	default:
+		progress.Percent = 100
+	}
+	return progress
+}
+
+// finishedBefore reports whether the job finished before t.
+func (j *Job) finishedBefore(t time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.progress.FinishedAt != nil && j.progress.FinishedAt.Before(t)
+}
+
+// JobRegistry tracks background jobs by ID. Finished jobs are forgotten
+// after an hour.
+type JobRegistry struct {
+	mu   sync.RWMutex
+	jobs map[string]*Job
+}
+
+// NewJobRegistry creates an empty job registry.
+func NewJobRegistry() *JobRegistry {
+	return &JobRegistry{jobs: make(map[string]*Job)}
+}
+
+// Start registers a running job of the given kind over total items.
+func (r *JobRegistry) Start(kind string, total int) *Job {
+	job := r.Enqueue(kind)
+	job.Begin(total)
+	return job
+}
+
+// Enqueue registers a job of the given kind that has not started yet. Call
+// Begin when it starts.
+func (r *JobRegistry) Enqueue(kind string) *Job {
+	job := &Job{progress: JobProgress{
+		JobID:  uuid.New().String(),
+		Kind:   kind,
+		Status: JobQueued,
+	}}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := time.Now().Add(-finishedJobRetention)
+	for id, j := range r.jobs {
+		if j.finishedBefore(cutoff) {
+			delete(r.jobs, id)
+		}
+	}
+	r.jobs[job.ID()] = job
+	return job
+}
+
+// Get returns the job with the given ID.
+func (r *JobRegistry) Get(id string) (*Job, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, ok := r.jobs[id]
+	return job, ok
+}
+
+// remove forgets a job that will never run.
+func (r *JobRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.jobs, id)
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobProgress(t *testing.T) {
+	registry := NewJobRegistry()
+	job := registry.Start("test", 5)
+
+	got, ok := registry.Get(job.ID())
+	require.True(t, ok)
+	assert.Same(t, job, got)
+
+	job.Done(nil)
+	job.Done(errors.New("failed"))
+	job.Skip()
+
+	progress := job.Progress()
+	assert.Equal(t, JobRunning, progress.Status)
+	assert.Equal(t, 3, progress.Completed)
+	assert.Equal(t, 5, progress.Total)
+	assert.Equal(t, 1, progress.Errors)
+	assert.Equal(t, 1, progress.Skipped)
+	assert.InDelta(t, 60.0, progress.Percent, 0.001)
+	assert.Nil(t, progress.FinishedAt)
+
+	job.Finish()
+	progress = job.Progress()
+	assert.Equal(t, JobCompleted, progress.Status)
+	assert.NotNil(t, progress.FinishedAt)
+
+	_, ok = registry.Get("missing")
+	assert.False(t, ok)
+}
+
+func TestJobProgressEmpty(t *testing.T) {
+	job := NewJobRegistry().Start("test", 0)
+	assert.Equal(t, 100.0, job.Progress().Percent)
+}
+
+func TestJobRegistryForgetsFinishedJobs(t *testing.T) {
+	registry := NewJobRegistry()
+
+	old := registry.Start("test", 1)
+	old.Finish()
+	finishedAt := time.Now().Add(-2 * finishedJobRetention)
+	old.progress.FinishedAt = &finishedAt
+
+	running := registry.Start("test", 1)
+	registry.Start("test", 1)
+
+	_, ok := registry.Get(old.ID())
+	assert.False(t, ok)
+	_, ok = registry.Get(running.ID())
+	assert.True(t, ok)
+}
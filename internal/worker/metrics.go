@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metricsAlpha is the smoothing factor of the worker moving averages.
+const metricsAlpha = 0.2
+
+// recentRunCount is how many run summaries WorkerMetrics keeps.
+const recentRunCount = 10
+
+// RunSummary describes a single cache update run.
+type RunSummary struct {
+	StartedAt          time.Time     `json:"started_at"`
+	Duration           time.Duration `json:"duration"`
+	Succeeded          int           `json:"succeeded"`
+	Failed             int           `json:"failed"`
+	Skipped            int           `json:"skipped"`
+	TokensUsed         int           `json:"tokens_used"`
+	IncrementalUpdates int           `json:"incremental_updates"`
+	Error              string        `json:"error,omitempty"`
+}
+
+// SuccessRate returns the fraction of SDKs analyzed successfully. A run
+// that analyzed nothing counts as fully successful unless it failed.
+func (r RunSummary) SuccessRate() float64 {
+	total := r.Succeeded + r.Failed
+	if total == 0 {
+		if r.Error != "" {
+			return 0
+		}
+		return 1
+	}
+	return float64(r.Succeeded) / float64(total)
+}
+
+// WorkerMetrics holds exponential moving averages over cache update runs
+// and a ring buffer of the most recent runs.
+type WorkerMetrics struct {
+	SuccessRateEMA  float64
+	AvgDurationEMA  float64 // Seconds
+	TokensPerRunEMA float64
+	LastNRuns       [recentRunCount]RunSummary
+
+	// Runs is the total number of runs recorded
+	Runs int
+	// IncrementalUpdates is the total number of incremental SDK updates
+	IncrementalUpdates int64
+	// RepoCleaned is the total number of repositories removed because their
+	// SDK was no longer active
+	RepoCleaned int64
+}
+
+// record folds run into the moving averages. The first run seeds them.
+func (m *WorkerMetrics) record(run RunSummary) {
+	successRate := run.SuccessRate()
+	duration := run.Duration.Seconds()
+	tokens := float64(run.TokensUsed)
+
+	if m.Runs == 0 {
+		m.SuccessRateEMA = successRate
+		m.AvgDurationEMA = duration
+		m.TokensPerRunEMA = tokens
+	} else {
+		m.SuccessRateEMA = ema(m.SuccessRateEMA, successRate)
+		m.AvgDurationEMA = ema(m.AvgDurationEMA, duration)
+		m.TokensPerRunEMA = ema(m.TokensPerRunEMA, tokens)
+	}
+
+	m.LastNRuns[m.Runs%recentRunCount] = run
+	m.Runs++
+	m.IncrementalUpdates += int64(run.IncrementalUpdates)
+}
+
+// RecentRuns returns the recorded runs in LastNRuns, newest first.
+func (m *WorkerMetrics) RecentRuns() []RunSummary {
+	n := min(m.Runs, recentRunCount)
+	runs := make([]RunSummary, 0, n)
+	for i := 1; i <= n; i++ {
+		runs = append(runs, m.LastNRuns[(m.Runs-i)%recentRunCount])
+	}
+	return runs
+}
+
+func ema(previous, value float64) float64 {
+	return metricsAlpha*value + (1-metricsAlpha)*previous
+}
+
+// Metrics returns a snapshot of the worker run metrics.
+func (w *UpdateWorker) Metrics() WorkerMetrics {
+	w.metricsMu.RLock()
+	defer w.metricsMu.RUnlock()
+	return w.metrics
+}
+
+func (w *UpdateWorker) recordRun(run RunSummary) {
+	w.metricsMu.Lock()
+	defer w.metricsMu.Unlock()
+	w.metrics.record(run)
+}
+
+// RegisterMetrics registers Prometheus gauges for the worker run metrics.
+func (w *UpdateWorker) RegisterMetrics(reg prometheus.Registerer) error {
+	gauges := []prometheus.Collector{
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "claude_cache_worker_success_rate_ema",
+			Help: "Moving average of the fraction of SDKs analyzed successfully per update run.",
+		}, func() float64 { return w.Metrics().SuccessRateEMA }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "claude_cache_worker_duration_seconds_ema",
+			Help: "Moving average of update run duration in seconds.",
+		}, func() float64 { return w.Metrics().AvgDurationEMA }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "claude_cache_worker_tokens_per_run_ema",
+			Help: "Moving average of Claude tokens used per update run.",
+		}, func() float64 { return w.Metrics().TokensPerRunEMA }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "claude_cache_worker_runs_total",
+			Help: "Number of update runs recorded.",
+		}, func() float64 { return float64(w.Metrics().Runs) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "claude_cache_worker_incremental_updates_total",
+			Help: "Number of SDK analyses updated from changed files only.",
+		}, func() float64 { return float64(w.Metrics().IncrementalUpdates) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Name: "claude_cache_worker_repos_cleaned_total",
+			Help: "Number of cloned repositories removed because their SDK was no longer active.",
+		}, func() float64 { return float64(w.Metrics().RepoCleaned) }),
+	}
+
+	for _, gauge := range gauges {
+		if err := reg.Register(gauge); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerMetricsConvergence(t *testing.T) {
+	tests := []struct {
+		name             string
+		first            RunSummary
+		steady           RunSummary
+		expectedSuccess  float64
+		expectedDuration float64
+		expectedTokens   float64
+	}{
+		{
+			name:             "slow first run",
+			first:            RunSummary{Duration: 20 * time.Second, Succeeded: 3, TokensUsed: 6000},
+			steady:           RunSummary{Duration: 10 * time.Second, Succeeded: 3, TokensUsed: 3000},
+			expectedSuccess:  1,
+			expectedDuration: 10,
+			expectedTokens:   3000,
+		},
+		{
+			name:             "failing first run",
+			first:            RunSummary{Duration: time.Second, Failed: 3, Error: "upstream unavailable"},
+			steady:           RunSummary{Duration: 30 * time.Second, Succeeded: 3, Failed: 1, TokensUsed: 1200},
+			expectedSuccess:  0.75,
+			expectedDuration: 30,
+			expectedTokens:   1200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var m WorkerMetrics
+			m.record(tt.first)
+			for i := 1; i < 20; i++ {
+				m.record(tt.steady)
+			}
+
+			assert.Equal(t, 20, m.Runs)
+			assert.InEpsilon(t, tt.expectedSuccess, m.SuccessRateEMA, 0.05)
+			assert.InEpsilon(t, tt.expectedDuration, m.AvgDurationEMA, 0.05)
+			assert.InEpsilon(t, tt.expectedTokens, m.TokensPerRunEMA, 0.05)
+		})
+	}
+}
+
+func TestWorkerMetricsFirstRunSeeds(t *testing.T) {
+	var m WorkerMetrics
+	m.record(RunSummary{Duration: 4 * time.Second, Succeeded: 1, Failed: 1, TokensUsed: 100})
+
+	assert.Equal(t, 0.5, m.SuccessRateEMA)
+	assert.Equal(t, 4.0, m.AvgDurationEMA)
+	assert.Equal(t, 100.0, m.TokensPerRunEMA)
+
+	m.record(RunSummary{Duration: 9 * time.Second, Succeeded: 1, TokensUsed: 600, IncrementalUpdates: 1})
+
+	assert.InDelta(t, 0.6, m.SuccessRateEMA, 1e-9)
+	assert.InDelta(t, 5.0, m.AvgDurationEMA, 1e-9)
+	assert.InDelta(t, 200.0, m.TokensPerRunEMA, 1e-9)
+	assert.Equal(t, int64(1), m.IncrementalUpdates)
+}
+
+func TestWorkerMetricsRecentRuns(t *testing.T) {
+	var m WorkerMetrics
+	assert.Empty(t, m.RecentRuns())
+
+	for i := 1; i <= 3; i++ {
+		m.record(RunSummary{TokensUsed: i})
+	}
+	runs := m.RecentRuns()
+	require.Len(t, runs, 3)
+	assert.Equal(t, 3, runs[0].TokensUsed, "newest run first")
+	assert.Equal(t, 1, runs[2].TokensUsed)
+
+	for i := 4; i <= 25; i++ {
+		m.record(RunSummary{TokensUsed: i})
+	}
+	runs = m.RecentRuns()
+	require.Len(t, runs, recentRunCount)
+	assert.Equal(t, 25, runs[0].TokensUsed)
+	assert.Equal(t, 16, runs[recentRunCount-1].TokensUsed)
+}
+
+func TestRunSummarySuccessRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		run      RunSummary
+		expected float64
+	}{
+		{"all succeeded", RunSummary{Succeeded: 4}, 1},
+		{"partial", RunSummary{Succeeded: 1, Failed: 3}, 0.25},
+		{"nothing analyzed", RunSummary{}, 1},
+		{"failed before analyzing", RunSummary{Error: "failed to load SDKs"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.run.SuccessRate())
+		})
+	}
+}
+
+func TestRegisterMetrics(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	w.recordRun(RunSummary{Duration: 2 * time.Second, Succeeded: 1, TokensUsed: 50})
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, w.RegisterMetrics(reg))
+
+	expected := `
+# HELP claude_cache_worker_duration_seconds_ema Moving average of update run duration in seconds.
+# TYPE claude_cache_worker_duration_seconds_ema gauge
+claude_cache_worker_duration_seconds_ema 2
+# HELP claude_cache_worker_tokens_per_run_ema Moving average of Claude tokens used per update run.
+# TYPE claude_cache_worker_tokens_per_run_ema gauge
+claude_cache_worker_tokens_per_run_ema 50
+`
+	err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"claude_cache_worker_duration_seconds_ema", "claude_cache_worker_tokens_per_run_ema")
+	assert.NoError(t, err)
+
+	assert.Error(t, w.RegisterMetrics(reg), "registering twice fails")
+}
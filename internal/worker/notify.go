@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+)
+
+// SetNotifier sets where new SDK analyses are announced; nil turns
+// notifications off. It must be called before Start.
+func (w *UpdateWorker) SetNotifier(notifier notify.Notifier) {
+	w.notifier = notifier
+}
+
+// Notifier returns the configured notifier, or nil.
+func (w *UpdateWorker) Notifier() notify.Notifier {
+	return w.notifier
+}
+
+// storedEvent describes analysis, which storeLatest cached in place of the
+// value previous, empty if there was none. The diff comes from the value
+// storeLatest read, so notifying does not read the cache again.
+func (w *UpdateWorker) storedEvent(sdkName, previous string, analysis *analyzer.SDKAnalysis, incremental bool) notify.AnalysisEvent {
+	return analysisEvent(sdkName, w.decodeAnalysis(sdkName, previous), analysis, incremental)
+}
+
+// decodeAnalysis decodes a cached analysis of an SDK, returning nil if
+// value is empty or cannot be decoded.
+func (w *UpdateWorker) decodeAnalysis(sdkName, value string) *analyzer.SDKAnalysis {
+	if value == "" {
+		return nil
+	}
+
+	var analysis analyzer.SDKAnalysis
+	if err := json.Unmarshal([]byte(value), &analysis); err != nil {
+		w.logger.Debug().Err(err).Str("sdk", sdkName).Msg("Failed to decode cached analysis")
+		return nil
+	}
+	return &analysis
+}
+
+// analysisEvent describes a new analysis of an SDK and, unless previous is
+// nil, what changed since the previous one.
+func analysisEvent(sdkName string, previous, analysis *analyzer.SDKAnalysis, incremental bool) notify.AnalysisEvent {
+	event := notify.AnalysisEvent{
+		Type:            notify.EventAnalysisUpdated,
+		SDK:             sdkName,
+		AnalysisVersion: analysis.AnalysisVersion,
+		AnalyzedAt:      analysis.AnalyzedAt,
+		Incremental:     incremental,
+		TokensUsed:      analysis.TokensUsed,
+	}
+	if previous != nil {
+		diff := analyzer.DiffAnalyses(previous, analysis)
+		event.Diff = &diff
+	}
+	return event
+}
+
+// sendNotifications delivers events, logging failures. Delivery continues
+// while the worker drains, so it does not follow ctx's cancellation.
+func (w *UpdateWorker) sendNotifications(ctx context.Context, events []notify.AnalysisEvent) {
+	if w.notifier == nil {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	for _, event := range events {
+		if err := w.notifier.Notify(ctx, event); err != nil {
+			w.logger.Error().
+				Err(err).
+				Str("sdk", event.SDK).
+				Msg("Failed to send analysis notification")
+		}
+	}
+}
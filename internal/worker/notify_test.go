@@ -0,0 +1,139 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
+)
+
+// recordingNotifier records the events it is sent and fails with err.
+type recordingNotifier struct {
+	events []notify.AnalysisEvent
+	err    error
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event notify.AnalysisEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestNewUpdateWorkerNotifier(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	cfg := &config.Config{UpdateSchedule: "0 2 * * 0", CacheTTL: time.Hour, CacheDir: tempDir}
+	assert.Nil(t, NewUpdateWorker(cacheManager, logger, cfg).Notifier())
+
+	cfg.WebhookURL = "https://hooks.example.com/sdk-updates"
+	assert.IsType(t, &notify.WebhookNotifier{}, NewUpdateWorker(cacheManager, logger, cfg).Notifier())
+}
+
+func TestAnalysisEvent(t *testing.T) {
+	analyzedAt := time.Now().Truncate(time.Second)
+	previous := &analyzer.SDKAnalysis{Language: "go", Features: []string{"breadcrumbs"}}
+	current := &analyzer.SDKAnalysis{
+		Language:        "go",
+		Features:        []string{"breadcrumbs", "sessions"},
+		TokensUsed:      1200,
+		AnalyzedAt:      analyzedAt,
+		AnalysisVersion: "1.0.0",
+	}
+
+	event := analysisEvent("sentry-go", previous, current, true)
+	assert.Equal(t, notify.EventAnalysisUpdated, event.Type)
+	assert.Equal(t, "sentry-go", event.SDK)
+	assert.Equal(t, "1.0.0", event.AnalysisVersion)
+	assert.True(t, event.AnalyzedAt.Equal(analyzedAt))
+	assert.True(t, event.Incremental)
+	assert.Equal(t, 1200, event.TokensUsed)
+	require.NotNil(t, event.Diff)
+	assert.Equal(t, []string{"sessions"}, event.Diff.Lists["features"].Added)
+
+	assert.Nil(t, analysisEvent("sentry-go", nil, current, false).Diff, "first analyses have no diff")
+}
+
+func TestStoreAnalysisNotifies(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	cfg := &config.Config{UpdateSchedule: "0 2 * * 0", CacheTTL: time.Hour, CacheDir: tempDir}
+	w := NewUpdateWorker(cacheManager, logger, cfg)
+	notifier := &recordingNotifier{}
+	w.SetNotifier(notifier)
+
+	analyzedAt := time.Now().Truncate(time.Second)
+	first := &analyzer.SDKAnalysis{Language: "go", ProtocolVersion: "7", AnalysisVersion: "1.0.0", AnalyzedAt: analyzedAt}
+	require.NoError(t, w.storeAnalysis(context.Background(), "sentry-go", first))
+	second := &analyzer.SDKAnalysis{Language: "go", ProtocolVersion: "8", AnalysisVersion: "1.0.1", AnalyzedAt: analyzedAt.Add(time.Minute)}
+	require.NoError(t, w.storeAnalysis(context.Background(), "sentry-go", second))
+
+	// An analysis older than the cached one is neither stored nor announced
+	stale := &analyzer.SDKAnalysis{Language: "go", AnalysisVersion: "0.9.0", AnalyzedAt: analyzedAt.Add(-time.Minute)}
+	require.NoError(t, w.storeAnalysis(context.Background(), "sentry-go", stale))
+
+	require.Len(t, notifier.events, 2)
+	assert.Nil(t, notifier.events[0].Diff, "first analyses have no diff")
+	assert.Equal(t, "1.0.1", notifier.events[1].AnalysisVersion)
+	require.NotNil(t, notifier.events[1].Diff)
+	require.Len(t, notifier.events[1].Diff.Changed, 1)
+	assert.Equal(t, "protocol_version", notifier.events[1].Diff.Changed[0].Field)
+
+	// Diffing against the previous analysis does not count as a cache hit
+	assert.Zero(t, cacheManager.GetStats().Hits)
+}
+
+func TestDecodeAnalysis(t *testing.T) {
+	w := newBackoffTestWorker(t)
+
+	assert.Nil(t, w.decodeAnalysis("sentry-go", ""))
+	assert.Nil(t, w.decodeAnalysis("sentry-go", "not json"))
+
+	analysisJSON, err := json.Marshal(analyzer.SDKAnalysis{Language: "go", ProtocolVersion: "7"})
+	require.NoError(t, err)
+	decoded := w.decodeAnalysis("sentry-go", string(analysisJSON))
+	require.NotNil(t, decoded)
+	assert.Equal(t, "7", decoded.ProtocolVersion)
+}
+
+func TestSendNotifications(t *testing.T) {
+	w := newBackoffTestWorker(t)
+	events := []notify.AnalysisEvent{{SDK: "sentry-go"}, {SDK: "sentry-python"}}
+
+	// Without a notifier nothing is sent
+	w.sendNotifications(context.Background(), events)
+
+	// A failure does not stop later events, and a draining run still notifies
+	notifier := &recordingNotifier{err: errors.New("webhook down")}
+	w.SetNotifier(notifier)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w.sendNotifications(ctx, events)
+	assert.Equal(t, events, notifier.events)
+}
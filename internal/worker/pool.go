@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// highPriorityShare is the fraction of slots reserved for high-priority SDKs.
+const highPriorityShare = 0.8
+
+// PriorityWorkerPool limits concurrent analyses per priority tier so a burst
+// of low-priority work cannot delay high-priority SDKs. High-priority
+// analyses share 80% of the slots; normal and low-priority analyses share
+// the rest. Each semaphore has at least one slot.
+type PriorityWorkerPool struct {
+	high     chan struct{}
+	standard chan struct{}
+
+	mu    sync.Mutex
+	tiers map[int]*tierCounters
+}
+
+// tierCounters tracks the analyses of one priority.
+type tierCounters struct {
+	active    int
+	waiting   int
+	completed int
+}
+
+// TierStats describes slot usage of one priority tier.
+type TierStats struct {
+	Priority  string `json:"priority"`
+	Slots     int    `json:"slots"`
+	SlotsUsed int    `json:"slots_used"`
+	Active    int    `json:"active"`
+	Waiting   int    `json:"waiting"`
+	Completed int    `json:"completed"`
+}
+
+// NewPriorityWorkerPool creates a pool with maxConcurrent slots.
+func NewPriorityWorkerPool(maxConcurrent int) *PriorityWorkerPool {
+	maxConcurrent = max(maxConcurrent, 1)
+	highSlots := max(int(float64(maxConcurrent)*highPriorityShare), 1)
+	standardSlots := max(maxConcurrent-highSlots, 1)
+
+	return &PriorityWorkerPool{
+		high:     make(chan struct{}, highSlots),
+		standard: make(chan struct{}, standardSlots),
+		tiers: map[int]*tierCounters{
+			sdk.PriorityLow:    {},
+			sdk.PriorityNormal: {},
+			sdk.PriorityHigh:   {},
+		},
+	}
+}
+
+// Acquire waits for a slot of the given sdk priority and returns the
+// function releasing it. Unknown priorities are treated as normal.
+func (p *PriorityWorkerPool) Acquire(ctx context.Context, priority int) (func(), error) {
+	priority = sdk.Config{Priority: priority}.EffectivePriority()
+	sem := p.semaphore(priority)
+
+	p.update(priority, func(c *tierCounters) { c.waiting++ })
+	select {
+	case sem <- struct{}{}:
+	case <-ctx.Done():
+		p.update(priority, func(c *tierCounters) { c.waiting-- })
+		return nil, ctx.Err()
+	}
+	p.update(priority, func(c *tierCounters) {
+		c.waiting--
+		c.active++
+	})
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-sem
+			p.update(priority, func(c *tierCounters) {
+				c.active--
+				c.completed++
+			})
+		})
+	}, nil
+}
+
+// Stats returns slot usage per priority tier, highest priority first.
+func (p *PriorityWorkerPool) Stats() []TierStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]TierStats, 0, len(p.tiers))
+	for _, priority := range []int{sdk.PriorityHigh, sdk.PriorityNormal, sdk.PriorityLow} {
+		c := p.tiers[priority]
+		sem := p.semaphore(priority)
+		stats = append(stats, TierStats{
+			Priority:  priorityName(priority),
+			Slots:     cap(sem),
+			SlotsUsed: len(sem),
+			Active:    c.active,
+			Waiting:   c.waiting,
+			Completed: c.completed,
+		})
+	}
+	return stats
+}
+
+func (p *PriorityWorkerPool) semaphore(priority int) chan struct{} {
+	if priority == sdk.PriorityHigh {
+		return p.high
+	}
+	return p.standard
+}
+
+func (p *PriorityWorkerPool) update(priority int, fn func(c *tierCounters)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	fn(p.tiers[priority])
+}
+
+func priorityName(priority int) string {
+	switch priority {
+	case sdk.PriorityHigh:
+		return "high"
+	case sdk.PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// PoolStats returns slot usage of the worker's analysis pool.
+func (w *UpdateWorker) PoolStats() []TierStats {
+	return w.pool.Stats()
+}
@@ -0,0 +1,124 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func TestNewPriorityWorkerPoolSlots(t *testing.T) {
+	tests := []struct {
+		maxConcurrent int
+		high          int
+		standard      int
+	}{
+		{10, 8, 2},
+		{5, 4, 1},
+		{2, 1, 1},
+		{1, 1, 1},
+		{0, 1, 1},
+	}
+
+	for _, tt := range tests {
+		pool := NewPriorityWorkerPool(tt.maxConcurrent)
+		stats := pool.Stats()
+		require.Len(t, stats, 3)
+		assert.Equal(t, tt.high, stats[0].Slots, "high slots for %d", tt.maxConcurrent)
+		assert.Equal(t, tt.standard, stats[1].Slots, "normal slots for %d", tt.maxConcurrent)
+		assert.Equal(t, tt.standard, stats[2].Slots, "low slots for %d", tt.maxConcurrent)
+	}
+}
+
+func TestPriorityWorkerPoolHighPriorityFirst(t *testing.T) {
+	pool := NewPriorityWorkerPool(5)
+
+	var mu sync.Mutex
+	var order []int
+	run := func(priority int, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		release, err := pool.Acquire(context.Background(), priority)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer release()
+
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		order = append(order, priority)
+		mu.Unlock()
+	}
+
+	// Queue the low-priority burst first
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go run(sdk.PriorityLow, &wg)
+	}
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go run(sdk.PriorityHigh, &wg)
+	}
+	wg.Wait()
+
+	require.Len(t, order, 15)
+
+	// High-priority jobs use 4 slots and need two rounds; the single shared
+	// slot finishes at most a couple of low-priority jobs meanwhile
+	lastHigh := 0
+	for i, priority := range order {
+		if priority == sdk.PriorityHigh {
+			lastHigh = i
+		}
+	}
+	assert.Less(t, lastHigh, 8, "completion order: %v", order)
+
+	for _, tier := range pool.Stats() {
+		assert.Zero(t, tier.Active)
+		assert.Zero(t, tier.Waiting)
+		assert.Zero(t, tier.SlotsUsed)
+	}
+}
+
+func TestPriorityWorkerPoolLowRunsWhileHighSaturated(t *testing.T) {
+	pool := NewPriorityWorkerPool(5)
+	ctx := context.Background()
+
+	var releases []func()
+	for i := 0; i < 4; i++ {
+		release, err := pool.Acquire(ctx, sdk.PriorityHigh)
+		require.NoError(t, err)
+		releases = append(releases, release)
+	}
+
+	// High-priority slots are exhausted, so further high-priority jobs wait
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	_, err := pool.Acquire(waitCtx, sdk.PriorityHigh)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// Low-priority jobs still get their own slot
+	release, err := pool.Acquire(ctx, sdk.PriorityLow)
+	require.NoError(t, err)
+
+	stats := pool.Stats()
+	assert.Equal(t, TierStats{Priority: "high", Slots: 4, SlotsUsed: 4, Active: 4}, stats[0])
+	assert.Equal(t, TierStats{Priority: "low", Slots: 1, SlotsUsed: 1, Active: 1}, stats[2])
+
+	release()
+	release() // Releasing twice is a no-op
+	for _, r := range releases {
+		r()
+	}
+
+	stats = pool.Stats()
+	assert.Equal(t, TierStats{Priority: "high", Slots: 4, Completed: 4}, stats[0])
+	assert.Equal(t, TierStats{Priority: "normal", Slots: 1}, stats[1])
+	assert.Equal(t, TierStats{Priority: "low", Slots: 1, Completed: 1}, stats[2])
+}
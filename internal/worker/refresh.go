@@ -0,0 +1,281 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// JobKindRefresh identifies cache refresh jobs in the JobRegistry.
+const JobKindRefresh = "cache_refresh"
+
+// Refresh types.
+const (
+	// RefreshFull clears the SDK keys and re-analyzes every active SDK
+	RefreshFull = "full"
+	// RefreshIncremental re-analyzes active SDKs older than StaleThreshold
+	RefreshIncremental = "incremental"
+	// RefreshSpecific re-analyzes the SDKs listed in Targets
+	RefreshSpecific = "specific"
+)
+
+// refreshQueueSize is how many refresh jobs may wait to run.
+const refreshQueueSize = 32
+
+var (
+	// ErrInvalidRefresh is returned for a malformed refresh request.
+	ErrInvalidRefresh = errors.New("invalid refresh request")
+
+	// ErrQueueFull is returned when the refresh queue has no room.
+	ErrQueueFull = errors.New("refresh queue is full")
+)
+
+// RefreshRequest asks for a cache refresh. Type defaults to specific when
+// Targets are given and full otherwise. Incremental and specific refreshes
+// skip SDKs analyzed within StaleThreshold unless Force is set. SDKs named
+// in ForceSDK are analyzed before all others, regardless of priority.
+type RefreshRequest struct {
+	Type     string   `json:"type"`
+	Targets  []string `json:"targets"`
+	Force    bool     `json:"force"`
+	ForceSDK []string `json:"force_sdk"`
+}
+
+// RefreshJob is a queued cache refresh.
+type RefreshJob struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Targets   []string  `json:"targets,omitempty"`
+	Force     bool      `json:"force"`
+	ForceSDK  []string  `json:"force_sdk,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	job     *Job
+	configs []sdk.Config
+}
+
+// JobQueue holds refresh jobs waiting for the worker.
+type JobQueue struct {
+	mu   sync.Mutex
+	jobs chan *RefreshJob
+}
+
+// NewJobQueue creates a queue holding up to size jobs.
+func NewJobQueue(size int) *JobQueue {
+	return &JobQueue{jobs: make(chan *RefreshJob, size)}
+}
+
+// Push adds job to the queue and returns its estimated 1-based position,
+// or ErrQueueFull.
+func (q *JobQueue) Push(job *RefreshJob) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	select {
+	case q.jobs <- job:
+		return len(q.jobs), nil
+	default:
+		return 0, ErrQueueFull
+	}
+}
+
+// Len returns the number of jobs waiting to run.
+func (q *JobQueue) Len() int {
+	return len(q.jobs)
+}
+
+// cancelPending removes the jobs waiting to run, marks them cancelled and
+// returns them.
+func (q *JobQueue) cancelPending() []*RefreshJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var cancelled []*RefreshJob
+	for {
+		select {
+		case refresh := <-q.jobs:
+			refresh.job.Cancel()
+			cancelled = append(cancelled, refresh)
+		default:
+			return cancelled
+		}
+	}
+}
+
+// EnqueueRefresh validates req and queues it, returning the job and its
+// estimated position in the queue. Progress is tracked in Jobs under the
+// job's ID.
+func (w *UpdateWorker) EnqueueRefresh(req RefreshRequest) (*RefreshJob, int, error) {
+	refreshType := req.Type
+	if refreshType == "" {
+		refreshType = RefreshFull
+		if len(req.Targets) > 0 {
+			refreshType = RefreshSpecific
+		}
+	}
+
+	var targets []sdk.Config
+	switch refreshType {
+	case RefreshFull, RefreshIncremental:
+		if len(req.Targets) > 0 {
+			return nil, 0, fmt.Errorf("%w: targets are only allowed for %s refreshes", ErrInvalidRefresh, RefreshSpecific)
+		}
+	case RefreshSpecific:
+		if len(req.Targets) == 0 {
+			return nil, 0, fmt.Errorf("%w: %s refreshes require targets", ErrInvalidRefresh, RefreshSpecific)
+		}
+		var err error
+		if targets, err = resolveSDKs(req.Targets); err != nil {
+			return nil, 0, err
+		}
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown type %q, must be %s, %s or %s", ErrInvalidRefresh, req.Type, RefreshFull, RefreshIncremental, RefreshSpecific)
+	}
+
+	if len(req.ForceSDK) > 0 {
+		if _, err := resolveSDKs(req.ForceSDK); err != nil {
+			return nil, 0, err
+		}
+		if refreshType == RefreshSpecific {
+			for _, name := range req.ForceSDK {
+				if !slices.Contains(req.Targets, name) {
+					return nil, 0, fmt.Errorf("%w: force_sdk entry %s is not a target", ErrInvalidRefresh, name)
+				}
+			}
+		}
+	}
+
+	if w.isDraining() {
+		return nil, 0, ErrDraining
+	}
+
+	job := w.jobs.Enqueue(JobKindRefresh)
+	refresh := &RefreshJob{
+		ID:        job.ID(),
+		Type:      refreshType,
+		Targets:   req.Targets,
+		Force:     req.Force,
+		ForceSDK:  req.ForceSDK,
+		CreatedAt: time.Now(),
+		job:       job,
+		configs:   targets,
+	}
+
+	position, err := w.refreshQueue.Push(refresh)
+	if err != nil {
+		w.jobs.remove(job.ID())
+		return nil, 0, err
+	}
+
+	w.logger.Info().
+		Str("job_id", refresh.ID).
+		Str("type", refresh.Type).
+		Strs("targets", refresh.Targets).
+		Strs("force_sdk", refresh.ForceSDK).
+		Int("position", position).
+		Msg("Cache refresh queued")
+	return refresh, position, nil
+}
+
+// resolveSDKs looks up the named SDKs, ignoring duplicates.
+func resolveSDKs(names []string) ([]sdk.Config, error) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SDK configs: %w", err)
+	}
+
+	targets := make([]sdk.Config, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		cfg, ok := configs.FindSDK(name)
+		if !ok {
+			return nil, &apperrors.SDKNotFoundError{Name: name}
+		}
+		targets = append(targets, *cfg)
+	}
+	return targets, nil
+}
+
+// processRefreshJobs runs queued refresh jobs one at a time until ctx is
+// cancelled. Jobs still queued at shutdown are not run and are marked
+// cancelled.
+func (w *UpdateWorker) processRefreshJobs(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			for _, refresh := range w.refreshQueue.cancelPending() {
+				w.logger.Info().Str("job_id", refresh.ID).Msg("Cancelled queued cache refresh at shutdown")
+			}
+			return
+		case refresh := <-w.refreshQueue.jobs:
+			w.runRefresh(ctx, refresh)
+		}
+	}
+}
+
+// runRefresh executes a refresh job. It waits for any scheduled update in
+// progress so the two never analyze the same SDKs concurrently.
+func (w *UpdateWorker) runRefresh(ctx context.Context, refresh *RefreshJob) {
+	if !w.beginRun() {
+		w.logger.Debug().Str("job_id", refresh.ID).Msg("Skipping cache refresh while draining")
+		refresh.job.Cancel()
+		return
+	}
+	defer w.endRun()
+
+	w.updateMu.Lock()
+	defer w.updateMu.Unlock()
+
+	w.logger.Info().
+		Str("job_id", refresh.ID).
+		Str("type", refresh.Type).
+		Msg("Starting cache refresh")
+
+	switch refresh.Type {
+	case RefreshFull:
+		refresh.job.Begin(1)
+		// Drop every SDK key so analyses of removed SDKs do not linger
+		if _, err := w.cache.DeleteByPrefix("sdk:"); err != nil {
+			w.logger.Error().Err(err).Str("job_id", refresh.ID).Msg("Failed to clear SDK cache keys")
+		}
+		err := w.updateCache(ctx, false, refresh.ForceSDK...)
+		if err != nil {
+			w.logger.Error().Err(err).Str("job_id", refresh.ID).Msg("Cache refresh failed")
+		}
+		refresh.job.Done(err)
+		refresh.job.Finish()
+	case RefreshIncremental:
+		configs, err := sdk.LoadConfigs()
+		if err != nil {
+			w.logger.Error().Err(err).Str("job_id", refresh.ID).Msg("Failed to load SDK configs")
+			refresh.job.Begin(1)
+			refresh.job.Done(err)
+			refresh.job.Finish()
+			return
+		}
+		targets := configs.GetActiveSDKs()
+		refresh.job.Begin(len(targets))
+		w.warm(refresh.job, targets, refresh.Force, refresh.ForceSDK)
+	case RefreshSpecific:
+		refresh.job.Begin(len(refresh.configs))
+		w.warm(refresh.job, refresh.configs, refresh.Force, refresh.ForceSDK)
+	}
+}
+
+// isDraining reports whether the worker has begun shutting down.
+func (w *UpdateWorker) isDraining() bool {
+	w.drain.mu.Lock()
+	defer w.drain.mu.Unlock()
+	return w.drain.draining
+}
@@ -0,0 +1,155 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+)
+
+func TestEnqueueRefreshValidation(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          RefreshRequest
+		expectedType string
+		expectedErr  error
+	}{
+		{name: "default full", req: RefreshRequest{}, expectedType: RefreshFull},
+		{name: "default specific", req: RefreshRequest{Targets: []string{"sentry-go"}}, expectedType: RefreshSpecific},
+		{name: "incremental", req: RefreshRequest{Type: RefreshIncremental}, expectedType: RefreshIncremental},
+		{name: "unknown type", req: RefreshRequest{Type: "partial"}, expectedErr: ErrInvalidRefresh},
+		{name: "specific without targets", req: RefreshRequest{Type: RefreshSpecific}, expectedErr: ErrInvalidRefresh},
+		{name: "full with targets", req: RefreshRequest{Type: RefreshFull, Targets: []string{"sentry-go"}}, expectedErr: ErrInvalidRefresh},
+		{name: "unknown SDK", req: RefreshRequest{Targets: []string{"no-such-sdk"}}, expectedErr: ErrUnknownSDK},
+		{name: "full with force_sdk", req: RefreshRequest{ForceSDK: []string{"sentry-python"}}, expectedType: RefreshFull},
+		{name: "specific with force_sdk", req: RefreshRequest{Targets: []string{"sentry-go", "sentry-python"}, ForceSDK: []string{"sentry-python"}}, expectedType: RefreshSpecific},
+		{name: "unknown force_sdk", req: RefreshRequest{ForceSDK: []string{"no-such-sdk"}}, expectedErr: ErrUnknownSDK},
+		{name: "force_sdk not a target", req: RefreshRequest{Targets: []string{"sentry-go"}, ForceSDK: []string{"sentry-python"}}, expectedErr: ErrInvalidRefresh},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			worker, _, _ := newWarmTestWorker(t, 2)
+
+			job, position, err := worker.EnqueueRefresh(tt.req)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Zero(t, worker.refreshQueue.Len())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedType, job.Type)
+			assert.Equal(t, tt.req.ForceSDK, job.ForceSDK)
+			assert.Equal(t, 1, position)
+
+			tracked, ok := worker.Jobs().Get(job.ID)
+			require.True(t, ok)
+			progress := tracked.Progress()
+			assert.Equal(t, JobKindRefresh, progress.Kind)
+			assert.Equal(t, JobQueued, progress.Status)
+			assert.Zero(t, progress.Percent)
+		})
+	}
+}
+
+func TestEnqueueRefreshQueueFull(t *testing.T) {
+	worker, _, _ := newWarmTestWorker(t, 2)
+
+	for i := 1; i <= refreshQueueSize; i++ {
+		_, position, err := worker.EnqueueRefresh(RefreshRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, i, position)
+	}
+
+	_, _, err := worker.EnqueueRefresh(RefreshRequest{})
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+func TestProcessRefreshJobs(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 2)
+	close(gated.release)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-python:last_analyzed", time.Now().Format(time.RFC3339), 0))
+	require.NoError(t, cacheManager.Set("sdk:removed-sdk", "stale", 0))
+	require.NoError(t, cacheManager.Set("project:sentry", "kept", 0))
+
+	specific, _, err := worker.EnqueueRefresh(RefreshRequest{Targets: []string{"sentry-go", "sentry-python"}})
+	require.NoError(t, err)
+	full, position, err := worker.EnqueueRefresh(RefreshRequest{Type: RefreshFull})
+	require.NoError(t, err)
+	assert.Equal(t, 2, position)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.processRefreshJobs(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	for _, id := range []string{specific.ID, full.ID} {
+		job, ok := worker.Jobs().Get(id)
+		require.True(t, ok)
+		require.Eventually(t, func() bool { return job.Progress().Status == JobCompleted }, 5*time.Second, 5*time.Millisecond)
+	}
+
+	// The specific refresh skips the fresh SDK
+	specificJob, _ := worker.Jobs().Get(specific.ID)
+	progress := specificJob.Progress()
+	assert.Equal(t, 2, progress.Total)
+	assert.Equal(t, 1, progress.Skipped)
+	assert.Equal(t, 0, progress.Errors)
+
+	// The full refresh runs a whole update cycle
+	fullJob, _ := worker.Jobs().Get(full.ID)
+	progress = fullJob.Progress()
+	assert.Equal(t, 1, progress.Total)
+	assert.Equal(t, 0, progress.Errors)
+	assert.Equal(t, 1, worker.Metrics().Runs)
+
+	// and clears SDK keys beforehand, leaving other keys alone
+	_, err = cacheManager.Get("sdk:removed-sdk")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+	value, err := cacheManager.Get("project:sentry")
+	require.NoError(t, err)
+	assert.Equal(t, "kept", value)
+
+	assert.Equal(t, []string{"sentry-go", "sentry-go", "sentry-python", "sentry-javascript"}, gated.Calls())
+}
+
+func TestRefreshJobsCancelledWhileDraining(t *testing.T) {
+	worker, _, gated := newWarmTestWorker(t, 2)
+	close(gated.release)
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		refresh, _, err := worker.EnqueueRefresh(RefreshRequest{Type: RefreshFull})
+		require.NoError(t, err)
+		ids = append(ids, refresh.ID)
+	}
+	worker.drain.draining = true
+
+	// Jobs taken from the queue while draining and those left in it at
+	// shutdown are cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	worker.processRefreshJobs(ctx)
+
+	assert.Zero(t, worker.refreshQueue.Len())
+	for _, id := range ids {
+		job, ok := worker.Jobs().Get(id)
+		require.True(t, ok)
+		progress := job.Progress()
+		assert.Equal(t, JobCancelled, progress.Status)
+		assert.NotNil(t, progress.FinishedAt)
+		assert.Zero(t, progress.Percent)
+	}
+	assert.Empty(t, gated.Calls())
+}
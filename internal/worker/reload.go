@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// UpdateSchedule returns the cron spec that scheduled updates run on.
+func (w *UpdateWorker) UpdateSchedule() string {
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+
+	if w.schedule == "" {
+		return w.config.UpdateSchedule
+	}
+	return w.schedule
+}
+
+// scheduleUpdates runs scheduled updates on spec, replacing the previous
+// schedule. The previous schedule stays in place if spec is invalid.
+func (w *UpdateWorker) scheduleUpdates(ctx context.Context, spec string) error {
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+
+	entry, err := w.cron.AddFunc(spec, func() {
+		w.runScheduledUpdate(ctx)
+	})
+	if err != nil {
+		return fmt.Errorf("invalid update schedule %q: %w", spec, err)
+	}
+
+	if w.updateEntry != 0 {
+		w.cron.Remove(w.updateEntry)
+	}
+	w.updateEntry = entry
+	w.schedule = spec
+	return nil
+}
+
+// WatchConfig reschedules updates when UpdateSchedule changes, and cleans
+// up repositories of disabled SDKs and reschedules SDKs with their own
+// schedule after every change, until changes is closed or ctx is
+// cancelled. Pass the context given to Start.
+func (w *UpdateWorker) WatchConfig(ctx context.Context, changes <-chan config.ConfigChange) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case change, ok := <-changes:
+			if !ok {
+				return
+			}
+			w.RequestRepoCleanup()
+			w.RequestSDKReschedule()
+			if !change.Changed("UpdateSchedule") {
+				continue
+			}
+
+			if err := w.scheduleUpdates(ctx, change.New.UpdateSchedule); err != nil {
+				w.logger.Error().Err(err).Msg("Failed to reschedule updates, keeping previous schedule")
+				continue
+			}
+			w.logger.Info().
+				Str("schedule", change.New.UpdateSchedule).
+				Msg("Rescheduled cache updates")
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// entrySchedule returns the schedule of the worker's update cron entry.
+func entrySchedule(w *UpdateWorker) cron.Schedule {
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+	return w.cron.Entry(w.updateEntry).Schedule
+}
+
+func TestWatchConfigReschedulesUpdates(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+	// Load sets UPDATE_SCHEDULE from .env; t.Setenv restores it afterwards
+	t.Setenv("UPDATE_SCHEDULE", "")
+	require.NoError(t, os.Unsetenv("UPDATE_SCHEDULE"))
+
+	dotenv := filepath.Join(dir, ".env")
+	require.NoError(t, os.WriteFile(dotenv, []byte("UPDATE_SCHEDULE=0 2 * * 0\n"), 0o644))
+	cfg, err := config.Load(zerolog.Nop())
+	require.NoError(t, err)
+
+	logger := zerolog.Nop()
+	cacheManager, err := cache.NewManager(t.TempDir(), logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, worker.scheduleUpdates(ctx, cfg.UpdateSchedule))
+	initial := worker.updateEntry
+
+	watcher, err := config.NewWatcher(cfg, logger)
+	require.NoError(t, err)
+	go worker.WatchConfig(ctx, watcher.Subscribe())
+	go watcher.Run(ctx)
+
+	require.NoError(t, os.WriteFile(dotenv, []byte("UPDATE_SCHEDULE=30 4 * * *\n"), 0o644))
+
+	require.Eventually(t, func() bool {
+		return worker.UpdateSchedule() == "30 4 * * *"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The new schedule replaces the old cron entry
+	from := time.Date(2025, 6, 2, 12, 0, 0, 0, time.Local) // A Monday
+	assert.Equal(t, time.Date(2025, 6, 3, 4, 30, 0, 0, time.Local), entrySchedule(worker).Next(from))
+	assert.Len(t, worker.cron.Entries(), 1)
+	assert.Zero(t, worker.cron.Entry(initial).ID)
+}
+
+func TestScheduleUpdatesKeepsScheduleOnError(t *testing.T) {
+	worker := &UpdateWorker{
+		config: &config.Config{UpdateSchedule: "0 2 * * 0"},
+		cron:   cron.New(),
+	}
+	ctx := context.Background()
+	require.NoError(t, worker.scheduleUpdates(ctx, "0 2 * * 0"))
+
+	assert.Error(t, worker.scheduleUpdates(ctx, "not a schedule"))
+	assert.Equal(t, "0 2 * * 0", worker.UpdateSchedule())
+	assert.Len(t, worker.cron.Entries(), 1)
+}
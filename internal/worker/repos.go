@@ -0,0 +1,193 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// ErrSDKActive is returned when asked to clean the repository of an SDK
+// that is still active.
+var ErrSDKActive = errors.New("SDK is active")
+
+// RepoCleanedEvent reports a cloned repository removed because its SDK is
+// no longer active.
+type RepoCleanedEvent struct {
+	Repo      string
+	CleanedAt time.Time
+}
+
+// repoCleanedHolder wraps the handler so it can be swapped atomically.
+type repoCleanedHolder struct {
+	fn func(RepoCleanedEvent)
+}
+
+// OnRepoCleaned sets the function called for each repository removed by
+// cleanup or pruning, replacing any set before. It may be called at any
+// time, including while the worker runs.
+func (w *UpdateWorker) OnRepoCleaned(fn func(RepoCleanedEvent)) {
+	w.repoCleaned.Store(&repoCleanedHolder{fn: fn})
+}
+
+// reposCleaned counts the removed repositories in the worker metrics and
+// reports each to the OnRepoCleaned handler.
+func (w *UpdateWorker) reposCleaned(names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	w.metricsMu.Lock()
+	w.metrics.RepoCleaned += int64(len(names))
+	w.metricsMu.Unlock()
+
+	holder := w.repoCleaned.Load()
+	if holder == nil || holder.fn == nil {
+		return
+	}
+	now := time.Now()
+	for _, name := range names {
+		holder.fn(RepoCleanedEvent{Repo: name, CleanedAt: now})
+	}
+}
+
+// PruneInactiveRepos removes cloned repositories of SDKs that are no longer
+// active and returns the removed paths.
+func (w *UpdateWorker) PruneInactiveRepos(ctx context.Context) ([]string, error) {
+	activeURLs, err := activeRepoURLs()
+	if err != nil {
+		return nil, err
+	}
+	removed, err := w.git.PruneInactiveRepos(ctx, activeURLs)
+
+	names := make([]string, 0, len(removed))
+	for _, repoPath := range removed {
+		names = append(names, filepath.Base(repoPath))
+	}
+	w.reposCleaned(names)
+	return removed, err
+}
+
+// CleanRepo removes the cloned repository of the named SDK, which must be
+// inactive. It reports false when the SDK had no clone to remove.
+func (w *UpdateWorker) CleanRepo(name string) (bool, error) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		return false, fmt.Errorf("failed to load SDK configs: %w", err)
+	}
+	cfg, ok := configs.FindSDK(name)
+	if !ok {
+		return false, &apperrors.SDKNotFoundError{Name: name}
+	}
+	if cfg.Active {
+		return false, fmt.Errorf("%w: %s", ErrSDKActive, name)
+	}
+
+	removed, err := w.git.RemoveRepo(cfg.URL)
+	if err != nil {
+		return false, err
+	}
+	if removed {
+		w.reposCleaned([]string{filepath.Base(w.git.GetRepoPath(cfg.URL))})
+	}
+	return removed, nil
+}
+
+// SDKCommits returns up to limit commits made to the named SDK's cloned
+// repository since the given time, newest first. It returns
+// git.ErrRepoNotCloned when the repository has not been cloned.
+func (w *UpdateWorker) SDKCommits(ctx context.Context, name string, since time.Time, limit int) ([]git.Commit, error) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SDK configs: %w", err)
+	}
+	cfg, ok := configs.FindSDK(name)
+	if !ok {
+		return nil, &apperrors.SDKNotFoundError{Name: name}
+	}
+
+	return w.git.GetRecentCommits(ctx, w.git.GetRepoPath(cfg.URL), since, limit)
+}
+
+// RepoDiskUsage returns the size in bytes of each cloned repository, keyed
+// by repository name.
+func (w *UpdateWorker) RepoDiskUsage() (map[string]int64, error) {
+	return w.git.GetDiskUsage()
+}
+
+// CheckGitWorkDir verifies that the directory repositories are cloned into
+// exists and is writable; see git.Client.CheckWorkDir.
+func (w *UpdateWorker) CheckGitWorkDir() error {
+	return w.git.CheckWorkDir()
+}
+
+// activeRepoURLs returns the repository URLs of the active SDKs.
+func activeRepoURLs() ([]string, error) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SDK configs: %w", err)
+	}
+
+	var activeURLs []string
+	for _, cfg := range configs.GetActiveSDKs() {
+		activeURLs = append(activeURLs, cfg.URL)
+	}
+	return activeURLs, nil
+}
+
+// cleanStaleRepos removes repositories of SDKs disabled since the last run.
+func (w *UpdateWorker) cleanStaleRepos(ctx context.Context) {
+	activeURLs, err := activeRepoURLs()
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to clean stale repositories")
+		return
+	}
+
+	removed, err := w.git.CleanStaleRepos(ctx, activeURLs)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to clean stale repositories")
+	}
+	if len(removed) > 0 {
+		w.logger.Info().Strs("repos", removed).Msg("Cleaned stale repositories")
+	}
+	w.reposCleaned(removed)
+}
+
+// RequestRepoCleanup asks the worker to remove repositories of SDKs that
+// are no longer active. It does not wait for the cleanup, and requests made
+// while one is pending are merged into it.
+func (w *UpdateWorker) RequestRepoCleanup() {
+	select {
+	case w.repoCleanup <- struct{}{}:
+	default:
+	}
+}
+
+// processRepoCleanups runs requested repository cleanups until ctx is
+// cancelled.
+func (w *UpdateWorker) processRepoCleanups(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.repoCleanup:
+			w.cleanStaleRepos(ctx)
+		}
+	}
+}
+
+// runScheduledRepoPrune removes inactive repositories after an update cycle.
+func (w *UpdateWorker) runScheduledRepoPrune(ctx context.Context) {
+	removed, err := w.PruneInactiveRepos(ctx)
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to prune inactive repositories")
+	}
+	if len(removed) > 0 {
+		w.logger.Info().Int("removed", len(removed)).Msg("Pruned inactive repositories")
+	}
+}
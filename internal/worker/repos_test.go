@@ -0,0 +1,137 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/git"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func TestPruneInactiveRepos(t *testing.T) {
+	workDir := t.TempDir()
+
+	// sentry-go and sentry-python are active; the rest are not configured
+	// or inactive
+	for _, repo := range []string{"sentry-go", "sentry-python", "sentry-electron", "old-sdk", "fork"} {
+		require.NoError(t, os.Mkdir(filepath.Join(workDir, repo), 0o755))
+	}
+
+	w := newBackoffTestWorker(t)
+	w.git = git.NewClient(workDir, zerolog.Nop())
+
+	removed, err := w.PruneInactiveRepos(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(workDir, "sentry-electron"),
+		filepath.Join(workDir, "old-sdk"),
+		filepath.Join(workDir, "fork"),
+	}, removed)
+	assert.DirExists(t, filepath.Join(workDir, "sentry-go"))
+	assert.DirExists(t, filepath.Join(workDir, "sentry-python"))
+}
+
+func TestRunScheduledUpdatePrunesRepos(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		tempDir := t.TempDir()
+		logger := zerolog.Nop()
+
+		cacheManager, err := cache.NewManager(tempDir, logger)
+		require.NoError(t, err)
+
+		w := NewUpdateWorker(cacheManager, logger, &config.Config{
+			UpdateSchedule:         "0 2 * * 0",
+			CacheTTL:               time.Hour,
+			CacheDir:               tempDir,
+			AutoPruneInactiveRepos: enabled,
+		})
+		w.sdkAnalyzer = nil
+
+		inactive := filepath.Join(tempDir, "repos", "sentry-electron")
+		require.NoError(t, os.MkdirAll(inactive, 0o755))
+
+		w.runScheduledUpdate(context.Background())
+
+		if enabled {
+			assert.NoDirExists(t, inactive)
+		} else {
+			assert.DirExists(t, inactive)
+		}
+		require.NoError(t, cacheManager.Close())
+	}
+}
+
+func TestRepoCleanupRemovesDisabledSDK(t *testing.T) {
+	workDir := t.TempDir()
+	repoPath := filepath.Join(workDir, "sentry-go")
+	require.NoError(t, os.Mkdir(repoPath, 0o755))
+	require.NoError(t, os.Mkdir(filepath.Join(repoPath, "dummy"), 0o755))
+
+	w := newBackoffTestWorker(t)
+	w.git = git.NewClient(workDir, zerolog.Nop())
+	w.repoCleanup = make(chan struct{}, 1)
+
+	events := make(chan RepoCleanedEvent, 1)
+	w.OnRepoCleaned(func(event RepoCleanedEvent) { events <- event })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go w.processRepoCleanups(ctx)
+
+	// Nothing to clean while sentry-go is active
+	w.cleanStaleRepos(ctx)
+	assert.DirExists(t, repoPath)
+
+	_, err := sdk.SetActive("sentry-go", false)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := sdk.SetActive("sentry-go", true)
+		require.NoError(t, err)
+	})
+
+	w.RequestRepoCleanup()
+	select {
+	case event := <-events:
+		assert.Equal(t, "sentry-go", event.Repo)
+	case <-time.After(5 * time.Second):
+		t.Fatal("repository of the disabled SDK was not cleaned")
+	}
+	assert.NoDirExists(t, repoPath)
+	assert.Equal(t, int64(1), w.Metrics().RepoCleaned)
+}
+
+func TestCleanRepo(t *testing.T) {
+	workDir := t.TempDir()
+	for _, repo := range []string{"sentry-go", "sentry-electron"} {
+		require.NoError(t, os.Mkdir(filepath.Join(workDir, repo), 0o755))
+	}
+
+	w := newBackoffTestWorker(t)
+	w.git = git.NewClient(workDir, zerolog.Nop())
+
+	_, err := w.CleanRepo("sentry-cobol")
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+
+	_, err = w.CleanRepo("sentry-go")
+	assert.ErrorIs(t, err, ErrSDKActive)
+	assert.DirExists(t, filepath.Join(workDir, "sentry-go"))
+
+	removed, err := w.CleanRepo("sentry-electron")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoDirExists(t, filepath.Join(workDir, "sentry-electron"))
+
+	removed, err = w.CleanRepo("sentry-electron")
+	require.NoError(t, err)
+	assert.False(t, removed)
+	assert.Equal(t, int64(1), w.Metrics().RepoCleaned)
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+)
+
+// analyticsPruneSchedule runs the analytics prune daily at midnight.
+const analyticsPruneSchedule = "0 0 * * *"
+
+// ErrAnalyticsDisabled is returned when no analytics store is configured.
+var ErrAnalyticsDisabled = errors.New("analytics is disabled")
+
+// SetAnalyticsStore sets the store that token usage and failed analyses
+// are recorded in and that is pruned daily. It must be called before Start.
+func (w *UpdateWorker) SetAnalyticsStore(store *analytics.Store) {
+	w.analytics = store
+	w.deadLetters = store.DeadLetters(deadLetterWindow)
+}
+
+// AnalyticsStore returns the configured analytics store, or nil.
+func (w *UpdateWorker) AnalyticsStore() *analytics.Store {
+	return w.analytics
+}
+
+// PruneAnalytics deletes analytics events older than the configured
+// retention policy.
+func (w *UpdateWorker) PruneAnalytics(ctx context.Context) (analytics.PruneResult, error) {
+	if w.analytics == nil {
+		return analytics.PruneResult{}, ErrAnalyticsDisabled
+	}
+	return w.analytics.Prune(ctx, w.config.Retention)
+}
+
+func (w *UpdateWorker) runScheduledPrune(ctx context.Context) {
+	if _, err := w.PruneAnalytics(ctx); err != nil {
+		w.logger.Error().Err(err).Msg("Failed to prune analytics")
+	}
+}
+
+func (w *UpdateWorker) recordTokenUsage(sdkName string, tokens int) {
+	if w.analytics == nil {
+		return
+	}
+	if err := w.analytics.RecordTokenUsage(analytics.TokenEvent{SDK: sdkName, Tokens: tokens}); err != nil {
+		w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to record token usage")
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+func TestPruneAnalyticsDisabled(t *testing.T) {
+	w := newBackoffTestWorker(t)
+
+	_, err := w.PruneAnalytics(context.Background())
+	assert.ErrorIs(t, err, ErrAnalyticsDisabled)
+}
+
+func TestUpdateCacheRecordsTokenUsage(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, cacheManager.Close())
+	}()
+
+	store, err := analytics.Open(filepath.Join(tempDir, "analytics.db"), logger)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, store.Close())
+	}()
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+		Retention:      config.RetentionPolicy{TokenEventDays: 30},
+	}
+
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+	worker.sdkAnalyzer = nil
+	worker.SetAnalyticsStore(store)
+
+	require.NoError(t, worker.updateCache(context.Background(), true))
+
+	tokens, _, _, err := store.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 3, tokens, "one token event per analyzed SDK")
+
+	// Recent events survive the configured retention
+	result, err := worker.PruneAnalytics(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.TokenEventsDeleted)
+	assert.NotNil(t, store.LastPrune())
+}
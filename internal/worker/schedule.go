@@ -0,0 +1,172 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// scheduleParser parses the cron specs of the worker's jobs: the standard
+// five fields with an optional leading seconds field, or a descriptor such
+// as @daily.
+var scheduleParser = cron.NewParser(
+	cron.SecondOptional | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+)
+
+// sdkSchedule is the cron entry analyzing one SDK on its own schedule.
+type sdkSchedule struct {
+	spec  string
+	entry cron.EntryID
+}
+
+// RequestSDKReschedule asks the worker to bring the cron entries of SDKs
+// with their own schedule in line with the SDK configuration, for example
+// after an SDK was toggled. It does not wait for the entries to change.
+func (w *UpdateWorker) RequestSDKReschedule() {
+	select {
+	case w.sdkReschedule <- struct{}{}:
+	default:
+	}
+}
+
+// processSDKReschedules applies requested SDK reschedules until ctx is
+// cancelled.
+func (w *UpdateWorker) processSDKReschedules(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.sdkReschedule:
+			w.scheduleSDKs(ctx)
+		}
+	}
+}
+
+// scheduleSDKs registers a cron entry for every active SDK with a Schedule
+// and removes the entries of SDKs whose schedule changed, was cleared or
+// that were disabled. SDKs whose schedule is invalid are logged and left
+// to scheduled updates.
+func (w *UpdateWorker) scheduleSDKs(ctx context.Context) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		w.logger.Error().Err(err).Msg("Failed to load SDK configs for scheduling")
+		return
+	}
+
+	wanted := make(map[string]string)
+	for _, cfg := range configs.GetActiveSDKs() {
+		if cfg.Schedule != "" {
+			wanted[cfg.Name] = cfg.Schedule
+		}
+	}
+
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+
+	for name, schedule := range w.sdkSchedules {
+		if wanted[name] == schedule.spec {
+			continue
+		}
+		w.cron.Remove(schedule.entry)
+		delete(w.sdkSchedules, name)
+		w.logger.Info().
+			Str("sdk", name).
+			Str("schedule", schedule.spec).
+			Msg("Removed SDK schedule")
+	}
+
+	for name, spec := range wanted {
+		if _, ok := w.sdkSchedules[name]; ok {
+			continue
+		}
+		entry, err := w.cron.AddFunc(spec, func() {
+			w.runScheduledSDKUpdate(ctx, name)
+		})
+		if err != nil {
+			w.logger.Error().
+				Err(err).
+				Str("sdk", name).
+				Str("schedule", spec).
+				Msg("Invalid SDK schedule, analyzing with scheduled updates instead")
+			continue
+		}
+		w.sdkSchedules[name] = sdkSchedule{spec: spec, entry: entry}
+		w.logger.Info().
+			Str("sdk", name).
+			Str("schedule", spec).
+			Msg("Scheduled SDK analysis")
+	}
+}
+
+// globallyScheduled returns the SDKs of sdks that scheduled updates
+// analyze, leaving out those analyzed on their own schedule.
+func (w *UpdateWorker) globallyScheduled(sdks []sdk.Config) []sdk.Config {
+	w.scheduleMu.Lock()
+	defer w.scheduleMu.Unlock()
+
+	scheduled := make([]sdk.Config, 0, len(sdks))
+	for _, cfg := range sdks {
+		if _, ok := w.sdkSchedules[cfg.Name]; !ok {
+			scheduled = append(scheduled, cfg)
+		}
+	}
+	return scheduled
+}
+
+// runScheduledSDKUpdate analyzes an SDK on its own schedule if it changed
+// since its last analysis. Runs are skipped while the previous one for the
+// SDK is still going, and while the worker drains or backs off.
+func (w *UpdateWorker) runScheduledSDKUpdate(ctx context.Context, name string) {
+	if !w.beginRun() {
+		w.logger.Debug().Str("sdk", name).Msg("Skipping scheduled SDK analysis while draining")
+		return
+	}
+	defer w.endRun()
+
+	if w.inBackoff(time.Now()) {
+		w.logger.Debug().Str("sdk", name).Msg("Skipping scheduled SDK analysis while backing off")
+		return
+	}
+
+	w.sdkRunsMu.Lock()
+	if w.sdkRuns[name] {
+		w.sdkRunsMu.Unlock()
+		w.logger.Debug().Str("sdk", name).Msg("Scheduled SDK analysis still running, skipping")
+		return
+	}
+	w.sdkRuns[name] = true
+	w.sdkRunsMu.Unlock()
+	defer func() {
+		w.sdkRunsMu.Lock()
+		delete(w.sdkRuns, name)
+		w.sdkRunsMu.Unlock()
+	}()
+
+	targets, err := resolveSDKs([]string{name})
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", name).Msg("Failed to resolve scheduled SDK")
+		return
+	}
+	target := targets[0]
+
+	if !w.needsUpdate(ctx, target) {
+		w.logger.Debug().Str("sdk", name).Msg("SDK analysis is up to date, skipping")
+		return
+	}
+
+	workCtx := w.analysisContext(ctx)
+	release, err := w.pool.Acquire(workCtx, target.Priority)
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", name).Msg("Failed to acquire worker slot")
+		return
+	}
+	defer release()
+
+	w.logger.Info().Str("sdk", name).Msg("Running scheduled SDK analysis")
+	if _, err := w.warmSDK(workCtx, target); err != nil {
+		w.logger.Error().Err(err).Str("sdk", name).Msg("Scheduled SDK analysis failed")
+	}
+}
@@ -0,0 +1,92 @@
+package worker
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// setSDKSchedule gives an SDK a schedule for the rest of the test.
+func setSDKSchedule(t *testing.T, name, schedule string) {
+	t.Helper()
+
+	previous, err := sdk.SetSchedule(name, schedule)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := sdk.SetSchedule(name, previous)
+		require.NoError(t, err)
+	})
+}
+
+func TestScheduledSDKAnalysis(t *testing.T) {
+	setSDKSchedule(t, "sentry-go", "* * * * * *")
+
+	worker, _, gated := newWarmTestWorker(t, 1)
+	close(gated.release)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	worker.scheduleSDKs(ctx)
+	worker.cron.Start()
+	defer worker.cron.Stop()
+
+	require.Eventually(t, func() bool {
+		return slices.Contains(gated.Calls(), "sentry-go")
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// Only sentry-go has its own schedule, and the analysis is fresh now
+	assert.Equal(t, []string{"sentry-go"}, gated.Calls())
+	assert.True(t, worker.isFresh("sentry-go"))
+}
+
+func TestScheduleSDKsTracksConfigChanges(t *testing.T) {
+	setSDKSchedule(t, "sentry-go", "0 3 * * 1")
+	setSDKSchedule(t, "sentry-python", "not a schedule")
+
+	worker, _, _ := newWarmTestWorker(t, 1)
+	ctx := context.Background()
+
+	worker.scheduleSDKs(ctx)
+	require.Contains(t, worker.sdkSchedules, "sentry-go")
+	assert.NotContains(t, worker.sdkSchedules, "sentry-python", "invalid schedules are not registered")
+	first := worker.sdkSchedules["sentry-go"].entry
+	assert.True(t, worker.cron.Entry(first).Valid())
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	var names []string
+	for _, cfg := range worker.globallyScheduled(configs.GetActiveSDKs()) {
+		names = append(names, cfg.Name)
+	}
+	assert.NotContains(t, names, "sentry-go")
+	assert.Contains(t, names, "sentry-python")
+
+	// Unchanged schedules keep their entry
+	worker.scheduleSDKs(ctx)
+	assert.Equal(t, first, worker.sdkSchedules["sentry-go"].entry)
+
+	// A new schedule replaces the old entry
+	setSDKSchedule(t, "sentry-go", "0 4 * * *")
+	worker.scheduleSDKs(ctx)
+	second := worker.sdkSchedules["sentry-go"].entry
+	assert.NotEqual(t, first, second)
+	assert.False(t, worker.cron.Entry(first).Valid())
+	assert.Equal(t, "0 4 * * *", worker.sdkSchedules["sentry-go"].spec)
+
+	// Disabling the SDK removes its entry
+	_, err = sdk.SetActive("sentry-go", false)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := sdk.SetActive("sentry-go", true)
+		require.NoError(t, err)
+	})
+	worker.scheduleSDKs(ctx)
+	assert.NotContains(t, worker.sdkSchedules, "sentry-go")
+	assert.False(t, worker.cron.Entry(second).Valid())
+}
@@ -0,0 +1,69 @@
+package worker
+
+import "time"
+
+// WorkerStatus describes what the update worker is doing: whether a cache
+// update is running, how the last one went and when the next is due.
+type WorkerStatus struct {
+	Running             bool          `json:"running"`
+	LastRunAt           time.Time     `json:"last_run_at"`
+	LastRunDuration     time.Duration `json:"last_run_duration"`
+	NextRunAt           time.Time     `json:"next_run_at"`
+	LastRunSuccessCount int           `json:"last_run_success_count"`
+	LastRunErrorCount   int           `json:"last_run_error_count"`
+}
+
+// Status returns the current worker status. NextRunAt is the next
+// scheduled update, or zero when the schedule is invalid.
+func (w *UpdateWorker) Status() WorkerStatus {
+	w.statusMu.RLock()
+	status := w.status
+	w.statusMu.RUnlock()
+
+	status.NextRunAt = w.nextUpdateAt(time.Now())
+	return status
+}
+
+// nextUpdateAt returns when the next scheduled update runs after now. The
+// cron entry knows once the scheduler is running; before that the time is
+// worked out from the schedule.
+func (w *UpdateWorker) nextUpdateAt(now time.Time) time.Time {
+	w.scheduleMu.Lock()
+	entry := w.updateEntry
+	w.scheduleMu.Unlock()
+
+	if entry != 0 {
+		if next := w.cron.Entry(entry).Next; !next.IsZero() {
+			return next
+		}
+	}
+
+	schedule, err := scheduleParser.Parse(w.UpdateSchedule())
+	if err != nil {
+		return time.Time{}
+	}
+	return schedule.Next(now)
+}
+
+// beginStatusRun marks a cache update as running.
+func (w *UpdateWorker) beginStatusRun() {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+	w.status.Running = true
+}
+
+// endStatusRun marks the cache update as finished. Runs interrupted by
+// shutdown leave the last run as it was.
+func (w *UpdateWorker) endStatusRun(run RunSummary, interrupted bool) {
+	w.statusMu.Lock()
+	defer w.statusMu.Unlock()
+
+	w.status.Running = false
+	if interrupted {
+		return
+	}
+	w.status.LastRunAt = run.StartedAt
+	w.status.LastRunDuration = run.Duration
+	w.status.LastRunSuccessCount = run.Succeeded
+	w.status.LastRunErrorCount = run.Failed
+}
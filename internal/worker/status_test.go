@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerStatus(t *testing.T) {
+	worker, _, gated := newWarmTestWorker(t, 1)
+
+	status := worker.Status()
+	assert.False(t, status.Running)
+	assert.True(t, status.LastRunAt.IsZero())
+	// 0 2 * * 0: Sundays at 02:00
+	assert.Equal(t, time.Sunday, status.NextRunAt.Weekday())
+	assert.True(t, status.NextRunAt.After(time.Now()))
+
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.updateCache(context.Background(), true)
+	}()
+
+	// The gated analyzer holds the run until released
+	require.Eventually(t, func() bool {
+		return worker.Status().Running
+	}, 2*time.Second, 5*time.Millisecond)
+
+	close(gated.release)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("cache update did not finish")
+	}
+
+	status = worker.Status()
+	assert.False(t, status.Running)
+	assert.Greater(t, status.LastRunSuccessCount, 0)
+	assert.Zero(t, status.LastRunErrorCount)
+	assert.WithinDuration(t, time.Now(), status.LastRunAt, 5*time.Second)
+	assert.Positive(t, status.LastRunDuration)
+}
@@ -3,20 +3,33 @@ package worker
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/robfig/cron/v3"
 	"github.com/rs/zerolog"
 
+	"github.com/ryanrussell/claude-cache-service/internal/analytics"
 	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
 	"github.com/ryanrussell/claude-cache-service/internal/config"
 	"github.com/ryanrussell/claude-cache-service/internal/git"
+	"github.com/ryanrussell/claude-cache-service/internal/notify"
 	"github.com/ryanrussell/claude-cache-service/internal/sdk"
 )
 
+// protocolChecker records Sentry protocol violations on stored analyses.
+var protocolChecker = analyzer.NewProtocolChecker()
+
+// maxVersionRetries bounds how often storeLatest retries a write after a
+// version conflict.
+const maxVersionRetries = 3
+
 // UpdateWorker handles scheduled cache updates.
 type UpdateWorker struct {
 	cache       *cache.Manager
@@ -24,6 +37,72 @@ type UpdateWorker struct {
 	config      *config.Config
 	cron        *cron.Cron
 	sdkAnalyzer *sdk.Analyzer
+	git         *git.Client
+
+	// The cron entry of scheduled updates and its spec, which starts as
+	// config.UpdateSchedule and changes when the configuration is reloaded
+	scheduleMu  sync.Mutex
+	schedule    string
+	updateEntry cron.EntryID
+
+	// Cron entries of SDKs analyzed on their own schedule, by SDK name,
+	// guarded by scheduleMu. sdkReschedule signals processSDKReschedules to
+	// update them; sdkRuns holds the SDKs whose scheduled analysis is running.
+	sdkSchedules  map[string]sdkSchedule
+	sdkReschedule chan struct{}
+	sdkRunsMu     sync.Mutex
+	sdkRuns       map[string]bool
+
+	// fallbackAnalyzer produces analyses when the SDK analyzer is unavailable
+	fallbackAnalyzer analyzer.Analyzer
+
+	// codeAnalyzer analyzes ad-hoc code submitted through the API
+	codeAnalyzer analyzer.Analyzer
+
+	// analytics records token usage and is pruned daily (optional)
+	analytics *analytics.Store
+
+	// notifier announces cached SDK analyses and disabled SDKs (optional)
+	notifier notify.Notifier
+
+	// deadLetters holds the SDKs whose analyses failed, in memory unless an
+	// analytics store is set
+	deadLetters *analytics.DeadLetterQueue
+
+	// Shutdown drain state
+	started    atomic.Bool
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+	drain      drainState
+
+	// Background jobs such as cache warming
+	jobs *JobRegistry
+
+	// refreshQueue holds requested refreshes; updateMu keeps them from
+	// overlapping scheduled updates
+	refreshQueue *JobQueue
+	updateMu     sync.Mutex
+
+	// pool limits concurrent SDK analyses by priority
+	pool *PriorityWorkerPool
+
+	// Moving averages over completed update runs
+	metricsMu sync.RWMutex
+	metrics   WorkerMetrics
+
+	// What the worker is doing and how its last update went
+	statusMu sync.RWMutex
+	status   WorkerStatus
+
+	// Backoff state after repeated cycle failures
+	backoffMu           sync.RWMutex
+	consecutiveFailures int
+	backoffUntil        time.Time
+
+	// repoCleanup signals processRepoCleanups to remove repositories of
+	// disabled SDKs; repoCleaned is told about each removal
+	repoCleanup chan struct{}
+	repoCleaned atomic.Pointer[repoCleanedHolder]
 }
 
 // NewUpdateWorker creates a new update worker.
@@ -31,61 +110,130 @@ func NewUpdateWorker(cache *cache.Manager, logger zerolog.Logger, config *config
 	// Create git client
 	gitWorkDir := filepath.Join(config.CacheDir, "repos")
 	gitClient := git.NewClient(gitWorkDir, logger)
-
-	// Create analyzer based on configuration
-	var claudeAnalyzer analyzer.Analyzer
-	if config.ClaudeAPIKey != "" {
-		claudeAnalyzer = analyzer.NewClaudeAnalyzer(config.ClaudeAPIKey, config.ClaudeModel, logger)
-		logger.Info().Msg("Claude analyzer initialized")
-	} else {
+	gitClient.SetFeatureFlags(config)
+	gitClient.SetMinFreeDiskBytes(config.MinFreeDiskBytes)
+	gitClient.Depth = config.GitCloneDepth
+	gitClient.MaxRetries = config.MaxGitRetries
+	gitClient.SSHKeyPath = config.GitSSHKeyPath
+	gitClient.SSHKeyPassphrase = config.GitSSHKeyPassphrase
+	gitClient.GitHubToken = config.GitHubToken
+	gitClient.SetProgressReporter(git.NewLogProgressReporter(logger, config.ProgressInterval))
+
+	// Create analyzer from the configured provider
+	provider := config.AnalyzerProvider
+	if provider == "" {
+		provider = "claude"
+	}
+	if provider == "claude" && config.ClaudeAPIKey == "" {
 		logger.Warn().Msg("Claude API key not configured, using mock analyzer")
-		claudeAnalyzer = &mockAnalyzer{logger: logger}
+		provider = "mock"
+	}
+	providerAnalyzer, err := analyzer.Registry.Create(provider, config, logger)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to create analyzer, using mock analyzer")
+		providerAnalyzer = analyzer.NewMockAnalyzer(logger)
+	} else {
+		logger.Info().Str("provider", provider).Msg("Analyzer initialized")
+	}
+	if claudeAnalyzer, ok := providerAnalyzer.(*analyzer.ClaudeAnalyzer); ok {
+		claudeAnalyzer.SetCache(cache)
+	}
+
+	w := &UpdateWorker{
+		cache:            cache,
+		logger:           logger,
+		config:           config,
+		cron:             cron.New(cron.WithParser(scheduleParser), cron.WithLogger(cron.VerbosePrintfLogger(&cronLogger{logger: logger}))),
+		git:              gitClient,
+		fallbackAnalyzer: analyzer.NewMockAnalyzer(logger),
+		codeAnalyzer:     providerAnalyzer,
+		drain:            drainState{done: make(chan struct{})},
+		jobs:             NewJobRegistry(),
+		refreshQueue:     NewJobQueue(refreshQueueSize),
+		pool:             NewPriorityWorkerPool(config.MaxConcurrent),
+		repoCleanup:      make(chan struct{}, 1),
+		sdkSchedules:     make(map[string]sdkSchedule),
+		sdkReschedule:    make(chan struct{}, 1),
+		sdkRuns:          make(map[string]bool),
+		notifier:         notify.New(config, logger),
+		deadLetters:      analytics.NewDeadLetterQueue(deadLetterWindow),
 	}
 
 	// Create SDK analyzer
-	sdkAnalyzer, err := sdk.NewAnalyzer(gitClient, claudeAnalyzer, cache, logger)
+	sdkAnalyzer, err := sdk.NewAnalyzer(gitClient, providerAnalyzer, cache, logger)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to create SDK analyzer")
 		// Return worker without SDK analyzer, will use fallback
-		return &UpdateWorker{
-			cache:       cache,
-			logger:      logger,
-			config:      config,
-			cron:        cron.New(cron.WithLogger(cron.VerbosePrintfLogger(&cronLogger{logger: logger}))),
-			sdkAnalyzer: nil,
-		}
+		return w
 	}
+	sdkAnalyzer.SetMultiPass(config.MultiPassThreshold, config.MaxFilesPerPass)
+	sdkAnalyzer.SetConcurrency(config.MaxConcurrent)
+	sdkAnalyzer.SetSlotPool(w.pool)
+	sdkAnalyzer.SetIncrementalThreshold(config.IncrementalThreshold)
+
+	w.sdkAnalyzer = sdkAnalyzer
+	return w
+}
 
-	return &UpdateWorker{
-		cache:       cache,
-		logger:      logger,
-		config:      config,
-		cron:        cron.New(cron.WithLogger(cron.VerbosePrintfLogger(&cronLogger{logger: logger}))),
-		sdkAnalyzer: sdkAnalyzer,
+// ClaudeCircuitBreaker returns the circuit breaker guarding Claude API
+// calls, or nil when analyses do not use Claude.
+func (w *UpdateWorker) ClaudeCircuitBreaker() *claude.CircuitBreaker {
+	claudeAnalyzer, ok := w.codeAnalyzer.(*analyzer.ClaudeAnalyzer)
+	if !ok {
+		return nil
 	}
+	return claudeAnalyzer.CircuitBreaker()
 }
 
-// Start starts the update worker.
+// Start starts the update worker. When ctx is cancelled the worker stops
+// scheduling new runs and drains in-flight analyses for up to DrainTimeout.
 func (w *UpdateWorker) Start(ctx context.Context) {
 	w.logger.Info().Str("schedule", w.config.UpdateSchedule).Msg("Starting update worker")
 
+	w.workCtx, w.cancelWork = context.WithCancel(context.WithoutCancel(ctx))
+	defer w.cancelWork()
+	w.started.Store(true)
+
 	// Add scheduled job
-	_, err := w.cron.AddFunc(w.config.UpdateSchedule, func() {
-		if err := w.updateCache(ctx); err != nil {
-			w.logger.Error().Err(err).Msg("Failed to update cache")
-		}
-	})
-	if err != nil {
+	if err := w.scheduleUpdates(ctx, w.config.UpdateSchedule); err != nil {
 		w.logger.Error().Err(err).Msg("Failed to add cron job")
+		close(w.drain.done)
 		return
 	}
 
-	// Run initial update
+	// Analyze SDKs with their own schedule on it, and keep the entries in
+	// line with the SDK configuration
+	w.scheduleSDKs(ctx)
+	go w.processSDKReschedules(ctx)
+
+	// Prune analytics daily at midnight
+	if w.analytics != nil {
+		if _, err := w.cron.AddFunc(analyticsPruneSchedule, func() {
+			w.runScheduledPrune(ctx)
+		}); err != nil {
+			w.logger.Error().Err(err).Msg("Failed to add analytics prune job")
+		}
+	}
+
+	// Compact the cache database on its own schedule
+	if w.config.AutoCompact {
+		if _, err := w.cron.AddFunc(w.config.CompactSchedule, w.runScheduledCompaction); err != nil {
+			w.logger.Error().Err(err).Msg("Failed to add cache compaction job")
+		}
+	}
+
+	// Run requested refreshes between scheduled updates
+	go w.processRefreshJobs(ctx)
+
+	// Remove repositories of SDKs disabled while running
+	go w.processRepoCleanups(ctx)
+
+	// Remove repositories of disabled SDKs, then run the initial update
 	go func() {
+		w.cleanStaleRepos(ctx)
+
 		w.logger.Info().Msg("Running initial cache update")
-		if err := w.updateCache(ctx); err != nil {
-			w.logger.Error().Err(err).Msg("Failed to run initial cache update")
-		}
+		w.runScheduledUpdate(ctx)
 	}()
 
 	// Start cron scheduler
@@ -95,161 +243,379 @@ func (w *UpdateWorker) Start(ctx context.Context) {
 	<-ctx.Done()
 	w.logger.Info().Msg("Stopping update worker")
 
-	// Stop cron scheduler
+	// Stop cron scheduler and drain in-flight runs
 	cronCtx := w.cron.Stop()
-	<-cronCtx.Done()
+	w.drainInflight(cronCtx.Done())
 }
 
-// updateCache performs the cache update.
-func (w *UpdateWorker) updateCache(ctx context.Context) error {
-	start := time.Now()
+// updateCache performs the cache update and records it in the worker
+// metrics and status, analyzing the SDKs named in force first. Scheduled
+// updates leave out SDKs analyzed on their own schedule. Runs interrupted
+// by shutdown are not recorded.
+func (w *UpdateWorker) updateCache(ctx context.Context, scheduled bool, force ...string) error {
+	run := RunSummary{StartedAt: time.Now()}
+	w.beginStatusRun()
+	err := w.refreshCache(ctx, &run, scheduled, force)
+
+	run.Duration = time.Since(run.StartedAt)
+	if err != nil {
+		run.Error = err.Error()
+	}
+	interrupted := ctx.Err() != nil
+	if !interrupted {
+		w.recordRun(run)
+	}
+	w.endStatusRun(run, interrupted)
+	return err
+}
+
+// refreshCache analyzes every SDK, those named in force first, and caches
+// the results, counting them in run. Scheduled runs skip SDKs analyzed on
+// their own schedule.
+func (w *UpdateWorker) refreshCache(ctx context.Context, run *RunSummary, scheduled bool, force []string) error {
+	start := run.StartedAt
 	w.logger.Info().Msg("Starting cache update")
 
 	// Check if SDK analyzer is available
 	if w.sdkAnalyzer == nil {
 		w.logger.Warn().Msg("SDK analyzer not available, using fallback")
-		return w.updateCacheFallback(ctx)
+		return w.updateCacheFallback(ctx, run)
+	}
+
+	// Analyze the active SDKs, skipping those not yet started once ctx is cancelled
+	targets := w.sdkAnalyzer.ActiveSDKs()
+	if scheduled {
+		targets = w.globallyScheduled(targets)
+	}
+	results := w.sdkAnalyzer.AnalyzeSDKs(w.analysisContext(ctx), ctx.Done(), targets, force...)
+	completed, abandoned := w.cacheResults(ctx, run, results)
+
+	if ctx.Err() != nil {
+		w.recordDrainOutcome(completed, abandoned)
+	}
+
+	// Cache project summaries (these would be aggregated from actual usage data)
+	projects := []string{
+		"gremlin-arrow-flight",
+		"claude-code-gui",
 	}
 
-	// Analyze all active SDKs
-	results := w.sdkAnalyzer.AnalyzeAllSDKs(ctx)
+	for _, project := range projects {
+		summary := map[string]interface{}{
+			"project":       project,
+			"cache_hits":    1000,
+			"token_savings": 45000,
+			"last_updated":  time.Now().Format(time.RFC3339),
+		}
 
+		summaryJSON, err := json.Marshal(summary)
+		if err != nil {
+			w.logger.Error().Err(err).Str("project", project).Msg("Failed to marshal project summary")
+			continue
+		}
+
+		key := fmt.Sprintf("project:%s", project)
+		if err := w.cache.Set(key, string(summaryJSON), w.config.CacheTTL); err != nil {
+			w.logger.Error().Err(err).Str("project", project).Msg("Failed to cache project summary")
+		}
+	}
+
+	duration := time.Since(start)
+	w.logger.Info().
+		Dur("duration", duration).
+		Int("success", run.Succeeded).
+		Int("errors", run.Failed).
+		Int("skipped", run.Skipped).
+		Msg("Cache update completed")
+
+	if run.Failed > 0 && run.Succeeded == 0 {
+		return fmt.Errorf("all %d SDK analyses failed", run.Failed)
+	}
+
+	return nil
+}
+
+// cacheResults stores the analyses in results and records their outcome in
+// run. It returns the SDKs whose analysis completed and those abandoned
+// because the run was cancelled before they started.
+func (w *UpdateWorker) cacheResults(ctx context.Context, run *RunSummary, results []sdk.AnalysisResult) (completed, abandoned []string) {
 	successCount := 0
 	errorCount := 0
+	skippedCount := 0
+	var analyzed []string
+	var entries []cache.CacheEntry
+	var events []notify.AnalysisEvent
 
 	// Process results
 	for _, result := range results {
-		if result.Error != nil {
-			w.logger.Error().
+		if errors.Is(result.Error, sdk.ErrAnalysisSkipped) {
+			abandoned = append(abandoned, result.SDK.Name)
+			continue
+		}
+		completed = append(completed, result.SDK.Name)
+
+		if errors.Is(result.Error, sdk.ErrPrivateRepo) {
+			w.logger.Warn().
 				Err(result.Error).
 				Str("sdk", result.SDK.Name).
-				Msg("Failed to analyze SDK")
-			errorCount++
+				Msg("Skipping private SDK")
 			continue
 		}
-
-		// Convert analysis to JSON for caching
-		analysisJSON, err := json.Marshal(result.Analysis)
-		if err != nil {
+		if result.Error != nil {
 			w.logger.Error().
-				Err(err).
+				Err(result.Error).
 				Str("sdk", result.SDK.Name).
-				Msg("Failed to marshal analysis")
+				Msg("Failed to analyze SDK")
+			w.recordAnalysisFailure(ctx, result.SDK.Name, result.Error)
 			errorCount++
 			continue
 		}
+		w.clearAnalysisFailures(result.SDK.Name)
 
-		// Cache the analysis
-		key := fmt.Sprintf("sdk:%s", result.SDK.Name)
-		if err := w.cache.Set(key, string(analysisJSON), w.config.CacheTTL); err != nil {
+		sdkEntries, err := w.analysisEntries(result.SDK.Name, result.Analysis)
+		var previous string
+		var stored bool
+		if err == nil {
+			previous, stored, err = w.storeLatest(sdkEntries[0], result.Analysis.AnalyzedAt)
+		}
+		if err != nil {
 			w.logger.Error().
 				Err(err).
 				Str("sdk", result.SDK.Name).
 				Msg("Failed to cache SDK analysis")
 			errorCount++
-		} else {
+		} else if !stored {
 			w.logger.Info().
 				Str("sdk", result.SDK.Name).
-				Int("tokens_used", result.Analysis.TokensUsed).
-				Msg("SDK analysis cached")
-			successCount++
+				Msg("SDK analysis skipped, a newer analysis is cached")
+			skippedCount++
+		} else {
+			entries = append(entries, sdkEntries[1:]...)
+			if w.notifier != nil {
+				events = append(events, w.storedEvent(result.SDK.Name, previous, result.Analysis, result.Incremental))
+			}
+			analyzed = append(analyzed, result.SDK.Name)
+			if result.Incremental {
+				run.IncrementalUpdates++
+			}
 		}
+		run.TokensUsed += result.Analysis.TokensUsed
+		w.recordTokenUsage(result.SDK.Name, result.Analysis.TokensUsed)
+	}
 
-		// Cache version-specific analysis
-		versionKey := fmt.Sprintf("sdk:%s:%s", result.SDK.Name, result.Analysis.AnalysisVersion)
-		if err := w.cache.Set(versionKey, string(analysisJSON), w.config.CacheTTL); err != nil {
-			w.logger.Error().
-				Err(err).
-				Str("key", versionKey).
-				Msg("Failed to cache version-specific analysis")
+	// Store the version and timestamp keys of every analysis in one cache
+	// transaction
+	if err := w.cache.SetMulti(entries); err != nil {
+		w.logger.Error().
+			Err(err).
+			Strs("sdks", analyzed).
+			Msg("Failed to cache SDK analyses")
+		errorCount += len(analyzed)
+	} else {
+		for _, name := range analyzed {
+			w.logger.Info().Str("sdk", name).Msg("SDK analysis cached")
 		}
+		successCount += len(analyzed)
+		w.sendNotifications(ctx, events)
+	}
+	run.Succeeded = successCount
+	run.Failed = errorCount
+	run.Skipped = skippedCount
+	return completed, abandoned
 
-		// Update last analyzed timestamp
-		timestampKey := fmt.Sprintf("sdk:%s:last_analyzed", result.SDK.Name)
-		if err := w.cache.Set(timestampKey, time.Now().Format(time.RFC3339), 0); err != nil {
-			w.logger.Error().
-				Err(err).
-				Str("sdk", result.SDK.Name).
-				Msg("Failed to update last analyzed timestamp")
-		}
+}
+
+// storeAnalysis attaches the protocol compliance report and quality score
+// to an SDK analysis, caches it under its latest and version keys and
+// records when it was analyzed, then announces it to the notifier.
+// Nothing is stored if a newer analysis is already cached.
+func (w *UpdateWorker) storeAnalysis(ctx context.Context, sdkName string, analysis *analyzer.SDKAnalysis) error {
+	entries, err := w.analysisEntries(sdkName, analysis)
+	if err != nil {
+		return err
 	}
 
-	// Cache project summaries (these would be aggregated from actual usage data)
-	projects := []string{
-		"gremlin-arrow-flight",
-		"claude-code-gui",
+	previous, stored, err := w.storeLatest(entries[0], analysis.AnalyzedAt)
+	if err != nil {
+		return fmt.Errorf("failed to cache analysis: %w", err)
+	}
+	if !stored {
+		return nil
+	}
+	if err := w.cache.SetMulti(entries[1:]); err != nil {
+		return fmt.Errorf("failed to cache analysis: %w", err)
 	}
 
-	for _, project := range projects {
-		summary := map[string]interface{}{
-			"project":       project,
-			"cache_hits":    1000,
-			"token_savings": 45000,
-			"last_updated":  time.Now().Format(time.RFC3339),
+	if w.notifier != nil {
+		w.sendNotifications(ctx, []notify.AnalysisEvent{w.storedEvent(sdkName, previous, analysis, false)})
+	}
+	return nil
+}
+
+// analysisEntries attaches the protocol compliance report and quality score
+// to an SDK analysis and returns the cache entries storeAnalysis writes for
+// it. The first is the latest analysis, which is written with storeLatest.
+func (w *UpdateWorker) analysisEntries(sdkName string, analysis *analyzer.SDKAnalysis) ([]cache.CacheEntry, error) {
+	analysis.ComplianceReport = protocolChecker.Check(analysis)
+	w.scoreAnalysis(sdkName, analysis)
+
+	analysisJSON, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal analysis: %w", err)
+	}
+
+	return []cache.CacheEntry{
+		{
+			Key:          fmt.Sprintf("sdk:%s", sdkName),
+			Value:        string(analysisJSON),
+			TTL:          w.config.CacheTTL,
+			TokensCached: analysis.TokensUsed,
+			TokenSavings: cache.TokenSavings{EstimatedInputTokens: int64(analysis.TokensUsed)},
+		},
+		// Version-specific analysis
+		{
+			Key:          fmt.Sprintf("sdk:%s:%s", sdkName, versionKey(analysis)),
+			Value:        string(analysisJSON),
+			TTL:          w.config.CacheTTL,
+			TokensCached: analysis.TokensUsed,
+			TokenSavings: cache.TokenSavings{EstimatedInputTokens: int64(analysis.TokensUsed)},
+		},
+		// Last analyzed timestamp
+		{
+			Key:   fmt.Sprintf("sdk:%s:last_analyzed", sdkName),
+			Value: time.Now().Format(time.RFC3339),
+		},
+	}, nil
+}
+
+// versionKey returns the version an analysis is cached under besides the
+// latest: the SDK version it was made for, or the analysis version for
+// analyzers that do not know it.
+func versionKey(analysis *analyzer.SDKAnalysis) string {
+	if analysis.SDKVersion != "" {
+		return analysis.SDKVersion
+	}
+	return analysis.AnalysisVersion
+}
+
+// scoreAnalysis sets the quality score of an SDK analysis, warning when it
+// is low enough that the analysis is probably incomplete.
+func (w *UpdateWorker) scoreAnalysis(sdkName string, analysis *analyzer.SDKAnalysis) {
+	score, reasons := analyzer.Score(analysis)
+	analysis.QualityScore = score
+	if score < analyzer.LowQualityScore {
+		w.logger.Warn().
+			Str("sdk", sdkName).
+			Float64("quality_score", score).
+			Strs("reasons", reasons).
+			Msg("SDK analysis quality is low")
+	}
+}
+
+// storeLatest caches entry, an SDK's latest analysis made at analyzedAt,
+// and reports whether it did, returning the cached value it replaced, if
+// any. Refreshes, warms and scheduled updates can analyze the same SDK at
+// once, so the write is conditional on the version read and retried up to
+// maxVersionRetries times if another writer got there first. An analysis
+// newer than entry is left in place.
+func (w *UpdateWorker) storeLatest(entry cache.CacheEntry, analyzedAt time.Time) (string, bool, error) {
+	for retries := 0; ; retries++ {
+		current, version, err := w.cache.PeekWithVersion(entry.Key)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return "", false, err
+		}
+		if err == nil && analyzedAfter(current, analyzedAt) {
+			w.logger.Info().
+				Str("key", entry.Key).
+				Msg("Newer analysis already cached, skipping")
+			return "", false, nil
 		}
 
-		summaryJSON, err := json.Marshal(summary)
-		if err != nil {
-			w.logger.Error().Err(err).Str("project", project).Msg("Failed to marshal project summary")
+		_, err = w.cache.SetWithVersion(entry.Key, entry.Value, entry.TTL, version, cache.WithTokensCached(entry.TokensCached), cache.WithTokenHint(entry.TokenSavings.EstimatedInputTokens))
+		if errors.Is(err, cache.ErrVersionConflict) && retries < maxVersionRetries {
+			w.logger.Debug().
+				Str("key", entry.Key).
+				Int("retry", retries+1).
+				Msg("Cached analysis changed concurrently, retrying")
 			continue
 		}
-
-		key := fmt.Sprintf("project:%s", project)
-		if err := w.cache.Set(key, string(summaryJSON), w.config.CacheTTL); err != nil {
-			w.logger.Error().Err(err).Str("project", project).Msg("Failed to cache project summary")
+		if err != nil {
+			return "", false, err
 		}
+		return current, true, nil
 	}
+}
 
-	duration := time.Since(start)
-	w.logger.Info().
-		Dur("duration", duration).
-		Int("success", successCount).
-		Int("errors", errorCount).
-		Msg("Cache update completed")
-
-	return nil
+// analyzedAfter reports whether a cached analysis was made after t.
+// Analyses that cannot be decoded are treated as older.
+func analyzedAfter(analysisJSON string, t time.Time) bool {
+	var cached struct {
+		AnalyzedAt time.Time `json:"analyzed_at"`
+	}
+	if err := json.Unmarshal([]byte(analysisJSON), &cached); err != nil {
+		return false
+	}
+	return cached.AnalyzedAt.After(t)
 }
 
 // updateCacheFallback performs cache update using mock data when SDK analyzer is not available
-func (w *UpdateWorker) updateCacheFallback(ctx context.Context) error {
+func (w *UpdateWorker) updateCacheFallback(ctx context.Context, run *RunSummary) error {
 	// Use the original mock implementation
 	sampleSDKs := []string{"sentry-go", "sentry-python", "sentry-javascript"}
 
-	for _, sdkName := range sampleSDKs {
-		select {
-		case <-ctx.Done():
+	workCtx := w.analysisContext(ctx)
+	var completed []string
+
+	for i, sdkName := range sampleSDKs {
+		if ctx.Err() != nil {
+			w.recordDrainOutcome(completed, sampleSDKs[i:])
 			return fmt.Errorf("update cancelled")
-		default:
-			// Create mock analysis
-			mockAnalyzer := &mockAnalyzer{logger: w.logger}
-			request := analyzer.AnalysisRequest{
-				SDKName:    sdkName,
-				Version:    "1.0.0",
-				Code:       map[string]string{"main.file": "// mock code"},
-				CommitHash: "mock",
-			}
+		}
 
-			analysis, err := mockAnalyzer.AnalyzeCode(ctx, request)
-			if err != nil {
-				w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to analyze SDK")
-				continue
-			}
+		// Create mock analysis
+		request := analyzer.AnalysisRequest{
+			SDKName:    sdkName,
+			Version:    "1.0.0",
+			Code:       map[string]string{"main.file": "// mock code"},
+			CommitHash: "mock",
+		}
 
-			// Convert analysis to JSON for caching
-			analysisJSON, err := json.Marshal(analysis)
-			if err != nil {
-				w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to marshal analysis")
-				continue
-			}
+		analysis, err := w.fallbackAnalyzer.AnalyzeCode(workCtx, request)
+		completed = append(completed, sdkName)
+		if err != nil {
+			w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to analyze SDK")
+			w.recordAnalysisFailure(ctx, sdkName, err)
+			run.Failed++
+			continue
+		}
+		w.clearAnalysisFailures(sdkName)
 
-			// Cache the analysis
-			key := fmt.Sprintf("sdk:%s", sdkName)
-			if err := w.cache.Set(key, string(analysisJSON), w.config.CacheTTL); err != nil {
-				w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to cache SDK analysis")
-			} else {
-				w.logger.Info().Str("sdk", sdkName).Msg("SDK analysis cached")
-			}
+		analysis.ComplianceReport = protocolChecker.Check(analysis)
+		w.scoreAnalysis(sdkName, analysis)
+
+		// Convert analysis to JSON for caching
+		analysisJSON, err := json.Marshal(analysis)
+		if err != nil {
+			w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to marshal analysis")
+			continue
 		}
+
+		// Cache the analysis
+		key := fmt.Sprintf("sdk:%s", sdkName)
+		if err := w.cache.Set(key, string(analysisJSON), w.config.CacheTTL, cache.WithTokensCached(analysis.TokensUsed), cache.WithTokenHint(int64(analysis.TokensUsed))); err != nil {
+			w.logger.Error().Err(err).Str("sdk", sdkName).Msg("Failed to cache SDK analysis")
+			run.Failed++
+		} else {
+			w.logger.Info().Str("sdk", sdkName).Msg("SDK analysis cached")
+			run.Succeeded++
+		}
+		run.TokensUsed += analysis.TokensUsed
+		w.recordTokenUsage(sdkName, analysis.TokensUsed)
+	}
+
+	if ctx.Err() != nil {
+		w.recordDrainOutcome(completed, nil)
+		return fmt.Errorf("update cancelled")
 	}
 
 	// Cache project summaries
@@ -285,117 +651,3 @@ type cronLogger struct {
 func (l *cronLogger) Printf(format string, v ...interface{}) {
 	l.logger.Debug().Msgf(format, v...)
 }
-
-// mockAnalyzer provides mock analysis when Claude API is not configured
-type mockAnalyzer struct {
-	logger zerolog.Logger
-}
-
-func (m *mockAnalyzer) AnalyzeCode(ctx context.Context, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
-	m.logger.Info().
-		Str("sdk", request.SDKName).
-		Str("version", request.Version).
-		Msg("Using mock analyzer")
-
-	// Return mock analysis data
-	return &analyzer.SDKAnalysis{
-		Language:       detectLanguage(request.SDKName),
-		EnvelopeFormat: "JSON envelope with headers and items array",
-		Transport: analyzer.TransportDetails{
-			Type:                "http",
-			Protocols:           []string{"https"},
-			RetryMechanism:      "exponential backoff with jitter",
-			QueueImplementation: "in-memory queue with disk overflow",
-		},
-		EventTypes: []string{"error", "transaction", "profile", "metric"},
-		ErrorPatterns: []analyzer.ErrorPattern{
-			{
-				Name:        "structured_errors",
-				Pattern:     "Error{type, message, stacktrace}",
-				Description: "Structured error handling with full context",
-			},
-		},
-		Integrations:    []string{"logging", "http", "database"},
-		Features:        []string{"breadcrumbs", "attachments", "sessions", "release_tracking"},
-		ProtocolVersion: "7",
-		CachingPatterns: []analyzer.CachingPattern{
-			{
-				Type:        "envelope_buffer",
-				Location:    "transport",
-				Description: "Buffers envelopes during network failures",
-			},
-		},
-		TokensUsed:      0, // Mock analyzer doesn't use tokens
-		AnalyzedAt:      time.Now(),
-		AnalysisVersion: "mock-1.0.0",
-	}, nil
-}
-
-func (m *mockAnalyzer) BatchAnalyze(ctx context.Context, requests []analyzer.AnalysisRequest) (*analyzer.BatchAnalysisResult, error) {
-	result := &analyzer.BatchAnalysisResult{
-		JobID:   fmt.Sprintf("mock-job-%d", time.Now().Unix()),
-		Status:  "completed",
-		Results: make(map[string]*analyzer.SDKAnalysis),
-		Errors:  make(map[string]string),
-	}
-
-	for _, req := range requests {
-		analysis, err := m.AnalyzeCode(ctx, req)
-		if err != nil {
-			result.Errors[req.SDKName] = err.Error()
-		} else {
-			result.Results[req.SDKName] = analysis
-		}
-	}
-
-	now := time.Now()
-	result.CompletedAt = &now
-	return result, nil
-}
-
-func (m *mockAnalyzer) GetBatchStatus(ctx context.Context, jobID string) (*analyzer.BatchAnalysisResult, error) {
-	return nil, fmt.Errorf("batch status not supported in mock analyzer")
-}
-
-func (m *mockAnalyzer) CountTokens(ctx context.Context, request analyzer.AnalysisRequest) (int, error) {
-	// Mock token count based on code size
-	totalChars := 0
-	for _, code := range request.Code {
-		totalChars += len(code)
-	}
-	return totalChars / 4, nil // Approximate 4 chars per token
-}
-
-func detectLanguage(sdkName string) string {
-	switch {
-	case contains(sdkName, "python"):
-		return "python"
-	case contains(sdkName, "javascript"), contains(sdkName, "js"):
-		return "javascript"
-	case contains(sdkName, "go"):
-		return "go"
-	case contains(sdkName, "java"):
-		return "java"
-	case contains(sdkName, "ruby"):
-		return "ruby"
-	case contains(sdkName, "php"):
-		return "php"
-	case contains(sdkName, "dotnet"), contains(sdkName, "csharp"):
-		return "csharp"
-	default:
-		return "unknown"
-	}
-}
-
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && findSubstring(s, substr) != -1
-}
-
-func findSubstring(s, substr string) int {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return i
-		}
-	}
-	return -1
-}
@@ -1,8 +1,11 @@
 package worker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,8 +13,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
 	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/claude"
+	"github.com/ryanrussell/claude-cache-service/internal/claude/mockserver"
 	"github.com/ryanrussell/claude-cache-service/internal/config"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
 )
 
 func TestNewUpdateWorker(t *testing.T) {
@@ -62,7 +69,7 @@ func TestUpdateCache(t *testing.T) {
 	worker.sdkAnalyzer = nil
 
 	ctx := context.Background()
-	err = worker.updateCache(ctx)
+	err = worker.updateCache(ctx, true)
 	assert.NoError(t, err)
 
 	// Verify that SDKs were cached
@@ -90,6 +97,211 @@ func TestUpdateCache(t *testing.T) {
 	}
 }
 
+func TestCacheResultsSkipsSupersededAnalysis(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+	}
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+
+	// A newer analysis of sentry-go is already cached
+	newer, err := json.Marshal(analyzer.SDKAnalysis{
+		Language:   "go",
+		SDKVersion: "2.0.0",
+		AnalyzedAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NoError(t, cacheManager.Set("sdk:sentry-go", string(newer), time.Hour))
+
+	results := []sdk.AnalysisResult{
+		{
+			SDK:      sdk.Config{Name: "sentry-go"},
+			Analysis: &analyzer.SDKAnalysis{Language: "go", SDKVersion: "1.0.0", AnalyzedAt: time.Now()},
+		},
+		{
+			SDK:      sdk.Config{Name: "sentry-python"},
+			Analysis: &analyzer.SDKAnalysis{Language: "python", SDKVersion: "1.0.0", AnalyzedAt: time.Now()},
+		},
+	}
+
+	run := &RunSummary{}
+	completed, abandoned := worker.cacheResults(context.Background(), run, results)
+	assert.Equal(t, []string{"sentry-go", "sentry-python"}, completed)
+	assert.Empty(t, abandoned)
+	assert.Equal(t, 1, run.Succeeded)
+	assert.Equal(t, 1, run.Skipped)
+	assert.Equal(t, 0, run.Failed)
+
+	// The newer analysis is kept and no version key is written for the older one
+	value, err := cacheManager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.JSONEq(t, string(newer), value)
+	_, err = cacheManager.Get("sdk:sentry-go:1.0.0")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	_, err = cacheManager.Get("sdk:sentry-python")
+	assert.NoError(t, err)
+}
+
+func TestStoreAnalysisConcurrent(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+	}
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+
+	// Writers finishing out of order must leave the newest analysis cached
+	base := time.Now().Truncate(time.Second)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			analysis := &analyzer.SDKAnalysis{
+				Language:        "go",
+				AnalysisVersion: "1.0.0",
+				AnalyzedAt:      base.Add(time.Duration(i) * time.Minute),
+			}
+			assert.NoError(t, worker.storeAnalysis(context.Background(), "sentry-go", analysis))
+		}(i)
+	}
+	wg.Wait()
+
+	// Reading the version to write against is not a cache hit
+	assert.Zero(t, cacheManager.GetStats().Hits)
+
+	value, version, err := cacheManager.GetWithVersion("sdk:sentry-go")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, version, int64(1))
+
+	var cached analyzer.SDKAnalysis
+	require.NoError(t, json.Unmarshal([]byte(value), &cached))
+	assert.True(t, cached.AnalyzedAt.Equal(base.Add(9*time.Minute)), "cached analysis from %s", cached.AnalyzedAt)
+
+	// An older analysis arriving late is not stored
+	stale := &analyzer.SDKAnalysis{Language: "go", AnalysisVersion: "0.9.0", AnalyzedAt: base}
+	require.NoError(t, worker.storeAnalysis(context.Background(), "sentry-go", stale))
+	_, err = cacheManager.Get("sdk:sentry-go:0.9.0")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	// Analyses of a known SDK version are cached under it
+	tagged := &analyzer.SDKAnalysis{Language: "go", AnalysisVersion: "1.0.0", SDKVersion: "v0.29.1", AnalyzedAt: base.Add(time.Hour)}
+	require.NoError(t, worker.storeAnalysis(context.Background(), "sentry-go", tagged))
+	_, err = cacheManager.Get("sdk:sentry-go:v0.29.1")
+	assert.NoError(t, err)
+}
+
+func TestStoreAnalysisQualityScore(t *testing.T) {
+	tempDir := t.TempDir()
+	var logs bytes.Buffer
+	logger := zerolog.New(&logs).Level(zerolog.WarnLevel)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+	}
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+
+	// Language only: every other deduction applies
+	incomplete := &analyzer.SDKAnalysis{Language: "go", AnalysisVersion: "1.0.0", AnalyzedAt: time.Now()}
+	require.NoError(t, worker.storeAnalysis(context.Background(), "sentry-go", incomplete))
+
+	value, err := cacheManager.Get("sdk:sentry-go")
+	require.NoError(t, err)
+	var cached analyzer.SDKAnalysis
+	require.NoError(t, json.Unmarshal([]byte(value), &cached))
+	assert.Equal(t, 25.0, cached.QualityScore)
+	assert.Contains(t, logs.String(), "SDK analysis quality is low")
+	assert.Contains(t, logs.String(), "no error patterns")
+
+	// Complete analyses are not warned about
+	logs.Reset()
+	complete, err := analyzer.NewMockAnalyzer(logger).AnalyzeCode(context.Background(), analyzer.AnalysisRequest{SDKName: "sentry-python"})
+	require.NoError(t, err)
+	require.NoError(t, worker.storeAnalysis(context.Background(), "sentry-python", complete))
+	assert.Equal(t, analyzer.MaxQualityScore, complete.QualityScore)
+	assert.NotContains(t, logs.String(), "SDK analysis quality is low")
+}
+
+func TestUpdateCacheWithClaudeAnalyzer(t *testing.T) {
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	defer func() {
+		err := cacheManager.Close()
+		require.NoError(t, err)
+	}()
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+	}
+
+	server := mockserver.NewMockServer(t)
+	server.SetResponse(mockserver.TextResponse(`{"language": "mocked", "envelope_format": "json", "protocol_version": "7"}`, 10, 20))
+
+	client := claude.NewClient("test-key", "claude-3-opus", logger)
+	client.BaseURL = server.URL
+
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+	worker.sdkAnalyzer = nil
+	worker.fallbackAnalyzer = analyzer.NewClaudeAnalyzerWithClient(client, logger)
+
+	err = worker.updateCache(context.Background(), true)
+	require.NoError(t, err)
+
+	// One Claude request per sample SDK
+	assert.Equal(t, 3, server.CallCount())
+
+	metrics := worker.Metrics()
+	assert.Equal(t, 1, metrics.Runs)
+	assert.Equal(t, 1.0, metrics.SuccessRateEMA)
+	assert.Equal(t, 90.0, metrics.TokensPerRunEMA)
+	assert.Equal(t, 3, metrics.LastNRuns[0].Succeeded)
+	assert.Contains(t, server.LastRequest().Messages[1].Content, "sentry-javascript")
+
+	for _, sdk := range []string{"sentry-go", "sentry-python", "sentry-javascript"} {
+		value, err := cacheManager.Get("sdk:" + sdk)
+		require.NoError(t, err, "SDK %s should be cached", sdk)
+		assert.Contains(t, value, `"language":"mocked"`)
+		assert.Contains(t, value, `"tokens_used":30`)
+		assert.Contains(t, value, `"rule":"https_transport"`, "compliance report is stored with the analysis")
+	}
+}
+
 func TestUpdateCacheWithCancellation(t *testing.T) {
 	tempDir := t.TempDir()
 	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
@@ -117,9 +329,10 @@ func TestUpdateCacheWithCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	err = worker.updateCache(ctx)
+	err = worker.updateCache(ctx, true)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "update cancelled")
+	assert.Zero(t, worker.Metrics().Runs, "cancelled runs are not recorded")
 }
 
 func TestWorkerStartStop(t *testing.T) {
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	apperrors "github.com/ryanrussell/claude-cache-service/internal/errors"
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// JobKindCacheWarm identifies cache warming jobs in the JobRegistry.
+const JobKindCacheWarm = "cache_warm"
+
+var (
+	// ErrUnknownSDK matches the *apperrors.SDKNotFoundError returned when
+	// a requested SDK is not configured.
+	ErrUnknownSDK = apperrors.ErrSDKNotFound
+
+	// ErrDraining is returned when work is requested during shutdown.
+	ErrDraining = errors.New("worker is shutting down")
+)
+
+// Jobs returns the registry tracking the worker's background jobs.
+func (w *UpdateWorker) Jobs() *JobRegistry {
+	return w.jobs
+}
+
+// WarmSDKs analyzes and caches the named SDKs in the background, limited by
+// the worker's priority pool, and returns the job tracking their progress.
+// SDKs analyzed within StaleThreshold are skipped unless force is set.
+func (w *UpdateWorker) WarmSDKs(names []string, force bool) (*Job, error) {
+	targets, err := resolveSDKs(names)
+	if err != nil {
+		return nil, err
+	}
+
+	if !w.beginRun() {
+		return nil, ErrDraining
+	}
+
+	job := w.jobs.Start(JobKindCacheWarm, len(targets))
+	w.logger.Info().
+		Str("job_id", job.ID()).
+		Int("sdks", len(targets)).
+		Bool("force", force).
+		Msg("Starting cache warm")
+
+	go func() {
+		defer w.endRun()
+		w.warm(job, targets, force, nil)
+	}()
+
+	return job, nil
+}
+
+// warm analyzes targets in priority order, starting the SDKs named in first
+// ahead of the rest and giving them high-priority pool slots.
+func (w *UpdateWorker) warm(job *Job, targets []sdk.Config, force bool, first []string) {
+	ctx := w.analysisContext(context.Background())
+
+	var wg sync.WaitGroup
+	queue := sdk.NewSDKPriorityQueue(targets, first)
+	for {
+		target, _, ok := queue.Next()
+		if !ok {
+			break
+		}
+		if !force && w.isFresh(target.Name) {
+			w.logger.Debug().Str("sdk", target.Name).Msg("SDK analysis is fresh, skipping warm")
+			job.Skip()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			priority := target.Priority
+			if slices.Contains(first, target.Name) {
+				priority = sdk.PriorityHigh
+			}
+			release, err := w.pool.Acquire(ctx, priority)
+			if err == nil {
+				_, err = w.warmSDK(ctx, target)
+				release()
+			}
+			if err != nil {
+				w.logger.Error().
+					Err(err).
+					Str("job_id", job.ID()).
+					Str("sdk", target.Name).
+					Msg("Failed to warm SDK analysis")
+			}
+			job.Done(err)
+		}()
+	}
+
+	wg.Wait()
+	job.Finish()
+
+	progress := job.Progress()
+	w.logger.Info().
+		Str("job_id", job.ID()).
+		Int("completed", progress.Completed).
+		Int("errors", progress.Errors).
+		Int("skipped", progress.Skipped).
+		Msg("Cache warm completed")
+}
+
+// isFresh reports whether the SDK was analyzed within StaleThreshold.
+func (w *UpdateWorker) isFresh(sdkName string) bool {
+	if w.config.StaleThreshold <= 0 {
+		return false
+	}
+
+	value, err := w.cache.Get(fmt.Sprintf("sdk:%s:last_analyzed", sdkName))
+	if err != nil {
+		return false
+	}
+
+	lastAnalyzed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	return time.Since(lastAnalyzed) < w.config.StaleThreshold
+}
+
+// warmSDK analyzes a single SDK, caches the result and returns it.
+func (w *UpdateWorker) warmSDK(ctx context.Context, target sdk.Config) (*analyzer.SDKAnalysis, error) {
+	var analysis *analyzer.SDKAnalysis
+	var err error
+
+	if w.sdkAnalyzer != nil {
+		analysis, err = w.sdkAnalyzer.AnalyzeSDK(ctx, target)
+	} else {
+		analysis, err = w.fallbackAnalyzer.AnalyzeCode(ctx, fallbackRequest(target.Name))
+		if err != nil {
+			err = &apperrors.AnalysisFailedError{SDK: target.Name, Cause: err}
+		}
+	}
+	if err != nil {
+		w.recordAnalysisFailure(ctx, target.Name, err)
+		return nil, err
+	}
+	w.clearAnalysisFailures(target.Name)
+
+	w.recordTokenUsage(target.Name, analysis.TokensUsed)
+	if err := w.storeAnalysis(ctx, target.Name, analysis); err != nil {
+		return nil, err
+	}
+	return analysis, nil
+}
+
+// fallbackRequest is the placeholder analysis request for an SDK used when
+// the SDK analyzer is unavailable.
+func fallbackRequest(sdkName string) analyzer.AnalysisRequest {
+	return analyzer.AnalysisRequest{
+		SDKName:    sdkName,
+		Version:    "1.0.0",
+		Code:       map[string]string{"main.file": "// mock code"},
+		CommitHash: "mock",
+	}
+}
@@ -0,0 +1,187 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/analyzer"
+	"github.com/ryanrussell/claude-cache-service/internal/cache"
+	"github.com/ryanrussell/claude-cache-service/internal/config"
+)
+
+// gatedAnalyzer blocks each analysis until released and fails the SDKs in
+// failing.
+type gatedAnalyzer struct {
+	*analyzer.MockAnalyzer
+	release chan struct{}
+	failing map[string]bool
+
+	mu      sync.Mutex
+	active  int
+	maxSeen int
+	calls   []string
+}
+
+func (g *gatedAnalyzer) AnalyzeCode(ctx context.Context, request analyzer.AnalysisRequest) (*analyzer.SDKAnalysis, error) {
+	g.mu.Lock()
+	g.active++
+	g.maxSeen = max(g.maxSeen, g.active)
+	g.calls = append(g.calls, request.SDKName)
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		g.active--
+		g.mu.Unlock()
+	}()
+
+	select {
+	case <-g.release:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if g.failing[request.SDKName] {
+		return nil, errors.New("analysis failed")
+	}
+	return g.MockAnalyzer.AnalyzeCode(ctx, request)
+}
+
+func (g *gatedAnalyzer) Calls() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.calls...)
+}
+
+func (g *gatedAnalyzer) MaxConcurrent() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.maxSeen
+}
+
+func newWarmTestWorker(t *testing.T, maxConcurrent int) (*UpdateWorker, *cache.Manager, *gatedAnalyzer) {
+	t.Helper()
+
+	tempDir := t.TempDir()
+	logger := zerolog.New(os.Stderr).Level(zerolog.Disabled)
+
+	cacheManager, err := cache.NewManager(tempDir, logger)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, cacheManager.Close())
+	})
+
+	cfg := &config.Config{
+		UpdateSchedule: "0 2 * * 0",
+		CacheTTL:       time.Hour,
+		CacheDir:       tempDir,
+		MaxConcurrent:  maxConcurrent,
+		StaleThreshold: time.Hour,
+	}
+
+	gated := &gatedAnalyzer{
+		MockAnalyzer: analyzer.NewMockAnalyzer(logger),
+		release:      make(chan struct{}),
+		failing:      map[string]bool{"sentry-ruby": true},
+	}
+
+	worker := NewUpdateWorker(cacheManager, logger, cfg)
+	worker.sdkAnalyzer = nil
+	worker.fallbackAnalyzer = gated
+	return worker, cacheManager, gated
+}
+
+func waitForProgress(t *testing.T, job *Job, completed int) JobProgress {
+	t.Helper()
+
+	var progress JobProgress
+	require.Eventually(t, func() bool {
+		progress = job.Progress()
+		return progress.Completed == completed
+	}, 5*time.Second, 5*time.Millisecond)
+	return progress
+}
+
+func TestWarmSDKsProgress(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 2)
+	sdks := []string{"sentry-go", "sentry-python", "sentry-ruby", "sentry-java", "sentry-php"}
+
+	job, err := worker.WarmSDKs(sdks, false)
+	require.NoError(t, err)
+
+	// Two analyses start and wait to be released
+	require.Eventually(t, func() bool { return len(gated.Calls()) == 2 }, 5*time.Second, 5*time.Millisecond)
+	progress := job.Progress()
+	assert.Equal(t, JobRunning, progress.Status)
+	assert.Equal(t, 0, progress.Completed)
+	assert.Equal(t, 5, progress.Total)
+
+	for i := 1; i <= 3; i++ {
+		gated.release <- struct{}{}
+		waitForProgress(t, job, i)
+	}
+
+	progress = job.Progress()
+	assert.Equal(t, JobRunning, progress.Status)
+	assert.InDelta(t, 60.0, progress.Percent, 0.001)
+
+	gated.release <- struct{}{}
+	gated.release <- struct{}{}
+	waitForProgress(t, job, 5)
+
+	require.Eventually(t, func() bool { return job.Progress().Status == JobCompleted }, 5*time.Second, 5*time.Millisecond)
+	progress = job.Progress()
+	assert.Equal(t, 1, progress.Errors)
+	assert.Equal(t, 0, progress.Skipped)
+	assert.Equal(t, 100.0, progress.Percent)
+	assert.LessOrEqual(t, gated.MaxConcurrent(), 2)
+	assert.ElementsMatch(t, sdks, gated.Calls())
+
+	for _, name := range []string{"sentry-go", "sentry-python", "sentry-java", "sentry-php"} {
+		_, err := cacheManager.Get("sdk:" + name)
+		assert.NoError(t, err, "SDK %s should be cached", name)
+		_, err = cacheManager.Get("sdk:" + name + ":last_analyzed")
+		assert.NoError(t, err, "SDK %s should have a last analyzed time", name)
+	}
+	_, err = cacheManager.Get("sdk:sentry-ruby")
+	assert.Error(t, err)
+}
+
+func TestWarmSDKsSkipsFresh(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 2)
+	close(gated.release)
+
+	require.NoError(t, cacheManager.Set("sdk:sentry-go:last_analyzed", time.Now().Format(time.RFC3339), 0))
+	require.NoError(t, cacheManager.Set("sdk:sentry-python:last_analyzed", time.Now().Add(-2*time.Hour).Format(time.RFC3339), 0))
+
+	job, err := worker.WarmSDKs([]string{"sentry-go", "sentry-python"}, false)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return job.Progress().Status == JobCompleted }, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 1, job.Progress().Skipped)
+	assert.Equal(t, []string{"sentry-python"}, gated.Calls())
+
+	// force re-analyzes fresh SDKs
+	job, err = worker.WarmSDKs([]string{"sentry-go"}, true)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return job.Progress().Status == JobCompleted }, 5*time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, 0, job.Progress().Skipped)
+	assert.Equal(t, []string{"sentry-python", "sentry-go"}, gated.Calls())
+}
+
+func TestWarmSDKsUnknown(t *testing.T) {
+	worker, _, gated := newWarmTestWorker(t, 2)
+
+	_, err := worker.WarmSDKs([]string{"sentry-go", "no-such-sdk"}, false)
+	assert.ErrorIs(t, err, ErrUnknownSDK)
+	assert.Empty(t, gated.Calls())
+}
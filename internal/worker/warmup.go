@@ -0,0 +1,179 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+// Warmup outcomes of an SDK.
+const (
+	WarmupAnalyzed  = "analyzed"
+	WarmupEstimated = "estimated"
+	WarmupSkipped   = "skipped"
+	WarmupFailed    = "failed"
+)
+
+// WarmupResult is the outcome of warming up one SDK.
+type WarmupResult struct {
+	SDK        string        `json:"sdk"`
+	Status     string        `json:"status"`
+	TokensUsed int           `json:"tokens_used,omitempty"`
+	Estimate   *CostEstimate `json:"estimate,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// WarmupSummary is the outcome of a warmup. In a dry run nothing is
+// analyzed; the SDKs that would be are estimated instead.
+type WarmupSummary struct {
+	DryRun     bool `json:"dry_run"`
+	Total      int  `json:"total"`
+	Cached     int  `json:"cached"`
+	Estimated  int  `json:"estimated"`
+	Skipped    int  `json:"skipped"`
+	Failed     int  `json:"failed"`
+	TokensUsed int  `json:"tokens_used"`
+
+	EstimatedInputTokens  int     `json:"estimated_input_tokens,omitempty"`
+	EstimatedOutputTokens int     `json:"estimated_output_tokens,omitempty"`
+	EstimatedCostUSD      float64 `json:"estimated_cost_usd,omitempty"`
+
+	SDKs []WarmupResult `json:"sdks"`
+}
+
+// Warmup analyzes and caches every active SDK, highest priority first and
+// limited by the worker's priority pool, and waits for the results. SDKs
+// analyzed within StaleThreshold are skipped, so repeating a warmup only
+// analyzes what is missing or stale. With dryRun, the cost of analyzing
+// each SDK is estimated instead. SDKs not started before ctx is done are
+// reported as failed.
+func (w *UpdateWorker) Warmup(ctx context.Context, dryRun bool) (*WarmupSummary, error) {
+	configs, err := sdk.LoadConfigs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SDK configs: %w", err)
+	}
+
+	if !w.beginRun() {
+		return nil, ErrDraining
+	}
+	defer w.endRun()
+
+	targets := configs.GetActiveSDKs()
+	results := make([]WarmupResult, len(targets))
+
+	w.logger.Info().
+		Int("sdks", len(targets)).
+		Bool("dry_run", dryRun).
+		Msg("Starting cache warmup")
+
+	var wg sync.WaitGroup
+	queue := sdk.NewSDKPriorityQueue(targets, nil)
+	for {
+		target, i, ok := queue.Next()
+		if !ok {
+			break
+		}
+		if w.isFresh(target.Name) {
+			results[i] = WarmupResult{SDK: target.Name, Status: WarmupSkipped}
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			release, err := w.pool.Acquire(ctx, target.Priority)
+			if err != nil {
+				results[i] = WarmupResult{SDK: target.Name, Status: WarmupFailed, Error: err.Error()}
+				return
+			}
+			defer release()
+			results[i] = w.warmupSDK(ctx, target, dryRun)
+		}()
+	}
+	wg.Wait()
+
+	summary := &WarmupSummary{DryRun: dryRun, Total: len(results), SDKs: results}
+	for _, result := range results {
+		switch result.Status {
+		case WarmupAnalyzed:
+			summary.Cached++
+			summary.TokensUsed += result.TokensUsed
+		case WarmupEstimated:
+			summary.Estimated++
+			summary.EstimatedInputTokens += result.Estimate.EstimatedInputTokens
+			summary.EstimatedOutputTokens += result.Estimate.EstimatedOutputTokens
+			summary.EstimatedCostUSD += result.Estimate.EstimatedCostUSD
+		case WarmupSkipped:
+			summary.Skipped++
+		case WarmupFailed:
+			summary.Failed++
+		}
+	}
+
+	w.logger.Info().
+		Bool("dry_run", dryRun).
+		Int("cached", summary.Cached).
+		Int("estimated", summary.Estimated).
+		Int("skipped", summary.Skipped).
+		Int("failed", summary.Failed).
+		Int("tokens_used", summary.TokensUsed).
+		Msg("Cache warmup completed")
+	return summary, nil
+}
+
+// warmupSDK analyzes target, or estimates its cost in a dry run.
+func (w *UpdateWorker) warmupSDK(ctx context.Context, target sdk.Config, dryRun bool) WarmupResult {
+	result := WarmupResult{SDK: target.Name}
+
+	if dryRun {
+		estimate, err := w.estimateCost(ctx, target)
+		if err != nil {
+			w.logger.Error().Err(err).Str("sdk", target.Name).Msg("Failed to estimate SDK analysis cost")
+			result.Status = WarmupFailed
+			result.Error = err.Error()
+			return result
+		}
+		result.Status = WarmupEstimated
+		result.Estimate = estimate
+		return result
+	}
+
+	analysis, err := w.warmSDK(ctx, target)
+	if err != nil {
+		w.logger.Error().Err(err).Str("sdk", target.Name).Msg("Failed to warm up SDK analysis")
+		result.Status = WarmupFailed
+		result.Error = err.Error()
+		return result
+	}
+	result.Status = WarmupAnalyzed
+	result.TokensUsed = analysis.TokensUsed
+	return result
+}
+
+// estimateCost estimates what analyzing target now would cost. Without the
+// SDK analyzer, the placeholder request the fallback analyzer would get is
+// counted instead.
+func (w *UpdateWorker) estimateCost(ctx context.Context, target sdk.Config) (*CostEstimate, error) {
+	if w.sdkAnalyzer != nil {
+		input, err := w.sdkAnalyzer.EstimateInput(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		return w.costEstimate(target.Name, input), nil
+	}
+
+	request := fallbackRequest(target.Name)
+	tokens, err := w.fallbackAnalyzer.CountTokens(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count tokens: %w", err)
+	}
+
+	input := &sdk.InputEstimate{Version: request.Version, Tokens: tokens, Files: len(request.Code), Passes: 1}
+	for _, content := range request.Code {
+		input.TotalBytes += int64(len(content))
+	}
+	return w.costEstimate(target.Name, input), nil
+}
@@ -0,0 +1,96 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ryanrussell/claude-cache-service/internal/sdk"
+)
+
+func TestWarmupDryRun(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 2)
+	close(gated.release)
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	active := configs.GetActiveSDKs()
+	require.NotEmpty(t, active)
+
+	// Fresh SDKs are left out of the estimate
+	require.NoError(t, cacheManager.Set("sdk:"+active[0].Name+":last_analyzed", time.Now().Format(time.RFC3339), 0))
+
+	summary, err := worker.Warmup(context.Background(), true)
+	require.NoError(t, err)
+
+	assert.Empty(t, gated.Calls(), "a dry run must not analyze")
+	assert.True(t, summary.DryRun)
+	assert.Equal(t, len(active), summary.Total)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Equal(t, len(active)-1, summary.Estimated)
+	assert.Zero(t, summary.Cached)
+	assert.Zero(t, summary.TokensUsed)
+	assert.Positive(t, summary.EstimatedInputTokens)
+	assert.Positive(t, summary.EstimatedOutputTokens)
+
+	for i, result := range summary.SDKs {
+		assert.Equal(t, active[i].Name, result.SDK)
+		_, err := cacheManager.Get("sdk:" + result.SDK)
+		assert.Error(t, err, "a dry run must not cache %s", result.SDK)
+		if i == 0 {
+			assert.Equal(t, WarmupSkipped, result.Status)
+			continue
+		}
+		assert.Equal(t, WarmupEstimated, result.Status)
+		require.NotNil(t, result.Estimate)
+		assert.Equal(t, result.SDK, result.Estimate.SDK)
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	worker, cacheManager, gated := newWarmTestWorker(t, 2)
+	close(gated.release)
+
+	configs, err := sdk.LoadConfigs()
+	require.NoError(t, err)
+	active := configs.GetActiveSDKs()
+
+	failing := 0
+	for _, target := range active {
+		if gated.failing[target.Name] {
+			failing++
+		}
+	}
+
+	summary, err := worker.Warmup(context.Background(), false)
+	require.NoError(t, err)
+	assert.False(t, summary.DryRun)
+	assert.Equal(t, len(active), summary.Total)
+	assert.Equal(t, len(active)-failing, summary.Cached)
+	assert.Equal(t, failing, summary.Failed)
+	assert.Zero(t, summary.Skipped)
+	assert.Len(t, gated.Calls(), len(active))
+
+	for _, result := range summary.SDKs {
+		_, err := cacheManager.Get("sdk:" + result.SDK)
+		if gated.failing[result.SDK] {
+			assert.Equal(t, WarmupFailed, result.Status)
+			assert.NotEmpty(t, result.Error)
+			assert.Error(t, err)
+			continue
+		}
+		assert.Equal(t, WarmupAnalyzed, result.Status)
+		assert.NoError(t, err)
+	}
+
+	// Warming up again only retries what failed
+	summary, err = worker.Warmup(context.Background(), false)
+	require.NoError(t, err)
+	assert.Equal(t, len(active)-failing, summary.Skipped)
+	assert.Equal(t, failing, summary.Failed)
+	assert.Zero(t, summary.Cached)
+	assert.Len(t, gated.Calls(), len(active)+failing)
+}